@@ -0,0 +1,41 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/gnolang/tlin/internal/cache"
+)
+
+// cacheCommand is the first argument of the cache subcommands.
+const cacheCommand = "cache"
+
+// cacheDir returns the directory of the result cache, or an empty one when
+// the cache is disabled or the user has no cache directory.
+func (c Config) cacheDir() string {
+	if c.NoCache {
+		return ""
+	}
+	dir, err := cache.DefaultDir()
+	if err != nil {
+		return ""
+	}
+	return dir
+}
+
+// runCacheCommand runs `tlin cache <command>`.
+func runCacheCommand(w io.Writer, args []string) error {
+	if len(args) != 1 || args[0] != "clean" {
+		return errors.New("usage: tlin cache clean")
+	}
+	dir, err := cache.DefaultDir()
+	if err != nil {
+		return fmt.Errorf("error locating the cache directory: %w", err)
+	}
+	if err := cache.Clean(dir); err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "removed the result cache %s\n", dir)
+	return nil
+}
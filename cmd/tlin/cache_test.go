@@ -0,0 +1,35 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gnolang/tlin/internal/cache"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunCacheCommand(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CACHE_HOME", filepath.Join(home, ".cache"))
+
+	dir, err := cache.DefaultDir()
+	require.NoError(t, err)
+	require.NoError(t, os.MkdirAll(dir, 0o755))
+
+	config := parseFlags([]string{"file.go"})
+	assert.Equal(t, dir, config.runOptions(nil).CacheDir)
+	config = parseFlags([]string{"-no-cache", "file.go"})
+	assert.Empty(t, config.runOptions(nil).CacheDir)
+
+	var buf bytes.Buffer
+	assert.EqualError(t, runCacheCommand(&buf, []string{"purge"}), "usage: tlin cache clean")
+	assert.DirExists(t, dir)
+
+	require.NoError(t, runCacheCommand(&buf, []string{"clean"}))
+	assert.NoDirExists(t, dir)
+	assert.Contains(t, buf.String(), dir)
+}
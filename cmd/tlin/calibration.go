@@ -0,0 +1,107 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+
+	"github.com/gnolang/tlin/internal"
+	"github.com/gnolang/tlin/internal/calibration"
+	tt "github.com/gnolang/tlin/internal/types"
+	"github.com/gnolang/tlin/lint"
+	"go.uber.org/zap"
+)
+
+// calibrationRepo is the repository the calibration history is kept for.
+// Like the engine root, it is the working directory.
+const calibrationRepo = "."
+
+// calibrationReport is the JSON form of the calibration report.
+type calibrationReport struct {
+	Repo        string                   `json:"repo"`
+	Runs        int                      `json:"runs"`
+	Rules       []calibration.RuleStats  `json:"rules"`
+	Suggestions []calibration.Suggestion `json:"suggestions"`
+	Config      string                   `json:"config,omitempty"`
+}
+
+func calibrationStore() (calibration.Store, error) {
+	dir, err := calibration.DefaultDir()
+	if err != nil {
+		return calibration.Store{}, fmt.Errorf("error locating the cache directory: %w", err)
+	}
+	return calibration.Store{Dir: dir}, nil
+}
+
+// recordCalibration returns a report hook appending the suppression counts
+// of the run to the history of the repository.
+func recordCalibration(logger *zap.Logger, store calibration.Store) func(*lint.Report) {
+	return func(report *lint.Report) {
+		run := calibration.Run{Time: time.Now(), Rules: make(map[string]calibration.Counts)}
+		for rule, stats := range report.Rules {
+			if stats.Found > 0 || stats.Suppressed > 0 {
+				run.Rules[rule] = calibration.Counts{Found: stats.Found, Suppressed: stats.Suppressed}
+			}
+		}
+		if err := store.Record(calibrationRepo, run); err != nil {
+			// the history is only a tuning aid, it never fails the run
+			logger.Warn("Error recording calibration data", zap.Error(err))
+		}
+	}
+}
+
+// writeCalibrationReport lists the rules by suppression ratio and suggests
+// a configuration for the ones suppressed most often.
+func writeCalibrationReport(w io.Writer, engine *internal.Engine, history calibration.History, isJson bool) error {
+	configs, err := engine.EffectiveConfig(calibrationRepo)
+	if err != nil {
+		return err
+	}
+	current := make(map[string]tt.Severity, len(configs))
+	for _, config := range configs {
+		current[config.Name] = config.Severity
+	}
+
+	stats := calibration.Aggregate(history)
+	suggestions := calibration.Suggest(stats, current, calibration.DefaultThresholds)
+	snippet := calibration.ConfigSnippet(suggestions)
+
+	if isJson {
+		d, err := json.Marshal(calibrationReport{
+			Repo:        history.Repo,
+			Runs:        len(history.Runs),
+			Rules:       stats,
+			Suggestions: suggestions,
+			Config:      snippet,
+		})
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(w, string(d))
+		return err
+	}
+
+	if len(stats) == 0 {
+		_, err := fmt.Fprintf(w, "no calibration data for %s, lint with -calibration to record some\n", history.Repo)
+		return err
+	}
+
+	fmt.Fprintf(w, "calibration report for %s (%d runs)\n\n", history.Repo, len(history.Runs))
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "RULE\tRUNS\tFOUND\tSUPPRESSED\tRATIO")
+	for _, s := range stats {
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%d\t%.0f%%\n", s.Rule, s.Runs, s.Found, s.Suppressed, s.Ratio*100)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	if snippet == "" {
+		_, err := fmt.Fprintln(w, "\nno rule is suppressed often enough to suggest a change")
+		return err
+	}
+	_, err = fmt.Fprintf(w, "\nsuggested configuration:\n\n%s", snippet)
+	return err
+}
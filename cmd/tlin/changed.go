@@ -0,0 +1,199 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	tt "github.com/gnolang/tlin/internal/types"
+)
+
+// diffStdin is the -diff-base value reading the diff from the standard input.
+const diffStdin = "-"
+
+// lineRange is a range of lines of a file, both ends included.
+type lineRange struct {
+	start, end int
+}
+
+// changedLines are the lines a diff adds or modifies, by absolute file path.
+type changedLines map[string][]lineRange
+
+// add marks a line as changed, extending the last range of the file when it
+// is the line after.
+func (c changedLines) add(file string, line int) {
+	ranges := c[file]
+	if n := len(ranges); n > 0 && ranges[n-1].end == line-1 {
+		ranges[n-1].end = line
+		return
+	}
+	c[file] = append(ranges, lineRange{line, line})
+}
+
+// contains reports whether an issue touches one of the changed lines.
+func (c changedLines) contains(issue tt.Issue) bool {
+	file, err := filepath.Abs(issue.Filename)
+	if err != nil {
+		return false
+	}
+	end := max(issue.End.Line, issue.Start.Line)
+	for _, r := range c[file] {
+		if issue.Start.Line <= r.end && end >= r.start {
+			return true
+		}
+	}
+	return false
+}
+
+// hunkHeader matches the header of a hunk, "@@ -l,s +l,s @@", where the
+// sizes default to 1.
+var hunkHeader = regexp.MustCompile(`^@@ -\d+(?:,(\d+))? \+(\d+)(?:,(\d+))? @@`)
+
+// parseUnifiedDiff reads the lines a unified diff adds to the files, with
+// the file names relative to dir. The names may have the a/ and b/ prefixes
+// of git, and the context lines are not changes.
+func parseUnifiedDiff(r io.Reader, dir string) (changedLines, error) {
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	changed := make(changedLines)
+	var (
+		file             string // empty for a removed file
+		line             int    // number of the next line of the new file
+		oldLeft, newLeft int    // lines of the hunk left to read
+		lineNumber       int
+	)
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		text := scanner.Text()
+		lineNumber++
+		if oldLeft > 0 || newLeft > 0 {
+			switch {
+			case strings.HasPrefix(text, "+"):
+				if file != "" {
+					changed.add(file, line)
+				}
+				line++
+				newLeft--
+			case strings.HasPrefix(text, "-"):
+				oldLeft--
+			case strings.HasPrefix(text, `\`):
+				// "\ No newline at end of file"
+			default:
+				// a context line, whose leading space some tools trim
+				line++
+				oldLeft--
+				newLeft--
+			}
+			continue
+		}
+
+		switch {
+		case strings.HasPrefix(text, "+++ "):
+			name, err := diffFileName(strings.TrimPrefix(text, "+++ "))
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", lineNumber, err)
+			}
+			file = ""
+			if name != "/dev/null" {
+				file = filepath.Join(absDir, filepath.FromSlash(strings.TrimPrefix(name, "b/")))
+			}
+		case strings.HasPrefix(text, "@@ "):
+			m := hunkHeader.FindStringSubmatch(text)
+			if m == nil {
+				return nil, fmt.Errorf("line %d: invalid hunk header %q", lineNumber, text)
+			}
+			line, _ = strconv.Atoi(m[2])
+			oldLeft, newLeft = hunkSize(m[1]), hunkSize(m[3])
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if oldLeft > 0 || newLeft > 0 {
+		return nil, fmt.Errorf("line %d: truncated hunk", lineNumber)
+	}
+	return changed, nil
+}
+
+// diffFileName returns the file name of a "+++" line, without the
+// timestamp diff may follow it with, and unquoted when git quoted it.
+func diffFileName(name string) (string, error) {
+	if strings.HasPrefix(name, `"`) {
+		end := strings.LastIndex(name, `"`)
+		unquoted, err := strconv.Unquote(name[:end+1])
+		if err != nil {
+			return "", fmt.Errorf("invalid file name %s", name)
+		}
+		return unquoted, nil
+	}
+	name, _, _ = strings.Cut(name, "\t")
+	return name, nil
+}
+
+func hunkSize(size string) int {
+	if size == "" {
+		return 1
+	}
+	n, _ := strconv.Atoi(size)
+	return n
+}
+
+// gitChangedLines returns the lines of the files of dir changed since a git
+// revision, in the working tree, staged or not, and in the commits since the
+// revision forked from HEAD. The untracked files are changed as a whole.
+func gitChangedLines(ctx context.Context, dir, ref string) (changedLines, error) {
+	repo := baseRevision{dir: dir, ref: ref}
+	out, err := repo.git(ctx, "merge-base", ref, "HEAD")
+	if err != nil {
+		return nil, err
+	}
+	base := strings.TrimSpace(string(out))
+	diff, err := repo.git(ctx, "diff", "--relative", "--no-color", "--no-ext-diff", "-U0", base, "--")
+	if err != nil {
+		return nil, err
+	}
+	changed, err := parseUnifiedDiff(bytes.NewReader(diff), dir)
+	if err != nil {
+		return nil, err
+	}
+
+	untracked, err := repo.git(ctx, "ls-files", "--others", "--exclude-standard", "-z")
+	if err != nil {
+		return nil, err
+	}
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range strings.Split(string(untracked), "\x00") {
+		if name != "" {
+			file := filepath.Join(absDir, filepath.FromSlash(name))
+			changed[file] = []lineRange{{1, math.MaxInt}}
+		}
+	}
+	return changed, nil
+}
+
+// changedLines returns the lines -changed or -diff-base restrict the issues
+// to.
+func (c Config) changedLines(ctx context.Context) (changedLines, error) {
+	switch c.DiffBase {
+	case "":
+		return gitChangedLines(ctx, ".", "HEAD")
+	case diffStdin:
+		return parseUnifiedDiff(os.Stdin, ".")
+	default:
+		return gitChangedLines(ctx, ".", c.DiffBase)
+	}
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"context"
+	"go/token"
+	"math"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tt "github.com/gnolang/tlin/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseUnifiedDiff(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	diff := `diff --git a/pkg/a.gno b/pkg/a.gno
+index 1111111..2222222 100644
+--- a/pkg/a.gno
++++ b/pkg/a.gno
+@@ -1,5 +1,6 @@
+ package a
+-
++// Package a is documented.
++
+ func f() {
+--- removed line that looks like a header
++++ added line that looks like a header
+ }
+@@ -20,0 +22,1 @@ func g() {
++	println()
+\ No newline at end of file
+diff --git a/pkg/gone.gno b/pkg/gone.gno
+--- a/pkg/gone.gno
++++ /dev/null
+@@ -1 +0,0 @@
+-package a
+--- pkg/b.gno	2024-01-01 00:00:00
++++ "pkg/b c.gno"	2024-01-01 00:00:01
+@@ -3 +3 @@
+-a
++b
+`
+	changed, err := parseUnifiedDiff(strings.NewReader(diff), dir)
+	require.NoError(t, err)
+	assert.Equal(t, changedLines{
+		filepath.Join(dir, "pkg", "a.gno"):   {{2, 3}, {5, 5}, {22, 22}},
+		filepath.Join(dir, "pkg", "b c.gno"): {{3, 3}},
+	}, changed)
+
+	_, err = parseUnifiedDiff(strings.NewReader("+++ b/a.gno\n@@ -1,3 +1,3 @@\n a\n"), dir)
+	assert.ErrorContains(t, err, "truncated hunk")
+	_, err = parseUnifiedDiff(strings.NewReader("+++ b/a.gno\n@@ -x +1 @@\n"), dir)
+	assert.ErrorContains(t, err, "line 2: invalid hunk header")
+}
+
+func TestChangedLinesContains(t *testing.T) {
+	t.Parallel()
+	file, err := filepath.Abs("a.gno")
+	require.NoError(t, err)
+	changed := changedLines{file: {{5, 7}, {10, 10}}}
+
+	tests := []struct {
+		filename   string
+		start, end int
+		want       bool
+	}{
+		{"a.gno", 5, 5, true},
+		{"a.gno", 7, 0, true},
+		{"a.gno", 3, 5, true},
+		{"a.gno", 8, 9, false},
+		{"a.gno", 8, 12, true},
+		{"a.gno", 11, 11, false},
+		{file, 10, 10, true},
+		{"b.gno", 5, 5, false},
+	}
+	for _, tc := range tests {
+		issue := tt.Issue{
+			Filename: tc.filename,
+			Start:    token.Position{Line: tc.start},
+			End:      token.Position{Line: tc.end},
+		}
+		assert.Equal(t, tc.want, changed.contains(issue), "%s:%d-%d", tc.filename, tc.start, tc.end)
+	}
+}
+
+func TestGitChangedLines(t *testing.T) {
+	t.Parallel()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	dir := gitRepo(t, map[string]string{
+		"pkg/a.gno": "package a\n\nfunc f() {}\n",
+		"pkg/b.gno": "package a\n",
+	})
+	writeFiles(t, dir, map[string]string{
+		"pkg/a.gno":   "package a\n\nfunc f() {}\n\nfunc g() {}\n",
+		"pkg/new.gno": "package a\n",
+	})
+	add := exec.Command("git", "add", "pkg/a.gno")
+	add.Dir = dir
+	out, err := add.CombinedOutput()
+	require.NoError(t, err, string(out))
+	require.NoError(t, os.Remove(filepath.Join(dir, "pkg", "b.gno")))
+
+	changed, err := gitChangedLines(context.Background(), dir, "HEAD")
+	require.NoError(t, err)
+	assert.Equal(t, changedLines{
+		filepath.Join(dir, "pkg", "a.gno"):   {{4, 5}},
+		filepath.Join(dir, "pkg", "new.gno"): {{1, math.MaxInt}},
+	}, changed)
+
+	// the paths are relative to the directory, not to the repository
+	changed, err = gitChangedLines(context.Background(), filepath.Join(dir, "pkg"), "HEAD")
+	require.NoError(t, err)
+	assert.Contains(t, changed, filepath.Join(dir, "pkg", "a.gno"))
+
+	_, err = gitChangedLines(context.Background(), dir, "unknown-ref")
+	assert.Error(t, err)
+}
@@ -0,0 +1,269 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/gnolang/tlin/formatter"
+	tt "github.com/gnolang/tlin/internal/types"
+	"github.com/gnolang/tlin/lint"
+	"go.uber.org/zap"
+)
+
+// baseRevision reads the files of a git revision without checking it out.
+type baseRevision struct {
+	// dir is the directory the git commands run in, which the paths are
+	// relative to.
+	dir string
+	ref string
+}
+
+func (r baseRevision) git(ctx context.Context, args ...string) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = r.dir
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("git %s: %w: %s", strings.Join(args, " "), err, strings.TrimSpace(stderr.String()))
+	}
+	return out, nil
+}
+
+// files lists the files of the revision under the given paths. A file path
+// lists the other files of its directory too, since package rules need to
+// see them.
+func (r baseRevision) files(ctx context.Context, paths []string) ([]string, error) {
+	var recursive, flat []string
+	for _, path := range paths {
+		info, err := os.Stat(filepath.Join(r.dir, path))
+		if err == nil && !info.IsDir() {
+			flat = append(flat, filepath.Dir(path)+string(filepath.Separator))
+		} else {
+			recursive = append(recursive, path)
+		}
+	}
+
+	seen := make(map[string]bool)
+	var files []string
+	list := func(args []string, paths []string) error {
+		if len(paths) == 0 {
+			return nil
+		}
+		args = append(append(args, r.ref, "--"), paths...)
+		out, err := r.git(ctx, args...)
+		if err != nil {
+			return err
+		}
+		for _, entry := range strings.Split(string(out), "\x00") {
+			// <mode> SP <type> SP <object> TAB <path>
+			meta, name, ok := strings.Cut(entry, "\t")
+			if !ok || !strings.Contains(meta, " blob ") || seen[name] {
+				continue
+			}
+			seen[name] = true
+			files = append(files, filepath.FromSlash(name))
+		}
+		return nil
+	}
+	if err := list([]string{"ls-tree", "-r", "-z"}, recursive); err != nil {
+		return nil, err
+	}
+	if err := list([]string{"ls-tree", "-z"}, flat); err != nil {
+		return nil, err
+	}
+	return files, nil
+}
+
+// materialize writes the files of the revision under the given paths into
+// root, keeping their relative paths.
+func (r baseRevision) materialize(ctx context.Context, paths []string, root string) error {
+	files, err := r.files(ctx, paths)
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		content, err := r.git(ctx, "show", r.ref+":./"+filepath.ToSlash(file))
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(root, file)
+		if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(target, content, 0o644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// runDifferentialLintProcess lints the paths in the working tree and in the
+// base revision, and only reports the issues the working tree introduces.
+// Issues are matched by fingerprint, so the issues of added or renamed files
+// are all new.
+func runDifferentialLintProcess(ctx context.Context, logger *zap.Logger, opts lint.Options, config Config, onReport func(*lint.Report)) {
+	report, err := lint.Run(ctx, opts)
+	if err != nil {
+		logger.Error("Error processing files", zap.Error(err))
+		os.Exit(1)
+	}
+	if onReport != nil {
+		onReport(report)
+	}
+	issues := report.Issues
+
+	baseIssues, err := lintBaseRevision(ctx, logger, baseRevision{dir: ".", ref: config.Base}, config)
+	if err != nil {
+		logger.Error("Error linting the base revision", zap.String("base", config.Base), zap.Error(err))
+		os.Exit(1)
+	}
+
+	for i := range issues {
+		if issues[i].Filename, err = relativePath(".", issues[i].Filename); err != nil {
+			logger.Error("Error comparing with the base revision", zap.Error(err))
+			os.Exit(1)
+		}
+	}
+	if err := lint.SetFingerprints(issues, "."); err != nil {
+		logger.Error("Error reading source file", zap.Error(err))
+		os.Exit(1)
+	}
+	regressions, fixed := compareIssues(baseIssues, issues)
+
+	printIssues(logger, formatter.Report{Issues: regressions}, config.Format, config.Output)
+
+	summary := os.Stdout
+	if config.Format != formatter.FormatText {
+		summary = os.Stderr
+	}
+	writeDifferentialSummary(summary, config.Base, len(regressions), fixed)
+
+	if failsRun(regressions, config.failOn()) {
+		os.Exit(1)
+	}
+}
+
+// lintBaseRevision lints the paths as they are in the base revision, with
+// the settings of the current run. The issues have the file names of the
+// working tree and their fingerprints set.
+func lintBaseRevision(ctx context.Context, logger *zap.Logger, base baseRevision, config Config) ([]tt.Issue, error) {
+	root, err := os.MkdirTemp("", "tlin-base-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(root)
+
+	paths := make([]string, 0, len(config.Paths))
+	for _, path := range config.Paths {
+		rel, err := relativePath(base.dir, path)
+		if err != nil {
+			return nil, err
+		}
+		paths = append(paths, rel)
+	}
+	if err := base.materialize(ctx, paths, root); err != nil {
+		return nil, err
+	}
+
+	// paths that are new in the working tree have nothing to compare with
+	var basePaths []string
+	for _, path := range paths {
+		if _, err := os.Stat(filepath.Join(root, path)); err == nil {
+			basePaths = append(basePaths, filepath.Join(root, path))
+		}
+	}
+	opts := config.runOptions(logger)
+	opts.Targets = basePaths
+	opts.RootDir = root
+	// the issues of the files of a temporary directory are not worth caching
+	opts.CacheDir = ""
+	report, err := lint.Run(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var kept []tt.Issue
+	for _, issue := range report.Issues {
+		rel, err := filepath.Rel(root, issue.Filename)
+		if err != nil || strings.HasPrefix(rel, "..") {
+			continue
+		}
+		// the paths ignored in the working tree are ignored in the base too
+		if matchesAny(config.ignoredPaths(), rel) {
+			continue
+		}
+		issue.Filename = rel
+		kept = append(kept, issue)
+	}
+	if err := lint.SetFingerprints(kept, root); err != nil {
+		return nil, err
+	}
+	return kept, nil
+}
+
+// relativePath returns path relative to dir, which it must be inside of.
+func relativePath(dir, path string) (string, error) {
+	if !filepath.IsAbs(path) {
+		return filepath.Clean(path), nil
+	}
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+	rel, err := filepath.Rel(absDir, path)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return "", fmt.Errorf("%s is outside of %s", path, absDir)
+	}
+	return rel, nil
+}
+
+func matchesAny(patterns []string, path string) bool {
+	for _, pattern := range patterns {
+		if ok, err := filepath.Match(pattern, path); err == nil && ok {
+			return true
+		}
+	}
+	return false
+}
+
+// compareIssues returns the current issues missing from the base, and the
+// number of base issues missing from the current ones.
+func compareIssues(base, current []tt.Issue) ([]tt.Issue, int) {
+	inBase := make(map[string]bool, len(base))
+	for _, issue := range base {
+		inBase[issue.Fingerprint] = true
+	}
+	inCurrent := make(map[string]bool, len(current))
+	var regressions []tt.Issue
+	for _, issue := range current {
+		inCurrent[issue.Fingerprint] = true
+		if !inBase[issue.Fingerprint] {
+			regressions = append(regressions, issue)
+		}
+	}
+	fixed := 0
+	for fingerprint := range inBase {
+		if !inCurrent[fingerprint] {
+			fixed++
+		}
+	}
+	return regressions, fixed
+}
+
+func writeDifferentialSummary(w io.Writer, base string, regressions, fixed int) {
+	fmt.Fprintf(w, "compared to %s: %d new %s, %d fixed\n", base, regressions, plural(regressions, "issue", "issues"), fixed)
+}
+
+func plural(n int, one, many string) string {
+	if n == 1 {
+		return one
+	}
+	return many
+}
@@ -0,0 +1,161 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	tt "github.com/gnolang/tlin/internal/types"
+	"github.com/gnolang/tlin/lint"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const uselessBreakSource = `package foo
+
+func f(x int) {
+	switch x {
+	case 1:
+		println("one")
+		break
+	}
+}
+`
+
+func gitRepo(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	run := func(args ...string) {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		out, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(out))
+	}
+	run("init", "-q")
+	writeFiles(t, dir, files)
+	run("add", "-A")
+	run("-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "-q", "-m", "base")
+	return dir
+}
+
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	}
+}
+
+func TestLintBaseRevision(t *testing.T) {
+	t.Parallel()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	dir := gitRepo(t, map[string]string{
+		"pkg/kept.gno":    uselessBreakSource,
+		"pkg/old.gno":     uselessBreakSource,
+		"pkg/deleted.gno": uselessBreakSource,
+	})
+
+	// the working tree shifts the kept issue down and adds one after it,
+	// renames a file and deletes another one
+	writeFiles(t, dir, map[string]string{
+		"pkg/kept.gno": "// Package foo is documented.\n" + uselessBreakSource + `
+func g(x int) {
+	switch x {
+	case 2:
+		break
+	}
+}
+`,
+		"pkg/renamed.gno": uselessBreakSource,
+	})
+	require.NoError(t, os.Remove(filepath.Join(dir, "pkg/old.gno")))
+	require.NoError(t, os.Remove(filepath.Join(dir, "pkg/deleted.gno")))
+
+	config := Config{Paths: []string{"pkg"}, Concurrency: 1}
+	base, err := lintBaseRevision(context.Background(), nil, baseRevision{dir: dir, ref: "HEAD"}, config)
+	require.NoError(t, err)
+	base = issuesOfRule(base, "useless-break")
+
+	var baseFiles []string
+	for _, issue := range base {
+		baseFiles = append(baseFiles, issue.Filename)
+		assert.NotEmpty(t, issue.Fingerprint)
+	}
+	sort.Strings(baseFiles)
+	assert.Equal(t, []string{
+		filepath.Join("pkg", "deleted.gno"),
+		filepath.Join("pkg", "kept.gno"),
+		filepath.Join("pkg", "old.gno"),
+	}, baseFiles)
+
+	engine, err := lint.New(dir, nil, "")
+	require.NoError(t, err)
+	current, err := lint.ProcessFiles(context.Background(), nil, engine, []string{filepath.Join(dir, "pkg")}, lint.ProcessFile)
+	require.NoError(t, err)
+	current = issuesOfRule(current, "useless-break")
+	for i := range current {
+		current[i].Filename, err = relativePath(dir, current[i].Filename)
+		require.NoError(t, err)
+	}
+	require.NoError(t, lint.SetFingerprints(current, dir))
+
+	regressions, fixed := compareIssues(base, current)
+	var found []string
+	for _, issue := range regressions {
+		found = append(found, fmt.Sprintf("%s:%d", issue.Filename, issue.Start.Line))
+	}
+	sort.Strings(found)
+	assert.Equal(t, []string{
+		filepath.Join("pkg", "kept.gno") + ":15",
+		filepath.Join("pkg", "renamed.gno") + ":7",
+	}, found)
+	assert.Equal(t, 2, fixed)
+}
+
+func TestLintBaseRevisionUnknownRef(t *testing.T) {
+	t.Parallel()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	dir := gitRepo(t, map[string]string{"foo.gno": uselessBreakSource})
+	config := Config{Paths: []string{"."}, Concurrency: 1}
+	_, err := lintBaseRevision(context.Background(), nil, baseRevision{dir: dir, ref: "missing"}, config)
+	assert.Error(t, err)
+}
+
+func TestCompareIssues(t *testing.T) {
+	t.Parallel()
+	issue := func(fingerprint string) tt.Issue {
+		return tt.Issue{Rule: "rule", Fingerprint: fingerprint}
+	}
+
+	regressions, fixed := compareIssues(
+		[]tt.Issue{issue("a"), issue("b"), issue("c")},
+		[]tt.Issue{issue("a"), issue("d")},
+	)
+	assert.Equal(t, []tt.Issue{issue("d")}, regressions)
+	assert.Equal(t, 2, fixed)
+
+	regressions, fixed = compareIssues(nil, []tt.Issue{issue("a")})
+	assert.Equal(t, []tt.Issue{issue("a")}, regressions)
+	assert.Equal(t, 0, fixed)
+}
+
+func issuesOfRule(issues []tt.Issue, rule string) []tt.Issue {
+	var kept []tt.Issue
+	for _, issue := range issues {
+		if issue.Rule == rule {
+			kept = append(kept, issue)
+		}
+	}
+	return kept
+}
@@ -0,0 +1,438 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/textproto"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+
+	"github.com/gnolang/tlin/internal"
+	"github.com/gnolang/tlin/internal/fixer"
+	tt "github.com/gnolang/tlin/internal/types"
+	"github.com/gnolang/tlin/lint"
+)
+
+const lspCommand = "lsp"
+
+// runLSPCommand runs `tlin lsp [-c config] [-confidence c] [-rules-dir dir]`,
+// a Language Server Protocol server on in and out for editors such as VS
+// Code and Neovim. It lints the .go and .gno files when they are opened
+// and saved, publishes their issues as diagnostics, and offers the fixes
+// of the issues as quick fix code actions.
+func runLSPCommand(in io.Reader, out io.Writer, args []string) error {
+	flagSet := flag.NewFlagSet(lspCommand, flag.ContinueOnError)
+	configPath := flagSet.String("c", "", "Path to the linter configuration file, by default the nearest .tlin.yaml up to the project root")
+	confidence := flagSet.Float64("confidence", defaultConfidenceThreshold, "Confidence threshold of the fixes offered as code actions (0.0 to 1.0)")
+	rulesDir := flagSet.String("rules-dir", "", "Directory of additional rule packs, such as the ones installed by tlin rules fetch")
+	if err := flagSet.Parse(args); err != nil {
+		return err
+	}
+	if flagSet.NArg() > 0 {
+		return errors.New("usage: tlin lsp [-c config] [-confidence c] [-rules-dir dir]")
+	}
+
+	if *rulesDir != "" {
+		if err := internal.LoadRulePack(*rulesDir); err != nil {
+			return err
+		}
+	}
+	if *configPath == "" {
+		if path, err := internal.FindConfigFile("."); err == nil {
+			*configPath = path
+		}
+	}
+	engine, err := lint.New(".", nil, *configPath)
+	if err != nil {
+		return fmt.Errorf("failed to initialize lint engine: %w", err)
+	}
+	return newLSPServer(engine, *confidence, out).serve(in)
+}
+
+// lspServer answers the messages of an editor, one at a time.
+type lspServer struct {
+	engine        lint.LintEngine
+	minConfidence float64
+	out           io.Writer
+	// issues are the diagnostics of the open documents, by URI
+	issues   map[string][]lspIssue
+	shutdown bool
+}
+
+// lspIssue is an issue as published, along with its fix if it has one.
+type lspIssue struct {
+	rule       string
+	diagnostic lspDiagnostic
+	fix        []lspTextEdit
+}
+
+func newLSPServer(engine lint.LintEngine, minConfidence float64, out io.Writer) *lspServer {
+	return &lspServer{
+		engine:        engine,
+		minConfidence: minConfidence,
+		out:           out,
+		issues:        make(map[string][]lspIssue),
+	}
+}
+
+// JSON-RPC error codes used by the server.
+const (
+	rpcParseError     = -32700
+	rpcMethodNotFound = -32601
+	rpcInvalidParams  = -32602
+	rpcRequestFailed  = -32803
+)
+
+// rpcMessage is a JSON-RPC request or notification, the latter without ID.
+type rpcMessage struct {
+	ID     json.RawMessage `json:"id,omitempty"`
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params,omitempty"`
+}
+
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *rpcError) Error() string { return e.Message }
+
+type (
+	lspPosition struct {
+		Line      int `json:"line"`
+		Character int `json:"character"` // in UTF-16 code units
+	}
+	lspRange struct {
+		Start lspPosition `json:"start"`
+		End   lspPosition `json:"end"`
+	}
+	lspDiagnostic struct {
+		Range    lspRange `json:"range"`
+		Severity int      `json:"severity"`
+		Code     string   `json:"code"`
+		Source   string   `json:"source"`
+		Message  string   `json:"message"`
+	}
+	lspTextEdit struct {
+		Range   lspRange `json:"range"`
+		NewText string   `json:"newText"`
+	}
+	lspCodeAction struct {
+		Title       string           `json:"title"`
+		Kind        string           `json:"kind"`
+		Diagnostics []lspDiagnostic  `json:"diagnostics"`
+		Edit        lspWorkspaceEdit `json:"edit"`
+	}
+	lspWorkspaceEdit struct {
+		Changes map[string][]lspTextEdit `json:"changes"`
+	}
+	lspDocument struct {
+		URI string `json:"uri"`
+	}
+)
+
+// serve answers the messages read from in until the editor exits.
+func (s *lspServer) serve(in io.Reader) error {
+	r := bufio.NewReader(in)
+	for {
+		data, err := readLSPMessage(r)
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		var msg rpcMessage
+		if err := json.Unmarshal(data, &msg); err != nil {
+			if err := s.reply(json.RawMessage("null"), nil, &rpcError{Code: rpcParseError, Message: err.Error()}); err != nil {
+				return err
+			}
+			continue
+		}
+		if msg.Method == "exit" {
+			if !s.shutdown {
+				return errors.New("the editor exited without shutting the server down")
+			}
+			return nil
+		}
+
+		result, err := s.handle(msg.Method, msg.Params)
+		if msg.ID == nil {
+			// notifications get no answer, their errors are logged
+			if err != nil {
+				err = s.notify("window/logMessage", map[string]any{"type": 1, "message": err.Error()})
+			}
+		} else {
+			err = s.reply(msg.ID, result, err)
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// handle runs a method and returns its result, for requests.
+func (s *lspServer) handle(method string, params json.RawMessage) (any, error) {
+	switch method {
+	case "initialize":
+		return map[string]any{
+			"capabilities": map[string]any{
+				"textDocumentSync":   map[string]any{"openClose": true, "save": true},
+				"codeActionProvider": map[string]any{"codeActionKinds": []string{"quickfix"}},
+			},
+			"serverInfo": map[string]any{"name": "tlin"},
+		}, nil
+	case "initialized":
+		return nil, nil
+	case "shutdown":
+		s.shutdown = true
+		return nil, nil
+	case "textDocument/didOpen", "textDocument/didSave":
+		var p struct {
+			TextDocument lspDocument `json:"textDocument"`
+		}
+		if err := json.Unmarshal(params, &p); err != nil {
+			return nil, &rpcError{Code: rpcInvalidParams, Message: err.Error()}
+		}
+		return nil, s.lint(p.TextDocument.URI)
+	case "textDocument/didClose":
+		var p struct {
+			TextDocument lspDocument `json:"textDocument"`
+		}
+		if err := json.Unmarshal(params, &p); err != nil {
+			return nil, &rpcError{Code: rpcInvalidParams, Message: err.Error()}
+		}
+		delete(s.issues, p.TextDocument.URI)
+		return nil, s.publish(p.TextDocument.URI, nil)
+	case "textDocument/codeAction":
+		var p struct {
+			TextDocument lspDocument `json:"textDocument"`
+			Range        lspRange    `json:"range"`
+		}
+		if err := json.Unmarshal(params, &p); err != nil {
+			return nil, &rpcError{Code: rpcInvalidParams, Message: err.Error()}
+		}
+		return s.codeActions(p.TextDocument.URI, p.Range), nil
+	}
+	if strings.HasPrefix(method, "$/") {
+		// optional notifications, such as $/cancelRequest
+		return nil, nil
+	}
+	return nil, &rpcError{Code: rpcMethodNotFound, Message: "unsupported method " + method}
+}
+
+// lint lints the saved content of a document and publishes its issues.
+func (s *lspServer) lint(uri string) error {
+	path, err := uriPath(uri)
+	if err != nil {
+		return err
+	}
+	if !strings.HasSuffix(path, ".go") && !strings.HasSuffix(path, ".gno") {
+		return nil
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	issues, err := lint.ProcessFileContext(context.Background(), s.engine, path)
+	if err != nil {
+		// the previous issues no longer apply
+		delete(s.issues, uri)
+		if publishErr := s.publish(uri, nil); publishErr != nil {
+			return publishErr
+		}
+		return fmt.Errorf("failed to lint %s: %w", path, err)
+	}
+
+	lines := strings.Split(string(content), "\n")
+	fix := fixer.New(false, s.minConfidence)
+	var published []lspIssue
+	for _, issue := range issues {
+		if filepath.Clean(issue.Filename) != filepath.Clean(path) {
+			continue
+		}
+		published = append(published, lspIssue{
+			rule:       issue.Rule,
+			diagnostic: issueDiagnostic(lines, issue),
+			fix:        issueFix(fix, lines, path, issue),
+		})
+	}
+	s.issues[uri] = published
+	return s.publish(uri, published)
+}
+
+// issueDiagnostic returns the diagnostic of an issue of the file of the lines.
+func issueDiagnostic(lines []string, issue tt.Issue) lspDiagnostic {
+	end := issue.End
+	if end.Line == 0 {
+		end = issue.Start
+	}
+	return lspDiagnostic{
+		Range: lspRange{
+			Start: lspPositionOf(lines, issue.Start.Line, issue.Start.Column),
+			End:   lspPositionOf(lines, end.Line, end.Column),
+		},
+		Severity: lspSeverity(issue.Severity),
+		Code:     issue.Rule,
+		Source:   "tlin",
+		Message:  issue.Message,
+	}
+}
+
+// issueFix returns the edits fixing an issue as -fix would, nil for the
+// issues without a fix or whose fix doesn't apply.
+func issueFix(fix *fixer.Fixer, lines []string, path string, issue tt.Issue) []lspTextEdit {
+	if len(issue.SuggestedFix) == 0 && issue.Suggestion == "" {
+		return nil
+	}
+	patch, err := fix.Patch(path, []tt.Issue{issue})
+	if err != nil {
+		return nil
+	}
+	var edits []lspTextEdit
+	for _, edit := range patch.Edits {
+		edits = append(edits, lspTextEdit{
+			Range: lspRange{
+				Start: lspPositionOf(lines, edit.StartLine, edit.StartColumn),
+				End:   lspPositionOf(lines, edit.EndLine, edit.EndColumn),
+			},
+			NewText: edit.Replacement,
+		})
+	}
+	return edits
+}
+
+// codeActions returns the fixes of the issues of a document overlapping
+// the range.
+func (s *lspServer) codeActions(uri string, r lspRange) []lspCodeAction {
+	actions := []lspCodeAction{}
+	for _, issue := range s.issues[uri] {
+		if issue.fix == nil || !rangesOverlap(issue.diagnostic.Range, r) {
+			continue
+		}
+		actions = append(actions, lspCodeAction{
+			Title:       fmt.Sprintf("Fix %s", issue.rule),
+			Kind:        "quickfix",
+			Diagnostics: []lspDiagnostic{issue.diagnostic},
+			Edit:        lspWorkspaceEdit{Changes: map[string][]lspTextEdit{uri: issue.fix}},
+		})
+	}
+	return actions
+}
+
+func (s *lspServer) publish(uri string, issues []lspIssue) error {
+	diagnostics := make([]lspDiagnostic, len(issues))
+	for i, issue := range issues {
+		diagnostics[i] = issue.diagnostic
+	}
+	return s.notify("textDocument/publishDiagnostics", map[string]any{
+		"uri":         uri,
+		"diagnostics": diagnostics,
+	})
+}
+
+func (s *lspServer) notify(method string, params any) error {
+	return writeLSPMessage(s.out, map[string]any{
+		"jsonrpc": "2.0",
+		"method":  method,
+		"params":  params,
+	})
+}
+
+// reply answers a request with its result, or with its error.
+func (s *lspServer) reply(id json.RawMessage, result any, err error) error {
+	msg := map[string]any{"jsonrpc": "2.0", "id": id}
+	if err == nil {
+		msg["result"] = result
+		return writeLSPMessage(s.out, msg)
+	}
+	var rpcErr *rpcError
+	if !errors.As(err, &rpcErr) {
+		rpcErr = &rpcError{Code: rpcRequestFailed, Message: err.Error()}
+	}
+	msg["error"] = rpcErr
+	return writeLSPMessage(s.out, msg)
+}
+
+// readLSPMessage reads the content of a message, framed by a header with
+// its Content-Length.
+func readLSPMessage(r *bufio.Reader) ([]byte, error) {
+	header, err := textproto.NewReader(r).ReadMIMEHeader()
+	if err != nil {
+		if errors.Is(err, io.EOF) && len(header) == 0 {
+			return nil, io.EOF
+		}
+		return nil, fmt.Errorf("invalid message header: %w", err)
+	}
+	length, err := strconv.Atoi(header.Get("Content-Length"))
+	if err != nil || length < 0 {
+		return nil, fmt.Errorf("invalid Content-Length %q", header.Get("Content-Length"))
+	}
+	data := make([]byte, length)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+func writeLSPMessage(w io.Writer, msg any) error {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "Content-Length: %d\r\n\r\n%s", len(data), data)
+	return err
+}
+
+// uriPath returns the path of a file URI.
+func uriPath(uri string) (string, error) {
+	u, err := url.Parse(uri)
+	if err != nil || u.Scheme != "file" {
+		return "", fmt.Errorf("unsupported document URI %q", uri)
+	}
+	return filepath.FromSlash(u.Path), nil
+}
+
+// lspPositionOf converts a line and a byte column, counted from 1, to the
+// position of the protocol, counted from 0 in UTF-16 code units.
+func lspPositionOf(lines []string, line, column int) lspPosition {
+	if line < 1 {
+		return lspPosition{}
+	}
+	if line > len(lines) {
+		return lspPosition{Line: len(lines)}
+	}
+	text := lines[line-1]
+	column = min(max(column-1, 0), len(text))
+	return lspPosition{Line: line - 1, Character: len(utf16.Encode([]rune(text[:column])))}
+}
+
+func lspSeverity(severity tt.Severity) int {
+	switch severity {
+	case tt.SeverityError:
+		return 1
+	case tt.SeverityWarning:
+		return 2
+	case tt.SeverityInfo:
+		return 3
+	}
+	return 4
+}
+
+func rangesOverlap(a, b lspRange) bool {
+	return !positionBefore(a.End, b.Start) && !positionBefore(b.End, a.Start)
+}
+
+func positionBefore(a, b lspPosition) bool {
+	return a.Line < b.Line || a.Line == b.Line && a.Character < b.Character
+}
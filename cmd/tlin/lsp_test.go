@@ -0,0 +1,172 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"go/token"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tt "github.com/gnolang/tlin/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// lspReply is a message of the server, as the tests read it.
+type lspReply struct {
+	ID     *int            `json:"id"`
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params"`
+	Result json.RawMessage `json:"result"`
+	Error  *rpcError       `json:"error"`
+}
+
+func TestLSPServer(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	file := filepath.Join(dir, "main.gno")
+	require.NoError(t, os.WriteFile(file, []byte(sliceRangeIssueExample), 0o644))
+	uri := (&url.URL{Scheme: "file", Path: filepath.ToSlash(file)}).String()
+	start := strings.Index(sliceRangeIssueExample, "slice[:len")
+	end := start + len("slice[:len(slice)]")
+	engine := setupMockEngine([]tt.Issue{
+		{
+			Rule:         "simplify-slice-range",
+			Filename:     file,
+			Message:      "unnecessary use of len() in slice expression, can be simplified",
+			Start:        token.Position{Line: 5, Column: 6, Offset: start},
+			End:          token.Position{Line: 5, Column: 24, Offset: end},
+			SuggestedFix: []tt.TextEdit{{Start: token.Position{Offset: start}, End: token.Position{Offset: end}, NewText: "slice[:]"}},
+			Confidence:   0.9,
+			Severity:     tt.SeverityWarning,
+		},
+		{
+			Rule:     "unfixable",
+			Filename: file,
+			Message:  "no fix",
+			Start:    token.Position{Line: 5, Column: 2},
+			Severity: tt.SeverityInfo,
+		},
+	}, file)
+
+	var in bytes.Buffer
+	document := map[string]any{"uri": uri}
+	for _, msg := range []map[string]any{
+		{"id": 1, "method": "initialize", "params": map[string]any{}},
+		{"method": "initialized", "params": map[string]any{}},
+		{"method": "textDocument/didOpen", "params": map[string]any{"textDocument": document}},
+		{"id": 2, "method": "textDocument/codeAction", "params": map[string]any{
+			"textDocument": document,
+			"range":        lspRange{Start: lspPosition{Line: 4, Character: 10}, End: lspPosition{Line: 4, Character: 10}},
+		}},
+		{"id": 3, "method": "textDocument/codeAction", "params": map[string]any{
+			"textDocument": document,
+			"range":        lspRange{Start: lspPosition{Line: 0}, End: lspPosition{Line: 1}},
+		}},
+		{"method": "textDocument/didClose", "params": map[string]any{"textDocument": document}},
+		{"id": 4, "method": "textDocument/hover", "params": map[string]any{}},
+		{"id": 5, "method": "shutdown"},
+		{"method": "exit"},
+	} {
+		msg["jsonrpc"] = "2.0"
+		require.NoError(t, writeLSPMessage(&in, msg))
+	}
+
+	var out bytes.Buffer
+	require.NoError(t, newLSPServer(engine, defaultConfidenceThreshold, &out).serve(&in))
+
+	var replies []lspReply
+	r := bufio.NewReader(&out)
+	for {
+		data, err := readLSPMessage(r)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		require.NoError(t, err)
+		var reply lspReply
+		require.NoError(t, json.Unmarshal(data, &reply))
+		replies = append(replies, reply)
+	}
+	require.Len(t, replies, 7)
+
+	assert.Equal(t, 1, *replies[0].ID)
+	assert.Contains(t, string(replies[0].Result), `"codeActionProvider"`)
+
+	assert.Equal(t, "textDocument/publishDiagnostics", replies[1].Method)
+	var published struct {
+		URI         string          `json:"uri"`
+		Diagnostics []lspDiagnostic `json:"diagnostics"`
+	}
+	require.NoError(t, json.Unmarshal(replies[1].Params, &published))
+	assert.Equal(t, uri, published.URI)
+	require.Len(t, published.Diagnostics, 2)
+	diagnostic := published.Diagnostics[0]
+	assert.Equal(t, lspDiagnostic{
+		Range:    lspRange{Start: lspPosition{Line: 4, Character: 5}, End: lspPosition{Line: 4, Character: 23}},
+		Severity: 2,
+		Code:     "simplify-slice-range",
+		Source:   "tlin",
+		Message:  "unnecessary use of len() in slice expression, can be simplified",
+	}, diagnostic)
+	assert.Equal(t, lspRange{Start: lspPosition{Line: 4, Character: 1}, End: lspPosition{Line: 4, Character: 1}}, published.Diagnostics[1].Range)
+
+	var actions []lspCodeAction
+	require.NoError(t, json.Unmarshal(replies[2].Result, &actions))
+	require.Len(t, actions, 1)
+	assert.Equal(t, "quickfix", actions[0].Kind)
+	assert.Equal(t, []lspDiagnostic{diagnostic}, actions[0].Diagnostics)
+	edits := actions[0].Edit.Changes[uri]
+	assert.Equal(t, []lspTextEdit{{Range: diagnostic.Range, NewText: "slice[:]"}}, edits)
+	assert.JSONEq(t, "[]", string(replies[3].Result), "no fix outside of the issues")
+
+	assert.Equal(t, "textDocument/publishDiagnostics", replies[4].Method)
+	assert.JSONEq(t, `{"uri": "`+uri+`", "diagnostics": []}`, string(replies[4].Params))
+	require.NotNil(t, replies[5].Error)
+	assert.Equal(t, rpcMethodNotFound, replies[5].Error.Code)
+	assert.Equal(t, 5, *replies[6].ID)
+	assert.JSONEq(t, "null", string(replies[6].Result))
+}
+
+func TestLSPServerExitWithoutShutdown(t *testing.T) {
+	t.Parallel()
+	var in, out bytes.Buffer
+	require.NoError(t, writeLSPMessage(&in, map[string]any{"jsonrpc": "2.0", "method": "exit"}))
+	assert.Error(t, newLSPServer(nil, 0, &out).serve(&in))
+
+	in.WriteString("Content-Length: x\r\n\r\n{}")
+	assert.ErrorContains(t, newLSPServer(nil, 0, &out).serve(&in), "invalid Content-Length")
+}
+
+func TestLSPPositionOf(t *testing.T) {
+	t.Parallel()
+	lines := []string{"package a", "var s = \"é😀\" + x"}
+	tests := []struct {
+		line, column int
+		want         lspPosition
+	}{
+		{1, 1, lspPosition{0, 0}},
+		{2, 9, lspPosition{1, 8}},
+		{2, 17, lspPosition{1, 13}}, // é is one code unit, 😀 two
+		{2, 100, lspPosition{1, 17}},
+		{0, 0, lspPosition{0, 0}},
+		{3, 1, lspPosition{2, 0}},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, lspPositionOf(lines, tt.line, tt.column), "%d:%d", tt.line, tt.column)
+	}
+}
+
+func TestUriPath(t *testing.T) {
+	t.Parallel()
+	path, err := uriPath("file:///home/gno/my%20realm/a.gno")
+	require.NoError(t, err)
+	assert.Equal(t, filepath.FromSlash("/home/gno/my realm/a.gno"), path)
+	_, err = uriPath("untitled:Untitled-1")
+	assert.Error(t, err)
+}
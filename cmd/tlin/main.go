@@ -8,8 +8,10 @@ import (
 	"go/ast"
 	"go/parser"
 	"go/token"
+	"io"
 	"os"
-	"sort"
+	"os/signal"
+	"runtime"
 	"strings"
 	"time"
 
@@ -19,8 +21,8 @@ import (
 	"github.com/gnolang/tlin/internal/fixer"
 	tt "github.com/gnolang/tlin/internal/types"
 	"github.com/gnolang/tlin/lint"
+	"github.com/mattn/go-isatty"
 	"go.uber.org/zap"
-	"gopkg.in/yaml.v3"
 )
 
 const (
@@ -28,6 +30,15 @@ const (
 	defaultConfidenceThreshold = 0.75
 )
 
+// fix export formats
+const (
+	exportJSON = "json"
+	exportDiff = "diff"
+)
+
+// cyclomaticRule is the rule run by -cyclo.
+const cyclomaticRule = "high-cyclomatic-complexity"
+
 type Config struct {
 	IgnoreRules          string
 	FuncName             string
@@ -42,15 +53,240 @@ type Config struct {
 	AutoFix              bool
 	DryRun               bool
 	JsonOutput           bool
+	Format               string
+	NDJSONOutput         bool
 	Init                 bool
 	IgnorePaths          string
+	PrintConfig          string
+	Doctor               bool
+	Concurrency          int
+	FileTimeout          time.Duration
+	RuleTimeout          time.Duration
+	MemoryLimit          int // in MiB
+	CPUProfile           string
+	MemProfile           string
+	Trace                string
+	Preset               string
+	ExportFixes          string
+	Progress             bool
+	Calibration          bool
+	CalibrationReport    bool
+	CalibrationReset     bool
+	Base                 string
+	ListRules            bool
+	Explain              string
+	RulesDir             string
+	ReportUnusedIgnores  bool
+	FailOn               string
+	NoCache              bool
+	Watch                bool
+	Changed              bool
+	DiffBase             string
+
+	// explicitFlags records the flags set on the command line,
+	// which take precedence over the configuration file.
+	explicitFlags map[string]bool
+}
+
+// processOptions returns the worker pool settings of the run.
+func (c Config) processOptions() lint.ProcessOptions {
+	opts := lint.ProcessOptions{
+		Concurrency: c.workers(),
+		FileTimeout: c.FileTimeout,
+		MemoryLimit: uint64(c.MemoryLimit) << 20,
+	}
+
+	// profiles only cover the analysis, not the file discovery or the output
+	prof := &profiler{cpuProfile: c.CPUProfile, memProfile: c.MemProfile, trace: c.Trace}
+	if prof.enabled() {
+		opts.BeforeAnalysis = prof.start
+		opts.AfterAnalysis = prof.stop
+	}
+	return opts
+}
+
+// workers returns the number of files analyzed in parallel, one per CPU
+// unless set.
+func (c Config) workers() int {
+	if c.Concurrency > 0 {
+		return c.Concurrency
+	}
+	return runtime.GOMAXPROCS(0)
+}
+
+// runOptions returns the options of the lint run shared by every mode.
+func (c Config) runOptions(logger *zap.Logger) lint.Options {
+	return lint.Options{
+		Targets:             c.Paths,
+		RootDir:             ".",
+		ConfigPath:          c.ConfigurationPath,
+		Preset:              c.Preset,
+		IgnoreRules:         c.ignoredRules(),
+		IgnorePaths:         c.ignoredPaths(),
+		MinConfidence:       c.ConfidenceThreshold,
+		Process:             c.processOptions(),
+		Logger:              logger,
+		Observer:            skipNotices{out: os.Stderr},
+		ReportUnusedIgnores: c.ReportUnusedIgnores,
+		CacheDir:            c.cacheDir(),
+		RuleTimeout:         c.RuleTimeout,
+	}
+}
+
+// failOn returns the least severity of the issues failing the run.
+func (c Config) failOn() tt.Severity {
+	severity, err := tt.ParseSeverity(strings.ToUpper(c.FailOn))
+	if err != nil {
+		return tt.SeverityHint
+	}
+	return severity
+}
+
+// failsRun reports whether any of the issues is severe enough to fail the run.
+func failsRun(issues []tt.Issue, failOn tt.Severity) bool {
+	for _, issue := range issues {
+		if issue.Severity.AtLeast(failOn) {
+			return true
+		}
+	}
+	return false
+}
+
+// ignoredRules returns the rules given to -ignore.
+func (c Config) ignoredRules() []string {
+	return splitList(c.IgnoreRules)
+}
+
+// ignoredPaths returns the path patterns given to -ignore-paths.
+func (c Config) ignoredPaths() []string {
+	return splitList(c.IgnorePaths)
+}
+
+func splitList(list string) []string {
+	if list == "" {
+		return nil
+	}
+	items := strings.Split(list, ",")
+	for i, item := range items {
+		items[i] = strings.TrimSpace(item)
+	}
+	return items
+}
+
+// discoverConfigFile uses the configuration file of the nearest directory,
+// up to the project root, unless a file was given with -c.
+func (c *Config) discoverConfigFile() {
+	if c.explicitFlags["c"] || c.Init {
+		return
+	}
+	if path, err := internal.FindConfigFile("."); err == nil && path != "" {
+		c.ConfigurationPath = path
+	}
+}
+
+// applyFileConfig fills the engine settings that were not given on the
+// command line from the configuration file.
+func (c *Config) applyFileConfig(fileConfig lint.Config) {
+	if !c.explicitFlags["concurrency"] && fileConfig.Concurrency > 0 {
+		c.Concurrency = fileConfig.Concurrency
+	}
+	if !c.explicitFlags["timeout"] && fileConfig.Timeout > 0 {
+		c.Timeout = fileConfig.Timeout
+	}
+	if !c.explicitFlags["file-timeout"] && fileConfig.FileTimeout > 0 {
+		c.FileTimeout = fileConfig.FileTimeout
+	}
+	if !c.explicitFlags["rule-timeout"] && fileConfig.RuleTimeout > 0 {
+		c.RuleTimeout = fileConfig.RuleTimeout
+	}
+	if !c.explicitFlags["memory-limit"] && fileConfig.MemoryLimit > 0 {
+		c.MemoryLimit = fileConfig.MemoryLimit
+	}
+	if !c.explicitFlags["fail-on"] && fileConfig.FailOn != "" {
+		c.FailOn = fileConfig.FailOn
+	}
 }
 
 func main() {
 	logger, _ := zap.NewProduction()
 	defer logger.Sync()
 
+	if len(os.Args) > 1 && os.Args[1] == cacheCommand {
+		if err := runCacheCommand(os.Stdout, os.Args[2:]); err != nil {
+			fmt.Println("error:", err)
+			os.Exit(1)
+		}
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == rulesCommand {
+		if err := runRulesCommand(os.Stdout, os.Args[2:]); err != nil {
+			fmt.Println("error:", err)
+			os.Exit(1)
+		}
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == lspCommand {
+		// stdout carries the protocol, errors go to stderr
+		if err := runLSPCommand(os.Stdin, os.Stdout, os.Args[2:]); err != nil {
+			fmt.Fprintln(os.Stderr, "error:", err)
+			os.Exit(1)
+		}
+		return
+	}
+	if len(os.Args) > 1 && (os.Args[1] == queryCommand || os.Args[1] == rewriteCommand) {
+		run := runQueryCommand
+		if os.Args[1] == rewriteCommand {
+			run = runRewriteCommand
+		}
+		found, err := run(os.Stdout, os.Args[2:])
+		if err != nil {
+			fmt.Println("error:", err)
+			os.Exit(2)
+		}
+		if !found {
+			os.Exit(1)
+		}
+		return
+	}
+
 	config := parseFlags(os.Args[1:])
+	config.discoverConfigFile()
+
+	if config.RulesDir != "" {
+		if err := internal.LoadRulePack(config.RulesDir); err != nil {
+			fmt.Println("error:", err)
+			os.Exit(1)
+		}
+	}
+
+	if config.Doctor {
+		if !runDoctor(config.ConfigurationPath) {
+			os.Exit(1)
+		}
+		return
+	}
+
+	if config.ListRules {
+		writeRuleList(os.Stdout, internal.Rules())
+		return
+	}
+	if config.Explain != "" {
+		rule, ok := internal.LookupRule(config.Explain)
+		if !ok {
+			fmt.Printf("error: unknown rule %q, run tlin -list-rules for the available rules\n", config.Explain)
+			os.Exit(1)
+		}
+		writeRuleExplanation(os.Stdout, rule)
+		return
+	}
+
+	if !config.Init {
+		fileConfig, err := lint.LoadConfig(config.ConfigurationPath)
+		if err != nil {
+			logger.Fatal("Failed to load configuration", zap.Error(err))
+		}
+		config.applyFileConfig(fileConfig)
+	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), config.Timeout)
 	defer cancel()
@@ -64,40 +300,88 @@ func main() {
 		return
 	}
 
-	engine, err := lint.New(".", nil, config.ConfigurationPath)
-	if err != nil {
-		logger.Fatal("Failed to initialize lint engine", zap.Error(err))
-	}
+	if config.PrintConfig != "" || config.CalibrationReport || config.CalibrationReset {
+		engine, err := lint.New(".", nil, config.ConfigurationPath)
+		if err != nil {
+			logger.Fatal("Failed to initialize lint engine", zap.Error(err))
+		}
+		if config.Preset != "" {
+			if err := engine.SetPreset(config.Preset); err != nil {
+				logger.Fatal("Failed to select preset", zap.Error(err))
+			}
+		}
 
-	if config.IgnoreRules != "" {
-		rules := strings.Split(config.IgnoreRules, ",")
-		for _, rule := range rules {
-			engine.IgnoreRule(strings.TrimSpace(rule))
+		if config.PrintConfig != "" {
+			if err := printEffectiveConfig(engine, config.PrintConfig); err != nil {
+				logger.Error("Error resolving configuration", zap.Error(err))
+				os.Exit(1)
+			}
+			return
+		}
+		if err := runCalibrationCommand(engine, config.CalibrationReset, config.JsonOutput); err != nil {
+			logger.Error("Error running calibration command", zap.Error(err))
+			os.Exit(1)
 		}
+		return
 	}
 
-	if config.IgnorePaths != "" {
-		paths := strings.Split(config.IgnorePaths, ",")
-		for _, path := range paths {
-			engine.IgnorePath(strings.TrimSpace(path))
+	opts := config.runOptions(logger)
+	if config.Watch {
+		// the watcher runs until interrupted, whatever the timeout
+		watchCtx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer stop()
+		if err := runWatchMode(watchCtx, opts, os.Stdout); err != nil {
+			logger.Error("Error watching files", zap.Error(err))
+			os.Exit(1)
 		}
+		return
+	}
+	if config.Changed || config.DiffBase != "" {
+		changed, err := config.changedLines(ctx)
+		if err != nil {
+			logger.Error("Error reading the changed lines", zap.Error(err))
+			os.Exit(1)
+		}
+		opts.Filter = changed.contains
 	}
-
 	if config.CFGAnalysis {
 		runWithTimeout(ctx, func() {
 			runCFGAnalysis(ctx, logger, config.Paths, config.FuncName, config.Output)
 		})
 	} else if config.CyclomaticComplexity {
 		runWithTimeout(ctx, func() {
-			runCyclomaticComplexityAnalysis(ctx, logger, config.Paths, config.CyclomaticThreshold, config.JsonOutput, config.Output)
+			runCyclomaticComplexityAnalysis(ctx, logger, opts, config.CyclomaticThreshold, config.Format, config.Output)
+		})
+	} else if config.ExportFixes != "" {
+		runWithTimeout(ctx, func() {
+			runExportFixes(ctx, logger, opts, config.ExportFixes, config.Output)
 		})
 	} else if config.AutoFix {
 		runWithTimeout(ctx, func() {
-			runAutoFix(ctx, logger, engine, config.Paths, config.DryRun, config.ConfidenceThreshold)
+			runAutoFix(ctx, logger, opts, config.DryRun)
 		})
 	} else {
+		if config.Progress && isatty.IsTerminal(os.Stderr.Fd()) {
+			opts.Observer = newProgress(os.Stderr)
+		}
+		var onReport func(*lint.Report)
+		if config.Calibration {
+			store, err := calibrationStore()
+			if err != nil {
+				logger.Fatal("Failed to enable calibration", zap.Error(err))
+			}
+			onReport = recordCalibration(logger, store)
+		}
 		runWithTimeout(ctx, func() {
-			runNormalLintProcess(ctx, logger, engine, config.Paths, config.JsonOutput, config.Output)
+			if config.Base != "" {
+				runDifferentialLintProcess(ctx, logger, opts, config, onReport)
+				return
+			}
+			if config.NDJSONOutput {
+				runStreamingLintProcess(ctx, logger, opts, config.Output, config.failOn(), onReport)
+				return
+			}
+			runNormalLintProcess(ctx, logger, opts, config.Format, config.Output, config.failOn(), onReport)
 		})
 	}
 }
@@ -116,10 +400,37 @@ func parseFlags(args []string) Config {
 	flagSet.BoolVar(&config.AutoFix, "fix", false, "Automatically fix issues")
 	flagSet.StringVar(&config.Output, "o", "", "Output path")
 	flagSet.BoolVar(&config.DryRun, "dry-run", false, "Run in dry-run mode (show fixes without applying them)")
-	flagSet.BoolVar(&config.JsonOutput, "json", false, "Output issues in JSON format")
+	flagSet.BoolVar(&config.JsonOutput, "json", false, "Output issues in JSON format, same as -format json")
+	flagSet.StringVar(&config.Format, "format", formatter.FormatText, "Output format of the issues: "+strings.Join(formatter.FormatNames(), ", "))
+	flagSet.BoolVar(&config.NDJSONOutput, "ndjson", false, "Stream issues as newline-delimited JSON while linting, followed by a summary line")
+	flagSet.BoolVar(&config.ReportUnusedIgnores, "report-unused-ignores", false, "Report the //tlin:ignore comments that suppressed no issue")
+	flagSet.BoolVar(&config.Watch, "watch", false, "Lint the paths again whenever one of their files changes, until interrupted")
+	flagSet.BoolVar(&config.NoCache, "no-cache", false, "Check every file again instead of reusing the issues of unchanged files from the result cache")
+	flagSet.StringVar(&config.FailOn, "fail-on", "hint", "Least severity of the issues failing the run: error, warning, info, hint")
 	flagSet.Float64Var(&config.ConfidenceThreshold, "confidence", defaultConfidenceThreshold, "Confidence threshold for auto-fixing (0.0 to 1.0)")
 	flagSet.BoolVar(&config.Init, "init", false, "Initialize a new linter configuration file")
-	flagSet.StringVar(&config.ConfigurationPath, "c", ".tlin.yaml", "Path to the linter configuration file")
+	flagSet.StringVar(&config.Preset, "preset", "", "Rule preset to start from, overriding the one in the configuration file: "+strings.Join(internal.PresetNames(), ", "))
+	flagSet.StringVar(&config.ConfigurationPath, "c", ".tlin.yaml", "Path to the linter configuration file, by default the nearest .tlin.yaml up to the project root")
+	flagSet.IntVar(&config.Concurrency, "concurrency", 0, "Number of files analyzed in parallel, 0 for one per CPU")
+	flagSet.DurationVar(&config.FileTimeout, "file-timeout", 0, "Maximum time spent on a single file, 0 for no limit. example: 30s")
+	flagSet.DurationVar(&config.RuleTimeout, "rule-timeout", 0, "Maximum time a rule spends on a file, 0 for no limit; the issues of the other rules are still reported")
+	flagSet.IntVar(&config.MemoryLimit, "memory-limit", 0, "Soft memory budget in MiB; once exceeded, files are analyzed one at a time. 0 for no limit")
+	flagSet.StringVar(&config.CPUProfile, "cpuprofile", "", "Write a CPU profile of the analysis to the given file")
+	flagSet.StringVar(&config.MemProfile, "memprofile", "", "Write a heap profile taken after the analysis to the given file")
+	flagSet.StringVar(&config.Trace, "trace", "", "Write an execution trace of the analysis to the given file")
+	flagSet.BoolVar(&config.Doctor, "doctor", false, "Check the environment and external tool integrations, then exit")
+	flagSet.StringVar(&config.PrintConfig, "print-config", "", "Print the effective configuration for the given file and exit")
+	flagSet.BoolVar(&config.ListRules, "list-rules", false, "List the rules with their default severity and options, then exit")
+	flagSet.StringVar(&config.RulesDir, "rules-dir", "", "Directory of additional rule packs, such as the ones installed by tlin rules fetch")
+	flagSet.StringVar(&config.Explain, "explain", "", "Describe the given rule and each of its options, then exit")
+	flagSet.BoolVar(&config.Progress, "progress", true, "Show the progress of the run on stderr when it is a terminal")
+	flagSet.BoolVar(&config.Calibration, "calibration", false, "Record how many issues of each rule are suppressed, in the local cache directory")
+	flagSet.BoolVar(&config.CalibrationReport, "calibration-report", false, "Report the rules suppressed most often with a suggested configuration, then exit")
+	flagSet.BoolVar(&config.CalibrationReset, "calibration-reset", false, "Delete the calibration data of the current directory, then exit")
+	flagSet.StringVar(&config.Base, "base", "", "Git revision to compare with, reporting only the issues that are not in it")
+	flagSet.BoolVar(&config.Changed, "changed", false, "Only report the issues on the lines changed since HEAD, staged or not, and in the untracked files")
+	flagSet.StringVar(&config.DiffBase, "diff-base", "", "Only report the issues on the lines changed since the given git revision forked, or in the unified diff read from stdin with -")
+	flagSet.StringVar(&config.ExportFixes, "export-fixes", "", "Print the available fixes without applying them, as json file patches or as a unified diff: json, diff")
 
 	err := flagSet.Parse(args)
 	if err != nil {
@@ -127,8 +438,61 @@ func parseFlags(args []string) Config {
 		os.Exit(1)
 	}
 
+	config.explicitFlags = make(map[string]bool)
+	flagSet.Visit(func(f *flag.Flag) {
+		config.explicitFlags[f.Name] = true
+	})
+
+	if config.ExportFixes != "" && config.ExportFixes != exportJSON && config.ExportFixes != exportDiff {
+		fmt.Printf("error: unknown fix export format %q, expected %s or %s\n", config.ExportFixes, exportJSON, exportDiff)
+		os.Exit(1)
+	}
+
+	if config.JsonOutput {
+		if config.explicitFlags["format"] && config.Format != formatter.FormatJSON {
+			fmt.Println("error: -json cannot be combined with -format", config.Format)
+			os.Exit(1)
+		}
+		config.Format = formatter.FormatJSON
+	}
+	if _, err := formatter.NewFormat(config.Format); err != nil {
+		fmt.Println("error:", err)
+		os.Exit(1)
+	}
+	config.JsonOutput = config.Format == formatter.FormatJSON
+
+	if severity, err := tt.ParseSeverity(strings.ToUpper(config.FailOn)); err != nil || severity == tt.SeverityOff {
+		fmt.Printf("error: invalid -fail-on severity %q, expected error, warning, info or hint\n", config.FailOn)
+		os.Exit(1)
+	}
+
+	if config.NDJSONOutput && config.explicitFlags["format"] {
+		fmt.Println("error: -ndjson cannot be combined with -format")
+		os.Exit(1)
+	}
+
+	if config.NDJSONOutput && config.Base != "" {
+		fmt.Println("error: -ndjson cannot be combined with -base")
+		os.Exit(1)
+	}
+
+	if config.Changed && config.DiffBase != "" {
+		fmt.Println("error: -changed cannot be combined with -diff-base")
+		os.Exit(1)
+	}
+
+	if config.Watch && (config.AutoFix || config.ExportFixes != "" || config.Base != "" || config.NDJSONOutput ||
+		config.CyclomaticComplexity || config.CFGAnalysis || config.Format != formatter.FormatText) {
+		fmt.Println("error: -watch prints the issues as text, and cannot be combined with -fix, -export-fixes, -base, -ndjson, -cyclo, -cfg or -format")
+		os.Exit(1)
+	}
+	if config.Watch && (config.Changed || config.DiffBase != "") {
+		fmt.Println("error: -watch cannot be combined with -changed or -diff-base")
+		os.Exit(1)
+	}
+
 	config.Paths = flagSet.Args()
-	if !config.Init && len(config.Paths) == 0 {
+	if !config.Init && !config.Doctor && config.PrintConfig == "" && !config.CalibrationReport && !config.CalibrationReset && !config.ListRules && config.Explain == "" && len(config.Paths) == 0 {
 		fmt.Println("error: Please provide file or directory paths")
 		os.Exit(1)
 	}
@@ -152,32 +516,54 @@ func runWithTimeout(ctx context.Context, f func()) {
 	}
 }
 
-func runNormalLintProcess(ctx context.Context, logger *zap.Logger, engine lint.LintEngine, paths []string, isJson bool, jsonOutput string) {
-	issues, err := lint.ProcessFiles(ctx, logger, engine, paths, lint.ProcessFile)
+// runNormalLintProcess lints the paths and prints the issues, failing when
+// one is at least as severe as failOn. onReport, when set, is called with
+// the report before the issues are printed.
+func runNormalLintProcess(ctx context.Context, logger *zap.Logger, opts lint.Options, format, output string, failOn tt.Severity, onReport func(*lint.Report)) {
+	report, err := lint.Run(ctx, opts)
 	if err != nil {
 		logger.Error("Error processing files", zap.Error(err))
 		os.Exit(1)
 	}
+	if onReport != nil {
+		onReport(report)
+	}
 
-	printIssues(logger, issues, isJson, jsonOutput)
+	out := formatter.Report{Issues: report.Issues}
+	if format != formatter.FormatText {
+		// the formats for tools carry the fixes of the issues
+		out.Fixes = previewFixes(logger, report.Issues, opts.MinConfidence)
+	}
+	printIssues(logger, out, format, output)
 
-	if len(issues) > 0 {
+	if failsRun(report.Issues, failOn) {
 		os.Exit(1)
 	}
 }
 
-func runCyclomaticComplexityAnalysis(ctx context.Context, logger *zap.Logger, paths []string, threshold int, isJson bool, jsonOutput string) {
-	issues, err := lint.ProcessFiles(ctx, logger, nil, paths, func(_ lint.LintEngine, path string) ([]tt.Issue, error) {
-		return lint.ProcessCyclomaticComplexity(path, threshold)
-	})
+// runCyclomaticComplexityAnalysis only runs the cyclomatic complexity rule,
+// with the threshold given on the command line.
+func runCyclomaticComplexityAnalysis(ctx context.Context, logger *zap.Logger, opts lint.Options, threshold int, format, output string) {
+	opts.Config = &lint.Config{
+		Rules: map[string]tt.ConfigRule{
+			cyclomaticRule: {
+				Severity: tt.SeverityError,
+				Data:     map[string]interface{}{"threshold": threshold},
+			},
+		},
+	}
+	opts.Preset = ""
+	opts.Only = []string{cyclomaticRule}
+
+	report, err := lint.Run(ctx, opts)
 	if err != nil {
 		logger.Error("Error processing files for cyclomatic complexity", zap.Error(err))
 		os.Exit(1)
 	}
 
-	printIssues(logger, issues, isJson, jsonOutput)
+	printIssues(logger, formatter.Report{Issues: report.Issues}, format, output)
 
-	if len(issues) > 0 {
+	if len(report.Issues) > 0 {
 		os.Exit(1)
 	}
 }
@@ -218,20 +604,88 @@ func runCFGAnalysis(_ context.Context, logger *zap.Logger, paths []string, funcN
 	}
 }
 
-func runAutoFix(ctx context.Context, logger *zap.Logger, engine lint.LintEngine, paths []string, dryRun bool, confidenceThreshold float64) {
-	fix := fixer.New(dryRun, confidenceThreshold)
+// runAutoFix fixes the issues of the paths, or only prints the fixes in
+// dry-run mode.
+func runAutoFix(ctx context.Context, logger *zap.Logger, opts lint.Options, dryRun bool) {
+	opts.Fix = lint.FixApply
+	if dryRun {
+		opts.Fix = lint.FixPreview
+	}
+	report, err := lint.Run(ctx, opts)
+	if err != nil {
+		logger.Error("Error processing files", zap.Error(err))
+		os.Exit(1)
+	}
 
-	for _, path := range paths {
-		issues, err := lint.ProcessPath(ctx, logger, engine, path, lint.ProcessFile)
-		if err != nil {
-			logger.Error("error processing path", zap.String("path", path), zap.Error(err))
+	issues := make(map[string]tt.Issue, len(report.Issues))
+	for _, issue := range report.Issues {
+		issues[issue.ID()] = issue
+	}
+	for _, patch := range report.Fixes {
+		for _, conflict := range patch.Conflicts {
+			fmt.Printf("Skipped fix of %s in %s at line %d: %s\n", conflict.Rule, patch.Filename, conflict.StartLine, conflict)
+		}
+		if !dryRun {
+			fmt.Printf("Fixed issues in %s\n", patch.Filename)
 			continue
 		}
+		seen := make(map[string]bool)
+		for _, edit := range patch.Edits {
+			// the edits of an issue are printed once
+			if seen[edit.IssueID] {
+				continue
+			}
+			seen[edit.IssueID] = true
+			issue := issues[edit.IssueID]
+			fmt.Printf("Would fix issue in %s at line %d: %s\n", patch.Filename, issue.Start.Line, issue.Message)
+			fmt.Printf("Suggestion:\n%s\n", issue.Suggestion)
+		}
+	}
+}
+
+// runExportFixes prints the fixes that runAutoFix would apply, as JSON file
+// patches or as a single unified diff, without modifying any file. Each edit
+// carries the ID of its issue in the JSON issue output.
+func runExportFixes(ctx context.Context, logger *zap.Logger, opts lint.Options, format, output string) {
+	opts.Fix = lint.FixPreview
+	report, err := lint.Run(ctx, opts)
+	if err != nil {
+		logger.Error("Error processing files", zap.Error(err))
+		os.Exit(1)
+	}
+	patches := report.Fixes
+	if patches == nil {
+		patches = []*fixer.Patch{}
+	}
 
-		err = fix.Fix(path, issues)
+	var d []byte
+	switch format {
+	case exportJSON:
+		d, err = json.Marshal(patches)
 		if err != nil {
-			logger.Error("error fixing issues", zap.String("path", path), zap.Error(err))
+			logger.Error("Error marshalling fixes to JSON", zap.Error(err))
+			return
 		}
+		d = append(d, '\n')
+	case exportDiff:
+		var diff strings.Builder
+		for _, patch := range patches {
+			text, err := patch.UnifiedDiff()
+			if err != nil {
+				logger.Error("error computing diff", zap.String("path", patch.Filename), zap.Error(err))
+				continue
+			}
+			diff.WriteString(text)
+		}
+		d = []byte(diff.String())
+	}
+
+	if output == "" {
+		fmt.Print(string(d))
+		return
+	}
+	if err := os.WriteFile(output, d, 0o644); err != nil {
+		logger.Error("Error writing fixes output file", zap.Error(err))
 	}
 }
 
@@ -240,74 +694,131 @@ func initConfigurationFile(configurationPath string) error {
 		configurationPath = ".tlin.yaml"
 	}
 
-	// Create a yaml file with rules
-	config := lint.Config{
-		Name:  "tlin",
-		Rules: map[string]tt.ConfigRule{},
+	// Create a yaml file listing every rule with its defaults
+	d := internal.GenerateDefaultConfig()
+
+	f, err := os.Create(configurationPath)
+	if err != nil {
+		return err
 	}
-	d, err := yaml.Marshal(config)
+
+	defer f.Close()
+
+	_, err = f.Write(d)
 	if err != nil {
 		return err
 	}
 
-	f, err := os.Create(configurationPath)
+	return nil
+}
+
+// runDoctor prints the result of every environment check and reports
+// whether all required integrations are usable.
+func runDoctor(configurationPath string) bool {
+	healthy := true
+	for _, check := range internal.RunChecks(configurationPath, true) {
+		status := "PASS"
+		if !check.OK {
+			status = "FAIL"
+			if !check.Required {
+				status = "WARN"
+			} else {
+				healthy = false
+			}
+		}
+
+		fmt.Printf("[%s] %s: %s\n", status, check.Name, check.Detail)
+		if !check.OK {
+			if len(check.Rules) > 0 {
+				fmt.Printf("       affected rules: %s\n", strings.Join(check.Rules, ", "))
+			}
+			if check.Hint != "" {
+				fmt.Printf("       hint: %s\n", check.Hint)
+			}
+		}
+	}
+	return healthy
+}
+
+// runCalibrationCommand resets or reports the calibration history of the
+// current directory.
+func runCalibrationCommand(engine *internal.Engine, reset, isJson bool) error {
+	store, err := calibrationStore()
 	if err != nil {
 		return err
 	}
+	if reset {
+		return store.Reset(calibrationRepo)
+	}
 
-	defer f.Close()
+	history, err := store.Load(calibrationRepo)
+	if err != nil {
+		return err
+	}
+	return writeCalibrationReport(os.Stdout, engine, history, isJson)
+}
 
-	_, err = f.Write(d)
+// printEffectiveConfig prints the merged configuration that applies to a file,
+// along with the configuration file each setting comes from.
+func printEffectiveConfig(engine *internal.Engine, filename string) error {
+	configs, err := engine.EffectiveConfig(filename)
 	if err != nil {
 		return err
 	}
 
+	fmt.Printf("effective configuration for %s:\n", filename)
+	for _, rule := range configs {
+		fmt.Printf("  %s:\n", rule.Name)
+		fmt.Printf("    severity: %s (from %s)\n", rule.Severity, rule.SeveritySource)
+		for _, opt := range rule.Options {
+			fmt.Printf("    %s: %v (from %s)\n", opt.Name, opt.Value, opt.Source)
+		}
+	}
 	return nil
 }
 
-func printIssues(logger *zap.Logger, issues []tt.Issue, isJson bool, jsonOutput string) {
-	issuesByFile := make(map[string][]tt.Issue)
+// previewFixes computes the fixes -fix would apply for the issues, file by
+// file. The files whose fixes cannot be computed are left without any.
+func previewFixes(logger *zap.Logger, issues []tt.Issue, minConfidence float64) []*fixer.Patch {
+	byFile := make(map[string][]tt.Issue)
 	for _, issue := range issues {
-		issuesByFile[issue.Filename] = append(issuesByFile[issue.Filename], issue)
+		byFile[issue.Filename] = append(byFile[issue.Filename], issue)
 	}
 
-	sortedFiles := make([]string, 0, len(issuesByFile))
-	for filename := range issuesByFile {
-		sortedFiles = append(sortedFiles, filename)
+	fix := fixer.New(false, minConfidence)
+	var patches []*fixer.Patch
+	for filename, fileIssues := range byFile {
+		patch, err := fix.Patch(filename, fileIssues)
+		if err != nil {
+			logger.Warn("Error computing fixes", zap.String("file", filename), zap.Error(err))
+			continue
+		}
+		patches = append(patches, patch)
 	}
-	sort.Strings(sortedFiles)
+	return patches
+}
 
-	if !isJson {
-		for _, filename := range sortedFiles {
-			fileIssues := issuesByFile[filename]
-			sourceCode, err := internal.ReadSourceCode(filename)
-			if err != nil {
-				logger.Error("Error reading source file", zap.String("file", filename), zap.Error(err))
-				continue
-			}
-			output := formatter.GenerateFormattedIssue(fileIssues, sourceCode)
-			fmt.Println(output)
-		}
-	} else {
-		d, err := json.Marshal(issuesByFile)
+// printIssues prints the issues in the given output format, to the output
+// file for the formats meant for tools, if any. The fingerprints of the
+// issues are expected to be set, as lint.Run does.
+func printIssues(logger *zap.Logger, report formatter.Report, format, output string) {
+	f, err := formatter.NewFormat(format)
+	if err != nil {
+		logger.Error("Error printing issues", zap.Error(err))
+		return
+	}
+
+	w := io.Writer(os.Stdout)
+	if output != "" && format != formatter.FormatText {
+		file, err := os.Create(output)
 		if err != nil {
-			logger.Error("Error marshalling issues to JSON", zap.Error(err))
+			logger.Error("Error creating output file", zap.Error(err))
 			return
 		}
-		if jsonOutput == "" {
-			fmt.Println(string(d))
-		} else {
-			f, err := os.Create(jsonOutput)
-			if err != nil {
-				logger.Error("Error creating JSON output file", zap.Error(err))
-				return
-			}
-			defer f.Close()
-			_, err = f.Write(d)
-			if err != nil {
-				logger.Error("Error writing JSON output file", zap.Error(err))
-				return
-			}
-		}
+		defer file.Close()
+		w = file
+	}
+	if err := f.Write(w, report); err != nil {
+		logger.Error("Error printing issues", zap.Error(err))
 	}
 }
@@ -10,11 +10,16 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"runtime"
 	"strings"
 	"sync"
 	"testing"
 	"time"
 
+	"github.com/gnolang/tlin/formatter"
+	"github.com/gnolang/tlin/internal"
+	"github.com/gnolang/tlin/internal/calibration"
+	"github.com/gnolang/tlin/internal/fixer"
 	tt "github.com/gnolang/tlin/internal/types"
 	"github.com/gnolang/tlin/lint"
 	"github.com/stretchr/testify/assert"
@@ -51,6 +56,12 @@ func setupMockEngine(expectedIssues []tt.Issue, filePath string) *mockLintEngine
 	return mockEngine
 }
 
+// testOptions returns the options of a run of the engine over the paths,
+// fixing the issues whose confidence is at least 0.8.
+func testOptions(engine lint.LintEngine, paths ...string) lint.Options {
+	return lint.Options{Targets: paths, Engine: engine, MinConfidence: 0.8}
+}
+
 func TestParseFlags(t *testing.T) {
 	t.Parallel()
 	tests := []struct {
@@ -109,6 +120,46 @@ func TestParseFlags(t *testing.T) {
 				ConfigurationPath:   ".tlin.yaml",
 			},
 		},
+		{
+			name: "Print Config",
+			args: []string{"-print-config", "file.go"},
+			expected: Config{
+				PrintConfig:         "file.go",
+				Paths:               []string{},
+				ConfidenceThreshold: defaultConfidenceThreshold,
+				ConfigurationPath:   ".tlin.yaml",
+			},
+		},
+		{
+			name: "Doctor",
+			args: []string{"-doctor"},
+			expected: Config{
+				Doctor:              true,
+				Paths:               []string{},
+				ConfidenceThreshold: defaultConfidenceThreshold,
+				ConfigurationPath:   ".tlin.yaml",
+			},
+		},
+		{
+			name: "Export Fixes",
+			args: []string{"-export-fixes", "diff", "file.go"},
+			expected: Config{
+				ExportFixes:         "diff",
+				Paths:               []string{"file.go"},
+				ConfidenceThreshold: defaultConfidenceThreshold,
+				ConfigurationPath:   ".tlin.yaml",
+			},
+		},
+		{
+			name: "Watch",
+			args: []string{"-watch", "dir"},
+			expected: Config{
+				Watch:               true,
+				Paths:               []string{"dir"},
+				ConfidenceThreshold: defaultConfidenceThreshold,
+				ConfigurationPath:   ".tlin.yaml",
+			},
+		},
 		{
 			name: "Configuration File",
 			args: []string{"-c", "config.yaml", "file.go"},
@@ -133,6 +184,9 @@ func TestParseFlags(t *testing.T) {
 			assert.Equal(t, tt.expected.JsonOutput, config.JsonOutput)
 			assert.Equal(t, tt.expected.Output, config.Output)
 			assert.Equal(t, tt.expected.ConfigurationPath, config.ConfigurationPath)
+			assert.Equal(t, tt.expected.PrintConfig, config.PrintConfig)
+			assert.Equal(t, tt.expected.ExportFixes, config.ExportFixes)
+			assert.Equal(t, tt.expected.Watch, config.Watch)
 		})
 	}
 }
@@ -175,14 +229,79 @@ func TestInitConfigurationFile(t *testing.T) {
 	content, err := os.ReadFile(configPath)
 	assert.NoError(t, err)
 
-	expectedConfig := lint.Config{
-		Name:  "tlin",
-		Rules: map[string]tt.ConfigRule{},
-	}
+	assert.NoError(t, internal.ValidateConfig(content))
+
 	config := &lint.Config{}
-	yaml.Unmarshal(content, config)
+	err = yaml.Unmarshal(content, config)
+	assert.NoError(t, err)
 
-	assert.Equal(t, expectedConfig, *config)
+	assert.Equal(t, "tlin", config.Name)
+	assert.Contains(t, config.Rules, "useless-break")
+	assert.Equal(t, tt.SeverityError, config.Rules["useless-break"].Severity)
+	assert.Equal(t, tt.SeverityOff, config.Rules["high-cyclomatic-complexity"].Severity)
+}
+
+func TestPrintEffectiveConfig(t *testing.T) {
+	t.Parallel()
+	tempDir, err := os.MkdirTemp("", "print-config-test")
+	assert.NoError(t, err)
+	defer os.RemoveAll(tempDir)
+
+	nestedConfig := filepath.Join(tempDir, "p", ".tlin.yaml")
+	assert.NoError(t, os.MkdirAll(filepath.Dir(nestedConfig), 0o755))
+	err = os.WriteFile(nestedConfig, []byte("rules:\n  high-cyclomatic-complexity:\n    severity: ERROR\n"), 0o644)
+	assert.NoError(t, err)
+
+	engine, err := lint.New(tempDir, nil, filepath.Join(tempDir, ".tlin.yaml"))
+	assert.NoError(t, err)
+
+	output := captureOutput(t, func() {
+		err = printEffectiveConfig(engine, filepath.Join(tempDir, "p", "lib.gno"))
+	})
+	assert.NoError(t, err)
+
+	assert.Contains(t, output, "high-cyclomatic-complexity:\n    severity: ERROR (from "+nestedConfig+")")
+	assert.Contains(t, output, "threshold: 10 (from default)")
+	assert.Contains(t, output, "useless-break:\n    severity: ERROR (from default)")
+}
+
+func TestWriteCalibrationReport(t *testing.T) {
+	t.Parallel()
+	tempDir := t.TempDir()
+
+	engine, err := lint.New(tempDir, nil, filepath.Join(tempDir, ".tlin.yaml"))
+	assert.NoError(t, err)
+
+	history := calibration.History{Repo: "/repo"}
+	for i := 0; i < 4; i++ {
+		history.Runs = append(history.Runs, calibration.Run{Rules: map[string]calibration.Counts{
+			"useless-break":        {Found: 5, Suppressed: 5},
+			"simplify-slice-range": {Found: 5, Suppressed: 1},
+		}})
+	}
+
+	var out bytes.Buffer
+	assert.NoError(t, writeCalibrationReport(&out, engine, history, false))
+	assert.Contains(t, out.String(), "calibration report for /repo (4 runs)")
+	assert.Regexp(t, `useless-break\s+4\s+20\s+20\s+100%`, out.String())
+	assert.Regexp(t, `simplify-slice-range\s+4\s+20\s+4\s+20%`, out.String())
+	assert.Contains(t, out.String(), "suggested configuration:\n\nrules:\n  # 20 of 20 issues suppressed (100%), was ERROR\n  useless-break:\n    severity: OFF\n")
+	assert.NotContains(t, out.String(), "  simplify-slice-range:\n")
+
+	out.Reset()
+	assert.NoError(t, writeCalibrationReport(&out, engine, history, true))
+	var report calibrationReport
+	assert.NoError(t, json.Unmarshal(out.Bytes(), &report))
+	assert.Equal(t, 4, report.Runs)
+	assert.Len(t, report.Rules, 2)
+	if assert.Len(t, report.Suggestions, 1) {
+		assert.Equal(t, "useless-break", report.Suggestions[0].Rule)
+	}
+	assert.Contains(t, out.String(), `"current":"ERROR","suggested":"OFF"`)
+
+	out.Reset()
+	assert.NoError(t, writeCalibrationReport(&out, engine, calibration.History{Repo: "/repo"}, false))
+	assert.Equal(t, "no calibration data for /repo, lint with -calibration to record some\n", out.String())
 }
 
 func TestRunCFGAnalysis(t *testing.T) {
@@ -272,7 +391,7 @@ func TestRunAutoFix(t *testing.T) {
 	mockEngine := setupMockEngine(expectedIssues, testFile)
 
 	output := captureOutput(t, func() {
-		runAutoFix(ctx, logger, mockEngine, []string{testFile}, false, 0.8)
+		runAutoFix(ctx, logger, testOptions(mockEngine, testFile), false)
 	})
 
 	content, err := os.ReadFile(testFile)
@@ -293,7 +412,7 @@ func main() {
 	assert.NoError(t, err)
 
 	output = captureOutput(t, func() {
-		runAutoFix(ctx, logger, mockEngine, []string{testFile}, true, 0.8)
+		runAutoFix(ctx, logger, testOptions(mockEngine, testFile), true)
 	})
 
 	content, err = os.ReadFile(testFile)
@@ -302,6 +421,64 @@ func main() {
 	assert.Contains(t, output, "Would fix issue in")
 }
 
+func TestRunExportFixes(t *testing.T) {
+	logger, _ := zap.NewProduction()
+	ctx := context.Background()
+
+	tempDir := t.TempDir()
+	testFile := filepath.Join(tempDir, "test.go")
+	err := os.WriteFile(testFile, []byte(sliceRangeIssueExample), 0o644)
+	assert.NoError(t, err)
+
+	expectedIssues := []tt.Issue{
+		{
+			Rule:       "simplify-slice-range",
+			Filename:   testFile,
+			Message:    "unnecessary use of len() in slice expression, can be simplified",
+			Start:      token.Position{Line: 5, Column: 5},
+			End:        token.Position{Line: 5, Column: 24},
+			Suggestion: "_ = slice[:]",
+			Confidence: 0.9,
+		},
+	}
+	mockEngine := setupMockEngine(expectedIssues, testFile)
+
+	output := captureOutput(t, func() {
+		runExportFixes(ctx, logger, testOptions(mockEngine, testFile), exportJSON, "")
+	})
+
+	var patches []struct {
+		File  string       `json:"file"`
+		Edits []fixer.Edit `json:"edits"`
+	}
+	assert.NoError(t, json.Unmarshal([]byte(output), &patches))
+	assert.Len(t, patches, 1)
+	assert.Equal(t, testFile, patches[0].File)
+	assert.Len(t, patches[0].Edits, 1)
+	edit := patches[0].Edits[0]
+	assert.Equal(t, "simplify-slice-range", edit.Rule)
+	assert.Equal(t, 5, edit.StartLine)
+	assert.Equal(t, "_ = slice[:]", edit.Replacement)
+
+	// the edit refers to the issue as printed by the JSON issue output
+	issueJSON := captureOutput(t, func() {
+		printIssues(logger, formatter.Report{Issues: expectedIssues}, formatter.FormatJSON, "")
+	})
+	var printed formatter.JSONDocument
+	assert.NoError(t, json.Unmarshal([]byte(issueJSON), &printed))
+	assert.Equal(t, edit.IssueID, printed.Issues[0].ID)
+
+	output = captureOutput(t, func() {
+		runExportFixes(ctx, logger, testOptions(mockEngine, testFile), exportDiff, "")
+	})
+	assert.Contains(t, output, "--- "+testFile)
+	assert.Contains(t, output, "-\t_ = slice[:len(slice)]\n+\t_ = slice[:]\n")
+
+	content, err := os.ReadFile(testFile)
+	assert.NoError(t, err)
+	assert.Equal(t, sliceRangeIssueExample, string(content))
+}
+
 func TestRunJsonOutput(t *testing.T) {
 	if os.Getenv("BE_CRASHER") != "1" {
 		cmd := exec.Command(os.Args[0], "-test.run=TestRunJsonOutput")
@@ -316,15 +493,14 @@ func TestRunJsonOutput(t *testing.T) {
 			content, err := os.ReadFile(jsonOutput)
 			assert.NoError(t, err)
 
-			var actualContent map[string][]tt.Issue
+			var actualContent formatter.JSONDocument
 			err = json.Unmarshal(content, &actualContent)
 			assert.NoError(t, err)
 
-			assert.Len(t, actualContent, 1)
-			for filename, issues := range actualContent {
-				assert.True(t, strings.HasSuffix(filename, "test.go"))
-				assert.Len(t, issues, 1)
-				issue := issues[0]
+			assert.Equal(t, formatter.JSONSchemaVersion, actualContent.Version)
+			assert.Len(t, actualContent.Issues, 1)
+			for _, issue := range actualContent.Issues {
+				assert.True(t, strings.HasSuffix(issue.File, "test.go"))
 				assert.Equal(t, "simplify-slice-range", issue.Rule)
 				assert.Equal(t, "unnecessary use of len() in slice expression, can be simplified", issue.Message)
 				assert.Equal(t, "_ = slice[:]", issue.Suggestion)
@@ -333,7 +509,14 @@ func TestRunJsonOutput(t *testing.T) {
 				assert.Equal(t, 5, issue.Start.Column)
 				assert.Equal(t, 5, issue.End.Line)
 				assert.Equal(t, 24, issue.End.Column)
-				assert.Equal(t, tt.SeverityError, issue.Severity)
+				assert.Equal(t, "ERROR", issue.Severity)
+				// the fix -fix would apply
+				if assert.NotNil(t, issue.Fix) && assert.Len(t, issue.Fix.Edits, 1) {
+					edit := issue.Fix.Edits[0]
+					assert.Equal(t, formatter.JSONPosition{Line: 5, Column: 1, Offset: 53}, edit.Start)
+					assert.Equal(t, 5, edit.End.Line)
+					assert.Equal(t, "_ = slice[:]", edit.Replacement)
+				}
 			}
 
 			return
@@ -367,7 +550,109 @@ func TestRunJsonOutput(t *testing.T) {
 	mockEngine := setupMockEngine(expectedIssues, testFile)
 
 	jsonOutput := filepath.Join(tempDir, "output.json")
-	runNormalLintProcess(ctx, logger, mockEngine, []string{testFile}, true, jsonOutput)
+	runNormalLintProcess(ctx, logger, testOptions(mockEngine, testFile), formatter.FormatJSON, jsonOutput, tt.SeverityHint, nil)
+}
+
+func TestConcurrencyOutputIsIdentical(t *testing.T) {
+	logger, _ := zap.NewProduction()
+	ctx := context.Background()
+
+	tempDir, err := os.MkdirTemp("", "concurrency-test")
+	assert.NoError(t, err)
+	defer os.RemoveAll(tempDir)
+
+	for i := 0; i < 12; i++ {
+		dir := filepath.Join(tempDir, fmt.Sprintf("pkg%d", i%3))
+		assert.NoError(t, os.MkdirAll(dir, 0o755))
+		err := os.WriteFile(filepath.Join(dir, fmt.Sprintf("file%d.go", i)), []byte(concurrencyExample), 0o644)
+		assert.NoError(t, err)
+	}
+
+	engine, err := lint.New(tempDir, nil, filepath.Join(tempDir, ".tlin.yaml"))
+	assert.NoError(t, err)
+
+	render := func(concurrency int) (string, string) {
+		issues, err := lint.ProcessFilesWithOptions(ctx, logger, engine, []string{tempDir}, lint.ProcessFile, lint.ProcessOptions{Concurrency: concurrency})
+		assert.NoError(t, err)
+		assert.NotEmpty(t, issues)
+
+		text := captureOutput(t, func() {
+			printIssues(logger, formatter.Report{Issues: issues}, formatter.FormatText, "")
+		})
+		jsonText := captureOutput(t, func() {
+			printIssues(logger, formatter.Report{Issues: issues}, formatter.FormatJSON, "")
+		})
+		return text, jsonText
+	}
+
+	sequentialText, sequentialJSON := render(1)
+	parallelText, parallelJSON := render(8)
+
+	assert.Equal(t, sequentialText, parallelText)
+	assert.Equal(t, sequentialJSON, parallelJSON)
+}
+
+const concurrencyExample = `package main
+
+func main() {
+	slice := []int{1, 2, 3}
+	_ = slice[:len(slice)]
+
+	for i := 0; i < 3; i++ {
+		defer println(i)
+	}
+
+	switch len(slice) {
+	case 3:
+		println("three")
+		break
+	}
+}
+`
+
+func TestApplyFileConfig(t *testing.T) {
+	t.Parallel()
+
+	fileConfig := lint.Config{
+		Concurrency: 4,
+		Timeout:     time.Minute,
+		FileTimeout: 10 * time.Second,
+		RuleTimeout: 2 * time.Second,
+		MemoryLimit: 512,
+		FailOn:      "WARNING",
+	}
+
+	config := parseFlags([]string{"-concurrency", "2", "file.go"})
+	config.applyFileConfig(fileConfig)
+	assert.Equal(t, tt.SeverityWarning, config.failOn())
+
+	assert.Equal(t, 2, config.Concurrency)
+	assert.Equal(t, time.Minute, config.Timeout)
+	assert.Equal(t, 10*time.Second, config.FileTimeout)
+	assert.Equal(t, 2*time.Second, config.runOptions(nil).RuleTimeout)
+	assert.Equal(t, 512, config.MemoryLimit)
+	assert.Equal(t, uint64(512<<20), config.processOptions().MemoryLimit)
+
+	config = parseFlags([]string{"file.go"})
+	assert.Equal(t, 0, config.Concurrency)
+	assert.Equal(t, runtime.GOMAXPROCS(0), config.processOptions().Concurrency)
+	assert.Equal(t, defaultTimeout, config.Timeout)
+	assert.Equal(t, tt.SeverityHint, config.failOn())
+
+	// the command line wins over the configuration file
+	config = parseFlags([]string{"-fail-on", "error", "file.go"})
+	config.applyFileConfig(fileConfig)
+	assert.Equal(t, tt.SeverityError, config.failOn())
+}
+
+func TestFailsRun(t *testing.T) {
+	t.Parallel()
+
+	issues := []tt.Issue{{Severity: tt.SeverityInfo}, {Severity: tt.SeverityWarning}}
+	assert.True(t, failsRun(issues, tt.SeverityHint))
+	assert.True(t, failsRun(issues, tt.SeverityWarning))
+	assert.False(t, failsRun(issues, tt.SeverityError))
+	assert.False(t, failsRun(nil, tt.SeverityHint))
 }
 
 func createTempFileWithContent(t *testing.T, content string) string {
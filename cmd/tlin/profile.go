@@ -0,0 +1,98 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"runtime"
+	"runtime/pprof"
+	"runtime/trace"
+)
+
+// profiler writes the CPU, memory and execution trace profiles requested on
+// the command line. Empty paths disable the corresponding profile.
+type profiler struct {
+	cpuProfile string
+	memProfile string
+	trace      string
+
+	cpuFile   *os.File
+	traceFile *os.File
+}
+
+func (p *profiler) enabled() bool {
+	return p.cpuProfile != "" || p.memProfile != "" || p.trace != ""
+}
+
+// start begins CPU profiling and execution tracing.
+func (p *profiler) start() error {
+	if p.cpuProfile != "" {
+		f, err := os.Create(p.cpuProfile)
+		if err != nil {
+			return fmt.Errorf("error creating CPU profile: %w", err)
+		}
+		if err := pprof.StartCPUProfile(f); err != nil {
+			f.Close()
+			return fmt.Errorf("error starting CPU profile: %w", err)
+		}
+		p.cpuFile = f
+	}
+
+	if p.trace != "" {
+		f, err := os.Create(p.trace)
+		if err != nil {
+			p.stopCPU()
+			return fmt.Errorf("error creating trace file: %w", err)
+		}
+		if err := trace.Start(f); err != nil {
+			f.Close()
+			p.stopCPU()
+			return fmt.Errorf("error starting trace: %w", err)
+		}
+		p.traceFile = f
+	}
+
+	return nil
+}
+
+// stop ends CPU profiling and tracing, then writes the heap profile.
+func (p *profiler) stop() error {
+	var errs []error
+	if p.traceFile != nil {
+		trace.Stop()
+		errs = append(errs, p.traceFile.Close())
+		p.traceFile = nil
+	}
+	errs = append(errs, p.stopCPU())
+
+	if p.memProfile != "" {
+		errs = append(errs, writeHeapProfile(p.memProfile))
+	}
+
+	return errors.Join(errs...)
+}
+
+func (p *profiler) stopCPU() error {
+	if p.cpuFile == nil {
+		return nil
+	}
+	pprof.StopCPUProfile()
+	err := p.cpuFile.Close()
+	p.cpuFile = nil
+	return err
+}
+
+func writeHeapProfile(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("error creating memory profile: %w", err)
+	}
+	defer f.Close()
+
+	// get up-to-date statistics
+	runtime.GC()
+	if err := pprof.WriteHeapProfile(f); err != nil {
+		return fmt.Errorf("error writing memory profile: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProfiler(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+
+	config := Config{
+		CPUProfile: filepath.Join(dir, "cpu.out"),
+		MemProfile: filepath.Join(dir, "mem.out"),
+		Trace:      filepath.Join(dir, "trace.out"),
+	}
+	opts := config.processOptions()
+	require.NotNil(t, opts.BeforeAnalysis)
+	require.NotNil(t, opts.AfterAnalysis)
+
+	require.NoError(t, opts.BeforeAnalysis())
+	require.NoError(t, opts.AfterAnalysis())
+
+	for _, path := range []string{config.CPUProfile, config.MemProfile, config.Trace} {
+		info, err := os.Stat(path)
+		require.NoError(t, err)
+		assert.NotZero(t, info.Size(), path)
+	}
+}
+
+func TestProfilerDisabled(t *testing.T) {
+	t.Parallel()
+
+	opts := Config{}.processOptions()
+	assert.Nil(t, opts.BeforeAnalysis)
+	assert.Nil(t, opts.AfterAnalysis)
+}
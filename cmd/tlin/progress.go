@@ -0,0 +1,89 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/gnolang/tlin/internal"
+	tt "github.com/gnolang/tlin/internal/types"
+)
+
+// progressWidth bounds the progress line so that it never wraps on a
+// regular terminal, which would break the carriage return redraw.
+const progressWidth = 80
+
+// progress draws the advance of a lint run on a terminal: the files done out
+// of the total, the running issue count and the file being linted.
+// The engine serializes the calls, so no locking is needed.
+type progress struct {
+	internal.NopObserver
+
+	out     io.Writer
+	total   int
+	done    int
+	issues  int
+	current string
+}
+
+func newProgress(out io.Writer) *progress {
+	return &progress{out: out}
+}
+
+func (p *progress) OnRunStart(files int) {
+	p.total = files
+	p.draw()
+}
+
+func (p *progress) OnFileStart(filename string) {
+	p.current = filename
+	p.draw()
+}
+
+func (p *progress) OnFileDone(_ string, issues []tt.Issue, _ time.Duration) {
+	p.done++
+	p.issues += len(issues)
+	p.draw()
+}
+
+// OnRuleSkipped prints the notice on a line of its own, above the progress
+// line.
+func (p *progress) OnRuleSkipped(rule, reason string) {
+	fmt.Fprint(p.out, "\r\033[K")
+	printSkipNotice(p.out, rule, reason)
+	p.draw()
+}
+
+// OnRunDone erases the progress line before the issues are printed.
+func (p *progress) OnRunDone(internal.RunSummary) {
+	fmt.Fprint(p.out, "\r\033[K")
+}
+
+func (p *progress) draw() {
+	line := fmt.Sprintf("[%d/%d] %d issues", p.done, p.total, p.issues)
+	if p.current != "" {
+		current := p.current
+		// keep the end of long paths, where the file name is
+		if room := progressWidth - len(line) - 1; len(current) > room {
+			current = "..." + current[len(current)-room+3:]
+		}
+		line += " " + current
+	}
+	fmt.Fprint(p.out, "\r\033[K"+line)
+}
+
+// skipNotices prints a notice for every rule the engine skips, and ignores
+// the other events.
+type skipNotices struct {
+	internal.NopObserver
+
+	out io.Writer
+}
+
+func (n skipNotices) OnRuleSkipped(rule, reason string) {
+	printSkipNotice(n.out, rule, reason)
+}
+
+func printSkipNotice(out io.Writer, rule, reason string) {
+	fmt.Fprintf(out, "notice: rule %s skipped: %s (run `tlin -doctor` for details)\n", rule, reason)
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/gnolang/tlin/internal"
+	tt "github.com/gnolang/tlin/internal/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProgress(t *testing.T) {
+	t.Parallel()
+
+	var out bytes.Buffer
+	p := newProgress(&out)
+	p.OnRunStart(2)
+	p.OnFileStart("a.gno")
+	p.OnFileDone("a.gno", []tt.Issue{{}, {}}, 0)
+	p.OnFileStart(strings.Repeat("dir/", 30) + "b.gno")
+
+	lines := strings.Split(out.String(), "\r\033[K")
+	last := lines[len(lines)-1]
+	assert.Contains(t, lines, "[0/2] 0 issues a.gno")
+	assert.Contains(t, lines, "[1/2] 2 issues a.gno")
+	assert.True(t, strings.HasPrefix(last, "[1/2] 2 issues .../dir/"), last)
+	assert.True(t, strings.HasSuffix(last, "/b.gno"), last)
+	assert.LessOrEqual(t, len(last), progressWidth)
+
+	// notices get a line of their own, and the progress line is redrawn
+	p.OnRuleSkipped("golangci-lint", "golangci-lint not found in PATH")
+	assert.True(t, strings.HasSuffix(out.String(), "\r\033[Knotice: rule golangci-lint skipped: golangci-lint not found in PATH (run `tlin -doctor` for details)\n\r\033[K"+last))
+
+	p.OnFileDone("b.gno", nil, 0)
+	p.OnRunDone(internal.RunSummary{Files: 2, Issues: 2})
+	// the line is erased once the run is done
+	assert.True(t, strings.HasSuffix(out.String(), "\r\033[K"))
+	assert.Contains(t, out.String(), "[2/2] 2 issues")
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"go/parser"
+	"go/token"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/gnolang/tlin/fixer_v2/query"
+)
+
+// queryCommand is the first argument of the structural search.
+const queryCommand = "query"
+
+// runQueryCommand runs `tlin query [-ast] [-comments] [-max-backtrack n]
+// [-where clause] <pattern> [path...]`, printing the code matching the
+// pattern in the .go and .gno files of the paths, along with what its holes
+// captured. It reports whether anything matched.
+func runQueryCommand(w io.Writer, args []string) (bool, error) {
+	flagSet := flag.NewFlagSet(queryCommand, flag.ContinueOnError)
+	nodes := flagSet.Bool("ast", false, "Only match whole statements, expressions and declarations")
+	comments := flagSet.Bool("comments", false, "Match inside comments too")
+	maxBacktrack := flagSet.Int("max-backtrack", query.DefaultMaxBacktrack, "Captures a match attempt may try before giving up")
+	where := flagSet.String("where", "", "Conditions on the captures of the matches, such as 'not match(:[body], \"panic(:[_])\")'")
+	if err := flagSet.Parse(args); err != nil {
+		return false, err
+	}
+	args = flagSet.Args()
+	if len(args) == 0 {
+		return false, errors.New("usage: tlin query [-ast] [-comments] [-max-backtrack n] [-where clause] <pattern> [path...]")
+	}
+	matcher, err := query.Compile(args[0])
+	if err != nil {
+		return false, fmt.Errorf("invalid pattern: %w", err)
+	}
+	matcher.InComments = *comments
+	matcher.MaxBacktrack = *maxBacktrack
+	if *where != "" {
+		if err := matcher.Where(*where); err != nil {
+			return false, err
+		}
+	}
+
+	paths := args[1:]
+	if len(paths) == 0 {
+		paths = []string{"."}
+	}
+	found := false
+	for _, path := range paths {
+		files, err := queryFiles(path)
+		if err != nil {
+			return found, err
+		}
+		for _, file := range files {
+			src, err := os.ReadFile(file)
+			if err != nil {
+				return found, err
+			}
+			matches, err := findMatches(matcher, file, src, *nodes)
+			tokFile := query.SourceFile(file, src)
+			for _, match := range matches {
+				found = true
+				printQueryMatch(w, tokFile, src, match)
+			}
+			if err != nil {
+				return found, fmt.Errorf("%s: %w", file, err)
+			}
+		}
+	}
+	return found, nil
+}
+
+// findMatches returns the matches of the pattern in the source of a file,
+// or those spanning its syntax nodes when nodes is set.
+func findMatches(matcher *query.Matcher, file string, src []byte, nodes bool) ([]query.Match, error) {
+	if !nodes {
+		return matcher.FindAll(src)
+	}
+	fset := token.NewFileSet()
+	node, err := parser.ParseFile(fset, file, src, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+	return matcher.FindNodes(fset, node, src)
+}
+
+// queryFiles lists the .go and .gno files of a path, in walk order. Like
+// the go command, dir/... stands for dir, whose subdirectories are always
+// searched.
+func queryFiles(path string) ([]string, error) {
+	path = strings.TrimSuffix(path, "...")
+	if path != "/" {
+		path = strings.TrimSuffix(path, "/")
+	}
+	if path == "" {
+		path = "."
+	}
+
+	var files []string
+	err := filepath.WalkDir(path, func(file string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			if file != path && strings.HasPrefix(entry.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if ext := filepath.Ext(file); ext == ".go" || ext == ".gno" {
+			files = append(files, file)
+		}
+		return nil
+	})
+	return files, err
+}
+
+// printQueryMatch prints the position and first line of a match, then the
+// captures of its holes by name.
+func printQueryMatch(w io.Writer, file *token.File, src []byte, match query.Match) {
+	text := string(src[match.Start:match.End])
+	if i := strings.IndexByte(text, '\n'); i >= 0 {
+		text = text[:i] + " ..."
+	}
+	fmt.Fprintf(w, "%s: %s\n", match.Location(file).Start, text)
+
+	names := make([]string, 0, len(match.Bindings))
+	for name := range match.Bindings {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(w, "\t%s = %q\n", name, match.Bindings[name])
+	}
+}
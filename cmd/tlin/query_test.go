@@ -0,0 +1,73 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gnolang/tlin/fixer_v2/query"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunQueryCommand(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "sub"), 0o755))
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, ".git"), 0o755))
+	files := map[string]string{
+		"a.gno":        "package a\n\nfunc f(x int) error {\n\treturn nil\n}\n",
+		"sub/b.go":     "package b\n\nfunc g() error { return nil }\n",
+		".git/c.go":    "package c\n\nfunc h() error { return nil }\n",
+		"sub/notes.md": "func i() { return nil }\n",
+	}
+	for name, content := range files {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644))
+	}
+
+	var buf bytes.Buffer
+	found, err := runQueryCommand(&buf, []string{"func :[fn](:[args]) error { return nil }", dir + "/..."})
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, filepath.Join(dir, "a.gno")+":3:1: func f(x int) error { ...\n"+
+		"\targs = \"x int\"\n"+
+		"\tfn = \"f\"\n"+
+		filepath.Join(dir, "sub", "b.go")+":3:1: func g() error { return nil }\n"+
+		"\targs = \"\"\n"+
+		"\tfn = \"g\"\n", buf.String())
+
+	buf.Reset()
+	found, err = runQueryCommand(&buf, []string{"panic(:[x])", dir})
+	require.NoError(t, err)
+	assert.False(t, found)
+	assert.Empty(t, buf.String())
+
+	buf.Reset()
+	found, err = runQueryCommand(&buf, []string{"-comments", "TODO: :[what]", dir + "/sub"})
+	require.NoError(t, err)
+	assert.False(t, found)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "sub", "c.go"), []byte("package b\n\n// TODO: remove\n"), 0o644))
+	found, err = runQueryCommand(&buf, []string{"-comments", "TODO: :[what]", dir + "/sub"})
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.Contains(t, buf.String(), `what = "remove"`)
+
+	_, err = runQueryCommand(&buf, []string{"-max-backtrack", "5", ":[a] :[b] :[c] { :[d] }", dir + "/sub"})
+	assert.ErrorIs(t, err, query.ErrBacktrackLimit)
+	assert.ErrorContains(t, err, filepath.Join(dir, "sub", "b.go"))
+
+	buf.Reset()
+	found, err = runQueryCommand(&buf, []string{"-where", `not match(:[args], "int")`, "func :[fn](:[args]) error", dir})
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.Contains(t, buf.String(), `fn = "g"`)
+	assert.NotContains(t, buf.String(), `fn = "f"`)
+	_, err = runQueryCommand(&buf, []string{"-where", "match(:[y], `x`)", "f(:[x])", dir})
+	assert.ErrorContains(t, err, "the pattern has no hole y")
+
+	_, err = runQueryCommand(&buf, nil)
+	assert.EqualError(t, err, "usage: tlin query [-ast] [-comments] [-max-backtrack n] [-where clause] <pattern> [path...]")
+	_, err = runQueryCommand(&buf, []string{":[x"})
+	assert.Error(t, err)
+}
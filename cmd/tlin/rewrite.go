@@ -0,0 +1,152 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/gnolang/tlin/fixer_v2/query"
+	"github.com/gnolang/tlin/internal/fixer"
+	tt "github.com/gnolang/tlin/internal/types"
+)
+
+// rewriteCommand is the first argument of the pattern-based rewrite.
+const rewriteCommand = "rewrite"
+
+// rewriteRule names the issues standing for the matches of a rewrite.
+const rewriteRule = "rewrite"
+
+// runRewriteCommand runs `tlin rewrite -match <pattern> -replace <template>
+// [-diff | -write] [-ast] [-comments] [-max-backtrack n] [-where clause]
+// [path...]`, replacing the code matching the pattern in the .go and .gno
+// files of the paths with the template, its holes filled with the captures
+// of the match. The changes are printed as a unified diff unless -write
+// applies them. It reports whether anything matched.
+func runRewriteCommand(w io.Writer, args []string) (bool, error) {
+	flagSet := flag.NewFlagSet(rewriteCommand, flag.ContinueOnError)
+	pattern := flagSet.String("match", "", "Pattern of the code to rewrite")
+	replacement := flagSet.String("replace", "", "Template replacing the matched code, with the holes of the pattern")
+	diff := flagSet.Bool("diff", false, "Print the changes as a unified diff (default)")
+	write := flagSet.Bool("write", false, "Write the changes to the files")
+	nodes := flagSet.Bool("ast", false, "Only rewrite whole statements, expressions and declarations")
+	comments := flagSet.Bool("comments", false, "Rewrite inside comments too")
+	maxBacktrack := flagSet.Int("max-backtrack", query.DefaultMaxBacktrack, "Captures a match attempt may try before giving up")
+	where := flagSet.String("where", "", "Conditions on the captures of the matches, such as 'not match(:[body], \"panic(:[_])\")'")
+	if err := flagSet.Parse(args); err != nil {
+		return false, err
+	}
+	if *pattern == "" {
+		return false, errors.New("usage: tlin rewrite -match <pattern> -replace <template> [-diff | -write] [-ast] [-comments] [-max-backtrack n] [-where clause] [path...]")
+	}
+	if *diff && *write {
+		return false, errors.New("-diff and -write are mutually exclusive")
+	}
+
+	matcher, err := query.Compile(*pattern)
+	if err != nil {
+		return false, fmt.Errorf("invalid pattern: %w", err)
+	}
+	matcher.InComments = *comments
+	matcher.MaxBacktrack = *maxBacktrack
+	if *where != "" {
+		if err := matcher.Where(*where); err != nil {
+			return false, err
+		}
+	}
+	template, err := query.ParseTemplate(*replacement)
+	if err != nil {
+		return false, fmt.Errorf("invalid replacement: %w", err)
+	}
+	bound := make(map[string]bool)
+	for _, name := range matcher.Holes() {
+		bound[name] = true
+	}
+	for _, name := range template.Holes() {
+		if !bound[name] {
+			return false, fmt.Errorf("invalid replacement: the pattern has no hole %s", name)
+		}
+	}
+
+	paths := flagSet.Args()
+	if len(paths) == 0 {
+		paths = []string{"."}
+	}
+	// compute every patch first, so that a file the rewrite breaks leaves
+	// them all untouched
+	var patches []*fixer.Patch
+	for _, path := range paths {
+		files, err := queryFiles(path)
+		if err != nil {
+			return false, err
+		}
+		for _, file := range files {
+			patch, err := rewriteFile(file, matcher, template, *nodes)
+			if err != nil {
+				return false, err
+			}
+			if patch != nil {
+				patches = append(patches, patch)
+			}
+		}
+	}
+
+	for _, patch := range patches {
+		if *write {
+			if err := patch.Apply(); err != nil {
+				return true, err
+			}
+			fmt.Fprintf(w, "Rewrote %d matches in %s\n", len(patch.Edits), patch.Filename)
+			continue
+		}
+		text, err := patch.UnifiedDiff()
+		if err != nil {
+			return true, err
+		}
+		fmt.Fprint(w, text)
+	}
+	return len(patches) > 0, nil
+}
+
+// rewriteFile computes the rewrite of the matches in a file, or of those
+// spanning its syntax nodes with nodes set, or returns nil when nothing
+// matched. Like the fixes of the issues, the rewritten file is formatted and
+// must parse.
+func rewriteFile(file string, matcher *query.Matcher, template *query.Template, nodes bool) (*fixer.Patch, error) {
+	src, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	matches, err := findMatches(matcher, file, src, nodes)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", file, err)
+	}
+	if len(matches) == 0 {
+		return nil, nil
+	}
+
+	tokFile := query.SourceFile(file, src)
+	issues := make([]tt.Issue, 0, len(matches))
+	for _, match := range matches {
+		text, err := template.Expand(match.Bindings)
+		if err != nil {
+			return nil, err
+		}
+		loc := match.Location(tokFile)
+		issues = append(issues, tt.Issue{
+			Rule:         rewriteRule,
+			Filename:     file,
+			Message:      "rewrite",
+			Start:        loc.Start,
+			End:          loc.End,
+			Confidence:   1,
+			SuggestedFix: []tt.TextEdit{{Start: loc.Start, End: loc.End, NewText: text}},
+		})
+	}
+	patch, err := fixer.New(false, 0).Patch(file, issues)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", file, err)
+	}
+	return patch, nil
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunRewriteCommand(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	file := filepath.Join(dir, "a.gno")
+	content := "package a\n\nfunc f(err error) {\n\tif err != nil {\n\t\tpanic(err)\n\t}\n}\n"
+	require.NoError(t, os.WriteFile(file, []byte(content), 0o644))
+
+	args := []string{"-match", "if :[x] != nil { panic(:[x]) }", "-replace", "must(:[x])", dir + "/..."}
+	var buf bytes.Buffer
+	found, err := runRewriteCommand(&buf, args)
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.Contains(t, buf.String(), "-\tif err != nil {\n")
+	assert.Contains(t, buf.String(), "+\tmust(err)\n")
+	written, err := os.ReadFile(file)
+	require.NoError(t, err)
+	assert.Equal(t, content, string(written), "the diff leaves the file untouched")
+
+	buf.Reset()
+	found, err = runRewriteCommand(&buf, append([]string{"-write"}, args...))
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, "Rewrote 1 matches in "+file+"\n", buf.String())
+	written, err = os.ReadFile(file)
+	require.NoError(t, err)
+	assert.Equal(t, "package a\n\nfunc f(err error) {\n\tmust(err)\n}\n", string(written))
+
+	found, err = runRewriteCommand(&buf, args)
+	require.NoError(t, err)
+	assert.False(t, found)
+
+	// the text matches start anywhere, the node ones at a node
+	partial := []string{"-match", "ust(:[x])", "-replace", "ust2(:[x])", dir}
+	found, err = runRewriteCommand(&buf, partial)
+	require.NoError(t, err)
+	assert.True(t, found)
+	found, err = runRewriteCommand(&buf, append([]string{"-ast"}, partial...))
+	require.NoError(t, err)
+	assert.False(t, found)
+	found, err = runRewriteCommand(&buf, []string{"-ast", "-match", "must(:[x])", "-replace", "check(:[x])", dir})
+	require.NoError(t, err)
+	assert.True(t, found)
+
+	_, err = runRewriteCommand(&buf, []string{"-match", "f(:[x])", "-replace", "g(:[y])", dir})
+	assert.EqualError(t, err, "invalid replacement: the pattern has no hole y")
+	_, err = runRewriteCommand(&buf, []string{"-match", "must(:[x])", "-replace", "must(", dir})
+	assert.ErrorContains(t, err, "does not parse")
+	_, err = runRewriteCommand(&buf, []string{"-diff", "-write", "-match", "f"})
+	assert.Error(t, err)
+	_, err = runRewriteCommand(&buf, nil)
+	assert.Error(t, err)
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/gnolang/tlin/internal"
+)
+
+// writeRuleList prints every rule with its default severity, its category
+// and the options it accepts.
+func writeRuleList(w io.Writer, rules []internal.RuleInfo) {
+	for _, rule := range rules {
+		fmt.Fprintf(w, "%s (%s, %s)\n", rule.Name, rule.Severity, rule.Category)
+		fmt.Fprintf(w, "    %s\n", rule.Description)
+		for _, opt := range rule.Options {
+			fmt.Fprintf(w, "    - %s: %s, default %s\n", opt.Name, opt.Constraint(), formatDefault(opt.Default))
+		}
+	}
+}
+
+// writeRuleExplanation prints a rule along with the description of each
+// of its options.
+func writeRuleExplanation(w io.Writer, rule internal.RuleInfo) {
+	fmt.Fprintf(w, "%s\n", rule.Name)
+	fmt.Fprintf(w, "  %s\n", rule.Description)
+	fmt.Fprintf(w, "  severity: %s\n", rule.Severity)
+	fmt.Fprintf(w, "  category: %s\n", rule.Category)
+	if len(rule.Options) == 0 {
+		fmt.Fprintf(w, "  options: none\n")
+		return
+	}
+	fmt.Fprintf(w, "  options:\n")
+	for _, opt := range rule.Options {
+		fmt.Fprintf(w, "    %s (%s)\n", opt.Name, opt.Constraint())
+		fmt.Fprintf(w, "      %s\n", opt.Description)
+		fmt.Fprintf(w, "      default: %s\n", formatDefault(opt.Default))
+	}
+}
+
+func formatDefault(v interface{}) string {
+	switch v := v.(type) {
+	case string, []string:
+		return fmt.Sprintf("%q", v)
+	}
+	return fmt.Sprint(v)
+}
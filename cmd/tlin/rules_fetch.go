@@ -0,0 +1,219 @@
+package main
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/gnolang/tlin/internal"
+)
+
+// rulesCommand is the first argument of the rule pack subcommands.
+const rulesCommand = "rules"
+
+const (
+	// defaultRulesDir is where fetched rule packs are installed, relative to
+	// the current directory.
+	defaultRulesDir = ".tlin/rules"
+	// maxRuleArchiveSize bounds the size of a downloaded rule pack archive,
+	// and of the files extracted from it.
+	maxRuleArchiveSize = 16 << 20
+	fetchTimeout       = time.Minute
+)
+
+// runRulesCommand runs `tlin rules fetch [-dir dir] -sha256 <checksum> <url>`,
+// installing the rule packs of a remote archive.
+func runRulesCommand(w io.Writer, args []string) error {
+	const usage = "usage: tlin rules fetch [-dir dir] -sha256 <checksum> <url>"
+	if len(args) == 0 || args[0] != "fetch" {
+		return errors.New(usage)
+	}
+	flagSet := flag.NewFlagSet(rulesCommand+" fetch", flag.ContinueOnError)
+	dir := flagSet.String("dir", defaultRulesDir, "Directory the rule packs are installed in")
+	checksum := flagSet.String("sha256", "", "Expected SHA-256 checksum of the archive, in hexadecimal")
+	if err := flagSet.Parse(args[1:]); err != nil {
+		return err
+	}
+	if flagSet.NArg() != 1 || *checksum == "" {
+		return errors.New(usage)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), fetchTimeout)
+	defer cancel()
+	installed, err := fetchRulePacks(ctx, flagSet.Arg(0), *checksum, *dir)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "Installed rule packs in %s\n", installed)
+	return nil
+}
+
+// fetchRulePacks downloads a .tar.gz, .tgz or .zip archive of rule packs,
+// checks its checksum and installs its .yaml and .yml files in a directory
+// of dir named after the archive, such as policies-v1.2.0 for
+// policies-v1.2.0.tar.gz. The packs are checked before replacing those of
+// a previous fetch of the same archive. It returns the directory.
+func fetchRulePacks(ctx context.Context, rawURL, checksum, dir string) (string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", err
+	}
+	name := path.Base(u.Path)
+	var extract func([]byte, string) error
+	for _, format := range []struct {
+		ext     string
+		extract func([]byte, string) error
+	}{
+		{".tar.gz", extractTarGz},
+		{".tgz", extractTarGz},
+		{".zip", extractZip},
+	} {
+		if strings.HasSuffix(name, format.ext) && len(name) > len(format.ext) {
+			name = strings.TrimSuffix(name, format.ext)
+			extract = format.extract
+			break
+		}
+	}
+	if extract == nil {
+		return "", fmt.Errorf("unsupported rule pack archive %s: expected a .tar.gz, .tgz or .zip file", rawURL)
+	}
+
+	archive, err := download(ctx, u.String())
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(archive)
+	if got := hex.EncodeToString(sum[:]); !strings.EqualFold(got, checksum) {
+		return "", fmt.Errorf("checksum mismatch for %s: got %s, want %s", rawURL, got, checksum)
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", err
+	}
+	temp, err := os.MkdirTemp(dir, "."+name+"-*")
+	if err != nil {
+		return "", err
+	}
+	defer os.RemoveAll(temp)
+	if err := extract(archive, temp); err != nil {
+		return "", fmt.Errorf("invalid rule pack archive %s: %w", rawURL, err)
+	}
+	if err := internal.CheckRulePack(temp); err != nil {
+		return "", err
+	}
+
+	installed := filepath.Join(dir, name)
+	if err := os.RemoveAll(installed); err != nil {
+		return "", err
+	}
+	if err := os.Rename(temp, installed); err != nil {
+		return "", err
+	}
+	return installed, nil
+}
+
+func download(ctx context.Context, rawURL string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch %s: %w", rawURL, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch %s: %s", rawURL, resp.Status)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxRuleArchiveSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch %s: %w", rawURL, err)
+	}
+	if len(data) > maxRuleArchiveSize {
+		return nil, fmt.Errorf("failed to fetch %s: archive larger than %d bytes", rawURL, maxRuleArchiveSize)
+	}
+	return data, nil
+}
+
+func extractTarGz(archive []byte, dir string) error {
+	gz, err := gzip.NewReader(bytes.NewReader(archive))
+	if err != nil {
+		return err
+	}
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+		if err := extractRulePack(dir, header.Name, tr); err != nil {
+			return err
+		}
+	}
+}
+
+func extractZip(archive []byte, dir string) error {
+	zr, err := zip.NewReader(bytes.NewReader(archive), int64(len(archive)))
+	if err != nil {
+		return err
+	}
+	for _, file := range zr.File {
+		if file.FileInfo().IsDir() {
+			continue
+		}
+		rc, err := file.Open()
+		if err != nil {
+			return err
+		}
+		err = extractRulePack(dir, file.Name, rc)
+		rc.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// extractRulePack writes the archived file to dir if it is a rule pack,
+// refusing the names escaping dir.
+func extractRulePack(dir, name string, r io.Reader) error {
+	if ext := path.Ext(name); ext != ".yaml" && ext != ".yml" {
+		return nil
+	}
+	if !filepath.IsLocal(name) {
+		return fmt.Errorf("file %q is outside of the archive", name)
+	}
+	data, err := io.ReadAll(io.LimitReader(r, maxRuleArchiveSize+1))
+	if err != nil {
+		return err
+	}
+	if len(data) > maxRuleArchiveSize {
+		return fmt.Errorf("file %q is larger than %d bytes", name, maxRuleArchiveSize)
+	}
+	target := filepath.Join(dir, filepath.FromSlash(name))
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(target, data, 0o644)
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const fetchedPack = "rules:\n  - {name: fetched-no-println, match: 'println(:[_])', message: 'println is for debugging'}\n"
+
+func tarGzArchive(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for name, content := range files {
+		require.NoError(t, tw.WriteHeader(&tar.Header{Name: name, Mode: 0o644, Size: int64(len(content)), Typeflag: tar.TypeReg}))
+		_, err := tw.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, tw.Close())
+	require.NoError(t, gz.Close())
+	return buf.Bytes()
+}
+
+func zipArchive(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, content := range files {
+		w, err := zw.Create(name)
+		require.NoError(t, err)
+		_, err = w.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, zw.Close())
+	return buf.Bytes()
+}
+
+func checksum(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func TestFetchRulePacks(t *testing.T) {
+	t.Parallel()
+	archives := map[string][]byte{
+		"/policies-v1.0.0.tar.gz": tarGzArchive(t, map[string]string{
+			"policies/debug.yaml": fetchedPack,
+			"README.md":           "not a rule pack",
+		}),
+		"/policies-v1.0.0.zip": zipArchive(t, map[string]string{"debug.yml": fetchedPack}),
+		"/escape.tar.gz":       tarGzArchive(t, map[string]string{"../escape.yaml": fetchedPack}),
+		"/broken.tar.gz":       tarGzArchive(t, map[string]string{"broken.yaml": "rules:\n  - {name: useless-break, match: x, message: m}\n"}),
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		archive, ok := archives[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write(archive)
+	}))
+	defer server.Close()
+	ctx := context.Background()
+	dir := filepath.Join(t.TempDir(), "rules")
+
+	for _, name := range []string{"/policies-v1.0.0.tar.gz", "/policies-v1.0.0.zip"} {
+		installed, err := fetchRulePacks(ctx, server.URL+name, checksum(archives[name]), dir)
+		require.NoError(t, err, name)
+		assert.Equal(t, filepath.Join(dir, "policies-v1.0.0"), installed)
+	}
+	// the zip archive replaced the packs of the tar one
+	assert.FileExists(t, filepath.Join(dir, "policies-v1.0.0", "debug.yml"))
+	assert.NoDirExists(t, filepath.Join(dir, "policies-v1.0.0", "policies"))
+
+	_, err := fetchRulePacks(ctx, server.URL+"/policies-v1.0.0.zip", checksum(nil), dir)
+	assert.ErrorContains(t, err, "checksum mismatch")
+	_, err = fetchRulePacks(ctx, server.URL+"/escape.tar.gz", checksum(archives["/escape.tar.gz"]), dir)
+	assert.ErrorContains(t, err, "outside of the archive")
+	_, err = fetchRulePacks(ctx, server.URL+"/broken.tar.gz", checksum(archives["/broken.tar.gz"]), dir)
+	assert.ErrorContains(t, err, "already registered")
+	assert.NoDirExists(t, filepath.Join(dir, "broken"))
+	_, err = fetchRulePacks(ctx, server.URL+"/missing.tar.gz", "00", dir)
+	assert.ErrorContains(t, err, "404")
+	_, err = fetchRulePacks(ctx, server.URL+"/policies.rar", "00", dir)
+	assert.ErrorContains(t, err, "unsupported rule pack archive")
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 1, "failed fetches leave nothing behind")
+}
+
+func TestRunRulesCommand(t *testing.T) {
+	t.Parallel()
+	var buf bytes.Buffer
+	for _, args := range [][]string{nil, {"list"}, {"fetch", "https://example.com/p.tar.gz"}, {"fetch", "-sha256", "00"}} {
+		assert.ErrorContains(t, runRulesCommand(&buf, args), "usage: tlin rules fetch", args)
+	}
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/gnolang/tlin/internal"
+	tt "github.com/gnolang/tlin/internal/types"
+	"github.com/stretchr/testify/assert"
+)
+
+var testRules = []internal.RuleInfo{
+	{
+		Name:        "high-cyclomatic-complexity",
+		Description: "Reports complex functions.",
+		Category:    "complexity",
+		Severity:    tt.SeverityOff,
+		Options: []tt.RuleOption{
+			{Name: "threshold", Description: "Maximum complexity.", Type: tt.OptionInt, Default: 10, Min: tt.Limit(1)},
+		},
+	},
+	{
+		Name:        "useless-break",
+		Description: "Detects useless breaks.",
+		Category:    "style",
+		Severity:    tt.SeverityWarning,
+	},
+}
+
+func TestWriteRuleList(t *testing.T) {
+	t.Parallel()
+
+	var out bytes.Buffer
+	writeRuleList(&out, testRules)
+	assert.Equal(t, `high-cyclomatic-complexity (OFF, complexity)
+    Reports complex functions.
+    - threshold: int, at least 1, default 10
+useless-break (WARNING, style)
+    Detects useless breaks.
+`, out.String())
+}
+
+func TestWriteRuleExplanation(t *testing.T) {
+	t.Parallel()
+
+	var out bytes.Buffer
+	writeRuleExplanation(&out, testRules[0])
+	assert.Equal(t, `high-cyclomatic-complexity
+  Reports complex functions.
+  severity: OFF
+  category: complexity
+  options:
+    threshold (int, at least 1)
+      Maximum complexity.
+      default: 10
+`, out.String())
+
+	out.Reset()
+	writeRuleExplanation(&out, testRules[1])
+	assert.Contains(t, out.String(), "  options: none\n")
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+
+	"github.com/gnolang/tlin/formatter"
+	"github.com/gnolang/tlin/internal"
+	tt "github.com/gnolang/tlin/internal/types"
+	"github.com/gnolang/tlin/lint"
+	"go.uber.org/zap"
+)
+
+// ndjsonWriter streams issues as newline-delimited JSON: one issue object per
+// line, as in the JSON output, then a summary object on the last line.
+type ndjsonWriter struct {
+	out *bufio.Writer
+	enc *json.Encoder
+}
+
+func newNDJSONWriter(w io.Writer) *ndjsonWriter {
+	out := bufio.NewWriter(w)
+	return &ndjsonWriter{out: out, enc: json.NewEncoder(out)}
+}
+
+// ndjsonSummary is the last line of the stream, telling consumers the run
+// completed.
+type ndjsonSummary struct {
+	Summary struct {
+		Files      int   `json:"files"`
+		Failed     int   `json:"failed"`
+		Issues     int   `json:"issues"`
+		DurationMs int64 `json:"duration_ms"`
+	} `json:"summary"`
+}
+
+// writeIssues writes a batch of issues and flushes it, so that consumers see
+// each file as soon as it is linted. Fingerprints are computed per file,
+// which needs all the issues of a file to be in the same batch.
+func (w *ndjsonWriter) writeIssues(issues []tt.Issue) error {
+	byFile := make(map[string][]tt.Issue)
+	var order []string
+	for _, issue := range issues {
+		if _, ok := byFile[issue.Filename]; !ok {
+			order = append(order, issue.Filename)
+		}
+		byFile[issue.Filename] = append(byFile[issue.Filename], issue)
+	}
+
+	for _, filename := range order {
+		fileIssues := byFile[filename]
+		if source, err := internal.ReadSourceCode(filename); err == nil {
+			tt.SetFingerprints(fileIssues, source.Lines)
+		}
+		for _, issue := range fileIssues {
+			if err := w.enc.Encode(formatter.NewJSONIssue(issue)); err != nil {
+				return err
+			}
+		}
+	}
+	return w.out.Flush()
+}
+
+func (w *ndjsonWriter) writeSummary(summary internal.RunSummary) error {
+	var line ndjsonSummary
+	line.Summary.Files = summary.Files
+	line.Summary.Failed = summary.Failed
+	line.Summary.Issues = summary.Issues
+	line.Summary.DurationMs = summary.Duration.Milliseconds()
+	if err := w.enc.Encode(line); err != nil {
+		return err
+	}
+	return w.out.Flush()
+}
+
+// runStreamingLintProcess lints the paths like runNormalLintProcess, writing
+// the issues as NDJSON while the files are linted instead of once the run
+// is over. The summary line is left out when the run fails.
+func runStreamingLintProcess(ctx context.Context, logger *zap.Logger, opts lint.Options, output string, failOn tt.Severity, onReport func(*lint.Report)) {
+	out := io.Writer(os.Stdout)
+	if output != "" {
+		f, err := os.Create(output)
+		if err != nil {
+			logger.Error("Error creating NDJSON output file", zap.Error(err))
+			os.Exit(1)
+		}
+		defer f.Close()
+		out = f
+	}
+
+	w := newNDJSONWriter(out)
+	failed := false
+	opts.Stream = func(issues []tt.Issue) error {
+		failed = failed || failsRun(issues, failOn)
+		return w.writeIssues(issues)
+	}
+	report, err := lint.Run(ctx, opts)
+	if err != nil {
+		logger.Error("Error processing files", zap.Error(err))
+		os.Exit(1)
+	}
+	if onReport != nil {
+		onReport(report)
+	}
+	if err := w.writeSummary(report.Summary); err != nil {
+		logger.Error("Error writing NDJSON output", zap.Error(err))
+		os.Exit(1)
+	}
+
+	if failed {
+		os.Exit(1)
+	}
+}
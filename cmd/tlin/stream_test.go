@@ -0,0 +1,62 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"go/token"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gnolang/tlin/internal"
+	tt "github.com/gnolang/tlin/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNDJSONWriter(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	a := filepath.Join(dir, "a.gno")
+	b := filepath.Join(dir, "b.gno")
+	require.NoError(t, os.WriteFile(a, []byte("package a\n\nvar x = 1\n"), 0o644))
+	require.NoError(t, os.WriteFile(b, []byte("package a\n\nvar y = 2\n"), 0o644))
+
+	issueAt := func(filename string, line int) tt.Issue {
+		pos := token.Position{Filename: filename, Line: line, Column: 1}
+		return tt.Issue{Rule: "rule", Filename: filename, Message: "message", Start: pos, End: pos}
+	}
+
+	var out bytes.Buffer
+	w := newNDJSONWriter(&out)
+	require.NoError(t, w.writeIssues([]tt.Issue{issueAt(a, 3), issueAt(a, 1)}))
+	// each batch is flushed as soon as it is written
+	assert.Equal(t, 2, bytes.Count(out.Bytes(), []byte("\n")))
+	require.NoError(t, w.writeIssues([]tt.Issue{issueAt(b, 3)}))
+	require.NoError(t, w.writeSummary(internal.RunSummary{Files: 2, Issues: 3, Duration: 1500 * time.Millisecond}))
+
+	var lines []map[string]any
+	scanner := bufio.NewScanner(&out)
+	for scanner.Scan() {
+		var line map[string]any
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &line), scanner.Text())
+		lines = append(lines, line)
+	}
+	require.Len(t, lines, 4)
+
+	for i, want := range []string{a, a, b} {
+		assert.Equal(t, want, lines[i]["file"])
+		assert.NotEmpty(t, lines[i]["id"])
+		assert.NotEmpty(t, lines[i]["fingerprint"])
+	}
+	// the issues of a file keep their order
+	assert.EqualValues(t, 3, lines[0]["start"].(map[string]any)["line"])
+	assert.NotEqual(t, lines[0]["fingerprint"], lines[2]["fingerprint"])
+
+	assert.Equal(t, map[string]any{
+		"summary": map[string]any{"files": 2.0, "failed": 0.0, "issues": 3.0, "duration_ms": 1500.0},
+	}, lines[3])
+}
@@ -0,0 +1,220 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/gnolang/tlin/formatter"
+	"github.com/gnolang/tlin/internal"
+	tt "github.com/gnolang/tlin/internal/types"
+	"github.com/gnolang/tlin/lint"
+	"go.uber.org/zap"
+)
+
+// watchDelay is how long the watcher waits after a change before linting,
+// so that the writes of an editor saving several files are linted at once.
+const watchDelay = 100 * time.Millisecond
+
+// watcher lints the targets, then lints their packages again whenever one
+// of their files changes.
+type watcher struct {
+	opts   lint.Options
+	out    io.Writer
+	notify *fsnotify.Watcher
+	// issues are the issues of the files linted, by file
+	issues map[string][]tt.Issue
+}
+
+// runWatchMode lints the targets of opts and prints their issues, then
+// watches their directories: when a .go or .gno file changes, the files of
+// its package are linted again and their issues printed, followed by the
+// summary of all the issues. It returns once ctx is done.
+func runWatchMode(ctx context.Context, opts lint.Options, out io.Writer) error {
+	notify, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	defer notify.Close()
+
+	w := &watcher{opts: opts, out: out, notify: notify, issues: make(map[string][]tt.Issue)}
+	for _, target := range opts.Targets {
+		if err := w.watch(target); err != nil {
+			return err
+		}
+	}
+	report, err := w.run(ctx, opts.Targets)
+	if err != nil {
+		return err
+	}
+	w.add(report.Issues, report.Summary)
+
+	changed := make(map[string]bool) // directories of the changed files
+	var delay <-chan time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event, ok := <-notify.Events:
+			if !ok {
+				return nil
+			}
+			if info, err := os.Stat(event.Name); err == nil && info.IsDir() && event.Has(fsnotify.Create) {
+				// the files of a new directory are linted along with it
+				if err := w.watch(event.Name); err != nil {
+					w.logWarn("Error watching directory", event.Name, err)
+				}
+				changed[event.Name] = true
+			} else if lintable(event.Name) && w.covers(event.Name) {
+				changed[filepath.Dir(event.Name)] = true
+			}
+			if len(changed) > 0 && delay == nil {
+				delay = time.After(watchDelay)
+			}
+		case err, ok := <-notify.Errors:
+			if !ok {
+				return nil
+			}
+			w.logWarn("Error watching files", "", err)
+		case <-delay:
+			delay = nil
+			if err := w.relint(ctx, changed); err != nil {
+				fmt.Fprintln(w.out, "error:", err)
+			}
+			changed = make(map[string]bool)
+		}
+	}
+}
+
+// watch watches a target, with the directories under it but the hidden
+// ones. A file target is watched through its directory.
+func (w *watcher) watch(target string) error {
+	info, err := os.Stat(target)
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		return w.notify.Add(filepath.Dir(target))
+	}
+	return filepath.WalkDir(target, func(path string, d os.DirEntry, err error) error {
+		if err != nil || !d.IsDir() {
+			return err
+		}
+		if path != target && strings.HasPrefix(d.Name(), ".") {
+			return filepath.SkipDir
+		}
+		return w.notify.Add(path)
+	})
+}
+
+// covers reports whether a file is one of the targets, or under one.
+func (w *watcher) covers(file string) bool {
+	for _, target := range w.opts.Targets {
+		rel, err := filepath.Rel(target, file)
+		if err == nil && (rel == "." || filepath.IsLocal(rel)) {
+			return true
+		}
+	}
+	return false
+}
+
+// relint lints the files of the targets directly in the directories
+// again, replacing their issues, those of the removed files included.
+func (w *watcher) relint(ctx context.Context, dirs map[string]bool) error {
+	var files []string
+	for dir := range dirs {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue // removed since
+		}
+		for _, entry := range entries {
+			file := filepath.Join(dir, entry.Name())
+			if !entry.IsDir() && lintable(file) && w.covers(file) {
+				files = append(files, file)
+			}
+		}
+	}
+	sort.Strings(files)
+
+	report := &lint.Report{}
+	if len(files) > 0 {
+		var err error
+		if report, err = w.run(ctx, files); err != nil {
+			return err
+		}
+	}
+	for file := range w.issues {
+		if dirs[filepath.Dir(file)] {
+			delete(w.issues, file)
+		}
+	}
+	linted := make(map[string]bool, len(files))
+	for _, file := range files {
+		linted[file] = true
+	}
+	var issues []tt.Issue
+	for _, issue := range report.Issues {
+		// such as the ones of the suppression list about the other files
+		if linted[issue.Filename] {
+			issues = append(issues, issue)
+		}
+	}
+	w.add(issues, report.Summary)
+	return nil
+}
+
+// run lints the files with the options of the watcher.
+func (w *watcher) run(ctx context.Context, files []string) (*lint.Report, error) {
+	opts := w.opts
+	opts.Targets = files
+	return lint.Run(ctx, opts)
+}
+
+// add records the issues found by a run, then prints them along with the
+// summary of all the issues.
+func (w *watcher) add(issues []tt.Issue, summary internal.RunSummary) {
+	for _, issue := range issues {
+		w.issues[issue.Filename] = append(w.issues[issue.Filename], issue)
+	}
+	w.printSummary(issues, summary.Files, summary.Duration)
+}
+
+// printSummary prints the issues just found, then the count of all the
+// issues.
+func (w *watcher) printSummary(issues []tt.Issue, linted int, duration time.Duration) {
+	if len(issues) > 0 {
+		text, _ := formatter.NewFormat(formatter.FormatText)
+		if err := text.Write(w.out, formatter.Report{Issues: issues}); err != nil {
+			fmt.Fprintln(w.out, "error:", err)
+		}
+	}
+	total, withIssues := 0, 0
+	for _, fileIssues := range w.issues {
+		total += len(fileIssues)
+		if len(fileIssues) > 0 {
+			withIssues++
+		}
+	}
+	fmt.Fprintf(w.out, "[%s] %d %s in %d %s, linted %d %s in %s; watching for changes\n",
+		time.Now().Format(time.TimeOnly),
+		total, plural(total, "issue", "issues"), withIssues, plural(withIssues, "file", "files"),
+		linted, plural(linted, "file", "files"), duration.Round(time.Millisecond))
+}
+
+// lintable reports whether tlin lints the file, from its extension.
+func lintable(path string) bool {
+	ext := filepath.Ext(path)
+	return ext == ".go" || ext == ".gno"
+}
+
+func (w *watcher) logWarn(msg, path string, err error) {
+	if w.opts.Logger != nil {
+		w.opts.Logger.Warn(msg, zap.String("path", path), zap.Error(err))
+	}
+}
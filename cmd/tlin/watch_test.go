@@ -0,0 +1,82 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gnolang/tlin/lint"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// syncBuffer is a buffer written by the watcher while the test reads it.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestRunWatchMode(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	file := filepath.Join(dir, "main.gno")
+	require.NoError(t, os.WriteFile(file, []byte(sliceRangeIssueExample), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "README.md"), []byte("# main\n"), 0o644))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var out syncBuffer
+	done := make(chan error)
+	go func() {
+		done <- runWatchMode(ctx, lint.Options{Targets: []string{dir}}, &out)
+	}()
+
+	// waitFor waits for the summary printed after the previous one
+	printed := 0
+	waitFor := func(summary string) string {
+		t.Helper()
+		var output string
+		require.Eventually(t, func() bool {
+			output = out.String()[printed:]
+			return strings.Contains(output, "watching for changes")
+		}, 5*time.Second, 10*time.Millisecond)
+		printed += len(output)
+		assert.Contains(t, output, summary)
+		return output
+	}
+
+	output := waitFor("] 1 issue in 1 file, linted 1 file in")
+	assert.Contains(t, output, "simplify-slice-range")
+
+	require.NoError(t, os.WriteFile(file, []byte(strings.Replace(sliceRangeIssueExample, "len(slice)", "", 1)), 0o644))
+	output = waitFor("] 0 issues in 0 files, linted 1 file in")
+	assert.NotContains(t, output, "simplify-slice-range")
+
+	sub := filepath.Join(dir, "sub")
+	require.NoError(t, os.Mkdir(sub, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(sub, "sub.gno"), []byte(sliceRangeIssueExample), 0o644))
+	output = waitFor("] 1 issue in 1 file, linted")
+	assert.Contains(t, output, "sub.gno")
+
+	require.NoError(t, os.RemoveAll(sub))
+	waitFor("] 0 issues in 0 files")
+
+	cancel()
+	assert.NoError(t, <-done)
+}
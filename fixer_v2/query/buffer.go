@@ -73,7 +73,7 @@ func (b *buffer) transition() (States, error) {
 
 	// check for error state
 	if nextState == ER {
-		return ER, fmt.Errorf("invalid syntax at position %d", b.index)
+		return ER, b.syntaxError(b.index, expectedInHole(b.state), "unexpected %q in hole", b.data[b.index])
 	}
 
 	// update state
@@ -96,9 +96,10 @@ func (b *buffer) parseMetaVariable() (*HoleConfig, error) {
 
 	// check initial state
 	if b.index >= b.length || b.data[b.index] != ':' {
-		return nil, fmt.Errorf("expected ':' at position %d", b.index)
+		return nil, b.syntaxError(b.index, ":[", "expected a hole")
 	}
 
+	double := false
 	for b.index < b.length {
 		state, err := b.transition()
 		if err != nil {
@@ -109,6 +110,17 @@ func (b *buffer) parseMetaVariable() (*HoleConfig, error) {
 		b.tokenValue.WriteByte(b.data[b.index])
 		b.index++
 
+		if state == DB {
+			double = true
+		}
+		// a long form hole is only closed by the second bracket
+		if state == CB && double {
+			if b.index >= b.length || b.data[b.index] != ']' {
+				return nil, b.syntaxError(b.index-1, "]]", "unclosed hole, opened with :[[")
+			}
+			continue
+		}
+
 		// CB(closing bracket) or QB(double closing bracket) state reached
 		if state == CB || state == QB {
 			// check if next character is quantifier
@@ -122,17 +134,50 @@ func (b *buffer) parseMetaVariable() (*HoleConfig, error) {
 			value := b.tokenValue.String()
 			config, err := ParseHolePattern(value)
 			if err != nil {
-				return nil, err
+				return nil, b.syntaxError(b.tokenStart, "", "%v", err)
 			}
 			return config, nil
 		}
 	}
 
-	return nil, fmt.Errorf("incomplete meta variable at position %d", b.tokenStart)
+	closing := "]"
+	if double {
+		closing = "]]"
+	}
+	return nil, b.syntaxError(b.tokenStart, closing, "unclosed hole")
+}
+
+// syntaxError returns the error of the pattern at an offset.
+func (b *buffer) syntaxError(offset int, expected, format string, args ...any) *SyntaxError {
+	return &SyntaxError{
+		Pattern:  string(b.data),
+		Offset:   offset,
+		Msg:      fmt.Sprintf(format, args...),
+		Expected: expected,
+	}
+}
+
+// expectedInHole describes what may follow in a hole after the state.
+func expectedInHole(state States) string {
+	switch state {
+	case CL:
+		return "["
+	case OB:
+		return "a hole name or ["
+	case DB:
+		return "a hole name"
+	case NM:
+		return "a name character, :type or ]"
+	case ID:
+		return "a type name character or ]"
+	case LP:
+		return "]"
+	}
+	return ""
 }
 
 // parseText collects and returns text from the current index
-// until it encounters a 'boundary character' (e.g., :, [, ], {, }, *) or EOF.
+// until it encounters a metavar start (:[), a block delimiter ({, }) or EOF.
 // Implemented using a 'peek' approach to look at the next character.
 func (b *buffer) parseText() (string, error) {
 	if len(b.data) == 0 {
@@ -147,12 +192,21 @@ func (b *buffer) parseText() (string, error) {
 		class := b.getClass()
 
 		switch class {
-		// current character is for metavar start/end or block delimiter => end text segment
-		case C_COLON, C_LBRACK, C_RBRACK, C_LBRACE, C_RBRACE, C_QUANT:
+		// current character is a block delimiter => end text segment
+		case C_LBRACE, C_RBRACE:
 			// breaking here leaves the character unconsumed,
 			// so it will be processed by next token (metavar etc.)
 			goto DONE
 
+		// a colon only starts a metavar when followed by a bracket,
+		// brackets and quantifiers outside of a metavar are plain text
+		case C_COLON:
+			if b.index+1 < b.length && b.data[b.index+1] == '[' {
+				goto DONE
+			}
+			b.tokenValue.WriteByte(b.data[b.index])
+			b.index++
+
 		case C_SPACE:
 			// TODO (@notJoon): Decide whether to treat whitespace as part of text or separate WS token.
 			// If you want "all WS in Text token", handle same as default below
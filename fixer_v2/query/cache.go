@@ -0,0 +1,59 @@
+package query
+
+import (
+	"container/list"
+	"sync"
+)
+
+// compileCacheSize is the number of patterns whose compilation Compile
+// keeps, enough for the rule packs of a project.
+const compileCacheSize = 512
+
+var compiledPatterns = newPatternCache(compileCacheSize)
+
+// patternCache is a least recently used cache of compiled patterns.
+type patternCache struct {
+	mu      sync.Mutex
+	size    int
+	order   *list.List // of *cachedPattern, the most recently used first
+	entries map[string]*list.Element
+}
+
+type cachedPattern struct {
+	pattern string
+	elems   []elem
+}
+
+func newPatternCache(size int) *patternCache {
+	return &patternCache{
+		size:    size,
+		order:   list.New(),
+		entries: make(map[string]*list.Element),
+	}
+}
+
+func (c *patternCache) get(pattern string) ([]elem, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[pattern]
+	if !ok {
+		return nil, false
+	}
+	c.order.MoveToFront(entry)
+	return entry.Value.(*cachedPattern).elems, true
+}
+
+func (c *patternCache) add(pattern string, elems []elem) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if entry, ok := c.entries[pattern]; ok {
+		c.order.MoveToFront(entry)
+		return
+	}
+	c.entries[pattern] = c.order.PushFront(&cachedPattern{pattern: pattern, elems: elems})
+	if c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*cachedPattern).pattern)
+	}
+}
@@ -0,0 +1,72 @@
+package query
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestPatternCache(t *testing.T) {
+	c := newPatternCache(2)
+	a, b := []elem{{text: "a"}}, []elem{{text: "b"}}
+	c.add("a", a)
+	c.add("b", b)
+	if _, ok := c.get("a"); !ok {
+		t.Fatal("get(a) missed")
+	}
+	// b is now the least recently used
+	c.add("c", nil)
+	if _, ok := c.get("b"); ok {
+		t.Error("get(b) hit, want it evicted")
+	}
+	for _, pattern := range []string{"a", "c"} {
+		if _, ok := c.get(pattern); !ok {
+			t.Errorf("get(%s) missed", pattern)
+		}
+	}
+}
+
+func TestCompileCached(t *testing.T) {
+	pattern := "cached(:[x])"
+	first, err := Compile(pattern)
+	if err != nil {
+		t.Fatal(err)
+	}
+	first.InComments = true
+	if err := first.Where(`:[x] == "a"`); err != nil {
+		t.Fatal(err)
+	}
+
+	second, err := Compile(pattern)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if second == first || second.InComments || len(second.conditions) != 0 {
+		t.Fatal("Compile() returned a matcher sharing the settings of another")
+	}
+	if &second.elems[0] != &first.elems[0] {
+		t.Error("Compile() compiled the pattern again")
+	}
+}
+
+func TestMatcherConcurrentUse(t *testing.T) {
+	m, err := Compile("f(:[x])")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Where(`not :[x] == "b"`); err != nil {
+		t.Fatal(err)
+	}
+	src := []byte("f(a) f(b) f(c)")
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			matches, err := m.FindAll(src)
+			if err != nil || len(matches) != 2 {
+				t.Errorf("FindAll() = %v, %v, want 2 matches", matches, err)
+			}
+		}()
+	}
+	wg.Wait()
+}
@@ -19,6 +19,72 @@ Metavariables in patterns are expressed using two forms:
  2. Long form: :[[identifier]]
     Example: :[[function]]
 
+The two forms differ in the text they may capture: a short form metavariable
+stops at the end of the line, while a long form one may span several lines,
+as a function body does. A line policy marker right before the closing
+bracket overrides the default:
+
+  - ":[body~]" may span lines
+  - ":[[arg.]]" stops at the end of the line, like the regexp dot
+
+A type after a colon restricts what a metavariable may capture, and a
+quantifier after the closing brackets repeats it:
+
+  - ":[[name:identifier]]" only captures an identifier
+  - ":[[ws:whitespace]]?" captures optional whitespace
+  - ":[[body:stmt]]*" captures zero or more statements, separated by
+    semicolons or newlines
+  - ":[args:expr]+" captures one or more expressions, separated by commas
+
+Holes with a quantifier capture as much code as they can, the others as
+little as lets the rest of the pattern match. Since every hole capture is
+another way for a match to fail, a match attempt gives up after
+Matcher.MaxBacktrack captures, and FindAll then reports ErrBacktrackLimit.
+
+The builtin types are any (the default), whitespace and the syntactic kinds
+identifier (or ident), expression (or expr), statement (or stmt), block,
+string and number. A capture has a syntactic kind when it parses as such
+Go syntax, a single statement for statement and a basic literal for string
+and number: in "if err == nil", ":[x:expr] == nil" captures "err", since
+"if err" is no expression.
+An application embedding the matcher can add its own with
+RegisterHoleType, whose validation function the matcher calls on every
+capture of the hole. ParseHoleType and ParseQuantifier map the syntax to
+the HoleType and Quantifier values.
+
+A metavariable named more than once captures the same code every time,
+whitespace aside: ":[x] == :[x]" matches "a+b == a + b" but not "a == b".
+The metavariables whose name starts with _, whose HoleConfig is
+Independent, capture any code instead.
+
+Matcher.Where restricts the matches with conditions on the captures, as
+the where clauses of Comby do: with
+
+	not match(:[[body]], "std.AssertOriginCall()")
+
+the capture of body must not hold code matching the quoted pattern. The
+conditions may also test a capture against a regular expression, as in
+:[n] matches "^[0-9]+$", compare it to a text, or count its items, as in
+len(:[args]) > 3.
+
+Matcher.FindNodes matches a pattern against the statements, expressions
+and declarations of a parsed file rather than its text, so that a match
+covers whole syntax nodes and never starts or ends within a token.
+Match.Location maps the offsets of a match to lines and columns, through
+the token.File of the parsed file or the one SourceFile makes of a source.
+MatcherSet searches a source for many patterns at once, only trying each
+pattern where the text it starts with occurs.
+
+Validate checks a pattern without matching it. Patterns that don't parse,
+with an unclosed hole or an unmatched brace say, fail to compile with a
+*SyntaxError giving the line and column of the problem, what was expected
+there, and the line of the pattern with a caret under it.
+
+Format renders the syntax tree of a pattern, as Parse returns it, in a
+canonical form: patterns that only differ by their whitespace or the
+spelling of their holes, like "if :[c]{\n\t:[body~]\n}" and
+"if :[c] { :[[body]] }", format the same.
+
 These metavariables can be used in both match and rewrite patterns. When a pattern
 is matched against source code, metavariables capture the corresponding text and can
 be referenced in the rewrite pattern.
@@ -41,7 +107,7 @@ The lexer recognizes the following token types:
     Used for block structure
 
   - TokenWhitespace: Spaces, tabs, newlines
-    Preserved for accurate source mapping
+    Preserved for accurate source mapping, only split from text by Tokenize
 
   - TokenEOF: End of input marker
 
@@ -61,19 +127,22 @@ The parser produces an AST with the following node types:
   - BlockNode: Represents a curly brace enclosed block
     Contains child nodes between braces
 
-# Usage Example
-
-Basic usage of the lexer and parser:
+The nodes marshal to JSON objects whose type field names the kind of node,
+so that parsed patterns can be stored, diffed or handed to other tools.
 
-	// Create a new lexer with input
-	lexer := NewLexer("if :[condition] { :[[body]] }")
-
-	// Tokenize the input
-	tokens := lexer.Tokenize()
+# Usage Example
 
-	// Create parser and generate AST
-	parser := NewParser(tokens)
-	ast := parser.Parse()
+Tokenize exposes the token stream of a pattern to other tools, such as
+syntax highlighters or pattern editors. Concatenating the token values gives
+back the input, and tokens can be marshaled to JSON:
+
+	tokens, err := Tokenize("if :[condition] { :[[body]] }")
+	if err != nil {
+		return err
+	}
+	for _, token := range tokens {
+		fmt.Println(token.Type, token.Position, token.Value)
+	}
 
 # Pattern Matching Rules
 
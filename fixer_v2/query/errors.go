@@ -0,0 +1,69 @@
+package query
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// SyntaxError describes why a pattern doesn't parse, and where.
+type SyntaxError struct {
+	Pattern string
+	Offset  int    // byte offset of the problem in the pattern
+	Msg     string // what is wrong, such as "unclosed hole"
+	// Expected hints at what would be valid at the offset, if known.
+	Expected string
+}
+
+// Position returns the line and column of the problem, counted from 1.
+// Columns count runes.
+func (e *SyntaxError) Position() (line, column int) {
+	before := e.Pattern[:e.Offset]
+	lineStart := strings.LastIndexByte(before, '\n') + 1
+	return strings.Count(before, "\n") + 1, utf8.RuneCountInString(before[lineStart:]) + 1
+}
+
+// Error returns the position and the description of the problem, followed
+// by the line of the pattern with a caret under the problem:
+//
+//	1:7: unclosed hole, expected ]
+//		hello :[name
+//		      ^
+func (e *SyntaxError) Error() string {
+	line, column := e.Position()
+	msg := fmt.Sprintf("%d:%d: %s", line, column, e.Msg)
+	if e.Expected != "" {
+		msg += ", expected " + e.Expected
+	}
+	return msg + "\n" + e.Snippet()
+}
+
+// Snippet returns the line of the pattern holding the problem, and a caret
+// line pointing at it, both indented by a tab.
+func (e *SyntaxError) Snippet() string {
+	lineStart := strings.LastIndexByte(e.Pattern[:e.Offset], '\n') + 1
+	lineEnd := len(e.Pattern)
+	if i := strings.IndexByte(e.Pattern[e.Offset:], '\n'); i >= 0 {
+		lineEnd = e.Offset + i
+	}
+
+	// the caret line keeps the tabs of the line so that the caret lines up
+	var caret strings.Builder
+	for _, r := range e.Pattern[lineStart:e.Offset] {
+		if r == '\t' {
+			caret.WriteByte('\t')
+		} else {
+			caret.WriteByte(' ')
+		}
+	}
+	caret.WriteByte('^')
+	return "\t" + strings.TrimSuffix(e.Pattern[lineStart:lineEnd], "\r") + "\n\t" + caret.String()
+}
+
+// Validate reports whether a pattern compiles, with a *SyntaxError locating
+// the first problem of a pattern that doesn't parse, such as an unclosed
+// hole or an unmatched brace.
+func Validate(pattern string) error {
+	_, err := Compile(pattern)
+	return err
+}
@@ -0,0 +1,79 @@
+package query
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	for _, pattern := range []string{
+		"hello :[name]",
+		"if :[cond] { :[[body]] }",
+		"func :[name](:[args]) {\n\tif :[x] {\n\t}\n}",
+	} {
+		if err := Validate(pattern); err != nil {
+			t.Errorf("Validate(%q) error = %v", pattern, err)
+		}
+	}
+
+	tests := []struct {
+		pattern  string
+		offset   int
+		msg      string
+		expected string
+	}{
+		{"hello :[name", 6, "unclosed hole", "]"},
+		{"f(:[[body]) x", 9, "unclosed hole, opened with :[[", "]]"},
+		{":[[body", 0, "unclosed hole", "]]"},
+		{"f(:[a b])", 5, `unexpected ' ' in hole`, "a name character, :type or ]"},
+		{"f(:[])", 4, `unexpected ']' in hole`, "a hole name or ["},
+		{"f(:[x:bogus])", 2, "unknown hole type: bogus", ""},
+		{"if :[x] { return }}", 18, "unmatched }", ""},
+		{"} else {", 0, "unmatched }", ""},
+		{"if :[x] {\n\treturn", 8, "unclosed block", "}"},
+		{"for { if :[x] { }", 4, "unclosed block", "}"},
+		{" \n\t", 0, "empty pattern", ""},
+	}
+	for _, tt := range tests {
+		err := Validate(tt.pattern)
+		var syntaxErr *SyntaxError
+		if !errors.As(err, &syntaxErr) {
+			t.Errorf("Validate(%q) error = %v, want a *SyntaxError", tt.pattern, err)
+			continue
+		}
+		if syntaxErr.Offset != tt.offset || syntaxErr.Msg != tt.msg || syntaxErr.Expected != tt.expected {
+			t.Errorf("Validate(%q) = {%d %q %q}, want {%d %q %q}", tt.pattern,
+				syntaxErr.Offset, syntaxErr.Msg, syntaxErr.Expected, tt.offset, tt.msg, tt.expected)
+		}
+	}
+}
+
+func TestSyntaxErrorError(t *testing.T) {
+	tests := []struct {
+		pattern string
+		want    string
+	}{
+		{
+			pattern: "hello :[name",
+			want:    "1:7: unclosed hole, expected ]\n\thello :[name\n\t      ^",
+		},
+		{
+			pattern: "if :[x] {\r\n\t\treturn }}\r\nx",
+			want:    "2:11: unmatched }\n\t\t\treturn }}\n\t\t\t        ^",
+		},
+		{
+			pattern: "f(\"é\", :[x:bogus])",
+			want:    "1:8: unknown hole type: bogus\n\tf(\"é\", :[x:bogus])\n\t       ^",
+		},
+	}
+	for _, tt := range tests {
+		err := Validate(tt.pattern)
+		if err == nil {
+			t.Errorf("Validate(%q) succeeded, want an error", tt.pattern)
+			continue
+		}
+		if got := err.Error(); got != tt.want {
+			t.Errorf("Validate(%q) error =\n%s\nwant\n%s", tt.pattern, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package query
+
+import "strings"
+
+// Parse parses a pattern into its syntax tree, failing with a *SyntaxError
+// on patterns that don't parse.
+func Parse(pattern string) (*PatternNode, error) {
+	nodes, err := NewParser().Parse(newBuffer(pattern))
+	if err != nil {
+		return nil, err
+	}
+	return &PatternNode{Children: nodes}, nil
+}
+
+// Format renders a node back to a pattern in canonical form, so that
+// patterns matching the same code format the same:
+//
+//   - runs of whitespace become a single space, and the pattern has none
+//     at its ends
+//   - blocks are spaced as in "if :[cond] { :[[body]] }", or written {}
+//     when empty
+//   - holes take the form of their line policy, :[x] or :[[x]], with the
+//     full name of their type, as in :[[body:statement]]*
+//
+// Formatting the parse of a formatted pattern gives it back.
+func Format(node Node) string {
+	var f formatter
+	f.node(node)
+	return f.sb.String()
+}
+
+type formatter struct {
+	sb strings.Builder
+	// space is whether whitespace separates the next piece from the last
+	space bool
+}
+
+func (f *formatter) node(node Node) {
+	switch n := node.(type) {
+	case *PatternNode:
+		for _, child := range n.Children {
+			f.node(child)
+		}
+	case *TextNode:
+		for _, token := range splitWhitespace(Token{Type: TokenText, Value: n.Content}) {
+			if token.Type == TokenWhitespace {
+				f.space = true
+			} else {
+				f.write(token.Value)
+			}
+		}
+	case *HoleNode:
+		f.write(formatHole(n.Config))
+	case *BlockNode:
+		f.space = true
+		f.write("{")
+		f.space = true
+		open := f.sb.Len()
+		for _, child := range n.Content {
+			f.node(child)
+		}
+		if f.sb.Len() == open {
+			f.space = false
+		} else {
+			f.space = true
+		}
+		f.write("}")
+		f.space = true
+	}
+}
+
+// write writes a piece of the pattern, after a space if one separates it
+// from the last piece.
+func (f *formatter) write(piece string) {
+	if f.space && f.sb.Len() > 0 {
+		f.sb.WriteByte(' ')
+	}
+	f.space = false
+	f.sb.WriteString(piece)
+}
+
+func formatHole(config HoleConfig) string {
+	name := config.Name
+	if config.Type != HoleAny {
+		name += ":" + config.Type.String()
+	}
+	if config.Multiline {
+		return ":[[" + name + "]]" + config.Quantifier.String()
+	}
+	return ":[" + name + "]" + config.Quantifier.String()
+}
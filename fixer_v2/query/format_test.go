@@ -0,0 +1,64 @@
+package query
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFormat(t *testing.T) {
+	tests := []struct {
+		pattern string
+		want    string
+	}{
+		{"  hello   world\n", "hello world"},
+		{"f(:[x],\t:[y])", "f(:[x], :[y])"},
+		{"if :[cond]{\n\t:[[body]]\n}", "if :[cond] { :[[body]] }"},
+		{"func :[name]() {}", "func :[name]() {}"},
+		{"func :[name]() {\n}", "func :[name]() {}"},
+		{"if :[x] { a } else{b}", "if :[x] { a } else { b }"},
+		{"for {{ :[x] }}", "for { { :[x] } }"},
+		{":[body~] :[[arg.]]", ":[[body]] :[arg]"},
+		{":[[s:stmt]]* :[e:expression]+ :[_:ident]?", ":[[s:statement]]* :[e:expression]+ :[_:identifier]?"},
+	}
+	for _, tt := range tests {
+		pattern, err := Parse(tt.pattern)
+		if err != nil {
+			t.Fatalf("Parse(%q) error = %v", tt.pattern, err)
+		}
+		got := Format(pattern)
+		if got != tt.want {
+			t.Errorf("Format(%q) = %q, want %q", tt.pattern, got, tt.want)
+		}
+
+		// the formatted pattern is canonical, and matches the same code
+		reparsed, err := Parse(got)
+		if err != nil {
+			t.Fatalf("Parse(%q) error = %v", got, err)
+		}
+		if again := Format(reparsed); again != got {
+			t.Errorf("Format(Parse(%q)) = %q, want it unchanged", got, again)
+		}
+		before, err := compileElems(tt.pattern)
+		if err != nil {
+			t.Fatalf("compileElems(%q) error = %v", tt.pattern, err)
+		}
+		after, err := compileElems(got)
+		if err != nil {
+			t.Fatalf("compileElems(%q) error = %v", got, err)
+		}
+		if !reflect.DeepEqual(before, after) {
+			t.Errorf("%q compiles to %+v, %q to %+v", tt.pattern, before, got, after)
+		}
+	}
+}
+
+func TestFormatNode(t *testing.T) {
+	hole := &HoleNode{Config: HoleConfig{Name: "x", Type: HoleNumber, Quantifier: QuantZeroOrOne}}
+	if got, want := Format(hole), ":[x:number]?"; got != want {
+		t.Errorf("Format(hole) = %q, want %q", got, want)
+	}
+	block := &BlockNode{Content: []Node{&TextNode{Content: "\n\treturn "}, hole}}
+	if got, want := Format(block), "{ return :[x:number]? }"; got != want {
+		t.Errorf("Format(block) = %q, want %q", got, want)
+	}
+}
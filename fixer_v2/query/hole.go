@@ -2,7 +2,12 @@ package query
 
 import (
 	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
 	"strings"
+	"sync"
+	"unicode"
 )
 
 // HoleType defines the type of hole pattern
@@ -14,25 +19,205 @@ const (
 	HoleBlock                      // :[[block:block]]
 	HoleWhitespace                 // :[[ws:whitespace]]
 	HoleExpression                 // :[[expr:expression]]
+	HoleStatement                  // :[[stmt:statement]]
+	HoleString                     // :[[s:string]]
+	HoleNumber                     // :[[n:number]]
 )
 
+// holeKind describes a hole type: its name in the pattern syntax and the
+// check a captured text must pass. The syntactic kinds have a short alias.
+type holeKind struct {
+	name   string
+	alias  string
+	accept func(text string) bool
+	// separators split the capture of a repeated hole into items, a comma
+	// when empty.
+	separators string
+}
+
+func acceptAll(string) bool { return true }
+
+// statementSeparators separate the statements of a block.
+const statementSeparators = ";\n"
+
+var (
+	holeKindsMu sync.RWMutex
+	// holeKinds is indexed by HoleType, custom kinds are appended after the
+	// builtin ones by RegisterHoleType.
+	holeKinds = []holeKind{
+		HoleAny:        {name: "any", accept: acceptAll},
+		HoleIdentifier: {name: "identifier", alias: "ident", accept: isIdentifier},
+		HoleBlock:      {name: "block", accept: isBlock, separators: statementSeparators},
+		HoleWhitespace: {name: "whitespace", accept: isBlank},
+		HoleExpression: {name: "expression", alias: "expr", accept: isExpression},
+		HoleStatement:  {name: "statement", alias: "stmt", accept: isStatement, separators: statementSeparators},
+		HoleString:     {name: "string", accept: isLiteral(token.STRING, token.CHAR)},
+		HoleNumber:     {name: "number", accept: isLiteral(token.INT, token.FLOAT, token.IMAG)},
+	}
+)
+
+// RegisterHoleType defines a custom hole type, usable in patterns as
+// :[[name:kind]], whose captures must pass accept. It is meant to be called
+// during the initialization of the embedding application, and returns an
+// error if the name is already taken or cannot be written in a pattern.
+func RegisterHoleType(name string, accept func(text string) bool) (HoleType, error) {
+	if name == "" {
+		return 0, fmt.Errorf("empty hole type name")
+	}
+	for i := 0; i < len(name); i++ {
+		if !isIdentChar(name[i]) {
+			return 0, fmt.Errorf("invalid hole type name %q: only letters, digits, _ and - are allowed", name)
+		}
+	}
+	if accept == nil {
+		return 0, fmt.Errorf("hole type %q has no validation function", name)
+	}
+
+	holeKindsMu.Lock()
+	defer holeKindsMu.Unlock()
+	for _, kind := range holeKinds {
+		if kind.name == name || kind.alias == name {
+			return 0, fmt.Errorf("hole type %q is already registered", name)
+		}
+	}
+	holeKinds = append(holeKinds, holeKind{name: name, accept: accept})
+	return HoleType(len(holeKinds) - 1), nil
+}
+
+func (h HoleType) kind() (holeKind, bool) {
+	holeKindsMu.RLock()
+	defer holeKindsMu.RUnlock()
+	if h < 0 || int(h) >= len(holeKinds) {
+		return holeKind{}, false
+	}
+	return holeKinds[h], true
+}
+
+// ParseHoleType returns the hole type written as name, or as its alias,
+// after the colon of a hole, builtin or registered.
+func ParseHoleType(name string) (HoleType, error) {
+	holeKindsMu.RLock()
+	defer holeKindsMu.RUnlock()
+	for i, kind := range holeKinds {
+		if kind.name == name || kind.alias != "" && kind.alias == name {
+			return HoleType(i), nil
+		}
+	}
+	return 0, fmt.Errorf("unknown hole type: %s", name)
+}
+
 func (h HoleType) String() string {
-	switch h {
-	case HoleAny:
-		return "any"
-	case HoleIdentifier:
-		return "identifier"
-	case HoleBlock:
-		return "block"
-	case HoleWhitespace:
-		return "whitespace"
-	case HoleExpression:
-		return "expression"
-	default:
-		return "unknown"
+	if kind, ok := h.kind(); ok {
+		return kind.name
+	}
+	return "unknown"
+}
+
+// Accepts reports whether text may be captured by a hole of this type.
+// Unknown types accept nothing.
+func (h HoleType) Accepts(text string) bool {
+	kind, ok := h.kind()
+	return ok && kind.accept(text)
+}
+
+// separators returns the bytes separating the items captured by a
+// repeated hole of this type.
+func (h HoleType) separators() string {
+	if kind, ok := h.kind(); ok && kind.separators != "" {
+		return kind.separators
+	}
+	return ","
+}
+
+// MarshalText encodes the hole type as its name.
+func (h HoleType) MarshalText() ([]byte, error) {
+	kind, ok := h.kind()
+	if !ok {
+		return nil, fmt.Errorf("unknown hole type %d", int(h))
+	}
+	return []byte(kind.name), nil
+}
+
+// UnmarshalText decodes a hole type from its name.
+func (h *HoleType) UnmarshalText(text []byte) error {
+	t, err := ParseHoleType(string(text))
+	if err != nil {
+		return fmt.Errorf("unknown hole type %q", text)
+	}
+	*h = t
+	return nil
+}
+
+func isIdentifier(text string) bool {
+	for i, r := range text {
+		if !unicode.IsLetter(r) && r != '_' && (i == 0 || !unicode.IsDigit(r)) {
+			return false
+		}
 	}
+	return text != "" && !token.Lookup(text).IsKeyword()
 }
 
+// The syntactic kinds parse the captured text as Go, whose syntax Gno
+// shares, in the smallest source holding the kind.
+
+func isExpression(text string) bool {
+	_, err := parser.ParseExpr(text)
+	return err == nil
+}
+
+func isBlock(text string) bool {
+	text = strings.TrimSpace(text)
+	if !strings.HasPrefix(text, "{") {
+		return false
+	}
+	// anything after the block would make a larger expression
+	expr, err := parser.ParseExpr("func()" + text)
+	_, ok := expr.(*ast.FuncLit)
+	return err == nil && ok
+}
+
+func isStatement(text string) bool {
+	const prefix = "package p; func _() {\n"
+	file, err := parser.ParseFile(token.NewFileSet(), "", prefix+text+"\n}", parser.SkipObjectResolution)
+	// a text closing the function could declare others
+	if err != nil || len(file.Decls) != 1 {
+		return false
+	}
+	return len(file.Decls[0].(*ast.FuncDecl).Body.List) == 1
+}
+
+// isLiteral returns the check of the basic literals of the kinds.
+func isLiteral(kinds ...token.Token) func(text string) bool {
+	return func(text string) bool {
+		expr, err := parser.ParseExpr(text)
+		if err != nil {
+			return false
+		}
+		lit, ok := expr.(*ast.BasicLit)
+		if !ok {
+			return false
+		}
+		for _, kind := range kinds {
+			if lit.Kind == kind {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+func isBlank(text string) bool {
+	return strings.TrimSpace(text) == ""
+}
+
+// Line policy markers, written right before the closing bracket of a hole.
+// By default short form holes (:[x]) stop at the end of the line and long
+// form holes (:[[x]]) may span several lines.
+const (
+	lineSingle = '.' // :[x.] never spans lines, like the regexp dot
+	lineMulti  = '~' // :[x~] may span lines
+)
+
 // Quantifier defines repetition patterns
 type Quantifier int
 
@@ -43,23 +228,52 @@ const (
 	QuantZeroOrOne                    // ? (zero or one time)
 )
 
+var quantifierSymbols = [...]string{
+	QuantNone:       "",
+	QuantZeroOrMore: "*",
+	QuantOneOrMore:  "+",
+	QuantZeroOrOne:  "?",
+}
+
+// ParseQuantifier returns the quantifier written as suffix after a hole,
+// QuantNone for an empty suffix.
+func ParseQuantifier(suffix string) (Quantifier, error) {
+	for q, symbol := range quantifierSymbols {
+		if symbol == suffix {
+			return Quantifier(q), nil
+		}
+	}
+	return 0, fmt.Errorf("unknown quantifier: %s", suffix)
+}
+
 func (q Quantifier) String() string {
-	switch q {
-	case QuantNone:
-		return ""
-	case QuantZeroOrMore:
-		return "*"
-	case QuantOneOrMore:
-		return "+"
-	case QuantZeroOrOne:
-		return "?"
-	default:
+	if q < 0 || int(q) >= len(quantifierSymbols) {
 		return "unknown"
 	}
+	return quantifierSymbols[q]
+}
+
+// MarshalText encodes the quantifier as its symbol, empty for QuantNone.
+func (q Quantifier) MarshalText() ([]byte, error) {
+	if q < 0 || int(q) >= len(quantifierSymbols) {
+		return nil, fmt.Errorf("unknown quantifier %d", int(q))
+	}
+	return []byte(q.String()), nil
+}
+
+// UnmarshalText decodes a quantifier from its symbol.
+func (q *Quantifier) UnmarshalText(text []byte) error {
+	quant, err := ParseQuantifier(string(text))
+	if err != nil {
+		return fmt.Errorf("unknown quantifier %q", text)
+	}
+	*q = quant
+	return nil
 }
 
 // ParseHolePattern parses a hole pattern string and returns a HoleConfig
-// Format: :[[name:type]] or :[[name:type]]*
+// Format: :[[name:type]] or :[[name:type]]*, with an optional line policy
+// marker before the closing bracket such as :[name:type~]
 func ParseHolePattern(pattern string) (*HoleConfig, error) {
 	// Skip : and opening brackets
 	start := 1
@@ -92,41 +306,47 @@ func ParseHolePattern(pattern string) (*HoleConfig, error) {
 		return nil, fmt.Errorf("invalid hole pattern: %s", pattern)
 	}
 
-	// Parse name and type
+	// Parse line policy, defaulting to the form of the hole
 	content := pattern[start : end+1]
+	multiline := start == 3
+	switch content[len(content)-1] {
+	case lineSingle:
+		multiline = false
+		content = content[:len(content)-1]
+	case lineMulti:
+		multiline = true
+		content = content[:len(content)-1]
+	}
+	if content == "" {
+		return nil, fmt.Errorf("invalid hole pattern: %s", pattern)
+	}
+
+	// Parse name and type
 	parts := strings.Split(content, ":")
 	config := &HoleConfig{
-		Name:       parts[0],
-		Type:       HoleAny,
-		Quantifier: QuantNone,
+		Name:        parts[0],
+		Type:        HoleAny,
+		Quantifier:  QuantNone,
+		Multiline:   multiline,
+		Independent: strings.HasPrefix(parts[0], "_"),
 	}
 
 	// Parse type if specified
 	if len(parts) > 1 {
-		switch parts[1] {
-		case "identifier":
-			config.Type = HoleIdentifier
-		case "block":
-			config.Type = HoleBlock
-		case "whitespace":
-			config.Type = HoleWhitespace
-		case "expression":
-			config.Type = HoleExpression
-		default:
-			return nil, fmt.Errorf("unknown hole type: %s", parts[1])
+		holeType, err := ParseHoleType(parts[1])
+		if err != nil {
+			return nil, err
 		}
+		config.Type = holeType
 	}
 
 	// Set quantifier if found earlier
 	if hasQuantifier {
-		switch pattern[len(pattern)-1] {
-		case '*':
-			config.Quantifier = QuantZeroOrMore
-		case '+':
-			config.Quantifier = QuantOneOrMore
-		case '?':
-			config.Quantifier = QuantZeroOrOne
+		quantifier, err := ParseQuantifier(pattern[len(pattern)-1:])
+		if err != nil {
+			return nil, err
 		}
+		config.Quantifier = quantifier
 	}
 
 	return config, nil
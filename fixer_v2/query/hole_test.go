@@ -1,6 +1,8 @@
 package query
 
 import (
+	"encoding/json"
+	"strings"
 	"testing"
 )
 
@@ -27,6 +29,7 @@ func TestParseHolePattern(t *testing.T) {
 				Name:       "name",
 				Type:       HoleIdentifier,
 				Quantifier: QuantNone,
+				Multiline:  true,
 			},
 		},
 		{
@@ -36,6 +39,7 @@ func TestParseHolePattern(t *testing.T) {
 				Name:       "block",
 				Type:       HoleBlock,
 				Quantifier: QuantZeroOrMore,
+				Multiline:  true,
 			},
 		},
 		{
@@ -45,6 +49,7 @@ func TestParseHolePattern(t *testing.T) {
 				Name:       "expr",
 				Type:       HoleExpression,
 				Quantifier: QuantOneOrMore,
+				Multiline:  true,
 			},
 		},
 		{
@@ -54,6 +59,7 @@ func TestParseHolePattern(t *testing.T) {
 				Name:       "ws",
 				Type:       HoleWhitespace,
 				Quantifier: QuantZeroOrOne,
+				Multiline:  true,
 			},
 		},
 		{
@@ -61,6 +67,41 @@ func TestParseHolePattern(t *testing.T) {
 			pattern: ":[[var:invalid]]",
 			wantErr: true,
 		},
+		{
+			name:    "short hole spanning lines",
+			pattern: ":[body~]",
+			wantConfig: &HoleConfig{
+				Name:       "body",
+				Type:       HoleAny,
+				Quantifier: QuantNone,
+				Multiline:  true,
+			},
+		},
+		{
+			name:    "long hole on a single line",
+			pattern: ":[[arg:expression.]]+",
+			wantConfig: &HoleConfig{
+				Name:       "arg",
+				Type:       HoleExpression,
+				Quantifier: QuantOneOrMore,
+				Multiline:  false,
+			},
+		},
+		{
+			name:    "independent hole",
+			pattern: ":[_arg]",
+			wantConfig: &HoleConfig{
+				Name:        "_arg",
+				Type:        HoleAny,
+				Quantifier:  QuantNone,
+				Independent: true,
+			},
+		},
+		{
+			name:    "line policy without name",
+			pattern: ":[~]",
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -79,6 +120,230 @@ func TestParseHolePattern(t *testing.T) {
 	}
 }
 
+func TestHoleLinePolicy(t *testing.T) {
+	tests := []struct {
+		input     string
+		multiline bool
+		str       string
+		wantErr   bool
+	}{
+		{input: ":[x]", multiline: false, str: "HoleNode(x)"},
+		{input: ":[[x]]", multiline: true, str: "HoleNode(x~)"},
+		{input: ":[x~]", multiline: true, str: "HoleNode(x~)"},
+		{input: ":[[x.]]", multiline: false, str: "HoleNode(x)"},
+		{input: ":[x:block~]*", multiline: true, str: "HoleNode(x:block~)*"},
+		{input: ":[[x:identifier.]]?", multiline: false, str: "HoleNode(x:identifier)?"},
+		{input: ":[x.y]", wantErr: true},
+		{input: ":[x~.]", wantErr: true},
+		{input: ":[.]", wantErr: true},
+		{input: ":[x:~]", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			nodes, err := NewParser().Parse(newBuffer(tt.input))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Parse() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			hole, ok := nodes[0].(*HoleNode)
+			if !ok {
+				t.Fatalf("Parse() = %v, want a hole", nodes)
+			}
+			if hole.Config.Multiline != tt.multiline {
+				t.Errorf("Multiline = %v, want %v", hole.Config.Multiline, tt.multiline)
+			}
+			if hole.String() != tt.str {
+				t.Errorf("String() = %q, want %q", hole.String(), tt.str)
+			}
+
+			// the line policy tells otherwise identical holes apart
+			other := *hole
+			other.Config.Multiline = !other.Config.Multiline
+			if hole.Equal(&other) {
+				t.Errorf("holes with different line policies are equal")
+			}
+		})
+	}
+}
+
+func TestHoleTypeString(t *testing.T) {
+	// the names are part of the pattern syntax and of the JSON encoding
+	tests := map[HoleType]string{
+		HoleAny:        "any",
+		HoleIdentifier: "identifier",
+		HoleBlock:      "block",
+		HoleWhitespace: "whitespace",
+		HoleExpression: "expression",
+		HoleStatement:  "statement",
+		HoleString:     "string",
+		HoleNumber:     "number",
+		HoleType(-1):   "unknown",
+		HoleType(1000): "unknown",
+	}
+	seen := make(map[string]HoleType)
+	for typ, want := range tests {
+		got := typ.String()
+		if got != want {
+			t.Errorf("HoleType(%d).String() = %q, want %q", int(typ), got, want)
+		}
+		if got == "unknown" {
+			continue
+		}
+		if other, ok := seen[got]; ok {
+			t.Errorf("HoleType(%d) and HoleType(%d) are both named %q", int(typ), int(other), got)
+		}
+		seen[got] = typ
+
+		parsed, err := ParseHoleType(got)
+		if err != nil || parsed != typ {
+			t.Errorf("ParseHoleType(%q) = %v, %v, want %v", got, parsed, err, typ)
+		}
+	}
+
+	for alias, want := range map[string]HoleType{"ident": HoleIdentifier, "expr": HoleExpression, "stmt": HoleStatement} {
+		if got, err := ParseHoleType(alias); err != nil || got != want {
+			t.Errorf("ParseHoleType(%q) = %v, %v, want %v", alias, got, err, want)
+		}
+	}
+	if _, err := ParseHoleType("unknown"); err == nil {
+		t.Error("ParseHoleType() accepted an unknown hole type")
+	}
+}
+
+func TestQuantifierString(t *testing.T) {
+	tests := map[Quantifier]string{
+		QuantNone:       "",
+		QuantZeroOrMore: "*",
+		QuantOneOrMore:  "+",
+		QuantZeroOrOne:  "?",
+		Quantifier(42):  "unknown",
+	}
+	seen := make(map[string]Quantifier)
+	for quant, want := range tests {
+		got := quant.String()
+		if got != want {
+			t.Errorf("Quantifier(%d).String() = %q, want %q", int(quant), got, want)
+		}
+		if got == "unknown" {
+			continue
+		}
+		if other, ok := seen[got]; ok {
+			t.Errorf("Quantifier(%d) and Quantifier(%d) are both written %q", int(quant), int(other), got)
+		}
+		seen[got] = quant
+
+		parsed, err := ParseQuantifier(got)
+		if err != nil || parsed != quant {
+			t.Errorf("ParseQuantifier(%q) = %v, %v, want %v", got, parsed, err, quant)
+		}
+	}
+
+	for _, invalid := range []string{"**", "!", "unknown"} {
+		if _, err := ParseQuantifier(invalid); err == nil {
+			t.Errorf("ParseQuantifier(%q) accepted an unknown quantifier", invalid)
+		}
+	}
+}
+
+func TestHoleTypeAccepts(t *testing.T) {
+	tests := []struct {
+		typ  HoleType
+		text string
+		want bool
+	}{
+		{HoleAny, "a + b", true},
+		{HoleIdentifier, "_name2", true},
+		{HoleIdentifier, "héllo", true},
+		{HoleIdentifier, "2name", false},
+		{HoleIdentifier, "a.b", false},
+		{HoleIdentifier, "", false},
+		{HoleIdentifier, "func", false},
+		{HoleWhitespace, " \t\n", true},
+		{HoleWhitespace, " x ", false},
+		{HoleBlock, "{ return }", true},
+		{HoleBlock, "{\n\tx := 1\n\tf(x)\n}", true},
+		{HoleBlock, "{ a } else { b }", false},
+		{HoleBlock, "{}.x", false},
+		{HoleBlock, "f()", false},
+		{HoleExpression, "f(x)", true},
+		{HoleExpression, "a + b[i]", true},
+		{HoleExpression, "a +", false},
+		{HoleExpression, "x := 1", false},
+		{HoleStatement, "x := f()", true},
+		{HoleStatement, "if x { y() }", true},
+		{HoleStatement, "x(); y()", false},
+		{HoleStatement, "x()\n}\nfunc g() {\ny()", false},
+		{HoleStatement, "", false},
+		{HoleString, `"a\"b"`, true},
+		{HoleString, "`raw`", true},
+		{HoleString, "'c'", true},
+		{HoleString, `"a" + "b"`, false},
+		{HoleNumber, "0x1F", true},
+		{HoleNumber, "1.5e3", true},
+		{HoleNumber, "-1", false},
+		{HoleNumber, `"1"`, false},
+		{HoleType(1000), "x", false},
+	}
+	for _, tt := range tests {
+		if got := tt.typ.Accepts(tt.text); got != tt.want {
+			t.Errorf("%v.Accepts(%q) = %v, want %v", tt.typ, tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestRegisterHoleType(t *testing.T) {
+	hex, err := RegisterHoleType("hex-literal", func(text string) bool {
+		return strings.HasPrefix(text, "0x")
+	})
+	if err != nil {
+		t.Fatalf("RegisterHoleType() error = %v", err)
+	}
+	if hex <= HoleNumber {
+		t.Errorf("RegisterHoleType() = %d, reusing a builtin hole type", int(hex))
+	}
+	if got := hex.String(); got != "hex-literal" {
+		t.Errorf("String() = %q, want %q", got, "hex-literal")
+	}
+	if !hex.Accepts("0xff") || hex.Accepts("255") {
+		t.Error("Accepts() does not use the registered validation function")
+	}
+
+	config, err := ParseHolePattern(":[[n:hex-literal]]+")
+	if err != nil {
+		t.Fatalf("ParseHolePattern() error = %v", err)
+	}
+	if config.Type != hex || config.Quantifier != QuantOneOrMore {
+		t.Errorf("ParseHolePattern() = %+v, want a hex-literal hole", config)
+	}
+
+	node := &HoleNode{Config: *config}
+	if got, want := node.String(), "HoleNode(n:hex-literal~)+"; got != want {
+		t.Errorf("HoleNode.String() = %q, want %q", got, want)
+	}
+
+	data, err := json.Marshal(config)
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+	var decoded HoleConfig
+	if err := json.Unmarshal(data, &decoded); err != nil || !decoded.Equal(*config) {
+		t.Errorf("json round trip = %+v, %v, want %+v", decoded, err, *config)
+	}
+
+	for _, name := range []string{"hex-literal", "identifier", "", "two words", "a:b"} {
+		if _, err := RegisterHoleType(name, func(string) bool { return true }); err == nil {
+			t.Errorf("RegisterHoleType(%q) succeeded", name)
+		}
+	}
+	if _, err := RegisterHoleType("no-check", nil); err == nil {
+		t.Error("RegisterHoleType() accepted a nil validation function")
+	}
+}
+
 func BenchmarkParseHolePattern(b *testing.B) {
 	patterns := []struct {
 		name    string
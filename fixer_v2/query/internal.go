@@ -72,7 +72,8 @@ const __ States = -1
 //   - TX (10) - Processing regular text
 //   - WS (11) - Processing whitespace
 //   - BR (12) - Processing block delimiters ({, })
-//   - ER (13) - Error state (invalid input)
+//   - LP (13) - After a line policy marker (. or ~), expecting closing bracket
+//   - ER (14) - Error state (invalid input)
 //
 // The state numbering is significant - states <= OK are final states,
 // allowing for efficient loop termination with a single comparison.
@@ -90,6 +91,7 @@ const (
 	TX               // Reading text state
 	WS               // Reading whitespace state
 	BR               // Reading block state ({, })
+	LP               // After line policy state (. or ~)
 	ER               // Error state
 )
 
@@ -103,6 +105,7 @@ const (
 	C_SPACE                 // Whitespace characters (space, tab, newline)
 	C_IDENT                 // Identifier characters (alphanumeric, _, -)
 	C_QUANT                 // Quantifiers (*, +, ?)
+	C_LINE                  // Line policy markers inside a hole (., ~)
 	C_OTHER                 // Any other character
 )
 
@@ -112,22 +115,24 @@ const (
 //  2. CB and QB states allow whitespace transitions for better error recovery
 //  3. After quantifiers (QT), we can continue with any valid pattern start
 //  4. TX (text) state allows transitioning back to pattern parsing
-var StateTransitionTable = [14][9]States{
-	//         COLON   LBRACK RBRACK LBRACE RBRACE SPACE  IDENT  QUANT  OTHER
-	/* GO 0*/ {CL, OB, ER, BR, BR, WS, TX, ER, ER},
-	/* OK 1*/ {CL, OB, ER, BR, BR, WS, TX, ER, ER},
-	/* CL 2*/ {TX, OB, ER, ER, ER, ER, ID, ER, ER},
-	/* OB 3*/ {TX, DB, ER, ER, ER, ER, NM, ER, ER},
-	/* DB 4*/ {TX, ER, ER, ER, ER, ER, NM, ER, ER},
-	/* NM 5*/ {ID, ER, CB, ER, ER, ER, NM, ER, ER},
-	/* ID 6*/ {ER, ER, CB, ER, ER, ER, ID, ER, ER},
-	/* CB 7*/ {OK, ER, QB, ER, ER, WS, TX, QT, ER},
-	/* QB 8*/ {OK, ER, ER, ER, ER, WS, TX, QT, ER},
-	/* QT 9*/ {CL, ER, ER, BR, BR, WS, TX, ER, ER},
-	/* TX10*/ {CL, OB, CB, BR, BR, WS, TX, QT, ER},
-	/* WS11*/ {CL, ER, ER, BR, BR, WS, TX, ER, ER},
-	/* BR12*/ {CL, ER, ER, BR, OK, WS, TX, ER, ER},
-	/* ER13*/ {ER, ER, ER, ER, ER, ER, ER, ER, ER},
+//  5. A line policy marker may only end the name or type, right before the closing bracket
+var StateTransitionTable = [15][10]States{
+	//         COLON   LBRACK RBRACK LBRACE RBRACE SPACE  IDENT  QUANT  LINE  OTHER
+	/* GO 0*/ {CL, OB, ER, BR, BR, WS, TX, ER, ER, ER},
+	/* OK 1*/ {CL, OB, ER, BR, BR, WS, TX, ER, ER, ER},
+	/* CL 2*/ {TX, OB, ER, ER, ER, ER, ID, ER, ER, ER},
+	/* OB 3*/ {TX, DB, ER, ER, ER, ER, NM, ER, ER, ER},
+	/* DB 4*/ {TX, ER, ER, ER, ER, ER, NM, ER, ER, ER},
+	/* NM 5*/ {ID, ER, CB, ER, ER, ER, NM, ER, LP, ER},
+	/* ID 6*/ {ER, ER, CB, ER, ER, ER, ID, ER, LP, ER},
+	/* CB 7*/ {OK, ER, QB, ER, ER, WS, TX, QT, ER, ER},
+	/* QB 8*/ {OK, ER, ER, ER, ER, WS, TX, QT, ER, ER},
+	/* QT 9*/ {CL, ER, ER, BR, BR, WS, TX, ER, ER, ER},
+	/* TX10*/ {CL, OB, CB, BR, BR, WS, TX, QT, ER, ER},
+	/* WS11*/ {CL, ER, ER, BR, BR, WS, TX, ER, ER, ER},
+	/* BR12*/ {CL, ER, ER, BR, OK, WS, TX, ER, ER, ER},
+	/* LP13*/ {ER, ER, CB, ER, ER, ER, ER, ER, ER, ER},
+	/* ER14*/ {ER, ER, ER, ER, ER, ER, ER, ER, ER, ER},
 }
 
 func (c Classes) String() string {
@@ -148,6 +153,8 @@ func (c Classes) String() string {
 		return "IDENT"
 	case C_QUANT:
 		return "QUANT"
+	case C_LINE:
+		return "LINE"
 	case C_OTHER:
 		return "OTHER"
 	default:
@@ -190,6 +197,9 @@ func getCharacterClass(c byte, mode CharClassMode) Classes {
 
 	switch mode {
 	case ModeHole:
+		if c == lineSingle || c == lineMulti {
+			return C_LINE
+		}
 		// in metavariable hole, we allow only identifier characters
 		if isIdentChar(c) {
 			return C_IDENT
@@ -0,0 +1,29 @@
+package query
+
+import "go/token"
+
+// Location is the range of a match in a file, as the positions of an issue
+// or of a fix.
+type Location struct {
+	Start token.Position
+	End   token.Position
+}
+
+// Location returns the positions of the match in file, the token.File of
+// the source it was found in: the one of the parsed file for FindNodes,
+// or one made by SourceFile.
+func (m Match) Location(file *token.File) Location {
+	return Location{
+		Start: file.Position(file.Pos(m.Start)),
+		End:   file.Position(file.Pos(m.End)),
+	}
+}
+
+// SourceFile returns a token.File of the source of a file, mapping the
+// offsets of the matches found by FindAll to lines and columns when the
+// file is not parsed.
+func SourceFile(filename string, src []byte) *token.File {
+	file := token.NewFileSet().AddFile(filename, -1, len(src))
+	file.SetLinesForContent(src)
+	return file
+}
@@ -0,0 +1,55 @@
+package query
+
+import (
+	"go/parser"
+	"go/token"
+	"testing"
+)
+
+func TestMatchLocation(t *testing.T) {
+	src := "package a\n\nfunc f() {\n\tpanic(\"a\")\n\tpanic(\n\t\t\"b\",\n\t)\n}\n"
+	m, err := Compile("panic(:[[x]])")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Location{
+		{
+			Start: token.Position{Filename: "a.gno", Offset: 23, Line: 4, Column: 2},
+			End:   token.Position{Filename: "a.gno", Offset: 33, Line: 4, Column: 12},
+		},
+		{
+			Start: token.Position{Filename: "a.gno", Offset: 35, Line: 5, Column: 2},
+			End:   token.Position{Filename: "a.gno", Offset: 51, Line: 7, Column: 3},
+		},
+	}
+
+	matches, err := m.FindAll([]byte(src))
+	if err != nil || len(matches) != len(want) {
+		t.Fatalf("FindAll() = %v, %v, want %d matches", matches, err, len(want))
+	}
+	file := SourceFile("a.gno", []byte(src))
+	for i, match := range matches {
+		if got := match.Location(file); got != want[i] {
+			t.Errorf("Location() = %v, want %v", got, want[i])
+		}
+	}
+
+	fset := token.NewFileSet()
+	node, err := parser.ParseFile(fset, "a.gno", src, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	matches, err = m.FindNodes(fset, node, []byte(src))
+	if err != nil || len(matches) != len(want) {
+		t.Fatalf("FindNodes() = %v, %v, want %d matches", matches, err, len(want))
+	}
+	for i, match := range matches {
+		got := match.Location(fset.File(node.Pos()))
+		if got != want[i] {
+			t.Errorf("Location() = %v, want %v", got, want[i])
+		}
+		if start := fset.Position(match.Node.Pos()); start != got.Start {
+			t.Errorf("node starts at %v, want %v", start, got.Start)
+		}
+	}
+}
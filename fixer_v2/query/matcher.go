@@ -1,8 +1,593 @@
 package query
 
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
 // Match represents a single pattern match result
 type Match struct {
 	Start    int               // Start position in source
 	End      int               // End position in source
 	Bindings map[string]string // Meta-variable bindings
+	Node     ast.Node          // Matched syntax node, with FindNodes
+}
+
+// Matcher finds the code matching a pattern, see Compile.
+type Matcher struct {
+	// InComments lets matches start inside comments, for rewriting their
+	// text, where quotes are no string delimiters. Such matches end in the
+	// comment.
+	InComments bool
+	// MaxBacktrack bounds the captures a match attempt may try for its
+	// holes, DefaultMaxBacktrack when zero, so that patterns with many
+	// holes can't take exponential time.
+	MaxBacktrack int
+
+	elems      []elem
+	conditions []condition
+}
+
+// DefaultMaxBacktrack is the number of captures a match attempt tries by
+// default before giving up.
+const DefaultMaxBacktrack = 100_000
+
+// ErrBacktrackLimit is returned by FindAll when a match attempt tried more
+// captures than allowed by MaxBacktrack.
+var ErrBacktrackLimit = errors.New("pattern exceeded the backtracking limit")
+
+type elemKind int
+
+const (
+	elemText  elemKind = iota // literal text
+	elemSpace                 // a run of whitespace
+	elemHole                  // a metavariable
+)
+
+// elem is a step of a compiled pattern. Blocks are flattened into their
+// braces and content: holes never capture unbalanced braces, so the content
+// of a block can't run past its closing brace.
+type elem struct {
+	kind elemKind
+	text string
+	// required is set for the whitespace and the holes that can't match
+	// nothing.
+	required bool
+	hole     HoleConfig
+}
+
+// anonymousHole is the name of the holes binding nothing, which may
+// capture different texts.
+const anonymousHole = "_"
+
+// Compile parses a pattern for matching code:
+//
+//   - text matches itself, except for whitespace: a run of whitespace
+//     matches any run of whitespace, or none at all next to punctuation
+//   - a block matches braces around code matching its content
+//   - a hole captures code whose parentheses, brackets and braces are
+//     balanced, strings and comments aside, stopping at the end of the line
+//     unless it may span lines. Holes capture the shortest code letting the
+//     rest of the pattern match, except a hole ending the pattern, which
+//     captures as much as it can.
+//   - a quantifier after a hole repeats its type: :[[s:stmt]]* captures zero
+//     or more statements, separated by semicolons or newlines, and
+//     :[args:expr]+ one or more expressions separated by commas, while
+//     :[x:expr]? captures an expression or nothing. Holes with a quantifier
+//     capture as much as they can.
+//
+// A hole may capture nothing, unless it starts or ends the pattern. A hole
+// named more than once must capture the same code every time, whitespace
+// aside, unless it is independent: the holes whose name starts with _ capture
+// any code, and bind what the first of them captured, but for _ which binds
+// nothing.
+//
+// The patterns compiled last are cached, so compiling a pattern again is
+// cheap. Each call returns its own Matcher, which may be used by several
+// goroutines once configured.
+func Compile(pattern string) (*Matcher, error) {
+	if elems, ok := compiledPatterns.get(pattern); ok {
+		return &Matcher{elems: elems}, nil
+	}
+	elems, err := compileElems(pattern)
+	if err != nil {
+		return nil, err
+	}
+	compiledPatterns.add(pattern, elems)
+	return &Matcher{elems: elems}, nil
+}
+
+// compileElems compiles a pattern into the steps of its matching, which
+// are never modified afterwards.
+func compileElems(pattern string) ([]elem, error) {
+	nodes, err := NewParser().Parse(newBuffer(pattern))
+	if err != nil {
+		return nil, err
+	}
+
+	var elems []elem
+	flattenNodes(nodes, &elems)
+	// whitespace at the ends of the pattern doesn't belong to the match
+	for len(elems) > 0 && elems[0].kind == elemSpace {
+		elems = elems[1:]
+	}
+	for len(elems) > 0 && elems[len(elems)-1].kind == elemSpace {
+		elems = elems[:len(elems)-1]
+	}
+	if len(elems) == 0 {
+		return nil, &SyntaxError{Pattern: pattern, Msg: "empty pattern"}
+	}
+
+	for i := range elems {
+		switch elems[i].kind {
+		case elemSpace:
+			elems[i].required = i > 0 && i < len(elems)-1 &&
+				endsWord(elems[i-1]) && startsWord(elems[i+1])
+		case elemHole:
+			// a match doesn't start or end with nothing captured
+			elems[i].required = i == 0 || i == len(elems)-1
+		}
+	}
+	return elems, nil
+}
+
+// Holes returns the names of the holes binding what they capture, in the
+// order of the pattern.
+func (m *Matcher) Holes() []string {
+	var names []string
+	seen := make(map[string]bool)
+	for _, e := range m.elems {
+		name := e.hole.Name
+		if e.kind == elemHole && name != anonymousHole && !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+func flattenNodes(nodes []Node, elems *[]elem) {
+	for _, node := range nodes {
+		switch n := node.(type) {
+		case *TextNode:
+			for _, token := range splitWhitespace(Token{Type: TokenText, Value: n.Content}) {
+				if token.Type == TokenWhitespace {
+					appendSpace(elems)
+				} else {
+					*elems = append(*elems, elem{kind: elemText, text: token.Value})
+				}
+			}
+		case *HoleNode:
+			*elems = append(*elems, elem{kind: elemHole, hole: n.Config})
+		case *BlockNode:
+			appendSpace(elems)
+			*elems = append(*elems, elem{kind: elemText, text: "{"})
+			appendSpace(elems)
+			flattenNodes(n.Content, elems)
+			appendSpace(elems)
+			*elems = append(*elems, elem{kind: elemText, text: "}"})
+			appendSpace(elems)
+		}
+	}
+}
+
+// appendSpace appends a run of whitespace, unless one precedes it.
+func appendSpace(elems *[]elem) {
+	if n := len(*elems); n > 0 && (*elems)[n-1].kind == elemSpace {
+		return
+	}
+	*elems = append(*elems, elem{kind: elemSpace})
+}
+
+func endsWord(e elem) bool {
+	if e.kind == elemHole {
+		return true
+	}
+	r, _ := utf8.DecodeLastRuneInString(e.text)
+	return isWordRune(r)
+}
+
+func startsWord(e elem) bool {
+	if e.kind == elemHole {
+		return true
+	}
+	r, _ := utf8.DecodeRuneInString(e.text)
+	return isWordRune(r)
+}
+
+func isWordRune(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// FindAll returns the matches of the pattern in src, in order. Matches
+// don't overlap, and never start with whitespace nor inside a string or a
+// comment, but for comments with InComments, and satisfy the conditions
+// of Where. When a match attempt exceeds MaxBacktrack, the search goes on
+// after it and FindAll returns the matches found along with
+// ErrBacktrackLimit.
+func (m *Matcher) FindAll(src []byte) ([]Match, error) {
+	text := string(src)
+	var (
+		matches []Match
+		err     error
+	)
+	for pos := 0; pos < len(text); {
+		if isWhitespace(text[pos]) {
+			pos++
+			continue
+		}
+		if match, ok := m.find(text, pos, scopeCode, &err); ok {
+			matches = append(matches, match)
+			pos = match.End
+			continue
+		}
+		if !startsLiteral(text, pos) {
+			_, size := utf8.DecodeRuneInString(text[pos:])
+			pos += size
+			continue
+		}
+
+		end := skipLiteral(text, pos)
+		if m.InComments && text[pos] == '/' {
+			// the matches in a comment stay in it
+			for inner := pos + 1; inner < end; {
+				match, ok := m.find(text[:end], inner, scopeComment, &err)
+				if !ok {
+					_, size := utf8.DecodeRuneInString(text[inner:])
+					inner += size
+					continue
+				}
+				matches = append(matches, match)
+				inner = match.End
+			}
+		}
+		pos = end
+	}
+	return matches, err
+}
+
+// scope is the kind of source a match attempt takes place in.
+type scope int
+
+const (
+	scopeCode    scope = iota // code, where a match may end anywhere
+	scopeComment              // the text of a comment, where quotes are no string delimiters
+	scopeNode                 // a syntax node, which a match spans whole
+)
+
+// find matches the pattern at pos, checks the conditions of the match and
+// records the first error in err.
+func (m *Matcher) find(src string, pos int, s scope, err *error) (Match, bool) {
+	match, ok, exceeded := m.matchAt(src, pos, s)
+	if exceeded && *err == nil {
+		*err = fmt.Errorf("%w at offset %d", ErrBacktrackLimit, pos)
+	}
+	if ok {
+		var condErr error
+		if ok, condErr = m.holds(match); condErr != nil && *err == nil {
+			*err = fmt.Errorf("where clause at offset %d: %w", pos, condErr)
+		}
+	}
+	return match, ok
+}
+
+// matchAt matches the pattern at pos. It reports whether the attempt
+// exceeded MaxBacktrack.
+func (m *Matcher) matchAt(src string, pos int, s scope) (match Match, ok, exceeded bool) {
+	if isWhitespace(src[pos]) {
+		return Match{}, false, false
+	}
+	budget := m.MaxBacktrack
+	if budget <= 0 {
+		budget = DefaultMaxBacktrack
+	}
+	r := &matchRun{elems: m.elems, src: src, scope: s, budget: budget, bindings: make(map[string]string)}
+	end, ok := r.match(0, pos)
+	if !ok || end == pos {
+		return Match{}, false, r.budget < 0
+	}
+	return Match{Start: pos, End: end, Bindings: r.bindings}, true, false
+}
+
+// matchRun matches the pattern at a position of the source, backtracking
+// over the ends of the holes.
+type matchRun struct {
+	elems []elem
+	src   string
+	scope scope
+	// budget is the number of captures left to try, negative once the
+	// attempt gave up
+	budget   int
+	bindings map[string]string
+}
+
+// try spends a capture of the budget, and reports whether the run may go
+// on.
+func (r *matchRun) try() bool {
+	r.budget--
+	return r.budget >= 0
+}
+
+// match matches the elements from i on at pos, and returns the end of the
+// match.
+func (r *matchRun) match(i, pos int) (int, bool) {
+	if i == len(r.elems) {
+		// a node is matched whole
+		return pos, r.scope != scopeNode || pos == len(r.src)
+	}
+
+	e := r.elems[i]
+	switch e.kind {
+	case elemText:
+		if !strings.HasPrefix(r.src[pos:], e.text) {
+			return 0, false
+		}
+		return r.match(i+1, pos+len(e.text))
+
+	case elemSpace:
+		end := pos
+		for end < len(r.src) && isWhitespace(r.src[end]) {
+			end++
+		}
+		if e.required && end == pos {
+			return 0, false
+		}
+		return r.match(i+1, end)
+	}
+
+	// a hole named before captures the same code, whitespace aside
+	name := e.hole.Name
+	bound, isBound := r.bindings[name]
+	backReference := isBound && !e.hole.Independent
+	accepts := func(text string) bool {
+		if !r.try() {
+			return false
+		}
+		if backReference {
+			return normalizeSpace(text) == normalizeSpace(bound)
+		}
+		return acceptsCapture(e, text)
+	}
+
+	ends := holeEnds(r.src, pos, e.hole.Multiline, r.scope == scopeComment)
+	if e.hole.Quantifier != QuantNone {
+		// repeated and optional holes are greedy
+		slices.Reverse(ends)
+	}
+	if i == len(r.elems)-1 && r.scope != scopeNode {
+		// nothing follows to bound the last hole, which captures all it can
+		for j := len(ends) - 1; j >= 0; j-- {
+			text := strings.TrimRightFunc(r.src[pos:ends[j]], unicode.IsSpace)
+			if r.budget < 0 {
+				return 0, false
+			}
+			if accepts(text) {
+				if !isBound {
+					r.bind(name, text)
+				}
+				return pos + len(text), true
+			}
+		}
+		return 0, false
+	}
+	// greedy holes leave the whitespace after them to the pattern
+	trim := e.hole.Quantifier != QuantNone && i+1 < len(r.elems) && r.elems[i+1].kind == elemSpace
+	for _, end := range ends {
+		if r.budget < 0 {
+			return 0, false
+		}
+		text := r.src[pos:end]
+		if trim && strings.TrimRightFunc(text, unicode.IsSpace) != text {
+			continue
+		}
+		if !accepts(text) {
+			continue
+		}
+		if !isBound {
+			r.bind(name, text)
+		}
+		if matchEnd, ok := r.match(i+1, end); ok {
+			return matchEnd, true
+		}
+		if !isBound {
+			delete(r.bindings, name)
+		}
+	}
+	return 0, false
+}
+
+func (r *matchRun) bind(name, text string) {
+	if name != anonymousHole {
+		r.bindings[name] = text
+	}
+}
+
+// normalizeSpace removes the whitespace of the text, but for a single space
+// between words.
+func normalizeSpace(text string) string {
+	var (
+		sb    strings.Builder
+		last  rune
+		space bool
+	)
+	for _, r := range text {
+		if unicode.IsSpace(r) {
+			space = true
+			continue
+		}
+		if space && isWordRune(last) && isWordRune(r) {
+			sb.WriteByte(' ')
+		}
+		sb.WriteRune(r)
+		last, space = r, false
+	}
+	return sb.String()
+}
+
+// acceptsCapture reports whether a hole may capture the text. A quantifier
+// making the hole optional lets it capture nothing in any case, and a
+// repeated hole captures a list of items of its type.
+func acceptsCapture(e elem, text string) bool {
+	switch e.hole.Quantifier {
+	case QuantZeroOrOne:
+		return text == "" || e.hole.Type.Accepts(text)
+	case QuantZeroOrMore, QuantOneOrMore:
+		items := splitItems(text, e.hole.Type.separators())
+		if len(items) == 0 {
+			return e.hole.Quantifier == QuantZeroOrMore
+		}
+		for _, item := range items {
+			if !e.hole.Type.Accepts(item) {
+				return false
+			}
+		}
+		return true
+	}
+	if text == "" && e.required {
+		return false
+	}
+	return e.hole.Type.Accepts(text)
+}
+
+// splitItems splits the text at the separators outside of parentheses,
+// brackets, braces, strings and comments, and returns the items without
+// their surrounding whitespace, empty ones and comments aside.
+func splitItems(text, separators string) []string {
+	var (
+		items []string
+		depth int
+		start int
+	)
+	for i := 0; i <= len(text); {
+		if i == len(text) || depth == 0 && strings.IndexByte(separators, text[i]) >= 0 {
+			if item := strings.TrimSpace(text[start:i]); !isComment(item) {
+				items = append(items, item)
+			}
+			start = i + 1
+			i++
+			continue
+		}
+		switch c := text[i]; {
+		case c == '(' || c == '[' || c == '{':
+			depth++
+		case c == ')' || c == ']' || c == '}':
+			depth--
+		case startsLiteral(text, i):
+			i = skipLiteral(text, i)
+			continue
+		}
+		i++
+	}
+	return items
+}
+
+// holeEnds returns the positions a hole starting at pos may end at, in
+// increasing order: those where the parentheses, brackets and braces opened
+// since pos are closed. The hole stops before an unbalanced closing
+// delimiter and, unless multiline, at the end of the line. Strings and
+// comments are captured whole, unless in prose.
+func holeEnds(src string, pos int, multiline, prose bool) []int {
+	var (
+		ends  []int
+		stack []byte
+	)
+	for i := pos; ; {
+		if len(stack) == 0 {
+			ends = append(ends, i)
+		}
+		if i >= len(src) {
+			return ends
+		}
+
+		c := src[i]
+		switch {
+		case c == '\n' && !multiline:
+			return ends
+		case c == '(' || c == '[' || c == '{':
+			stack = append(stack, c)
+		case c == ')' || c == ']' || c == '}':
+			if len(stack) == 0 || stack[len(stack)-1] != opening(c) {
+				return ends
+			}
+			stack = stack[:len(stack)-1]
+		case !prose && startsLiteral(src, i):
+			end := skipLiteral(src, i)
+			if !multiline && strings.Contains(src[i:end], "\n") {
+				return ends
+			}
+			i = end
+			continue
+		}
+		_, size := utf8.DecodeRuneInString(src[i:])
+		i += size
+	}
+}
+
+func opening(closing byte) byte {
+	switch closing {
+	case ')':
+		return '('
+	case ']':
+		return '['
+	}
+	return '{'
+}
+
+// isComment reports whether the text only holds comments, if any.
+func isComment(text string) bool {
+	for strings.HasPrefix(text, "//") || strings.HasPrefix(text, "/*") {
+		text = strings.TrimSpace(text[skipLiteral(text, 0):])
+	}
+	return text == ""
+}
+
+// startsLiteral reports whether a string, a rune literal or a comment
+// starts at i.
+func startsLiteral(src string, i int) bool {
+	switch src[i] {
+	case '"', '\'', '`':
+		return true
+	}
+	return strings.HasPrefix(src[i:], "//") || strings.HasPrefix(src[i:], "/*")
+}
+
+// skipLiteral returns the end of the string, rune literal or comment
+// starting at i, or the end of the source when it is not terminated.
+func skipLiteral(src string, i int) int {
+	switch {
+	case strings.HasPrefix(src[i:], "//"):
+		if end := strings.IndexByte(src[i:], '\n'); end >= 0 {
+			return i + end
+		}
+		return len(src)
+	case strings.HasPrefix(src[i:], "/*"):
+		if end := strings.Index(src[i+2:], "*/"); end >= 0 {
+			return i + 2 + end + 2
+		}
+		return len(src)
+	case src[i] == '`':
+		if end := strings.IndexByte(src[i+1:], '`'); end >= 0 {
+			return i + 1 + end + 1
+		}
+		return len(src)
+	}
+
+	quote := src[i]
+	for j := i + 1; j < len(src); j++ {
+		switch src[j] {
+		case '\\':
+			j++
+		case quote:
+			return j + 1
+		case '\n':
+			// unterminated on its line
+			return j
+		}
+	}
+	return len(src)
 }
@@ -0,0 +1,284 @@
+package query
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestMatcherFindAll(t *testing.T) {
+	tests := []struct {
+		name    string
+		pattern string
+		src     string
+		want    []string            // matched texts
+		binds   []map[string]string // bindings of each match
+	}{
+		{
+			name:    "function returning nil",
+			pattern: ":[fn](:[args]) { return nil }",
+			src:     "func f(a int) {\n\treturn nil\n}\n\nfunc g() { return x }\n",
+			want:    []string{"func f(a int) {\n\treturn nil\n}"},
+			binds:   []map[string]string{{"fn": "func f", "args": "a int"}},
+		},
+		{
+			name:    "balanced arguments",
+			pattern: "fmt.Println(:[arg])",
+			src:     `fmt.Println(f(x, y)) ; fmt.Println(")")`,
+			want:    []string{"fmt.Println(f(x, y))", `fmt.Println(")")`},
+			binds:   []map[string]string{{"arg": "f(x, y)"}, {"arg": `")"`}},
+		},
+		{
+			name:    "nested calls and brackets",
+			pattern: "foo(:[args])",
+			src:     "foo(bar(x), [2]int{1, 2}[0])",
+			want:    []string{"foo(bar(x), [2]int{1, 2}[0])"},
+			binds:   []map[string]string{{"args": "bar(x), [2]int{1, 2}[0]"}},
+		},
+		{
+			name:    "brackets in the pattern",
+			pattern: "m[:[k]] = :[v]",
+			src:     "m[k[0]] = v[1]",
+			want:    []string{"m[k[0]] = v[1]"},
+			binds:   []map[string]string{{"k": "k[0]", "v": "v[1]"}},
+		},
+		{
+			name:    "mismatched delimiters",
+			pattern: "foo(:[x])",
+			src:     "foo(a[1)] foo(b)",
+			want:    []string{"foo(b)"},
+			binds:   []map[string]string{{"x": "b"}},
+		},
+		{
+			name:    "block holes span lines",
+			pattern: "if :[cond] { :[[body]] }",
+			src:     "if x > 0 {\n\tif y {\n\t\tz()\n\t}\n}",
+			want:    []string{"if x > 0 {\n\tif y {\n\t\tz()\n\t}\n}"},
+			binds:   []map[string]string{{"cond": "x > 0", "body": "if y {\n\t\tz()\n\t}"}},
+		},
+		{
+			name:    "short holes stay on their line",
+			pattern: "a :[x] b",
+			src:     "a 1\n2 b; a 3 b",
+			want:    []string{"a 3 b"},
+			binds:   []map[string]string{{"x": "3"}},
+		},
+		{
+			name:    "repeated hole",
+			pattern: ":[x] = :[x]",
+			src:     "a = b\nc = c\n",
+			want:    []string{"c = c"},
+			binds:   []map[string]string{{"x": "c"}},
+		},
+		{
+			name:    "repeated hole ignores whitespace",
+			pattern: ":[x] == :[x]",
+			src:     "f(a+b) == f(a + b)\nx y == xy",
+			want:    []string{"f(a+b) == f(a + b)"},
+			binds:   []map[string]string{{"x": "f(a+b)"}},
+		},
+		{
+			name:    "independent holes",
+			pattern: ":[_x] = :[_x]",
+			src:     "a = b",
+			want:    []string{"a = b"},
+			binds:   []map[string]string{{"_x": "a"}},
+		},
+		{
+			name:    "anonymous holes",
+			pattern: ":[_] = :[_]",
+			src:     "a = b",
+			want:    []string{"a = b"},
+			binds:   []map[string]string{{}},
+		},
+		{
+			name:    "typed hole",
+			pattern: "x := :[[v:identifier]];",
+			src:     "x := f();x := y;",
+			want:    []string{"x := y;"},
+			binds:   []map[string]string{{"v": "y"}},
+		},
+		{
+			name:    "syntactic kinds",
+			pattern: "return :[v:number]",
+			src:     "return x; return 42",
+			want:    []string{"return 42"},
+			binds:   []map[string]string{{"v": "42"}},
+		},
+		{
+			name:    "expression kind",
+			pattern: ":[x:expr] == nil",
+			src:     "if err == nil {",
+			want:    []string{"if err == nil"[3:]},
+			binds:   []map[string]string{{"x": "err"}},
+		},
+		{
+			name:    "required whitespace between words",
+			pattern: "return nil",
+			src:     "returnnil; return  nil",
+			want:    []string{"return  nil"},
+			binds:   []map[string]string{{}},
+		},
+		{
+			name:    "trailing hole is greedy",
+			pattern: "x = :[v]",
+			src:     "x = a + b \ny = c",
+			want:    []string{"x = a + b"},
+			binds:   []map[string]string{{"v": "a + b"}},
+		},
+		{
+			name:    "empty holes",
+			pattern: "f(:[args])",
+			src:     "f() f(x)",
+			want:    []string{"f()", "f(x)"},
+			binds:   []map[string]string{{"args": ""}, {"args": "x"}},
+		},
+		{
+			name:    "braces in strings",
+			pattern: "if :[cond] { :[[body]] }",
+			src:     "if ok {\n\tprint(\"}\")\n}",
+			want:    []string{"if ok {\n\tprint(\"}\")\n}"},
+			binds:   []map[string]string{{"cond": "ok", "body": "print(\"}\")"}},
+		},
+		{
+			name:    "not in strings and comments",
+			pattern: "panic(:[x])",
+			src:     "s := \"panic(a)\" // panic(b)\n/* panic(c) */ panic(d)",
+			want:    []string{"panic(d)"},
+			binds:   []map[string]string{{"x": "d"}},
+		},
+		{
+			name:    "zero or more statements",
+			pattern: "func :[name]() { :[[body:stmt]]* }",
+			src:     "func f() {\n\ta()\n\t// note\n\tb := 1; c()\n}\nfunc g() {}\nfunc h() { if }",
+			want:    []string{"func f() {\n\ta()\n\t// note\n\tb := 1; c()\n}", "func g() {}"},
+			binds:   []map[string]string{{"name": "f", "body": "a()\n\t// note\n\tb := 1; c()"}, {"name": "g", "body": ""}},
+		},
+		{
+			name:    "one or more expressions",
+			pattern: "f(:[args:expr]+)",
+			src:     "f() f(a, b+1, g(x, y)) f(a b)",
+			want:    []string{"f(a, b+1, g(x, y))"},
+			binds:   []map[string]string{{"args": "a, b+1, g(x, y)"}},
+		},
+		{
+			name:    "optional identifier",
+			pattern: "f(:[x:ident]?)",
+			src:     "f() f(a) f(a, b)",
+			want:    []string{"f()", "f(a)"},
+			binds:   []map[string]string{{"x": ""}, {"x": "a"}},
+		},
+		{
+			name:    "repeated holes are greedy",
+			pattern: "f(:[a]*, :[b])",
+			src:     "f(x, y, z)",
+			want:    []string{"f(x, y, z)"},
+			binds:   []map[string]string{{"a": "x, y", "b": "z"}},
+		},
+		{
+			name:    "other holes are lazy",
+			pattern: "f(:[a], :[b])",
+			src:     "f(x, y, z)",
+			want:    []string{"f(x, y, z)"},
+			binds:   []map[string]string{{"a": "x", "b": "y, z"}},
+		},
+		{
+			name:    "no match",
+			pattern: "panic(:[x])",
+			src:     "func f() {}",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := Compile(tt.pattern)
+			if err != nil {
+				t.Fatalf("Compile() error = %v", err)
+			}
+			matches, err := m.FindAll([]byte(tt.src))
+			if err != nil {
+				t.Fatalf("FindAll() error = %v", err)
+			}
+			var got []string
+			var binds []map[string]string
+			for _, match := range matches {
+				got = append(got, tt.src[match.Start:match.End])
+				binds = append(binds, match.Bindings)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("FindAll() matched %q, want %q", got, tt.want)
+			}
+			if !reflect.DeepEqual(binds, tt.binds) {
+				t.Errorf("FindAll() bound %v, want %v", binds, tt.binds)
+			}
+		})
+	}
+}
+
+func TestMatcherInComments(t *testing.T) {
+	m, err := Compile("Deprecated: use :[name] instead.")
+	if err != nil {
+		t.Fatal(err)
+	}
+	src := "// Deprecated: use Bar's sibling instead.\nvar s = \"Deprecated: use X instead.\"\n"
+	if matches, _ := m.FindAll([]byte(src)); len(matches) != 0 {
+		t.Errorf("FindAll() = %v, want no match in comments", matches)
+	}
+
+	m.InComments = true
+	matches, _ := m.FindAll([]byte(src))
+	if len(matches) != 1 {
+		t.Fatalf("FindAll() = %v, want the match in the comment", matches)
+	}
+	if got, want := matches[0].Bindings["name"], "Bar's sibling"; got != want {
+		t.Errorf("name = %q, want %q", got, want)
+	}
+}
+
+func TestMatcherMaxBacktrack(t *testing.T) {
+	m, err := Compile(":[a] :[b] :[c] :[d] end")
+	if err != nil {
+		t.Fatal(err)
+	}
+	src := "p q r s end\n" + strings.Repeat("x ", 12) + "\n"
+	matches, err := m.FindAll([]byte(src))
+	if err != nil || len(matches) != 1 {
+		t.Fatalf("FindAll() = %v, %v, want one match", matches, err)
+	}
+
+	m.MaxBacktrack = 100
+	matches, err = m.FindAll([]byte(src))
+	if !errors.Is(err, ErrBacktrackLimit) {
+		t.Errorf("FindAll() error = %v, want %v", err, ErrBacktrackLimit)
+	}
+	if len(matches) != 1 {
+		t.Errorf("FindAll() = %v, want the match found before giving up", matches)
+	}
+}
+
+func TestCompileErrors(t *testing.T) {
+	for _, pattern := range []string{"", "   ", ":[x"} {
+		if _, err := Compile(pattern); err == nil {
+			t.Errorf("Compile(%q) succeeded, want an error", pattern)
+		}
+	}
+}
+
+func TestParseNestedBlocks(t *testing.T) {
+	nodes, err := NewParser().Parse(newBuffer("a{b{c}}d"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the content of the blocks is not repeated after them
+	if len(nodes) != 3 {
+		t.Fatalf("Parse() = %v, want a text, a block and a text", nodes)
+	}
+	outer := nodes[1].(*BlockNode)
+	if len(outer.Content) != 2 || outer.Content[1].Type() != NodeBlock {
+		t.Errorf("block content = %v, want a text and a block", outer.Content)
+	}
+	if text, ok := nodes[2].(*TextNode); !ok || text.Content != "d" {
+		t.Errorf("last node = %v, want the text d", nodes[2])
+	}
+}
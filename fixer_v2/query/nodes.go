@@ -0,0 +1,50 @@
+package query
+
+import (
+	"go/ast"
+	"go/token"
+)
+
+// FindNodes returns the matches of the pattern spanning whole statements,
+// expressions or declarations of a parsed file, in source order. Unlike
+// FindAll, a match can't start or end in the middle of a token, and its
+// node gives its exact position: "a + b" matches in "x := a + b" but not in
+// "a + b*c", whose "b*c" is one operand. Matches don't overlap: the nodes
+// of a match are not searched.
+//
+// src is the source of the file, and fset the file set it was parsed
+// with. The conditions of Where apply, and so does MaxBacktrack, as for
+// FindAll.
+func (m *Matcher) FindNodes(fset *token.FileSet, file *ast.File, src []byte) ([]Match, error) {
+	tokFile := fset.File(file.Pos())
+	if tokFile == nil {
+		return nil, nil
+	}
+	text := string(src)
+	var (
+		matches []Match
+		err     error
+	)
+	ast.Inspect(file, func(node ast.Node) bool {
+		switch node.(type) {
+		case ast.Stmt, ast.Expr, ast.Decl:
+		default:
+			return true
+		}
+		if !node.Pos().IsValid() || !node.End().IsValid() {
+			return true
+		}
+		start, end := tokFile.Offset(node.Pos()), tokFile.Offset(node.End())
+		if start >= end || end > len(text) {
+			return true
+		}
+		match, ok := m.find(text[:end], start, scopeNode, &err)
+		if !ok {
+			return true
+		}
+		match.Node = node
+		matches = append(matches, match)
+		return false
+	})
+	return matches, err
+}
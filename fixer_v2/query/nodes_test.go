@@ -0,0 +1,98 @@
+package query
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"reflect"
+	"testing"
+)
+
+func TestMatcherFindNodes(t *testing.T) {
+	src := `package a
+
+func f() {
+	x := a + b
+	y := a + b*c
+	z := aa + b
+	if err := g(a + b); err != nil {
+		panic(err)
+	}
+}
+`
+	tests := []struct {
+		name    string
+		pattern string
+		want    []string   // matched texts
+		nodes   []ast.Node // types of the matched nodes
+		where   string
+	}{
+		{
+			name:    "whole expressions",
+			pattern: "a + b",
+			want:    []string{"a + b", "a + b"},
+			nodes:   []ast.Node{&ast.BinaryExpr{}, &ast.BinaryExpr{}},
+		},
+		{
+			name:    "statements",
+			pattern: ":[x] := :[y] + b",
+			want:    []string{"x := a + b", "z := aa + b"},
+			nodes:   []ast.Node{&ast.AssignStmt{}, &ast.AssignStmt{}},
+		},
+		{
+			name:    "outermost node",
+			pattern: "if :[[cond]] { :[[body]] }",
+			want:    []string{"if err := g(a + b); err != nil {\n\t\tpanic(err)\n\t}"},
+			nodes:   []ast.Node{&ast.IfStmt{}},
+		},
+		{
+			name:    "where clause",
+			pattern: ":[x] := :[y]",
+			where:   `:[x] == "y"`,
+			want:    []string{"y := a + b*c"},
+			nodes:   []ast.Node{&ast.AssignStmt{}},
+		},
+	}
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "a.go", src, parser.ParseComments)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := Compile(tt.pattern)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.where != "" {
+				if err := m.Where(tt.where); err != nil {
+					t.Fatal(err)
+				}
+			}
+			matches, err := m.FindNodes(fset, file, []byte(src))
+			if err != nil {
+				t.Fatalf("FindNodes() error = %v", err)
+			}
+			var got []string
+			var nodes []ast.Node
+			for _, match := range matches {
+				got = append(got, src[match.Start:match.End])
+				if start := fset.Position(match.Node.Pos()).Offset; start != match.Start {
+					t.Errorf("match starts at %d, its node at %d", match.Start, start)
+				}
+				nodes = append(nodes, match.Node)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("FindNodes() matched %q, want %q", got, tt.want)
+			}
+			if len(nodes) != len(tt.nodes) {
+				return
+			}
+			for i := range nodes {
+				if reflect.TypeOf(nodes[i]) != reflect.TypeOf(tt.nodes[i]) {
+					t.Errorf("FindNodes() matched a %T, want a %T", nodes[i], tt.nodes[i])
+				}
+			}
+		})
+	}
+}
@@ -1,9 +1,5 @@
 package query
 
-import (
-	"fmt"
-)
-
 // hole name -> position (optional usage)
 type holes map[string]int
 
@@ -23,9 +19,8 @@ func (p *Parser) Parse(buf *buffer) ([]Node, error) {
 	p.buffer = buf
 	p.tokens = []Token{}
 
-	err := p.collectTokens()
-	if err != nil {
-		return nil, fmt.Errorf("failed to collect tokens: %w", err)
+	if err := p.collectTokens(); err != nil {
+		return nil, err
 	}
 
 	rootNode := &PatternNode{}
@@ -36,6 +31,20 @@ func (p *Parser) Parse(buf *buffer) ([]Node, error) {
 			break
 		}
 
+		switch p.tokens[current].Type {
+		case TokenLBrace:
+			// resume after the block, whose tokens are its content
+			block, end, err := p.parseBlock(current)
+			if err != nil {
+				return nil, err
+			}
+			rootNode.Children = append(rootNode.Children, block)
+			current = end + 1
+			continue
+		case TokenRBrace:
+			return nil, p.buffer.syntaxError(p.tokens[current].Position, "", "unmatched }")
+		}
+
 		node := p.parseTokenNode(current)
 		if node != nil {
 			rootNode.Children = append(rootNode.Children, node)
@@ -86,11 +95,11 @@ func (p *Parser) scanMetaVariable() (Token, error) {
 	startPos := p.buffer.index
 
 	if p.buffer.data[startPos] != ':' {
-		return Token{}, fmt.Errorf("expected ':' at position %d", startPos)
+		return Token{}, p.buffer.syntaxError(startPos, ":[", "expected a hole")
 	}
 
 	if startPos+1 >= p.buffer.length || p.buffer.data[startPos+1] != '[' {
-		return Token{}, fmt.Errorf("expected '[' at position %d", startPos+1)
+		return Token{}, p.buffer.syntaxError(startPos+1, "[", "expected a hole")
 	}
 
 	p.buffer.setMode(ModeHole)
@@ -98,7 +107,7 @@ func (p *Parser) scanMetaVariable() (Token, error) {
 
 	cfg, err := p.buffer.parseMetaVariable()
 	if err != nil {
-		return Token{}, fmt.Errorf("failed to parse meta variable at position %d: %w", startPos, err)
+		return Token{}, err
 	}
 
 	return Token{
@@ -186,6 +195,13 @@ func (p *Parser) parseTokenNode(current int) Node {
 }
 
 func (p *Parser) parseBlockFromTokens(start int) Node {
+	block, _, _ := p.parseBlock(start)
+	return block
+}
+
+// parseBlock parses the block opened at start, nested blocks included, and
+// returns it along with the index of the token closing it.
+func (p *Parser) parseBlock(start int) (*BlockNode, int, error) {
 	bn := &BlockNode{
 		Content: make([]Node, 0),
 		pos:     p.tokens[start].Position,
@@ -193,8 +209,19 @@ func (p *Parser) parseBlockFromTokens(start int) Node {
 
 	current := start + 1
 	for current < len(p.tokens) {
-		if p.tokens[current].Type == TokenRBrace {
-			return bn
+		switch p.tokens[current].Type {
+		case TokenRBrace:
+			return bn, current, nil
+		case TokenEOF:
+			return bn, current, p.buffer.syntaxError(bn.pos, "}", "unclosed block")
+		case TokenLBrace:
+			block, end, err := p.parseBlock(current)
+			if err != nil {
+				return bn, end, err
+			}
+			bn.Content = append(bn.Content, block)
+			current = end + 1
+			continue
 		}
 
 		if node := p.parseTokenNode(current); node != nil {
@@ -203,5 +230,5 @@ func (p *Parser) parseBlockFromTokens(start int) Node {
 		current++
 	}
 
-	return bn
+	return bn, current, p.buffer.syntaxError(bn.pos, "}", "unclosed block")
 }
@@ -1,6 +1,9 @@
 package query
 
 import (
+	"encoding/json"
+	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -416,3 +419,53 @@ func TestParser_Parse(t *testing.T) {
 		})
 	}
 }
+
+func TestNodeJSON(t *testing.T) {
+	nodes, err := NewParser().Parse(newBuffer("if :[[cond:expr]] {\n\t:[body]* {}\n}"))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	pattern := &PatternNode{Children: nodes}
+
+	data, err := json.Marshal(pattern)
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+	wantPrefix := `{"type":"pattern","position":0,"children":[{"type":"text","position":0,"content":"if "},` +
+		`{"type":"hole","position":3,"hole":{"type":"expression","quantifier":"","name":"cond","multiline":true}},`
+	if !strings.HasPrefix(string(data), wantPrefix) {
+		t.Errorf("json.Marshal() = %s, want prefix %s", data, wantPrefix)
+	}
+
+	var decoded PatternNode
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+	if !reflect.DeepEqual(&decoded, pattern) {
+		t.Errorf("decoded pattern = %s, want %s", decoded.String(), pattern.String())
+	}
+
+	var hole HoleNode
+	if err := json.Unmarshal([]byte(`{"type":"hole","position":2,"hole":{"type":"stmt","quantifier":"+","name":"s"}}`), &hole); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+	want := HoleNode{Config: HoleConfig{Name: "s", Type: HoleStatement, Quantifier: QuantOneOrMore}, pos: 2}
+	if !hole.Equal(&want) {
+		t.Errorf("decoded hole = %s, want %s", hole.String(), want.String())
+	}
+
+	for _, data := range []string{
+		`{"type":"text","content":"x"}`,
+		`{"type":"bogus"}`,
+		`{"type":"hole","position":0}`,
+		`{"type":"hole","hole":{"type":"bogus"}}`,
+	} {
+		var node HoleNode
+		if err := json.Unmarshal([]byte(data), &node); err == nil {
+			t.Errorf("json.Unmarshal(%s) into a HoleNode succeeded, want an error", data)
+		}
+	}
+	if err := json.Unmarshal([]byte(`{"type":"pattern","children":[{"type":"block","children":[{"type":"bogus"}]}]}`), &decoded); err == nil {
+		t.Error("json.Unmarshal() accepted a pattern holding an unknown node type")
+	}
+}
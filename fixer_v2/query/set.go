@@ -0,0 +1,205 @@
+package query
+
+// MatcherSet finds the matches of several patterns in a single pass over
+// the source, rather than one per pattern: the patterns starting with text
+// are only tried where their text occurs, found by an Aho-Corasick
+// automaton of the texts. The patterns starting with a hole are searched
+// as by FindAll.
+type MatcherSet struct {
+	matchers []*Matcher
+	anchors  *anchorAutomaton
+	// byAnchor lists the matchers starting with each anchor
+	byAnchor [][]int
+	// unanchored lists the matchers starting with a hole
+	unanchored []int
+}
+
+// NewMatcherSet returns the set of the matchers, whose settings apply to
+// their matches in the set.
+func NewMatcherSet(matchers ...*Matcher) *MatcherSet {
+	s := &MatcherSet{matchers: matchers}
+	ids := make(map[string]int)
+	var anchors []string
+	for i, m := range matchers {
+		first := m.elems[0]
+		if first.kind != elemText {
+			s.unanchored = append(s.unanchored, i)
+			continue
+		}
+		id, ok := ids[first.text]
+		if !ok {
+			id = len(anchors)
+			ids[first.text] = id
+			anchors = append(anchors, first.text)
+			s.byAnchor = append(s.byAnchor, nil)
+		}
+		s.byAnchor[id] = append(s.byAnchor[id], i)
+	}
+	s.anchors = newAnchorAutomaton(anchors)
+	return s
+}
+
+// SetResult is what a matcher of a MatcherSet found.
+type SetResult struct {
+	Matches []Match
+	// Err is the error of the matcher, returned along with its matches as
+	// by FindAll.
+	Err error
+}
+
+// FindAll returns what each matcher of the set found in src, in the order
+// of the matchers. They find the matches of their own FindAll, but for
+// patterns whose text ends inside a string or a comment.
+func (s *MatcherSet) FindAll(src []byte) []SetResult {
+	text := string(src)
+	results := make([]SetResult, len(s.matchers))
+	for _, i := range s.unanchored {
+		results[i].Matches, results[i].Err = s.matchers[i].FindAll(src)
+	}
+	if len(s.byAnchor) == 0 {
+		return results
+	}
+
+	// the starts of each anchor, in increasing order
+	starts := make([][]int, len(s.byAnchor))
+	s.anchors.scan(text, func(id, start int) {
+		starts[id] = append(starts[id], start)
+	})
+	literals := literalSpans(text)
+	for id, matchers := range s.byAnchor {
+		for _, i := range matchers {
+			results[i].Matches = s.matchers[i].findAtStarts(text, starts[id], literals, &results[i].Err)
+		}
+	}
+	return results
+}
+
+// span is the range of a string or a comment in the source.
+type span struct{ start, end int }
+
+// literalSpans returns the strings and comments of the source, in order.
+func literalSpans(text string) []span {
+	var spans []span
+	for pos := 0; pos < len(text); {
+		if !startsLiteral(text, pos) {
+			pos++
+			continue
+		}
+		end := skipLiteral(text, pos)
+		spans = append(spans, span{pos, end})
+		pos = end
+	}
+	return spans
+}
+
+// findAtStarts matches the pattern at the given starts like FindAll, the
+// strings and comments of the source being literals.
+func (m *Matcher) findAtStarts(text string, starts []int, literals []span, err *error) []Match {
+	var (
+		matches []Match
+		end     int // of the last match
+		l       int // index of the first literal not before the start
+	)
+	for _, pos := range starts {
+		if pos < end {
+			continue
+		}
+		for l < len(literals) && literals[l].end <= pos {
+			l++
+		}
+		s, src := scopeCode, text
+		if l < len(literals) && literals[l].start < pos {
+			// the matches in a comment stay in it
+			literal := literals[l]
+			if !m.InComments || text[literal.start] != '/' {
+				continue
+			}
+			s, src = scopeComment, text[:literal.end]
+		}
+		if match, ok := m.find(src, pos, s, err); ok {
+			matches = append(matches, match)
+			end = match.End
+		}
+	}
+	return matches
+}
+
+// anchorAutomaton is an Aho-Corasick automaton finding the occurrences of
+// several texts in one pass.
+type anchorAutomaton struct {
+	anchors []string
+	next    []map[byte]int // the transitions of the trie of the anchors
+	fail    []int          // the state of the longest proper suffix
+	output  [][]int        // the anchors ending at each state
+}
+
+func newAnchorAutomaton(anchors []string) *anchorAutomaton {
+	a := &anchorAutomaton{
+		anchors: anchors,
+		next:    []map[byte]int{{}},
+		fail:    []int{0},
+		output:  [][]int{nil},
+	}
+	for id, anchor := range anchors {
+		state := 0
+		for i := 0; i < len(anchor); i++ {
+			next, ok := a.next[state][anchor[i]]
+			if !ok {
+				next = len(a.next)
+				a.next = append(a.next, map[byte]int{})
+				a.fail = append(a.fail, 0)
+				a.output = append(a.output, nil)
+				a.next[state][anchor[i]] = next
+			}
+			state = next
+		}
+		a.output[state] = append(a.output[state], id)
+	}
+
+	// the failure links, breadth first so that shorter suffixes come first
+	queue := make([]int, 0, len(a.next))
+	for _, state := range a.next[0] {
+		queue = append(queue, state)
+	}
+	for len(queue) > 0 {
+		state := queue[0]
+		queue = queue[1:]
+		for c, next := range a.next[state] {
+			fail := a.fail[state]
+			for {
+				if target, ok := a.next[fail][c]; ok {
+					a.fail[next] = target
+					break
+				}
+				if fail == 0 {
+					break
+				}
+				fail = a.fail[fail]
+			}
+			a.output[next] = append(a.output[next], a.output[a.fail[next]]...)
+			queue = append(queue, next)
+		}
+	}
+	return a
+}
+
+// scan calls found with every occurrence of the anchors in the text, by
+// increasing end.
+func (a *anchorAutomaton) scan(text string, found func(id, start int)) {
+	state := 0
+	for i := 0; i < len(text); i++ {
+		for {
+			if next, ok := a.next[state][text[i]]; ok {
+				state = next
+				break
+			}
+			if state == 0 {
+				break
+			}
+			state = a.fail[state]
+		}
+		for _, id := range a.output[state] {
+			found(id, i+1-len(a.anchors[id]))
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package query
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestMatcherSetFindAll(t *testing.T) {
+	src := `package a
+
+// panic(x) is fine in comments, TODO: remove
+func f(err error) {
+	s := "panic(a) fmt.Println(s)"
+	fmt.Println(s, fmt.Sprint(err))
+	if err != nil {
+		panic(err)
+	}
+	x := a + b
+	y := ab + b
+}
+`
+	patterns := []string{
+		"panic(:[x])",
+		"fmt.Println(:[args])",
+		"fmt.Sprint(:[args])",
+		"fmt.Sprint(:[args])", // the same anchor twice
+		":[x] := :[y] + b",    // no anchor
+		"if :[cond] { :[[body]] }",
+		"TODO: :[what]",
+		"missing(:[x])",
+	}
+	var matchers []*Matcher
+	for i, pattern := range patterns {
+		m, err := Compile(pattern)
+		if err != nil {
+			t.Fatal(err)
+		}
+		m.InComments = i == 6
+		matchers = append(matchers, m)
+	}
+
+	got := NewMatcherSet(matchers...).FindAll([]byte(src))
+	if len(got) != len(matchers) {
+		t.Fatalf("FindAll() returned %d results, want %d", len(got), len(matchers))
+	}
+	for i, m := range matchers {
+		want, err := m.FindAll([]byte(src))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got[i].Err != nil {
+			t.Errorf("FindAll() error = %v for %q", got[i].Err, patterns[i])
+		}
+		if !reflect.DeepEqual(got[i].Matches, want) {
+			t.Errorf("FindAll() matched %v for %q, want %v", got[i].Matches, patterns[i], want)
+		}
+	}
+	if len(got[0].Matches) != 1 || len(got[6].Matches) != 1 || len(got[7].Matches) != 0 {
+		t.Errorf("FindAll() = %v, want one panic, one TODO and no missing", got)
+	}
+}
+
+func TestMatcherSetErrors(t *testing.T) {
+	slow, err := Compile("f(:[a] :[b] :[c] :[d] end)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	slow.MaxBacktrack = 10
+	fast, err := Compile("f(:[x])")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := NewMatcherSet(slow, fast).FindAll([]byte("f(p q r s t u v w)"))
+	if !errors.Is(got[0].Err, ErrBacktrackLimit) {
+		t.Errorf("FindAll() error = %v, want %v", got[0].Err, ErrBacktrackLimit)
+	}
+	if got[1].Err != nil || len(got[1].Matches) != 1 {
+		t.Errorf("FindAll() = %v, want one match without error", got[1])
+	}
+}
+
+func TestAnchorAutomaton(t *testing.T) {
+	a := newAnchorAutomaton([]string{"he", "she", "his", "hers"})
+	type occurrence struct{ anchor, start int }
+	var got []occurrence
+	a.scan("ushers his", func(id, start int) {
+		got = append(got, occurrence{id, start})
+	})
+	want := []occurrence{{1, 1}, {0, 2}, {3, 2}, {2, 7}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("scan() = %v, want %v", got, want)
+	}
+}
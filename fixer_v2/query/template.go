@@ -0,0 +1,56 @@
+package query
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Template is the replacement of a rewrite, whose holes are filled with
+// what the holes of the same name captured in a match.
+type Template struct {
+	tokens []Token
+}
+
+// ParseTemplate parses a replacement template. Unlike a pattern, its text
+// is kept byte for byte, whitespace included.
+func ParseTemplate(template string) (*Template, error) {
+	tokens, err := Tokenize(template)
+	if err != nil {
+		return nil, err
+	}
+	for _, token := range tokens {
+		if token.Type == TokenHole && token.HoleConfig.Name == anonymousHole {
+			return nil, fmt.Errorf("hole %s at position %d binds nothing", token.Value, token.Position)
+		}
+	}
+	return &Template{tokens: tokens}, nil
+}
+
+// Holes returns the names of the holes of the template, in order.
+func (t *Template) Holes() []string {
+	var names []string
+	for _, token := range t.tokens {
+		if token.Type == TokenHole {
+			names = append(names, token.HoleConfig.Name)
+		}
+	}
+	return names
+}
+
+// Expand returns the template with its holes replaced by the bindings of a
+// match. A hole without binding is an error.
+func (t *Template) Expand(bindings map[string]string) (string, error) {
+	var sb strings.Builder
+	for _, token := range t.tokens {
+		if token.Type != TokenHole {
+			sb.WriteString(token.Value)
+			continue
+		}
+		value, ok := bindings[token.HoleConfig.Name]
+		if !ok {
+			return "", fmt.Errorf("hole %s is not bound", token.Value)
+		}
+		sb.WriteString(value)
+	}
+	return sb.String(), nil
+}
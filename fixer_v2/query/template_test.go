@@ -0,0 +1,41 @@
+package query
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTemplateExpand(t *testing.T) {
+	tmpl, err := ParseTemplate("if :[cond] {\n\t:[[body]]\n}  // :[cond]")
+	if err != nil {
+		t.Fatalf("ParseTemplate() error = %v", err)
+	}
+	if got, want := tmpl.Holes(), []string{"cond", "body", "cond"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Holes() = %v, want %v", got, want)
+	}
+
+	got, err := tmpl.Expand(map[string]string{"cond": "x > 0", "body": "f()"})
+	if err != nil {
+		t.Fatalf("Expand() error = %v", err)
+	}
+	if want := "if x > 0 {\n\tf()\n}  // x > 0"; got != want {
+		t.Errorf("Expand() = %q, want %q", got, want)
+	}
+
+	if _, err := tmpl.Expand(map[string]string{"cond": "x"}); err == nil {
+		t.Error("Expand() without the body succeeded, want an error")
+	}
+	if _, err := ParseTemplate("f(:[_])"); err == nil {
+		t.Error("ParseTemplate() with an anonymous hole succeeded, want an error")
+	}
+}
+
+func TestMatcherHoles(t *testing.T) {
+	m, err := Compile(":[x] = :[_] + :[y] + :[x]")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := m.Holes(), []string{"x", "y"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Holes() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,50 @@
+package query
+
+// Tokenize splits a pattern into its full token stream, for tools such as
+// syntax highlighters that need more than the parsed nodes. Runs of
+// whitespace, which the parser keeps inside text, get their own
+// TokenWhitespace tokens, and the stream always ends with a TokenEOF token.
+//
+// Concatenating the values of the tokens in order gives back the input
+// byte for byte.
+func Tokenize(input string) ([]Token, error) {
+	p := NewParser()
+	p.buffer = newBuffer(input)
+	if err := p.collectTokens(); err != nil {
+		return nil, err
+	}
+
+	tokens := make([]Token, 0, len(p.tokens))
+	for _, token := range p.tokens {
+		if token.Type != TokenText {
+			tokens = append(tokens, token)
+			continue
+		}
+		tokens = append(tokens, splitWhitespace(token)...)
+	}
+	// the EOF token sits right after the input
+	tokens[len(tokens)-1].Position = len(input)
+	return tokens, nil
+}
+
+// splitWhitespace splits a text token into alternating text and whitespace tokens.
+func splitWhitespace(token Token) []Token {
+	var tokens []Token
+	start := 0
+	for i := 1; i <= len(token.Value); i++ {
+		if i < len(token.Value) && isWhitespace(token.Value[i]) == isWhitespace(token.Value[start]) {
+			continue
+		}
+		typ := TokenText
+		if isWhitespace(token.Value[start]) {
+			typ = TokenWhitespace
+		}
+		tokens = append(tokens, Token{
+			Type:     typ,
+			Value:    token.Value[start:i],
+			Position: token.Position + start,
+		})
+		start = i
+	}
+	return tokens
+}
@@ -0,0 +1,148 @@
+package query
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestTokenize(t *testing.T) {
+	input := "if :[[cond:expression]]? {\n\t:[body]*\n}"
+	want := []Token{
+		{Type: TokenText, Value: "if", Position: 0},
+		{Type: TokenWhitespace, Value: " ", Position: 2},
+		{
+			Type:       TokenHole,
+			Value:      ":[[cond:expression]]?",
+			Position:   3,
+			HoleConfig: &HoleConfig{Name: "cond", Type: HoleExpression, Quantifier: QuantZeroOrOne, Multiline: true},
+		},
+		{Type: TokenWhitespace, Value: " ", Position: 24},
+		{Type: TokenLBrace, Value: "{", Position: 25},
+		{Type: TokenWhitespace, Value: "\n\t", Position: 26},
+		{
+			Type:       TokenHole,
+			Value:      ":[body]*",
+			Position:   28,
+			HoleConfig: &HoleConfig{Name: "body", Type: HoleAny, Quantifier: QuantZeroOrMore},
+		},
+		{Type: TokenWhitespace, Value: "\n", Position: 36},
+		{Type: TokenRBrace, Value: "}", Position: 37},
+		{Type: TokenEOF, Value: "", Position: 38},
+	}
+
+	got, err := Tokenize(input)
+	if err != nil {
+		t.Fatalf("Tokenize() error = %v", err)
+	}
+	if len(got) != len(want) {
+		t.Fatalf("Tokenize() returned %d tokens, want %d: %v", len(got), len(want), got)
+	}
+	for i := range want {
+		if !got[i].Equal(want[i]) {
+			t.Errorf("token %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestTokenizeRoundTrip(t *testing.T) {
+	inputs := []string{
+		"",
+		"   ",
+		"hello world",
+		":[x]",
+		":[[x]]",
+		":[[x:identifier]]+ :[[y:expression]]?",
+		":[x]* :[y:block]+ :[z]?",
+		":[x~] :[[y.]] :[z:block~]*",
+		"a: b",
+		"case x:",
+		"x[0] = y[i:j]",
+		"a * b + c?",
+		"map[string]int{}",
+		`\:[x] "\n" '\t'`,
+		"if :[cond] {\n\treturn :[[value]]\n} else {\r\n}",
+		"func :[name](:[args]) :[ret] { :[[body:block]] }",
+	}
+
+	for _, input := range inputs {
+		t.Run(input, func(t *testing.T) {
+			tokens, err := Tokenize(input)
+			if err != nil {
+				t.Fatalf("Tokenize(%q) error = %v", input, err)
+			}
+			if last := tokens[len(tokens)-1]; last.Type != TokenEOF {
+				t.Errorf("last token = %v, want EOF", last.Type)
+			}
+
+			var sb strings.Builder
+			for _, token := range tokens {
+				if token.Position != sb.Len() {
+					t.Errorf("token %q at position %d, want %d", token.Value, token.Position, sb.Len())
+				}
+				if token.Type == TokenText && strings.ContainsAny(token.Value, " \t\r\n") {
+					t.Errorf("text token %q contains whitespace", token.Value)
+				}
+				sb.WriteString(token.Value)
+			}
+			if sb.String() != input {
+				t.Errorf("concatenated tokens = %q, want %q", sb.String(), input)
+			}
+		})
+	}
+}
+
+func TestTokenizeErrors(t *testing.T) {
+	for _, input := range []string{":[x", ":[[x]", ":[[x] ]", ":[x:unknown]", ":[]"} {
+		if _, err := Tokenize(input); err == nil {
+			t.Errorf("Tokenize(%q) succeeded, want an error", input)
+		}
+	}
+}
+
+func TestTokenJSON(t *testing.T) {
+	tokens, err := Tokenize(":[[x:identifier]]+ = :[y]")
+	if err != nil {
+		t.Fatalf("Tokenize() error = %v", err)
+	}
+
+	data, err := json.Marshal(tokens)
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+	wantPrefix := `[{"type":"hole","value":":[[x:identifier]]+","position":0,"hole":{"type":"identifier","quantifier":"+","name":"x","multiline":true}},{"type":"whitespace","value":" ","position":18}`
+	if !strings.HasPrefix(string(data), wantPrefix) {
+		t.Errorf("json.Marshal() = %s, want prefix %s", data, wantPrefix)
+	}
+
+	var decoded []Token
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+	if !reflect.DeepEqual(decoded, tokens) {
+		t.Errorf("decoded tokens = %+v, want %+v", decoded, tokens)
+	}
+
+	var token Token
+	if err := json.Unmarshal([]byte(`{"type":"bogus"}`), &token); err == nil {
+		t.Error("json.Unmarshal() accepted an unknown token type")
+	}
+}
+
+func TestTokenTypeString(t *testing.T) {
+	tests := map[TokenType]string{
+		TokenText:       "text",
+		TokenHole:       "hole",
+		TokenLBrace:     "lbrace",
+		TokenRBrace:     "rbrace",
+		TokenWhitespace: "whitespace",
+		TokenEOF:        "eof",
+		TokenType(42):   "unknown",
+	}
+	for typ, want := range tests {
+		if got := typ.String(); got != want {
+			t.Errorf("TokenType(%d).String() = %q, want %q", int(typ), got, want)
+		}
+	}
+}
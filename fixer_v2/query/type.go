@@ -1,6 +1,8 @@
 package query
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
 	"strconv"
 	"strings"
@@ -18,12 +20,47 @@ const (
 	TokenEOF                         // End of file (input)
 )
 
+var tokenTypeNames = [...]string{
+	TokenText:       "text",
+	TokenHole:       "hole",
+	TokenLBrace:     "lbrace",
+	TokenRBrace:     "rbrace",
+	TokenWhitespace: "whitespace",
+	TokenEOF:        "eof",
+}
+
+func (t TokenType) String() string {
+	if t < 0 || int(t) >= len(tokenTypeNames) {
+		return "unknown"
+	}
+	return tokenTypeNames[t]
+}
+
+// MarshalText encodes the token type as its name.
+func (t TokenType) MarshalText() ([]byte, error) {
+	if t < 0 || int(t) >= len(tokenTypeNames) {
+		return nil, fmt.Errorf("unknown token type %d", int(t))
+	}
+	return []byte(t.String()), nil
+}
+
+// UnmarshalText decodes a token type from its name.
+func (t *TokenType) UnmarshalText(text []byte) error {
+	for i, name := range tokenTypeNames {
+		if name == string(text) {
+			*t = TokenType(i)
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown token type %q", text)
+}
+
 // Token represents a single lexical token with type, value, and position.
 type Token struct {
-	Type       TokenType   // type of this token
-	Value      string      // the literal string for this token
-	Position   int         // the starting position in the original input
-	HoleConfig *HoleConfig // configuration for hole tokens (nil for non-hole tokens)
+	Type       TokenType   `json:"type"`           // type of this token
+	Value      string      `json:"value"`          // the literal string for this token
+	Position   int         `json:"position"`       // the starting position in the original input
+	HoleConfig *HoleConfig `json:"hole,omitempty"` // configuration for hole tokens (nil for non-hole tokens)
 }
 
 func (t *Token) Equal(other Token) bool {
@@ -49,6 +86,39 @@ const (
 	NodeBlock
 )
 
+var nodeTypeNames = [...]string{
+	NodePattern: "pattern",
+	NodeHole:    "hole",
+	NodeText:    "text",
+	NodeBlock:   "block",
+}
+
+func (t NodeType) String() string {
+	if t < 0 || int(t) >= len(nodeTypeNames) {
+		return "unknown"
+	}
+	return nodeTypeNames[t]
+}
+
+// MarshalText encodes the node type as its name.
+func (t NodeType) MarshalText() ([]byte, error) {
+	if t < 0 || int(t) >= len(nodeTypeNames) {
+		return nil, fmt.Errorf("unknown node type %d", int(t))
+	}
+	return []byte(t.String()), nil
+}
+
+// UnmarshalText decodes a node type from its name.
+func (t *NodeType) UnmarshalText(text []byte) error {
+	for i, name := range nodeTypeNames {
+		if name == string(text) {
+			*t = NodeType(i)
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown node type %q", text)
+}
+
 // Node is an interface that any AST node must implement.
 type Node interface {
 	Type() NodeType        // returns the node type
@@ -89,15 +159,23 @@ func (p *PatternNode) Equal(other Node) bool {
 
 // HoleConfig stores configuration for a hole pattern
 type HoleConfig struct {
-	Type       HoleType
-	Quantifier Quantifier
-	Name       string
+	Type       HoleType   `json:"type"`
+	Quantifier Quantifier `json:"quantifier"`
+	Name       string     `json:"name"`
+	// Multiline lets the hole capture text spanning several lines.
+	Multiline bool `json:"multiline"`
+	// Independent lets the hole capture other text than the holes of the
+	// same name, which otherwise all capture the same code. Holes whose name
+	// starts with _ are independent.
+	Independent bool `json:"independent,omitempty"`
 }
 
 func (h *HoleConfig) Equal(other HoleConfig) bool {
 	return h.Name == other.Name &&
 		h.Type == other.Type &&
-		h.Quantifier == other.Quantifier
+		h.Quantifier == other.Quantifier &&
+		h.Multiline == other.Multiline &&
+		h.Independent == other.Independent
 }
 
 // HoleNode represents a placeholder in the pattern like :[name] or :[[name]].
@@ -120,20 +198,21 @@ func NewHoleNode(name string, pos int) *HoleNode {
 func (h *HoleNode) Type() NodeType { return NodeHole }
 
 func (h *HoleNode) String() string {
-	if h.Config.Type == HoleAny && h.Config.Quantifier == QuantNone {
-		return fmt.Sprintf("HoleNode(%s)", h.Config.Name)
+	name := h.Config.Name
+	if h.Config.Type != HoleAny || h.Config.Quantifier != QuantNone {
+		name += ":" + h.Config.Type.String()
+	}
+	if h.Config.Multiline {
+		name += string(lineMulti)
 	}
-	return fmt.Sprintf("HoleNode(%s:%s)%s", h.Config.Name, h.Config.Type, h.Config.Quantifier)
+	return fmt.Sprintf("HoleNode(%s)%s", name, h.Config.Quantifier)
 }
 
 func (h *HoleNode) Position() int { return h.pos }
 func (h *HoleNode) Name() string  { return h.Config.Name }
 func (h *HoleNode) Equal(other Node) bool {
 	if otherHole, ok := other.(*HoleNode); ok {
-		return h.Config.Name == otherHole.Config.Name &&
-			h.Config.Type == otherHole.Config.Type &&
-			h.Config.Quantifier == otherHole.Config.Quantifier &&
-			h.pos == otherHole.pos
+		return h.Config.Equal(otherHole.Config) && h.pos == otherHole.pos
 	}
 	return false
 }
@@ -180,6 +259,114 @@ func (b *BlockNode) Equal(other Node) bool {
 	return ok
 }
 
+// nodeJSON is the JSON form of every node, whose type tells which of the
+// other fields are set: the content of text, the configuration of a hole,
+// or the children of a pattern or a block.
+type nodeJSON struct {
+	Type     NodeType          `json:"type"`
+	Position int               `json:"position"`
+	Content  string            `json:"content,omitempty"`
+	Hole     *HoleConfig       `json:"hole,omitempty"`
+	Children []json.RawMessage `json:"children,omitempty"`
+}
+
+// MarshalJSON encodes the pattern as a JSON object holding its children,
+// each a JSON object whose type field tells the kind of node.
+func (p *PatternNode) MarshalJSON() ([]byte, error) {
+	return marshalNode(nodeJSON{Type: NodePattern, Position: p.pos}, p.Children)
+}
+
+func (h *HoleNode) MarshalJSON() ([]byte, error) {
+	config := h.Config
+	return marshalNode(nodeJSON{Type: NodeHole, Position: h.pos, Hole: &config}, nil)
+}
+
+func (t *TextNode) MarshalJSON() ([]byte, error) {
+	return marshalNode(nodeJSON{Type: NodeText, Position: t.pos, Content: t.Content}, nil)
+}
+
+func (b *BlockNode) MarshalJSON() ([]byte, error) {
+	return marshalNode(nodeJSON{Type: NodeBlock, Position: b.pos}, b.Content)
+}
+
+// UnmarshalJSON decodes a pattern encoded by MarshalJSON, nodes of another
+// type being an error.
+func (p *PatternNode) UnmarshalJSON(data []byte) error {
+	node, err := unmarshalNode(data, NodePattern)
+	if err == nil {
+		*p = *node.(*PatternNode)
+	}
+	return err
+}
+
+func (h *HoleNode) UnmarshalJSON(data []byte) error {
+	node, err := unmarshalNode(data, NodeHole)
+	if err == nil {
+		*h = *node.(*HoleNode)
+	}
+	return err
+}
+
+func (t *TextNode) UnmarshalJSON(data []byte) error {
+	node, err := unmarshalNode(data, NodeText)
+	if err == nil {
+		*t = *node.(*TextNode)
+	}
+	return err
+}
+
+func (b *BlockNode) UnmarshalJSON(data []byte) error {
+	node, err := unmarshalNode(data, NodeBlock)
+	if err == nil {
+		*b = *node.(*BlockNode)
+	}
+	return err
+}
+
+// marshalNode encodes a node along with its children.
+func marshalNode(n nodeJSON, children []Node) ([]byte, error) {
+	for _, child := range children {
+		data, err := json.Marshal(child)
+		if err != nil {
+			return nil, err
+		}
+		n.Children = append(n.Children, data)
+	}
+	return json.Marshal(n)
+}
+
+// unmarshalNode decodes a node of the given type, or of any type for -1.
+func unmarshalNode(data []byte, want NodeType) (Node, error) {
+	var n nodeJSON
+	if err := json.Unmarshal(data, &n); err != nil {
+		return nil, err
+	}
+	if want >= 0 && n.Type != want {
+		return nil, fmt.Errorf("expected a %s node, got a %s node", want, n.Type)
+	}
+
+	children := make([]Node, len(n.Children))
+	for i, child := range n.Children {
+		node, err := unmarshalNode(child, -1)
+		if err != nil {
+			return nil, err
+		}
+		children[i] = node
+	}
+	switch n.Type {
+	case NodePattern:
+		return &PatternNode{Children: children, pos: n.Position}, nil
+	case NodeHole:
+		if n.Hole == nil {
+			return nil, errors.New("hole node without a hole configuration")
+		}
+		return &HoleNode{Config: *n.Hole, pos: n.Position}, nil
+	case NodeText:
+		return &TextNode{Content: n.Content, pos: n.Position}, nil
+	}
+	return &BlockNode{Content: children, pos: n.Position}, nil
+}
+
 func nodesEqual(a, b []Node) bool {
 	if len(a) != len(b) {
 		return false
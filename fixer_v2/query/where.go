@@ -0,0 +1,304 @@
+package query
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// condition is a clause of Where: a predicate on the capture of a hole,
+// which must not hold when negated.
+type condition struct {
+	hole    string
+	negated bool
+	pred    predicate
+}
+
+// predicate tests the capture of a hole, given the configuration of the
+// hole and the matcher testing it.
+type predicate interface {
+	eval(capture string, hole HoleConfig, m *Matcher) (bool, error)
+}
+
+// Where restricts the matches to those whose captures satisfy a clause
+// such as
+//
+//	not match(:[[body]], "std.AssertOriginCall()") and len(:[args]) > 3
+//
+// A condition is one of the predicates:
+//
+//   - match(hole, pattern): the pattern matches within the capture. The
+//     holes of the pattern are its own.
+//   - hole matches "regexp": the capture matches the regular expression.
+//   - hole == "text" and hole != "text": the capture is the text,
+//     whitespace aside.
+//   - len(hole) compared to an integer with ==, !=, <, <=, > or >=: len
+//     counts the items of the capture, such as the arguments of a call,
+//     split as the captures of repeated holes.
+//
+// Strings are written in Go syntax, and not negates a condition.
+// Conditions are joined with and, and the clause may start with where, as
+// in Comby.
+func (m *Matcher) Where(clause string) error {
+	conditions, err := parseWhere(clause)
+	if err != nil {
+		return fmt.Errorf("invalid where clause: %w", err)
+	}
+	for _, c := range conditions {
+		if _, ok := m.holeConfig(c.hole); !ok {
+			return fmt.Errorf("invalid where clause: the pattern has no hole %s", c.hole)
+		}
+	}
+	m.conditions = append(m.conditions, conditions...)
+	return nil
+}
+
+// holeConfig returns the configuration of the first hole binding name.
+func (m *Matcher) holeConfig(name string) (HoleConfig, bool) {
+	for _, e := range m.elems {
+		if e.kind == elemHole && e.hole.Name == name && name != anonymousHole {
+			return e.hole, true
+		}
+	}
+	return HoleConfig{}, false
+}
+
+// holds reports whether a match satisfies the conditions of the matcher.
+func (m *Matcher) holds(match Match) (bool, error) {
+	for _, c := range m.conditions {
+		hole, _ := m.holeConfig(c.hole)
+		ok, err := c.pred.eval(match.Bindings[c.hole], hole, m)
+		if err != nil {
+			return false, err
+		}
+		if ok == c.negated {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+func parseWhere(clause string) ([]condition, error) {
+	rest := strings.TrimSpace(clause)
+	if after, ok := cutWord(rest, "where"); ok {
+		rest = after
+	}
+	var conditions []condition
+	for {
+		c, after, err := parseCondition(rest)
+		if err != nil {
+			return nil, err
+		}
+		conditions = append(conditions, c)
+
+		rest = strings.TrimSpace(after)
+		if rest == "" {
+			return conditions, nil
+		}
+		var ok bool
+		if rest, ok = cutWord(rest, "and"); !ok {
+			return nil, fmt.Errorf("expected and at %q", rest)
+		}
+	}
+}
+
+// parseCondition parses the condition the text starts with, and returns
+// the rest of the text.
+func parseCondition(text string) (condition, string, error) {
+	var c condition
+	rest, negated := cutWord(text, "not")
+	c.negated = negated
+
+	if after, ok := cutWord(rest, "match"); ok {
+		hole, after, err := cutCall(after, true)
+		if err != nil {
+			return c, "", err
+		}
+		pattern, after, err := cutString(after)
+		if err != nil {
+			return c, "", err
+		}
+		matcher, err := Compile(pattern)
+		if err != nil {
+			return c, "", fmt.Errorf("pattern %q: %w", pattern, err)
+		}
+		after, err = cutToken(after, ")")
+		c.hole, c.pred = hole, matchPredicate{matcher}
+		return c, after, err
+	}
+
+	if after, ok := cutWord(rest, "len"); ok {
+		hole, after, err := cutCall(after, false)
+		if err != nil {
+			return c, "", err
+		}
+		after, err = cutToken(after, ")")
+		if err != nil {
+			return c, "", err
+		}
+		op, after, err := cutOperator(after, "==", "!=", "<=", ">=", "<", ">")
+		if err != nil {
+			return c, "", err
+		}
+		digits := strings.TrimSpace(after)
+		end := 0
+		for end < len(digits) && '0' <= digits[end] && digits[end] <= '9' {
+			end++
+		}
+		n, err := strconv.Atoi(digits[:end])
+		if err != nil {
+			return c, "", fmt.Errorf("expected an integer at %q", digits)
+		}
+		c.hole, c.pred = hole, lenPredicate{op: op, n: n}
+		return c, digits[end:], nil
+	}
+
+	hole, after, err := cutHole(rest)
+	if err != nil {
+		return c, "", err
+	}
+	c.hole = hole
+	if after, ok := cutWord(after, "matches"); ok {
+		expr, after, err := cutString(after)
+		if err != nil {
+			return c, "", err
+		}
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return c, "", err
+		}
+		c.pred = regexpPredicate{re}
+		return c, after, nil
+	}
+	op, after, err := cutOperator(after, "==", "!=")
+	if err != nil {
+		return c, "", fmt.Errorf("expected matches, == or != at %q", after)
+	}
+	value, after, err := cutString(after)
+	if err != nil {
+		return c, "", err
+	}
+	c.pred = equalPredicate{normalizeSpace(value)}
+	c.negated = c.negated != (op == "!=")
+	return c, after, nil
+}
+
+// cutWord removes a leading word from the text, and the whitespace after
+// it.
+func cutWord(text, word string) (string, bool) {
+	after, ok := strings.CutPrefix(text, word)
+	if !ok || after != "" && isWordRune(rune(after[0])) {
+		return text, false
+	}
+	return strings.TrimSpace(after), true
+}
+
+// cutToken removes a leading token from the text, whitespace aside.
+func cutToken(text, token string) (string, error) {
+	after, ok := strings.CutPrefix(strings.TrimSpace(text), token)
+	if !ok {
+		return "", fmt.Errorf("expected %s at %q", token, text)
+	}
+	return strings.TrimSpace(after), nil
+}
+
+// cutOperator removes the first of the operators leading the text.
+func cutOperator(text string, operators ...string) (string, string, error) {
+	text = strings.TrimSpace(text)
+	for _, op := range operators {
+		if after, ok := strings.CutPrefix(text, op); ok {
+			return op, after, nil
+		}
+	}
+	return "", "", fmt.Errorf("expected one of %s at %q", strings.Join(operators, " "), text)
+}
+
+// cutCall removes the opening parenthesis and the hole argument of a call,
+// followed by a comma when more arguments follow.
+func cutCall(text string, more bool) (string, string, error) {
+	rest, err := cutToken(text, "(")
+	if err != nil {
+		return "", "", err
+	}
+	hole, rest, err := cutHole(rest)
+	if err != nil || !more {
+		return hole, rest, err
+	}
+	rest, err = cutToken(rest, ",")
+	return hole, rest, err
+}
+
+// cutHole removes a leading hole from the text, and returns its name.
+func cutHole(text string) (string, string, error) {
+	text = strings.TrimSpace(text)
+	closing := "]"
+	if strings.HasPrefix(text, ":[[") {
+		closing = "]]"
+	}
+	end := strings.Index(text, closing)
+	if !strings.HasPrefix(text, ":[") || end < 0 {
+		return "", "", fmt.Errorf("expected a hole at %q", text)
+	}
+	end += len(closing)
+	tokens, err := Tokenize(text[:end])
+	if err != nil || len(tokens) != 2 || tokens[0].Type != TokenHole {
+		return "", "", fmt.Errorf("expected a hole at %q", text)
+	}
+	return tokens[0].HoleConfig.Name, strings.TrimSpace(text[end:]), nil
+}
+
+// cutString removes a leading Go string literal from the text, and returns
+// its value.
+func cutString(text string) (string, string, error) {
+	text = strings.TrimSpace(text)
+	quoted, err := strconv.QuotedPrefix(text)
+	if err != nil || quoted[0] == '\'' {
+		return "", "", fmt.Errorf("expected a quoted string at %q", text)
+	}
+	value, err := strconv.Unquote(quoted)
+	return value, text[len(quoted):], err
+}
+
+type matchPredicate struct{ matcher *Matcher }
+
+func (p matchPredicate) eval(capture string, _ HoleConfig, m *Matcher) (bool, error) {
+	sub := *p.matcher
+	sub.InComments, sub.MaxBacktrack = m.InComments, m.MaxBacktrack
+	found, err := sub.FindAll([]byte(capture))
+	return len(found) > 0, err
+}
+
+type regexpPredicate struct{ re *regexp.Regexp }
+
+func (p regexpPredicate) eval(capture string, _ HoleConfig, _ *Matcher) (bool, error) {
+	return p.re.MatchString(capture), nil
+}
+
+type equalPredicate struct{ text string }
+
+func (p equalPredicate) eval(capture string, _ HoleConfig, _ *Matcher) (bool, error) {
+	return normalizeSpace(capture) == p.text, nil
+}
+
+type lenPredicate struct {
+	op string
+	n  int
+}
+
+func (p lenPredicate) eval(capture string, hole HoleConfig, _ *Matcher) (bool, error) {
+	n := len(splitItems(capture, hole.Type.separators()))
+	switch p.op {
+	case "==":
+		return n == p.n, nil
+	case "!=":
+		return n != p.n, nil
+	case "<":
+		return n < p.n, nil
+	case "<=":
+		return n <= p.n, nil
+	case ">":
+		return n > p.n, nil
+	}
+	return n >= p.n, nil
+}
@@ -0,0 +1,136 @@
+package query
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMatcherWhere(t *testing.T) {
+	src := `func Transfer(to std.Address) {
+	std.AssertOriginCall()
+	send(to)
+}
+
+func Mint(to std.Address) {
+	// std.AssertOriginCall() is checked by the caller
+	mint(to)
+}
+
+func Burn(amount int) {
+	burn(amount)
+}
+`
+	tests := []struct {
+		name   string
+		clause string
+		want   []string // names of the matched functions
+	}{
+		{
+			name:   "not match",
+			clause: `not match(:[[body]], "std.AssertOriginCall()")`,
+			want:   []string{"Mint", "Burn"},
+		},
+		{
+			name:   "match",
+			clause: "where match(:[[body]], `std.AssertOriginCall()`)",
+			want:   []string{"Transfer"},
+		},
+		{
+			name:   "and",
+			clause: `not match(:[[body]], "std.AssertOriginCall()") and match(:[params], ":[_] std.Address")`,
+			want:   []string{"Mint"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := Compile("func :[name](:[params]) { :[[body]] }")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := m.Where(tt.clause); err != nil {
+				t.Fatalf("Where() error = %v", err)
+			}
+			matches, err := m.FindAll([]byte(src))
+			if err != nil {
+				t.Fatalf("FindAll() error = %v", err)
+			}
+			var got []string
+			for _, match := range matches {
+				got = append(got, match.Bindings["name"])
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("FindAll() matched %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMatcherWherePredicates(t *testing.T) {
+	src := "f(1) f(a, b) f(x, y, g(z, w), v) f(nil)"
+	tests := []struct {
+		clause string
+		want   []string // captures of args
+	}{
+		{`:[args] matches "^[0-9]+$"`, []string{"1"}},
+		{`:[args] == "nil"`, []string{"nil"}},
+		{`:[args] == "a,b"`, []string{"a, b"}},
+		{`:[args] != "nil" and len(:[args]) > 1`, []string{"a, b", "x, y, g(z, w), v"}},
+		{`not len(:[args]) == 1`, []string{"a, b", "x, y, g(z, w), v"}},
+		{`len(:[args]) >= 4`, []string{"x, y, g(z, w), v"}},
+		{`len(:[args]) < 2 and not :[args] matches "^[a-z]+$"`, []string{"1"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.clause, func(t *testing.T) {
+			m, err := Compile("f(:[args])")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := m.Where(tt.clause); err != nil {
+				t.Fatalf("Where() error = %v", err)
+			}
+			matches, err := m.FindAll([]byte(src))
+			if err != nil {
+				t.Fatalf("FindAll() error = %v", err)
+			}
+			var got []string
+			for _, match := range matches {
+				got = append(got, match.Bindings["args"])
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("FindAll() captured %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMatcherWhereErrors(t *testing.T) {
+	for _, clause := range []string{
+		"",
+		"where",
+		`match(:[y], "x")`,
+		`match(:[_], "x")`,
+		`match(x, "x")`,
+		`match(:[x] "x")`,
+		`match(:[x], x)`,
+		`match(:[x], ":[y")`,
+		`match(:[x], "x"`,
+		`match(:[x], "x") or match(:[x], "y")`,
+		`nothing(:[x], "x")`,
+		`:[x] matches "("`,
+		`:[x] matches 'a'`,
+		`:[x] ~ "a"`,
+		`:[x] == a`,
+		`len(:[x] > 1`,
+		`len(:[x]) > x`,
+		`len(:[x]) => 1`,
+		`len(:[y]) > 1`,
+	} {
+		m, err := Compile("f(:[x])")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := m.Where(clause); err == nil {
+			t.Errorf("Where(%q) succeeded, want an error", clause)
+		}
+	}
+}
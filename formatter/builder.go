@@ -86,6 +86,7 @@ type IssueData struct {
 	Note            string
 	SnippetLines    []string
 	CommonIndent    string
+	Related         []tt.Location
 }
 
 var funcMap = template.FuncMap{
@@ -97,6 +98,7 @@ var funcMap = template.FuncMap{
 	"message":             message,
 	"warning":             warning,
 	"complexityInfo":      complexityInfo,
+	"related":             related,
 }
 
 var templateCache sync.Map
@@ -112,8 +114,10 @@ func getCachedTemplate(tmplStr string) *template.Template {
 }
 
 func buildIssue(issue tt.Issue, snippet *internal.SourceCode, formatter issueFormatter) string {
-	startLine := issue.Start.Line
-	endLine := issue.End.Line
+	// point issues are rendered as a range covering a single position
+	start, end := issue.Range()
+	startLine := start.Line
+	endLine := end.Line
 	maxLineNumWidth := calculateMaxLineNumWidth(endLine)
 	padding := strings.Repeat(" ", maxLineNumWidth+1)
 
@@ -129,10 +133,10 @@ func buildIssue(issue tt.Issue, snippet *internal.SourceCode, formatter issueFor
 		Category:        issue.Category,
 		Rule:            issue.Rule,
 		Filename:        issue.Filename,
-		StartLine:       issue.Start.Line,
-		StartColumn:     issue.Start.Column,
-		EndLine:         issue.End.Line,
-		EndColumn:       issue.End.Column,
+		StartLine:       start.Line,
+		StartColumn:     start.Column,
+		EndLine:         end.Line,
+		EndColumn:       end.Column,
 		Message:         issue.Message,
 		Suggestion:      issue.Suggestion,
 		Note:            issue.Note,
@@ -140,6 +144,7 @@ func buildIssue(issue tt.Issue, snippet *internal.SourceCode, formatter issueFor
 		Padding:         padding,
 		CommonIndent:    commonIndent,
 		SnippetLines:    snippet.Lines,
+		Related:         issue.Related,
 	}
 
 	issueTemplate := formatter.IssueTemplate()
@@ -147,7 +152,7 @@ func buildIssue(issue tt.Issue, snippet *internal.SourceCode, formatter issueFor
 
 	var buf bytes.Buffer
 	if err := tmpl.Execute(&buf, data); err != nil {
-		return fmt.Sprintf("Error formatting issue: %v", err)
+		return fmt.Sprintf("Error formatting issue: %v\n", err)
 	}
 	return buf.String()
 }
@@ -163,6 +168,8 @@ func header(rule string, severity string, maxLineNumWidth int, filename string,
 		endString = warningStyle.Sprintf("warning: ")
 	case "INFO":
 		endString = messageStyle.Sprintf("info: ")
+	case "HINT":
+		endString = messageStyle.Sprintf("hint: ")
 	}
 
 	endString += ruleStyle.Sprintf("%s\n", rule)
@@ -211,9 +218,15 @@ func underlineAndMessage(message string, padding string, startLine int, endLine
 		underlineStart = 0
 	}
 
-	// calculate underline end position
+	// calculate underline end position. A range over several lines is
+	// underlined to the end of its first line, its end column being
+	// unrelated to the start one.
 	underlineEnd := calculateVisualColumn(snippetLines[endLine-1], endColumn) - commonIndentWidth
-	underlineLength := underlineEnd - underlineStart + 1
+	if endLine > startLine {
+		firstLine := snippetLines[startLine-1]
+		underlineEnd = calculateVisualColumn(firstLine, len(firstLine)+1) - commonIndentWidth - 1
+	}
+	underlineLength := max(underlineEnd-underlineStart+1, 1)
 
 	endString += fmt.Sprint(strings.Repeat(" ", underlineStart))
 	endString += messageStyle.Sprintf("%s\n", strings.Repeat("^", underlineLength))
@@ -265,6 +278,16 @@ func note(note string, padding string, suggestion string) string {
 	return endString
 }
 
+func related(locations []tt.Location, padding string) string {
+	var endString string
+	for _, loc := range locations {
+		endString += lineStyle.Sprintf("%s= ", padding)
+		endString += noStyle.Sprintf("%s: ", loc.Label)
+		endString += fileStyle.Sprintf("%s:%d:%d\n", loc.Position.Filename, loc.Position.Line, loc.Position.Column)
+	}
+	return endString
+}
+
 func isValidLineRange(startLine int, endLine int, snippetLines []string) bool {
 	return startLine > 0 &&
 		endLine > 0 &&
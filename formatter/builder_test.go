@@ -0,0 +1,66 @@
+package formatter
+
+import (
+	"go/token"
+	"testing"
+
+	"github.com/gnolang/tlin/internal"
+	tt "github.com/gnolang/tlin/internal/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormatMultiLineRange(t *testing.T) {
+	t.Parallel()
+	code := &internal.SourceCode{
+		Lines: []string{
+			"package main",
+			"",
+			"var table = []int{",
+			"\t1, 2, 3,",
+			"}",
+		},
+	}
+
+	issues := []tt.Issue{
+		{
+			Rule:     "large-literal",
+			Filename: "test.go",
+			// the end column is left of the start one
+			Start:   token.Position{Line: 3, Column: 13},
+			End:     token.Position{Line: 5, Column: 2},
+			Message: "large literal",
+		},
+		{
+			Rule:     "whole-lines",
+			Filename: "test.go",
+			Start:    token.Position{Line: 3, Column: 1},
+			End:      token.Position{Line: 4, Column: 10},
+			Message:  "from the first column",
+		},
+	}
+
+	expected := `error: large-literal
+ --> test.go:3:13
+  |
+3 | var table = []int{
+4 | 	1, 2, 3,
+5 | }
+  |             ^^^^^^
+  |
+  = large literal
+
+error: whole-lines
+ --> test.go:3:1
+  |
+3 | var table = []int{
+4 | 	1, 2, 3,
+  | ^^^^^^^^^^^^^^^^^^
+  |
+  = from the first column
+
+`
+
+	result := GenerateFormattedIssue(issues, code)
+	assert.Equal(t, expected, result)
+	assert.NotContains(t, result, "Error formatting issue")
+}
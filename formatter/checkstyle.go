@@ -0,0 +1,80 @@
+package formatter
+
+import (
+	"encoding/xml"
+	"io"
+
+	tt "github.com/gnolang/tlin/internal/types"
+)
+
+// checkstyleVersion is the version of the Checkstyle report schema, the one
+// other linters emit and the tools reading the reports expect.
+const checkstyleVersion = "4.3"
+
+// checkstyleFormat renders the issues as a Checkstyle XML report, which
+// tools such as Jenkins and reviewdog read. Files under rootDir are referred
+// to by their path relative to it.
+type checkstyleFormat struct {
+	rootDir string
+}
+
+type checkstyleReport struct {
+	XMLName xml.Name         `xml:"checkstyle"`
+	Version string           `xml:"version,attr"`
+	Files   []checkstyleFile `xml:"file"`
+}
+
+type checkstyleFile struct {
+	Name   string            `xml:"name,attr"`
+	Errors []checkstyleError `xml:"error"`
+}
+
+type checkstyleError struct {
+	Line     int    `xml:"line,attr"`
+	Column   int    `xml:"column,attr,omitempty"`
+	Severity string `xml:"severity,attr"`
+	Message  string `xml:"message,attr"`
+	Source   string `xml:"source,attr"`
+}
+
+// Write renders the issues file by file, in the order of the run within a
+// file, with their rule as source.
+func (f *checkstyleFormat) Write(w io.Writer, report Report) error {
+	out := checkstyleReport{Version: checkstyleVersion}
+	for _, filename := range sortedFilenames(report.Issues) {
+		file := checkstyleFile{Name: relativeTo(f.rootDir, filename)}
+		for _, issue := range issuesOf(report.Issues, filename) {
+			file.Errors = append(file.Errors, checkstyleError{
+				Line:     issue.Start.Line,
+				Column:   issue.Start.Column,
+				Severity: checkstyleSeverity(issue.Severity),
+				Message:  issue.Message,
+				Source:   issue.Rule,
+			})
+		}
+		out.Files = append(out.Files, file)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(out); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// checkstyleSeverity maps a severity to a Checkstyle one.
+func checkstyleSeverity(severity tt.Severity) string {
+	switch severity {
+	case tt.SeverityError:
+		return "error"
+	case tt.SeverityWarning:
+		return "warning"
+	default:
+		return "info"
+	}
+}
@@ -0,0 +1,69 @@
+package formatter
+
+import (
+	"bytes"
+	"encoding/xml"
+	"go/token"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tt "github.com/gnolang/tlin/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckstyleFormat(t *testing.T) {
+	t.Parallel()
+	root := t.TempDir()
+	a := filepath.Join(root, "p", "demo", "a.gno")
+	b := filepath.Join(root, "b.gno")
+
+	issues := []tt.Issue{
+		{
+			Rule:     "useless-break",
+			Filename: a,
+			Message:  "useless break statement",
+			Start:    token.Position{Line: 6, Column: 3},
+			Severity: tt.SeverityWarning,
+		},
+		{
+			Rule:     "errcheck",
+			Filename: b,
+			Message:  `error of "f" is not checked & lost`,
+			Start:    token.Position{Line: 2},
+			Severity: tt.SeverityError,
+		},
+		{
+			Rule:     "useless-break",
+			Filename: a,
+			Message:  "useless break statement",
+			Start:    token.Position{Line: 9, Column: 3},
+			Severity: tt.SeverityHint,
+		},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, (&checkstyleFormat{rootDir: root}).Write(&buf, Report{Issues: issues}))
+	assert.True(t, strings.HasPrefix(buf.String(), xml.Header), "the report starts with the XML declaration")
+	assert.Equal(t, `<?xml version="1.0" encoding="UTF-8"?>
+<checkstyle version="4.3">
+  <file name="b.gno">
+    <error line="2" severity="error" message="error of &#34;f&#34; is not checked &amp; lost" source="errcheck"></error>
+  </file>
+  <file name="p/demo/a.gno">
+    <error line="6" column="3" severity="warning" message="useless break statement" source="useless-break"></error>
+    <error line="9" column="3" severity="info" message="useless break statement" source="useless-break"></error>
+  </file>
+</checkstyle>
+`, buf.String())
+
+	var report checkstyleReport
+	require.NoError(t, xml.Unmarshal(buf.Bytes(), &report))
+	require.Len(t, report.Files, 2)
+	assert.Equal(t, `error of "f" is not checked & lost`, report.Files[0].Errors[0].Message)
+
+	buf.Reset()
+	require.NoError(t, (&checkstyleFormat{rootDir: root}).Write(&buf, Report{}))
+	assert.Equal(t, xml.Header+"<checkstyle version=\"4.3\"></checkstyle>\n", buf.String())
+}
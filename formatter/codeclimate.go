@@ -0,0 +1,97 @@
+package formatter
+
+import (
+	"encoding/json"
+	"io"
+
+	tt "github.com/gnolang/tlin/internal/types"
+)
+
+// codeClimateFormat renders the issues as a Code Climate report, the code
+// quality artifact GitLab shows in merge requests. Files under rootDir are
+// referred to by their path relative to it, as GitLab expects.
+type codeClimateFormat struct {
+	rootDir string
+}
+
+type codeClimateIssue struct {
+	Type        string              `json:"type"`
+	CheckName   string              `json:"check_name"`
+	Description string              `json:"description"`
+	Categories  []string            `json:"categories"`
+	Severity    string              `json:"severity"`
+	Fingerprint string              `json:"fingerprint"`
+	Location    codeClimateLocation `json:"location"`
+}
+
+type codeClimateLocation struct {
+	Path  string           `json:"path"`
+	Lines codeClimateLines `json:"lines"`
+}
+
+type codeClimateLines struct {
+	Begin int `json:"begin"`
+	End   int `json:"end"`
+}
+
+// Write renders the issues as a JSON array, sorted by file. The issues
+// keep their fingerprint, so that GitLab tells the new ones from the ones
+// of the target branch; those without one are identified by their ID.
+func (f *codeClimateFormat) Write(w io.Writer, report Report) error {
+	issues := []codeClimateIssue{}
+	for _, filename := range sortedFilenames(report.Issues) {
+		for _, issue := range issuesOf(report.Issues, filename) {
+			start, end := issue.Range()
+			fingerprint := issue.Fingerprint
+			if fingerprint == "" {
+				fingerprint = issue.ID()
+			}
+			issues = append(issues, codeClimateIssue{
+				Type:        "issue",
+				CheckName:   issue.Rule,
+				Description: issue.Message,
+				Categories:  []string{codeClimateCategory(issue.Category)},
+				Severity:    codeClimateSeverity(issue.Severity),
+				Fingerprint: fingerprint,
+				Location: codeClimateLocation{
+					Path:  relativeTo(f.rootDir, filename),
+					Lines: codeClimateLines{Begin: start.Line, End: end.Line},
+				},
+			})
+		}
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(issues)
+}
+
+// codeClimateCategory maps the category of a rule to a Code Climate one.
+// The rules without category, such as the linters of golangci-lint, are
+// style issues.
+func codeClimateCategory(category string) string {
+	switch category {
+	case "correctness":
+		return "Bug Risk"
+	case "performance":
+		return "Performance"
+	case "complexity":
+		return "Complexity"
+	default:
+		return "Style"
+	}
+}
+
+// codeClimateSeverity maps a severity to a Code Climate one.
+func codeClimateSeverity(severity tt.Severity) string {
+	switch severity {
+	case tt.SeverityError:
+		return "critical"
+	case tt.SeverityWarning:
+		return "major"
+	case tt.SeverityInfo:
+		return "minor"
+	default:
+		return "info"
+	}
+}
@@ -0,0 +1,85 @@
+package formatter
+
+import (
+	"bytes"
+	"go/token"
+	"path/filepath"
+	"testing"
+
+	tt "github.com/gnolang/tlin/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCodeClimateFormat(t *testing.T) {
+	t.Parallel()
+	root := t.TempDir()
+	a := filepath.Join(root, "p", "demo", "a.gno")
+	b := filepath.Join(root, "b.gno")
+
+	issues := []tt.Issue{
+		{
+			Rule:        "useless-break",
+			Category:    "style",
+			Filename:    a,
+			Message:     "useless break statement",
+			Start:       token.Position{Line: 6, Column: 3},
+			End:         token.Position{Line: 7, Column: 8},
+			Severity:    tt.SeverityWarning,
+			Fingerprint: "3f1c",
+		},
+		{
+			Rule:     "errcheck",
+			Filename: b,
+			Message:  "error return value is not checked",
+			Start:    token.Position{Line: 2, Column: 1},
+			Severity: tt.SeverityError,
+		},
+		{
+			Rule:        "simplify-slice-range",
+			Category:    "performance",
+			Filename:    a,
+			Message:     "unnecessary use of len() in slice expression",
+			Start:       token.Position{Line: 9, Column: 3},
+			Severity:    tt.SeverityHint,
+			Fingerprint: "9a0b",
+		},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, (&codeClimateFormat{rootDir: root}).Write(&buf, Report{Issues: issues}))
+	assert.JSONEq(t, `[
+		{
+			"type": "issue",
+			"check_name": "errcheck",
+			"description": "error return value is not checked",
+			"categories": ["Style"],
+			"severity": "critical",
+			"fingerprint": "`+issues[1].ID()+`",
+			"location": {"path": "b.gno", "lines": {"begin": 2, "end": 2}}
+		},
+		{
+			"type": "issue",
+			"check_name": "useless-break",
+			"description": "useless break statement",
+			"categories": ["Style"],
+			"severity": "major",
+			"fingerprint": "3f1c",
+			"location": {"path": "p/demo/a.gno", "lines": {"begin": 6, "end": 7}}
+		},
+		{
+			"type": "issue",
+			"check_name": "simplify-slice-range",
+			"description": "unnecessary use of len() in slice expression",
+			"categories": ["Performance"],
+			"severity": "info",
+			"fingerprint": "9a0b",
+			"location": {"path": "p/demo/a.gno", "lines": {"begin": 9, "end": 9}}
+		}
+	]`, buf.String())
+
+	// GitLab expects an array even without issues
+	buf.Reset()
+	require.NoError(t, (&codeClimateFormat{rootDir: root}).Write(&buf, Report{}))
+	assert.Equal(t, "[]\n", buf.String())
+}
@@ -17,6 +17,10 @@ func (f *CyclomaticComplexityFormatter) IssueTemplate() string {
 {{note .Note .Padding .Suggestion}}
 {{- end }}
 
+{{- if .Related }}
+{{related .Related .Padding}}
+{{- end }}
+
 {{- if .Suggestion }}
 {{suggestion .Suggestion .Padding .MaxLineNumWidth .StartLine}}
 {{- end }}
@@ -290,3 +290,59 @@ func TestFindCommonIndent(t *testing.T) {
 		})
 	}
 }
+
+func TestFormatPointAndRelatedIssues(t *testing.T) {
+	t.Parallel()
+	code := &internal.SourceCode{
+		Lines: []string{
+			"package main",
+			"",
+			"func main() {",
+			"    x := 1",
+			"    x := 2",
+			"}",
+		},
+	}
+
+	issues := []tt.Issue{
+		{
+			Rule:     "point",
+			Filename: "test.go",
+			Start:    token.Position{Line: 4, Column: 5},
+			Message:  "issue without an end position",
+		},
+		{
+			Rule:     "redeclared",
+			Filename: "test.go",
+			Start:    token.Position{Line: 5, Column: 5},
+			End:      token.Position{Line: 5, Column: 5},
+			Message:  "x redeclared",
+			Related: []tt.Location{
+				{Position: token.Position{Filename: "test.go", Line: 4, Column: 5}, Label: "previous declaration"},
+			},
+		},
+	}
+
+	expected := `error: point
+ --> test.go:4:5
+  |
+4 | x := 1
+  | ^
+  |
+  = issue without an end position
+
+error: redeclared
+ --> test.go:5:5
+  |
+5 | x := 2
+  | ^
+  |
+  = x redeclared
+
+  = previous declaration: test.go:4:5
+
+`
+
+	result := GenerateFormattedIssue(issues, code)
+	assert.Equal(t, expected, result)
+}
@@ -11,6 +11,10 @@ func (f *GeneralIssueFormatter) IssueTemplate() string {
 {{note .Note .Padding .Suggestion}}
 {{- end }}
 
+{{- if .Related }}
+{{related .Related .Padding}}
+{{- end }}
+
 {{- if .Suggestion }}
 {{suggestion .Suggestion .Padding .MaxLineNumWidth .StartLine}}
 {{- end }}
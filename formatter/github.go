@@ -0,0 +1,67 @@
+package formatter
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	tt "github.com/gnolang/tlin/internal/types"
+)
+
+// githubFormat prints the issues as GitHub Actions workflow commands, which
+// the runner turns into annotations of the lines of the pull request. Files
+// under rootDir, the checkout of the repository, are referred to by their
+// path relative to it.
+type githubFormat struct {
+	rootDir string
+}
+
+// Write prints one command per issue, such as
+//
+//	::warning file=p/demo/a.gno,line=6,col=3,endLine=6,endColumn=8,title=useless-break::useless break statement
+func (f *githubFormat) Write(w io.Writer, report Report) error {
+	for _, issue := range report.Issues {
+		start, end := issue.Range()
+		properties := []string{
+			"file=" + escapeGitHubProperty(relativeTo(f.rootDir, issue.Filename)),
+			fmt.Sprintf("line=%d", start.Line),
+		}
+		if start.Column > 0 {
+			properties = append(properties, fmt.Sprintf("col=%d", start.Column))
+		}
+		properties = append(properties, fmt.Sprintf("endLine=%d", end.Line))
+		if end.Column > 0 {
+			properties = append(properties, fmt.Sprintf("endColumn=%d", end.Column))
+		}
+		properties = append(properties, "title="+escapeGitHubProperty(issue.Rule))
+
+		if _, err := fmt.Fprintf(w, "::%s %s::%s\n", githubLevel(issue.Severity), strings.Join(properties, ","), escapeGitHubData(issue.Message)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// githubLevel maps a severity to the command of its annotations.
+func githubLevel(severity tt.Severity) string {
+	switch severity {
+	case tt.SeverityError:
+		return "error"
+	case tt.SeverityWarning:
+		return "warning"
+	default:
+		return "notice"
+	}
+}
+
+// escapeGitHubData escapes the message of a workflow command, which ends at
+// the end of the line.
+func escapeGitHubData(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A").Replace(s)
+}
+
+// escapeGitHubProperty escapes the value of a property of a workflow
+// command, which also ends at a comma or a colon.
+func escapeGitHubProperty(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C").Replace(s)
+}
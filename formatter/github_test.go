@@ -0,0 +1,50 @@
+package formatter
+
+import (
+	"bytes"
+	"go/token"
+	"path/filepath"
+	"testing"
+
+	tt "github.com/gnolang/tlin/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGitHubFormat(t *testing.T) {
+	t.Parallel()
+	root := t.TempDir()
+	outside := filepath.Join(t.TempDir(), "b,c.gno")
+
+	issues := []tt.Issue{
+		{
+			Rule:     "useless-break",
+			Filename: filepath.Join(root, "p", "demo", "a.gno"),
+			Message:  "useless break statement",
+			Start:    token.Position{Line: 6, Column: 3},
+			End:      token.Position{Line: 6, Column: 8},
+			Severity: tt.SeverityWarning,
+		},
+		{
+			Rule:     "errcheck",
+			Filename: outside,
+			Message:  "error return value is not checked:\n100% sure",
+			Start:    token.Position{Line: 2, Column: 1},
+			Severity: tt.SeverityError,
+		},
+		{
+			Rule:     "golangci-lint:unused",
+			Filename: filepath.Join(root, "a.go"),
+			Message:  "unused",
+			Start:    token.Position{Line: 4},
+			Severity: tt.SeverityInfo,
+		},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, (&githubFormat{rootDir: root}).Write(&buf, Report{Issues: issues}))
+	assert.Equal(t, "::warning file=p/demo/a.gno,line=6,col=3,endLine=6,endColumn=8,title=useless-break::useless break statement\n"+
+		"::error file="+escapeGitHubProperty(filepath.ToSlash(outside))+",line=2,col=1,endLine=2,endColumn=1,title=errcheck::error return value is not checked:%0A100%25 sure\n"+
+		"::notice file=a.go,line=4,endLine=4,title=golangci-lint%3Aunused::unused\n", buf.String())
+	assert.Contains(t, buf.String(), "b%2Cc.gno")
+}
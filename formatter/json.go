@@ -0,0 +1,138 @@
+package formatter
+
+import (
+	"encoding/json"
+	"go/token"
+	"io"
+
+	tt "github.com/gnolang/tlin/internal/types"
+)
+
+// JSONSchemaVersion is the version of the JSON document. It only changes
+// when a field is removed or changes meaning; fields may be added to the
+// document at any time.
+const JSONSchemaVersion = 1
+
+// JSONDocument is the JSON output of a run.
+type JSONDocument struct {
+	Version int `json:"version"`
+	// Issues are sorted by file, in the order of the run within a file.
+	Issues []JSONIssue `json:"issues"`
+}
+
+// JSONIssue is an issue of the JSON output.
+type JSONIssue struct {
+	// ID identifies the issue within the run, and Fingerprint across runs.
+	ID          string `json:"id"`
+	Fingerprint string `json:"fingerprint,omitempty"`
+
+	File     string `json:"file"`
+	Rule     string `json:"rule"`
+	Category string `json:"category,omitempty"`
+	// Severity is one of ERROR, WARNING, INFO and HINT.
+	Severity string       `json:"severity"`
+	Start    JSONPosition `json:"start"`
+	End      JSONPosition `json:"end"`
+	Message  string       `json:"message"`
+	Note     string       `json:"note,omitempty"`
+
+	// Suggestion is the code suggested in place of the issue, and Fix the
+	// edit -fix would apply for it, when it is confident enough to.
+	Suggestion string         `json:"suggestion,omitempty"`
+	Confidence float64        `json:"confidence"`
+	Fix        *JSONFix       `json:"fix,omitempty"`
+	Related    []JSONLocation `json:"related,omitempty"`
+}
+
+// JSONPosition is a position in a file. Lines and columns start at 1, the
+// column and the offset count bytes.
+type JSONPosition struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+	Offset int `json:"offset"`
+}
+
+// JSONFix lists the edits fixing an issue, in the order of the file.
+type JSONFix struct {
+	Edits []JSONEdit `json:"edits"`
+}
+
+// JSONEdit replaces the text of the file from Start, included, to End,
+// excluded, with Replacement.
+type JSONEdit struct {
+	Start       JSONPosition `json:"start"`
+	End         JSONPosition `json:"end"`
+	Replacement string       `json:"replacement"`
+}
+
+// JSONLocation is a secondary location an issue refers to.
+type JSONLocation struct {
+	File  string       `json:"file"`
+	At    JSONPosition `json:"at"`
+	Label string       `json:"label"`
+}
+
+// jsonFormat renders the issues as a JSONDocument.
+type jsonFormat struct{}
+
+func (jsonFormat) Write(w io.Writer, report Report) error {
+	fixes := editsByIssue(report)
+
+	doc := JSONDocument{Version: JSONSchemaVersion, Issues: []JSONIssue{}}
+	for _, filename := range sortedFilenames(report.Issues) {
+		for _, issue := range issuesOf(report.Issues, filename) {
+			out := NewJSONIssue(issue)
+			if edits, ok := fixes[out.ID]; ok {
+				out.Fix = &JSONFix{}
+				for _, edit := range edits {
+					out.Fix.Edits = append(out.Fix.Edits, JSONEdit{
+						Start:       JSONPosition{Line: edit.StartLine, Column: edit.StartColumn, Offset: edit.StartOffset},
+						End:         JSONPosition{Line: edit.EndLine, Column: edit.EndColumn, Offset: edit.EndOffset},
+						Replacement: edit.Replacement,
+					})
+				}
+			}
+			doc.Issues = append(doc.Issues, out)
+		}
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(doc)
+}
+
+// NewJSONIssue maps an issue to the JSON output. Fix is left out, as it
+// depends on the other issues of the report.
+func NewJSONIssue(issue tt.Issue) JSONIssue {
+	start, end := issue.Range()
+	out := JSONIssue{
+		ID:          issue.ID(),
+		Fingerprint: issue.Fingerprint,
+		File:        issue.Filename,
+		Rule:        issue.Rule,
+		Category:    issue.Category,
+		Severity:    issue.Severity.String(),
+		Start:       jsonPosition(start),
+		End:         jsonPosition(end),
+		Message:     issue.Message,
+		Note:        issue.Note,
+		Suggestion:  issue.Suggestion,
+		Confidence:  issue.Confidence,
+	}
+	for _, related := range issue.Related {
+		file := related.Position.Filename
+		if file == "" {
+			file = issue.Filename
+		}
+		out.Related = append(out.Related, JSONLocation{
+			File:  file,
+			At:    jsonPosition(related.Position),
+			Label: related.Label,
+		})
+	}
+	return out
+}
+
+func jsonPosition(pos token.Position) JSONPosition {
+	return JSONPosition{Line: pos.Line, Column: pos.Column, Offset: pos.Offset}
+}
@@ -0,0 +1,100 @@
+package formatter
+
+import (
+	"bytes"
+	"encoding/json"
+	"go/token"
+	"testing"
+
+	"github.com/gnolang/tlin/internal/fixer"
+	tt "github.com/gnolang/tlin/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJSONFormat(t *testing.T) {
+	t.Parallel()
+	issues := []tt.Issue{
+		{
+			Rule:       "simplify-slice-range",
+			Category:   "style",
+			Filename:   "b.gno",
+			Message:    "unnecessary use of len() in slice expression, can be simplified",
+			Suggestion: "_ = slice[:]",
+			Start:      token.Position{Line: 5, Column: 2, Offset: 40},
+			End:        token.Position{Line: 5, Column: 24, Offset: 62},
+			Confidence: 0.9,
+			Severity:   tt.SeverityError,
+		},
+		{
+			Rule:        "duplicate-branch",
+			Filename:    "a.gno",
+			Message:     "duplicate branch",
+			Start:       token.Position{Line: 3, Column: 1},
+			Severity:    tt.SeverityWarning,
+			Fingerprint: "3f1c",
+			Related: []tt.Location{
+				{Position: token.Position{Line: 1, Column: 1}, Label: "same as here"},
+			},
+		},
+	}
+	patch := &fixer.Patch{
+		Filename: "b.gno",
+		Edits: []fixer.Edit{{
+			Rule:        "simplify-slice-range",
+			IssueID:     issues[0].ID(),
+			StartLine:   5,
+			StartColumn: 1,
+			EndLine:     5,
+			EndColumn:   24,
+			StartOffset: 39,
+			EndOffset:   62,
+			Replacement: "_ = slice[:]",
+		}},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, jsonFormat{}.Write(&buf, Report{Issues: issues, Fixes: []*fixer.Patch{patch}}))
+
+	var doc JSONDocument
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &doc))
+	assert.Equal(t, JSONDocument{
+		Version: JSONSchemaVersion,
+		Issues: []JSONIssue{
+			{
+				ID:          issues[1].ID(),
+				Fingerprint: "3f1c",
+				File:        "a.gno",
+				Rule:        "duplicate-branch",
+				Severity:    "WARNING",
+				// issues without end are a point
+				Start:   JSONPosition{Line: 3, Column: 1},
+				End:     JSONPosition{Line: 3, Column: 1},
+				Message: "duplicate branch",
+				Related: []JSONLocation{{File: "a.gno", At: JSONPosition{Line: 1, Column: 1}, Label: "same as here"}},
+			},
+			{
+				ID:         issues[0].ID(),
+				File:       "b.gno",
+				Rule:       "simplify-slice-range",
+				Category:   "style",
+				Severity:   "ERROR",
+				Start:      JSONPosition{Line: 5, Column: 2, Offset: 40},
+				End:        JSONPosition{Line: 5, Column: 24, Offset: 62},
+				Message:    "unnecessary use of len() in slice expression, can be simplified",
+				Suggestion: "_ = slice[:]",
+				Confidence: 0.9,
+				Fix: &JSONFix{Edits: []JSONEdit{{
+					Start:       JSONPosition{Line: 5, Column: 1, Offset: 39},
+					End:         JSONPosition{Line: 5, Column: 24, Offset: 62},
+					Replacement: "_ = slice[:]",
+				}}},
+			},
+		},
+	}, doc)
+
+	// an empty run still yields a list
+	buf.Reset()
+	require.NoError(t, jsonFormat{}.Write(&buf, Report{}))
+	assert.JSONEq(t, `{"version": 1, "issues": []}`, buf.String())
+}
@@ -0,0 +1,99 @@
+package formatter
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"sort"
+)
+
+// junitFormat renders the issues as a JUnit XML report, for the CI systems
+// that only display test results: each rule is a test suite, and each of
+// its issues a failed test case. Files under rootDir are referred to by
+// their path relative to it.
+type junitFormat struct {
+	rootDir string
+}
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name     string          `xml:"name,attr"`
+	Tests    int             `xml:"tests,attr"`
+	Failures int             `xml:"failures,attr"`
+	Cases    []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string       `xml:"name,attr"`
+	ClassName string       `xml:"classname,attr"`
+	Failure   junitFailure `xml:"failure"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// Write renders a suite per rule, sorted by name, with the issues of the
+// rule sorted by file, in the order of the run within a file.
+func (f *junitFormat) Write(w io.Writer, report Report) error {
+	out := junitTestSuites{Name: toolName}
+	byRule := make(map[string]*junitTestSuite)
+	for _, filename := range sortedFilenames(report.Issues) {
+		for _, issue := range issuesOf(report.Issues, filename) {
+			suite, ok := byRule[issue.Rule]
+			if !ok {
+				suite = &junitTestSuite{Name: issue.Rule}
+				byRule[issue.Rule] = suite
+			}
+			start, _ := issue.Range()
+			location := fmt.Sprintf("%s:%d:%d", relativeTo(f.rootDir, filename), start.Line, start.Column)
+			text := location + ": " + issue.Message
+			if issue.Note != "" {
+				text += "\n" + issue.Note
+			}
+			suite.Cases = append(suite.Cases, junitTestCase{
+				Name:      location,
+				ClassName: issue.Rule,
+				Failure: junitFailure{
+					Message: issue.Message,
+					Type:    issue.Severity.String(),
+					Text:    text,
+				},
+			})
+			suite.Tests++
+			suite.Failures++
+		}
+	}
+
+	rules := make([]string, 0, len(byRule))
+	for rule := range byRule {
+		rules = append(rules, rule)
+	}
+	sort.Strings(rules)
+	for _, rule := range rules {
+		suite := byRule[rule]
+		out.Suites = append(out.Suites, *suite)
+		out.Tests += suite.Tests
+		out.Failures += suite.Failures
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(out); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
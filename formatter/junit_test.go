@@ -0,0 +1,74 @@
+package formatter
+
+import (
+	"bytes"
+	"encoding/xml"
+	"go/token"
+	"path/filepath"
+	"testing"
+
+	tt "github.com/gnolang/tlin/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJUnitFormat(t *testing.T) {
+	t.Parallel()
+	root := t.TempDir()
+	a := filepath.Join(root, "p", "demo", "a.gno")
+	b := filepath.Join(root, "b.gno")
+
+	issues := []tt.Issue{
+		{
+			Rule:     "useless-break",
+			Filename: a,
+			Message:  "useless break statement",
+			Note:     "remove the break",
+			Start:    token.Position{Line: 6, Column: 3},
+			Severity: tt.SeverityWarning,
+		},
+		{
+			Rule:     "errcheck",
+			Filename: b,
+			Message:  "error return value is not checked",
+			Start:    token.Position{Line: 2, Column: 1},
+			Severity: tt.SeverityError,
+		},
+		{
+			Rule:     "useless-break",
+			Filename: b,
+			Message:  "useless break statement",
+			Start:    token.Position{Line: 9, Column: 3},
+			Severity: tt.SeverityWarning,
+		},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, (&junitFormat{rootDir: root}).Write(&buf, Report{Issues: issues}))
+	assert.Equal(t, `<?xml version="1.0" encoding="UTF-8"?>
+<testsuites name="tlin" tests="3" failures="3">
+  <testsuite name="errcheck" tests="1" failures="1">
+    <testcase name="b.gno:2:1" classname="errcheck">
+      <failure message="error return value is not checked" type="ERROR">b.gno:2:1: error return value is not checked</failure>
+    </testcase>
+  </testsuite>
+  <testsuite name="useless-break" tests="2" failures="2">
+    <testcase name="b.gno:9:3" classname="useless-break">
+      <failure message="useless break statement" type="WARNING">b.gno:9:3: useless break statement</failure>
+    </testcase>
+    <testcase name="p/demo/a.gno:6:3" classname="useless-break">
+      <failure message="useless break statement" type="WARNING">p/demo/a.gno:6:3: useless break statement&#xA;remove the break</failure>
+    </testcase>
+  </testsuite>
+</testsuites>
+`, buf.String())
+
+	var report junitTestSuites
+	require.NoError(t, xml.Unmarshal(buf.Bytes(), &report))
+	require.Len(t, report.Suites, 2)
+	assert.Equal(t, "p/demo/a.gno:6:3: useless break statement\nremove the break", report.Suites[1].Cases[1].Failure.Text)
+
+	buf.Reset()
+	require.NoError(t, (&junitFormat{rootDir: root}).Write(&buf, Report{}))
+	assert.Equal(t, xml.Header+`<testsuites name="tlin" tests="0" failures="0"></testsuites>`+"\n", buf.String())
+}
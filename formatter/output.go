@@ -0,0 +1,144 @@
+package formatter
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/gnolang/tlin/internal"
+	"github.com/gnolang/tlin/internal/fixer"
+	tt "github.com/gnolang/tlin/internal/types"
+)
+
+// output formats
+const (
+	FormatText        = "text"
+	FormatJSON        = "json"
+	FormatSARIF       = "sarif"
+	FormatGitHub      = "github"
+	FormatCheckstyle  = "checkstyle"
+	FormatJUnit       = "junit"
+	FormatCodeClimate = "codeclimate"
+)
+
+// Report is what an output format renders: the issues of a run and the
+// fixes computed for them, which only some formats describe.
+type Report struct {
+	Issues []tt.Issue
+	Fixes  []*fixer.Patch
+}
+
+// Format renders the report of a run.
+type Format interface {
+	Write(w io.Writer, report Report) error
+}
+
+var formats = map[string]func() Format{
+	FormatText:        func() Format { return textFormat{} },
+	FormatJSON:        func() Format { return jsonFormat{} },
+	FormatSARIF:       func() Format { return &sarifFormat{rootDir: "."} },
+	FormatGitHub:      func() Format { return &githubFormat{rootDir: "."} },
+	FormatCheckstyle:  func() Format { return &checkstyleFormat{rootDir: "."} },
+	FormatJUnit:       func() Format { return &junitFormat{rootDir: "."} },
+	FormatCodeClimate: func() Format { return &codeClimateFormat{rootDir: "."} },
+}
+
+// NewFormat returns the output format with the given name.
+func NewFormat(name string) (Format, error) {
+	newFormat, ok := formats[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown output format %q (expected one of %s)", name, strings.Join(FormatNames(), ", "))
+	}
+	return newFormat(), nil
+}
+
+// FormatNames returns the names of the output formats, sorted.
+func FormatNames() []string {
+	names := make([]string, 0, len(formats))
+	for name := range formats {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// textFormat renders the issues for humans, file by file, with the snippet
+// of code they point to.
+type textFormat struct{}
+
+// Write renders the issues of every file it can read, and returns the errors
+// reading the others.
+func (textFormat) Write(w io.Writer, report Report) error {
+	var errs []error
+	for _, filename := range sortedFilenames(report.Issues) {
+		sourceCode, err := internal.ReadSourceCode(filename)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("error reading source file %s: %w", filename, err))
+			continue
+		}
+		if _, err := fmt.Fprintln(w, GenerateFormattedIssue(issuesOf(report.Issues, filename), sourceCode)); err != nil {
+			return err
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// editsByIssue indexes the edits of the fixes by the ID of their issue, in
+// the order of the file.
+func editsByIssue(report Report) map[string][]fixer.Edit {
+	edits := make(map[string][]fixer.Edit)
+	for _, patch := range report.Fixes {
+		for _, edit := range patch.Edits {
+			edits[edit.IssueID] = append(edits[edit.IssueID], edit)
+		}
+	}
+	for _, issueEdits := range edits {
+		sort.Slice(issueEdits, func(i, j int) bool {
+			return issueEdits[i].StartOffset < issueEdits[j].StartOffset
+		})
+	}
+	return edits
+}
+
+// relativeTo returns the slash-separated path of a file relative to root
+// when the file is beneath it, and its path as is otherwise.
+func relativeTo(root, filename string) string {
+	abs, err := filepath.Abs(filename)
+	if err != nil {
+		return filepath.ToSlash(filename)
+	}
+	if absRoot, err := filepath.Abs(root); err == nil {
+		if rel, err := filepath.Rel(absRoot, abs); err == nil && filepath.IsLocal(rel) {
+			return filepath.ToSlash(rel)
+		}
+	}
+	return filepath.ToSlash(filename)
+}
+
+// sortedFilenames returns the files of the issues, sorted.
+func sortedFilenames(issues []tt.Issue) []string {
+	seen := make(map[string]bool)
+	var filenames []string
+	for _, issue := range issues {
+		if !seen[issue.Filename] {
+			seen[issue.Filename] = true
+			filenames = append(filenames, issue.Filename)
+		}
+	}
+	sort.Strings(filenames)
+	return filenames
+}
+
+// issuesOf returns the issues of a file, in their order.
+func issuesOf(issues []tt.Issue, filename string) []tt.Issue {
+	var fileIssues []tt.Issue
+	for _, issue := range issues {
+		if issue.Filename == filename {
+			fileIssues = append(fileIssues, issue)
+		}
+	}
+	return fileIssues
+}
@@ -0,0 +1,263 @@
+package formatter
+
+import (
+	"encoding/json"
+	"io"
+	"net/url"
+	"path/filepath"
+	"strings"
+
+	"github.com/gnolang/tlin/internal"
+	tt "github.com/gnolang/tlin/internal/types"
+)
+
+const (
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+	sarifVersion = "2.1.0"
+	toolName     = "tlin"
+	toolURI      = "https://github.com/gnolang/tlin"
+
+	// fingerprintKey names the fingerprint of the issues among the partial
+	// fingerprints of a result, versioned as SARIF recommends.
+	fingerprintKey = "tlinFingerprint/v1"
+)
+
+// sarifFormat renders the issues as a SARIF 2.1.0 log, the format code
+// scanning services such as GitHub's accept. Files under rootDir are
+// referred to by their path relative to it, the others by a file URI.
+type sarifFormat struct {
+	rootDir string
+}
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID                   string              `json:"id"`
+	ShortDescription     *sarifMessage       `json:"shortDescription,omitempty"`
+	DefaultConfiguration *sarifConfiguration `json:"defaultConfiguration,omitempty"`
+	Properties           *sarifRuleProps     `json:"properties,omitempty"`
+}
+
+type sarifConfiguration struct {
+	Level string `json:"level"`
+}
+
+type sarifRuleProps struct {
+	Tags []string `json:"tags"`
+}
+
+type sarifResult struct {
+	RuleID              string            `json:"ruleId"`
+	RuleIndex           int               `json:"ruleIndex"`
+	Level               string            `json:"level"`
+	Message             sarifMessage      `json:"message"`
+	Locations           []sarifLocation   `json:"locations"`
+	RelatedLocations    []sarifLocation   `json:"relatedLocations,omitempty"`
+	PartialFingerprints map[string]string `json:"partialFingerprints,omitempty"`
+	Fixes               []sarifFix        `json:"fixes,omitempty"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifLocation struct {
+	ID               *int                  `json:"id,omitempty"`
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+	Message          *sarifMessage         `json:"message,omitempty"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           sarifRegion           `json:"region"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+// sarifRegion is a range of a file.
+type sarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn,omitempty"`
+	EndLine     int `json:"endLine,omitempty"`
+	EndColumn   int `json:"endColumn,omitempty"`
+}
+
+type sarifFix struct {
+	Description     sarifMessage          `json:"description"`
+	ArtifactChanges []sarifArtifactChange `json:"artifactChanges"`
+}
+
+type sarifArtifactChange struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Replacements     []sarifReplacement    `json:"replacements"`
+}
+
+type sarifReplacement struct {
+	DeletedRegion   sarifRegion  `json:"deletedRegion"`
+	InsertedContent sarifMessage `json:"insertedContent"`
+}
+
+// Write renders the issues along with the metadata of their rules. The
+// fixes of the report are attached to the results of the issues they fix.
+func (f *sarifFormat) Write(w io.Writer, report Report) error {
+	fixes := editsByIssue(report)
+
+	run := sarifRun{
+		Tool: sarifTool{Driver: sarifDriver{
+			Name:           toolName,
+			InformationURI: toolURI,
+			Rules:          []sarifRule{},
+		}},
+		Results: []sarifResult{},
+	}
+	ruleIndex := make(map[string]int)
+	for _, issue := range report.Issues {
+		index, ok := ruleIndex[issue.Rule]
+		if !ok {
+			index = len(run.Tool.Driver.Rules)
+			ruleIndex[issue.Rule] = index
+			run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, sarifRuleOf(issue.Rule))
+		}
+
+		start, end := issue.Range()
+		result := sarifResult{
+			RuleID:    issue.Rule,
+			RuleIndex: index,
+			Level:     sarifLevel(issue.Severity),
+			Message:   sarifMessage{Text: issue.Message},
+			Locations: []sarifLocation{{
+				PhysicalLocation: f.physicalLocation(issue.Filename, sarifRegion{
+					StartLine:   start.Line,
+					StartColumn: start.Column,
+					EndLine:     end.Line,
+					EndColumn:   end.Column,
+				}),
+			}},
+		}
+		for i, related := range issue.Related {
+			id := i + 1
+			filename := related.Position.Filename
+			if filename == "" {
+				filename = issue.Filename
+			}
+			result.RelatedLocations = append(result.RelatedLocations, sarifLocation{
+				ID: &id,
+				PhysicalLocation: f.physicalLocation(filename, sarifRegion{
+					StartLine:   related.Position.Line,
+					StartColumn: related.Position.Column,
+				}),
+				Message: &sarifMessage{Text: related.Label},
+			})
+		}
+		if issue.Fingerprint != "" {
+			result.PartialFingerprints = map[string]string{fingerprintKey: issue.Fingerprint}
+		}
+		if edits, ok := fixes[issue.ID()]; ok {
+			change := sarifArtifactChange{ArtifactLocation: sarifArtifactLocation{URI: f.uri(issue.Filename)}}
+			for _, edit := range edits {
+				change.Replacements = append(change.Replacements, sarifReplacement{
+					DeletedRegion: sarifRegion{
+						StartLine:   edit.StartLine,
+						StartColumn: edit.StartColumn,
+						EndLine:     edit.EndLine,
+						EndColumn:   edit.EndColumn,
+					},
+					InsertedContent: sarifMessage{Text: edit.Replacement},
+				})
+			}
+			result.Fixes = []sarifFix{{
+				Description:     sarifMessage{Text: "apply the suggestion of " + issue.Rule},
+				ArtifactChanges: []sarifArtifactChange{change},
+			}}
+		}
+		run.Results = append(run.Results, result)
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(sarifLog{
+		Schema:  sarifSchema,
+		Version: sarifVersion,
+		Runs:    []sarifRun{run},
+	})
+}
+
+// sarifRuleOf describes a rule. Rules that are not registered, such as the
+// linters of golangci-lint, are only described by their name.
+func sarifRuleOf(name string) sarifRule {
+	rule := sarifRule{ID: name}
+	info, ok := internal.LookupRule(name)
+	if !ok {
+		return rule
+	}
+	if info.Description != "" {
+		rule.ShortDescription = &sarifMessage{Text: info.Description}
+	}
+	rule.DefaultConfiguration = &sarifConfiguration{Level: sarifLevel(info.Severity)}
+	if info.Category != "" {
+		rule.Properties = &sarifRuleProps{Tags: []string{info.Category}}
+	}
+	return rule
+}
+
+// sarifLevel maps a severity to a SARIF level.
+func sarifLevel(severity tt.Severity) string {
+	switch severity {
+	case tt.SeverityError:
+		return "error"
+	case tt.SeverityWarning:
+		return "warning"
+	case tt.SeverityInfo, tt.SeverityHint:
+		return "note"
+	default:
+		return "none"
+	}
+}
+
+func (f *sarifFormat) physicalLocation(filename string, region sarifRegion) sarifPhysicalLocation {
+	return sarifPhysicalLocation{
+		ArtifactLocation: sarifArtifactLocation{URI: f.uri(filename)},
+		Region:           region,
+	}
+}
+
+// uri returns the URI of a file, relative to the root directory when the
+// file is beneath it.
+func (f *sarifFormat) uri(filename string) string {
+	abs, err := filepath.Abs(filename)
+	if err != nil {
+		return filepath.ToSlash(filename)
+	}
+	if root, err := filepath.Abs(f.rootDir); err == nil {
+		if rel, err := filepath.Rel(root, abs); err == nil && !strings.HasPrefix(rel, "..") {
+			return (&url.URL{Path: filepath.ToSlash(rel)}).String()
+		}
+	}
+	path := filepath.ToSlash(abs)
+	if !strings.HasPrefix(path, "/") {
+		// drive letters of Windows paths
+		path = "/" + path
+	}
+	return (&url.URL{Scheme: "file", Path: path}).String()
+}
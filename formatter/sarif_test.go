@@ -0,0 +1,123 @@
+package formatter
+
+import (
+	"bytes"
+	"encoding/json"
+	"go/token"
+	"path/filepath"
+	"testing"
+
+	"github.com/gnolang/tlin/internal/fixer"
+	tt "github.com/gnolang/tlin/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSARIFFormat(t *testing.T) {
+	t.Parallel()
+	root := t.TempDir()
+	filename := filepath.Join(root, "p", "demo", "a.gno")
+	outside := filepath.Join(t.TempDir(), "b.gno")
+
+	issues := []tt.Issue{
+		{
+			Rule:        "useless-break",
+			Filename:    filename,
+			Message:     "useless break statement",
+			Start:       token.Position{Line: 6, Column: 3},
+			End:         token.Position{Line: 6, Column: 8},
+			Severity:    tt.SeverityWarning,
+			Fingerprint: "3f1c",
+		},
+		{
+			Rule:     "errcheck",
+			Filename: outside,
+			Message:  "error return value is not checked",
+			Start:    token.Position{Line: 2, Column: 1},
+			Severity: tt.SeverityError,
+			Related: []tt.Location{
+				{Position: token.Position{Line: 1, Column: 1}, Label: "declared here"},
+			},
+		},
+		{
+			Rule:     "useless-break",
+			Filename: filename,
+			Message:  "useless break statement",
+			Start:    token.Position{Line: 9, Column: 3},
+			End:      token.Position{Line: 9, Column: 8},
+			Severity: tt.SeverityInfo,
+		},
+	}
+	patch := &fixer.Patch{
+		Filename: filename,
+		Edits: []fixer.Edit{{
+			Rule:        "useless-break",
+			IssueID:     issues[0].ID(),
+			StartLine:   6,
+			EndLine:     6,
+			Replacement: "",
+		}},
+	}
+
+	var buf bytes.Buffer
+	format := &sarifFormat{rootDir: root}
+	require.NoError(t, format.Write(&buf, Report{Issues: issues, Fixes: []*fixer.Patch{patch}}))
+
+	var log sarifLog
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &log))
+	assert.Equal(t, "2.1.0", log.Version)
+	require.Len(t, log.Runs, 1)
+	run := log.Runs[0]
+
+	assert.Equal(t, "tlin", run.Tool.Driver.Name)
+	require.Len(t, run.Tool.Driver.Rules, 2)
+	breakRule := run.Tool.Driver.Rules[0]
+	assert.Equal(t, "useless-break", breakRule.ID)
+	require.NotNil(t, breakRule.ShortDescription)
+	assert.NotEmpty(t, breakRule.ShortDescription.Text)
+	assert.NotNil(t, breakRule.DefaultConfiguration)
+	// rules that are not registered are only named
+	assert.Equal(t, sarifRule{ID: "errcheck"}, run.Tool.Driver.Rules[1])
+
+	require.Len(t, run.Results, 3)
+	first := run.Results[0]
+	assert.Equal(t, "useless-break", first.RuleID)
+	assert.Equal(t, 0, first.RuleIndex)
+	assert.Equal(t, "warning", first.Level)
+	assert.Equal(t, "useless break statement", first.Message.Text)
+	assert.Equal(t, "p/demo/a.gno", first.Locations[0].PhysicalLocation.ArtifactLocation.URI)
+	assert.Equal(t, sarifRegion{StartLine: 6, StartColumn: 3, EndLine: 6, EndColumn: 8}, first.Locations[0].PhysicalLocation.Region)
+	assert.Equal(t, map[string]string{fingerprintKey: "3f1c"}, first.PartialFingerprints)
+	require.Len(t, first.Fixes, 1)
+	change := first.Fixes[0].ArtifactChanges[0]
+	assert.Equal(t, "p/demo/a.gno", change.ArtifactLocation.URI)
+	assert.Equal(t, sarifRegion{StartLine: 6, EndLine: 6}, change.Replacements[0].DeletedRegion)
+
+	second := run.Results[1]
+	assert.Equal(t, 1, second.RuleIndex)
+	assert.Equal(t, "error", second.Level)
+	assert.Equal(t, "file://"+filepath.ToSlash(outside), second.Locations[0].PhysicalLocation.ArtifactLocation.URI)
+	// issues without end are a point
+	assert.Equal(t, sarifRegion{StartLine: 2, StartColumn: 1, EndLine: 2, EndColumn: 1}, second.Locations[0].PhysicalLocation.Region)
+	require.Len(t, second.RelatedLocations, 1)
+	assert.Equal(t, "declared here", second.RelatedLocations[0].Message.Text)
+	assert.Equal(t, second.Locations[0].PhysicalLocation.ArtifactLocation, second.RelatedLocations[0].PhysicalLocation.ArtifactLocation)
+	assert.Empty(t, second.Fixes)
+
+	third := run.Results[2]
+	assert.Equal(t, 0, third.RuleIndex)
+	assert.Equal(t, "note", third.Level)
+	assert.Empty(t, third.Fixes)
+}
+
+func TestNewFormat(t *testing.T) {
+	t.Parallel()
+	for _, name := range FormatNames() {
+		format, err := NewFormat(name)
+		require.NoError(t, err, name)
+		assert.NotNil(t, format, name)
+	}
+
+	_, err := NewFormat("xml")
+	assert.ErrorContains(t, err, `unknown output format "xml"`)
+}
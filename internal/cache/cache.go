@@ -0,0 +1,131 @@
+// Package cache keeps the issues the rules reported for a file, so that the
+// files unchanged since a previous run are not checked again.
+//
+// Entries stay on the local machine, in the user cache directory, one file
+// per key. Keys are derived from everything the issues depend on, the
+// running executable included, so entries are never invalidated: they are
+// no longer looked up once a file, its configuration or tlin changes, until
+// Clean removes them.
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/gob"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	tt "github.com/gnolang/tlin/internal/types"
+)
+
+// formatVersion changes whenever the layout of the entries does.
+const formatVersion = 1
+
+// Cache stores the issues of the rules for a file by key.
+type Cache struct {
+	dir string
+	// salt is mixed into every key, see Open.
+	salt []byte
+}
+
+// entry is the content of an entry file.
+type entry struct {
+	Issues map[string][]tt.Issue
+}
+
+// DefaultDir returns the directory of the cache in the user cache directory.
+func DefaultDir() (string, error) {
+	cache, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(cache, "tlin", "results"), nil
+}
+
+// Open returns the cache stored in dir. The keys are salted with a hash of
+// the running executable, so that a rebuilt tlin, whose rules may report
+// other issues, never reuses the entries of another build.
+func Open(dir string) (*Cache, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return nil, fmt.Errorf("error locating the executable: %w", err)
+	}
+	f, err := os.Open(exe)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	fmt.Fprintf(h, "tlin cache %d\x00", formatVersion)
+	if _, err := io.Copy(h, f); err != nil {
+		return nil, err
+	}
+	return &Cache{dir: dir, salt: h.Sum(nil)}, nil
+}
+
+// Key derives the key of an entry from the data its issues depend on.
+func (c *Cache) Key(parts ...[]byte) string {
+	h := sha256.New()
+	h.Write(c.salt)
+	for _, part := range parts {
+		// the length keeps parts from running into each other
+		var size [8]byte
+		binary.LittleEndian.PutUint64(size[:], uint64(len(part)))
+		h.Write(size[:])
+		h.Write(part)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func (c *Cache) path(key string) string {
+	return filepath.Join(c.dir, key[:2], key)
+}
+
+// Get returns the issues stored under the key, by rule. Missing and
+// unreadable entries are both misses.
+func (c *Cache) Get(key string) (map[string][]tt.Issue, bool) {
+	f, err := os.Open(c.path(key))
+	if err != nil {
+		return nil, false
+	}
+	defer f.Close()
+
+	var e entry
+	if err := gob.NewDecoder(f).Decode(&e); err != nil {
+		return nil, false
+	}
+	return e.Issues, true
+}
+
+// Put stores the issues of the rules under the key. The entry is replaced
+// at once, so that concurrent runs never read a partial one.
+func (c *Cache) Put(key string, issues map[string][]tt.Issue) error {
+	path := c.path(key)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), "entry-*.tmp")
+	if err != nil {
+		return err
+	}
+	if err := gob.NewEncoder(tmp).Encode(entry{Issues: issues}); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// Clean removes the cache stored in dir.
+func Clean(dir string) error {
+	return os.RemoveAll(dir)
+}
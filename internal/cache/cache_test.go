@@ -0,0 +1,54 @@
+package cache
+
+import (
+	"go/token"
+	"os"
+	"path/filepath"
+	"testing"
+
+	tt "github.com/gnolang/tlin/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCache(t *testing.T) {
+	t.Parallel()
+	dir := filepath.Join(t.TempDir(), "results")
+
+	c, err := Open(dir)
+	require.NoError(t, err)
+
+	key := c.Key([]byte("a.gno"), []byte("package a"))
+	assert.NotEqual(t, key, c.Key([]byte("a.gnopackage a")), "parts are delimited")
+	assert.NotEqual(t, key, c.Key([]byte("a.gno"), []byte("package b")))
+
+	_, ok := c.Get(key)
+	assert.False(t, ok)
+
+	issues := map[string][]tt.Issue{
+		"useless-break": {{
+			Rule:        "useless-break",
+			Filename:    "a.gno",
+			Message:     "useless break statement",
+			Start:       token.Position{Filename: "a.gno", Line: 6, Column: 3},
+			Severity:    tt.SeverityWarning,
+			MessageArgs: map[string]string{"kind": "switch"},
+		}},
+		// rules without issues are kept, to be told from rules not run
+		"emit-format": nil,
+	}
+	require.NoError(t, c.Put(key, issues))
+
+	got, ok := c.Get(key)
+	require.True(t, ok)
+	assert.Equal(t, issues["useless-break"], got["useless-break"])
+	assert.Contains(t, got, "emit-format")
+
+	// corrupted entries are misses
+	require.NoError(t, os.WriteFile(c.path(key), []byte("garbage"), 0o644))
+	_, ok = c.Get(key)
+	assert.False(t, ok)
+
+	require.NoError(t, Clean(dir))
+	assert.NoDirExists(t, dir)
+}
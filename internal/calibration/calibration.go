@@ -0,0 +1,339 @@
+// Package calibration records, per rule, how many of the reported issues end
+// up suppressed, so that rules a team keeps silencing can be toned down.
+//
+// The history stays on the local machine, in the user cache directory, with
+// one file per repository named after a hash of its path.
+package calibration
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	tt "github.com/gnolang/tlin/internal/types"
+)
+
+// DefaultMaxRuns bounds the number of runs kept per repository.
+const DefaultMaxRuns = 200
+
+// Counts is the number of issues a rule found and how many of them were
+// suppressed.
+type Counts struct {
+	Found      int `json:"found"`
+	Suppressed int `json:"suppressed"`
+}
+
+// Run is the per-rule counts of a single lint run.
+type Run struct {
+	Time  time.Time         `json:"time"`
+	Rules map[string]Counts `json:"rules"`
+}
+
+// History is the runs recorded for a repository, oldest first.
+type History struct {
+	Repo string `json:"repo"`
+	Runs []Run  `json:"runs"`
+}
+
+// Counter gathers counts from concurrent rule runs.
+// A nil counter ignores everything.
+type Counter struct {
+	mu     sync.Mutex
+	counts map[string]Counts
+}
+
+// NewCounter creates an empty counter.
+func NewCounter() *Counter {
+	return &Counter{counts: make(map[string]Counts)}
+}
+
+// Add counts the issues found on a file and those left once suppressions
+// were applied.
+func (c *Counter) Add(found, kept []tt.Issue) {
+	if c == nil || len(found) == 0 {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	// the suppressed issues are the found ones that were not kept
+	for _, issue := range found {
+		counts := c.counts[issue.Rule]
+		counts.Found++
+		counts.Suppressed++
+		c.counts[issue.Rule] = counts
+	}
+	for _, issue := range kept {
+		counts := c.counts[issue.Rule]
+		counts.Suppressed--
+		c.counts[issue.Rule] = counts
+	}
+}
+
+// AddSuppressed counts the issues suppressed after they were added, that is
+// the issues missing from kept.
+func (c *Counter) AddSuppressed(issues, kept []tt.Issue) {
+	if c == nil || len(issues) == len(kept) {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, issue := range issues {
+		counts := c.counts[issue.Rule]
+		counts.Suppressed++
+		c.counts[issue.Rule] = counts
+	}
+	for _, issue := range kept {
+		counts := c.counts[issue.Rule]
+		counts.Suppressed--
+		c.counts[issue.Rule] = counts
+	}
+}
+
+// Counts returns a copy of the counts gathered so far.
+func (c *Counter) Counts() map[string]Counts {
+	if c == nil {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	counts := make(map[string]Counts, len(c.counts))
+	for rule, count := range c.counts {
+		counts[rule] = count
+	}
+	return counts
+}
+
+// Store keeps the histories of the repositories in a directory.
+type Store struct {
+	Dir string
+	// MaxRuns bounds the runs kept per repository, oldest runs being
+	// dropped first. Values below 1 mean DefaultMaxRuns.
+	MaxRuns int
+}
+
+// DefaultDir returns the directory of the histories in the user cache directory.
+func DefaultDir() (string, error) {
+	cache, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(cache, "tlin", "calibration"), nil
+}
+
+// Path returns the file holding the history of the repository at the given path.
+func (s Store) Path(repo string) (string, error) {
+	abs, err := filepath.Abs(repo)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256([]byte(filepath.ToSlash(abs)))
+	return filepath.Join(s.Dir, hex.EncodeToString(sum[:])[:16]+".json"), nil
+}
+
+// Load reads the history of a repository. A repository without any recorded
+// run has an empty history.
+func (s Store) Load(repo string) (History, error) {
+	abs, err := filepath.Abs(repo)
+	if err != nil {
+		return History{}, err
+	}
+	path, err := s.Path(repo)
+	if err != nil {
+		return History{}, err
+	}
+
+	history := History{Repo: abs}
+	content, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return history, nil
+	}
+	if err != nil {
+		return History{}, err
+	}
+	if err := json.Unmarshal(content, &history); err != nil {
+		return History{}, fmt.Errorf("error reading calibration history %s: %w", path, err)
+	}
+	return history, nil
+}
+
+// Record appends a run to the history of a repository.
+func (s Store) Record(repo string, run Run) error {
+	history, err := s.Load(repo)
+	if err != nil {
+		return err
+	}
+
+	// rules without any issue carry no information
+	rules := make(map[string]Counts, len(run.Rules))
+	for rule, counts := range run.Rules {
+		if counts.Found > 0 {
+			rules[rule] = counts
+		}
+	}
+	run.Rules = rules
+
+	history.Runs = append(history.Runs, run)
+	maxRuns := s.MaxRuns
+	if maxRuns < 1 {
+		maxRuns = DefaultMaxRuns
+	}
+	if len(history.Runs) > maxRuns {
+		history.Runs = history.Runs[len(history.Runs)-maxRuns:]
+	}
+
+	return s.write(repo, history)
+}
+
+// Reset deletes the history of a repository.
+func (s Store) Reset(repo string) error {
+	path, err := s.Path(repo)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// write replaces the history file at once, so that concurrent runs never
+// read a partial file.
+func (s Store) write(repo string, history History) error {
+	path, err := s.Path(repo)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(s.Dir, 0o755); err != nil {
+		return err
+	}
+
+	content, err := json.Marshal(history)
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(s.Dir, "history-*.tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(content); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// RuleStats sums the counts of a rule over a history.
+type RuleStats struct {
+	Rule       string  `json:"rule"`
+	Runs       int     `json:"runs"` // runs in which the rule found issues
+	Found      int     `json:"found"`
+	Suppressed int     `json:"suppressed"`
+	Ratio      float64 `json:"ratio"` // suppressed out of found
+}
+
+// Aggregate sums the counts of every rule over the history, ordered from the
+// most suppressed rule to the least.
+func Aggregate(history History) []RuleStats {
+	byRule := make(map[string]*RuleStats)
+	for _, run := range history.Runs {
+		for rule, counts := range run.Rules {
+			if counts.Found == 0 {
+				continue
+			}
+			stats, ok := byRule[rule]
+			if !ok {
+				stats = &RuleStats{Rule: rule}
+				byRule[rule] = stats
+			}
+			stats.Runs++
+			stats.Found += counts.Found
+			stats.Suppressed += counts.Suppressed
+		}
+	}
+
+	all := make([]RuleStats, 0, len(byRule))
+	for _, stats := range byRule {
+		stats.Ratio = float64(stats.Suppressed) / float64(stats.Found)
+		all = append(all, *stats)
+	}
+	sort.Slice(all, func(i, j int) bool {
+		if all[i].Ratio != all[j].Ratio {
+			return all[i].Ratio > all[j].Ratio
+		}
+		if all[i].Found != all[j].Found {
+			return all[i].Found > all[j].Found
+		}
+		return all[i].Rule < all[j].Rule
+	})
+	return all
+}
+
+// Thresholds decide which rules deserve a configuration change.
+type Thresholds struct {
+	// MinFound is the number of issues below which a rule is not judged.
+	MinFound int
+	// Lower is the suppression ratio from which the severity is lowered.
+	Lower float64
+	// Disable is the suppression ratio from which the rule is turned off.
+	Disable float64
+}
+
+// DefaultThresholds are the thresholds of the report command.
+var DefaultThresholds = Thresholds{MinFound: 10, Lower: 0.5, Disable: 0.9}
+
+// Suggestion is a severity change for a rule that is often suppressed.
+type Suggestion struct {
+	RuleStats
+	Current   tt.Severity `json:"current"`
+	Suggested tt.Severity `json:"suggested"`
+}
+
+// Suggest proposes severity changes for the rules suppressed more often than
+// the thresholds allow. Rules missing from current or already off are skipped.
+func Suggest(stats []RuleStats, current map[string]tt.Severity, thresholds Thresholds) []Suggestion {
+	var suggestions []Suggestion
+	for _, s := range stats {
+		severity, ok := current[s.Rule]
+		if !ok || severity == tt.SeverityOff || s.Found < thresholds.MinFound || s.Ratio < thresholds.Lower {
+			continue
+		}
+
+		suggested := severity + 1 // severities go from error to off
+		if s.Ratio >= thresholds.Disable {
+			suggested = tt.SeverityOff
+		}
+		suggestions = append(suggestions, Suggestion{
+			RuleStats: s,
+			Current:   severity,
+			Suggested: suggested,
+		})
+	}
+	return suggestions
+}
+
+// ConfigSnippet renders the suggestions as a `rules` section of the
+// configuration file.
+func ConfigSnippet(suggestions []Suggestion) string {
+	if len(suggestions) == 0 {
+		return ""
+	}
+	snippet := "rules:\n"
+	for _, s := range suggestions {
+		snippet += fmt.Sprintf("  # %d of %d issues suppressed (%.0f%%), was %s\n", s.Suppressed, s.Found, s.Ratio*100, s.Current)
+		snippet += fmt.Sprintf("  %s:\n    severity: %s\n", s.Rule, s.Suggested)
+	}
+	return snippet
+}
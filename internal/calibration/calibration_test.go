@@ -0,0 +1,190 @@
+package calibration
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	tt "github.com/gnolang/tlin/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCounter(t *testing.T) {
+	t.Parallel()
+
+	c := NewCounter()
+	found := []tt.Issue{{Rule: "a"}, {Rule: "a"}, {Rule: "a"}, {Rule: "b"}}
+	kept := []tt.Issue{{Rule: "a"}, {Rule: "b"}}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			c.Add(found, kept)
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, map[string]Counts{
+		"a": {Found: 12, Suppressed: 8},
+		"b": {Found: 4, Suppressed: 0},
+	}, c.Counts())
+
+	var disabled *Counter
+	disabled.Add(found, kept)
+	assert.Nil(t, disabled.Counts())
+}
+
+func TestStore(t *testing.T) {
+	t.Parallel()
+
+	store := Store{Dir: filepath.Join(t.TempDir(), "calibration"), MaxRuns: 3}
+	repo := t.TempDir()
+	other := t.TempDir()
+
+	history, err := store.Load(repo)
+	require.NoError(t, err)
+	assert.Empty(t, history.Runs)
+
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < 5; i++ {
+		require.NoError(t, store.Record(repo, Run{
+			Time: start.Add(time.Duration(i) * time.Hour),
+			Rules: map[string]Counts{
+				"a":     {Found: i + 1, Suppressed: i},
+				"quiet": {},
+			},
+		}))
+	}
+	require.NoError(t, store.Record(other, Run{Time: start, Rules: map[string]Counts{"a": {Found: 1}}}))
+
+	history, err = store.Load(repo)
+	require.NoError(t, err)
+	abs, err := filepath.Abs(repo)
+	require.NoError(t, err)
+	assert.Equal(t, abs, history.Repo)
+
+	// only the last runs are kept, without the rules that found nothing
+	require.Len(t, history.Runs, 3)
+	assert.True(t, start.Add(2*time.Hour).Equal(history.Runs[0].Time))
+	assert.Equal(t, map[string]Counts{"a": {Found: 5, Suppressed: 4}}, history.Runs[2].Rules)
+
+	// repositories are kept apart
+	otherHistory, err := store.Load(other)
+	require.NoError(t, err)
+	assert.Len(t, otherHistory.Runs, 1)
+
+	// files are named after a hash of the repository path
+	path, err := store.Path(repo)
+	require.NoError(t, err)
+	assert.NotContains(t, path, filepath.Base(repo))
+	_, err = os.Stat(path)
+	require.NoError(t, err)
+
+	require.NoError(t, store.Reset(repo))
+	require.NoError(t, store.Reset(repo), "resetting twice is fine")
+	history, err = store.Load(repo)
+	require.NoError(t, err)
+	assert.Empty(t, history.Runs)
+
+	otherHistory, err = store.Load(other)
+	require.NoError(t, err)
+	assert.Len(t, otherHistory.Runs, 1)
+}
+
+func TestStoreCorruptHistory(t *testing.T) {
+	t.Parallel()
+
+	store := Store{Dir: t.TempDir()}
+	path, err := store.Path("repo")
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(path, []byte("{"), 0o644))
+
+	_, err = store.Load("repo")
+	assert.Error(t, err)
+}
+
+// syntheticHistory builds runs from per-rule (found, suppressed) pairs.
+func syntheticHistory(runs ...map[string][2]int) History {
+	history := History{Repo: "/repo"}
+	for _, run := range runs {
+		rules := make(map[string]Counts, len(run))
+		for rule, counts := range run {
+			rules[rule] = Counts{Found: counts[0], Suppressed: counts[1]}
+		}
+		history.Runs = append(history.Runs, Run{Rules: rules})
+	}
+	return history
+}
+
+func TestAggregate(t *testing.T) {
+	t.Parallel()
+
+	history := syntheticHistory(
+		map[string][2]int{"noisy": {10, 9}, "useful": {4, 0}, "mixed": {6, 3}},
+		map[string][2]int{"noisy": {10, 10}, "mixed": {2, 1}},
+		map[string][2]int{"useful": {6, 1}, "mixed": {0, 0}},
+		map[string][2]int{"rare": {1, 1}},
+	)
+
+	stats := Aggregate(history)
+	assert.Equal(t, []RuleStats{
+		{Rule: "rare", Runs: 1, Found: 1, Suppressed: 1, Ratio: 1},
+		{Rule: "noisy", Runs: 2, Found: 20, Suppressed: 19, Ratio: 0.95},
+		{Rule: "mixed", Runs: 2, Found: 8, Suppressed: 4, Ratio: 0.5},
+		{Rule: "useful", Runs: 2, Found: 10, Suppressed: 1, Ratio: 0.1},
+	}, stats)
+
+	assert.Empty(t, Aggregate(History{}))
+}
+
+func TestSuggest(t *testing.T) {
+	t.Parallel()
+
+	stats := []RuleStats{
+		{Rule: "always", Found: 50, Suppressed: 48, Ratio: 0.96},
+		{Rule: "often", Found: 20, Suppressed: 12, Ratio: 0.6},
+		{Rule: "often-info", Found: 20, Suppressed: 12, Ratio: 0.6},
+		{Rule: "often-hint", Found: 20, Suppressed: 12, Ratio: 0.6},
+		{Rule: "rare", Found: 5, Suppressed: 5, Ratio: 1},
+		{Rule: "off", Found: 30, Suppressed: 30, Ratio: 1},
+		{Rule: "fine", Found: 40, Suppressed: 4, Ratio: 0.1},
+		{Rule: "unknown", Found: 40, Suppressed: 40, Ratio: 1},
+	}
+	current := map[string]tt.Severity{
+		"always":     tt.SeverityWarning,
+		"often":      tt.SeverityError,
+		"often-info": tt.SeverityInfo,
+		"often-hint": tt.SeverityHint,
+		"rare":       tt.SeverityError,
+		"off":        tt.SeverityOff,
+		"fine":       tt.SeverityError,
+	}
+
+	suggestions := Suggest(stats, current, DefaultThresholds)
+	require.Len(t, suggestions, 4)
+	assert.Equal(t, Suggestion{RuleStats: stats[0], Current: tt.SeverityWarning, Suggested: tt.SeverityOff}, suggestions[0])
+	assert.Equal(t, Suggestion{RuleStats: stats[1], Current: tt.SeverityError, Suggested: tt.SeverityWarning}, suggestions[1])
+	assert.Equal(t, Suggestion{RuleStats: stats[2], Current: tt.SeverityInfo, Suggested: tt.SeverityHint}, suggestions[2])
+	assert.Equal(t, Suggestion{RuleStats: stats[3], Current: tt.SeverityHint, Suggested: tt.SeverityOff}, suggestions[3])
+
+	assert.Equal(t, `rules:
+  # 48 of 50 issues suppressed (96%), was WARNING
+  always:
+    severity: OFF
+  # 12 of 20 issues suppressed (60%), was ERROR
+  often:
+    severity: WARNING
+  # 12 of 20 issues suppressed (60%), was INFO
+  often-info:
+    severity: HINT
+  # 12 of 20 issues suppressed (60%), was HINT
+  often-hint:
+    severity: OFF
+`, ConfigSnippet(suggestions))
+	assert.Empty(t, ConfigSnippet(nil))
+}
@@ -0,0 +1,447 @@
+package internal
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gnolang/tlin/internal/stdapi"
+	tt "github.com/gnolang/tlin/internal/types"
+	"gopkg.in/yaml.v3"
+)
+
+// ConfigError describes a problem found while validating a configuration file.
+type ConfigError struct {
+	Line    int
+	Message string
+}
+
+func (e *ConfigError) Error() string {
+	return fmt.Sprintf("line %d: %s", e.Line, e.Message)
+}
+
+var (
+	configKeys     = []string{"name", "preset", "rules", "concurrency", "timeout", "file-timeout", "rule-timeout", "memory-limit", "messages", "rule-packs", "gno-version", "exclude", "fail-on"}
+	ruleConfigKeys = []string{"severity", "data"}
+	severityNames  = []string{"ERROR", "WARNING", "INFO", "HINT", "OFF"}
+)
+
+// ValidateConfig strictly validates the content of a configuration file.
+// It rejects unknown top-level keys, unknown rule names, invalid severities
+// and option values of the wrong type. Every problem is reported with the
+// line it was found on.
+func ValidateConfig(content []byte) error {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(content, &doc); err != nil {
+		return err
+	}
+	if len(doc.Content) == 0 {
+		return nil
+	}
+
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return &ConfigError{Line: root.Line, Message: "configuration must be a mapping"}
+	}
+
+	var errs []error
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		key, value := root.Content[i], root.Content[i+1]
+		switch key.Value {
+		case "name":
+		case "preset":
+			if _, ok := presets[value.Value]; value.Kind != yaml.ScalarNode || !ok {
+				errs = append(errs, &ConfigError{Line: value.Line, Message: unknownPresetError(value.Value).Error()})
+			}
+		case "rules":
+			errs = append(errs, validateRules(value)...)
+		case "messages":
+			if value.Kind != yaml.ScalarNode || value.Value == "" {
+				errs = append(errs, &ConfigError{Line: value.Line, Message: "messages must be the path of a message catalog"})
+			}
+		case "rule-packs":
+			if !matchesOptionType(value, tt.OptionStringList) {
+				errs = append(errs, &ConfigError{Line: value.Line, Message: "rule-packs must be a list of rule pack paths"})
+			}
+		case "gno-version":
+			if value.Kind != yaml.ScalarNode || value.Value == "" {
+				errs = append(errs, &ConfigError{Line: value.Line, Message: "gno-version must be a version such as " + stdapi.Latest().Version})
+			}
+		case "exclude":
+			errs = append(errs, validateExcludes(value)...)
+		case "fail-on":
+			if severity, err := tt.ParseSeverity(value.Value); value.Kind != yaml.ScalarNode || err != nil || severity == tt.SeverityOff {
+				errs = append(errs, &ConfigError{Line: value.Line, Message: fmt.Sprintf("invalid fail-on severity %q (expected one of ERROR, WARNING, INFO, HINT)", value.Value)})
+			}
+		case "concurrency", "memory-limit":
+			if !matchesOptionType(value, tt.OptionInt) {
+				errs = append(errs, &ConfigError{Line: value.Line, Message: fmt.Sprintf("%s must be an integer", key.Value)})
+			}
+		case "timeout", "file-timeout", "rule-timeout":
+			if _, err := time.ParseDuration(value.Value); value.Kind != yaml.ScalarNode || err != nil {
+				errs = append(errs, &ConfigError{Line: value.Line, Message: fmt.Sprintf("%s must be a duration such as 30s or 5m", key.Value)})
+			}
+		default:
+			errs = append(errs, unknownKeyError(key, "top-level key", configKeys))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// validateExcludes checks that the excludes are a list of valid globs.
+func validateExcludes(node *yaml.Node) []error {
+	if isNull(node) {
+		return nil
+	}
+	if !matchesOptionType(node, tt.OptionStringList) {
+		return []error{&ConfigError{Line: node.Line, Message: "exclude must be a list of path globs"}}
+	}
+	var errs []error
+	for _, item := range node.Content {
+		if !validGlob(normalizeSlashes(item.Value)) {
+			errs = append(errs, &ConfigError{Line: item.Line, Message: fmt.Sprintf("invalid exclude glob %q", item.Value)})
+		}
+	}
+	return errs
+}
+
+func validateRules(node *yaml.Node) []error {
+	if isNull(node) {
+		return nil
+	}
+	if node.Kind != yaml.MappingNode {
+		return []error{&ConfigError{Line: node.Line, Message: "rules must be a mapping of rule names"}}
+	}
+
+	var errs []error
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+		rule, ok := allRules.lookup(key.Value)
+		if !ok {
+			errs = append(errs, unknownKeyError(key, "rule", sortedRuleNames()))
+			continue
+		}
+		errs = append(errs, validateRule(rule, value)...)
+	}
+	return errs
+}
+
+func validateRule(rule LintRule, node *yaml.Node) []error {
+	if isNull(node) {
+		return nil
+	}
+	if node.Kind != yaml.MappingNode {
+		return []error{&ConfigError{Line: node.Line, Message: "rule configuration must be a mapping"}}
+	}
+
+	var errs []error
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+		switch key.Value {
+		case "severity":
+			if value.Kind != yaml.ScalarNode || !contains(severityNames, value.Value) {
+				errs = append(errs, &ConfigError{
+					Line:    value.Line,
+					Message: fmt.Sprintf("invalid severity %q (expected one of %s)", value.Value, strings.Join(severityNames, ", ")),
+				})
+			}
+		case "data":
+			errs = append(errs, validateOptions(rule, value)...)
+		default:
+			errs = append(errs, unknownKeyError(key, "rule key", ruleConfigKeys))
+		}
+	}
+	return errs
+}
+
+func validateOptions(rule LintRule, node *yaml.Node) []error {
+	if isNull(node) {
+		return nil
+	}
+	if node.Kind != yaml.MappingNode {
+		return []error{&ConfigError{Line: node.Line, Message: "rule data must be a mapping of options"}}
+	}
+
+	names := make([]string, 0, len(rule.options))
+	for _, opt := range rule.options {
+		names = append(names, opt.Name)
+	}
+
+	var errs []error
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+		opt, ok := findOption(rule.options, key.Value)
+		if !ok {
+			errs = append(errs, unknownKeyError(key, "option", names))
+			continue
+		}
+		if !matchesOptionType(value, opt.Type) {
+			errs = append(errs, &ConfigError{
+				Line:    value.Line,
+				Message: fmt.Sprintf("option %q must be of type %s", opt.Name, opt.Type),
+			})
+			continue
+		}
+		if opt.Type == tt.OptionInt || opt.Type == tt.OptionFloat {
+			if n, err := strconv.ParseFloat(value.Value, 64); err == nil {
+				if err := opt.CheckRange(n); err != nil {
+					errs = append(errs, &ConfigError{
+						Line:    value.Line,
+						Message: fmt.Sprintf("option %q %v, got %s", opt.Name, err, value.Value),
+					})
+				}
+			}
+		}
+		if opt.Validate == nil {
+			continue
+		}
+		values := []*yaml.Node{value}
+		if opt.Type == tt.OptionStringList {
+			values = value.Content
+		}
+		for _, v := range values {
+			if err := opt.Validate(v.Value); err != nil {
+				errs = append(errs, &ConfigError{
+					Line:    v.Line,
+					Message: fmt.Sprintf("invalid value for option %q: %v", opt.Name, err),
+				})
+			}
+		}
+	}
+	return errs
+}
+
+func matchesOptionType(node *yaml.Node, typ tt.OptionType) bool {
+	switch typ {
+	case tt.OptionInt:
+		return node.Kind == yaml.ScalarNode && node.Tag == "!!int"
+	case tt.OptionFloat:
+		return node.Kind == yaml.ScalarNode && (node.Tag == "!!float" || node.Tag == "!!int")
+	case tt.OptionBool:
+		return node.Kind == yaml.ScalarNode && node.Tag == "!!bool"
+	case tt.OptionString:
+		return node.Kind == yaml.ScalarNode && node.Tag == "!!str"
+	case tt.OptionStringList:
+		if node.Kind != yaml.SequenceNode {
+			return false
+		}
+		for _, item := range node.Content {
+			if item.Kind != yaml.ScalarNode || item.Tag != "!!str" {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+func unknownKeyError(key *yaml.Node, kind string, valid []string) error {
+	msg := fmt.Sprintf("unknown %s %q", kind, key.Value)
+	if len(valid) > 0 {
+		msg += fmt.Sprintf(" (expected one of %s)", strings.Join(valid, ", "))
+	}
+	return &ConfigError{Line: key.Line, Message: msg}
+}
+
+func isNull(node *yaml.Node) bool {
+	return node.Kind == yaml.ScalarNode && node.Tag == "!!null"
+}
+
+func findOption(options []tt.RuleOption, name string) (tt.RuleOption, bool) {
+	for _, opt := range options {
+		if opt.Name == name {
+			return opt, true
+		}
+	}
+	return tt.RuleOption{}, false
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func sortedRuleNames() []string {
+	return allRules.names()
+}
+
+// withOptions returns a copy of the rule whose check function is built from
+// the given configuration data merged over the option defaults.
+func (r LintRule) withOptions(data interface{}) LintRule {
+	if r.configure != nil {
+		r.check = r.configure(resolveOptions(r.options, data))
+	}
+	if r.configurePackage != nil {
+		r.checkPackage = r.configurePackage(resolveOptions(r.options, data))
+	}
+	return r
+}
+
+// resolveOptions merges the user supplied option values over the declared defaults.
+// Values that were not validated beforehand and do not match the declared type
+// or range are ignored.
+func resolveOptions(options []tt.RuleOption, data interface{}) map[string]interface{} {
+	values := make(map[string]interface{}, len(options))
+	for _, opt := range options {
+		values[opt.Name] = opt.Default
+	}
+
+	userValues, _ := data.(map[string]interface{})
+	for _, opt := range options {
+		raw, ok := userValues[opt.Name]
+		if !ok {
+			continue
+		}
+		v, ok := convertOption(raw, opt.Type)
+		if !ok {
+			continue
+		}
+		switch n := v.(type) {
+		case int:
+			ok = opt.CheckRange(float64(n)) == nil
+		case float64:
+			ok = opt.CheckRange(n) == nil
+		}
+		if ok {
+			values[opt.Name] = v
+		}
+	}
+	return values
+}
+
+func convertOption(raw interface{}, typ tt.OptionType) (interface{}, bool) {
+	switch typ {
+	case tt.OptionInt:
+		v, ok := raw.(int)
+		return v, ok
+	case tt.OptionFloat:
+		switch v := raw.(type) {
+		case float64:
+			return v, true
+		case int:
+			return float64(v), true
+		}
+	case tt.OptionBool:
+		v, ok := raw.(bool)
+		return v, ok
+	case tt.OptionString:
+		v, ok := raw.(string)
+		return v, ok
+	case tt.OptionStringList:
+		items, ok := raw.([]interface{})
+		if !ok {
+			return nil, false
+		}
+		list := make([]string, 0, len(items))
+		for _, item := range items {
+			s, ok := item.(string)
+			if !ok {
+				return nil, false
+			}
+			list = append(list, s)
+		}
+		return list, true
+	}
+	return nil, false
+}
+
+// FindConfigFile looks for the configuration file in dir, then in its
+// parents up to the root of the project, the first directory holding a .git
+// entry. The path is relative when dir is, and empty when there is no file.
+func FindConfigFile(dir string) (string, error) {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+	for {
+		candidate := filepath.Join(dir, localConfigName)
+		if info, err := os.Stat(candidate); err == nil && !info.IsDir() {
+			return candidate, nil
+		}
+		if _, err := os.Stat(filepath.Join(abs, ".git")); err == nil {
+			return "", nil
+		}
+		parent := filepath.Dir(abs)
+		if parent == abs {
+			return "", nil
+		}
+		abs, dir = parent, filepath.Join(dir, "..")
+	}
+}
+
+// GenerateDefaultConfig renders a commented configuration file listing every
+// registered rule with its default severity and options.
+func GenerateDefaultConfig() []byte {
+	var sb strings.Builder
+	sb.WriteString("# tlin configuration file.\n")
+	sb.WriteString("#\n")
+	sb.WriteString("# Each entry under `rules` configures a single lint rule.\n")
+	sb.WriteString("# Available severities: " + strings.Join(severityNames, ", ") + ".\n")
+	sb.WriteString("# Rule options are set under the `data` key of the rule.\n")
+	sb.WriteString("#\n")
+	sb.WriteString("# Uncomment `preset` to start from a curated rule set instead of the\n")
+	sb.WriteString("# defaults below; rules listed under `rules` override the preset.\n")
+	sb.WriteString("# Available presets: " + strings.Join(PresetNames(), ", ") + ".\n")
+	sb.WriteString("#\n")
+	sb.WriteString("# Uncomment `messages` to translate the issue messages with a catalog\n")
+	sb.WriteString("# mapping message IDs to templates, relative to this file.\n")
+	sb.WriteString("# Uncomment `gno-version` to check the std API calls against a gno\n")
+	sb.WriteString("# version (" + strings.Join(stdapi.Versions(), ", ") + "), the newest by default.\n")
+	sb.WriteString("# Uncomment `exclude` to leave the files matching globs relative to this\n")
+	sb.WriteString("# file out of the run, `**` matching any number of directories.\n")
+	sb.WriteString("# Uncomment `fail-on` to only fail the run on issues of a severity or\n")
+	sb.WriteString("# more; every issue fails it by default.\n")
+	sb.WriteString("name: tlin\n")
+	sb.WriteString("# preset: recommended\n")
+	sb.WriteString("# messages: messages.ko.yaml\n")
+	sb.WriteString("# gno-version: \"" + stdapi.Latest().Version + "\"\n")
+	sb.WriteString("# exclude:\n")
+	sb.WriteString("#   - \"**/testdata/**\"\n")
+	sb.WriteString("# fail-on: WARNING\n")
+	sb.WriteString("rules:\n")
+
+	for _, name := range sortedRuleNames() {
+		rule, _ := allRules.lookup(name)
+		if rule.description != "" {
+			fmt.Fprintf(&sb, "  # %s\n", rule.description)
+		}
+		fmt.Fprintf(&sb, "  %s:\n", name)
+		fmt.Fprintf(&sb, "    severity: %s\n", rule.severity)
+		if len(rule.options) == 0 {
+			continue
+		}
+		sb.WriteString("    data:\n")
+		for _, opt := range rule.options {
+			if opt.Description != "" {
+				fmt.Fprintf(&sb, "      # %s (%s)\n", opt.Description, opt.Constraint())
+			}
+			fmt.Fprintf(&sb, "      %s: %s\n", opt.Name, formatOptionValue(opt.Default))
+		}
+	}
+
+	return []byte(sb.String())
+}
+
+func formatOptionValue(v interface{}) string {
+	switch v := v.(type) {
+	case string:
+		return strconv.Quote(v)
+	case []string:
+		quoted := make([]string, 0, len(v))
+		for _, s := range v {
+			quoted = append(quoted, strconv.Quote(s))
+		}
+		return "[" + strings.Join(quoted, ", ") + "]"
+	default:
+		return fmt.Sprint(v)
+	}
+}
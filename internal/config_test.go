@@ -0,0 +1,282 @@
+package internal
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestValidateConfig(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		content string
+		errors  []string
+	}{
+		{
+			name:    "empty",
+			content: "",
+		},
+		{
+			name: "valid",
+			content: `name: tlin
+rules:
+  useless-break:
+    severity: WARNING
+  high-cyclomatic-complexity:
+    severity: ERROR
+    data:
+      threshold: 15
+`,
+		},
+		{
+			name: "engine settings",
+			content: `concurrency: 4
+timeout: 10m
+file-timeout: 30s
+memory-limit: 2048
+`,
+		},
+		{
+			name: "invalid engine settings",
+			content: `concurrency: many
+file-timeout: 30
+`,
+			errors: []string{
+				"line 1: concurrency must be an integer",
+				"line 2: file-timeout must be a duration such as 30s or 5m",
+			},
+		},
+		{
+			name: "unknown top-level key",
+			content: `name: tlin
+rule:
+  useless-break:
+    severity: WARNING
+`,
+			errors: []string{`line 2: unknown top-level key "rule"`},
+		},
+		{
+			name: "unknown rule",
+			content: `rules:
+  useles-break:
+    severity: WARNING
+`,
+			errors: []string{`line 2: unknown rule "useles-break"`},
+		},
+		{
+			name: "invalid severity",
+			content: `rules:
+  useless-break:
+    severity: FATAL
+`,
+			errors: []string{`line 3: invalid severity "FATAL"`},
+		},
+		{
+			name: "unknown option",
+			content: `rules:
+  high-cyclomatic-complexity:
+    data:
+      thresold: 15
+`,
+			errors: []string{`line 4: unknown option "thresold" (expected one of threshold)`},
+		},
+		{
+			name: "wrong option type",
+			content: `rules:
+  high-cyclomatic-complexity:
+    data:
+      threshold: high
+`,
+			errors: []string{`line 4: option "threshold" must be of type int`},
+		},
+		{
+			name: "invalid option value",
+			content: `rules:
+  call-protocol:
+    data:
+      prerequisites:
+        - "Set* -> AssertCallerIsOwner"
+        - "Set* AssertCallerIsOwner"
+      pairs:
+        - "Pause <-> Pause"
+`,
+			errors: []string{
+				`line 6: invalid value for option "prerequisites": missing -> in "Set* AssertCallerIsOwner"`,
+				`line 8: invalid value for option "pairs": "Pause <-> Pause" pairs a call with itself`,
+			},
+		},
+		{
+			name:    "message catalog",
+			content: "messages: messages.ko.yaml\n",
+		},
+		{
+			name:    "invalid message catalog",
+			content: "messages:\n  - messages.ko.yaml\n",
+			errors:  []string{"line 2: messages must be the path of a message catalog"},
+		},
+		{
+			name:    "rule packs",
+			content: "rule-packs:\n  - rules/codemods.yaml\n",
+		},
+		{
+			name:    "invalid rule packs",
+			content: "rule-packs: rules/codemods.yaml\n",
+			errors:  []string{"line 1: rule-packs must be a list of rule pack paths"},
+		},
+		{
+			name:    "gno version",
+			content: "gno-version: 0.2\n",
+		},
+		{
+			name:    "invalid gno version",
+			content: "gno-version: [0.2]\nrules:\n  std-api:\n    data:\n      gno-version: \"0.0\"\n",
+			errors: []string{
+				"line 1: gno-version must be a version such as 0.2",
+				`line 5: invalid value for option "gno-version": unknown gno version "0.0" (expected one of 0.1, 0.2)`,
+			},
+		},
+		{
+			name:    "exclude",
+			content: "exclude:\n  - \"**/testdata/**\"\n  - p/demo/*_gen.gno\n",
+		},
+		{
+			name:    "invalid exclude",
+			content: "exclude: testdata\n",
+			errors:  []string{"line 1: exclude must be a list of path globs"},
+		},
+		{
+			name:    "fail-on",
+			content: "fail-on: WARNING\n",
+		},
+		{
+			name:    "invalid fail-on",
+			content: "fail-on: OFF\n",
+			errors:  []string{`line 1: invalid fail-on severity "OFF" (expected one of ERROR, WARNING, INFO, HINT)`},
+		},
+		{
+			name:    "invalid exclude glob",
+			content: "exclude:\n  - p/[demo\n",
+			errors:  []string{`line 2: invalid exclude glob "p/[demo"`},
+		},
+		{
+			name: "option out of range",
+			content: `rules:
+  high-cyclomatic-complexity:
+    data:
+      threshold: 0
+  duplicate-function:
+    data:
+      similarity: 1.5
+      min-tokens: many
+`,
+			errors: []string{
+				`line 4: option "threshold" must be at least 1, got 0`,
+				`line 7: option "similarity" must be at most 1, got 1.5`,
+				`line 8: option "min-tokens" must be of type int`,
+			},
+		},
+		{
+			name: "multiple errors",
+			content: `name: tlin
+thresold: 10
+rules:
+  foo:
+    severity: ERROR
+  useless-break:
+    level: ERROR
+`,
+			errors: []string{
+				`line 2: unknown top-level key "thresold"`,
+				`line 4: unknown rule "foo"`,
+				`line 7: unknown rule key "level"`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			err := ValidateConfig([]byte(tt.content))
+			if len(tt.errors) == 0 {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			for _, msg := range tt.errors {
+				assert.Contains(t, err.Error(), msg)
+			}
+
+			var cfgErr *ConfigError
+			assert.True(t, errors.As(err, &cfgErr))
+		})
+	}
+}
+
+func TestGenerateDefaultConfig(t *testing.T) {
+	t.Parallel()
+
+	content := GenerateDefaultConfig()
+	require.NoError(t, ValidateConfig(content))
+
+	var config struct {
+		Name  string                            `yaml:"name"`
+		Rules map[string]map[string]interface{} `yaml:"rules"`
+	}
+	require.NoError(t, yaml.Unmarshal(content, &config))
+
+	assert.Equal(t, "tlin", config.Name)
+	rules := allRules.all()
+	assert.Len(t, config.Rules, len(rules))
+	for name, rule := range rules {
+		assert.Contains(t, string(content), "# "+rule.description, name)
+		assert.Equal(t, rule.severity.String(), config.Rules[name]["severity"], name)
+	}
+
+	data := config.Rules["high-cyclomatic-complexity"]["data"].(map[string]interface{})
+	assert.Equal(t, 10, data["threshold"])
+}
+
+func TestFindConfigFile(t *testing.T) {
+	t.Parallel()
+	root := t.TempDir()
+	require.NoError(t, os.Mkdir(filepath.Join(root, ".git"), 0o755))
+	writeFile(t, filepath.Join(root, localConfigName), "name: root\n")
+	writeFile(t, filepath.Join(root, "p", "demo", localConfigName), "name: demo\n")
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "p", "demo", "foo"), 0o755))
+
+	found, err := FindConfigFile(filepath.Join(root, "p", "demo", "foo"))
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(root, "p", "demo", "foo", "..", localConfigName), found)
+
+	found, err = FindConfigFile(filepath.Join(root, "p"))
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(root, localConfigName), found)
+
+	// the search stops at the project root
+	project := filepath.Join(root, "project")
+	require.NoError(t, os.MkdirAll(filepath.Join(project, ".git"), 0o755))
+	found, err = FindConfigFile(project)
+	require.NoError(t, err)
+	assert.Empty(t, found)
+}
+
+func TestResolveOptions(t *testing.T) {
+	t.Parallel()
+
+	var data interface{}
+	require.NoError(t, yaml.Unmarshal([]byte("threshold: 3\n"), &data))
+
+	values := resolveOptions(CyclomaticComplexityRule.options, data)
+	assert.Equal(t, 3, values["threshold"])
+
+	values = resolveOptions(CyclomaticComplexityRule.options, nil)
+	assert.Equal(t, 10, values["threshold"])
+}
@@ -0,0 +1,311 @@
+package internal
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	tt "github.com/gnolang/tlin/internal/types"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// localConfigName is the name of the configuration files looked up in
+	// the subdirectories of the engine's root directory.
+	localConfigName = ".tlin.yaml"
+
+	// defaultSource labels settings that come from the rule defaults.
+	defaultSource = "default"
+)
+
+// configLayer holds the rule settings read from one nested configuration file.
+// Only the settings present in the file are recorded, so that a child
+// configuration overrides exactly what it mentions.
+type configLayer struct {
+	source string
+	rules  map[string]layerRule
+}
+
+type layerRule struct {
+	Severity *tt.Severity           `yaml:"severity"`
+	Data     map[string]interface{} `yaml:"data"`
+}
+
+// ruleSetting is the merged configuration of a single rule along with the
+// configuration file each value comes from.
+type ruleSetting struct {
+	severity       tt.Severity
+	severitySource string
+	options        map[string]interface{}
+	optionSources  map[string]string
+}
+
+// dirConfigCache caches the nested configuration files and the resulting
+// rule sets per directory, so each directory is resolved only once.
+type dirConfigCache struct {
+	mu     sync.Mutex
+	layers map[string]*configLayer
+	rules  map[string]map[string]LintRule
+}
+
+func newDirConfigCache() *dirConfigCache {
+	return &dirConfigCache{
+		layers: make(map[string]*configLayer),
+		rules:  make(map[string]map[string]LintRule),
+	}
+}
+
+// RuleConfig is the effective configuration of a rule for a specific file.
+type RuleConfig struct {
+	Name           string
+	Severity       tt.Severity
+	SeveritySource string
+	Options        []OptionConfig
+}
+
+// OptionConfig is the effective value of a rule option and where it was set.
+type OptionConfig struct {
+	Name   string
+	Value  interface{}
+	Source string
+}
+
+// EffectiveConfig returns the configuration that applies to the given file once
+// the root configuration and every nested configuration file between the
+// engine's root directory and the file have been merged.
+func (e *Engine) EffectiveConfig(filename string) ([]RuleConfig, error) {
+	layers, err := e.layersFor(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	settings := e.ruleSettings(layers)
+	configs := make([]RuleConfig, 0, len(settings))
+	for _, name := range sortedRuleNames() {
+		setting := settings[name]
+		rule, _ := allRules.lookup(name)
+		values := resolveOptions(rule.options, setting.options)
+
+		cfg := RuleConfig{
+			Name:           name,
+			Severity:       setting.severity,
+			SeveritySource: setting.severitySource,
+		}
+		for _, opt := range rule.options {
+			source, ok := setting.optionSources[opt.Name]
+			if !ok {
+				source = defaultSource
+			}
+			cfg.Options = append(cfg.Options, OptionConfig{
+				Name:   opt.Name,
+				Value:  values[opt.Name],
+				Source: source,
+			})
+		}
+		configs = append(configs, cfg)
+	}
+
+	return configs, nil
+}
+
+// rulesFor returns the rules that apply to the given file. Files without any
+// nested configuration file above them share the engine's root rule set.
+func (e *Engine) rulesFor(filename string) (map[string]LintRule, error) {
+	layers, err := e.layersFor(filename)
+	if err != nil {
+		return nil, err
+	}
+	if len(layers) == 0 {
+		return e.rules, nil
+	}
+
+	dir := filepath.Dir(filename)
+	e.dirConfigs.mu.Lock()
+	defer e.dirConfigs.mu.Unlock()
+
+	if rules, ok := e.dirConfigs.rules[dir]; ok {
+		return rules, nil
+	}
+	rules := newRuleSet(e.ruleSettings(layers))
+	e.dirConfigs.rules[dir] = rules
+	return rules, nil
+}
+
+// layersFor collects the nested configuration files that apply to the given
+// file, ordered from the outermost directory to the innermost.
+func (e *Engine) layersFor(filename string) ([]*configLayer, error) {
+	if e.rootDir == "" || filename == "" {
+		return nil, nil
+	}
+
+	root, err := filepath.Abs(e.rootDir)
+	if err != nil {
+		return nil, err
+	}
+	dir, err := filepath.Abs(filepath.Dir(filename))
+	if err != nil {
+		return nil, err
+	}
+
+	rel, err := filepath.Rel(root, dir)
+	if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return nil, nil
+	}
+
+	var layers []*configLayer
+	current := root
+	for _, part := range strings.Split(rel, string(filepath.Separator)) {
+		current = filepath.Join(current, part)
+		layer, err := e.layerAt(current)
+		if err != nil {
+			return nil, err
+		}
+		if layer != nil {
+			layers = append(layers, layer)
+		}
+	}
+	return layers, nil
+}
+
+// layerAt loads the configuration file of a directory, if any.
+func (e *Engine) layerAt(dir string) (*configLayer, error) {
+	e.dirConfigs.mu.Lock()
+	defer e.dirConfigs.mu.Unlock()
+
+	if layer, ok := e.dirConfigs.layers[dir]; ok {
+		return layer, nil
+	}
+
+	layer, err := loadConfigLayer(filepath.Join(dir, localConfigName))
+	if err != nil {
+		return nil, err
+	}
+	e.dirConfigs.layers[dir] = layer
+	return layer, nil
+}
+
+func loadConfigLayer(path string) (*configLayer, error) {
+	content, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := ValidateConfig(content); err != nil {
+		return nil, fmt.Errorf("invalid configuration file %s: %w", path, err)
+	}
+
+	var config struct {
+		Preset     string               `yaml:"preset"`
+		Messages   string               `yaml:"messages"`
+		GnoVersion string               `yaml:"gno-version"`
+		Exclude    []string             `yaml:"exclude"`
+		Rules      map[string]layerRule `yaml:"rules"`
+	}
+	if err := yaml.Unmarshal(content, &config); err != nil {
+		return nil, fmt.Errorf("error parsing configuration file %s: %w", path, err)
+	}
+	if config.Preset != "" {
+		return nil, fmt.Errorf("invalid configuration file %s: preset can only be set in the root configuration", path)
+	}
+	if config.Messages != "" {
+		return nil, fmt.Errorf("invalid configuration file %s: messages can only be set in the root configuration", path)
+	}
+	if config.GnoVersion != "" {
+		return nil, fmt.Errorf("invalid configuration file %s: gno-version can only be set in the root configuration", path)
+	}
+	if len(config.Exclude) > 0 {
+		return nil, fmt.Errorf("invalid configuration file %s: exclude can only be set in the root configuration", path)
+	}
+
+	return &configLayer{source: path, rules: config.Rules}, nil
+}
+
+// ruleSettings merges the rule defaults, the selected preset, the root
+// configuration and the given layers. Later layers win over earlier ones.
+func (e *Engine) ruleSettings(layers []*configLayer) map[string]*ruleSetting {
+	rules := allRules.all()
+	settings := make(map[string]*ruleSetting, len(rules))
+	for name, rule := range rules {
+		settings[name] = &ruleSetting{
+			severity:       rule.severity,
+			severitySource: defaultSource,
+			options:        make(map[string]interface{}),
+			optionSources:  make(map[string]string),
+		}
+	}
+
+	if e.preset != "" {
+		apply := presets[e.preset]
+		for name, rule := range rules {
+			settings[name].severity = apply(rule)
+			settings[name].severitySource = "preset " + e.preset
+		}
+	}
+
+	source := e.configPath
+	if source == "" {
+		source = "configuration"
+	}
+	if e.gnoVersion != "" {
+		settings[stdAPIRule].options["gno-version"] = e.gnoVersion
+		settings[stdAPIRule].optionSources["gno-version"] = source
+	}
+	for name, rule := range e.config {
+		setting, ok := settings[name]
+		if !ok {
+			continue
+		}
+		setting.severity = rule.Severity
+		setting.severitySource = source
+		if data, ok := rule.Data.(map[string]interface{}); ok {
+			setting.setOptions(data, source)
+		}
+	}
+
+	for _, layer := range layers {
+		for name, rule := range layer.rules {
+			setting, ok := settings[name]
+			if !ok {
+				continue
+			}
+			if rule.Severity != nil {
+				setting.severity = *rule.Severity
+				setting.severitySource = layer.source
+			}
+			setting.setOptions(rule.Data, layer.source)
+		}
+	}
+
+	return settings
+}
+
+func (s *ruleSetting) setOptions(data map[string]interface{}, source string) {
+	for key, value := range data {
+		s.options[key] = value
+		s.optionSources[key] = source
+	}
+}
+
+// newRuleSet builds the enabled rules out of the merged settings.
+func newRuleSet(settings map[string]*ruleSetting) map[string]LintRule {
+	rules := make(map[string]LintRule, len(settings))
+	for name, setting := range settings {
+		if setting.severity == tt.SeverityOff {
+			continue
+		}
+		rule, _ := allRules.lookup(name)
+		rule.name = name
+		rule.severity = setting.severity
+		rule.config = fmt.Sprintf("%s %v", setting.severity, setting.options)
+		if rule.definition != "" {
+			rule.config += " " + rule.definition
+		}
+		rules[name] = rule.withOptions(setting.options)
+	}
+	return rules
+}
@@ -0,0 +1,158 @@
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gnolang/tlin/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const complexSource = `package foo
+
+func complex(a, b int) int {
+	if a > 0 {
+		if b > 0 {
+			return 1
+		}
+		return 2
+	}
+	return 3
+}
+`
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+}
+
+func findRuleConfig(configs []RuleConfig, name string) RuleConfig {
+	for _, cfg := range configs {
+		if cfg.Name == name {
+			return cfg
+		}
+	}
+	return RuleConfig{}
+}
+
+func TestNestedConfigOverrides(t *testing.T) {
+	t.Parallel()
+
+	root := createTempDir(t, "nested_config")
+	libConfig := filepath.Join(root, "p", ".tlin.yaml")
+	subConfig := filepath.Join(root, "p", "sub", ".tlin.yaml")
+
+	writeFile(t, libConfig, `rules:
+  high-cyclomatic-complexity:
+    severity: ERROR
+    data:
+      threshold: 2
+  useless-break:
+    severity: WARNING
+`)
+	writeFile(t, subConfig, `rules:
+  high-cyclomatic-complexity:
+    data:
+      threshold: 5
+  early-return-opportunity:
+    severity: OFF
+`)
+	libFile := filepath.Join(root, "p", "lib.go")
+	subFile := filepath.Join(root, "p", "sub", "sub.go")
+	exampleFile := filepath.Join(root, "examples", "main.go")
+	writeFile(t, libFile, complexSource)
+	writeFile(t, subFile, complexSource)
+	writeFile(t, exampleFile, complexSource)
+
+	engine, err := NewEngine(root, nil, map[string]types.ConfigRule{
+		"useless-break": {Severity: types.SeverityInfo},
+	})
+	require.NoError(t, err)
+	engine.SetConfigPath("root.yaml")
+
+	t.Run("effective config", func(t *testing.T) {
+		t.Parallel()
+
+		configs, err := engine.EffectiveConfig(subFile)
+		require.NoError(t, err)
+
+		cyclo := findRuleConfig(configs, "high-cyclomatic-complexity")
+		assert.Equal(t, types.SeverityError, cyclo.Severity)
+		assert.Equal(t, libConfig, cyclo.SeveritySource)
+		require.Len(t, cyclo.Options, 1)
+		assert.Equal(t, 5, cyclo.Options[0].Value)
+		assert.Equal(t, subConfig, cyclo.Options[0].Source)
+
+		brk := findRuleConfig(configs, "useless-break")
+		assert.Equal(t, types.SeverityWarning, brk.Severity)
+		assert.Equal(t, libConfig, brk.SeveritySource)
+
+		early := findRuleConfig(configs, "early-return-opportunity")
+		assert.Equal(t, types.SeverityOff, early.Severity)
+
+		configs, err = engine.EffectiveConfig(exampleFile)
+		require.NoError(t, err)
+
+		brk = findRuleConfig(configs, "useless-break")
+		assert.Equal(t, types.SeverityInfo, brk.Severity)
+		assert.Equal(t, "root.yaml", brk.SeveritySource)
+
+		cyclo = findRuleConfig(configs, "high-cyclomatic-complexity")
+		assert.Equal(t, types.SeverityOff, cyclo.Severity)
+		assert.Equal(t, defaultSource, cyclo.SeveritySource)
+		assert.Equal(t, defaultSource, cyclo.Options[0].Source)
+	})
+
+	t.Run("rule sets", func(t *testing.T) {
+		t.Parallel()
+
+		rules, err := engine.rulesFor(exampleFile)
+		require.NoError(t, err)
+		assert.NotContains(t, rules, "high-cyclomatic-complexity")
+
+		rules, err = engine.rulesFor(subFile)
+		require.NoError(t, err)
+		assert.Contains(t, rules, "high-cyclomatic-complexity")
+		assert.NotContains(t, rules, "early-return-opportunity")
+
+		cached, err := engine.rulesFor(filepath.Join(root, "p", "sub", "other.go"))
+		require.NoError(t, err)
+		assert.Equal(t, len(rules), len(cached))
+	})
+
+	t.Run("thresholds", func(t *testing.T) {
+		t.Parallel()
+
+		countComplexity := func(filename string) int {
+			issues, err := engine.Run(filename)
+			require.NoError(t, err)
+			count := 0
+			for _, issue := range issues {
+				if issue.Rule == "high-cyclomatic-complexity" {
+					count++
+				}
+			}
+			return count
+		}
+
+		assert.Equal(t, 1, countComplexity(libFile))
+		assert.Equal(t, 0, countComplexity(subFile))
+		assert.Equal(t, 0, countComplexity(exampleFile))
+	})
+}
+
+func TestNestedConfigInvalid(t *testing.T) {
+	t.Parallel()
+
+	root := createTempDir(t, "nested_config_invalid")
+	writeFile(t, filepath.Join(root, "p", ".tlin.yaml"), "rules:\n  unknown-rule:\n    severity: ERROR\n")
+
+	engine, err := NewEngine(root, nil, nil)
+	require.NoError(t, err)
+
+	_, err = engine.EffectiveConfig(filepath.Join(root, "p", "lib.go"))
+	assert.ErrorContains(t, err, `unknown rule "unknown-rule"`)
+}
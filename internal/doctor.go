@@ -0,0 +1,271 @@
+package internal
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+
+	"github.com/gnolang/tlin/internal/cache"
+	tt "github.com/gnolang/tlin/internal/types"
+)
+
+const golangciLintRule = "golangci-lint"
+
+// supported golangci-lint versions. v2 dropped the `--out-format` flag
+// used to request JSON output, so only v1 releases are compatible.
+var (
+	minGolangciVersion = [3]int{1, 50, 0}
+	maxGolangciMajor   = 1
+)
+
+var versionPattern = regexp.MustCompile(`(\d+)\.(\d+)\.(\d+)`)
+
+// these are variables so that tests can replace the external tools.
+var (
+	lookPath        = exec.LookPath
+	defaultCacheDir = cache.DefaultDir
+	runCommand      = func(dir, name string, args ...string) ([]byte, error) {
+		cmd := exec.Command(name, args...)
+		cmd.Dir = dir
+		return cmd.CombinedOutput()
+	}
+)
+
+// CheckResult is the outcome of a single environment check.
+type CheckResult struct {
+	Name     string
+	OK       bool
+	Required bool
+	Detail   string
+	Hint     string
+	// Rules lists the rules that cannot run when the check fails.
+	Rules []string
+}
+
+// RunChecks validates the environment tlin runs in: external tools used by
+// rules, the configuration file and the result cache. When probe is set, integrations are
+// exercised on a temporary file instead of only checking their version.
+func RunChecks(configPath string, probe bool) []CheckResult {
+	golangci := checkGolangciLint(probe)
+	if isRuleDisabled(configPath, golangciLintRule) {
+		golangci.Required = false
+	}
+
+	return []CheckResult{
+		golangci,
+		checkGnoToolchain(),
+		checkConfigFile(configPath),
+		checkCacheDir(),
+	}
+}
+
+// isRuleDisabled reports whether the configuration file turns the rule off.
+func isRuleDisabled(configPath, rule string) bool {
+	layer, err := loadConfigLayer(configPath)
+	if err != nil || layer == nil {
+		return false
+	}
+	severity := layer.rules[rule].Severity
+	return severity != nil && *severity == tt.SeverityOff
+}
+
+func checkGolangciLint(probe bool) CheckResult {
+	result := CheckResult{
+		Name:     "golangci-lint",
+		Required: true,
+		Rules:    []string{golangciLintRule},
+	}
+
+	path, err := lookPath("golangci-lint")
+	if err != nil {
+		result.Detail = "golangci-lint not found in PATH"
+		result.Hint = "install golangci-lint v1 (`make install-linter`) or disable the golangci-lint rule"
+		return result
+	}
+
+	output, err := runCommand("", path, "--version")
+	if err != nil {
+		result.Detail = fmt.Sprintf("failed to run golangci-lint --version: %v", err)
+		result.Hint = "check that the golangci-lint binary in PATH is executable"
+		return result
+	}
+
+	version, ok := parseVersion(string(output))
+	if !ok {
+		result.Detail = fmt.Sprintf("could not read golangci-lint version from %q", output)
+		result.Hint = "install golangci-lint v1 (`make install-linter`)"
+		return result
+	}
+	if !isSupportedGolangciVersion(version) {
+		result.Detail = fmt.Sprintf("golangci-lint %d.%d.%d is not supported", version[0], version[1], version[2])
+		result.Hint = fmt.Sprintf("install golangci-lint >= %d.%d.%d and < %d.0.0",
+			minGolangciVersion[0], minGolangciVersion[1], minGolangciVersion[2], maxGolangciMajor+1)
+		return result
+	}
+
+	if probe {
+		if err := probeGolangciLint(path); err != nil {
+			result.Detail = fmt.Sprintf("golangci-lint %d.%d.%d failed the probe run: %v", version[0], version[1], version[2], err)
+			result.Hint = "run golangci-lint manually on a file to see its error"
+			return result
+		}
+	}
+
+	result.OK = true
+	result.Detail = fmt.Sprintf("golangci-lint %d.%d.%d", version[0], version[1], version[2])
+	return result
+}
+
+// probeGolangciLint lints a temporary file and checks that the output
+// can be decoded the way the golangci-lint rule expects it.
+func probeGolangciLint(path string) error {
+	dir, err := os.MkdirTemp("", "tlin-doctor")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+
+	files := map[string]string{
+		"go.mod":  "module probe\n\ngo 1.22\n",
+		"main.go": "package main\n\nfunc main() {}\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			return err
+		}
+	}
+
+	output, _ := runCommand(dir, path, "run", "--out-format=json", "main.go")
+
+	var result struct {
+		Issues *json.RawMessage `json:"Issues"`
+	}
+	if err := json.Unmarshal(output, &result); err != nil {
+		return fmt.Errorf("unexpected output format: %w", err)
+	}
+	if result.Issues == nil {
+		return errors.New("unexpected output format: missing Issues field")
+	}
+	return nil
+}
+
+func checkGnoToolchain() CheckResult {
+	result := CheckResult{Name: "gno"}
+
+	path, err := lookPath("gno")
+	if err != nil {
+		result.Detail = "gno not found in PATH"
+		result.Hint = "install gno (https://github.com/gnolang/gno) to work with gno.mod files"
+		return result
+	}
+
+	result.OK = true
+	result.Detail = path
+	return result
+}
+
+func checkConfigFile(configPath string) CheckResult {
+	result := CheckResult{Name: "configuration", Required: true}
+
+	content, err := os.ReadFile(configPath)
+	if errors.Is(err, os.ErrNotExist) {
+		result.OK = true
+		result.Detail = fmt.Sprintf("%s not found, using defaults", configPath)
+		return result
+	}
+	if err != nil {
+		result.Detail = fmt.Sprintf("cannot read %s: %v", configPath, err)
+		result.Hint = "check the permissions of the configuration file"
+		return result
+	}
+
+	if err := ValidateConfig(content); err != nil {
+		result.Detail = fmt.Sprintf("%s is invalid: %v", configPath, err)
+		result.Hint = "fix the reported lines, or regenerate a template with `tlin -init`"
+		return result
+	}
+
+	result.OK = true
+	result.Detail = configPath
+	return result
+}
+
+// checkCacheDir checks that the result cache can be written. The cache only
+// speeds up the runs, so it is not required.
+func checkCacheDir() CheckResult {
+	result := CheckResult{Name: "result cache"}
+
+	dir, err := defaultCacheDir()
+	if err != nil {
+		result.Detail = fmt.Sprintf("cannot locate the cache directory: %v", err)
+		result.Hint = "set $XDG_CACHE_HOME or $HOME, or run with -no-cache"
+		return result
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		result.Detail = fmt.Sprintf("cannot create %s: %v", dir, err)
+		result.Hint = "check the permissions of the cache directory, or run with -no-cache"
+		return result
+	}
+	f, err := os.CreateTemp(dir, "doctor-*.tmp")
+	if err != nil {
+		result.Detail = fmt.Sprintf("%s is not writable: %v", dir, err)
+		result.Hint = "check the permissions of the cache directory, or run with -no-cache"
+		return result
+	}
+	f.Close()
+	os.Remove(f.Name())
+
+	result.OK = true
+	result.Detail = dir
+	return result
+}
+
+func parseVersion(s string) ([3]int, bool) {
+	var version [3]int
+	match := versionPattern.FindStringSubmatch(s)
+	if match == nil {
+		return version, false
+	}
+	for i := range version {
+		version[i], _ = strconv.Atoi(match[i+1])
+	}
+	return version, true
+}
+
+func isSupportedGolangciVersion(v [3]int) bool {
+	if v[0] > maxGolangciMajor {
+		return false
+	}
+	for i := range v {
+		if v[i] != minGolangciVersion[i] {
+			return v[i] > minGolangciVersion[i]
+		}
+	}
+	return true
+}
+
+// checkIntegrations runs the checks guarding rules that depend on external
+// tools, and skips those rules when their tool is unusable. The observer is
+// told of every skipped rule that would otherwise have run.
+func (e *Engine) checkIntegrations() {
+	if _, enabled := e.rules[golangciLintRule]; !enabled || e.ignoredRules[golangciLintRule] {
+		return
+	}
+
+	result := checkGolangciLint(false)
+	if result.OK {
+		return
+	}
+	if e.skippedRules == nil {
+		e.skippedRules = make(map[string]string)
+	}
+	for _, rule := range result.Rules {
+		e.skippedRules[rule] = result.Detail
+		e.observer.OnRuleSkipped(rule, result.Detail)
+	}
+}
@@ -0,0 +1,201 @@
+package internal
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stubTools replaces the external tool lookups for the duration of the test.
+// Tests using it must not run in parallel.
+func stubTools(t *testing.T, installed map[string]string) {
+	t.Helper()
+	origLookPath, origRunCommand := lookPath, runCommand
+	t.Cleanup(func() {
+		lookPath, runCommand = origLookPath, origRunCommand
+	})
+
+	lookPath = func(name string) (string, error) {
+		if _, ok := installed[name]; !ok {
+			return "", errors.New("executable file not found in $PATH")
+		}
+		return "/usr/bin/" + name, nil
+	}
+	runCommand = func(dir, name string, args ...string) ([]byte, error) {
+		if len(args) > 0 && args[0] == "--version" {
+			return []byte(installed[filepath.Base(name)]), nil
+		}
+		return []byte(`{"Issues":[]}`), nil
+	}
+}
+
+func stubCacheDir(t *testing.T, dir string) {
+	t.Helper()
+	orig := defaultCacheDir
+	t.Cleanup(func() { defaultCacheDir = orig })
+	defaultCacheDir = func() (string, error) { return dir, nil }
+}
+
+func TestCheckGolangciLint(t *testing.T) {
+	tests := []struct {
+		name      string
+		installed map[string]string
+		ok        bool
+		detail    string
+	}{
+		{
+			name:      "missing",
+			installed: map[string]string{},
+			detail:    "golangci-lint not found in PATH",
+		},
+		{
+			name:      "supported",
+			installed: map[string]string{"golangci-lint": "golangci-lint has version 1.61.0 built with go1.23.1"},
+			ok:        true,
+			detail:    "golangci-lint 1.61.0",
+		},
+		{
+			name:      "too old",
+			installed: map[string]string{"golangci-lint": "golangci-lint has version 1.42.1"},
+			detail:    "golangci-lint 1.42.1 is not supported",
+		},
+		{
+			name:      "v2",
+			installed: map[string]string{"golangci-lint": "golangci-lint has version 2.0.2"},
+			detail:    "golangci-lint 2.0.2 is not supported",
+		},
+		{
+			name:      "unreadable version",
+			installed: map[string]string{"golangci-lint": "dev"},
+			detail:    `could not read golangci-lint version from "dev"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stubTools(t, tt.installed)
+
+			result := checkGolangciLint(true)
+			assert.Equal(t, tt.ok, result.OK)
+			assert.Equal(t, tt.detail, result.Detail)
+			if !tt.ok {
+				assert.NotEmpty(t, result.Hint)
+			}
+		})
+	}
+}
+
+func TestRunChecks(t *testing.T) {
+	stubTools(t, map[string]string{})
+	dir := t.TempDir()
+	cacheDir := filepath.Join(dir, "cache")
+	stubCacheDir(t, cacheDir)
+
+	t.Run("missing config uses defaults", func(t *testing.T) {
+		results := RunChecks(filepath.Join(dir, "missing.yaml"), false)
+		require.Len(t, results, 4)
+
+		assert.Equal(t, "golangci-lint", results[0].Name)
+		assert.False(t, results[0].OK)
+		assert.True(t, results[0].Required)
+
+		assert.Equal(t, "gno", results[1].Name)
+		assert.False(t, results[1].OK)
+		assert.False(t, results[1].Required)
+
+		assert.Equal(t, "configuration", results[2].Name)
+		assert.True(t, results[2].OK)
+
+		assert.Equal(t, "result cache", results[3].Name)
+		assert.True(t, results[3].OK)
+		assert.False(t, results[3].Required)
+		assert.Equal(t, cacheDir, results[3].Detail)
+		entries, err := os.ReadDir(cacheDir)
+		require.NoError(t, err)
+		assert.Empty(t, entries, "the check cleans up after itself")
+	})
+
+	t.Run("unwritable cache", func(t *testing.T) {
+		file := filepath.Join(dir, "file")
+		require.NoError(t, os.WriteFile(file, nil, 0o644))
+		stubCacheDir(t, filepath.Join(file, "cache"))
+
+		results := RunChecks(filepath.Join(dir, "missing.yaml"), false)
+		assert.False(t, results[3].OK)
+		assert.Contains(t, results[3].Detail, "cannot create")
+		assert.Contains(t, results[3].Hint, "-no-cache")
+	})
+
+	t.Run("invalid config", func(t *testing.T) {
+		path := filepath.Join(dir, "invalid.yaml")
+		require.NoError(t, os.WriteFile(path, []byte("rules:\n  no-such-rule:\n    severity: ERROR\n"), 0o644))
+
+		results := RunChecks(path, false)
+		assert.False(t, results[2].OK)
+		assert.Contains(t, results[2].Detail, `unknown rule "no-such-rule"`)
+	})
+
+	t.Run("disabled rule is not required", func(t *testing.T) {
+		path := filepath.Join(dir, "disabled.yaml")
+		require.NoError(t, os.WriteFile(path, []byte("rules:\n  golangci-lint:\n    severity: OFF\n"), 0o644))
+
+		results := RunChecks(path, false)
+		assert.False(t, results[0].OK)
+		assert.False(t, results[0].Required)
+	})
+}
+
+func TestEngineSkipsUnavailableIntegration(t *testing.T) {
+	stubTools(t, map[string]string{})
+
+	engine, err := NewEngine("", nil, nil)
+	require.NoError(t, err)
+
+	_, err = engine.RunSource([]byte("package main\n\nfunc main() {}\n"))
+	require.NoError(t, err)
+	assert.Equal(t, "golangci-lint not found in PATH", engine.skippedRules["golangci-lint"])
+}
+
+type skipObserver struct {
+	NopObserver
+	skipped []string
+}
+
+func (o *skipObserver) OnRuleSkipped(rule, reason string) {
+	o.skipped = append(o.skipped, rule+": "+reason)
+}
+
+func TestEngineReportsSkippedRules(t *testing.T) {
+	stubTools(t, map[string]string{})
+
+	engine, err := NewEngine("", nil, nil)
+	require.NoError(t, err)
+	observer := &skipObserver{}
+	engine.SetObserver(observer)
+
+	for range 2 {
+		_, err = engine.RunSource([]byte("package main\n\nfunc main() {}\n"))
+		require.NoError(t, err)
+	}
+	// the skipped rules are reported once per engine
+	assert.Equal(t, []string{"golangci-lint: golangci-lint not found in PATH"}, observer.skipped)
+}
+
+func TestParseVersion(t *testing.T) {
+	t.Parallel()
+
+	version, ok := parseVersion("golangci-lint has version v1.59.1 built with go1.22.3")
+	assert.True(t, ok)
+	assert.Equal(t, [3]int{1, 59, 1}, version)
+
+	_, ok = parseVersion("unknown")
+	assert.False(t, ok)
+
+	assert.True(t, isSupportedGolangciVersion([3]int{1, 50, 0}))
+	assert.False(t, isSupportedGolangciVersion([3]int{1, 49, 9}))
+	assert.False(t, isSupportedGolangciVersion([3]int{2, 0, 0}))
+}
@@ -1,14 +1,21 @@
 package internal
 
 import (
+	"context"
 	"fmt"
+	"go/ast"
 	"go/token"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"sync"
+	"time"
 
+	"github.com/gnolang/tlin/internal/cache"
+	"github.com/gnolang/tlin/internal/calibration"
 	"github.com/gnolang/tlin/internal/lints"
+	"github.com/gnolang/tlin/internal/messages"
 	"github.com/gnolang/tlin/internal/nolint"
 	tt "github.com/gnolang/tlin/internal/types"
 )
@@ -17,61 +24,144 @@ import (
 // TODO: use symbol table
 type Engine struct {
 	ignoredPaths []string
+	// excludes are the globs of the files left out of the run, see Exclude.
+	excludes     []pathGlob
 	ignoredRules map[string]bool
-	nolintMgr    *nolint.Manager
 	rules        map[string]LintRule
+	rootDir      string
+	config       map[string]tt.ConfigRule
+	configPath   string
+	dirConfigs   *dirConfigCache
+	packages     *packageCache
+	preset       string
+	observer     Observer
+	// gnoVersion is the gno version selected by SetGnoVersion.
+	gnoVersion string
+	// messages translates the issue messages, nil to keep them in English.
+	messages *messages.Catalog
+	// suppressions is only set when suppressions are counted.
+	suppressions *calibration.Counter
+	// suppressionList is the checked-in suppression list, nil without one.
+	suppressionList *suppressionList
+	// reportUnusedIgnores is set by ReportUnusedIgnores.
+	reportUnusedIgnores bool
+	// cache keeps the issues of the rules between runs, nil to always run them.
+	cache *cache.Cache
+	// ruleTimeout bounds the time a rule spends on a file, zero for no limit.
+	ruleTimeout time.Duration
+
+	// skippedRules maps the rules whose external tool is unusable to the reason.
+	skippedRules     map[string]string
+	integrationsOnce sync.Once
 }
 
 // NewEngine creates a new lint engine.
 func NewEngine(rootDir string, source []byte, rules map[string]tt.ConfigRule) (*Engine, error) {
-	engine := &Engine{}
+	engine := &Engine{
+		rootDir:      rootDir,
+		dirConfigs:   newDirConfigCache(),
+		packages:     newPackageCache(),
+		skippedRules: make(map[string]string),
+		observer:     NopObserver{},
+	}
 	engine.applyRules(rules)
 
+	list, err := loadSuppressions(rootDir)
+	if err != nil {
+		return nil, err
+	}
+	engine.suppressionList = list
+
 	return engine, nil
 }
 
-func (e *Engine) applyRules(rules map[string]tt.ConfigRule) {
-	e.rules = make(map[string]LintRule)
-	e.registerDefaultRules()
-
-	// Iterate over the rules and apply severity
-	for key, rule := range rules {
-		r, ok := e.findRule(key)
-		if !ok {
-			newRule, exists := allRules[key]
-			if !exists {
-				// Unknown rule, continue to the next one
-				continue
-			}
-			newRule.severity = rule.Severity
-			e.rules[key] = newRule
-		} else {
-			if rule.Severity == tt.SeverityOff {
-				e.IgnoreRule(key)
-			}
-			r.severity = rule.Severity
-			e.rules[key] = r
-		}
-	}
+// SetConfigPath records the path of the root configuration file.
+// It is only used to report where settings come from.
+func (e *Engine) SetConfigPath(path string) {
+	e.configPath = path
 }
 
-func (e *Engine) registerDefaultRules() {
-	// iterate over allRules and add them to the rules map if severity is not off
-	for key, newRule := range allRules {
-		if newRule.Severity() != tt.SeverityOff {
-			newRule.name = key
-			e.rules[key] = newRule
-		}
+// SetObserver registers the observer notified of the progress of the run.
+// A nil observer restores the default one, which ignores every event.
+func (e *Engine) SetObserver(observer Observer) {
+	if observer == nil {
+		e.observer = NopObserver{}
+		return
 	}
+	e.observer = &syncObserver{observer: observer}
+}
+
+// SetMessages sets the catalog translating the messages of the issues.
+// A nil catalog keeps them in English.
+func (e *Engine) SetMessages(catalog *messages.Catalog) {
+	e.messages = catalog
+}
+
+// Observer returns the observer of the engine. Run reports the file and rule
+// events itself, while whoever drives the run reports its start and end.
+func (e *Engine) Observer() Observer {
+	return e.observer
 }
 
-func (e *Engine) findRule(name string) (LintRule, bool) {
-	rule, exists := e.rules[name]
-	return rule, exists
+// CountSuppressions makes the engine count, per rule, the issues found and
+// those suppressed by nolint comments. It must be called before the run.
+func (e *Engine) CountSuppressions() {
+	e.suppressions = calibration.NewCounter()
+}
+
+// SuppressionCounts returns the counts gathered since CountSuppressions.
+func (e *Engine) SuppressionCounts() map[string]calibration.Counts {
+	return e.suppressions.Counts()
+}
+
+// SuppressionIssues reports the entries of the suppression list that expired
+// or that suppressed nothing in the files linted so far. It is meant to be
+// called once every file has been linted.
+func (e *Engine) SuppressionIssues() []tt.Issue {
+	return e.suppressionList.issues()
+}
+
+// ReportUnusedIgnores makes the engine report the //tlin:ignore comments
+// that suppressed no issue of their rule, as unused-ignore issues.
+func (e *Engine) ReportUnusedIgnores() {
+	e.reportUnusedIgnores = true
+}
+
+// SetCache makes the engine reuse the issues the rules reported in previous
+// runs for the files that did not change since. A nil cache runs every rule.
+func (e *Engine) SetCache(c *cache.Cache) {
+	e.cache = c
+}
+
+// SetRuleTimeout bounds the time each rule spends on a file. A rule running
+// longer is given up on and reported to the observer as failed, while the
+// issues of the other rules are still returned. Zero disables the limit.
+func (e *Engine) SetRuleTimeout(timeout time.Duration) {
+	e.ruleTimeout = timeout
+}
+
+func (e *Engine) applyRules(rules map[string]tt.ConfigRule) {
+	e.config = rules
+	e.rules = newRuleSet(e.ruleSettings(nil))
 }
 
 // Run applies all lint rules to the given file and returns a slice of Issues.
 func (e *Engine) Run(filename string) ([]tt.Issue, error) {
+	return e.RunContext(context.Background(), filename)
+}
+
+// RunContext is Run, giving up on the rules still running once ctx is done,
+// in which case it returns the error of ctx.
+func (e *Engine) RunContext(ctx context.Context, filename string) (issues []tt.Issue, err error) {
+	e.observer.OnFileStart(filename)
+	defer func(start time.Time) {
+		e.observer.OnFileDone(filename, issues, time.Since(start))
+	}(time.Now())
+
+	if e.isExcluded(filename) {
+		return nil, nil
+	}
+
 	tempFile, err := e.prepareFile(filename)
 	if err != nil {
 		return nil, err
@@ -83,79 +173,261 @@ func (e *Engine) Run(filename string) ([]tt.Issue, error) {
 		return nil, fmt.Errorf("error parsing file: %w", err)
 	}
 
-	e.nolintMgr = nolint.ParseComments(node, fset)
+	nolintMgr := nolint.ParseComments(node, fset)
+	e.integrationsOnce.Do(e.checkIntegrations)
+
+	rules, err := e.rulesFor(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	// the issues of the rules in a previous run, when the file is unchanged
+	cacheKey := e.cacheKey(rules, filename, node)
+	var cached map[string][]tt.Issue
+	if cacheKey != "" {
+		cached, _ = e.cache.Get(cacheKey)
+	}
+	// fresh collects the issues to cache, unless a rule fails
+	fresh := make(map[string][]tt.Issue)
+	failed := false
 
 	var wg sync.WaitGroup
 	var mu sync.Mutex
 
 	var allIssues []tt.Issue
-	for _, rule := range e.rules {
+	// ran records the rules that checked the file, whose directives can be
+	// told unused
+	ran := make(map[string]bool)
+	for _, rule := range rules {
 		wg.Add(1)
 		go func(r LintRule) {
 			defer wg.Done()
-			if e.ignoredRules[r.Name()] {
+			if !e.runs(r) {
 				return
 			}
-			issues, err := r.Check(tempFile, node, fset)
+			var issues []tt.Issue
+			var err error
+			if ruleIssues, ok := cached[r.Name()]; ok && !r.uncached {
+				issues = renameFile(ruleIssues, cachedFile, tempFile)
+			} else {
+				issues, err = e.checkFile(ctx, r, filename, tempFile, node, fset)
+			}
 			if err != nil {
+				// the rules given up on with the run are not failures of their own
+				if ctx.Err() == nil {
+					e.observer.OnRuleError(filename, r.Name(), err)
+				}
+				mu.Lock()
+				failed = true
+				mu.Unlock()
 				return
 			}
+			if cacheKey != "" && !r.uncached {
+				mu.Lock()
+				fresh[r.Name()] = renameFile(issues, tempFile, cachedFile)
+				mu.Unlock()
+			}
 
-			nolinted := e.filterNolintIssues(issues)
+			nolinted := filterNolintIssues(nolintMgr, issues)
+			e.suppressions.Add(issues, nolinted)
 			noIgnoredPaths := e.filterIgnoredPaths(nolinted)
 
 			mu.Lock()
 			allIssues = append(allIssues, noIgnoredPaths...)
+			ran[r.Name()] = true
 			mu.Unlock()
 		}(rule)
 	}
 	wg.Wait()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if cacheKey != "" && cached == nil && !failed {
+		// the cache only speeds up later runs, failing to fill it is harmless
+		_ = e.cache.Put(cacheKey, fresh)
+	}
+	allIssues = append(allIssues, e.unusedIgnoreIssues(nolintMgr, ran)...)
 
 	// map issues back to .gno file if necessary
 	if strings.HasSuffix(filename, ".gno") {
-		for i := range allIssues {
-			allIssues[i].Filename = filename
-		}
+		allIssues = renameFile(allIssues, tempFile, filename)
 	}
 
+	kept := e.suppressionList.filter(filename, allIssues, func() ([]string, error) {
+		source, err := ReadSourceCode(filename)
+		if err != nil {
+			return nil, err
+		}
+		return source.Lines, nil
+	})
+	e.suppressions.AddSuppressed(allIssues, kept)
+	allIssues = kept
+
+	e.messages.Translate(allIssues)
+	sortIssues(allIssues)
 	return allIssues, nil
 }
 
-// Run applies all lint rules to the given source and returns a slice of Issues.
+// cachedFile stands for the parsed file in the issues of the result cache,
+// since the temporary file of a .gno file is named anew on every run.
+const cachedFile = "\x00file"
+
+// runs reports whether the rule is run, being neither ignored nor skipped.
+func (e *Engine) runs(r LintRule) bool {
+	return !e.ignoredRules[r.Name()] && e.skippedRules[r.Name()] == ""
+}
+
+// checkFile runs a rule on the parsed file, within the rule timeout.
+func (e *Engine) checkFile(ctx context.Context, r LintRule, filename, tempFile string, node *ast.File, fset *token.FileSet) ([]tt.Issue, error) {
+	ctx, cancel := e.ruleContext(ctx)
+	defer cancel()
+	if !r.IsPackageRule() {
+		return r.Check(ctx, tempFile, node, fset)
+	}
+	issues, err := e.runPackageRule(ctx, r, filename, node)
+	// nolint comments are indexed by the name of the parsed file
+	for i := range issues {
+		issues[i].Filename = tempFile
+	}
+	return issues, err
+}
+
+// ruleContext returns the context a rule runs with, bounded by the rule
+// timeout.
+func (e *Engine) ruleContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if e.ruleTimeout <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, e.ruleTimeout)
+}
+
+// cacheKey returns the key of the issues of the rules for a file in the
+// result cache, or an empty key without a cache. The issues depend on the
+// file, its package and the configuration of the rules.
+func (e *Engine) cacheKey(rules map[string]LintRule, filename string, node *ast.File) string {
+	if e.cache == nil {
+		return ""
+	}
+	abs, err := filepath.Abs(filename)
+	if err != nil {
+		return ""
+	}
+	content, err := os.ReadFile(filename)
+	if err != nil {
+		return ""
+	}
+	pkgDigest, err := e.packages.digest(filepath.Dir(filename))
+	if err != nil {
+		return ""
+	}
+
+	parts := [][]byte{[]byte(abs), content, []byte(node.Name.Name), pkgDigest}
+	names := make([]string, 0, len(rules))
+	for name, rule := range rules {
+		if e.runs(rule) && !rule.uncached {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		parts = append(parts, []byte(name), []byte(rules[name].config))
+	}
+	return e.cache.Key(parts...)
+}
+
+// renameFile returns a copy of the issues where the positions in the file
+// from are in the file to instead.
+func renameFile(issues []tt.Issue, from, to string) []tt.Issue {
+	rename := func(pos *token.Position) {
+		if pos.Filename == from {
+			pos.Filename = to
+		}
+	}
+	renamed := make([]tt.Issue, len(issues))
+	for i, issue := range issues {
+		if issue.Filename == from {
+			issue.Filename = to
+		}
+		rename(&issue.Start)
+		rename(&issue.End)
+		issue.Related = append([]tt.Location(nil), issue.Related...)
+		for j := range issue.Related {
+			rename(&issue.Related[j].Position)
+		}
+		issue.SuggestedFix = append([]tt.TextEdit(nil), issue.SuggestedFix...)
+		for j := range issue.SuggestedFix {
+			rename(&issue.SuggestedFix[j].Start)
+			rename(&issue.SuggestedFix[j].End)
+		}
+		renamed[i] = issue
+	}
+	return renamed
+}
+
+// RunSource applies all lint rules to the given source and returns a slice of Issues.
 func (e *Engine) RunSource(source []byte) ([]tt.Issue, error) {
+	return e.RunSourceContext(context.Background(), source)
+}
+
+// RunSourceContext is RunSource, giving up on the rules still running once
+// ctx is done, in which case it returns the error of ctx.
+func (e *Engine) RunSourceContext(ctx context.Context, source []byte) ([]tt.Issue, error) {
 	node, fset, err := lints.ParseFile("", source)
 	if err != nil {
 		return nil, fmt.Errorf("error parsing content: %w", err)
 	}
 
-	e.nolintMgr = nolint.ParseComments(node, fset)
+	nolintMgr := nolint.ParseComments(node, fset)
+	e.integrationsOnce.Do(e.checkIntegrations)
 
 	var wg sync.WaitGroup
 	var mu sync.Mutex
 
 	var allIssues []tt.Issue
+	// ran records the rules that checked the file, whose directives can be
+	// told unused
+	ran := make(map[string]bool)
 	for _, rule := range e.rules {
 		wg.Add(1)
 		go func(r LintRule) {
 			defer wg.Done()
-			if e.ignoredRules[r.Name()] {
+			if !e.runs(r) {
 				return
 			}
-			issues, err := r.Check("", node, fset)
+			ruleCtx, cancel := e.ruleContext(ctx)
+			defer cancel()
+			var issues []tt.Issue
+			var err error
+			if r.IsPackageRule() {
+				issues, err = runPackageRuleOnSource(ruleCtx, r, "", node, fset)
+			} else {
+				issues, err = r.Check(ruleCtx, "", node, fset)
+			}
 			if err != nil {
+				if ctx.Err() == nil {
+					e.observer.OnRuleError("", r.Name(), err)
+				}
 				return
 			}
 
-			nolinted := e.filterNolintIssues(issues)
+			nolinted := filterNolintIssues(nolintMgr, issues)
+			e.suppressions.Add(issues, nolinted)
 			noIgnoredPaths := e.filterIgnoredPaths(nolinted)
 
 			mu.Lock()
 			allIssues = append(allIssues, noIgnoredPaths...)
+			ran[r.Name()] = true
 			mu.Unlock()
 		}(rule)
 	}
 	wg.Wait()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	allIssues = append(allIssues, e.unusedIgnoreIssues(nolintMgr, ran)...)
 
+	e.messages.Translate(allIssues)
+	sortIssues(allIssues)
 	return allIssues, nil
 }
 
@@ -166,10 +438,70 @@ func (e *Engine) IgnoreRule(rule string) {
 	e.ignoredRules[rule] = true
 }
 
+// EnableOnly restricts the engine to the named rules. Those that are off by
+// default are turned on with their usual severity, and the configured
+// options are kept.
+func (e *Engine) EnableOnly(names ...string) error {
+	only := make(map[string]bool, len(names))
+	for _, name := range names {
+		if _, ok := allRules.lookup(name); !ok {
+			return fmt.Errorf("unknown rule %q (expected one of %s)", name, strings.Join(sortedRuleNames(), ", "))
+		}
+		only[name] = true
+	}
+
+	config := make(map[string]tt.ConfigRule, len(e.config)+len(names))
+	for name, rule := range e.config {
+		config[name] = rule
+	}
+	for name := range only {
+		if _, enabled := e.rules[name]; !enabled {
+			rule := config[name]
+			known, _ := allRules.lookup(name)
+			rule.Severity = enabledSeverity(known)
+			config[name] = rule
+		}
+	}
+	for _, name := range allRules.names() {
+		if !only[name] {
+			e.IgnoreRule(name)
+		}
+	}
+
+	e.dirConfigs = newDirConfigCache()
+	e.packages = newPackageCache()
+	e.applyRules(config)
+	return nil
+}
+
 func (e *Engine) IgnorePath(path string) {
 	e.ignoredPaths = append(e.ignoredPaths, path)
 }
 
+// pathGlob is a glob matched against the paths relative to dir.
+type pathGlob struct {
+	dir     string
+	pattern string
+}
+
+// Exclude leaves the files matching the globs out of the run: they are not
+// parsed and yield no issue. The globs are relative to dir, and `**` stands
+// for any number of directories, as in the suppression list.
+func (e *Engine) Exclude(dir string, patterns ...string) {
+	for _, pattern := range patterns {
+		e.excludes = append(e.excludes, pathGlob{dir: dir, pattern: normalizeSlashes(pattern)})
+	}
+}
+
+func (e *Engine) isExcluded(filename string) bool {
+	for _, glob := range e.excludes {
+		if matchGlob(glob.pattern, relativeTo(glob.dir, filename)) {
+			return true
+		}
+	}
+	return false
+}
+
 func (e *Engine) prepareFile(filename string) (string, error) {
 	if strings.HasSuffix(filename, ".gno") {
 		return createTempGoFile(filename)
@@ -195,10 +527,7 @@ func (e *Engine) filterIgnoredPaths(issues []tt.Issue) []tt.Issue {
 
 func (e *Engine) isIgnoredPath(path string) bool {
 	for _, ignored := range e.ignoredPaths {
-		res, err := filepath.Match(ignored, path)
-		fmt.Println("res:", res, err, ignored, path)
-		if err == nil && res {
-			fmt.Println("Ignoring path:", path)
+		if res, err := filepath.Match(ignored, path); err == nil && res {
 			return true
 		}
 	}
@@ -206,8 +535,8 @@ func (e *Engine) isIgnoredPath(path string) bool {
 }
 
 // filterNolintIssues filters issues based on nolint comments.
-func (e *Engine) filterNolintIssues(issues []tt.Issue) []tt.Issue {
-	if e.nolintMgr == nil {
+func filterNolintIssues(nolintMgr *nolint.Manager, issues []tt.Issue) []tt.Issue {
+	if nolintMgr == nil {
 		return issues
 	}
 	filtered := make([]tt.Issue, 0, len(issues))
@@ -216,13 +545,61 @@ func (e *Engine) filterNolintIssues(issues []tt.Issue) []tt.Issue {
 			Filename: issue.Filename,
 			Line:     issue.Start.Line,
 		}
-		if !e.nolintMgr.IsNolint(pos, issue.Rule) {
+		if !nolintMgr.IsNolint(pos, issue.Rule) {
 			filtered = append(filtered, issue)
 		}
 	}
 	return filtered
 }
 
+// unusedIgnoreIssues reports the //tlin:ignore comments that suppressed
+// nothing, when asked to. Comments naming a rule that did not check the file,
+// being off or ignored, are left alone. Names that are not tlin rules are
+// those of the linters run by golangci-lint.
+func (e *Engine) unusedIgnoreIssues(nolintMgr *nolint.Manager, ran map[string]bool) []tt.Issue {
+	if !e.reportUnusedIgnores {
+		return nil
+	}
+	var issues []tt.Issue
+	for _, directive := range nolintMgr.Unused() {
+		checked := ran[directive.Rule]
+		if _, known := allRules.lookup(directive.Rule); !known {
+			checked = ran[golangciLintRule]
+		}
+		if !checked {
+			continue
+		}
+		issues = append(issues, tt.Issue{
+			Rule:     unusedIgnoreRule,
+			Filename: directive.Pos.Filename,
+			Start:    directive.Pos,
+			End:      directive.Pos,
+			Message:  fmt.Sprintf("tlin:ignore of %s suppressed no issue", directive.Rule),
+			Note:     "remove the comment, the issue it silenced is gone",
+			Severity: tt.SeverityWarning,
+		})
+	}
+	return e.filterIgnoredPaths(issues)
+}
+
+// sortIssues orders issues by position then rule, so that the output does not
+// depend on the order in which the rules finished.
+func sortIssues(issues []tt.Issue) {
+	sort.SliceStable(issues, func(i, j int) bool {
+		a, b := issues[i], issues[j]
+		if a.Start.Line != b.Start.Line {
+			return a.Start.Line < b.Start.Line
+		}
+		if a.Start.Column != b.Start.Column {
+			return a.Start.Column < b.Start.Column
+		}
+		if a.Rule != b.Rule {
+			return a.Rule < b.Rule
+		}
+		return a.Message < b.Message
+	})
+}
+
 // createTempGoFile converts a .gno file to a .go file.
 // Since golangci-lint does not support .gno file, we need to convert it to .go file.
 // gno has a identical syntax to go, so it is possible to convert it to go file.
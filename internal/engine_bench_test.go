@@ -0,0 +1,143 @@
+package internal
+
+import (
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// Baseline on a single-core Intel Xeon, golangci-lint not installed:
+//
+//	BenchmarkEngineRun/files=300      2    534ms/op    134MB/op    996k allocs/op
+//	BenchmarkEngineRunSource        422    2.75ms/op   1.19MB/op    9.6k allocs/op
+//
+// Regenerate with:
+//
+//	go test ./internal -run '^$' -bench BenchmarkEngine -benchmem
+
+const (
+	corpusSeed  = 20240901
+	corpusFiles = 300
+)
+
+// generateCorpus writes a reproducible set of synthetic source files of
+// varying size to dir, exercising the constructs the default rules look at.
+func generateCorpus(dir string, files int, seed int64) ([]string, error) {
+	rnd := rand.New(rand.NewSource(seed))
+
+	paths := make([]string, 0, files)
+	for i := 0; i < files; i++ {
+		ext := ".go"
+		if i%2 == 1 {
+			ext = ".gno"
+		}
+		path := filepath.Join(dir, fmt.Sprintf("pkg%02d", i%10), fmt.Sprintf("file%03d%s", i, ext))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			return nil, err
+		}
+		if err := os.WriteFile(path, []byte(generateFile(rnd, i)), 0o644); err != nil {
+			return nil, err
+		}
+		paths = append(paths, path)
+	}
+	return paths, nil
+}
+
+func generateFile(rnd *rand.Rand, index int) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "package pkg%02d\n\n", index%10)
+	sb.WriteString("import (\n\t\"errors\"\n\t\"fmt\"\n)\n\n")
+	fmt.Fprintf(&sb, "var errNotFound%d = errors.New(\"not found\")\n\n", index)
+
+	// file sizes range from a couple of functions to a few hundred lines
+	funcs := 2 + rnd.Intn(20)
+	for f := 0; f < funcs; f++ {
+		name := fmt.Sprintf("fn%d_%d", index, f)
+		switch rnd.Intn(5) {
+		case 0:
+			fmt.Fprintf(&sb, "func %s(x int) int {\n\tif x > 0 {\n\t\treturn x\n\t} else {\n\t\treturn -x\n\t}\n}\n\n", name)
+		case 1:
+			fmt.Fprintf(&sb, "func %s(s []int) []int {\n\treturn s[0:len(s)]\n}\n\n", name)
+		case 2:
+			fmt.Fprintf(&sb, "func %s(x int) string {\n\tswitch x {\n\tcase 1:\n\t\tbreak\n\tdefault:\n\t\treturn fmt.Sprintf(\"%%d\", x)\n\t}\n\treturn \"\"\n}\n\n", name)
+		case 3:
+			fmt.Fprintf(&sb, "func %s() error {\n\tdefer func() {\n\t\trecover()\n\t}()\n\treturn errNotFound%d\n}\n\n", name, index)
+		default:
+			fmt.Fprintf(&sb, "func %s(n int) int {\n\ttotal := 0\n", name)
+			for j := 0; j < 1+rnd.Intn(15); j++ {
+				fmt.Fprintf(&sb, "\tif n%%%d == 0 {\n\t\ttotal += int(int(n))\n\t}\n", j+2)
+			}
+			sb.WriteString("\treturn total\n}\n\n")
+		}
+	}
+	return sb.String()
+}
+
+func newBenchEngine(b *testing.B, rootDir string) *Engine {
+	b.Helper()
+	engine, err := NewEngine(rootDir, nil, nil)
+	if err != nil {
+		b.Fatal(err)
+	}
+	// golangci-lint spawns an external process per file and would dominate
+	// the measurement, so it is left out of the benchmarks.
+	engine.IgnoreRule(golangciLintRule)
+	return engine
+}
+
+func BenchmarkEngineRun(b *testing.B) {
+	dir := b.TempDir()
+	paths, err := generateCorpus(dir, corpusFiles, corpusSeed)
+	if err != nil {
+		b.Fatal(err)
+	}
+	engine := newBenchEngine(b, dir)
+
+	b.Run(fmt.Sprintf("files=%d", corpusFiles), func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			for _, path := range paths {
+				if _, err := engine.Run(path); err != nil {
+					b.Fatal(err)
+				}
+			}
+		}
+	})
+}
+
+func BenchmarkEngineRunSource(b *testing.B) {
+	source := []byte(generateFile(rand.New(rand.NewSource(corpusSeed)), 0))
+	engine := newBenchEngine(b, "")
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := engine.RunSource(source); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestGenerateCorpusIsReproducible(t *testing.T) {
+	t.Parallel()
+
+	first, second := t.TempDir(), t.TempDir()
+	a, err := generateCorpus(first, 20, corpusSeed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := generateCorpus(second, 20, corpusSeed)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := range a {
+		contentA, _ := os.ReadFile(a[i])
+		contentB, _ := os.ReadFile(b[i])
+		if string(contentA) != string(contentB) {
+			t.Fatalf("file %d differs between runs with the same seed", i)
+		}
+	}
+}
@@ -1,12 +1,17 @@
 package internal
 
 import (
+	"go/ast"
+	"go/token"
 	"os"
 	"path/filepath"
 	"runtime"
 	"strings"
 	"testing"
 
+	"github.com/gnolang/tlin/internal/cache"
+	"github.com/gnolang/tlin/internal/calibration"
+	"github.com/gnolang/tlin/internal/lints"
 	"github.com/gnolang/tlin/internal/types"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -55,7 +60,7 @@ func TestNewEngineConfig(t *testing.T) {
 		}
 	}
 
-	assert.True(t, engine.ignoredRules["useless-break"])
+	assert.NotContains(t, engine.rules, "useless-break")
 }
 
 func TestNewEngineContent(t *testing.T) {
@@ -231,6 +236,213 @@ func BenchmarkRun(b *testing.B) {
 	}
 }
 
+func TestEngineExclude(t *testing.T) {
+	t.Parallel()
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, "p", "demo", "a.gno"), "package demo\n\nfunc f() {\n\tswitch 1 {\n\tcase 1:\n\t\tbreak\n\t}\n}\n")
+	writeFile(t, filepath.Join(root, "p", "demo", "testdata", "b.gno"), "package demo\n\nfunc f( {\n")
+
+	engine, err := NewEngine(root, nil, nil)
+	require.NoError(t, err)
+	engine.Exclude(root, "**/testdata/**", `p\demo\a.gno`)
+
+	for _, name := range []string{filepath.Join("p", "demo", "a.gno"), filepath.Join("p", "demo", "testdata", "b.gno")} {
+		// excluded files are not even parsed
+		issues, err := engine.Run(filepath.Join(root, name))
+		require.NoError(t, err, name)
+		assert.Empty(t, issues, name)
+	}
+
+	engine, err = NewEngine(root, nil, nil)
+	require.NoError(t, err)
+	engine.Exclude(filepath.Join(root, "p"), "demo/testdata/*.gno")
+	issues, err := engine.Run(filepath.Join(root, "p", "demo", "a.gno"))
+	require.NoError(t, err)
+	assert.NotEmpty(t, issues)
+	_, err = engine.Run(filepath.Join(root, "p", "demo", "testdata", "b.gno"))
+	assert.NoError(t, err)
+}
+
+func TestEngineCountSuppressions(t *testing.T) {
+	t.Parallel()
+
+	tempDir := createTempDir(t, "suppressions_test")
+	filename := filepath.Join(tempDir, "a.gno")
+	content := `package a
+
+func f() {
+	_ = 1 //nolint:noisy
+	_ = 2
+}
+`
+	require.NoError(t, os.WriteFile(filename, []byte(content), 0o644))
+
+	engine, err := NewEngine(tempDir, nil, nil)
+	require.NoError(t, err)
+	engine.rules = map[string]LintRule{
+		"noisy": {
+			name: "noisy",
+			check: func(filename string, node *ast.File, fset *token.FileSet, _ types.Severity) ([]types.Issue, error) {
+				var issues []types.Issue
+				ast.Inspect(node, func(n ast.Node) bool {
+					if assign, ok := n.(*ast.AssignStmt); ok {
+						issues = append(issues, types.Issue{
+							Rule:     "noisy",
+							Filename: filename,
+							Start:    fset.Position(assign.Pos()),
+							End:      fset.Position(assign.End()),
+						})
+					}
+					return true
+				})
+				return issues, nil
+			},
+		},
+	}
+
+	// suppressions are only counted on demand
+	_, err = engine.Run(filename)
+	require.NoError(t, err)
+	assert.Nil(t, engine.SuppressionCounts())
+
+	engine.CountSuppressions()
+	issues, err := engine.Run(filename)
+	require.NoError(t, err)
+	assert.Len(t, issues, 1)
+	assert.Equal(t, map[string]calibration.Counts{
+		"noisy": {Found: 2, Suppressed: 1},
+	}, engine.SuppressionCounts())
+}
+
+func TestEngineIgnoreDirectives(t *testing.T) {
+	t.Parallel()
+	root := t.TempDir()
+	filename := filepath.Join(root, "a.gno")
+	writeFile(t, filename, `package demo
+
+func f() {
+	switch 1 {
+	case 1:
+		break //tlin:ignore useless-break kept for readability
+	}
+	//tlin:ignore useless-break nothing to see
+	_ = 1
+	//tlin:ignore early-return-opportunity the rule does not run
+	_ = 2
+	//tlin:ignore errcheck golangci-lint does not run
+	_ = 3
+}
+`)
+
+	engine, err := NewEngine(root, nil, nil)
+	require.NoError(t, err)
+	require.NoError(t, engine.EnableOnly("useless-break"))
+
+	issues, err := engine.Run(filename)
+	require.NoError(t, err)
+	assert.Empty(t, issues)
+
+	engine.ReportUnusedIgnores()
+	issues, err = engine.Run(filename)
+	require.NoError(t, err)
+	require.Len(t, issues, 1)
+	assert.Equal(t, unusedIgnoreRule, issues[0].Rule)
+	assert.Equal(t, filename, issues[0].Filename)
+	assert.Equal(t, 8, issues[0].Start.Line)
+	assert.Equal(t, "tlin:ignore of useless-break suppressed no issue", issues[0].Message)
+}
+
+func TestEngineGnoRelatedPositions(t *testing.T) {
+	t.Parallel()
+	root := t.TempDir()
+	filename := filepath.Join(root, "a.gno")
+	writeFile(t, filename, `package demo
+
+func describe(x interface{}) string {
+	if _, ok := x.(int); ok {
+		return "int"
+	} else if _, ok := x.(string); ok {
+		return "string"
+	} else if _, ok := x.(bool); ok {
+		return "bool"
+	}
+	return "unknown"
+}
+`)
+
+	engine, err := NewEngine(root, nil, nil)
+	require.NoError(t, err)
+	require.NoError(t, engine.EnableOnly("type-assertion-chain"))
+
+	issues, err := engine.Run(filename)
+	require.NoError(t, err)
+	require.Len(t, issues, 1)
+	// the positions point at the .gno file, not at the temporary file linted
+	assert.Equal(t, filename, issues[0].Start.Filename)
+	require.NotEmpty(t, issues[0].Related)
+	for _, related := range issues[0].Related {
+		assert.Equal(t, filename, related.Position.Filename)
+	}
+}
+
+func TestEngineResultCache(t *testing.T) {
+	t.Parallel()
+	root := t.TempDir()
+	filename := filepath.Join(root, "a.gno")
+	source := `package demo
+
+func f() {
+	switch 1 {
+	case 1:
+		break
+	case 2:
+		break //tlin:ignore useless-break kept for readability
+	}
+}
+`
+	writeFile(t, filename, source)
+
+	c, err := cache.Open(t.TempDir())
+	require.NoError(t, err)
+	engine, err := NewEngine(root, nil, nil)
+	require.NoError(t, err)
+	require.NoError(t, engine.EnableOnly("useless-break"))
+	engine.SetCache(c)
+
+	issues, err := engine.Run(filename)
+	require.NoError(t, err)
+	require.Len(t, issues, 1)
+
+	rules, err := engine.rulesFor(filename)
+	require.NoError(t, err)
+	node, _, err := lints.ParseFile(filename, nil)
+	require.NoError(t, err)
+	key := engine.cacheKey(rules, filename, node)
+	cached, ok := c.Get(key)
+	require.True(t, ok)
+	// the ignored issue is cached too, the directive is applied on reuse
+	require.Len(t, cached["useless-break"], 2)
+
+	// the next run reuses the cached issues
+	for i := range cached["useless-break"] {
+		cached["useless-break"][i].Message = "from the cache"
+	}
+	require.NoError(t, c.Put(key, cached))
+	issues, err = engine.Run(filename)
+	require.NoError(t, err)
+	require.Len(t, issues, 1)
+	assert.Equal(t, "from the cache", issues[0].Message)
+	assert.Equal(t, filename, issues[0].Filename)
+	assert.Equal(t, 6, issues[0].Start.Line)
+
+	// changing the file misses the cache
+	writeFile(t, filename, source+"\nvar x = 1\n")
+	issues, err = engine.Run(filename)
+	require.NoError(t, err)
+	require.Len(t, issues, 1)
+	assert.NotEqual(t, "from the cache", issues[0].Message)
+}
+
 func createTempDir(tb testing.TB, prefix string) string {
 	tb.Helper()
 	tempDir, err := os.MkdirTemp("", prefix)
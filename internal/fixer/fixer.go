@@ -6,8 +6,6 @@ import (
 	"go/format"
 	"go/parser"
 	"go/token"
-	"os"
-	"sort"
 	"strings"
 
 	tt "github.com/gnolang/tlin/internal/types"
@@ -33,35 +31,37 @@ func New(dryRun bool, threshold float64) *Fixer {
 }
 
 // Fix applies fixes to the given file based on the provided issues.
+// Fixes conflicting with another one are skipped, see Patch. The file is
+// formatted, and written even without any fix to apply.
 func (f *Fixer) Fix(filename string, issues []tt.Issue) error {
-	content, err := os.ReadFile(filename)
+	patch, err := f.Patch(filename, issues)
 	if err != nil {
-		return fmt.Errorf("failed to read file: %w", err)
+		return err
 	}
 
-	lines := strings.Split(string(content), "\n")
-	sortIssuesByEndOffset(issues)
-
+	byID := make(map[string]tt.Issue, len(issues))
 	for _, issue := range issues {
-		if issue.Confidence < f.MinConfidence {
-			continue
-		}
-
-		if f.DryRun {
-			f.printDryRunInfo(filename, issue)
-			continue
-		}
-
-		lines = f.applyFix(lines, issue)
+		byID[issue.ID()] = issue
+	}
+	for _, conflict := range patch.Conflicts {
+		fmt.Printf("Skipped fix of %s in %s at line %d: %s\n", conflict.Rule, filename, conflict.StartLine, conflict)
 	}
 
-	if !f.DryRun {
-		if err := f.writeFixedContent(filename, lines); err != nil {
-			return err
+	if f.DryRun {
+		seen := make(map[string]bool)
+		for _, edit := range patch.Edits {
+			if !seen[edit.IssueID] {
+				seen[edit.IssueID] = true
+				f.printDryRunInfo(filename, byID[edit.IssueID])
+			}
 		}
-		fmt.Printf("Fixed issues in %s\n", filename)
+		return nil
 	}
 
+	if err := patch.Apply(); err != nil {
+		return err
+	}
+	fmt.Printf("Fixed issues in %s\n", filename)
 	return nil
 }
 
@@ -70,49 +70,26 @@ func (f *Fixer) printDryRunInfo(filename string, issue tt.Issue) {
 	fmt.Printf("Suggestion:\n%s\n", issue.Suggestion)
 }
 
-func (f *Fixer) applyFix(lines []string, issue tt.Issue) []string {
-	startLine := issue.Start.Line - 1
-	endLine := issue.End.Line - 1
-
-	indent := extractIndent(lines[startLine])
-	suggestion := applyIndent(issue.Suggestion, indent, issue.Start)
-
-	return append(lines[:startLine], append([]string{suggestion}, lines[endLine+1:]...)...)
+// replacement returns the indented suggestion replacing the lines of the issue.
+func replacement(lines []string, issue tt.Issue) string {
+	indent := extractIndent(lines[issue.Start.Line-1])
+	return applyIndent(issue.Suggestion, indent, issue.Start)
 }
 
-func (f *Fixer) writeFixedContent(filename string, lines []string) error {
-	f.buffer.Reset()
-	for i, line := range lines {
-		f.buffer.WriteString(line)
-		if i < len(lines)-1 {
-			f.buffer.WriteByte('\n')
-		}
-	}
-
+// format formats the fixed content of a file, failing when the fixes left
+// it unparsable.
+func (f *Fixer) format(filename string, content []byte) ([]byte, error) {
 	fset := token.NewFileSet()
-	astFile, err := parser.ParseFile(fset, filename, f.buffer.Bytes(), parser.ParseComments)
+	astFile, err := parser.ParseFile(fset, filename, content, parser.ParseComments)
 	if err != nil {
-		return fmt.Errorf("failed to parse file: %w", err)
+		return nil, fmt.Errorf("fixed file does not parse: %w", err)
 	}
 
 	f.buffer.Reset()
 	if err := format.Node(&f.buffer, fset, astFile); err != nil {
-		return fmt.Errorf("failed to format file: %w", err)
-	}
-
-	if err := os.WriteFile(filename, f.buffer.Bytes(), defaultFilePermissions); err != nil {
-		return fmt.Errorf("failed to write file: %w", err)
+		return nil, fmt.Errorf("failed to format file: %w", err)
 	}
-
-	return nil
-}
-
-// sorts the issues by the end offset of the issue.
-// By doing this, we ensure that the issues are applied in the correct order.
-func sortIssuesByEndOffset(issues []tt.Issue) {
-	sort.Slice(issues, func(i, j int) bool {
-		return issues[i].End.Offset > issues[j].End.Offset
-	})
+	return bytes.Clone(f.buffer.Bytes()), nil
 }
 
 // extractIndent extracts the indentation from the first line of the issue.
@@ -45,6 +45,35 @@ func main() {
 	slice := []int{1, 2, 3}
 	_ = slice[:]
 }
+`,
+		},
+		{
+			name: "Fix - Remove duplicate import",
+			input: `package main
+
+import (
+	foo "gno.land/p/demo/avl"
+	tree "gno.land/p/demo/avl"
+)
+
+var t = tree.NewTree()
+`,
+			issues: []tt.Issue{
+				{
+					Rule:       "duplicate-import",
+					Message:    "gno.land/p/demo/avl is imported 2 times, as foo, tree; only tree is used",
+					Start:      token.Position{Line: 4, Column: 2},
+					End:        token.Position{Line: 4, Column: 27},
+					Confidence: 0.9,
+				},
+			},
+			expected: `package main
+
+import (
+	tree "gno.land/p/demo/avl"
+)
+
+var t = tree.NewTree()
 `,
 		},
 		{
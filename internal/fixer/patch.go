@@ -0,0 +1,268 @@
+package fixer
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	tt "github.com/gnolang/tlin/internal/types"
+	"github.com/pmezard/go-difflib/difflib"
+)
+
+// Edit replaces a range of a file with the fix of an issue. Lines and
+// columns are 1-based, columns counting bytes; the end column and the end
+// offset, a byte offset into the original file, exclude the last byte of the
+// range. Fixes replacing the lines of their issue cover them whole, without
+// the newline of the last line.
+type Edit struct {
+	Rule        string `json:"rule"`
+	IssueID     string `json:"issue_id"`
+	StartLine   int    `json:"start_line"`
+	StartColumn int    `json:"start_column"`
+	EndLine     int    `json:"end_line"`
+	EndColumn   int    `json:"end_column"`
+	StartOffset int    `json:"start_offset"`
+	EndOffset   int    `json:"end_offset"`
+	Replacement string `json:"replacement"`
+}
+
+// overlaps reports whether two edits touch the same text. Insertions at the
+// same offset overlap too, since the order of the inserted texts is unknown.
+func (e Edit) overlaps(other Edit) bool {
+	if e.StartOffset == e.EndOffset && other.StartOffset == other.EndOffset {
+		return e.StartOffset == other.StartOffset
+	}
+	return e.StartOffset < other.EndOffset && other.StartOffset < e.EndOffset
+}
+
+// Conflict is a fix left out because it overlaps the fix of another issue.
+type Conflict struct {
+	Rule        string `json:"rule"`
+	IssueID     string `json:"issue_id"`
+	StartLine   int    `json:"start_line"`
+	WithRule    string `json:"with_rule"`
+	WithIssueID string `json:"with_issue_id"`
+}
+
+func (c Conflict) String() string {
+	return fmt.Sprintf("conflicts with the fix of %s", c.WithRule)
+}
+
+// Patch holds the fixes of a file, computed without modifying it.
+type Patch struct {
+	Filename string `json:"file"`
+	// Edits are listed in the order Fix applies them, from the end of the file.
+	Edits []Edit `json:"edits"`
+	// Conflicts lists the fixes left out of the edits.
+	Conflicts []Conflict `json:"conflicts,omitempty"`
+
+	original []byte
+	fixed    []byte
+}
+
+// Patch computes the fixes Fix would apply to the file, without writing it.
+//
+// The fix of an issue is its SuggestedFix, or else the replacement of its
+// lines with its Suggestion. Only the issues whose confidence reaches the
+// threshold are fixed, by position: a fix overlapping one already taken is
+// recorded as a conflict instead. The fixed content is formatted as Fix
+// formats it, and must parse.
+func (f *Fixer) Patch(filename string, issues []tt.Issue) (*Patch, error) {
+	content, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+	lines := strings.Split(string(content), "\n")
+	offsets := lineOffsets(lines)
+
+	type fix struct {
+		issue tt.Issue
+		edits []Edit
+	}
+	var fixes []fix
+	for _, issue := range issues {
+		if issue.Confidence < f.MinConfidence {
+			continue
+		}
+		edits, err := issueEdits(issue, content, lines, offsets)
+		if err != nil {
+			return nil, fmt.Errorf("invalid fix of %s at line %d: %w", issue.Rule, issue.Start.Line, err)
+		}
+		fixes = append(fixes, fix{issue: issue, edits: edits})
+	}
+	sort.SliceStable(fixes, func(i, j int) bool {
+		return fixes[i].edits[0].StartOffset < fixes[j].edits[0].StartOffset
+	})
+
+	patch := &Patch{Filename: filename, original: content}
+	owners := make(map[string]string) // issue ID to rule of the edits taken
+	for _, candidate := range fixes {
+		if taken, ok := findOverlap(patch.Edits, candidate.edits); ok {
+			patch.Conflicts = append(patch.Conflicts, Conflict{
+				Rule:        candidate.issue.Rule,
+				IssueID:     candidate.edits[0].IssueID,
+				StartLine:   candidate.edits[0].StartLine,
+				WithRule:    owners[taken.IssueID],
+				WithIssueID: taken.IssueID,
+			})
+			continue
+		}
+		owners[candidate.edits[0].IssueID] = candidate.issue.Rule
+		patch.Edits = append(patch.Edits, candidate.edits...)
+	}
+	sort.SliceStable(patch.Edits, func(i, j int) bool {
+		return patch.Edits[i].StartOffset > patch.Edits[j].StartOffset
+	})
+
+	fixed := bytes.Clone(content)
+	for _, edit := range patch.Edits {
+		fixed = append(fixed[:edit.StartOffset], append([]byte(edit.Replacement), fixed[edit.EndOffset:]...)...)
+	}
+	patch.fixed, err = f.format(filename, fixed)
+	if err != nil {
+		return nil, err
+	}
+	return patch, nil
+}
+
+// issueEdits returns the edits fixing an issue, sorted by offset.
+func issueEdits(issue tt.Issue, content []byte, lines []string, offsets []int) ([]Edit, error) {
+	id := issue.ID()
+	if len(issue.SuggestedFix) == 0 {
+		start, end := issue.Start.Line, issue.End.Line
+		if start < 1 || end < start || end > len(lines) {
+			return nil, fmt.Errorf("lines %d to %d out of the file", start, end)
+		}
+		return []Edit{{
+			Rule:        issue.Rule,
+			IssueID:     id,
+			StartLine:   start,
+			StartColumn: 1,
+			EndLine:     end,
+			EndColumn:   len(lines[end-1]) + 1,
+			StartOffset: offsets[start-1],
+			EndOffset:   offsets[end-1] + len(lines[end-1]),
+			Replacement: replacement(lines, issue),
+		}}, nil
+	}
+
+	edits := make([]Edit, 0, len(issue.SuggestedFix))
+	for _, textEdit := range issue.SuggestedFix {
+		start, end := textEdit.Start.Offset, textEdit.End.Offset
+		if start < 0 || end < start || end > len(content) {
+			return nil, fmt.Errorf("offsets %d to %d out of the file", start, end)
+		}
+		edit := Edit{
+			Rule:        issue.Rule,
+			IssueID:     id,
+			StartOffset: start,
+			EndOffset:   end,
+			Replacement: textEdit.NewText,
+		}
+		edit.StartLine, edit.StartColumn = position(offsets, start)
+		edit.EndLine, edit.EndColumn = position(offsets, end)
+		edits = append(edits, edit)
+	}
+	sort.Slice(edits, func(i, j int) bool {
+		return edits[i].StartOffset < edits[j].StartOffset
+	})
+	for i := 1; i < len(edits); i++ {
+		if edits[i].overlaps(edits[i-1]) {
+			return nil, errors.New("overlapping edits")
+		}
+	}
+	return edits, nil
+}
+
+// findOverlap returns an edit of taken overlapping one of edits.
+func findOverlap(taken, edits []Edit) (Edit, bool) {
+	for _, edit := range edits {
+		for _, other := range taken {
+			if edit.overlaps(other) {
+				return other, true
+			}
+		}
+	}
+	return Edit{}, false
+}
+
+// lineOffsets returns the offset of the start of each line.
+func lineOffsets(lines []string) []int {
+	offsets := make([]int, len(lines))
+	for i := 1; i < len(lines); i++ {
+		offsets[i] = offsets[i-1] + len(lines[i-1]) + 1
+	}
+	return offsets
+}
+
+// position returns the line and the column of an offset.
+func position(offsets []int, offset int) (line, column int) {
+	line = sort.Search(len(offsets), func(i int) bool { return offsets[i] > offset })
+	return line, offset - offsets[line-1] + 1
+}
+
+// Fixed returns the content of the file once fixed, as Fix would write it.
+func (p *Patch) Fixed() []byte {
+	return p.fixed
+}
+
+// Apply writes the fixed content to the file. It is written to a temporary
+// file renamed over the original, so that the file is never left half
+// written, and keeps the permissions of the original.
+func (p *Patch) Apply() error {
+	mode := os.FileMode(defaultFilePermissions)
+	if info, err := os.Stat(p.Filename); err == nil {
+		mode = info.Mode().Perm()
+	}
+
+	temp, err := os.CreateTemp(filepath.Dir(p.Filename), "."+filepath.Base(p.Filename)+".tlin-*")
+	if err != nil {
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+	defer os.Remove(temp.Name())
+
+	if _, err := temp.Write(p.fixed); err != nil {
+		temp.Close()
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+	if err := temp.Chmod(mode); err != nil {
+		temp.Close()
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+	if err := temp.Close(); err != nil {
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+	if err := os.Rename(temp.Name(), p.Filename); err != nil {
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+	return nil
+}
+
+// UnifiedDiff renders the changes between the file and its fixed content as
+// a unified diff.
+func (p *Patch) UnifiedDiff() (string, error) {
+	return difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        splitLines(p.original),
+		B:        splitLines(p.fixed),
+		FromFile: p.Filename,
+		ToFile:   p.Filename,
+		Context:  3,
+	})
+}
+
+// splitLines splits content after each newline. Unlike difflib.SplitLines, it
+// does not add a line for the final newline, which would show in the context.
+// A last line without newline carries the marker patch tools expect after it.
+func splitLines(content []byte) []string {
+	lines := strings.SplitAfter(string(content), "\n")
+	last := len(lines) - 1
+	if lines[last] == "" {
+		return lines[:last]
+	}
+	lines[last] += "\n\\ No newline at end of file\n"
+	return lines
+}
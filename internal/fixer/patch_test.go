@@ -0,0 +1,268 @@
+package fixer
+
+import (
+	"go/token"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tt "github.com/gnolang/tlin/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const patchExample = `package main
+
+import (
+	foo "gno.land/p/demo/avl"
+	tree "gno.land/p/demo/avl"
+)
+
+var t = tree.NewTree()
+
+func main() {
+    slice := []int{1, 2, 3}
+    _ = slice[:len(slice)]
+}`
+
+func patchIssues(filename string) []tt.Issue {
+	return []tt.Issue{
+		{
+			Rule:       "duplicate-import",
+			Filename:   filename,
+			Message:    "gno.land/p/demo/avl is imported 2 times, as foo, tree; only tree is used",
+			Start:      token.Position{Line: 4, Column: 2, Offset: 18},
+			End:        token.Position{Line: 4, Column: 27, Offset: 43},
+			Confidence: 0.9,
+		},
+		{
+			Rule:       "simplify-slice-range",
+			Filename:   filename,
+			Message:    "unnecessary use of len() in slice expression, can be simplified",
+			Start:      token.Position{Line: 12, Column: 9, Offset: 147},
+			End:        token.Position{Line: 12, Column: 26, Offset: 164},
+			Suggestion: "_ = slice[:]",
+			Confidence: 0.9,
+		},
+		{
+			Rule:       "early-return-opportunity",
+			Filename:   filename,
+			Message:    "below the threshold",
+			Start:      token.Position{Line: 10, Column: 1, Offset: 100},
+			End:        token.Position{Line: 13, Column: 1, Offset: 166},
+			Suggestion: "func main() {}",
+			Confidence: 0.5,
+		},
+	}
+}
+
+func TestPatch(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "main.gno")
+	require.NoError(t, os.WriteFile(filename, []byte(patchExample), 0o644))
+	issues := patchIssues(filename)
+
+	patch, err := New(false, confidenceThreshold).Patch(filename, issues)
+	require.NoError(t, err)
+
+	content, err := os.ReadFile(filename)
+	require.NoError(t, err)
+	assert.Equal(t, patchExample, string(content), "the file must not be modified")
+
+	assert.Equal(t, filename, patch.Filename)
+	assert.Equal(t, []Edit{
+		{
+			Rule:        "simplify-slice-range",
+			IssueID:     issues[1].ID(),
+			StartLine:   12,
+			StartColumn: 1,
+			EndLine:     12,
+			EndColumn:   27,
+			StartOffset: 147,
+			EndOffset:   173,
+			Replacement: "_ = slice[:]",
+		},
+		{
+			Rule:        "duplicate-import",
+			IssueID:     issues[0].ID(),
+			StartLine:   4,
+			StartColumn: 1,
+			EndLine:     4,
+			EndColumn:   27,
+			StartOffset: 23,
+			EndOffset:   49,
+			Replacement: "",
+		},
+	}, patch.Edits)
+	for _, edit := range patch.Edits {
+		original := patchExample[edit.StartOffset:edit.EndOffset]
+		assert.NotContains(t, original, "\n")
+	}
+}
+
+func TestPatchUnifiedDiffRoundTrip(t *testing.T) {
+	t.Parallel()
+	patchCmd, err := exec.LookPath("patch")
+	if err != nil {
+		t.Skip("patch is not installed")
+	}
+
+	for name, input := range map[string]string{
+		"without final newline": patchExample,
+		"with final newline":    patchExample + "\n",
+	} {
+		input := input
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			dir := t.TempDir()
+			exported := filepath.Join(dir, "exported.gno")
+			fixed := filepath.Join(dir, "fixed.gno")
+			require.NoError(t, os.WriteFile(exported, []byte(input), 0o644))
+			require.NoError(t, os.WriteFile(fixed, []byte(input), 0o644))
+
+			patch, err := New(false, confidenceThreshold).Patch(exported, patchIssues(exported))
+			require.NoError(t, err)
+			diff, err := patch.UnifiedDiff()
+			require.NoError(t, err)
+			assert.Contains(t, diff, "--- "+exported)
+
+			// what --fix writes
+			require.NoError(t, New(false, confidenceThreshold).Fix(fixed, patchIssues(fixed)))
+			want, err := os.ReadFile(fixed)
+			require.NoError(t, err)
+			assert.Equal(t, string(want), string(patch.Fixed()))
+
+			diffFile := filepath.Join(dir, "fix.diff")
+			require.NoError(t, os.WriteFile(diffFile, []byte(diff), 0o644))
+			out, err := exec.Command(patchCmd, "--batch", "--force", exported, diffFile).CombinedOutput()
+			require.NoError(t, err, string(out))
+
+			got, err := os.ReadFile(exported)
+			require.NoError(t, err)
+			assert.Equal(t, string(want), string(got))
+		})
+	}
+}
+
+const suggestedFixExample = `package main
+
+import "errors"
+
+const ErrNotFound = errors.New("not found")
+
+func main() {
+	x := 1
+	_ = x
+}
+`
+
+func TestPatchSuggestedFix(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "main.gno")
+	require.NoError(t, os.WriteFile(filename, []byte(suggestedFixExample), 0o600))
+
+	constOffset := strings.Index(suggestedFixExample, "const")
+	assignOffset := strings.Index(suggestedFixExample, "_ = x")
+	at := func(offset int) token.Position { return token.Position{Offset: offset} }
+	issues := []tt.Issue{
+		{
+			Rule:       "const-error-declaration",
+			Filename:   filename,
+			Start:      token.Position{Line: 5, Column: 1},
+			End:        token.Position{Line: 5, Column: 44},
+			Confidence: 1,
+			SuggestedFix: []tt.TextEdit{
+				{Start: at(constOffset), End: at(constOffset + len("const")), NewText: "var"},
+			},
+		},
+		{
+			// two edits applied together
+			Rule:       "rename",
+			Filename:   filename,
+			Start:      token.Position{Line: 8, Column: 2},
+			Confidence: 1,
+			SuggestedFix: []tt.TextEdit{
+				{Start: at(assignOffset + 4), End: at(assignOffset + 5), NewText: "y"},
+				{Start: at(assignOffset - 8), End: at(assignOffset - 7), NewText: "y"},
+			},
+		},
+		{
+			// overlaps the fix of const-error-declaration
+			Rule:       "other",
+			Filename:   filename,
+			Start:      token.Position{Line: 5, Column: 1},
+			End:        token.Position{Line: 5, Column: 44},
+			Suggestion: "var ErrNotFound = errors.New(\"missing\")",
+			Confidence: 1,
+		},
+	}
+
+	patch, err := New(false, confidenceThreshold).Patch(filename, issues)
+	require.NoError(t, err)
+	assert.Equal(t, Edit{
+		Rule:        "const-error-declaration",
+		IssueID:     issues[0].ID(),
+		StartLine:   5,
+		StartColumn: 1,
+		EndLine:     5,
+		EndColumn:   6,
+		StartOffset: constOffset,
+		EndOffset:   constOffset + len("const"),
+		Replacement: "var",
+	}, patch.Edits[len(patch.Edits)-1])
+	require.Len(t, patch.Edits, 3)
+	assert.Equal(t, []Conflict{{
+		Rule:        "other",
+		IssueID:     issues[2].ID(),
+		StartLine:   5,
+		WithRule:    "const-error-declaration",
+		WithIssueID: issues[0].ID(),
+	}}, patch.Conflicts)
+
+	want := strings.NewReplacer("const", "var", "x := 1", "y := 1", "_ = x", "_ = y").Replace(suggestedFixExample)
+	assert.Equal(t, want, string(patch.Fixed()))
+
+	require.NoError(t, patch.Apply())
+	content, err := os.ReadFile(filename)
+	require.NoError(t, err)
+	assert.Equal(t, want, string(content))
+	info, err := os.Stat(filename)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm(), "the permissions are kept")
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 1, "no temporary file is left behind")
+}
+
+func TestPatchInvalidFix(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "main.gno")
+	require.NoError(t, os.WriteFile(filename, []byte(suggestedFixExample), 0o644))
+	constOffset := strings.Index(suggestedFixExample, "const")
+
+	for name, edits := range map[string][]tt.TextEdit{
+		"unparsable result": {
+			{Start: token.Position{Offset: constOffset}, End: token.Position{Offset: constOffset + len("const")}, NewText: "func"},
+		},
+		"out of the file": {
+			{Start: token.Position{Offset: len(suggestedFixExample)}, End: token.Position{Offset: len(suggestedFixExample) + 1}},
+		},
+		"overlapping edits": {
+			{Start: token.Position{Offset: 0}, End: token.Position{Offset: 4}},
+			{Start: token.Position{Offset: 2}, End: token.Position{Offset: 6}},
+		},
+	} {
+		issue := tt.Issue{Rule: "broken", Filename: filename, Confidence: 1, SuggestedFix: edits}
+		_, err := New(false, confidenceThreshold).Patch(filename, []tt.Issue{issue})
+		assert.Error(t, err, name)
+	}
+
+	content, err := os.ReadFile(filename)
+	require.NoError(t, err)
+	assert.Equal(t, suggestedFixExample, string(content))
+}
@@ -0,0 +1,28 @@
+package internal
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/gnolang/tlin/internal/stdapi"
+)
+
+const stdAPIRule = "std-api"
+
+// SetGnoVersion selects the gno version whose std API the code is checked
+// against, which rule settings can still override. A version without a
+// bundled manifest falls back to the newest one, with a notice on stderr.
+func (e *Engine) SetGnoVersion(version string) {
+	if _, ok := stdapi.Lookup(version); !ok && version != "" {
+		latest := stdapi.Latest().Version
+		fmt.Fprintf(os.Stderr, "notice: no std API manifest for gno %s, checking against gno %s (known versions: %s)\n",
+			version, latest, strings.Join(stdapi.Versions(), ", "))
+		version = latest
+	}
+
+	e.gnoVersion = version
+	e.dirConfigs = newDirConfigCache()
+	e.packages = newPackageCache()
+	e.rules = newRuleSet(e.ruleSettings(nil))
+}
@@ -0,0 +1,481 @@
+package lints
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"strings"
+
+	tt "github.com/gnolang/tlin/internal/types"
+)
+
+const amountOverflowConfidence = 0.5
+
+// DefaultOverflowHelpers are the checked arithmetic helpers whose use
+// guards an operation, as `pkg.Func` or as a name alone for the functions
+// of the package.
+var DefaultOverflowHelpers = []string{
+	"safemath.Add",
+	"safemath.Mul",
+	"overflow.Add64",
+	"overflow.Add64p",
+	"overflow.Mul64",
+	"overflow.Mul64p",
+}
+
+// DetectAmountOverflows reports, in the exported functions and methods of
+// gno packages, integer multiplications, additions and left shifts with an
+// operand derived from a parameter whose result reaches a banker call, a
+// write to a package variable or a return statement. Integers wrap around
+// silently, so a crafted amount can turn a large fee or supply into a small
+// one.
+//
+// An operation is guarded when an enclosing or earlier terminating if
+// compares one of its operands to a bound such as `MaxInt64/b`, where b is
+// the other operand, or to a maximum constant, when one of
+// the helpers was called on the same operands before it, or when it is in
+// the body of a helper of the package. Values are followed through local
+// assignments in source order, the branches of the function are not told
+// apart.
+func DetectAmountOverflows(pkg *Package, severity tt.Severity, helpers []string) ([]tt.Issue, error) {
+	files, info, checked := typeCheck(pkg)
+	if checked == nil {
+		return nil, nil
+	}
+
+	var issues []tt.Issue
+	for _, file := range files {
+		for _, decl := range file.File.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok || fn.Body == nil || !fn.Name.IsExported() || isOverflowHelper(fn.Name.Name, helpers) {
+				continue
+			}
+			oc := &overflowChecker{
+				fn:       fn,
+				info:     info,
+				scope:    checked.Scope(),
+				helpers:  helpers,
+				origins:  make(map[types.Object]string),
+				flows:    make(map[types.Object][]*overflowFlow),
+				reported: make(map[ast.Node]bool),
+			}
+			for _, o := range oc.check() {
+				issues = append(issues, tt.Issue{
+					Rule:       "amount-overflow",
+					Filename:   file.Filename,
+					Start:      pkg.Fset.Position(o.op.Pos()),
+					End:        pkg.Fset.Position(o.op.End()),
+					Message:    o.message,
+					Suggestion: o.suggestion,
+					Note:       "integer arithmetic wraps around on overflow without any error, a crafted input can turn a large amount into a small one",
+					Confidence: amountOverflowConfidence,
+					Severity:   severity,
+				})
+			}
+		}
+	}
+	return issues, nil
+}
+
+// overflowFlow is the result of an unchecked operation and the path it
+// took from the parameter to the current variable.
+type overflowFlow struct {
+	op   ast.Node
+	x, y ast.Expr
+	tok  token.Token
+	typ  types.Type
+	path []string
+}
+
+type amountOverflow struct {
+	op         ast.Node
+	message    string
+	suggestion string
+}
+
+type overflowChecker struct {
+	fn      *ast.FuncDecl
+	info    *types.Info
+	scope   *types.Scope
+	helpers []string
+	// origins maps the variables derived from a parameter to its name.
+	origins map[types.Object]string
+	// flows maps the locals holding the result of unchecked operations to
+	// the operations.
+	flows    map[types.Object][]*overflowFlow
+	reported map[ast.Node]bool
+	found    []amountOverflow
+}
+
+func (oc *overflowChecker) check() []amountOverflow {
+	for _, field := range oc.fn.Type.Params.List {
+		for _, name := range field.Names {
+			if obj := oc.info.Defs[name]; obj != nil {
+				oc.origins[obj] = name.Name
+			}
+		}
+	}
+
+	ast.Inspect(oc.fn.Body, func(n ast.Node) bool {
+		switch node := n.(type) {
+		case *ast.FuncLit:
+			return false
+		case *ast.AssignStmt:
+			oc.assign(node)
+		case *ast.ValueSpec:
+			for i, name := range node.Names {
+				if i < len(node.Values) {
+					oc.store(name, node.Values[i])
+				}
+			}
+		case *ast.ReturnStmt:
+			for _, result := range node.Results {
+				for _, flow := range oc.carried(result) {
+					oc.report(flow, "returned")
+				}
+			}
+		case *ast.CallExpr:
+			if isBankerCall(node) {
+				for _, arg := range node.Args {
+					for _, flow := range oc.carried(arg) {
+						oc.report(flow, types.ExprString(node.Fun))
+					}
+				}
+			}
+		}
+		return true
+	})
+	return oc.found
+}
+
+func (oc *overflowChecker) assign(stmt *ast.AssignStmt) {
+	switch stmt.Tok {
+	case token.ADD_ASSIGN, token.MUL_ASSIGN, token.SHL_ASSIGN:
+		flows := oc.carried(stmt.Rhs[0])
+		if flow := oc.operation(stmt, stmt.Lhs[0], stmt.Rhs[0], assignOperator(stmt.Tok)); flow != nil {
+			flows = append(flows, flow)
+		}
+		if ident, ok := stmt.Lhs[0].(*ast.Ident); ok {
+			flows = append(flows, oc.flows[oc.object(ident)]...)
+		}
+		oc.sink(stmt.Lhs[0], flows)
+		return
+	}
+	if len(stmt.Lhs) != len(stmt.Rhs) {
+		return
+	}
+	for i, lhs := range stmt.Lhs {
+		oc.store(lhs, stmt.Rhs[i])
+	}
+}
+
+// store follows the value assigned to lhs: derivation from a parameter and
+// result of an unchecked operation.
+func (oc *overflowChecker) store(lhs ast.Expr, value ast.Expr) {
+	if ident, ok := lhs.(*ast.Ident); ok {
+		if obj := oc.object(ident); obj != nil {
+			if source := oc.origin(value); source != "" {
+				oc.origins[obj] = source
+			} else {
+				delete(oc.origins, obj)
+			}
+		}
+	}
+	oc.sink(lhs, oc.carried(value))
+}
+
+// sink reports the flows written to a package variable, and follows them
+// when written to a local, which no longer holds its previous flows.
+func (oc *overflowChecker) sink(lhs ast.Expr, flows []*overflowFlow) {
+	if state := packageRoot(lhs, oc.info, oc.scope); state != nil {
+		for _, flow := range flows {
+			oc.report(flow, state.Name())
+		}
+		return
+	}
+	ident, ok := lhs.(*ast.Ident)
+	if !ok {
+		return
+	}
+	obj := oc.object(ident)
+	if obj == nil {
+		return
+	}
+	var held []*overflowFlow
+	for _, flow := range flows {
+		next := *flow
+		if last := flow.path[len(flow.path)-1]; last != ident.Name {
+			next.path = append(append([]string(nil), flow.path...), ident.Name)
+		}
+		held = append(held, &next)
+	}
+	oc.flows[obj] = held
+}
+
+func (oc *overflowChecker) object(ident *ast.Ident) types.Object {
+	if obj := oc.info.Defs[ident]; obj != nil {
+		return obj
+	}
+	return oc.info.Uses[ident]
+}
+
+// origin returns the parameter expr derives from, if any.
+func (oc *overflowChecker) origin(expr ast.Expr) string {
+	source := ""
+	ast.Inspect(expr, func(n ast.Node) bool {
+		if ident, ok := n.(*ast.Ident); ok && source == "" {
+			source = oc.origins[oc.info.Uses[ident]]
+		}
+		return source == ""
+	})
+	return source
+}
+
+// carried returns the unchecked operations expr computes or uses the
+// result of. The operations nested in an unchecked one are left out, the
+// outer one is reported for them.
+func (oc *overflowChecker) carried(expr ast.Expr) []*overflowFlow {
+	var flows []*overflowFlow
+	nested := false
+	var visit func(n ast.Node) bool
+	visit = func(n ast.Node) bool {
+		switch node := n.(type) {
+		case *ast.FuncLit:
+			return false
+		case *ast.BinaryExpr:
+			switch node.Op {
+			case token.MUL, token.ADD, token.SHL:
+				if nested {
+					break
+				}
+				if flow := oc.operation(node, node.X, node.Y, node.Op); flow != nil {
+					flows = append(flows, flow)
+					nested = true
+					ast.Inspect(node.X, visit)
+					ast.Inspect(node.Y, visit)
+					nested = false
+					return false
+				}
+			}
+		case *ast.Ident:
+			flows = append(flows, oc.flows[oc.info.Uses[node]]...)
+		}
+		return true
+	}
+	ast.Inspect(expr, visit)
+	return flows
+}
+
+// operation returns the flow of an integer operation with an operand
+// derived from a parameter, unless it is guarded.
+func (oc *overflowChecker) operation(op ast.Node, x, y ast.Expr, tok token.Token) *overflowFlow {
+	typ := oc.integerType(x)
+	if typ == nil {
+		return nil
+	}
+	if tv, ok := oc.info.Types[x]; ok && tv.Value != nil {
+		if tv, ok := oc.info.Types[y]; ok && tv.Value != nil {
+			return nil
+		}
+	}
+	// prefer an operand that is itself a derived variable, balances[to]
+	// derives from to but is not chosen by the caller
+	var source string
+	var operand ast.Expr
+	for _, candidate := range []ast.Expr{x, y} {
+		if ident, ok := ast.Unparen(candidate).(*ast.Ident); ok && oc.origins[oc.info.Uses[ident]] != "" {
+			source, operand = oc.origins[oc.info.Uses[ident]], candidate
+			break
+		}
+	}
+	for _, candidate := range []ast.Expr{x, y} {
+		if source == "" {
+			source, operand = oc.origin(candidate), candidate
+		}
+	}
+	if source == "" || oc.guarded(op, x, y) {
+		return nil
+	}
+
+	var path []string
+	if name := types.ExprString(operand); name != source {
+		path = append(path, source)
+	}
+	path = append(path, types.ExprString(operand), operationText(x, y, tok))
+	return &overflowFlow{op: op, x: x, y: y, tok: tok, typ: typ, path: path}
+}
+
+// integerType returns the type of expr when it is an integer of at least 64
+// bits, the size of amounts.
+func (oc *overflowChecker) integerType(expr ast.Expr) types.Type {
+	tv, ok := oc.info.Types[expr]
+	if !ok || tv.Type == nil {
+		return nil
+	}
+	basic, ok := tv.Type.Underlying().(*types.Basic)
+	if !ok {
+		return nil
+	}
+	switch basic.Kind() {
+	case types.Int, types.Int64, types.Uint, types.Uint64:
+		return tv.Type
+	}
+	return nil
+}
+
+// guarded reports whether an overflow check or a helper call on the
+// operands protects op.
+func (oc *overflowChecker) guarded(op ast.Node, x, y ast.Expr) bool {
+	operands := []string{types.ExprString(ast.Unparen(x)), types.ExprString(ast.Unparen(y))}
+
+	path := pathTo(oc.fn.Body, op)
+	for i := 0; i < len(path)-1; i++ {
+		child := path[i+1]
+		switch parent := path[i].(type) {
+		case *ast.IfStmt:
+			if (child == parent.Body || child == parent.Else) && isOverflowCheck(parent.Cond, operands) {
+				return true
+			}
+		case *ast.BlockStmt:
+			for _, stmt := range parent.List {
+				if stmt == child {
+					break
+				}
+				if ifStmt, ok := stmt.(*ast.IfStmt); ok && isOverflowCheck(ifStmt.Cond, operands) && terminates(ifStmt.Body) {
+					return true
+				}
+			}
+		}
+	}
+
+	checked := false
+	ast.Inspect(oc.fn.Body, func(n ast.Node) bool {
+		if checked || n == nil || n.Pos() >= op.Pos() {
+			return false
+		}
+		if call, ok := n.(*ast.CallExpr); ok && isOverflowHelperCall(call, oc.helpers) && len(call.Args) >= 2 {
+			args := []string{types.ExprString(ast.Unparen(call.Args[0])), types.ExprString(ast.Unparen(call.Args[1]))}
+			checked = (args[0] == operands[0] && args[1] == operands[1]) || (args[0] == operands[1] && args[1] == operands[0])
+		}
+		return !checked
+	})
+	return checked
+}
+
+func (oc *overflowChecker) report(flow *overflowFlow, sink string) {
+	if oc.reported[flow.op] {
+		return
+	}
+	oc.reported[flow.op] = true
+
+	kind := map[token.Token]string{token.MUL: "multiplication", token.ADD: "addition", token.SHL: "shift"}[flow.tok]
+	path := append(append([]string(nil), flow.path...), sink)
+	oc.found = append(oc.found, amountOverflow{
+		op: flow.op,
+		message: fmt.Sprintf("unchecked %s %s can overflow %s: %s",
+			kind, operationText(flow.x, flow.y, flow.tok), types.TypeString(flow.typ, types.RelativeTo(nil)), strings.Join(path, " -> ")),
+		suggestion: overflowSuggestion(flow, oc.helpers),
+	})
+}
+
+// isOverflowCheck reports whether cond compares an operand to a bound
+// derived from the other one, such as `a > MaxInt64/b` or
+// `a > MaxUint64-b`, or to a maximum such as math.MaxInt64.
+func isOverflowCheck(cond ast.Expr, operands []string) bool {
+	found := false
+	ast.Inspect(cond, func(n ast.Node) bool {
+		bin, ok := n.(*ast.BinaryExpr)
+		if found || !ok {
+			return !found
+		}
+		switch bin.Op {
+		case token.LSS, token.LEQ, token.GTR, token.GEQ:
+			x, y := types.ExprString(ast.Unparen(bin.X)), types.ExprString(ast.Unparen(bin.Y))
+			for i, operand := range operands {
+				other := operands[1-i]
+				if (x == operand && isBound(bin.Y, other)) || (y == operand && isBound(bin.X, other)) {
+					found = true
+				}
+			}
+		}
+		return !found
+	})
+	return found
+}
+
+// isBound reports whether expr is a maximum, or a division, subtraction or
+// right shift involving other.
+func isBound(expr ast.Expr, other string) bool {
+	switch e := ast.Unparen(expr).(type) {
+	case *ast.Ident:
+		return isMaxName(e.Name)
+	case *ast.SelectorExpr:
+		return isMaxName(e.Sel.Name)
+	case *ast.BinaryExpr:
+		switch e.Op {
+		case token.QUO, token.SUB, token.SHR:
+			return types.ExprString(ast.Unparen(e.X)) == other || types.ExprString(ast.Unparen(e.Y)) == other
+		}
+	}
+	return false
+}
+
+func isMaxName(name string) bool {
+	return strings.HasPrefix(strings.ToLower(name), "max")
+}
+
+func isOverflowHelper(name string, helpers []string) bool {
+	for _, helper := range helpers {
+		if helper == name {
+			return true
+		}
+	}
+	return false
+}
+
+func isOverflowHelperCall(call *ast.CallExpr, helpers []string) bool {
+	for _, helper := range helpers {
+		if matchesCall(call, helper) {
+			return true
+		}
+	}
+	return false
+}
+
+// overflowSuggestion suggests the helper for the operation when one is
+// configured, and an explicit check otherwise.
+func overflowSuggestion(flow *overflowFlow, helpers []string) string {
+	x, y := types.ExprString(flow.x), types.ExprString(flow.y)
+	prefix := map[token.Token]string{token.MUL: "Mul", token.ADD: "Add", token.SHL: "Lsh"}[flow.tok]
+	for _, helper := range helpers {
+		if strings.HasPrefix(helper[strings.LastIndex(helper, ".")+1:], prefix) {
+			return fmt.Sprintf("%s(%s, %s)", helper, x, y)
+		}
+	}
+	limit := "math.MaxInt64"
+	if basic, ok := flow.typ.Underlying().(*types.Basic); ok && basic.Info()&types.IsUnsigned != 0 {
+		limit = "math.MaxUint64"
+	}
+	switch flow.tok {
+	case token.MUL:
+		return fmt.Sprintf("if %s != 0 && %s > %s/%s {\n\tpanic(\"amount overflow\")\n}", y, x, limit, y)
+	case token.ADD:
+		return fmt.Sprintf("if %s > %s-%s {\n\tpanic(\"amount overflow\")\n}", x, limit, y)
+	}
+	return fmt.Sprintf("if %s >= 63 {\n\tpanic(\"shift overflow\")\n}", y)
+}
+
+func operationText(x, y ast.Expr, tok token.Token) string {
+	return fmt.Sprintf("%s %s %s", types.ExprString(x), tok, types.ExprString(y))
+}
+
+func assignOperator(tok token.Token) token.Token {
+	switch tok {
+	case token.ADD_ASSIGN:
+		return token.ADD
+	case token.MUL_ASSIGN:
+		return token.MUL
+	}
+	return token.SHL
+}
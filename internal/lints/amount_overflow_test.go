@@ -0,0 +1,154 @@
+package lints
+
+import (
+	"testing"
+
+	tt "github.com/gnolang/tlin/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// The realistic contracts are in testdata/amount-overflow, these cover the
+// flow tracking and the guards.
+func TestDetectAmountOverflows(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name    string
+		src     string
+		helpers []string
+		lines   []int
+	}{
+		{
+			name: "flows followed to their sink",
+			src: `package token
+
+var supply int64
+
+func Grow(n int64) int64 {
+	doubled := n * 2
+	doubled = 0
+	scaled := n * 3
+	kept := scaled
+	supply = kept
+	return doubled
+}
+`,
+			lines: []int{8},
+		},
+		{
+			name: "results that stay local",
+			src: `package token
+
+func Log(n int64) {
+	total := n * 1000
+	println(total)
+}
+
+func Label(name string) string {
+	return name + "-token"
+}
+
+func Unit() int64 {
+	return 10 * 1000
+}
+
+func Later(n int64) func() int64 {
+	return func() int64 { return n * n }
+}
+
+func internal(n int64) int64 {
+	return n * n
+}
+`,
+		},
+		{
+			name: "guards",
+			src: `package token
+
+import "math"
+
+func Enclosing(a, b int64) int64 {
+	if b == 0 || a <= math.MaxInt64/b {
+		return a * b
+	}
+	return 0
+}
+
+func Earlier(a, b uint64) uint64 {
+	if a > maxSupply-b {
+		panic("overflow")
+	}
+	return a + b
+}
+
+func Helper(a, b int64) int64 {
+	if _, ok := checkedMul(a, b); !ok {
+		panic("overflow")
+	}
+	return a * b
+}
+
+func NotTheOperands(a, b, c int64) int64 {
+	if a > math.MaxInt64/c {
+		panic("overflow")
+	}
+	return a * b
+}
+
+func SafeMul(a, b int64) int64 {
+	return a * b
+}
+
+const maxSupply = 1 << 40
+
+func checkedMul(a, b int64) (int64, bool) { return a * b, true }
+`,
+			helpers: []string{"checkedMul", "SafeMul"},
+			lines:   []int{30},
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			pkg := parsePackage(t, map[string]string{"gno.land/r/demo/token/token.gno": tc.src})
+			issues, err := DetectAmountOverflows(pkg, tt.SeverityWarning, tc.helpers)
+			require.NoError(t, err)
+
+			var lines []int
+			for _, issue := range issues {
+				assert.Equal(t, "amount-overflow", issue.Rule)
+				assert.Equal(t, amountOverflowConfidence, issue.Confidence)
+				lines = append(lines, issue.Start.Line)
+			}
+			assert.Equal(t, tc.lines, lines)
+		})
+	}
+}
+
+func TestAmountOverflowSuggestion(t *testing.T) {
+	t.Parallel()
+	pkg := parsePackage(t, map[string]string{"gno.land/r/demo/token/token.gno": `package token
+
+func Fee(amount uint64) uint64 {
+	return amount * 3
+}
+
+func Total(a, b int64) int64 {
+	return a + b
+}
+`})
+
+	issues, err := DetectAmountOverflows(pkg, tt.SeverityWarning, nil)
+	require.NoError(t, err)
+	require.Len(t, issues, 2)
+	assert.Equal(t, "if 3 != 0 && amount > math.MaxUint64/3 {\n\tpanic(\"amount overflow\")\n}", issues[0].Suggestion)
+	assert.Equal(t, "if a > math.MaxInt64-b {\n\tpanic(\"amount overflow\")\n}", issues[1].Suggestion)
+
+	issues, err = DetectAmountOverflows(pkg, tt.SeverityWarning, DefaultOverflowHelpers)
+	require.NoError(t, err)
+	require.Len(t, issues, 2)
+	assert.Equal(t, "safemath.Mul(amount, 3)", issues[0].Suggestion)
+	assert.Equal(t, "safemath.Add(a, b)", issues[1].Suggestion)
+}
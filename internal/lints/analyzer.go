@@ -0,0 +1,207 @@
+package lints
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"os"
+	"reflect"
+	"runtime"
+
+	tt "github.com/gnolang/tlin/internal/types"
+	"golang.org/x/tools/go/analysis"
+)
+
+// RunAnalyzer runs a go/analysis analyzer on the package and turns its
+// diagnostics into issues, their first suggested fix included. The analyzers
+// it requires are run first, on the same type information, which TypesInfo
+// shares with the other rules. The package and its external test package are
+// analyzed apart.
+//
+// Unlike the go vet driver, RunAnalyzer works on a single package: facts are
+// only seen by the package that exported them, so analyzers relying on the
+// facts of the imported packages report less.
+func RunAnalyzer(pkg *Package, a *analysis.Analyzer, severity tt.Severity) ([]tt.Issue, error) {
+	if err := analysis.Validate([]*analysis.Analyzer{a}); err != nil {
+		return nil, err
+	}
+
+	info, checked := pkg.checkedTypes()
+	var issues []tt.Issue
+	for _, c := range checked {
+		if c.types == nil {
+			continue
+		}
+		run := &analyzerRun{
+			pkg:     pkg,
+			info:    info,
+			checked: c,
+			results: make(map[*analysis.Analyzer]*analyzerResult),
+			facts:   make(map[factKey]analysis.Fact),
+		}
+		res := run.run(a)
+		if res.err != nil {
+			return nil, res.err
+		}
+		for _, d := range res.diagnostics {
+			issues = append(issues, diagnosticIssue(pkg.Fset, a.Name, d, severity))
+		}
+	}
+	return issues, nil
+}
+
+// analyzerRun runs analyzers on one checked package.
+type analyzerRun struct {
+	pkg     *Package
+	info    *types.Info
+	checked *checkedPackage
+	results map[*analysis.Analyzer]*analyzerResult
+	facts   map[factKey]analysis.Fact
+}
+
+type analyzerResult struct {
+	result      interface{}
+	diagnostics []analysis.Diagnostic
+	err         error
+}
+
+// factKey identifies a fact by the object, or the package, it is about and
+// by its type, as each analyzer has its own fact types.
+type factKey struct {
+	obj types.Object
+	pkg *types.Package
+	typ reflect.Type
+}
+
+// run runs the analyzer, after the ones it requires, unless it already ran.
+func (r *analyzerRun) run(a *analysis.Analyzer) *analyzerResult {
+	if res, ok := r.results[a]; ok {
+		return res
+	}
+	res := &analyzerResult{}
+	r.results[a] = res
+
+	// like go vet, skip the analyzers that can't handle broken packages
+	if len(r.checked.errors) > 0 && !a.RunDespiteErrors {
+		return res
+	}
+
+	resultOf := make(map[*analysis.Analyzer]interface{}, len(a.Requires))
+	for _, req := range a.Requires {
+		reqRes := r.run(req)
+		if reqRes.err != nil {
+			res.err = fmt.Errorf("%s: %w", req.Name, reqRes.err)
+			return res
+		}
+		resultOf[req] = reqRes.result
+	}
+
+	files := make([]*ast.File, len(r.checked.files))
+	for i, file := range r.checked.files {
+		files[i] = file.File
+	}
+	pass := &analysis.Pass{
+		Analyzer:   a,
+		Fset:       r.pkg.Fset,
+		Files:      files,
+		Pkg:        r.checked.types,
+		TypesInfo:  r.info,
+		TypesSizes: types.SizesFor("gc", runtime.GOARCH),
+		TypeErrors: r.checked.errors,
+		ResultOf:   resultOf,
+		ReadFile:   os.ReadFile,
+		Report: func(d analysis.Diagnostic) {
+			res.diagnostics = append(res.diagnostics, d)
+		},
+		ImportObjectFact: func(obj types.Object, fact analysis.Fact) bool {
+			return r.importFact(factKey{obj: obj, typ: reflect.TypeOf(fact)}, fact)
+		},
+		ImportPackageFact: func(pkg *types.Package, fact analysis.Fact) bool {
+			return r.importFact(factKey{pkg: pkg, typ: reflect.TypeOf(fact)}, fact)
+		},
+		ExportObjectFact: func(obj types.Object, fact analysis.Fact) {
+			r.facts[factKey{obj: obj, typ: reflect.TypeOf(fact)}] = fact
+		},
+		ExportPackageFact: func(fact analysis.Fact) {
+			r.facts[factKey{pkg: r.checked.types, typ: reflect.TypeOf(fact)}] = fact
+		},
+		AllObjectFacts: func() []analysis.ObjectFact {
+			var facts []analysis.ObjectFact
+			for key, fact := range r.facts {
+				if key.obj != nil {
+					facts = append(facts, analysis.ObjectFact{Object: key.obj, Fact: fact})
+				}
+			}
+			return facts
+		},
+		AllPackageFacts: func() []analysis.PackageFact {
+			var facts []analysis.PackageFact
+			for key, fact := range r.facts {
+				if key.pkg != nil {
+					facts = append(facts, analysis.PackageFact{Package: key.pkg, Fact: fact})
+				}
+			}
+			return facts
+		},
+	}
+	res.result, res.err = a.Run(pass)
+	return res
+}
+
+// importFact copies the stored fact into fact, which points to a fact of
+// the same type.
+func (r *analyzerRun) importFact(key factKey, fact analysis.Fact) bool {
+	stored, ok := r.facts[key]
+	if !ok {
+		return false
+	}
+	reflect.ValueOf(fact).Elem().Set(reflect.ValueOf(stored).Elem())
+	return true
+}
+
+// diagnosticIssue converts a diagnostic of the analyzer into an issue, which
+// -fix only applies when the diagnostic suggests a fix.
+func diagnosticIssue(fset *token.FileSet, name string, d analysis.Diagnostic, severity tt.Severity) tt.Issue {
+	end := d.End
+	if !end.IsValid() {
+		end = d.Pos
+	}
+	issue := tt.Issue{
+		Rule:     name,
+		Category: d.Category,
+		Filename: fset.Position(d.Pos).Filename,
+		Message:  d.Message,
+		Note:     d.URL,
+		Start:    fset.Position(d.Pos),
+		End:      fset.Position(end),
+		Severity: severity,
+	}
+	for _, related := range d.Related {
+		issue.Related = append(issue.Related, tt.Location{
+			Position: fset.Position(related.Pos),
+			Label:    related.Message,
+		})
+	}
+	// without a fix, the confidence stays at zero: the fixer would otherwise
+	// replace the lines of the issue with its empty suggestion
+	if len(d.SuggestedFixes) > 0 {
+		fix := d.SuggestedFixes[0]
+		issue.Confidence = 0.8
+		if issue.Note == "" {
+			issue.Note = fix.Message
+		}
+		for _, edit := range fix.TextEdits {
+			editEnd := edit.End
+			if !editEnd.IsValid() {
+				editEnd = edit.Pos
+			}
+			issue.SuggestedFix = append(issue.SuggestedFix, tt.TextEdit{
+				Start:   fset.Position(edit.Pos),
+				End:     fset.Position(editEnd),
+				NewText: string(edit.NewText),
+			})
+		}
+	}
+	return issue
+}
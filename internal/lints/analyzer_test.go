@@ -0,0 +1,113 @@
+package lints
+
+import (
+	"go/ast"
+	"go/types"
+	"testing"
+
+	tt "github.com/gnolang/tlin/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/inspector"
+)
+
+// exportedFact marks the functions the test analyzer has seen.
+type exportedFact struct{}
+
+func (*exportedFact) AFact() {}
+
+// emptyFuncAnalyzer reports the empty functions and suggests a panic body.
+var emptyFuncAnalyzer = &analysis.Analyzer{
+	Name:      "emptyfunc",
+	Doc:       "reports empty functions",
+	Requires:  []*analysis.Analyzer{inspect.Analyzer},
+	FactTypes: []analysis.Fact{new(exportedFact)},
+	Run: func(pass *analysis.Pass) (interface{}, error) {
+		ins := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+		ins.Preorder([]ast.Node{(*ast.FuncDecl)(nil)}, func(n ast.Node) {
+			fn := n.(*ast.FuncDecl)
+			obj := pass.TypesInfo.Defs[fn.Name]
+			pass.ExportObjectFact(obj, new(exportedFact))
+			if !pass.ImportObjectFact(obj, new(exportedFact)) || len(fn.Body.List) > 0 {
+				return
+			}
+			pass.Report(analysis.Diagnostic{
+				Pos:     fn.Pos(),
+				End:     fn.End(),
+				Message: "empty function " + obj.(*types.Func).Name(),
+				SuggestedFixes: []analysis.SuggestedFix{{
+					Message: "panic instead",
+					TextEdits: []analysis.TextEdit{{
+						Pos:     fn.Body.Lbrace + 1,
+						NewText: []byte(` panic("todo") `),
+					}},
+				}},
+			})
+		})
+		return nil, nil
+	},
+}
+
+func TestRunAnalyzer(t *testing.T) {
+	t.Parallel()
+	pkg := parsePackage(t, map[string]string{
+		"a.gno":        "package foo\n\nfunc a() {}\n\nfunc b() { a() }\n",
+		"ext_test.gno": "package foo_test\n\nfunc c() {}\n",
+	})
+
+	issues, err := RunAnalyzer(pkg, emptyFuncAnalyzer, tt.SeverityWarning)
+	require.NoError(t, err)
+	require.Len(t, issues, 2, "both the package and its external tests are analyzed")
+
+	issue := issues[0]
+	assert.Equal(t, "emptyfunc", issue.Rule)
+	assert.Equal(t, "a.gno", issue.Filename)
+	assert.Equal(t, "empty function a", issue.Message)
+	assert.Equal(t, "panic instead", issue.Note)
+	assert.Equal(t, 3, issue.Start.Line)
+	assert.Equal(t, tt.SeverityWarning, issue.Severity)
+	assert.Equal(t, 0.8, issue.Confidence)
+	require.Len(t, issue.SuggestedFix, 1)
+	assert.Equal(t, issue.SuggestedFix[0].Start, issue.SuggestedFix[0].End)
+	assert.Equal(t, ` panic("todo") `, issue.SuggestedFix[0].NewText)
+
+	assert.Equal(t, "ext_test.gno", issues[1].Filename)
+}
+
+func TestRunAnalyzerWithoutFix(t *testing.T) {
+	t.Parallel()
+	pkg := parsePackage(t, map[string]string{
+		"a.gno": "package foo\n\nfunc a() {}\n",
+	})
+
+	noFix := *emptyFuncAnalyzer
+	noFix.Run = func(pass *analysis.Pass) (interface{}, error) {
+		pass.Reportf(pass.Files[0].Package, "package foo")
+		return nil, nil
+	}
+	issues, err := RunAnalyzer(pkg, &noFix, tt.SeverityWarning)
+	require.NoError(t, err)
+	require.Len(t, issues, 1)
+	// with no fix to apply, -fix must leave the line alone
+	assert.Zero(t, issues[0].Confidence)
+	assert.Empty(t, issues[0].SuggestedFix)
+}
+
+func TestRunAnalyzerTypeErrors(t *testing.T) {
+	t.Parallel()
+	pkg := parsePackage(t, map[string]string{
+		"a.gno": "package foo\n\nfunc a() { undefined() }\n\nfunc b() {}\n",
+	})
+
+	issues, err := RunAnalyzer(pkg, emptyFuncAnalyzer, tt.SeverityWarning)
+	require.NoError(t, err)
+	assert.Empty(t, issues, "analyzers not running despite errors are skipped")
+
+	tolerant := *emptyFuncAnalyzer
+	tolerant.RunDespiteErrors = true
+	issues, err = RunAnalyzer(pkg, &tolerant, tt.SeverityWarning)
+	require.NoError(t, err)
+	assert.Len(t, issues, 1)
+}
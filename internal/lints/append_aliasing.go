@@ -0,0 +1,174 @@
+package lints
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+
+	tt "github.com/gnolang/tlin/internal/types"
+)
+
+const appendAliasingConfidence = 0.5
+
+// DetectAppendAliasing reports appends to a slice obtained by slicing
+// another one with an upper bound, such as `a := base[:2]`, when base, or
+// another slice of it, is read after the append. Within the capacity of
+// base, the append overwrites the elements that follow a in base:
+//
+//	a := base[:2]
+//	a = append(a, x) // base[2] is now x
+//	use(base)
+//
+// Full slice expressions such as `base[:2:2]` cap the slice and make the
+// append copy, they are not reported. The analysis follows the variables by
+// name in source order, and confidence stays moderate.
+func DetectAppendAliasing(filename string, node *ast.File, fset *token.FileSet, severity tt.Severity) ([]tt.Issue, error) {
+	var issues []tt.Issue
+	for _, decl := range node.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok || fn.Body == nil {
+			continue
+		}
+
+		// slices of another slice, by variable
+		derived := make(map[*ast.Object]*sliceOf)
+		ast.Inspect(fn.Body, func(n ast.Node) bool {
+			assign, ok := n.(*ast.AssignStmt)
+			if !ok || len(assign.Lhs) != len(assign.Rhs) {
+				return true
+			}
+			for i, lhs := range assign.Lhs {
+				ident, ok := lhs.(*ast.Ident)
+				if !ok || ident.Obj == nil {
+					continue
+				}
+				rhs := ast.Unparen(assign.Rhs[i])
+				if call, ok := rhs.(*ast.CallExpr); ok && isIdent(call.Fun, "append") && len(call.Args) > 0 && !call.Ellipsis.IsValid() {
+					if arg, ok := call.Args[0].(*ast.Ident); ok && arg.Obj != nil && derived[arg.Obj] != nil {
+						slice := derived[arg.Obj]
+						if read := baseReadAfter(fn.Body, call, arg.Obj, derived); read != nil {
+							base := types.ExprString(slice.base)
+							issues = append(issues, tt.Issue{
+								Rule:       "append-aliasing",
+								Filename:   filename,
+								Start:      fset.Position(call.Pos()),
+								End:        fset.Position(call.End()),
+								Message:    fmt.Sprintf("append to %s may overwrite the elements of %s, which is read afterwards", arg.Name, base),
+								Note:       fmt.Sprintf("cap the slice with a full slice expression such as %s, or copy it, before appending", fullSlice(slice.at)),
+								Confidence: appendAliasingConfidence,
+								Severity:   severity,
+								Related: []tt.Location{
+									{Position: fset.Position(slice.at.Pos()), Label: arg.Name + " sliced from " + base},
+									{Position: fset.Position(read.Pos()), Label: "read here"},
+								},
+							})
+						}
+						if arg.Obj == ident.Obj {
+							// a grown slice may still share the memory
+							continue
+						}
+					}
+				}
+				delete(derived, ident.Obj)
+				if slice, ok := rhs.(*ast.SliceExpr); ok && !slice.Slice3 && slice.High != nil {
+					// a slice of itself, such as the pop of a stack, has no
+					// other slice to overwrite
+					if root := sliceRoot(slice.X); root != nil && root != ident.Obj {
+						derived[ident.Obj] = &sliceOf{base: slice.X, root: root, at: slice}
+					}
+				}
+			}
+			return true
+		})
+	}
+	return issues, nil
+}
+
+type sliceOf struct {
+	base ast.Expr
+	root *ast.Object
+	at   *ast.SliceExpr
+}
+
+// sliceRoot returns the variable at the root of a slice operand, such as
+// items in `items` or `s.items`.
+func sliceRoot(expr ast.Expr) *ast.Object {
+	for {
+		switch e := ast.Unparen(expr).(type) {
+		case *ast.Ident:
+			if e.Obj == nil || e.Obj.Kind != ast.Var {
+				return nil
+			}
+			return e.Obj
+		case *ast.SelectorExpr:
+			expr = e.X
+		default:
+			return nil
+		}
+	}
+}
+
+// baseReadAfter returns the first read, after the append call, of the base
+// of the slice held by appended, or of another slice of the same base. It
+// returns nil when the base is assigned a new value first.
+func baseReadAfter(body *ast.BlockStmt, call *ast.CallExpr, appended *ast.Object, derived map[*ast.Object]*sliceOf) ast.Expr {
+	slice := derived[appended]
+	base := types.ExprString(slice.base)
+	isRead := func(expr ast.Expr) bool {
+		if types.ExprString(expr) == base && sliceRoot(expr) == slice.root {
+			return true
+		}
+		ident, ok := expr.(*ast.Ident)
+		if !ok || ident.Obj == nil || ident.Obj == appended {
+			return false
+		}
+		other, ok := derived[ident.Obj]
+		return ok && other.root == slice.root && types.ExprString(other.base) == base
+	}
+
+	var read ast.Expr
+	reassigned := false
+	var visit func(n ast.Node) bool
+	visit = func(n ast.Node) bool {
+		if n == nil || read != nil || reassigned || n.End() <= call.End() {
+			return false
+		}
+		switch node := n.(type) {
+		case *ast.AssignStmt:
+			if node.Pos() < call.End() || node.Tok != token.ASSIGN {
+				return true
+			}
+			// the right hand side is evaluated first
+			for _, rhs := range node.Rhs {
+				ast.Inspect(rhs, visit)
+			}
+			for _, lhs := range node.Lhs {
+				if read == nil && types.ExprString(lhs) == base && sliceRoot(lhs) == slice.root {
+					reassigned = true
+				} else {
+					ast.Inspect(lhs, visit)
+				}
+			}
+			return false
+		case ast.Expr:
+			if node.Pos() > call.End() && isRead(node) {
+				read = node
+				return false
+			}
+		}
+		return true
+	}
+	ast.Inspect(body, visit)
+	return read
+}
+
+// fullSlice returns the full slice expression capping slice at its length.
+func fullSlice(slice *ast.SliceExpr) string {
+	low := ""
+	if slice.Low != nil {
+		low = types.ExprString(slice.Low)
+	}
+	high := types.ExprString(slice.High)
+	return fmt.Sprintf("%s[%s:%s:%s]", types.ExprString(slice.X), low, high, high)
+}
@@ -0,0 +1,139 @@
+package lints
+
+import (
+	"go/parser"
+	"go/token"
+	"testing"
+
+	tt "github.com/gnolang/tlin/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetectAppendAliasing(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name     string
+		code     string
+		messages []string
+		reads    []int // lines of the related reads
+	}{
+		{
+			name: "base read after the append",
+			code: `package foo
+
+type snapshot struct{ items []int }
+
+func grow(base []int, x int) []int {
+	a := base[:2]
+	a = append(a, x)
+	return append(base, a...)
+}
+
+func (s *snapshot) extend(x int) int {
+	head := s.items[1:3]
+	head = append(head, x)
+	return len(head) + s.items[0]
+}
+
+func siblings(base []int) int {
+	a := base[:2]
+	b := base[2:4]
+	a = append(a, 9)
+	return a[0] + b[0]
+}
+`,
+			messages: []string{
+				"append to a may overwrite the elements of base, which is read afterwards",
+				"append to head may overwrite the elements of s.items, which is read afterwards",
+				"append to a may overwrite the elements of base, which is read afterwards",
+			},
+			reads: []int{8, 14, 21},
+		},
+		{
+			name: "mitigated patterns",
+			code: `package foo
+
+func capped(base []int, x int) []int {
+	a := base[:2:2]
+	a = append(a, x)
+	return append(base, a...)
+}
+
+func copied(base []int, x int) []int {
+	a := append([]int(nil), base[:2]...)
+	a = append(a, x)
+	return append(base, a...)
+}
+
+func tail(base []int, x int) []int {
+	a := base[2:]
+	a = append(a, x)
+	return append(base, a...)
+}
+
+func unused(base []int, x int) []int {
+	a := base[:2]
+	a = append(a, x)
+	return a
+}
+
+func replaced(base []int, x int) []int {
+	a := base[:2]
+	a = append(a, x)
+	base = nil
+	return append(base, a...)
+}
+`,
+		},
+		{
+			name: "stack push and pop",
+			code: `package foo
+
+type parser struct{ stack []int }
+
+func walk(nodes []int) int {
+	var stack []int
+	for _, n := range nodes {
+		if n < 0 {
+			stack = stack[:len(stack)-1]
+			continue
+		}
+		stack = append(stack, n)
+	}
+	return len(stack)
+}
+
+func (p *parser) pop(x int) {
+	p.stack = p.stack[:len(p.stack)-1]
+	p.stack = append(p.stack, x)
+	_ = p.stack[0]
+}
+`,
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			fset := token.NewFileSet()
+			node, err := parser.ParseFile(fset, "test.gno", tc.code, 0)
+			require.NoError(t, err)
+
+			issues, err := DetectAppendAliasing("test.gno", node, fset, tt.SeverityWarning)
+			require.NoError(t, err)
+
+			var messages []string
+			var reads []int
+			for _, issue := range issues {
+				assert.Equal(t, "append-aliasing", issue.Rule)
+				require.Len(t, issue.Related, 2)
+				messages = append(messages, issue.Message)
+				reads = append(reads, issue.Related[1].Position.Line)
+			}
+			assert.Equal(t, tc.messages, messages)
+			assert.Equal(t, tc.reads, reads)
+		})
+	}
+}
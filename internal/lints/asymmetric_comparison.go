@@ -0,0 +1,245 @@
+package lints
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"strconv"
+	"strings"
+
+	tt "github.com/gnolang/tlin/internal/types"
+)
+
+var (
+	// DefaultAddressPrefixes are the prefixes a valid address literal starts with.
+	DefaultAddressPrefixes = []string{"g1"}
+	// DefaultAddressLength is the length of a valid address literal.
+	DefaultAddressLength = 40
+)
+
+// addressCalls are the calls returning an address.
+var addressCalls = []string{
+	"std.GetOrigCaller",
+	"std.OrigCaller",
+	"std.OriginCaller",
+	"std.GetCallerAt",
+	"std.DerivePkgAddr",
+}
+
+// DetectAsymmetricComparison reports equality comparisons whose sides are
+// not normalized the same way:
+//
+//   - strings.ToLower or strings.ToUpper applied to one side only, so that
+//     differently cased but equivalent values do not match, or that a literal
+//     in the other case never matches;
+//   - Address values compared to string literals which do not have the shape
+//     of an address, starting with one of prefixes and length characters long.
+//
+// Both are easy to miss when comparing user supplied addresses or denoms,
+// and tend to turn into authorization bugs.
+func DetectAsymmetricComparison(filename string, node *ast.File, fset *token.FileSet, severity tt.Severity, prefixes []string, length int) ([]tt.Issue, error) {
+	stringsName := ""
+	for _, imp := range node.Imports {
+		if strings.Trim(imp.Path.Value, `"`) == "strings" {
+			stringsName = importName(imp)
+		}
+	}
+
+	var issues []tt.Issue
+	ast.Inspect(node, func(n ast.Node) bool {
+		cmp, ok := n.(*ast.BinaryExpr)
+		if !ok || (cmp.Op != token.EQL && cmp.Op != token.NEQ) {
+			return true
+		}
+
+		message, suggestion := caseAsymmetry(cmp, stringsName)
+		if message == "" {
+			message = addressAsymmetry(cmp, prefixes, length)
+		}
+		if message == "" {
+			return true
+		}
+		issues = append(issues, tt.Issue{
+			Rule:       "asymmetric-comparison",
+			Filename:   filename,
+			Start:      fset.Position(cmp.Pos()),
+			End:        fset.Position(cmp.End()),
+			Message:    message,
+			Suggestion: suggestion,
+			Severity:   severity,
+		})
+		return true
+	})
+	return issues, nil
+}
+
+// caseAsymmetry describes a comparison normalizing the case of one side
+// only, and suggests comparing with strings.EqualFold when relevant.
+func caseAsymmetry(cmp *ast.BinaryExpr, stringsName string) (string, string) {
+	if stringsName == "" {
+		return "", ""
+	}
+	left, leftCase := caseNormalized(cmp.X, stringsName)
+	right, rightCase := caseNormalized(cmp.Y, stringsName)
+	if leftCase == rightCase {
+		return "", ""
+	}
+	if types.ExprString(left) == types.ExprString(right) {
+		// strings.ToLower(s) == s checks that s is already lower-cased
+		return "", ""
+	}
+
+	if leftCase != "" && rightCase != "" {
+		return fmt.Sprintf("the left side of the comparison is %s and the right side %s", caseLabel(leftCase), caseLabel(rightCase)), equalFold(cmp, stringsName, left, right)
+	}
+
+	side, fn, other := "left", leftCase, cmp.Y
+	if fn == "" {
+		side, fn, other = "right", rightCase, cmp.X
+	}
+	if lit, ok := other.(*ast.BasicLit); ok && lit.Kind == token.STRING {
+		value, err := strconv.Unquote(lit.Value)
+		if err != nil || normalizeCase(value, fn) == value {
+			return "", ""
+		}
+		return fmt.Sprintf("%s is compared to a %s string and never matches", lit.Value, caseLabel(fn)), ""
+	}
+	return fmt.Sprintf("only the %s side of the comparison is %s", side, caseLabel(fn)), equalFold(cmp, stringsName, left, right)
+}
+
+// caseNormalized unwraps a strings.ToLower or strings.ToUpper call, and
+// returns its argument along with the function name. Other expressions are
+// returned as is, with an empty name.
+func caseNormalized(expr ast.Expr, stringsName string) (ast.Expr, string) {
+	call, ok := expr.(*ast.CallExpr)
+	if !ok || len(call.Args) != 1 {
+		return expr, ""
+	}
+	name := callName(call)
+	if name == stringsName+".ToLower" || name == stringsName+".ToUpper" {
+		return call.Args[0], strings.TrimPrefix(name, stringsName+".")
+	}
+	return expr, ""
+}
+
+func caseLabel(fn string) string {
+	if fn == "ToUpper" {
+		return "upper-cased"
+	}
+	return "lower-cased"
+}
+
+func normalizeCase(s, fn string) string {
+	if fn == "ToUpper" {
+		return strings.ToUpper(s)
+	}
+	return strings.ToLower(s)
+}
+
+func equalFold(cmp *ast.BinaryExpr, stringsName string, left, right ast.Expr) string {
+	call := fmt.Sprintf("%s.EqualFold(%s, %s)", stringsName, types.ExprString(left), types.ExprString(right))
+	if cmp.Op == token.NEQ {
+		return "!" + call
+	}
+	return call
+}
+
+// addressAsymmetry describes a comparison of an address with a string
+// literal that is not shaped like an address.
+func addressAsymmetry(cmp *ast.BinaryExpr, prefixes []string, length int) string {
+	addr, other := cmp.X, cmp.Y
+	if !isAddressExpr(addr, 0) {
+		addr, other = other, addr
+		if !isAddressExpr(addr, 0) {
+			return ""
+		}
+	}
+	lit := addressLiteral(other)
+	if lit == nil {
+		return ""
+	}
+	value, err := strconv.Unquote(lit.Value)
+	if err != nil || value == "" || looksLikeAddress(value, prefixes, length) {
+		return ""
+	}
+	return fmt.Sprintf("address %s is compared to %s, which is not a valid address (expected %d characters starting with %s)",
+		types.ExprString(addr), lit.Value, length, strings.Join(prefixes, " or "))
+}
+
+// addressLiteral returns the string literal of expr, possibly converted to
+// an Address.
+func addressLiteral(expr ast.Expr) *ast.BasicLit {
+	if call, ok := expr.(*ast.CallExpr); ok && len(call.Args) == 1 && isAddressType(call.Fun) {
+		expr = call.Args[0]
+	}
+	if lit, ok := expr.(*ast.BasicLit); ok && lit.Kind == token.STRING {
+		return lit
+	}
+	return nil
+}
+
+// isAddressExpr reports whether expr is known to be an Address without type
+// information: a variable declared as such or assigned an address, a call
+// returning the caller, or an Addr method call.
+func isAddressExpr(expr ast.Expr, depth int) bool {
+	if depth > 4 {
+		return false
+	}
+	switch e := expr.(type) {
+	case *ast.ParenExpr:
+		return isAddressExpr(e.X, depth)
+	case *ast.CallExpr:
+		if isAddressType(e.Fun) {
+			return addressLiteral(e) == nil
+		}
+		if sel, ok := e.Fun.(*ast.SelectorExpr); ok && sel.Sel.Name == "Addr" && len(e.Args) == 0 {
+			return true
+		}
+		name := callName(e)
+		for _, fn := range addressCalls {
+			if name == fn {
+				return true
+			}
+		}
+	case *ast.Ident:
+		if e.Obj == nil || e.Obj.Kind != ast.Var {
+			return false
+		}
+		switch decl := e.Obj.Decl.(type) {
+		case *ast.Field:
+			return isAddressType(decl.Type)
+		case *ast.ValueSpec:
+			if decl.Type != nil {
+				return isAddressType(decl.Type)
+			}
+			for i, name := range decl.Names {
+				if name.Name == e.Name && i < len(decl.Values) && len(decl.Values) == len(decl.Names) {
+					return isAddressExpr(decl.Values[i], depth+1)
+				}
+			}
+		case *ast.AssignStmt:
+			if len(decl.Lhs) != len(decl.Rhs) {
+				return false
+			}
+			for i, lhs := range decl.Lhs {
+				if isIdent(lhs, e.Name) {
+					return isAddressExpr(decl.Rhs[i], depth+1)
+				}
+			}
+		}
+	}
+	return false
+}
+
+func looksLikeAddress(value string, prefixes []string, length int) bool {
+	if len(value) != length {
+		return false
+	}
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(value, prefix) {
+			return true
+		}
+	}
+	return false
+}
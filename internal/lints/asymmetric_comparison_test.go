@@ -0,0 +1,140 @@
+package lints
+
+import (
+	"go/parser"
+	"go/token"
+	"testing"
+
+	tt "github.com/gnolang/tlin/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetectAsymmetricComparison(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name        string
+		code        string
+		messages    []string
+		suggestions []string
+	}{
+		{
+			name: "case normalized on one side",
+			code: `package foo
+
+import "strings"
+
+func check(denom, want string) bool {
+	if strings.ToLower(denom) == want {
+		return true
+	}
+	return want != strings.ToUpper(denom)
+}
+`,
+			messages: []string{
+				"only the left side of the comparison is lower-cased",
+				"only the right side of the comparison is upper-cased",
+			},
+			suggestions: []string{
+				"strings.EqualFold(denom, want)",
+				"!strings.EqualFold(want, denom)",
+			},
+		},
+		{
+			name: "mixed normalizations and literals",
+			code: `package foo
+
+import str "strings"
+
+func check(a, b string) bool {
+	return str.ToLower(a) == str.ToUpper(b) || str.ToLower(a) == "UGNOT"
+}
+`,
+			messages: []string{
+				"the left side of the comparison is lower-cased and the right side upper-cased",
+				`"UGNOT" is compared to a lower-cased string and never matches`,
+			},
+			suggestions: []string{"str.EqualFold(a, b)", ""},
+		},
+		{
+			name: "consistent comparisons",
+			code: `package foo
+
+import "strings"
+
+func check(a, b string) bool {
+	return strings.ToLower(a) == strings.ToLower(b) || strings.ToLower(a) == "ugnot" || a == b
+}
+`,
+		},
+		{
+			name: "case checks of a value against itself",
+			code: `package foo
+
+import "strings"
+
+func check(s string, names []string) bool {
+	return strings.ToLower(s) == s || s != strings.ToUpper(s) || strings.ToLower(names[0]) == strings.ToUpper(names[0])
+}
+`,
+		},
+		{
+			name: "address compared to malformed literals",
+			code: `package foo
+
+import "std"
+
+func check(owner std.Address) bool {
+	caller := std.OriginCaller()
+	if caller == "g1short" {
+		return false
+	}
+	return owner == std.Address("G1JG8MTUTU9KHHFWC4NXMUHCPFTF0PAJDHFVSQF5")
+}
+`,
+			messages: []string{
+				`address caller is compared to "g1short", which is not a valid address (expected 40 characters starting with g1)`,
+				`address owner is compared to "G1JG8MTUTU9KHHFWC4NXMUHCPFTF0PAJDHFVSQF5", which is not a valid address (expected 40 characters starting with g1)`,
+			},
+			suggestions: []string{"", ""},
+		},
+		{
+			name: "valid or empty address literals",
+			code: `package foo
+
+import "std"
+
+var admin std.Address = "g1jg8mtutu9khhfwc4nxmuhcpftf0pajdhfvsqf5"
+
+func check(name string) bool {
+	if admin == "" || name == "g1short" {
+		return false
+	}
+	return std.PreviousRealm().Addr() == "g1jg8mtutu9khhfwc4nxmuhcpftf0pajdhfvsqf5"
+}
+`,
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			fset := token.NewFileSet()
+			node, err := parser.ParseFile(fset, "test.gno", tc.code, 0)
+			require.NoError(t, err)
+
+			issues, err := DetectAsymmetricComparison("test.gno", node, fset, tt.SeverityWarning, DefaultAddressPrefixes, DefaultAddressLength)
+			require.NoError(t, err)
+
+			var messages, suggestions []string
+			for _, issue := range issues {
+				assert.Equal(t, "asymmetric-comparison", issue.Rule)
+				messages = append(messages, issue.Message)
+				suggestions = append(suggestions, issue.Suggestion)
+			}
+			assert.Equal(t, tc.messages, messages)
+			assert.Equal(t, tc.suggestions, suggestions)
+		})
+	}
+}
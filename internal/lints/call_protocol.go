@@ -0,0 +1,395 @@
+package lints
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"path/filepath"
+	"strings"
+	"unicode"
+
+	tt "github.com/gnolang/tlin/internal/types"
+)
+
+// Prerequisite requires the functions whose name matches one of the glob
+// patterns of Functions to call Call before they write the package state.
+//
+// It is written `<patterns> -> <call>`, such as
+// `Set*, Withdraw* -> AssertCallerIsOwner`. A call name without a dot
+// matches the function or method of that name whatever its receiver or
+// package, a qualified one such as `ownable.AssertCallerIsOwner` only the
+// calls written that way.
+type Prerequisite struct {
+	Functions []string
+	Call      string
+}
+
+// CallPair requires a package making one of the two calls to make the
+// other one too, such as Pause and Unpause. It is written `<call> <-> <call>`.
+type CallPair struct {
+	First, Second string
+}
+
+// ParsePrerequisite parses a prerequisite written `<patterns> -> <call>`.
+func ParsePrerequisite(s string) (Prerequisite, error) {
+	if strings.Contains(s, "<->") {
+		return Prerequisite{}, fmt.Errorf("%q is a pair, expected <patterns> -> <call>", s)
+	}
+	patterns, call, ok := strings.Cut(s, "->")
+	if !ok {
+		return Prerequisite{}, fmt.Errorf("missing -> in %q, expected <patterns> -> <call>", s)
+	}
+	var p Prerequisite
+	for _, pattern := range strings.Split(patterns, ",") {
+		pattern = strings.TrimSpace(pattern)
+		if pattern == "" {
+			return Prerequisite{}, fmt.Errorf("empty function pattern in %q", s)
+		}
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			return Prerequisite{}, fmt.Errorf("invalid function pattern %q: %w", pattern, err)
+		}
+		p.Functions = append(p.Functions, pattern)
+	}
+	p.Call = strings.TrimSpace(call)
+	if err := validateCallName(p.Call); err != nil {
+		return Prerequisite{}, err
+	}
+	return p, nil
+}
+
+// ParseCallPair parses a pair written `<call> <-> <call>`.
+func ParseCallPair(s string) (CallPair, error) {
+	first, second, ok := strings.Cut(s, "<->")
+	if !ok {
+		return CallPair{}, fmt.Errorf("missing <-> in %q, expected <call> <-> <call>", s)
+	}
+	pair := CallPair{First: strings.TrimSpace(first), Second: strings.TrimSpace(second)}
+	if err := validateCallName(pair.First); err != nil {
+		return CallPair{}, err
+	}
+	if err := validateCallName(pair.Second); err != nil {
+		return CallPair{}, err
+	}
+	if pair.First == pair.Second {
+		return CallPair{}, fmt.Errorf("%q pairs a call with itself", s)
+	}
+	return pair, nil
+}
+
+func validateCallName(name string) error {
+	if name == "" {
+		return errors.New("empty call name")
+	}
+	parts := strings.Split(name, ".")
+	if len(parts) > 2 {
+		return fmt.Errorf("invalid call name %q, expected a name or a qualified name such as pkg.Func", name)
+	}
+	for _, part := range parts {
+		if !token.IsIdentifier(part) {
+			return fmt.Errorf("invalid call name %q, expected a name or a qualified name such as pkg.Func", name)
+		}
+	}
+	return nil
+}
+
+// stateMutators are the prefixes of the method names that write the value
+// they are called on, such as the Set and Remove methods of a tree.
+var stateMutators = []string{"Set", "Remove", "Delete", "Insert", "Add", "Append", "Push", "Pop", "Update", "Transfer", "Mint", "Burn", "Approve"}
+
+// DetectCallProtocolViolations checks the calls of a package against a
+// protocol. The prerequisite calls must be made by the matching functions
+// before any write to a package variable, unconditionally: a call made in a
+// branch does not cover the statements after the branch. The calls of a
+// pair must either both appear in the package or not at all.
+func DetectCallProtocolViolations(pkg *Package, severity tt.Severity, prerequisites []Prerequisite, pairs []CallPair) ([]tt.Issue, error) {
+	files, info, checked := typeCheck(pkg)
+	if checked == nil {
+		return nil, nil
+	}
+	scope := checked.Scope()
+
+	var issues []tt.Issue
+	for _, file := range files {
+		for _, decl := range file.File.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok || fn.Body == nil {
+				continue
+			}
+			for _, p := range prerequisites {
+				if !matchesAnyPattern(fn.Name.Name, p.Functions) {
+					continue
+				}
+				write := unguardedWrite(fn.Body, p.Call, info, scope)
+				if write == nil {
+					continue
+				}
+				message := fmt.Sprintf("%s writes %s without calling %s first", fn.Name.Name, write.state.Name(), p.Call)
+				if write.callSeen {
+					message = fmt.Sprintf("%s writes %s on a path that does not call %s first", fn.Name.Name, write.state.Name(), p.Call)
+				}
+				issues = append(issues, tt.Issue{
+					Rule:     "call-protocol",
+					Filename: file.Filename,
+					Start:    pkg.Fset.Position(write.node.Pos()),
+					End:      pkg.Fset.Position(write.node.End()),
+					Message:  message,
+					Note:     fmt.Sprintf("call %s at the start of %s, before any other statement", p.Call, fn.Name.Name),
+					Severity: severity,
+					Related: []tt.Location{
+						{Position: pkg.Fset.Position(fn.Name.Pos()), Label: fn.Name.Name + " declared here"},
+					},
+				})
+			}
+		}
+	}
+
+	for _, pair := range pairs {
+		first, firstFile := firstCall(files, pair.First)
+		second, secondFile := firstCall(files, pair.Second)
+		if (first == nil) == (second == nil) {
+			continue
+		}
+		made, missing, call, file := pair.First, pair.Second, first, firstFile
+		if first == nil {
+			made, missing, call, file = pair.Second, pair.First, second, secondFile
+		}
+		issues = append(issues, tt.Issue{
+			Rule:     "call-protocol",
+			Filename: file.Filename,
+			Start:    pkg.Fset.Position(call.Pos()),
+			End:      pkg.Fset.Position(call.End()),
+			Message:  fmt.Sprintf("the package calls %s but never %s", made, missing),
+			Note:     fmt.Sprintf("%s and %s go together, expose a function calling %s", made, missing, missing),
+			Severity: severity,
+		})
+	}
+	return issues, nil
+}
+
+func matchesAnyPattern(name string, patterns []string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := filepath.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesCall reports whether call calls the function named name, either
+// qualified or not.
+func matchesCall(call *ast.CallExpr, name string) bool {
+	if strings.Contains(name, ".") {
+		return callName(call) == name
+	}
+	switch fun := ast.Unparen(call.Fun).(type) {
+	case *ast.Ident:
+		return fun.Name == name
+	case *ast.SelectorExpr:
+		return fun.Sel.Name == name
+	}
+	return false
+}
+
+func firstCall(files []*PackageFile, name string) (*ast.CallExpr, *PackageFile) {
+	for _, file := range files {
+		var found *ast.CallExpr
+		ast.Inspect(file.File, func(n ast.Node) bool {
+			if call, ok := n.(*ast.CallExpr); ok && found == nil && matchesCall(call, name) {
+				found = call
+			}
+			return found == nil
+		})
+		if found != nil {
+			return found, file
+		}
+	}
+	return nil, nil
+}
+
+type stateWrite struct {
+	node  ast.Node
+	state *types.Var
+	// callSeen tells whether the prerequisite is called somewhere in the
+	// function, only not on every path to the write.
+	callSeen bool
+}
+
+// unguardedWrite returns the first write to a package variable of body that
+// is not preceded by the call on every path.
+func unguardedWrite(body *ast.BlockStmt, name string, info *types.Info, scope *types.Scope) *stateWrite {
+	var found *stateWrite
+
+	// calls reports whether node makes the call outside of function literals.
+	calls := func(node ast.Node) bool {
+		made := false
+		ast.Inspect(node, func(n ast.Node) bool {
+			switch n := n.(type) {
+			case *ast.FuncLit:
+				return false
+			case *ast.CallExpr:
+				if matchesCall(n, name) {
+					made = true
+				}
+			}
+			return !made
+		})
+		return made
+	}
+
+	var walkStmts func(stmts []ast.Stmt, guarded bool) bool
+	var walkStmt func(stmt ast.Stmt, guarded bool) bool
+	var walkClauses func(body *ast.BlockStmt, guarded bool)
+	// checkWrites looks for writes in a simple node, walking the bodies of
+	// its function literals with the state they are created in.
+	checkWrites := func(node ast.Node, guarded bool) {
+		ast.Inspect(node, func(n ast.Node) bool {
+			if found != nil {
+				return false
+			}
+			var state *types.Var
+			switch n := n.(type) {
+			case *ast.FuncLit:
+				walkStmts(n.Body.List, guarded)
+				return false
+			case *ast.AssignStmt:
+				if n.Tok != token.DEFINE {
+					for _, lhs := range n.Lhs {
+						if state = packageRoot(lhs, info, scope); state != nil {
+							break
+						}
+					}
+				}
+			case *ast.IncDecStmt:
+				state = packageRoot(n.X, info, scope)
+			case *ast.CallExpr:
+				if sel, ok := ast.Unparen(n.Fun).(*ast.SelectorExpr); ok && isMutatorName(sel.Sel.Name) {
+					state = packageRoot(sel.X, info, scope)
+				}
+			}
+			if state != nil && !guarded {
+				found = &stateWrite{node: n, state: state}
+			}
+			return true
+		})
+	}
+
+	// walkStmt checks stmt and returns whether the call is made on every
+	// path through it.
+	walkStmt = func(stmt ast.Stmt, guarded bool) bool {
+		if found != nil {
+			return guarded
+		}
+		switch s := stmt.(type) {
+		case *ast.BlockStmt:
+			return walkStmts(s.List, guarded)
+		case *ast.LabeledStmt:
+			return walkStmt(s.Stmt, guarded)
+		case *ast.IfStmt:
+			if s.Init != nil {
+				guarded = walkStmt(s.Init, guarded)
+			}
+			guarded = calls(s.Cond) || guarded
+			checkWrites(s.Cond, guarded)
+			walkStmts(s.Body.List, guarded)
+			if s.Else != nil {
+				walkStmt(s.Else, guarded)
+			}
+		case *ast.ForStmt:
+			if s.Init != nil {
+				guarded = walkStmt(s.Init, guarded)
+			}
+			if s.Cond != nil {
+				guarded = calls(s.Cond) || guarded
+				checkWrites(s.Cond, guarded)
+			}
+			if s.Post != nil {
+				walkStmt(s.Post, guarded)
+			}
+			walkStmts(s.Body.List, guarded)
+		case *ast.RangeStmt:
+			guarded = calls(s.X) || guarded
+			checkWrites(s.X, guarded)
+			if s.Tok == token.ASSIGN {
+				for _, target := range []ast.Expr{s.Key, s.Value} {
+					if target != nil && packageRoot(target, info, scope) != nil && !guarded && found == nil {
+						found = &stateWrite{node: target, state: packageRoot(target, info, scope)}
+					}
+				}
+			}
+			walkStmts(s.Body.List, guarded)
+		case *ast.SwitchStmt:
+			if s.Init != nil {
+				guarded = walkStmt(s.Init, guarded)
+			}
+			if s.Tag != nil {
+				guarded = calls(s.Tag) || guarded
+				checkWrites(s.Tag, guarded)
+			}
+			walkClauses(s.Body, guarded)
+		case *ast.TypeSwitchStmt:
+			if s.Init != nil {
+				guarded = walkStmt(s.Init, guarded)
+			}
+			guarded = walkStmt(s.Assign, guarded)
+			walkClauses(s.Body, guarded)
+		case *ast.SelectStmt:
+			walkClauses(s.Body, guarded)
+		case *ast.DeferStmt, *ast.GoStmt:
+			// the call runs later, so it guards nothing that follows, but
+			// its arguments are evaluated here
+			checkWrites(stmt, guarded)
+		default:
+			guarded = calls(stmt) || guarded
+			checkWrites(stmt, guarded)
+		}
+		return guarded
+	}
+	// the expressions of the case clauses are evaluated before the chosen
+	// body runs
+	walkClauses = func(body *ast.BlockStmt, guarded bool) {
+		for _, clause := range body.List {
+			switch c := clause.(type) {
+			case *ast.CaseClause:
+				for _, expr := range c.List {
+					checkWrites(expr, guarded)
+				}
+				walkStmts(c.Body, guarded)
+			case *ast.CommClause:
+				caseGuarded := guarded
+				if c.Comm != nil {
+					caseGuarded = calls(c.Comm) || guarded
+					checkWrites(c.Comm, caseGuarded)
+				}
+				walkStmts(c.Body, caseGuarded)
+			}
+		}
+	}
+	walkStmts = func(stmts []ast.Stmt, guarded bool) bool {
+		for _, stmt := range stmts {
+			guarded = walkStmt(stmt, guarded)
+		}
+		return guarded
+	}
+
+	walkStmts(body.List, false)
+	if found != nil {
+		ast.Inspect(body, func(n ast.Node) bool {
+			if call, ok := n.(*ast.CallExpr); ok && matchesCall(call, name) {
+				found.callSeen = true
+			}
+			return !found.callSeen
+		})
+	}
+	return found
+}
+
+func isMutatorName(name string) bool {
+	for _, prefix := range stateMutators {
+		if rest, ok := strings.CutPrefix(name, prefix); ok && (rest == "" || unicode.IsUpper(rune(rest[0]))) {
+			return true
+		}
+	}
+	return false
+}
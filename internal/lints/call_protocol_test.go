@@ -0,0 +1,121 @@
+package lints
+
+import (
+	"testing"
+
+	tt "github.com/gnolang/tlin/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePrerequisite(t *testing.T) {
+	t.Parallel()
+	p, err := ParsePrerequisite("Set*, Withdraw* -> ownable.AssertCallerIsOwner")
+	require.NoError(t, err)
+	assert.Equal(t, Prerequisite{Functions: []string{"Set*", "Withdraw*"}, Call: "ownable.AssertCallerIsOwner"}, p)
+
+	for _, invalid := range []string{
+		"Set* AssertCallerIsOwner",
+		"Set*, -> AssertCallerIsOwner",
+		"Set[ -> AssertCallerIsOwner",
+		"Set* -> ",
+		"Set* -> a.b.c",
+		"Set* -> Assert()",
+		"Pause <-> Unpause",
+	} {
+		_, err := ParsePrerequisite(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
+
+func TestParseCallPair(t *testing.T) {
+	t.Parallel()
+	pair, err := ParseCallPair(" Pause <-> pausable.Unpause ")
+	require.NoError(t, err)
+	assert.Equal(t, CallPair{First: "Pause", Second: "pausable.Unpause"}, pair)
+
+	for _, invalid := range []string{"Pause -> Unpause", "Pause <-> ", "Pause <-> Pause", "1Pause <-> Unpause"} {
+		_, err := ParseCallPair(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
+
+// The realistic contracts are in testdata/call-protocol, this covers the
+// paths the prerequisite has to dominate.
+func TestDetectCallProtocolViolations(t *testing.T) {
+	t.Parallel()
+	pkg := parsePackage(t, map[string]string{
+		"pkg/admin.gno": `package admin
+
+var (
+	limit int
+	names []string
+)
+
+func SetAfterLoop(n int) {
+	for i := 0; i < n; i++ {
+		Assert()
+	}
+	limit = n
+}
+
+func SetInSwitch(n int) {
+	switch Assert(); n {
+	case 1:
+		limit = n
+	}
+}
+
+func SetInClosure(n int) func() {
+	Assert()
+	return func() { limit = n }
+}
+
+func SetClosureFirst(n int) func() {
+	f := func() { limit = n }
+	Assert()
+	return f
+}
+
+func SetAppend(name string) {
+	defer Assert()
+	names = append(names, name)
+}
+
+func SetLocal(n int) {
+	local := n
+	local++
+	Assert()
+}
+
+func SetLabeled(n int) {
+	{
+		Assert()
+	}
+outer:
+	for {
+		limit = n
+		break outer
+	}
+}
+
+func Other(n int) { limit = n }
+`,
+	})
+
+	prerequisite, err := ParsePrerequisite("Set* -> Assert")
+	require.NoError(t, err)
+	issues, err := DetectCallProtocolViolations(pkg, tt.SeverityWarning, []Prerequisite{prerequisite}, nil)
+	require.NoError(t, err)
+
+	var messages []string
+	for _, issue := range issues {
+		assert.Equal(t, "call-protocol", issue.Rule)
+		messages = append(messages, issue.Message)
+	}
+	assert.Equal(t, []string{
+		"SetAfterLoop writes limit on a path that does not call Assert first",
+		"SetClosureFirst writes limit on a path that does not call Assert first",
+		"SetAppend writes names on a path that does not call Assert first",
+	}, messages)
+}
@@ -0,0 +1,139 @@
+package lints
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"strings"
+
+	tt "github.com/gnolang/tlin/internal/types"
+)
+
+const (
+	// DefaultCommentedCodeMinLines is the number of lines from which a block
+	// of commented-out code is reported.
+	DefaultCommentedCodeMinLines = 4
+	// DefaultCommentedCodeRatio is the fraction of the lines of a comment
+	// that must look like code for it to be commented-out code.
+	DefaultCommentedCodeRatio = 0.6
+)
+
+// DetectCommentedOutCode reports comment groups of at least minLines non
+// empty lines, of which at least ratio look like code: they parse as a
+// statement, or carry a structural signal such as a trailing brace, `:=` or
+// `func (`.
+//
+// Indented lines following a sentence are code examples in the godoc
+// convention, and directives such as //nolint are not counted.
+func DetectCommentedOutCode(filename string, node *ast.File, fset *token.FileSet, severity tt.Severity, minLines int, ratio float64) ([]tt.Issue, error) {
+	var issues []tt.Issue
+	for _, group := range node.Comments {
+		lines := commentLines(group)
+		if len(lines) < minLines {
+			continue
+		}
+		code := 0
+		for _, line := range lines {
+			if looksLikeCode(line) {
+				code++
+			}
+		}
+		if float64(code) < ratio*float64(len(lines)) {
+			continue
+		}
+		issues = append(issues, tt.Issue{
+			Rule:     "commented-out-code",
+			Filename: filename,
+			Start:    fset.Position(group.Pos()),
+			End:      fset.Position(group.End()),
+			Message:  fmt.Sprintf("%d of the %d lines of this comment look like commented-out code", code, len(lines)),
+			Note:     "remove the code, version control keeps its history",
+			Severity: severity,
+		})
+	}
+	return issues, nil
+}
+
+// commentLines returns the non empty lines of a comment group, without
+// comment markers, directives and godoc code examples.
+func commentLines(group *ast.CommentGroup) []string {
+	var lines []string
+	prose := false
+	for _, comment := range group.List {
+		var raw []string
+		if strings.HasPrefix(comment.Text, "//") {
+			text := comment.Text[2:]
+			if isDirective(text) {
+				continue
+			}
+			raw = []string{text}
+		} else {
+			raw = strings.Split(strings.TrimSuffix(strings.TrimPrefix(comment.Text, "/*"), "*/"), "\n")
+		}
+
+		for _, line := range raw {
+			trimmed := strings.TrimSpace(line)
+			if trimmed == "" {
+				continue
+			}
+			// an example is indented beyond the space after the marker,
+			// and follows a sentence
+			indented := strings.HasPrefix(line, "\t") || strings.HasPrefix(line, " \t") || strings.HasPrefix(line, "  ")
+			if indented && prose {
+				continue
+			}
+			prose = !indented && !looksLikeCode(trimmed)
+			lines = append(lines, trimmed)
+		}
+	}
+	return lines
+}
+
+// isDirective reports whether the text of a line comment is a tool
+// directive, such as go:build or nolint:rule.
+func isDirective(text string) bool {
+	if strings.HasPrefix(text, "nolint") || strings.HasPrefix(text, "export ") {
+		return true
+	}
+	colon := strings.Index(text, ":")
+	return colon > 0 && !strings.ContainsAny(text[:colon], " \t") && strings.ToLower(text[:colon]) == text[:colon] &&
+		colon+1 < len(text) && text[colon+1] != ' '
+}
+
+// looksLikeCode reports whether a trimmed comment line is likely code.
+func looksLikeCode(line string) bool {
+	switch {
+	case strings.HasSuffix(line, "{"), strings.HasPrefix(line, "}"), line == ")":
+		return true
+	case strings.Contains(line, ":="), strings.HasPrefix(line, "func ("):
+		return true
+	}
+	return parsesAsStatement(line)
+}
+
+// parsesAsStatement reports whether line parses as a statement of a
+// function body. Lone operands, which are common words, numbers and list
+// items in prose, do not count.
+func parsesAsStatement(line string) bool {
+	src := "package p\nfunc _() {\n" + line + "\n}\n"
+	file, err := parser.ParseFile(token.NewFileSet(), "", src, parser.SkipObjectResolution)
+	if err != nil {
+		return false
+	}
+	body := file.Decls[0].(*ast.FuncDecl).Body
+	if len(body.List) != 1 {
+		return false
+	}
+	if stmt, ok := body.List[0].(*ast.ExprStmt); ok {
+		switch stmt.X.(type) {
+		case *ast.Ident, *ast.BasicLit, *ast.SelectorExpr, *ast.ParenExpr, *ast.UnaryExpr, *ast.StarExpr:
+			return false
+		}
+	}
+	if _, ok := body.List[0].(*ast.LabeledStmt); ok {
+		// "Note: something" parses as a label
+		return false
+	}
+	return true
+}
@@ -0,0 +1,112 @@
+package lints
+
+import (
+	"go/parser"
+	"go/token"
+	"testing"
+
+	tt "github.com/gnolang/tlin/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetectCommentedOutCode(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name  string
+		code  string
+		lines []int
+	}{
+		{
+			name: "commented-out statements",
+			code: `package foo
+
+func f(items []int) int {
+	total := 0
+	// for _, item := range items {
+	// 	if item < 0 {
+	// 		continue
+	// 	}
+	// 	total += item
+	// }
+	return total
+}
+
+/*
+func old(x int) int {
+	y := x * 2
+	return y
+}
+*/
+`,
+			lines: []int{5, 14},
+		},
+		{
+			name: "prose and short snippets",
+			code: `package foo
+
+// Sum returns the sum of the items. It ignores negative values,
+// which are rejected earlier by the caller, and returns zero for
+// an empty slice. See the package documentation for the details
+// of the accounting rules.
+// TODO: cache the result.
+func Sum(items []int) int {
+	// x := 1
+	// y := 2
+	return 0
+}
+`,
+		},
+		{
+			name: "godoc code examples",
+			code: `package foo
+
+// Render renders the page. Call it from the realm render function:
+//
+//	func Render(path string) string {
+//		page := foo.Render(path)
+//		if page == "" {
+//			return "not found"
+//		}
+//		return page
+//	}
+//
+//nolint:exported-naming
+func Render(path string) string {
+	return path
+}
+`,
+		},
+		{
+			name: "mostly prose with a few code lines",
+			code: `package foo
+
+// The state is kept in a tree keyed by address, as in
+// tree.Set(addr, value)
+// and is never iterated in full, since iterating would be
+// too expensive for large realms with many users.
+var state int
+`,
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			fset := token.NewFileSet()
+			node, err := parser.ParseFile(fset, "test.gno", tc.code, parser.ParseComments)
+			require.NoError(t, err)
+
+			issues, err := DetectCommentedOutCode("test.gno", node, fset, tt.SeverityInfo, DefaultCommentedCodeMinLines, DefaultCommentedCodeRatio)
+			require.NoError(t, err)
+
+			var lines []int
+			for _, issue := range issues {
+				assert.Equal(t, "commented-out-code", issue.Rule)
+				lines = append(lines, issue.Start.Line)
+			}
+			assert.Equal(t, tc.lines, lines)
+		})
+	}
+}
@@ -62,6 +62,11 @@ func DetectConstErrorDeclaration(
 				Suggestion: suggestion,
 				Confidence: 1.0,
 				Severity:   severity,
+				SuggestedFix: []tt.TextEdit{{
+					Start:   fset.Position(genDecl.TokPos),
+					End:     fset.Position(genDecl.TokPos + token.Pos(len(token.CONST.String()))),
+					NewText: token.VAR.String(),
+				}},
 			}
 			issues = append(issues, issue)
 		}
@@ -1,15 +1,18 @@
 package lints
 
 import (
-	"fmt"
 	"go/ast"
 	"go/parser"
 	"go/token"
 
 	"github.com/fzipp/gocyclo"
+	"github.com/gnolang/tlin/internal/messages"
 	tt "github.com/gnolang/tlin/internal/types"
 )
 
+var cyclomaticComplexityMessage = messages.Define("high-cyclomatic-complexity",
+	"function {function} has a cyclomatic complexity of {complexity} (threshold {threshold})")
+
 func DetectHighCyclomaticComplexity(filename string, threshold int, severity tt.Severity) ([]tt.Issue, error) {
 	fset := token.NewFileSet()
 	f, err := parser.ParseFile(fset, filename, nil, parser.ParseComments)
@@ -40,11 +43,15 @@ func DetectHighCyclomaticComplexity(filename string, threshold int, severity tt.
 				Filename:   filename,
 				Start:      fset.Position(funcNode.Pos()),
 				End:        fset.Position(funcNode.End()),
-				Message:    fmt.Sprintf("function %s has a cyclomatic complexity of %d (threshold %d)", stat.FuncName, stat.Complexity, threshold),
 				Suggestion: "consider refactoring this function to reduce its complexity. you can split it into smaller functions or simplify the logic.\n",
 				Note:       "high cyclomatic complexity can make the code harder to understand, test, and maintain. aim for a complexity score of 10 or less for most functions.\n",
 				Severity:   severity,
 			}
+			cyclomaticComplexityMessage.Set(&issue, messages.Args{
+				"function":   stat.FuncName,
+				"complexity": stat.Complexity,
+				"threshold":  threshold,
+			})
 			issues = append(issues, issue)
 		}
 	}
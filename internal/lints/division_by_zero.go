@@ -0,0 +1,451 @@
+package lints
+
+import (
+	"fmt"
+	"go/ast"
+	"go/constant"
+	"go/token"
+	"go/types"
+	"strings"
+
+	tt "github.com/gnolang/tlin/internal/types"
+)
+
+// The confidences stay below the fix threshold: the issues have no fix,
+// and the fixer would delete the lines of the division otherwise.
+const (
+	divisionConfidence     = 0.7
+	divisionCallConfidence = 0.5
+)
+
+// DetectDivisionByZero detects integer divisions and modulos whose divisor is a
+// variable or a call result that is not checked against zero beforehand.
+//
+// Only divisions of integers are checked, as told by the type information
+// of the package; a floating-point division by zero gives an infinity or NaN
+// instead of panicking. Operands whose type is unknown are checked.
+//
+// The analysis is intra-procedural and syntactic. A division is considered
+// guarded when:
+//   - an `if d == 0 { return }` (or panic, break, continue) precedes it in an enclosing block,
+//   - it is inside `if d != 0 { ... }` or the else branch of `if d == 0`,
+//   - it is inside a for loop whose condition compares the divisor.
+//
+// Constant divisors, and variables only ever assigned non-zero constants, are
+// never reported. Divisors coming from calls are reported with a lower confidence.
+func DetectDivisionByZero(filename string, node *ast.File, fset *token.FileSet, info *types.Info, severity tt.Severity) ([]tt.Issue, error) {
+	consts := make(map[string]bool)
+	collectConsts(node, consts)
+	fileAssigns := collectAssignments(node)
+	imports := make(map[string]bool)
+	for _, imp := range node.Imports {
+		imports[importName(imp)] = true
+	}
+
+	var issues []tt.Issue
+	for _, decl := range node.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok || fn.Body == nil {
+			continue
+		}
+
+		params := make(map[string]bool)
+		for _, field := range fn.Type.Params.List {
+			for _, name := range field.Names {
+				params[name.Name] = true
+			}
+		}
+		if fn.Recv != nil {
+			for _, field := range fn.Recv.List {
+				for _, name := range field.Names {
+					params[name.Name] = true
+				}
+			}
+		}
+
+		localConsts := make(map[string]bool, len(consts))
+		for name := range consts {
+			localConsts[name] = true
+		}
+		collectConsts(fn.Body, localConsts)
+		dc := &divisionChecker{
+			consts:  localConsts,
+			imports: imports,
+			params:  params,
+			assigns: collectAssignments(fn.Body),
+			globals: fileAssigns,
+		}
+
+		ast.Inspect(fn.Body, func(n ast.Node) bool {
+			var divisor ast.Expr
+			var op token.Token
+			switch expr := n.(type) {
+			case *ast.BinaryExpr:
+				if expr.Op != token.QUO && expr.Op != token.REM {
+					return true
+				}
+				if isFloatLiteral(expr.X) || isFloatLiteral(expr.Y) || !mayBeInteger(info, expr) {
+					return true
+				}
+				divisor, op = expr.Y, expr.Op
+			case *ast.AssignStmt:
+				if expr.Tok != token.QUO_ASSIGN && expr.Tok != token.REM_ASSIGN || !mayBeInteger(info, expr.Lhs[0]) {
+					return true
+				}
+				divisor, op = expr.Rhs[0], token.QUO
+				if expr.Tok == token.REM_ASSIGN {
+					op = token.REM
+				}
+			default:
+				return true
+			}
+
+			divisor = ast.Unparen(divisor)
+			if !dc.mayBeZero(divisor) || isGuarded(fn.Body, n, types.ExprString(divisor)) {
+				return true
+			}
+
+			kind := "division"
+			if op == token.REM {
+				kind = "modulo"
+			}
+			confidence := divisionConfidence
+			if dc.fromCall(divisor) {
+				confidence = divisionCallConfidence
+			}
+
+			issues = append(issues, tt.Issue{
+				Rule:       "division-by-zero",
+				Filename:   filename,
+				Start:      fset.Position(n.Pos()),
+				End:        fset.Position(n.End()),
+				Message:    fmt.Sprintf("possible %s by zero: %s is not checked against zero", kind, types.ExprString(divisor)),
+				Note:       fmt.Sprintf("an integer division by zero panics and aborts the transaction; handle the case where %s is zero first", types.ExprString(divisor)),
+				Confidence: confidence,
+				Severity:   severity,
+			})
+			return true
+		})
+	}
+	return issues, nil
+}
+
+type divisionChecker struct {
+	consts  map[string]bool
+	imports map[string]bool
+	params  map[string]bool
+	assigns map[string][]ast.Expr // values assigned in the function, nil for unknown values
+	globals map[string][]ast.Expr // values assigned anywhere in the file
+}
+
+// mayBeZero reports whether the divisor is a variable or call result that is
+// not known to be a non-zero constant.
+func (dc *divisionChecker) mayBeZero(divisor ast.Expr) bool {
+	switch d := divisor.(type) {
+	case *ast.Ident:
+		if dc.consts[d.Name] {
+			return false
+		}
+		if dc.params[d.Name] {
+			return true
+		}
+		values, ok := dc.assigns[d.Name]
+		if !ok {
+			values = dc.globals[d.Name]
+		}
+		if len(values) == 0 {
+			return true
+		}
+		for _, value := range values {
+			if !dc.isNonZeroConstant(value) {
+				return true
+			}
+		}
+		return false
+	case *ast.CallExpr, *ast.SelectorExpr, *ast.IndexExpr, *ast.StarExpr:
+		return !dc.isConstant(divisor)
+	}
+	return false
+}
+
+// fromCall reports whether the divisor is, or was assigned from, a call result.
+func (dc *divisionChecker) fromCall(divisor ast.Expr) bool {
+	if _, ok := divisor.(*ast.CallExpr); ok {
+		return true
+	}
+	if ident, ok := divisor.(*ast.Ident); ok {
+		for _, value := range dc.assigns[ident.Name] {
+			if _, ok := value.(*ast.CallExpr); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (dc *divisionChecker) isConstant(expr ast.Expr) bool {
+	switch e := ast.Unparen(expr).(type) {
+	case *ast.BasicLit:
+		return true
+	case *ast.Ident:
+		return dc.consts[e.Name]
+	case *ast.SelectorExpr:
+		// values exported by other packages, such as time.Second, are
+		// almost always constants
+		x, ok := e.X.(*ast.Ident)
+		return ok && dc.imports[x.Name]
+	case *ast.UnaryExpr:
+		return dc.isConstant(e.X)
+	case *ast.BinaryExpr:
+		return dc.isConstant(e.X) && dc.isConstant(e.Y)
+	case *ast.CallExpr:
+		// conversion of a constant, such as int64(10)
+		_, isIdent := e.Fun.(*ast.Ident)
+		return isIdent && len(e.Args) == 1 && dc.isConstant(e.Args[0])
+	}
+	return false
+}
+
+func (dc *divisionChecker) isNonZeroConstant(expr ast.Expr) bool {
+	if expr == nil || !dc.isConstant(expr) {
+		return false
+	}
+	if lit, ok := ast.Unparen(expr).(*ast.BasicLit); ok {
+		value := constant.MakeFromLiteral(lit.Value, lit.Kind, 0)
+		return value.Kind() == constant.Unknown || constant.Sign(value) != 0
+	}
+	return true
+}
+
+// collectConsts records the names of the constants declared directly in node.
+func collectConsts(node ast.Node, consts map[string]bool) {
+	ast.Inspect(node, func(n ast.Node) bool {
+		gen, ok := n.(*ast.GenDecl)
+		if !ok || gen.Tok != token.CONST {
+			return true
+		}
+		for _, spec := range gen.Specs {
+			for _, name := range spec.(*ast.ValueSpec).Names {
+				consts[name.Name] = true
+			}
+		}
+		return false
+	})
+}
+
+// collectAssignments records, per variable name, the values assigned to it.
+// Values that cannot be known, such as increments or range keys, are recorded as nil.
+func collectAssignments(node ast.Node) map[string][]ast.Expr {
+	assigns := make(map[string][]ast.Expr)
+	record := func(lhs ast.Expr, value ast.Expr) {
+		if ident, ok := lhs.(*ast.Ident); ok && ident.Name != "_" {
+			assigns[ident.Name] = append(assigns[ident.Name], value)
+		}
+	}
+
+	ast.Inspect(node, func(n ast.Node) bool {
+		switch stmt := n.(type) {
+		case *ast.AssignStmt:
+			for i, lhs := range stmt.Lhs {
+				var value ast.Expr
+				if len(stmt.Lhs) == len(stmt.Rhs) && (stmt.Tok == token.ASSIGN || stmt.Tok == token.DEFINE) {
+					value = stmt.Rhs[i]
+				}
+				record(lhs, value)
+			}
+		case *ast.IncDecStmt:
+			record(stmt.X, nil)
+		case *ast.RangeStmt:
+			if stmt.Key != nil {
+				record(stmt.Key, nil)
+			}
+			if stmt.Value != nil {
+				record(stmt.Value, nil)
+			}
+		case *ast.ValueSpec:
+			for i, name := range stmt.Names {
+				var value ast.Expr
+				if i < len(stmt.Values) {
+					value = stmt.Values[i]
+				}
+				record(name, value)
+			}
+		case *ast.UnaryExpr:
+			// taking the address allows writes we cannot follow
+			if stmt.Op == token.AND {
+				record(stmt.X, nil)
+			}
+		}
+		return true
+	})
+	return assigns
+}
+
+// isGuarded reports whether the division node is protected by a zero check
+// on the divisor within body.
+func isGuarded(body *ast.BlockStmt, node ast.Node, divisor string) bool {
+	path := pathTo(body, node)
+	for i := 0; i < len(path)-1; i++ {
+		child := path[i+1]
+		switch parent := path[i].(type) {
+		case *ast.IfStmt:
+			if child == parent.Body && impliesNonZero(parent.Cond, divisor) {
+				return true
+			}
+			if child == parent.Else && impliesZero(parent.Cond, divisor) {
+				return true
+			}
+		case *ast.ForStmt:
+			if child == parent.Body && parent.Cond != nil && comparesDivisor(parent.Cond, divisor) {
+				return true
+			}
+		case *ast.BlockStmt:
+			for _, stmt := range parent.List {
+				if stmt == child {
+					break
+				}
+				if ifStmt, ok := stmt.(*ast.IfStmt); ok && impliesZero(ifStmt.Cond, divisor) && terminates(ifStmt.Body) {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// pathTo returns the chain of nodes from root down to target.
+func pathTo(root, target ast.Node) []ast.Node {
+	var stack, path []ast.Node
+	ast.Inspect(root, func(n ast.Node) bool {
+		if path != nil {
+			return false
+		}
+		if n == nil {
+			stack = stack[:len(stack)-1]
+			return true
+		}
+		stack = append(stack, n)
+		if n == target {
+			path = append([]ast.Node(nil), stack...)
+			return false
+		}
+		return true
+	})
+	return path
+}
+
+// impliesNonZero reports whether cond being true means the divisor is not zero.
+func impliesNonZero(cond ast.Expr, divisor string) bool {
+	cond = ast.Unparen(cond)
+	if bin, ok := cond.(*ast.BinaryExpr); ok && bin.Op == token.LAND {
+		return impliesNonZero(bin.X, divisor) || impliesNonZero(bin.Y, divisor)
+	}
+	op, ok := zeroComparison(cond, divisor)
+	return ok && (op == token.NEQ || op == token.GTR)
+}
+
+// impliesZero reports whether cond being false means the divisor is not zero.
+func impliesZero(cond ast.Expr, divisor string) bool {
+	cond = ast.Unparen(cond)
+	if bin, ok := cond.(*ast.BinaryExpr); ok && bin.Op == token.LOR {
+		return impliesZero(bin.X, divisor) || impliesZero(bin.Y, divisor)
+	}
+	op, ok := zeroComparison(cond, divisor)
+	return ok && (op == token.EQL || op == token.LEQ)
+}
+
+// zeroComparison matches `divisor <op> 0` and `0 <op> divisor`, normalizing the
+// operator so that the divisor is on the left.
+func zeroComparison(expr ast.Expr, divisor string) (token.Token, bool) {
+	bin, ok := expr.(*ast.BinaryExpr)
+	if !ok {
+		return 0, false
+	}
+	if types.ExprString(ast.Unparen(bin.X)) == divisor && isZeroLiteral(bin.Y) {
+		return bin.Op, true
+	}
+	if types.ExprString(ast.Unparen(bin.Y)) == divisor && isZeroLiteral(bin.X) {
+		switch bin.Op {
+		case token.LSS:
+			return token.GTR, true
+		case token.GEQ:
+			return token.LEQ, true
+		}
+		return bin.Op, true
+	}
+	return 0, false
+}
+
+// comparesDivisor reports whether cond contains a comparison involving the divisor.
+func comparesDivisor(cond ast.Expr, divisor string) bool {
+	found := false
+	ast.Inspect(cond, func(n ast.Node) bool {
+		bin, ok := n.(*ast.BinaryExpr)
+		if !ok {
+			return !found
+		}
+		switch bin.Op {
+		case token.LSS, token.LEQ, token.GTR, token.GEQ, token.NEQ:
+			if types.ExprString(ast.Unparen(bin.X)) == divisor || types.ExprString(ast.Unparen(bin.Y)) == divisor {
+				found = true
+			}
+		}
+		return !found
+	})
+	return found
+}
+
+// terminates reports whether the block always leaves the enclosing flow.
+func terminates(block *ast.BlockStmt) bool {
+	if len(block.List) == 0 {
+		return false
+	}
+	switch last := block.List[len(block.List)-1].(type) {
+	case *ast.ReturnStmt, *ast.BranchStmt:
+		return true
+	case *ast.ExprStmt:
+		call, ok := last.X.(*ast.CallExpr)
+		if !ok {
+			return false
+		}
+		ident, ok := call.Fun.(*ast.Ident)
+		return ok && ident.Name == "panic"
+	}
+	return false
+}
+
+func isZeroLiteral(expr ast.Expr) bool {
+	lit, ok := ast.Unparen(expr).(*ast.BasicLit)
+	return ok && (lit.Kind == token.INT || lit.Kind == token.FLOAT) && constant.Sign(constant.MakeFromLiteral(lit.Value, lit.Kind, 0)) == 0
+}
+
+// mayBeInteger reports whether an expression has an integer type, or a type
+// the type information doesn't tell.
+func mayBeInteger(info *types.Info, expr ast.Expr) bool {
+	if info == nil {
+		return true
+	}
+	t := info.TypeOf(expr)
+	if t == nil {
+		return true
+	}
+	basic, ok := t.Underlying().(*types.Basic)
+	if !ok || basic.Kind() == types.Invalid {
+		// type parameters and types that did not check
+		return true
+	}
+	return basic.Info()&types.IsInteger != 0
+}
+
+func isFloatLiteral(expr ast.Expr) bool {
+	lit, ok := ast.Unparen(expr).(*ast.BasicLit)
+	return ok && lit.Kind == token.FLOAT
+}
+
+// importName returns the name an import is referred to by in the file.
+func importName(imp *ast.ImportSpec) string {
+	if imp.Name != nil {
+		return imp.Name.Name
+	}
+	return getLastPart(strings.Trim(imp.Path.Value, `"`))
+}
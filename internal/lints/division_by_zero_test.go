@@ -0,0 +1,231 @@
+package lints
+
+import (
+	"go/parser"
+	"go/token"
+	"testing"
+
+	tt "github.com/gnolang/tlin/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetectDivisionByZero(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name       string
+		code       string
+		messages   []string
+		confidence []float64
+	}{
+		{
+			name: "unchecked parameter",
+			code: `
+package foo
+
+func share(total, n int) int {
+	return total / n
+}`,
+			messages:   []string{"possible division by zero: n is not checked against zero"},
+			confidence: []float64{0.7},
+		},
+		{
+			name: "modulo and compound assignment",
+			code: `
+package foo
+
+func f(a, b int) int {
+	a %= b
+	return a % b
+}`,
+			messages: []string{
+				"possible modulo by zero: b is not checked against zero",
+				"possible modulo by zero: b is not checked against zero",
+			},
+			confidence: []float64{0.7, 0.7},
+		},
+		{
+			name: "call result has lower confidence",
+			code: `
+package foo
+
+func avg(values []int, sum int) int {
+	n := count(values)
+	return sum / n + sum / len(values)
+}`,
+			messages: []string{
+				"possible division by zero: n is not checked against zero",
+				"possible division by zero: len(values) is not checked against zero",
+			},
+			confidence: []float64{0.5, 0.5},
+		},
+		{
+			name: "early return guard",
+			code: `
+package foo
+
+func share(total, n int) int {
+	if n == 0 {
+		return 0
+	}
+	return total / n
+}`,
+		},
+		{
+			name: "early panic guard in a disjunction",
+			code: `
+package foo
+
+func share(total, n int) int {
+	if total < 0 || n <= 0 {
+		panic("invalid")
+	}
+	return total / n
+}`,
+		},
+		{
+			name: "non-zero branch",
+			code: `
+package foo
+
+func share(total, n int) int {
+	if n != 0 && total > 0 {
+		return total / n
+	}
+	if 0 == n {
+		return 0
+	} else {
+		return total % n
+	}
+}`,
+		},
+		{
+			name: "guard that does not return",
+			code: `
+package foo
+
+func share(total, n int) int {
+	if n == 0 {
+		println("zero")
+	}
+	return total / n
+}`,
+			messages:   []string{"possible division by zero: n is not checked against zero"},
+			confidence: []float64{0.7},
+		},
+		{
+			name: "loop condition bounds the divisor",
+			code: `
+package foo
+
+func f(x int) int {
+	for d := x; d > 0; d-- {
+		x = x / d
+	}
+	return x
+}`,
+		},
+		{
+			name: "range key can be zero",
+			code: `
+package foo
+
+func f(xs []int) int {
+	total := 0
+	for i := range xs {
+		total += xs[i] / i
+	}
+	return total
+}`,
+			messages:   []string{"possible division by zero: i is not checked against zero"},
+			confidence: []float64{0.7},
+		},
+		{
+			name: "constants and constant assignments",
+			code: `
+package foo
+
+import "time"
+
+const decimals = 6
+
+var factor = 100
+
+func f(x int, d time.Duration) int {
+	const local = 3
+	scale := 10 * decimals
+	_ = d / time.Second
+	return x / decimals / factor / local / scale / 2 / int64(4)
+}`,
+		},
+		{
+			name: "variable assigned zero",
+			code: `
+package foo
+
+func f(x int) int {
+	d := 0
+	return x / d
+}`,
+			messages:   []string{"possible division by zero: d is not checked against zero"},
+			confidence: []float64{0.7},
+		},
+		{
+			name: "float division",
+			code: `
+package foo
+
+func f(x float64, y float64) float64 {
+	return 1.0 / y
+}`,
+		},
+		{
+			name: "float conversions",
+			code: `
+package foo
+
+func mean(a, b int) float64 {
+	x := float64(b)
+	x /= float64(b)
+	return float64(a) / float64(b) + x
+}`,
+		},
+		{
+			name: "named integer type",
+			code: `
+package foo
+
+type Amount int64
+
+func split(total, n Amount) Amount {
+	return total % n
+}`,
+			messages:   []string{"possible modulo by zero: n is not checked against zero"},
+			confidence: []float64{0.7},
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			fset := token.NewFileSet()
+			node, err := parser.ParseFile(fset, "test.gno", tc.code, 0)
+			require.NoError(t, err)
+
+			pkg := NewPackage(fset, &PackageFile{Filename: "test.gno", File: node})
+			issues, err := CheckTyped(pkg, DetectDivisionByZero, tt.SeverityWarning)
+			require.NoError(t, err)
+			require.Len(t, issues, len(tc.messages))
+
+			for i, issue := range issues {
+				assert.Equal(t, "division-by-zero", issue.Rule)
+				assert.Equal(t, tc.messages[i], issue.Message)
+				assert.Equal(t, tc.confidence[i], issue.Confidence)
+				// a zero check can't be written for the code, -fix leaves it
+				assert.Empty(t, issue.Suggestion)
+				assert.Empty(t, issue.SuggestedFix)
+			}
+		})
+	}
+}
@@ -0,0 +1,203 @@
+package lints
+
+import (
+	"fmt"
+	"go/ast"
+	"go/constant"
+	"go/token"
+	"sort"
+	"strings"
+
+	tt "github.com/gnolang/tlin/internal/types"
+)
+
+// ConstantSignificance filters out the constants whose values are too common
+// to be worth deduplicating.
+type ConstantSignificance struct {
+	// MinInt is the smallest absolute integer value considered.
+	MinInt int64
+	// MinStringLength is the length of the shortest string value considered.
+	MinStringLength int
+}
+
+type constDecl struct {
+	name     string
+	value    constant.Value
+	ident    *ast.Ident
+	filename string
+}
+
+// DetectDuplicateConstants reports untyped string and integer constants of the
+// package holding the same value under different names, and constants of the
+// same name holding different values in different files, as happens with
+// build tag variants. Values below the significance thresholds are skipped.
+func DetectDuplicateConstants(pkg *Package, severity tt.Severity, significance ConstantSignificance) ([]tt.Issue, error) {
+	var consts []constDecl
+	for _, file := range pkg.Files {
+		if file.IsTest() {
+			continue
+		}
+		consts = append(consts, untypedConsts(file)...)
+	}
+
+	var issues []tt.Issue
+	issues = append(issues, duplicateValueIssues(pkg.Fset, consts, severity, significance)...)
+	issues = append(issues, variantValueIssues(pkg.Fset, consts, severity)...)
+	return issues, nil
+}
+
+func duplicateValueIssues(fset *token.FileSet, consts []constDecl, severity tt.Severity, significance ConstantSignificance) []tt.Issue {
+	var order []string
+	byValue := make(map[string][]constDecl)
+	for _, c := range consts {
+		if !significance.keeps(c.value) {
+			continue
+		}
+		key := c.value.Kind().String() + ":" + c.value.ExactString()
+		if _, seen := byValue[key]; !seen {
+			order = append(order, key)
+		}
+		byValue[key] = append(byValue[key], c)
+	}
+
+	var issues []tt.Issue
+	for _, key := range order {
+		group := byValue[key]
+		names := distinctNames(group)
+		if len(names) < 2 {
+			continue
+		}
+
+		first := group[0]
+		var related []tt.Location
+		for _, c := range group[1:] {
+			if c.name != first.name {
+				related = append(related, tt.Location{Position: fset.Position(c.ident.Pos()), Label: "also declared as " + c.name})
+			}
+		}
+		issues = append(issues, tt.Issue{
+			Rule:     "duplicate-constant",
+			Filename: first.filename,
+			Start:    fset.Position(first.ident.Pos()),
+			End:      fset.Position(first.ident.End()),
+			Message:  fmt.Sprintf("value %s is declared by several constants: %s", first.value.ExactString(), strings.Join(names, ", ")),
+			Note:     "declare the value once so the copies cannot drift apart",
+			Severity: severity,
+			Related:  related,
+		})
+	}
+	return issues
+}
+
+func variantValueIssues(fset *token.FileSet, consts []constDecl, severity tt.Severity) []tt.Issue {
+	var order []string
+	byName := make(map[string][]constDecl)
+	for _, c := range consts {
+		if _, seen := byName[c.name]; !seen {
+			order = append(order, c.name)
+		}
+		byName[c.name] = append(byName[c.name], c)
+	}
+
+	var issues []tt.Issue
+	for _, name := range order {
+		group := byName[name]
+		first := group[0]
+
+		var related []tt.Location
+		for _, c := range group[1:] {
+			if c.filename != first.filename && !constant.Compare(c.value, token.EQL, first.value) {
+				related = append(related, tt.Location{
+					Position: fset.Position(c.ident.Pos()),
+					Label:    "declared as " + c.value.ExactString() + " here",
+				})
+			}
+		}
+		if len(related) == 0 {
+			continue
+		}
+		issues = append(issues, tt.Issue{
+			Rule:     "duplicate-constant",
+			Filename: first.filename,
+			Start:    fset.Position(first.ident.Pos()),
+			End:      fset.Position(first.ident.End()),
+			Message:  fmt.Sprintf("constant %s has different values across build variants", name),
+			Severity: severity,
+			Related:  related,
+		})
+	}
+	return issues
+}
+
+func (s ConstantSignificance) keeps(value constant.Value) bool {
+	switch value.Kind() {
+	case constant.Int:
+		abs := value
+		if constant.Sign(value) < 0 {
+			abs = constant.UnaryOp(token.SUB, value, 0)
+		}
+		return constant.Compare(abs, token.GEQ, constant.MakeInt64(s.MinInt))
+	case constant.String:
+		return len(constant.StringVal(value)) >= s.MinStringLength
+	}
+	return false
+}
+
+// untypedConsts returns the untyped constants of the file initialized with a
+// string or integer literal.
+func untypedConsts(file *PackageFile) []constDecl {
+	var consts []constDecl
+	for _, decl := range file.File.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.CONST {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			vs := spec.(*ast.ValueSpec)
+			if vs.Type != nil || len(vs.Values) != len(vs.Names) {
+				continue
+			}
+			for i, name := range vs.Names {
+				value := literalValue(vs.Values[i])
+				if value == nil || name.Name == "_" {
+					continue
+				}
+				consts = append(consts, constDecl{name: name.Name, value: value, ident: name, filename: file.Filename})
+			}
+		}
+	}
+	return consts
+}
+
+// literalValue evaluates an integer or string literal, possibly negated.
+func literalValue(expr ast.Expr) constant.Value {
+	negate := false
+	if unary, ok := expr.(*ast.UnaryExpr); ok && unary.Op == token.SUB {
+		negate, expr = true, unary.X
+	}
+	lit, ok := expr.(*ast.BasicLit)
+	if !ok || (lit.Kind != token.INT && lit.Kind != token.STRING) || (negate && lit.Kind != token.INT) {
+		return nil
+	}
+	value := constant.MakeFromLiteral(lit.Value, lit.Kind, 0)
+	if value.Kind() == constant.Unknown {
+		return nil
+	}
+	if negate {
+		value = constant.UnaryOp(token.SUB, value, 0)
+	}
+	return value
+}
+
+func distinctNames(group []constDecl) []string {
+	seen := make(map[string]bool)
+	var names []string
+	for _, c := range group {
+		if !seen[c.name] {
+			seen[c.name] = true
+			names = append(names, c.name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,135 @@
+package lints
+
+import (
+	"testing"
+
+	tt "github.com/gnolang/tlin/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var defaultSignificance = ConstantSignificance{MinInt: 2, MinStringLength: 1}
+
+func TestDetectDuplicateConstants(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name         string
+		files        map[string]string
+		significance ConstantSignificance
+		messages     []string
+		related      []int
+	}{
+		{
+			name: "same value under different names",
+			files: map[string]string{
+				"a.gno": `package token
+
+const denom = "ugnot"
+
+const Decimals = 1_000_000
+`,
+				"b.gno": `package token
+
+const (
+	nativeDenom = "ugnot"
+	factor      = 1000000
+	other       = 0xF4240
+)
+`,
+			},
+			significance: defaultSignificance,
+			messages: []string{
+				`value "ugnot" is declared by several constants: denom, nativeDenom`,
+				"value 1000000 is declared by several constants: Decimals, factor, other",
+			},
+			related: []int{1, 2},
+		},
+		{
+			name: "small and typed values are skipped",
+			files: map[string]string{
+				"a.gno": `package token
+
+const (
+	zero  = 0
+	none  = 0
+	one   = 1
+	first = 1
+	empty = ""
+	blank = ""
+	minus = -1
+	neg   = -1
+)
+
+type Amount int
+
+const (
+	fee    Amount = 100
+	reward Amount = 100
+)
+`,
+			},
+			significance: defaultSignificance,
+		},
+		{
+			name: "thresholds are configurable",
+			files: map[string]string{
+				"a.gno": `package token
+
+const (
+	short  = "ab"
+	abbrev = "ab"
+	big    = 100
+	large  = 100
+)
+`,
+			},
+			significance: ConstantSignificance{MinInt: 1000, MinStringLength: 3},
+		},
+		{
+			name: "different values across build variants",
+			files: map[string]string{
+				"chain_main.gno": `//go:build mainnet
+
+package token
+
+const chainID = "main"
+`,
+				"chain_test_net.gno": `//go:build !mainnet
+
+package token
+
+const chainID = "testnet"
+`,
+			},
+			significance: defaultSignificance,
+			messages:     []string{"constant chainID has different values across build variants"},
+			related:      []int{1},
+		},
+		{
+			name: "same name and value across variants is fine",
+			files: map[string]string{
+				"a.gno": "//go:build a\n\npackage token\n\nconst fee = 10\n",
+				"b.gno": "//go:build !a\n\npackage token\n\nconst fee = 10\n",
+			},
+			significance: defaultSignificance,
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			pkg := parsePackage(t, tc.files)
+
+			issues, err := DetectDuplicateConstants(pkg, tt.SeverityWarning, tc.significance)
+			require.NoError(t, err)
+			require.Len(t, issues, len(tc.messages))
+
+			for i, issue := range issues {
+				assert.Equal(t, "duplicate-constant", issue.Rule)
+				assert.Equal(t, tc.messages[i], issue.Message)
+				assert.Len(t, issue.Related, tc.related[i])
+			}
+		})
+	}
+}
@@ -0,0 +1,222 @@
+package lints
+
+import (
+	"fmt"
+	"go/ast"
+	"hash/fnv"
+	"math"
+	"reflect"
+	"sort"
+
+	tt "github.com/gnolang/tlin/internal/types"
+)
+
+const (
+	// duplicateKGram is the number of consecutive tokens hashed together.
+	duplicateKGram = 5
+	// duplicateWindow is the number of consecutive k-gram hashes the
+	// smallest one is kept from. Any run of duplicateKGram+duplicateWindow-1
+	// tokens shared by two bodies yields a shared fingerprint.
+	duplicateWindow = 4
+)
+
+// DetectDuplicateFunctions reports the pairs of functions of a package whose
+// bodies are near duplicates, such as a Withdraw copied from Deposit with a
+// single operator changed.
+//
+// Bodies are reduced to the kinds of their syntax nodes, operators included,
+// so that names and literals do not matter: identical bodies calling
+// different functions are reported too. The similarity of two bodies is the
+// share of the fingerprints they have in common, selected from the hashes of
+// their token k-grams by winnowing. Bodies shorter than minTokens are left
+// out, as short functions look alike.
+func DetectDuplicateFunctions(pkg *Package, severity tt.Severity, threshold float64, minTokens int) ([]tt.Issue, error) {
+	var bodies []*functionBody
+	for _, file := range pkg.Files {
+		for _, decl := range file.File.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok || fn.Body == nil {
+				continue
+			}
+			tokens := bodyTokens(fn.Body)
+			if len(tokens) < minTokens {
+				continue
+			}
+			bodies = append(bodies, &functionBody{
+				file:         file,
+				decl:         fn,
+				fingerprints: winnow(tokens),
+			})
+		}
+	}
+
+	// larger bodies first, so that the size bound below stops the inner loop
+	sort.SliceStable(bodies, func(i, j int) bool {
+		return len(bodies[i].fingerprints) > len(bodies[j].fingerprints)
+	})
+
+	var issues []tt.Issue
+	for i, a := range bodies {
+		for _, b := range bodies[i+1:] {
+			// the similarity cannot exceed the ratio of the sizes
+			if float64(len(b.fingerprints)) < threshold*float64(len(a.fingerprints)) {
+				break
+			}
+			similarity := jaccard(a.fingerprints, b.fingerprints)
+			if similarity < threshold {
+				continue
+			}
+
+			// the issue goes on the function declared last, the likely copy
+			first, second := a, b
+			if second.file.Filename < first.file.Filename ||
+				(second.file == first.file && second.decl.Pos() < first.decl.Pos()) {
+				first, second = second, first
+			}
+			percent := int(math.Floor(similarity * 100))
+			issues = append(issues, tt.Issue{
+				Rule:     "duplicate-function",
+				Filename: second.file.Filename,
+				Start:    pkg.Fset.Position(second.decl.Name.Pos()),
+				End:      pkg.Fset.Position(second.decl.Name.End()),
+				Message: fmt.Sprintf("the body of %s is %d%% similar to the one of %s",
+					functionName(second.decl), percent, functionName(first.decl)),
+				Note:     "check that the differences between the two are intended, or share the common part in a helper",
+				Severity: severity,
+				Related: []tt.Location{
+					{Position: pkg.Fset.Position(first.decl.Name.Pos()), Label: functionName(first.decl) + " declared here"},
+				},
+			})
+		}
+	}
+
+	sort.SliceStable(issues, func(i, j int) bool {
+		if issues[i].Filename != issues[j].Filename {
+			return issues[i].Filename < issues[j].Filename
+		}
+		return issues[i].Start.Offset < issues[j].Start.Offset
+	})
+	return issues, nil
+}
+
+type functionBody struct {
+	file         *PackageFile
+	decl         *ast.FuncDecl
+	fingerprints []uint64 // sorted, without duplicates
+}
+
+// functionName returns the name of a function, qualified with its receiver
+// type for methods.
+func functionName(fn *ast.FuncDecl) string {
+	if fn.Recv != nil && len(fn.Recv.List) == 1 {
+		if recv, _ := receiverType(fn.Recv.List[0].Type); recv != "" {
+			return recv + "." + fn.Name.Name
+		}
+	}
+	return fn.Name.Name
+}
+
+// bodyTokens returns the hashes of the node kinds of a body in visiting
+// order, with the operators of the expressions and statements having one,
+// and a marker closing each node.
+func bodyTokens(body *ast.BlockStmt) []uint64 {
+	var tokens []uint64
+	ast.Inspect(body, func(n ast.Node) bool {
+		if n == nil {
+			tokens = append(tokens, hashToken(")"))
+			return true
+		}
+		kind := reflect.TypeOf(n).Elem().Name()
+		switch n := n.(type) {
+		case *ast.BinaryExpr:
+			kind += n.Op.String()
+		case *ast.UnaryExpr:
+			kind += n.Op.String()
+		case *ast.AssignStmt:
+			kind += n.Tok.String()
+		case *ast.IncDecStmt:
+			kind += n.Tok.String()
+		case *ast.BranchStmt:
+			kind += n.Tok.String()
+		case *ast.BasicLit:
+			kind += n.Kind.String()
+		case *ast.Ident:
+			// keep the constants changing the meaning of the code
+			if n.Name == "nil" || n.Name == "true" || n.Name == "false" {
+				kind += n.Name
+			}
+		}
+		tokens = append(tokens, hashToken(kind))
+		return true
+	})
+	return tokens
+}
+
+func hashToken(s string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(s))
+	return h.Sum64()
+}
+
+// winnow returns the fingerprints of a token sequence: the smallest hash of
+// every window of consecutive k-gram hashes.
+func winnow(tokens []uint64) []uint64 {
+	if len(tokens) < duplicateKGram {
+		return nil
+	}
+	grams := make([]uint64, 0, len(tokens)-duplicateKGram+1)
+	for i := 0; i+duplicateKGram <= len(tokens); i++ {
+		var h uint64 = 14695981039346656037
+		for _, token := range tokens[i : i+duplicateKGram] {
+			h ^= token
+			h *= 1099511628211
+		}
+		grams = append(grams, h)
+	}
+
+	seen := make(map[uint64]bool)
+	var fingerprints []uint64
+	for i := 0; i < len(grams); i++ {
+		end := i + duplicateWindow
+		if end > len(grams) {
+			if i > 0 {
+				break
+			}
+			end = len(grams)
+		}
+		smallest := grams[i]
+		for _, h := range grams[i+1 : end] {
+			if h < smallest {
+				smallest = h
+			}
+		}
+		if !seen[smallest] {
+			seen[smallest] = true
+			fingerprints = append(fingerprints, smallest)
+		}
+	}
+	sort.Slice(fingerprints, func(i, j int) bool { return fingerprints[i] < fingerprints[j] })
+	return fingerprints
+}
+
+// jaccard returns the size of the intersection of two sorted sets over the
+// size of their union.
+func jaccard(a, b []uint64) float64 {
+	if len(a) == 0 && len(b) == 0 {
+		return 0
+	}
+	shared := 0
+	for i, j := 0, 0; i < len(a) && j < len(b); {
+		switch {
+		case a[i] == b[j]:
+			shared++
+			i++
+			j++
+		case a[i] < b[j]:
+			i++
+		default:
+			j++
+		}
+	}
+	return float64(shared) / float64(len(a)+len(b)-shared)
+}
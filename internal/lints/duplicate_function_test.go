@@ -0,0 +1,161 @@
+package lints
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	tt "github.com/gnolang/tlin/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const bankSource = `package bank
+
+func Deposit(amount int64) {
+	caller := std.PreviousRealm().Address()
+	if amount <= 0 {
+		panic("invalid amount")
+	}
+	balance := balances[caller]
+	balance += amount
+	balances[caller] = balance
+	total += amount
+	std.Emit("Deposit", "caller", caller.String(), "amount", strconv.Itoa(int(amount)))
+}
+`
+
+func TestDetectDuplicateFunctions(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name     string
+		files    map[string]string
+		messages []string
+	}{
+		{
+			name: "operator flipped in a copy",
+			files: map[string]string{
+				"pkg/bank.gno": bankSource + `
+func Withdraw(amount int64) {
+	caller := std.PreviousRealm().Address()
+	if amount <= 0 {
+		panic("invalid amount")
+	}
+	balance := balances[caller]
+	balance += amount
+	balances[caller] = balance
+	total -= amount
+	std.Emit("Withdraw", "caller", caller.String(), "amount", strconv.Itoa(int(amount)))
+}
+`,
+			},
+			messages: []string{"the body of Withdraw is 93% similar to the one of Deposit"},
+		},
+		{
+			name: "same body calling other functions in another file",
+			files: map[string]string{
+				"pkg/bank.gno": bankSource,
+				"pkg/vault.gno": `package bank
+
+func (v *Vault) Lock(amount int64) {
+	owner := chain.CurrentRealm().Owner()
+	if amount <= 0 {
+		abort("nothing to lock")
+	}
+	locked := lockedBalances[owner]
+	locked += amount
+	lockedBalances[owner] = locked
+	lockedTotal += amount
+	chain.Log("Lock", "owner", owner.Name(), "amount", fmt.Sprint(uint(amount)))
+}
+`,
+			},
+			messages: []string{"the body of Vault.Lock is 100% similar to the one of Deposit"},
+		},
+		{
+			name: "different bodies",
+			files: map[string]string{
+				"pkg/bank.gno": bankSource + `
+func Sum(values []int) int {
+	s := 0
+	for i, v := range values {
+		if i%2 == 0 {
+			s += v
+		} else {
+			s -= v * 2
+		}
+	}
+	for k, v := range index {
+		println(k, v)
+	}
+	return s
+}
+`,
+			},
+		},
+		{
+			name: "short bodies",
+			files: map[string]string{
+				"pkg/getters.gno": `package bank
+
+func Total() int64 { return total }
+
+func Supply() int64 { return supply }
+`,
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			pkg := parsePackage(t, tc.files)
+
+			issues, err := DetectDuplicateFunctions(pkg, tt.SeverityWarning, 0.85, 60)
+			require.NoError(t, err)
+
+			var messages []string
+			for _, issue := range issues {
+				assert.Equal(t, "duplicate-function", issue.Rule)
+				require.Len(t, issue.Related, 1)
+				assert.NotEqual(t, issue.Start, issue.Related[0].Position)
+				messages = append(messages, issue.Message)
+			}
+			assert.Equal(t, tc.messages, messages)
+		})
+	}
+}
+
+// BenchmarkDetectDuplicateFunctions compares every pair of 300 functions of
+// similar sizes, the worst case of the size bound.
+func BenchmarkDetectDuplicateFunctions(b *testing.B) {
+	var src strings.Builder
+	src.WriteString("package bank\n")
+	for i := 0; i < 300; i++ {
+		fmt.Fprintf(&src, `
+func f%d(values []int) int {
+	s := %d
+	for i, v := range values {
+		if i%%%d == 0 {
+			s += v * %d
+		}
+	}
+`, i, i, i%7+1, i)
+		for j := 0; j < i%5; j++ {
+			src.WriteString("\tif s > 10 {\n\t\ts--\n\t}\n")
+		}
+		for j := 0; j < i%3; j++ {
+			src.WriteString("\tfor s < 100 {\n\t\ts *= 2\n\t}\n")
+		}
+		src.WriteString("\treturn s\n}\n")
+	}
+	pkg := parsePackage(b, map[string]string{"pkg/bank.gno": src.String()})
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := DetectDuplicateFunctions(pkg, tt.SeverityWarning, 0.85, 60); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,179 @@
+package lints
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"strings"
+
+	tt "github.com/gnolang/tlin/internal/types"
+)
+
+const duplicateImportConfidence = 0.9
+
+// DetectDuplicateImports reports import paths imported more than once in a
+// file under different names, and aliases that repeat the name the package
+// would have anyway. When exactly one of the duplicates is referenced, the
+// others can be removed automatically.
+func DetectDuplicateImports(filename string, node *ast.File, fset *token.FileSet, severity tt.Severity) ([]tt.Issue, error) {
+	uses := packageUses(node)
+
+	var order []string
+	byPath := make(map[string][]*ast.ImportSpec)
+	parenthesized := make(map[*ast.ImportSpec]bool)
+	for _, decl := range node.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.IMPORT {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			imp := spec.(*ast.ImportSpec)
+			parenthesized[imp] = gen.Lparen.IsValid()
+			name := importName(imp)
+			if name == "_" || name == "." {
+				continue
+			}
+			path := strings.Trim(imp.Path.Value, `"`)
+			if _, seen := byPath[path]; !seen {
+				order = append(order, path)
+			}
+			byPath[path] = append(byPath[path], imp)
+		}
+	}
+
+	var issues []tt.Issue
+	for _, path := range order {
+		specs := byPath[path]
+		if len(specs) > 1 {
+			issues = append(issues, duplicateImportIssues(filename, fset, severity, path, specs, uses)...)
+			continue
+		}
+
+		imp := specs[0]
+		if imp.Name == nil || imp.Name.Name != getLastPart(path) {
+			continue
+		}
+		issue := tt.Issue{
+			Rule:     "duplicate-import",
+			Filename: filename,
+			Start:    fset.Position(imp.Pos()),
+			End:      fset.Position(imp.End()),
+			Message:  fmt.Sprintf("redundant alias %s for %s, the package already has this name", imp.Name.Name, path),
+			Severity: severity,
+		}
+		if imp.Comment == nil {
+			issue.Suggestion = importLine(imp.Path.Value, parenthesized[imp])
+			issue.Confidence = duplicateImportConfidence
+		}
+		issues = append(issues, issue)
+	}
+	return issues, nil
+}
+
+func duplicateImportIssues(
+	filename string,
+	fset *token.FileSet,
+	severity tt.Severity,
+	path string,
+	specs []*ast.ImportSpec,
+	uses map[string]int,
+) []tt.Issue {
+	var names, used []string
+	for _, imp := range specs {
+		name := importName(imp)
+		names = append(names, name)
+		if uses[name] > 0 {
+			used = append(used, name)
+		}
+	}
+
+	usage := "none of them is used"
+	switch len(used) {
+	case 1:
+		usage = "only " + used[0] + " is used"
+	case len(specs):
+		usage = "all of them are used"
+	default:
+		if len(used) > 0 {
+			usage = strings.Join(used, ", ") + " are used"
+		}
+	}
+	msg := fmt.Sprintf("%s is imported %d times, as %s; %s", path, len(specs), strings.Join(names, ", "), usage)
+
+	if len(used) != 1 {
+		first := specs[0]
+		var related []tt.Location
+		for _, imp := range specs[1:] {
+			related = append(related, tt.Location{Position: fset.Position(imp.Pos()), Label: "imported again as " + importName(imp)})
+		}
+		return []tt.Issue{{
+			Rule:     "duplicate-import",
+			Filename: filename,
+			Start:    fset.Position(first.Pos()),
+			End:      fset.Position(first.End()),
+			Message:  msg,
+			Severity: severity,
+			Related:  related,
+		}}
+	}
+
+	// one issue per unused duplicate, each removing its own line
+	var issues []tt.Issue
+	for _, imp := range specs {
+		name := importName(imp)
+		if name == used[0] {
+			continue
+		}
+		var related []tt.Location
+		for _, other := range specs {
+			if other != imp {
+				related = append(related, tt.Location{Position: fset.Position(other.Pos()), Label: "also imported as " + importName(other)})
+			}
+		}
+		issue := tt.Issue{
+			Rule:     "duplicate-import",
+			Filename: filename,
+			Start:    fset.Position(imp.Pos()),
+			End:      fset.Position(imp.End()),
+			Message:  msg,
+			Severity: severity,
+			Related:  related,
+		}
+		if imp.Comment == nil && imp.Doc == nil {
+			// the empty suggestion removes the import line
+			issue.Note = "remove this import"
+			issue.Confidence = duplicateImportConfidence
+		}
+		issues = append(issues, issue)
+	}
+	return issues
+}
+
+func importLine(path string, parenthesized bool) string {
+	if parenthesized {
+		return path
+	}
+	return "import " + path
+}
+
+// packageUses counts the selector expressions per package name, such as
+// `avl` in `avl.NewTree()`.
+func packageUses(node *ast.File) map[string]int {
+	uses := make(map[string]int)
+	for _, decl := range node.Decls {
+		if gen, ok := decl.(*ast.GenDecl); ok && gen.Tok == token.IMPORT {
+			continue
+		}
+		ast.Inspect(decl, func(n ast.Node) bool {
+			sel, ok := n.(*ast.SelectorExpr)
+			if !ok {
+				return true
+			}
+			if ident, ok := sel.X.(*ast.Ident); ok && ident.Obj == nil {
+				uses[ident.Name]++
+			}
+			return true
+		})
+	}
+	return uses
+}
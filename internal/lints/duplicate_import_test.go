@@ -0,0 +1,110 @@
+package lints
+
+import (
+	"go/parser"
+	"go/token"
+	"testing"
+
+	tt "github.com/gnolang/tlin/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetectDuplicateImports(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name        string
+		code        string
+		messages    []string
+		lines       []int
+		suggestions []string
+		fixable     []bool
+	}{
+		{
+			name: "one duplicate used",
+			code: `package foo
+
+import (
+	foo "gno.land/p/demo/avl"
+	tree "gno.land/p/demo/avl"
+	"strings"
+)
+
+var t = tree.NewTree()
+var s = strings.ToUpper("a")
+`,
+			messages:    []string{"gno.land/p/demo/avl is imported 2 times, as foo, tree; only tree is used"},
+			lines:       []int{4},
+			suggestions: []string{""},
+			fixable:     []bool{true},
+		},
+		{
+			name: "both duplicates used",
+			code: `package foo
+
+import (
+	foo "gno.land/p/demo/avl"
+	tree "gno.land/p/demo/avl"
+)
+
+var a = foo.NewTree()
+var b = tree.NewTree()
+`,
+			messages:    []string{"gno.land/p/demo/avl is imported 2 times, as foo, tree; all of them are used"},
+			lines:       []int{4},
+			suggestions: []string{""},
+			fixable:     []bool{false},
+		},
+		{
+			name: "redundant aliases",
+			code: `package foo
+
+import avl "gno.land/p/demo/avl"
+
+import (
+	ufmt "gno.land/p/demo/ufmt"
+	seqid "gno.land/p/demo/seqid" // keep the alias comment
+	_ "gno.land/p/demo/side"
+	json "gno.land/p/demo/json.v2"
+)
+
+var (
+	_ = avl.NewTree()
+	_ = ufmt.Sprintf("")
+	_ = seqid.ID(0)
+	_ = json.Marshal
+)
+`,
+			messages: []string{
+				"redundant alias avl for gno.land/p/demo/avl, the package already has this name",
+				"redundant alias ufmt for gno.land/p/demo/ufmt, the package already has this name",
+				"redundant alias seqid for gno.land/p/demo/seqid, the package already has this name",
+			},
+			lines:       []int{3, 6, 7},
+			suggestions: []string{`import "gno.land/p/demo/avl"`, `"gno.land/p/demo/ufmt"`, ""},
+			fixable:     []bool{true, true, false},
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			fset := token.NewFileSet()
+			node, err := parser.ParseFile(fset, "test.gno", tc.code, parser.ParseComments)
+			require.NoError(t, err)
+
+			issues, err := DetectDuplicateImports("test.gno", node, fset, tt.SeverityWarning)
+			require.NoError(t, err)
+			require.Len(t, issues, len(tc.messages))
+
+			for i, issue := range issues {
+				assert.Equal(t, "duplicate-import", issue.Rule)
+				assert.Equal(t, tc.messages[i], issue.Message)
+				assert.Equal(t, tc.lines[i], issue.Start.Line)
+				assert.Equal(t, tc.suggestions[i], issue.Suggestion)
+				assert.Equal(t, tc.fixable[i], issue.Confidence > 0)
+			}
+		})
+	}
+}
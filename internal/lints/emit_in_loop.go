@@ -0,0 +1,216 @@
+package lints
+
+import (
+	"fmt"
+	"go/ast"
+	"go/constant"
+	"go/token"
+	"go/types"
+
+	tt "github.com/gnolang/tlin/internal/types"
+)
+
+// enclosingLoop is a loop around an Emit call, with its label if any.
+type enclosingLoop struct {
+	stmt  ast.Stmt
+	label string
+}
+
+// DetectEmitInLoop reports std.Emit calls inside loop bodies, which emit one
+// event per iteration. Calls are fine when the enclosing loops of the
+// function have constant bounds whose product is at most maxIterations, or
+// when the call is directly followed by a return, or by a break leaving the
+// only unbounded loops, so that it fires once.
+func DetectEmitInLoop(filename string, node *ast.File, fset *token.FileSet, info *types.Info, severity tt.Severity, maxIterations int) ([]tt.Issue, error) {
+	var issues []tt.Issue
+	var stack []ast.Node
+	ast.Inspect(node, func(n ast.Node) bool {
+		if n == nil {
+			stack = stack[:len(stack)-1]
+			return true
+		}
+		stack = append(stack, n)
+
+		call, ok := n.(*ast.CallExpr)
+		if !ok || callName(call) != "std.Emit" {
+			return true
+		}
+		all := enclosingLoops(stack)
+		if len(all) == 0 {
+			return true
+		}
+
+		loops := all
+		if left, ok := exitAfterEmit(stack, loops); ok {
+			loops = left
+		}
+		if boundedLoops(loops, info, maxIterations) {
+			return true
+		}
+
+		issues = append(issues, tt.Issue{
+			Rule:     "emit-in-loop",
+			Filename: filename,
+			Start:    fset.Position(call.Pos()),
+			End:      fset.Position(call.End()),
+			Message:  "std.Emit is called inside a loop, emitting one event per iteration",
+			Note:     fmt.Sprintf("aggregate the data into a single event, or bound the loop to at most %d iterations", maxIterations),
+			Severity: severity,
+			Related:  []tt.Location{{Position: fset.Position(all[0].stmt.Pos()), Label: "enclosing loop"}},
+		})
+		return true
+	})
+	return issues, nil
+}
+
+// enclosingLoops returns the loops of the stack within the innermost
+// function, innermost first.
+func enclosingLoops(stack []ast.Node) []enclosingLoop {
+	var loops []enclosingLoop
+	for i := len(stack) - 1; i >= 0; i-- {
+		switch stmt := stack[i].(type) {
+		case *ast.FuncLit, *ast.FuncDecl:
+			return loops
+		case *ast.ForStmt, *ast.RangeStmt:
+			loop := enclosingLoop{stmt: stmt.(ast.Stmt)}
+			if labeled, ok := stack[i-1].(*ast.LabeledStmt); ok {
+				loop.label = labeled.Label.Name
+			}
+			loops = append(loops, loop)
+		}
+	}
+	return loops
+}
+
+// exitAfterEmit checks whether the statement following the Emit call, the top
+// of the stack, leaves loops. It returns the loops still enclosing the call
+// after that exit.
+func exitAfterEmit(stack []ast.Node, loops []enclosingLoop) ([]enclosingLoop, bool) {
+	if len(stack) < 3 {
+		return nil, false
+	}
+	stmt, ok := stack[len(stack)-2].(*ast.ExprStmt)
+	if !ok {
+		return nil, false
+	}
+	var list []ast.Stmt
+	switch parent := stack[len(stack)-3].(type) {
+	case *ast.BlockStmt:
+		list = parent.List
+	case *ast.CaseClause:
+		list = parent.Body
+	case *ast.CommClause:
+		list = parent.Body
+	}
+	var next ast.Stmt
+	for i, s := range list {
+		if s == stmt && i+1 < len(list) {
+			next = list[i+1]
+		}
+	}
+
+	switch next := next.(type) {
+	case *ast.ReturnStmt:
+		return nil, true
+	case *ast.BranchStmt:
+		if next.Tok != token.BREAK {
+			return nil, false
+		}
+		if next.Label != nil {
+			for i, loop := range loops {
+				if loop.label == next.Label.Name {
+					return loops[i+1:], true
+				}
+			}
+			return nil, false
+		}
+		// an unlabeled break leaves the innermost switch, select or loop
+		for i := len(stack) - 1; i >= 0; i-- {
+			switch stack[i].(type) {
+			case *ast.SwitchStmt, *ast.TypeSwitchStmt, *ast.SelectStmt:
+				return nil, false
+			case *ast.ForStmt, *ast.RangeStmt:
+				return loops[1:], true
+			}
+		}
+	}
+	return nil, false
+}
+
+// boundedLoops reports whether all loops have a constant bound, and run at
+// most maxIterations times altogether.
+func boundedLoops(loops []enclosingLoop, info *types.Info, maxIterations int) bool {
+	total := int64(1)
+	for _, loop := range loops {
+		bound, ok := constantLoopBound(loop.stmt, info)
+		if !ok {
+			return false
+		}
+		total *= bound
+		if total > int64(maxIterations) {
+			return false
+		}
+	}
+	return true
+}
+
+// constantLoopBound returns the number of iterations of a loop: ranges over
+// arrays, literals and integer constants, and counters compared with a
+// constant.
+func constantLoopBound(stmt ast.Stmt, info *types.Info) (int64, bool) {
+	switch loop := stmt.(type) {
+	case *ast.RangeStmt:
+		if lit, ok := ast.Unparen(loop.X).(*ast.CompositeLit); ok {
+			return int64(len(lit.Elts)), true
+		}
+		tv, ok := info.Types[loop.X]
+		if !ok {
+			return 0, false
+		}
+		if tv.Value != nil {
+			return constantInt(tv.Value)
+		}
+		if tv.Type == nil {
+			return 0, false
+		}
+		typ := tv.Type.Underlying()
+		if ptr, ok := typ.(*types.Pointer); ok {
+			typ = ptr.Elem().Underlying()
+		}
+		if array, ok := typ.(*types.Array); ok {
+			return array.Len(), true
+		}
+	case *ast.ForStmt:
+		cond, ok := loop.Cond.(*ast.BinaryExpr)
+		if !ok {
+			return 0, false
+		}
+		var limit ast.Expr
+		inclusive := false
+		switch cond.Op {
+		case token.LSS, token.LEQ:
+			limit, inclusive = cond.Y, cond.Op == token.LEQ
+		case token.GTR, token.GEQ:
+			limit, inclusive = cond.X, cond.Op == token.GEQ
+		default:
+			return 0, false
+		}
+		tv, ok := info.Types[limit]
+		if !ok || tv.Value == nil {
+			return 0, false
+		}
+		bound, ok := constantInt(tv.Value)
+		if inclusive {
+			bound++
+		}
+		return bound, ok
+	}
+	return 0, false
+}
+
+func constantInt(value constant.Value) (int64, bool) {
+	if value.Kind() != constant.Int {
+		return 0, false
+	}
+	return constant.Int64Val(value)
+}
@@ -0,0 +1,187 @@
+package lints
+
+import (
+	"go/parser"
+	"go/token"
+	"testing"
+
+	tt "github.com/gnolang/tlin/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetectEmitInLoop(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name  string
+		code  string
+		emits []int // lines of the reported calls
+		loops []int // lines of the related loops
+	}{
+		{
+			name: "emits per element",
+			code: `package foo
+
+import "std"
+
+func notify(users []string) {
+	for _, u := range users {
+		std.Emit("Notified", "user", u)
+	}
+	for i := 0; i < len(users); i++ {
+		if users[i] != "" {
+			std.Emit("Checked", "user", users[i])
+		}
+	}
+}
+`,
+			emits: []int{7, 11},
+			loops: []int{6, 9},
+		},
+		{
+			name: "small constant bounds",
+			code: `package foo
+
+import "std"
+
+const rounds = 3
+
+var slots [4]int
+
+func f() {
+	for i := 0; i < rounds; i++ {
+		std.Emit("Round")
+	}
+	for i := range slots {
+		for j := 0; j <= 1; j++ {
+			std.Emit("Slot", "i", i, "j", j)
+		}
+	}
+	for _, kind := range []string{"a", "b"} {
+		std.Emit("Kind", "kind", kind)
+	}
+	for range 5 {
+		std.Emit("Tick")
+	}
+}
+`,
+		},
+		{
+			name: "bounds above the limit",
+			code: `package foo
+
+import "std"
+
+func f() {
+	for i := 0; i < 100; i++ {
+		std.Emit("Tick")
+	}
+	for i := 0; i < 4; i++ {
+		for j := 0; j < 4; j++ {
+			std.Emit("Cell")
+		}
+	}
+}
+`,
+			emits: []int{7, 11},
+			loops: []int{6, 10},
+		},
+		{
+			name: "emitting once",
+			code: `package foo
+
+import "std"
+
+func find(users []string, target string) {
+	for _, u := range users {
+		if u == target {
+			std.Emit("Found", "user", u)
+			break
+		}
+	}
+	for _, u := range users {
+		if u == target {
+			std.Emit("Found", "user", u)
+			return
+		}
+	}
+outer:
+	for _, group := range [][]string{} {
+		for _, u := range group {
+			if u == target {
+				std.Emit("Found", "user", u)
+				break outer
+			}
+		}
+	}
+}
+`,
+		},
+		{
+			name: "break leaving an inner construct",
+			code: `package foo
+
+import "std"
+
+func f(groups [][]string, kind int) {
+	for _, group := range groups {
+		for _, u := range group {
+			std.Emit("Member", "user", u)
+			break
+		}
+	}
+	for _, group := range groups {
+		switch kind {
+		case 1:
+			std.Emit("Kind", "group", group[0])
+			break
+		}
+	}
+}
+`,
+			emits: []int{8, 15},
+			loops: []int{7, 12},
+		},
+		{
+			name: "function literals and emits outside loops",
+			code: `package foo
+
+import "std"
+
+func f(users []string) {
+	std.Emit("Start")
+	for _, u := range users {
+		callback := func() {
+			std.Emit("Deferred", "user", u)
+		}
+		_ = callback
+	}
+}
+`,
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			fset := token.NewFileSet()
+			node, err := parser.ParseFile(fset, "test.gno", tc.code, 0)
+			require.NoError(t, err)
+
+			pkg := NewPackage(fset, &PackageFile{Filename: "test.gno", File: node})
+			issues, err := DetectEmitInLoop("test.gno", node, fset, pkg.TypesInfo(), tt.SeverityWarning, 10)
+			require.NoError(t, err)
+
+			var emits, loops []int
+			for _, issue := range issues {
+				assert.Equal(t, "emit-in-loop", issue.Rule)
+				require.Len(t, issue.Related, 1)
+				emits = append(emits, issue.Start.Line)
+				loops = append(loops, issue.Related[0].Position.Line)
+			}
+			assert.Equal(t, tc.emits, emits)
+			assert.Equal(t, tc.loops, loops)
+		})
+	}
+}
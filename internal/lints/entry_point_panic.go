@@ -0,0 +1,283 @@
+package lints
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+
+	tt "github.com/gnolang/tlin/internal/types"
+)
+
+const entryPointPanicConfidence = 0.6
+
+// DetectEntryPointPanics reports, in the exported functions of realm
+// packages, expressions that panic on user input with a runtime error
+// instead of a meaningful message:
+//
+//   - slice, array and string indexes derived from a parameter, with no
+//     comparison of the index in an enclosing condition or in an earlier if
+//     leaving the function;
+//   - map reads whose result is dereferenced right away, through a pointer,
+//     a field or a method call, which panic when the key is missing.
+//
+// The guard analysis is a heuristic: any comparison involving the index
+// counts as a bounds check.
+func DetectEntryPointPanics(pkg *Package, severity tt.Severity) ([]tt.Issue, error) {
+	if !isRealmPackage(pkg) {
+		return nil, nil
+	}
+
+	files, info, _ := typeCheck(pkg)
+
+	var issues []tt.Issue
+	for _, file := range files {
+		for _, decl := range file.File.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok || fn.Body == nil || fn.Recv != nil || !fn.Name.IsExported() {
+				continue
+			}
+			for _, p := range entryPointPanics(fn, info) {
+				p.Rule = "entry-point-panic"
+				p.Filename = file.Filename
+				p.Start = pkg.Fset.Position(p.expr.Pos())
+				p.End = pkg.Fset.Position(p.expr.End())
+				p.Confidence = entryPointPanicConfidence
+				p.Severity = severity
+				issues = append(issues, p.Issue)
+			}
+		}
+	}
+	return issues, nil
+}
+
+type entryPointPanic struct {
+	tt.Issue
+	expr ast.Expr
+}
+
+func entryPointPanics(fn *ast.FuncDecl, info *types.Info) []entryPointPanic {
+	// variables holding a value derived from a parameter
+	tainted := make(map[types.Object]bool)
+	for _, field := range fn.Type.Params.List {
+		for _, name := range field.Names {
+			if obj := info.Defs[name]; obj != nil {
+				tainted[obj] = true
+			}
+		}
+	}
+
+	var panics []entryPointPanic
+	var stack []ast.Node
+	ast.Inspect(fn.Body, func(n ast.Node) bool {
+		if n == nil {
+			stack = stack[:len(stack)-1]
+			return true
+		}
+		stack = append(stack, n)
+
+		switch node := n.(type) {
+		case *ast.FuncLit:
+			stack = stack[:len(stack)-1]
+			return false
+		case *ast.AssignStmt:
+			trackTaint(node, info, tainted)
+		case *ast.IndexExpr:
+			if p, ok := uncheckedIndex(node, fn.Body, stack, info, tainted); ok {
+				panics = append(panics, p)
+			}
+		case *ast.StarExpr:
+			if p, ok := nilMapRead(node.X, fn.Body, info); ok {
+				panics = append(panics, p)
+			}
+		case *ast.SelectorExpr:
+			if p, ok := nilMapRead(node.X, fn.Body, info); ok {
+				panics = append(panics, p)
+			}
+		}
+		return true
+	})
+	return panics
+}
+
+// trackTaint marks the variables assigned from an expression using a
+// tainted variable.
+func trackTaint(assign *ast.AssignStmt, info *types.Info, tainted map[types.Object]bool) {
+	if len(assign.Lhs) != len(assign.Rhs) {
+		return
+	}
+	for i, lhs := range assign.Lhs {
+		ident, ok := lhs.(*ast.Ident)
+		if !ok {
+			continue
+		}
+		obj := info.Defs[ident]
+		if obj == nil {
+			obj = info.Uses[ident]
+		}
+		if obj != nil {
+			tainted[obj] = taintedBy(assign.Rhs[i], info, tainted) != nil
+		}
+	}
+}
+
+// taintedBy returns the first tainted variable used by expr.
+func taintedBy(expr ast.Expr, info *types.Info, tainted map[types.Object]bool) *ast.Ident {
+	var found *ast.Ident
+	ast.Inspect(expr, func(n ast.Node) bool {
+		if found != nil {
+			return false
+		}
+		if ident, ok := n.(*ast.Ident); ok && tainted[info.Uses[ident]] {
+			found = ident
+		}
+		return true
+	})
+	return found
+}
+
+func uncheckedIndex(index *ast.IndexExpr, body *ast.BlockStmt, stack []ast.Node, info *types.Info, tainted map[types.Object]bool) (entryPointPanic, bool) {
+	tv, ok := info.Types[index.X]
+	if !ok || tv.Type == nil || !isIndexable(tv.Type) {
+		return entryPointPanic{}, false
+	}
+	if idx, ok := info.Types[index.Index]; ok && idx.Value != nil {
+		// constant indexes are checked by the compiler or deliberate
+		return entryPointPanic{}, false
+	}
+	source := taintedBy(index.Index, info, tainted)
+	if source == nil || boundsChecked(index, body, stack, info) {
+		return entryPointPanic{}, false
+	}
+
+	indexText, seq := types.ExprString(index.Index), types.ExprString(index.X)
+	return entryPointPanic{
+		Issue: tt.Issue{
+			Message: fmt.Sprintf("index %s derives from the input %s and is not checked against len(%s), an out of range value aborts the transaction with a runtime panic",
+				indexText, source.Name, seq),
+			Suggestion: fmt.Sprintf("if %s < 0 || %s >= len(%s) {\n\tpanic(\"%s out of range\")\n}", indexText, indexText, seq, indexText),
+		},
+		expr: index,
+	}, true
+}
+
+func isIndexable(typ types.Type) bool {
+	switch t := typ.Underlying().(type) {
+	case *types.Slice, *types.Array:
+		return true
+	case *types.Pointer:
+		_, ok := t.Elem().Underlying().(*types.Array)
+		return ok
+	case *types.Basic:
+		return t.Info()&types.IsString != 0
+	}
+	return false
+}
+
+// boundsChecked reports whether a variable of the index is compared in the
+// condition of an enclosing statement, or of an earlier if statement of the
+// function leaving it.
+func boundsChecked(index *ast.IndexExpr, body *ast.BlockStmt, stack []ast.Node, info *types.Info) bool {
+	vars := make(map[types.Object]bool)
+	ast.Inspect(index.Index, func(n ast.Node) bool {
+		if ident, ok := n.(*ast.Ident); ok && info.Uses[ident] != nil {
+			vars[info.Uses[ident]] = true
+		}
+		return true
+	})
+	compares := func(cond ast.Expr) bool {
+		found := false
+		ast.Inspect(cond, func(n ast.Node) bool {
+			bin, ok := n.(*ast.BinaryExpr)
+			if found || !ok {
+				return !found
+			}
+			switch bin.Op {
+			case token.LSS, token.LEQ, token.GTR, token.GEQ:
+				for _, side := range []ast.Expr{bin.X, bin.Y} {
+					if ident, ok := ast.Unparen(side).(*ast.Ident); ok && vars[info.Uses[ident]] {
+						found = true
+					}
+				}
+			}
+			return !found
+		})
+		return found
+	}
+
+	for _, n := range stack {
+		switch stmt := n.(type) {
+		case *ast.IfStmt:
+			if compares(stmt.Cond) {
+				return true
+			}
+		case *ast.ForStmt:
+			if stmt.Cond != nil && compares(stmt.Cond) {
+				return true
+			}
+		}
+	}
+
+	checked := false
+	ast.Inspect(body, func(n ast.Node) bool {
+		if checked || n == nil || n.Pos() >= index.Pos() {
+			return false
+		}
+		if _, ok := n.(*ast.FuncLit); ok {
+			return false
+		}
+		if ifStmt, ok := n.(*ast.IfStmt); ok && ifStmt.End() < index.Pos() && exits(ifStmt.Body) && compares(ifStmt.Cond) {
+			checked = true
+		}
+		return true
+	})
+	return checked
+}
+
+// nilMapRead returns the map read expr when it yields a pointer or an
+// interface that the caller then dereferences, and that no earlier comma-ok
+// lookup of the same key guards.
+func nilMapRead(expr ast.Expr, body *ast.BlockStmt, info *types.Info) (entryPointPanic, bool) {
+	index, ok := ast.Unparen(expr).(*ast.IndexExpr)
+	if !ok {
+		return entryPointPanic{}, false
+	}
+	tv, ok := info.Types[index.X]
+	if !ok || tv.Type == nil {
+		return entryPointPanic{}, false
+	}
+	m, ok := tv.Type.Underlying().(*types.Map)
+	if !ok {
+		return entryPointPanic{}, false
+	}
+	switch m.Elem().Underlying().(type) {
+	case *types.Pointer, *types.Interface:
+	default:
+		return entryPointPanic{}, false
+	}
+
+	text := types.ExprString(index)
+	guarded := false
+	ast.Inspect(body, func(n ast.Node) bool {
+		assign, ok := n.(*ast.AssignStmt)
+		if guarded || !ok || assign.Pos() >= index.Pos() {
+			return !guarded
+		}
+		if len(assign.Lhs) == 2 && len(assign.Rhs) == 1 && types.ExprString(assign.Rhs[0]) == text {
+			guarded = true
+		}
+		return !guarded
+	})
+	if guarded {
+		return entryPointPanic{}, false
+	}
+
+	return entryPointPanic{
+		Issue: tt.Issue{
+			Message: fmt.Sprintf("%s is used right away, a missing key yields nil and aborts the transaction with a runtime panic", text),
+			Suggestion: fmt.Sprintf("v, ok := %s\nif !ok {\n\tpanic(\"%s not found\")\n}",
+				text, types.ExprString(index.Index)),
+		},
+		expr: index,
+	}, true
+}
@@ -0,0 +1,132 @@
+package lints
+
+import (
+	"testing"
+
+	tt "github.com/gnolang/tlin/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetectEntryPointPanics(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name  string
+		files map[string]string
+		lines []int
+	}{
+		{
+			name: "unchecked indexes and map reads",
+			files: map[string]string{
+				"gno.land/r/demo/board/board.gno": `package board
+
+type Post struct{ Title string }
+
+var (
+	posts []string
+	byID  = map[string]*Post{}
+)
+
+func GetPost(i int) string {
+	return posts[i]
+}
+
+func Nth(n int) string {
+	j := n - 1
+	return posts[j]
+}
+
+func Title(id string) string {
+	return byID[id].Title
+}
+
+func Deref(id string) Post {
+	return *byID[id]
+}
+`,
+			},
+			lines: []int{11, 16, 20, 24},
+		},
+		{
+			name: "guarded accesses",
+			files: map[string]string{
+				"gno.land/r/demo/board/board.gno": `package board
+
+type Post struct{ Title string }
+
+var (
+	posts  []string
+	byID   = map[string]*Post{}
+	counts = map[string]int{}
+)
+
+func GetPost(i int) string {
+	if i < 0 || i >= len(posts) {
+		panic("invalid post")
+	}
+	return posts[i]
+}
+
+func Inside(i int) string {
+	if i < len(posts) {
+		return posts[i]
+	}
+	return ""
+}
+
+func First() string {
+	for i := range posts {
+		return posts[i]
+	}
+	return posts[0]
+}
+
+func Title(id string) string {
+	if _, ok := byID[id]; !ok {
+		panic("unknown post")
+	}
+	return byID[id].Title
+}
+
+func Count(id string) int {
+	return counts[id] + 1
+}
+
+func helper(i int) string {
+	return posts[i]
+}
+`,
+			},
+		},
+		{
+			name: "not a realm",
+			files: map[string]string{
+				"gno.land/p/demo/board/board.gno": `package board
+
+var posts []string
+
+func GetPost(i int) string {
+	return posts[i]
+}
+`,
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			issues, err := DetectEntryPointPanics(parsePackage(t, tc.files), tt.SeverityWarning)
+			require.NoError(t, err)
+
+			var lines []int
+			for _, issue := range issues {
+				assert.Equal(t, "entry-point-panic", issue.Rule)
+				assert.NotEmpty(t, issue.Suggestion)
+				lines = append(lines, issue.Start.Line)
+			}
+			assert.Equal(t, tc.lines, lines)
+		})
+	}
+}
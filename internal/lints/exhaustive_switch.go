@@ -0,0 +1,336 @@
+package lints
+
+import (
+	"fmt"
+	"go/ast"
+	"go/constant"
+	"go/token"
+	"go/types"
+	"sort"
+	"strings"
+
+	tt "github.com/gnolang/tlin/internal/types"
+)
+
+// DetectNonExhaustiveSwitches reports the switches of a package that miss
+// some variants of an enum-like set declared in the same package:
+//
+//   - switches, and chains of if and else if statements comparing a value
+//     for equality, over a named type with several package-level constants;
+//   - type switches over a sealed interface, one with an unexported method,
+//     that miss some of the types of the package implementing it.
+//
+// A default case, or the final else of a chain, handles the new variants
+// and silences the finding, unless strict is set.
+func DetectNonExhaustiveSwitches(pkg *Package, severity tt.Severity, strict bool) ([]tt.Issue, error) {
+	files, info, checked := typeCheck(pkg)
+	if checked == nil {
+		return nil, nil
+	}
+	enums := enumConstants(checked)
+	sealed := sealedImplementations(checked)
+	if len(enums) == 0 && len(sealed) == 0 {
+		return nil, nil
+	}
+
+	var issues []tt.Issue
+	report := func(file *PackageFile, start, end token.Pos, kind string, named *types.Named, missing []string) {
+		issues = append(issues, tt.Issue{
+			Rule:     "non-exhaustive-switch",
+			Filename: file.Filename,
+			Start:    pkg.Fset.Position(start),
+			End:      pkg.Fset.Position(end),
+			Message: fmt.Sprintf("%s on %s misses %s",
+				kind, named.Obj().Name(), strings.Join(missing, ", ")),
+			Note:     "handle the missing variants, so that adding a variant shows every place to update",
+			Severity: severity,
+			Related: []tt.Location{
+				{Position: pkg.Fset.Position(named.Obj().Pos()), Label: named.Obj().Name() + " declared here"},
+			},
+		})
+	}
+
+	for _, file := range files {
+		elseIfs := make(map[*ast.IfStmt]bool)
+		ast.Inspect(file.File, func(n ast.Node) bool {
+			switch stmt := n.(type) {
+			case *ast.SwitchStmt:
+				if stmt.Tag == nil {
+					return true
+				}
+				named, consts := enumOf(info.TypeOf(stmt.Tag), enums)
+				if named == nil {
+					return true
+				}
+				var values []ast.Expr
+				hasDefault := false
+				for _, clause := range stmt.Body.List {
+					cc := clause.(*ast.CaseClause)
+					if cc.List == nil {
+						hasDefault = true
+					}
+					values = append(values, cc.List...)
+				}
+				if hasDefault && !strict {
+					return true
+				}
+				if missing, ok := missingConstants(values, consts, info); ok && len(missing) > 0 {
+					report(file, stmt.Pos(), stmt.Body.Lbrace, "switch", named, missing)
+				}
+			case *ast.TypeSwitchStmt:
+				x := typeSwitchOperand(stmt)
+				if x == nil {
+					return true
+				}
+				named, ok := info.TypeOf(x).(*types.Named)
+				if !ok || sealed[named] == nil {
+					return true
+				}
+				var cases []types.Type
+				hasDefault := false
+				for _, clause := range stmt.Body.List {
+					cc := clause.(*ast.CaseClause)
+					if cc.List == nil {
+						hasDefault = true
+					}
+					for _, expr := range cc.List {
+						if typ := info.TypeOf(expr); typ != nil {
+							cases = append(cases, typ)
+						}
+					}
+				}
+				if hasDefault && !strict {
+					return true
+				}
+				if missing := missingImplementations(cases, named.Underlying().(*types.Interface), sealed[named]); len(missing) > 0 {
+					report(file, stmt.Pos(), stmt.Body.Lbrace, "type switch", named, missing)
+				}
+			case *ast.IfStmt:
+				if _, chained := stmt.Else.(*ast.IfStmt); elseIfs[stmt] || !chained {
+					// a single if, such as a guard, doesn't mean to handle
+					// every variant
+					return true
+				}
+				x, values, hasElse := comparisonChain(stmt, elseIfs)
+				if x == nil || len(values) < 2 || (hasElse && !strict) {
+					return true
+				}
+				named, consts := enumOf(info.TypeOf(x), enums)
+				if named == nil {
+					return true
+				}
+				if missing, ok := missingConstants(values, consts, info); ok && len(missing) > 0 {
+					report(file, stmt.Pos(), stmt.Body.Lbrace, "if chain", named, missing)
+				}
+			}
+			return true
+		})
+	}
+	return issues, nil
+}
+
+// enumConstants maps the named types of pkg with a basic underlying type to
+// their package-level constants, in declaration order, when there are
+// several of them.
+func enumConstants(pkg *types.Package) map[*types.Named][]*types.Const {
+	consts := make(map[*types.Named][]*types.Const)
+	scope := pkg.Scope()
+	for _, name := range scope.Names() {
+		c, ok := scope.Lookup(name).(*types.Const)
+		if !ok || name == "_" {
+			continue
+		}
+		named, ok := c.Type().(*types.Named)
+		if !ok || named.Obj().Pkg() != pkg {
+			continue
+		}
+		if _, ok := named.Underlying().(*types.Basic); ok {
+			consts[named] = append(consts[named], c)
+		}
+	}
+	for named, list := range consts {
+		if len(list) < 2 {
+			delete(consts, named)
+			continue
+		}
+		sort.Slice(list, func(i, j int) bool { return list[i].Pos() < list[j].Pos() })
+	}
+	return consts
+}
+
+func enumOf(typ types.Type, enums map[*types.Named][]*types.Const) (*types.Named, []*types.Const) {
+	named, ok := typ.(*types.Named)
+	if !ok || enums[named] == nil {
+		return nil, nil
+	}
+	return named, enums[named]
+}
+
+// missingConstants returns the constants whose value none of the values
+// matches. It fails when a value is not constant.
+func missingConstants(values []ast.Expr, consts []*types.Const, info *types.Info) ([]string, bool) {
+	var covered []constant.Value
+	for _, expr := range values {
+		tv, ok := info.Types[expr]
+		if !ok || tv.Value == nil {
+			return nil, false
+		}
+		covered = append(covered, tv.Value)
+	}
+
+	var missing []string
+	for _, c := range consts {
+		found := false
+		for _, value := range covered {
+			if constant.Compare(c.Val(), token.EQL, value) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			missing = append(missing, c.Name())
+		}
+	}
+	return missing, true
+}
+
+// sealedImplementations maps the interfaces of pkg having an unexported
+// method, which no other package can implement, to the types of pkg
+// implementing them, when there are several of them.
+func sealedImplementations(pkg *types.Package) map[*types.Named][]*types.Named {
+	scope := pkg.Scope()
+	var interfaces, concrete []*types.Named
+	for _, name := range scope.Names() {
+		obj, ok := scope.Lookup(name).(*types.TypeName)
+		if !ok || obj.IsAlias() {
+			continue
+		}
+		named, ok := obj.Type().(*types.Named)
+		if !ok || named.TypeParams() != nil {
+			continue
+		}
+		if iface, ok := named.Underlying().(*types.Interface); ok {
+			if isSealed(iface) {
+				interfaces = append(interfaces, named)
+			}
+			continue
+		}
+		concrete = append(concrete, named)
+	}
+
+	impls := make(map[*types.Named][]*types.Named)
+	for _, iface := range interfaces {
+		underlying := iface.Underlying().(*types.Interface)
+		var list []*types.Named
+		for _, typ := range concrete {
+			if types.Implements(typ, underlying) || types.Implements(types.NewPointer(typ), underlying) {
+				list = append(list, typ)
+			}
+		}
+		if len(list) >= 2 {
+			sort.Slice(list, func(i, j int) bool { return list[i].Obj().Pos() < list[j].Obj().Pos() })
+			impls[iface] = list
+		}
+	}
+	return impls
+}
+
+func isSealed(iface *types.Interface) bool {
+	for i := 0; i < iface.NumMethods(); i++ {
+		if !iface.Method(i).Exported() {
+			return true
+		}
+	}
+	return false
+}
+
+// missingImplementations returns the implementations that no case type
+// matches, either as a value or a pointer, or through an interface.
+func missingImplementations(cases []types.Type, iface *types.Interface, impls []*types.Named) []string {
+	var missing []string
+	for _, impl := range impls {
+		ptr := types.NewPointer(impl)
+		found := false
+		for _, typ := range cases {
+			if types.Identical(typ, impl) || types.Identical(typ, ptr) {
+				found = true
+			} else if iface, ok := typ.Underlying().(*types.Interface); ok {
+				found = types.Implements(impl, iface) || types.Implements(ptr, iface)
+			}
+			if found {
+				break
+			}
+		}
+		if found {
+			continue
+		}
+		// name it the way a case would, as a pointer when only the pointer
+		// has the methods
+		if types.Implements(impl, iface) {
+			missing = append(missing, impl.Obj().Name())
+		} else {
+			missing = append(missing, "*"+impl.Obj().Name())
+		}
+	}
+	return missing
+}
+
+// typeSwitchOperand returns the expression a type switch asserts.
+func typeSwitchOperand(stmt *ast.TypeSwitchStmt) ast.Expr {
+	var assert ast.Expr
+	switch s := stmt.Assign.(type) {
+	case *ast.ExprStmt:
+		assert = s.X
+	case *ast.AssignStmt:
+		if len(s.Rhs) == 1 {
+			assert = s.Rhs[0]
+		}
+	}
+	if ta, ok := assert.(*ast.TypeAssertExpr); ok {
+		return ta.X
+	}
+	return nil
+}
+
+// comparisonChain returns the operand an if-else chain compares for
+// equality in every condition, along with the values it is compared to and
+// whether the chain ends with a plain else. The else-if statements of the
+// chain are recorded in elseIfs. It returns a nil operand for chains with
+// other conditions.
+func comparisonChain(stmt *ast.IfStmt, elseIfs map[*ast.IfStmt]bool) (ast.Expr, []ast.Expr, bool) {
+	for next, ok := stmt.Else.(*ast.IfStmt); ok; next, ok = next.Else.(*ast.IfStmt) {
+		elseIfs[next] = true
+	}
+
+	var x ast.Expr
+	var values []ast.Expr
+	for {
+		for _, cond := range disjuncts(stmt.Cond) {
+			bin, ok := ast.Unparen(cond).(*ast.BinaryExpr)
+			if !ok || bin.Op != token.EQL {
+				return nil, nil, false
+			}
+			if x == nil {
+				x = bin.X
+			} else if types.ExprString(x) != types.ExprString(bin.X) {
+				return nil, nil, false
+			}
+			values = append(values, bin.Y)
+		}
+		switch next := stmt.Else.(type) {
+		case *ast.IfStmt:
+			stmt = next
+		case nil:
+			return x, values, false
+		default:
+			return x, values, true
+		}
+	}
+}
+
+func disjuncts(expr ast.Expr) []ast.Expr {
+	if bin, ok := ast.Unparen(expr).(*ast.BinaryExpr); ok && bin.Op == token.LOR {
+		return append(disjuncts(bin.X), disjuncts(bin.Y)...)
+	}
+	return []ast.Expr{expr}
+}
@@ -0,0 +1,82 @@
+package lints
+
+import (
+	"testing"
+
+	tt "github.com/gnolang/tlin/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// The cases without a default are covered by the fixtures of
+// testdata/non-exhaustive-switch.
+func TestDetectNonExhaustiveSwitchesStrict(t *testing.T) {
+	t.Parallel()
+	pkg := parsePackage(t, map[string]string{
+		"pkg/kind.gno": `package foo
+
+type Kind string
+
+const (
+	KindA Kind = "a"
+	KindB Kind = "b"
+)
+
+type Node interface{ node() }
+
+type Leaf struct{}
+
+func (Leaf) node() {}
+
+type Branch struct{}
+
+func (Branch) node() {}
+
+func f(k Kind, n Node) int {
+	switch k {
+	case KindA:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func g(n Node) int {
+	switch n.(type) {
+	case Leaf:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func h(k Kind) int {
+	if k == KindA {
+		return 1
+	} else if k == KindA {
+		return 2
+	} else {
+		return 0
+	}
+}
+`,
+	})
+
+	issues, err := DetectNonExhaustiveSwitches(pkg, tt.SeverityWarning, false)
+	require.NoError(t, err)
+	assert.Empty(t, issues)
+
+	issues, err = DetectNonExhaustiveSwitches(pkg, tt.SeverityWarning, true)
+	require.NoError(t, err)
+	var messages []string
+	for _, issue := range issues {
+		assert.Equal(t, "non-exhaustive-switch", issue.Rule)
+		require.Len(t, issue.Related, 1)
+		messages = append(messages, issue.Message)
+	}
+	assert.Equal(t, []string{
+		"switch on Kind misses KindB",
+		"type switch on Node misses Branch",
+		"if chain on Kind misses KindB",
+	}, messages)
+}
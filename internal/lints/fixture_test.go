@@ -0,0 +1,51 @@
+package lints_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/gnolang/tlin/lint"
+	"github.com/gnolang/tlin/lint/linttest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFixtures(t *testing.T) {
+	t.Parallel()
+	for _, rule := range []string{
+		"const-error-declaration",
+		"float-comparison",
+		"non-exhaustive-switch",
+		"useless-break",
+	} {
+		rule := rule
+		t.Run(rule, func(t *testing.T) {
+			t.Parallel()
+			linttest.RunRules(t, filepath.Join("testdata", rule), rule)
+		})
+	}
+}
+
+func TestCallProtocolFixtures(t *testing.T) {
+	t.Parallel()
+	for _, dir := range []string{"compliant", "noncompliant"} {
+		dir := filepath.Join("testdata", "call-protocol", dir)
+		t.Run(dir, func(t *testing.T) {
+			t.Parallel()
+			engine, err := lint.New(dir, nil, filepath.Join("testdata", "call-protocol", "tlin.yaml"))
+			require.NoError(t, err)
+			require.NoError(t, engine.EnableOnly("call-protocol"))
+			linttest.Run(t, engine, dir)
+		})
+	}
+}
+
+func TestAmountOverflowFixtures(t *testing.T) {
+	t.Parallel()
+	for _, dir := range []string{"guarded", "vulnerable"} {
+		dir := filepath.Join("testdata", "amount-overflow", dir)
+		t.Run(dir, func(t *testing.T) {
+			t.Parallel()
+			linttest.RunRules(t, dir, "amount-overflow")
+		})
+	}
+}
@@ -0,0 +1,89 @@
+package lints
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"strings"
+
+	tt "github.com/gnolang/tlin/internal/types"
+)
+
+// DetectFloatComparison reports == and != comparisons, and switch cases, where
+// an operand has a floating point type.
+//
+// Comparisons against a literal 0 preceded by a comment mentioning "exact"
+// are allowed, as are the comparisons made inside functions whose name
+// mentions "zero" or "exact", such as isZero or equalExact.
+func DetectFloatComparison(filename string, node *ast.File, fset *token.FileSet, info *types.Info, severity tt.Severity) ([]tt.Issue, error) {
+	isFloat := func(expr ast.Expr) bool {
+		tv, ok := info.Types[expr]
+		if !ok || tv.Type == nil {
+			return false
+		}
+		basic, ok := tv.Type.Underlying().(*types.Basic)
+		return ok && basic.Info()&types.IsFloat != 0
+	}
+	exactLines := exactCommentLines(node, fset)
+
+	var issues []tt.Issue
+	report := func(n ast.Node, a, b ast.Expr, op token.Token) {
+		line := fset.Position(n.Pos()).Line
+		if (isZeroLiteral(a) || isZeroLiteral(b)) && (exactLines[line] || exactLines[line-1]) {
+			return
+		}
+		issues = append(issues, tt.Issue{
+			Rule:     "float-comparison",
+			Filename: filename,
+			Start:    fset.Position(n.Pos()),
+			End:      fset.Position(n.End()),
+			Message:  fmt.Sprintf("floating point values compared with %s: %s %s %s", op, types.ExprString(a), op, types.ExprString(b)),
+			Note:     "compare within a tolerance instead, e.g. math.Abs(a-b) <= epsilon",
+			Severity: severity,
+		})
+	}
+
+	for _, decl := range node.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if ok && isExactComparisonFunc(fn.Name.Name) {
+			continue
+		}
+
+		ast.Inspect(decl, func(n ast.Node) bool {
+			switch node := n.(type) {
+			case *ast.BinaryExpr:
+				if (node.Op == token.EQL || node.Op == token.NEQ) && (isFloat(node.X) || isFloat(node.Y)) {
+					report(node, node.X, node.Y, node.Op)
+				}
+			case *ast.SwitchStmt:
+				if node.Tag == nil || !isFloat(node.Tag) {
+					return true
+				}
+				for _, stmt := range node.Body.List {
+					for _, expr := range stmt.(*ast.CaseClause).List {
+						report(expr, node.Tag, expr, token.EQL)
+					}
+				}
+			}
+			return true
+		})
+	}
+	return issues, nil
+}
+
+func isExactComparisonFunc(name string) bool {
+	lower := strings.ToLower(name)
+	return strings.Contains(lower, "zero") || strings.Contains(lower, "exact")
+}
+
+// exactCommentLines returns the lines ending with a comment mentioning "exact".
+func exactCommentLines(node *ast.File, fset *token.FileSet) map[int]bool {
+	lines := make(map[int]bool)
+	for _, group := range node.Comments {
+		if strings.Contains(strings.ToLower(group.Text()), "exact") {
+			lines[fset.Position(group.End()).Line] = true
+		}
+	}
+	return lines
+}
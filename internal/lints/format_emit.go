@@ -1,13 +1,25 @@
 package lints
 
 import (
+	"bytes"
 	"go/ast"
+	"go/format"
+	"go/printer"
 	"go/token"
 	"strings"
 
 	tt "github.com/gnolang/tlin/internal/types"
 )
 
+// DetectEmitFormat reports std.Emit calls with more than one key/value pair
+// that are not laid out one pair per line, and suggests the canonical
+// layout: the event name and each pair on their own line, indented from the
+// call's column, with a trailing comma. Comments written next to an argument
+// stay on the line of its pair.
+//
+// Calls spreading a slice of arguments, calls sharing their lines with other
+// statements and calls with comments inside an argument are reported
+// without suggestion, and with a zero confidence so that they are not fixed.
 func DetectEmitFormat(filename string, node *ast.File, fset *token.FileSet, severity tt.Severity) ([]tt.Issue, error) {
 	imports := extractImports(node, func(path string) bool {
 		return path == "std"
@@ -17,37 +29,48 @@ func DetectEmitFormat(filename string, node *ast.File, fset *token.FileSet, seve
 		return nil, nil
 	}
 
+	stmts := emitStatements(node, fset)
 	issues := make([]tt.Issue, 0)
 	ast.Inspect(node, func(n ast.Node) bool {
 		call, ok := n.(*ast.CallExpr)
-		if !ok {
+		if !ok || !isEmitCall(call) {
+			return true
+		}
+		if len(call.Args) <= 3 || isEmitCorrectlyFormatted(call, fset) {
 			return true
 		}
 
-		if fun, ok := call.Fun.(*ast.SelectorExpr); ok {
-			if x, ok := fun.X.(*ast.Ident); ok && x.Name == "std" && fun.Sel.Name == "Emit" {
-				if len(call.Args) > 3 && !isEmitCorrectlyFormatted(call, fset) {
-					issue := tt.Issue{
-						Rule:       "emit-format",
-						Filename:   filename,
-						Start:      fset.Position(call.Pos()),
-						End:        fset.Position(call.End()),
-						Message:    "consider formatting std.Emit call for better readability",
-						Suggestion: formatEmitCall(call),
-						Confidence: 1.0,
-						Severity:   severity,
-					}
-					issues = append(issues, issue)
-				}
+		issue := tt.Issue{
+			Rule:     "emit-format",
+			Filename: filename,
+			Start:    fset.Position(call.Pos()),
+			End:      fset.Position(call.End()),
+			Message:  "consider formatting std.Emit call for better readability",
+			Severity: severity,
+		}
+		if stmt, ok := stmts[call]; ok && !call.Ellipsis.IsValid() {
+			indent := strings.Repeat("\t", fset.Position(stmt.Pos()).Column-1)
+			if suggestion, ok := formatEmitCall(call, fset, indent, node.Comments); ok {
+				issue.Suggestion = suggestion
+				issue.Confidence = 1.0
 			}
 		}
-
+		issues = append(issues, issue)
 		return true
 	})
 
 	return issues, nil
 }
 
+func isEmitCall(call *ast.CallExpr) bool {
+	fun, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok {
+		return false
+	}
+	x, ok := fun.X.(*ast.Ident)
+	return ok && x.Name == "std" && fun.Sel.Name == "Emit"
+}
+
 func isEmitCorrectlyFormatted(call *ast.CallExpr, fset *token.FileSet) bool {
 	if len(call.Args) < 2 {
 		return true
@@ -74,61 +97,167 @@ func isEmitCorrectlyFormatted(call *ast.CallExpr, fset *token.FileSet) bool {
 	return true
 }
 
-func formatEmitCall(call *ast.CallExpr) string {
-	var sb strings.Builder
-	sb.WriteString("std.Emit(\n")
-
-	// event type
-	if len(call.Args) > 0 {
-		sb.WriteString("    ")
-		sb.WriteString(formatArg(call.Args[0]))
-		sb.WriteString(",\n")
+// emitStatements maps the std.Emit calls that are statements alone on their
+// lines to their statement. The fixer replaces whole lines, so only those
+// can be rewritten.
+func emitStatements(node *ast.File, fset *token.FileSet) map[*ast.CallExpr]*ast.ExprStmt {
+	line := func(pos token.Pos) int { return fset.Position(pos).Line }
+	stmts := make(map[*ast.CallExpr]*ast.ExprStmt)
+	check := func(list []ast.Stmt, before, after int) {
+		for i, stmt := range list {
+			expr, ok := stmt.(*ast.ExprStmt)
+			if !ok {
+				continue
+			}
+			call, ok := expr.X.(*ast.CallExpr)
+			if !ok || !isEmitCall(call) {
+				continue
+			}
+			prev, next := before, after
+			if i > 0 {
+				prev = line(list[i-1].End())
+			}
+			if i+1 < len(list) {
+				next = line(list[i+1].Pos())
+			}
+			if prev < line(stmt.Pos()) && next > line(stmt.End()) {
+				stmts[call] = expr
+			}
+		}
 	}
-
-	// key-value pairs
-	for i := 1; i < len(call.Args); i += 2 {
-		sb.WriteString("    ")
-		sb.WriteString(formatArg(call.Args[i]))
-		sb.WriteString(", ")
-		if i+1 < len(call.Args) {
-			sb.WriteString(formatArg(call.Args[i+1]))
+	clauses := func(body *ast.BlockStmt) {
+		for i, clause := range body.List {
+			after := line(body.Rbrace)
+			if i+1 < len(body.List) {
+				after = line(body.List[i+1].Pos())
+			}
+			switch c := clause.(type) {
+			case *ast.CaseClause:
+				check(c.Body, line(c.Colon), after)
+			case *ast.CommClause:
+				check(c.Body, line(c.Colon), after)
+			}
 		}
-		sb.WriteString(",\n")
 	}
 
-	sb.WriteString(")")
-	return sb.String()
+	ast.Inspect(node, func(n ast.Node) bool {
+		switch stmt := n.(type) {
+		case *ast.BlockStmt:
+			check(stmt.List, line(stmt.Lbrace), line(stmt.Rbrace))
+		case *ast.SwitchStmt:
+			clauses(stmt.Body)
+		case *ast.TypeSwitchStmt:
+			clauses(stmt.Body)
+		case *ast.SelectStmt:
+			clauses(stmt.Body)
+		}
+		return true
+	})
+	return stmts
 }
 
-func formatArg(arg ast.Expr) string {
-	switch v := arg.(type) {
-	case *ast.BasicLit:
-		return v.Value
-	case *ast.Ident:
-		return v.Name
-	case *ast.CallExpr:
-		return formatCallExpr(v)
-	default:
-		return "..."
+// formatEmitCall lays out a std.Emit call with the event name and each
+// key/value pair on their own line, continuation lines being prefixed with
+// indent. Comments of the call's lines are kept, trailing ones on the line
+// of their pair. The result is formatted as gofmt would format it in place.
+// It reports false when a comment cannot be placed.
+func formatEmitCall(call *ast.CallExpr, fset *token.FileSet, indent string, comments []*ast.CommentGroup) (string, bool) {
+	line := func(pos token.Pos) int { return fset.Position(pos).Line }
+
+	// the event name, then the key/value pairs
+	groups := [][]ast.Expr{call.Args[:1]}
+	for i := 1; i < len(call.Args); i += 2 {
+		groups = append(groups, call.Args[i:min(i+2, len(call.Args))])
 	}
-}
+	leading := make([][]string, len(groups)+1)
+	trailing := make([][]string, len(groups))
+	var after []string
 
-func formatCallExpr(call *ast.CallExpr) string {
-	var sb strings.Builder
-	if sel, ok := call.Fun.(*ast.SelectorExpr); ok {
-		sb.WriteString(sel.X.(*ast.Ident).Name)
-		sb.WriteString(".")
-		sb.WriteString(sel.Sel.Name)
-	} else if ident, ok := call.Fun.(*ast.Ident); ok {
-		sb.WriteString(ident.Name)
+	for _, group := range comments {
+		for _, comment := range group.List {
+			if line(comment.End()) < line(call.Pos()) || line(comment.Pos()) > line(call.End()) {
+				continue
+			}
+			if strings.Contains(comment.Text, "\n") || comment.End() < call.Lparen {
+				return "", false
+			}
+			if comment.Pos() > call.Rparen {
+				after = append(after, comment.Text)
+				continue
+			}
+
+			placed := false
+			for g := len(groups) - 1; g >= 0 && !placed; g-- {
+				for _, arg := range groups[g] {
+					if comment.Pos() > arg.Pos() && comment.End() < arg.End() {
+						return "", false
+					}
+				}
+				last := groups[g][len(groups[g])-1]
+				if comment.Pos() > last.End() && line(comment.Pos()) == line(last.End()) {
+					trailing[g] = append(trailing[g], comment.Text)
+					placed = true
+				}
+			}
+			if placed {
+				continue
+			}
+			g := 0
+			for g < len(groups) && groups[g][0].Pos() < comment.Pos() {
+				g++
+			}
+			leading[g] = append(leading[g], comment.Text)
+		}
 	}
-	sb.WriteString("(")
-	for i, arg := range call.Args {
-		if i > 0 {
-			sb.WriteString(", ")
+
+	var sb strings.Builder
+	sb.WriteString("std.Emit(\n")
+	for g, group := range groups {
+		for _, comment := range leading[g] {
+			sb.WriteString(comment + "\n")
+		}
+		for i, arg := range group {
+			if i > 0 {
+				sb.WriteString(" ")
+			}
+			sb.WriteString(exprSource(fset, arg) + ",")
 		}
-		sb.WriteString(formatArg(arg))
+		for _, comment := range trailing[g] {
+			sb.WriteString(" " + comment)
+		}
+		sb.WriteString("\n")
+	}
+	for _, comment := range leading[len(groups)] {
+		sb.WriteString(comment + "\n")
 	}
 	sb.WriteString(")")
-	return sb.String()
+	for _, comment := range after {
+		sb.WriteString(" " + comment)
+	}
+
+	// let gofmt align the trailing comments, within a function body to get
+	// the indentation of a statement
+	src := "package p\n\nfunc _() {\n" + sb.String() + "\n}\n"
+	formatted, err := format.Source([]byte(src))
+	if err != nil {
+		return "", false
+	}
+	body := strings.TrimSuffix(strings.TrimPrefix(string(formatted), "package p\n\nfunc _() {\n"), "\n}\n")
+	lines := strings.Split(body, "\n")
+	for i, l := range lines {
+		l = strings.TrimPrefix(l, "\t")
+		if i > 0 && l != "" {
+			l = indent + l
+		}
+		lines[i] = l
+	}
+	return strings.Join(lines, "\n"), true
+}
+
+func exprSource(fset *token.FileSet, expr ast.Expr) string {
+	var buf bytes.Buffer
+	if err := printer.Fprint(&buf, fset, expr); err != nil {
+		return ""
+	}
+	return buf.String()
 }
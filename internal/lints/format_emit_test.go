@@ -0,0 +1,141 @@
+package lints
+
+import (
+	"go/format"
+	"go/parser"
+	"go/token"
+	"strings"
+	"testing"
+
+	tt "github.com/gnolang/tlin/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEmitFormatFix(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name     string
+		code     string
+		expected string // empty when the call must not be fixed
+	}{
+		{
+			name: "nested call with comments",
+			code: `package foo
+
+import "std"
+
+func transfer(from, to string, amount int, ok bool) {
+	if ok {
+		std.Emit("Transfer", "from", from, // sender
+			// the recipient
+			"to",
+			to, "amount", amount) // done
+	}
+}
+`,
+			expected: `package foo
+
+import "std"
+
+func transfer(from, to string, amount int, ok bool) {
+	if ok {
+		std.Emit(
+			"Transfer",
+			"from", from, // sender
+			// the recipient
+			"to", to,
+			"amount", amount,
+		) // done
+	}
+}
+`,
+		},
+		{
+			name: "call in a case clause with an odd argument",
+			code: `package foo
+
+import "std"
+
+func set(kind int, key string, values map[string]int) {
+	switch kind {
+	case 1:
+		std.Emit("Set", "key", key, "value", values[key], "extra")
+	}
+}
+`,
+			expected: `package foo
+
+import "std"
+
+func set(kind int, key string, values map[string]int) {
+	switch kind {
+	case 1:
+		std.Emit(
+			"Set",
+			"key", key,
+			"value", values[key],
+			"extra",
+		)
+	}
+}
+`,
+		},
+		{
+			name: "arguments spread from a slice",
+			code: `package foo
+
+import "std"
+
+func emit(args []string) {
+	std.Emit("Event", "a", args[0], args[1:]...)
+}
+`,
+		},
+		{
+			name: "call sharing its line",
+			code: `package foo
+
+import "std"
+
+func emit(a, b string) {
+	if a != "" { std.Emit("Event", "a", a, "b", b) }
+}
+`,
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			fset := token.NewFileSet()
+			node, err := parser.ParseFile(fset, "test.gno", tc.code, parser.ParseComments)
+			require.NoError(t, err)
+
+			issues, err := DetectEmitFormat("test.gno", node, fset, tt.SeverityWarning)
+			require.NoError(t, err)
+			require.Len(t, issues, 1)
+			issue := issues[0]
+
+			if tc.expected == "" {
+				assert.Empty(t, issue.Suggestion)
+				assert.Zero(t, issue.Confidence)
+				return
+			}
+
+			// replace the lines of the issue as the fixer does, keeping the
+			// indentation of the first one
+			lines := strings.Split(tc.code, "\n")
+			first := lines[issue.Start.Line-1]
+			indent := first[:len(first)-len(strings.TrimLeft(first, "\t"))]
+			fixed := append(append(append([]string{}, lines[:issue.Start.Line-1]...), indent+issue.Suggestion), lines[issue.End.Line:]...)
+			result := strings.Join(fixed, "\n")
+			assert.Equal(t, tc.expected, result)
+
+			formatted, err := format.Source([]byte(result))
+			require.NoError(t, err)
+			assert.Equal(t, string(formatted), result, "the fix must be gofmt compatible")
+		})
+	}
+}
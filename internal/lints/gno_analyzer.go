@@ -21,6 +21,8 @@ type Dependency struct {
 	IsGno      bool
 	IsUsed     bool
 	IsIgnored  bool // aliased as `_`
+	Start      token.Position
+	End        token.Position
 }
 
 type Dependencies map[string]*Dependency
@@ -60,6 +62,8 @@ func analyzeFile(filename string) (*ast.File, Dependencies, error) {
 			IsGno:      isGnoPackage(impPath),
 			IsUsed:     false,
 			IsIgnored:  imp.Name != nil && imp.Name.Name == "_",
+			Start:      fset.Position(imp.Pos()),
+			End:        fset.Position(imp.End()),
 		}
 	}
 
@@ -91,6 +95,8 @@ func runGnoPackageLinter(_ *ast.File, deps Dependencies, severity tt.Severity) [
 			issue := tt.Issue{
 				Rule:     "unused-import",
 				Message:  fmt.Sprintf("unused import: %s", impPath),
+				Start:    dep.Start,
+				End:      dep.End,
 				Severity: severity,
 			}
 			issues = append(issues, issue)
@@ -0,0 +1,249 @@
+package lints
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"math"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+
+	tt "github.com/gnolang/tlin/internal/types"
+)
+
+// SecretOptions tells what DetectHardcodedSecrets looks for.
+type SecretOptions struct {
+	// AddressPrefixes and AddressLength give the shape of an address.
+	AddressPrefixes []string
+	AddressLength   int
+	// MinLength is the length from which hex and base64 runs are checked,
+	// and HexEntropy and Base64Entropy the Shannon entropy, in bits per
+	// character, from which they look random.
+	MinLength     int
+	HexEntropy    float64
+	Base64Entropy float64
+	// Patterns are reported wherever they match.
+	Patterns []*regexp.Regexp
+	// Allowlist holds case-insensitive glob patterns of the names whose
+	// values are expected to be examples, such as testAddr.
+	Allowlist []string
+}
+
+// DefaultSecretAllowlist are the names whose values are expected to be
+// examples.
+var DefaultSecretAllowlist = []string{"example*", "test*"}
+
+const bech32Charset = "qpzry9x8gf2tvdw0s3jn54khce6mua7l"
+
+var (
+	hexRun    = regexp.MustCompile(`[0-9a-fA-F]+`)
+	base64Run = regexp.MustCompile(`[A-Za-z0-9+/_-]+={0,2}`)
+)
+
+// DetectHardcodedSecrets reports string literals holding an address, a
+// random-looking hex or base64 run such as a private key or a token, or a
+// match of the configured patterns. The messages mask the value found.
+//
+// Literals of test files and literals assigned to names of the allowlist
+// are reported as info.
+func DetectHardcodedSecrets(filename string, node *ast.File, fset *token.FileSet, severity tt.Severity, opts SecretOptions) ([]tt.Issue, error) {
+	allowed := make(map[*ast.BasicLit]bool)
+	markAllowed := func(name *ast.Ident, value ast.Node) {
+		if name == nil || !allowlisted(name.Name, opts.Allowlist) {
+			return
+		}
+		ast.Inspect(value, func(n ast.Node) bool {
+			if lit, ok := n.(*ast.BasicLit); ok {
+				allowed[lit] = true
+			}
+			return true
+		})
+	}
+	skipped := make(map[*ast.BasicLit]bool)
+	ast.Inspect(node, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.ImportSpec:
+			skipped[n.Path] = true
+		case *ast.Field:
+			if n.Tag != nil {
+				skipped[n.Tag] = true
+			}
+		case *ast.ValueSpec:
+			for i, name := range n.Names {
+				if i < len(n.Values) {
+					markAllowed(name, n.Values[i])
+				}
+			}
+		case *ast.AssignStmt:
+			if len(n.Lhs) == len(n.Rhs) {
+				for i, lhs := range n.Lhs {
+					markAllowed(assignedName(lhs), n.Rhs[i])
+				}
+			}
+		case *ast.KeyValueExpr:
+			if key, ok := n.Key.(*ast.Ident); ok {
+				markAllowed(key, n.Value)
+			}
+		}
+		return true
+	})
+
+	isTest := strings.HasSuffix(strings.TrimSuffix(strings.TrimSuffix(filename, ".go"), ".gno"), "_test")
+
+	var issues []tt.Issue
+	ast.Inspect(node, func(n ast.Node) bool {
+		lit, ok := n.(*ast.BasicLit)
+		if !ok || lit.Kind != token.STRING || skipped[lit] {
+			return true
+		}
+		value, err := strconv.Unquote(lit.Value)
+		if err != nil {
+			return true
+		}
+
+		litSeverity := severity
+		if (isTest || allowed[lit]) && severity < tt.SeverityInfo {
+			litSeverity = tt.SeverityInfo
+		}
+		for _, finding := range findSecrets(value, opts) {
+			issues = append(issues, tt.Issue{
+				Rule:     "hardcoded-secret",
+				Filename: filename,
+				Start:    fset.Position(lit.Pos()),
+				End:      fset.Position(lit.End()),
+				Message:  fmt.Sprintf("string literal holds %s: %s", finding.kind, maskSecret(finding.value)),
+				Note:     "read it from the configuration or pass it as a parameter, and rotate it if it is a credential",
+				Severity: litSeverity,
+			})
+		}
+		return true
+	})
+	return issues, nil
+}
+
+// assignedName returns the identifier an assignment target names, the
+// field for a selector.
+func assignedName(expr ast.Expr) *ast.Ident {
+	switch e := expr.(type) {
+	case *ast.Ident:
+		return e
+	case *ast.SelectorExpr:
+		return e.Sel
+	}
+	return nil
+}
+
+func allowlisted(name string, patterns []string) bool {
+	name = strings.ToLower(name)
+	for _, pattern := range patterns {
+		if ok, err := filepath.Match(strings.ToLower(pattern), name); err == nil && ok {
+			return true
+		}
+	}
+	return false
+}
+
+type secretFinding struct {
+	kind  string
+	value string
+}
+
+// findSecrets returns what looks like a secret in a string value, at most
+// one finding per run of characters.
+func findSecrets(value string, opts SecretOptions) []secretFinding {
+	var findings []secretFinding
+	for _, word := range strings.FieldsFunc(value, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		if isAddress(word, opts.AddressPrefixes, opts.AddressLength) {
+			findings = append(findings, secretFinding{kind: "an address", value: word})
+		}
+	}
+	for _, run := range hexRun.FindAllString(value, -1) {
+		if len(run) >= opts.MinLength && shannonEntropy(run) >= opts.HexEntropy && !isAddressPart(run, findings) {
+			findings = append(findings, secretFinding{kind: "a random-looking hex string", value: run})
+		}
+	}
+	for _, run := range base64Run.FindAllString(value, -1) {
+		if len(run) < opts.MinLength || !hasLetterAndDigit(run) || isAddressPart(run, findings) || hexRun.FindString(run) == run {
+			continue
+		}
+		if shannonEntropy(run) >= opts.Base64Entropy {
+			findings = append(findings, secretFinding{kind: "a random-looking base64 string", value: run})
+		}
+	}
+	for _, pattern := range opts.Patterns {
+		for _, match := range pattern.FindAllString(value, -1) {
+			findings = append(findings, secretFinding{kind: "a match of " + pattern.String(), value: match})
+		}
+	}
+	return findings
+}
+
+// isAddress reports whether word has the shape of a bech32 address with one
+// of the prefixes.
+func isAddress(word string, prefixes []string, length int) bool {
+	if !looksLikeAddress(word, prefixes, length) {
+		return false
+	}
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(word, prefix) {
+			data := word[len(prefix):]
+			return strings.Trim(data, bech32Charset) == ""
+		}
+	}
+	return false
+}
+
+// isAddressPart reports whether run is within an address already found,
+// whose data part may be mistaken for an encoded string.
+func isAddressPart(run string, findings []secretFinding) bool {
+	for _, f := range findings {
+		if f.kind == "an address" && strings.Contains(f.value, run) {
+			return true
+		}
+	}
+	return false
+}
+
+func hasLetterAndDigit(s string) bool {
+	return strings.ContainsAny(s, "0123456789") && strings.IndexFunc(s, unicode.IsLetter) >= 0
+}
+
+// shannonEntropy returns the entropy of the characters of s, in bits per
+// character: 0 for a repeated character, 4 for a uniform spread over the
+// hex digits.
+func shannonEntropy(s string) float64 {
+	if s == "" {
+		return 0
+	}
+	counts := make(map[rune]int)
+	total := 0
+	for _, r := range s {
+		counts[r]++
+		total++
+	}
+	entropy := 0.0
+	for _, count := range counts {
+		p := float64(count) / float64(total)
+		entropy -= p * math.Log2(p)
+	}
+	return entropy
+}
+
+// maskSecret hides the middle of a secret, keeping enough of its ends to
+// find it: four characters on each side, or the first two of short ones.
+func maskSecret(s string) string {
+	runes := []rune(s)
+	switch {
+	case len(runes) > 12:
+		return string(runes[:4]) + strings.Repeat("*", 4) + string(runes[len(runes)-4:])
+	case len(runes) > 2:
+		return string(runes[:2]) + strings.Repeat("*", len(runes)-2)
+	default:
+		return strings.Repeat("*", len(runes))
+	}
+}
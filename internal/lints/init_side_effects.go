@@ -0,0 +1,191 @@
+package lints
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+
+	tt "github.com/gnolang/tlin/internal/types"
+)
+
+// bankerMethods are the banker calls moving coins.
+var bankerMethods = map[string]bool{
+	"SendCoins":  true,
+	"IssueCoin":  true,
+	"RemoveCoin": true,
+}
+
+// initOperation is an operation that should not run at deployment.
+type initOperation struct {
+	node   ast.Node
+	reason string
+}
+
+// DetectInitSideEffects reports operations of the init functions that run at
+// deployment time: loops whose bound is not a constant, std.Emit calls and
+// banker calls. Calls to functions of the package doing such operations are
+// reported as well, one level deep. Loops over constant-size literals, which
+// seed data structures, are fine.
+func DetectInitSideEffects(pkg *Package, severity tt.Severity) ([]tt.Issue, error) {
+	consts := make(map[string]bool)
+	literals := make(map[string]bool)
+	funcs := make(map[string]*ast.FuncDecl)
+	for _, file := range pkg.Files {
+		collectConsts(file.File, consts)
+		collectLiteralVars(file.File, literals)
+		for _, decl := range file.File.Decls {
+			if fn, ok := decl.(*ast.FuncDecl); ok && fn.Recv == nil && fn.Body != nil {
+				funcs[fn.Name.Name] = fn
+			}
+		}
+	}
+
+	var issues []tt.Issue
+	for _, file := range pkg.Files {
+		for _, decl := range file.File.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok || fn.Recv != nil || fn.Name.Name != "init" || fn.Body == nil {
+				continue
+			}
+
+			for _, op := range initOperations(fn.Body, consts, literals) {
+				issues = append(issues, initIssue(pkg.Fset, file.Filename, op.node, op.reason, severity, nil))
+			}
+
+			ast.Inspect(fn.Body, func(n ast.Node) bool {
+				call, ok := n.(*ast.CallExpr)
+				if !ok {
+					return true
+				}
+				ident, ok := call.Fun.(*ast.Ident)
+				if !ok || funcs[ident.Name] == nil || ident.Name == "init" {
+					return true
+				}
+				ops := initOperations(funcs[ident.Name].Body, consts, literals)
+				if len(ops) == 0 {
+					return true
+				}
+				related := make([]tt.Location, 0, len(ops))
+				for _, op := range ops {
+					related = append(related, tt.Location{Position: pkg.Fset.Position(op.node.Pos()), Label: op.reason})
+				}
+				reason := fmt.Sprintf("init calls %s, which %s", ident.Name, ops[0].reason)
+				issues = append(issues, initIssue(pkg.Fset, file.Filename, call, reason, severity, related))
+				return true
+			})
+		}
+	}
+	return issues, nil
+}
+
+func initIssue(fset *token.FileSet, filename string, node ast.Node, reason string, severity tt.Severity, related []tt.Location) tt.Issue {
+	return tt.Issue{
+		Rule:     "init-side-effects",
+		Filename: filename,
+		Start:    fset.Position(node.Pos()),
+		End:      fset.Position(node.End()),
+		Message:  reason,
+		Note:     "init runs once at deployment, its cost is paid by the deployer and it cannot be retried",
+		Severity: severity,
+		Related:  related,
+	}
+}
+
+// initOperations lists the operations of body that should not run at deployment.
+func initOperations(body *ast.BlockStmt, consts, literals map[string]bool) []initOperation {
+	// local variables holding a literal
+	locals := make(map[string]bool)
+	collectLiteralVars(body, locals)
+	isConstant := func(expr ast.Expr) bool {
+		return isConstantBound(expr, consts) || isConstantSize(expr, consts, literals) || isConstantSize(expr, consts, locals)
+	}
+
+	var ops []initOperation
+	ast.Inspect(body, func(n ast.Node) bool {
+		switch node := n.(type) {
+		case *ast.FuncLit:
+			return false
+		case *ast.ForStmt:
+			if !hasConstantBound(node, isConstant) {
+				ops = append(ops, initOperation{node: node, reason: "runs a loop whose bound is not a constant at deployment"})
+			}
+		case *ast.RangeStmt:
+			if !isConstant(node.X) {
+				ops = append(ops, initOperation{node: node, reason: "ranges over a collection of unknown size at deployment"})
+			}
+		case *ast.CallExpr:
+			switch name := callName(node); {
+			case name == "std.Emit":
+				ops = append(ops, initOperation{node: node, reason: "emits an event at deployment, before any user interaction"})
+			case isBankerCall(node):
+				ops = append(ops, initOperation{node: node, reason: "moves coins at deployment, when the realm may not hold funds yet"})
+			}
+		}
+		return true
+	})
+	return ops
+}
+
+// hasConstantBound reports whether a three-clause loop compares its counter
+// with a constant.
+func hasConstantBound(loop *ast.ForStmt, isConstant func(ast.Expr) bool) bool {
+	cond, ok := loop.Cond.(*ast.BinaryExpr)
+	if !ok {
+		return false
+	}
+	switch cond.Op {
+	case token.LSS, token.LEQ, token.GTR, token.GEQ, token.NEQ:
+		return isConstant(cond.X) || isConstant(cond.Y)
+	}
+	return false
+}
+
+// isConstantSize reports whether expr is a composite literal, a variable
+// holding one, or the length of either.
+func isConstantSize(expr ast.Expr, consts, literals map[string]bool) bool {
+	switch e := expr.(type) {
+	case *ast.CompositeLit:
+		return true
+	case *ast.Ident:
+		return literals[e.Name]
+	case *ast.CallExpr:
+		return isLenCall(e) && isConstantSize(e.Args[0], consts, literals)
+	}
+	return false
+}
+
+func isBankerCall(call *ast.CallExpr) bool {
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	return ok && bankerMethods[sel.Sel.Name]
+}
+
+// collectLiteralVars records the variables of node initialized with a composite literal.
+func collectLiteralVars(node ast.Node, literals map[string]bool) {
+	ast.Inspect(node, func(n ast.Node) bool {
+		switch stmt := n.(type) {
+		case *ast.ValueSpec:
+			for i, name := range stmt.Names {
+				if i < len(stmt.Values) {
+					if _, ok := stmt.Values[i].(*ast.CompositeLit); ok {
+						literals[name.Name] = true
+					}
+				}
+			}
+		case *ast.AssignStmt:
+			if stmt.Tok != token.DEFINE || len(stmt.Lhs) != len(stmt.Rhs) {
+				return true
+			}
+			for i, lhs := range stmt.Lhs {
+				ident, ok := lhs.(*ast.Ident)
+				if _, isLit := stmt.Rhs[i].(*ast.CompositeLit); ok && isLit {
+					literals[ident.Name] = true
+				}
+			}
+		case *ast.FuncDecl:
+			// only package level declarations when node is a file
+			_, isFile := node.(*ast.File)
+			return !isFile
+		}
+		return true
+	})
+}
@@ -0,0 +1,131 @@
+package lints
+
+import (
+	"testing"
+
+	tt "github.com/gnolang/tlin/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetectInitSideEffects(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name     string
+		files    map[string]string
+		messages []string
+	}{
+		{
+			name: "seeding with constant-size literals",
+			files: map[string]string{
+				"a.gno": `package registry
+
+const size = 10
+
+var names = []string{"a", "b"}
+
+var slots [size]int
+
+func init() {
+	for _, n := range names {
+		register(n)
+	}
+	for i := 0; i < size; i++ {
+		slots[i] = i
+	}
+	for i := range 3 {
+		slots[i] = 0
+	}
+	defaults := map[string]int{"x": 1}
+	for k, v := range defaults {
+		_ = k + v
+	}
+	for _, n := range []int{1, 2, 3} {
+		_ = n
+	}
+}
+
+func register(name string) {}
+`,
+			},
+		},
+		{
+			name: "direct operations",
+			files: map[string]string{
+				"a.gno": `package registry
+
+import "std"
+
+var users []string
+
+func init() {
+	for i := 0; i < len(users); i++ {
+	}
+	for _, u := range users {
+		_ = u
+	}
+	std.Emit("Deployed")
+	banker := std.NewBanker(std.BankerTypeRealmSend)
+	banker.SendCoins(std.CurrentRealm().Addr(), owner, coins)
+}
+`,
+			},
+			messages: []string{
+				"runs a loop whose bound is not a constant at deployment",
+				"ranges over a collection of unknown size at deployment",
+				"emits an event at deployment, before any user interaction",
+				"moves coins at deployment, when the realm may not hold funds yet",
+			},
+		},
+		{
+			name: "calls one level deep across files",
+			files: map[string]string{
+				"a.gno": `package registry
+
+func init() {
+	setup()
+	seed()
+}
+`,
+				"b.gno": `package registry
+
+import "std"
+
+func setup() {
+	std.Emit("Setup")
+	inner()
+}
+
+func inner() {
+	std.Emit("Inner")
+}
+
+func seed() {}
+
+func notCalled() {
+	std.Emit("Other")
+}
+`,
+			},
+			messages: []string{"init calls setup, which emits an event at deployment, before any user interaction"},
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			pkg := parsePackage(t, tc.files)
+
+			issues, err := DetectInitSideEffects(pkg, tt.SeverityWarning)
+			require.NoError(t, err)
+
+			var messages []string
+			for _, issue := range issues {
+				assert.Equal(t, "init-side-effects", issue.Rule)
+				messages = append(messages, issue.Message)
+			}
+			assert.Equal(t, tc.messages, messages)
+		})
+	}
+}
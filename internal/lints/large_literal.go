@@ -0,0 +1,79 @@
+package lints
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+
+	tt "github.com/gnolang/tlin/internal/types"
+)
+
+// DetectLargeLiterals reports composite literals of more than maxElements
+// elements, nested literals included, built inside functions. Such literals
+// are rebuilt on every call and are better declared once at package level.
+// Package level literals and literals of init functions run only once and
+// are not reported.
+func DetectLargeLiterals(filename string, node *ast.File, fset *token.FileSet, severity tt.Severity, maxElements int) ([]tt.Issue, error) {
+	var issues []tt.Issue
+	inspectBody := func(body *ast.BlockStmt, funcName string) {
+		ast.Inspect(body, func(n ast.Node) bool {
+			lit, ok := n.(*ast.CompositeLit)
+			if !ok {
+				return true
+			}
+			size := literalSize(lit)
+			if size <= maxElements {
+				return true
+			}
+			issues = append(issues, tt.Issue{
+				Rule:     "large-literal",
+				Filename: filename,
+				Start:    fset.Position(lit.Pos()),
+				End:      fset.Position(lit.End()),
+				Message: fmt.Sprintf("composite literal with %d elements is rebuilt on every call of %s, exceeding the maximum of %d",
+					size, funcName, maxElements),
+				Note:     "move it to a package-level variable, or generate it",
+				Severity: severity,
+			})
+			return false
+		})
+	}
+
+	for _, decl := range node.Decls {
+		switch d := decl.(type) {
+		case *ast.FuncDecl:
+			if d.Body != nil && !(d.Recv == nil && d.Name.Name == "init") {
+				inspectBody(d.Body, d.Name.Name)
+			}
+		case *ast.GenDecl:
+			// functions declared at package level still run on every call
+			ast.Inspect(d, func(n ast.Node) bool {
+				if fn, ok := n.(*ast.FuncLit); ok {
+					inspectBody(fn.Body, "the function literal")
+					return false
+				}
+				return true
+			})
+		}
+	}
+	return issues, nil
+}
+
+// literalSize counts the elements of a composite literal, including the
+// elements of the literals nested in it.
+func literalSize(lit *ast.CompositeLit) int {
+	size := 0
+	for _, elt := range lit.Elts {
+		size++
+		if kv, ok := elt.(*ast.KeyValueExpr); ok {
+			elt = kv.Value
+		}
+		if unary, ok := elt.(*ast.UnaryExpr); ok && unary.Op == token.AND {
+			elt = unary.X
+		}
+		if nested, ok := elt.(*ast.CompositeLit); ok {
+			size += literalSize(nested)
+		}
+	}
+	return size
+}
@@ -0,0 +1,83 @@
+package lints
+
+import (
+	"fmt"
+	"go/parser"
+	"go/token"
+	"strings"
+	"testing"
+
+	tt "github.com/gnolang/tlin/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetectLargeLiterals(t *testing.T) {
+	t.Parallel()
+	elements := func(n int) string {
+		parts := make([]string, 0, n)
+		for i := 0; i < n; i++ {
+			parts = append(parts, fmt.Sprintf("%d", i))
+		}
+		return strings.Join(parts, ", ")
+	}
+	pairs := func(n int) string {
+		parts := make([]string, 0, n)
+		for i := 0; i < n; i++ {
+			parts = append(parts, fmt.Sprintf("%q: {%d, %d}", fmt.Sprint("k", i), i, i))
+		}
+		return strings.Join(parts, ", ")
+	}
+
+	tests := []struct {
+		name     string
+		code     string
+		messages []string
+	}{
+		{
+			name: "large literal in a function",
+			code: "package foo\n\nfunc lookup(i int) int {\n\ttable := []int{" + elements(60) + "}\n\treturn table[i]\n}\n",
+			messages: []string{
+				"composite literal with 60 elements is rebuilt on every call of lookup, exceeding the maximum of 10",
+			},
+		},
+		{
+			name: "nested elements are counted",
+			code: "package foo\n\nfunc rates() map[string][]int {\n\treturn map[string][]int{" + pairs(4) + "}\n}\n",
+			messages: []string{
+				"composite literal with 12 elements is rebuilt on every call of rates, exceeding the maximum of 10",
+			},
+		},
+		{
+			name: "function literal at package level",
+			code: "package foo\n\nvar handler = func() []int {\n\treturn []int{" + elements(11) + "}\n}\n",
+			messages: []string{
+				"composite literal with 11 elements is rebuilt on every call of the function literal, exceeding the maximum of 10",
+			},
+		},
+		{
+			name: "package level and init literals",
+			code: "package foo\n\nvar table = []int{" + elements(100) + "}\n\nvar seed []int\n\nfunc init() {\n\tseed = []int{" + elements(100) + "}\n}\n\nfunc small() []int {\n\treturn []int{" + elements(10) + "}\n}\n",
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			fset := token.NewFileSet()
+			node, err := parser.ParseFile(fset, "test.gno", tc.code, 0)
+			require.NoError(t, err)
+
+			issues, err := DetectLargeLiterals("test.gno", node, fset, tt.SeverityWarning, 10)
+			require.NoError(t, err)
+
+			var messages []string
+			for _, issue := range issues {
+				assert.Equal(t, "large-literal", issue.Rule)
+				messages = append(messages, issue.Message)
+			}
+			assert.Equal(t, tc.messages, messages)
+		})
+	}
+}
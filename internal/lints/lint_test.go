@@ -198,7 +198,8 @@ func example() {
 			node, fset, err := ParseFile(tmpfile, nil)
 			require.NoError(t, err)
 
-			issues, err := DetectUnnecessaryConversions(tmpfile, node, fset, types.SeverityError)
+			pkg := NewPackage(fset, &PackageFile{Filename: tmpfile, File: node})
+			issues, err := CheckTyped(pkg, DetectUnnecessaryConversions, types.SeverityError)
 			require.NoError(t, err)
 
 			assert.Equal(
@@ -299,27 +300,27 @@ func TestFormatEmitCall(t *testing.T) {
 			name:  "Simple Emit call",
 			input: `std.Emit("OwnershipChange", "newOwner", newOwner.String())`,
 			expected: `std.Emit(
-    "OwnershipChange",
-    "newOwner", newOwner.String(),
+	"OwnershipChange",
+	"newOwner", newOwner.String(),
 )`,
 		},
 		{
 			name:  "Emit call with multiple key-value pairs",
 			input: `std.Emit("OwnershipChange", "newOwner", newOwner.String(), "oldOwner", oldOwner.String())`,
 			expected: `std.Emit(
-    "OwnershipChange",
-    "newOwner", newOwner.String(),
-    "oldOwner", oldOwner.String(),
+	"OwnershipChange",
+	"newOwner", newOwner.String(),
+	"oldOwner", oldOwner.String(),
 )`,
 		},
 		{
 			name:  "Emit call with function calls as values",
 			input: `std.Emit("Transfer", "from", sender.Address(), "to", recipient.Address(), "amount", token.Format(amount))`,
 			expected: `std.Emit(
-    "Transfer",
-    "from", sender.Address(),
-    "to", recipient.Address(),
-    "amount", token.Format(amount),
+	"Transfer",
+	"from", sender.Address(),
+	"to", recipient.Address(),
+	"amount", token.Format(amount),
 )`,
 		},
 	}
@@ -328,202 +329,16 @@ func TestFormatEmitCall(t *testing.T) {
 		tt := tt
 		t.Run(tt.name, func(t *testing.T) {
 			t.Parallel()
-			expr, err := parser.ParseExpr(tt.input)
+			fset := token.NewFileSet()
+			expr, err := parser.ParseExprFrom(fset, "", tt.input, 0)
 			assert.NoError(t, err)
 
 			callExpr, ok := expr.(*ast.CallExpr)
 			assert.True(t, ok)
 
-			result := formatEmitCall(callExpr)
+			result, ok := formatEmitCall(callExpr, fset, "", nil)
+			assert.True(t, ok)
 			assert.Equal(t, tt.expected, result)
 		})
 	}
 }
-
-func TestDetectUselessBreak(t *testing.T) {
-	tests := []struct {
-		name     string
-		code     string
-		expected int
-	}{
-		{
-			name: "No useless break",
-			code: `
-package main
-
-func main() {
-	switch x := 1; x {
-	case 1:
-		println("one")
-	case 2:
-		println("two")
-	default:
-		println("other")
-	}
-}`,
-			expected: 0,
-		},
-		{
-			name: "Useless break in switch",
-			code: `
-package main
-
-func main() {
-	switch x := 1; x {
-	case 1:
-		println("one")
-		break
-	case 2:
-		println("two")
-	default:
-		println("other")
-		break
-	}
-}`,
-			expected: 2,
-		},
-		{
-			name: "Useless break in select",
-			code: `
-package main
-
-func main() {
-	select {
-	case <-ch1:
-		println("received from ch1")
-		break
-	case <-ch2:
-		println("received from ch2")
-	default:
-		println("no communication")
-		break
-	}
-}`,
-			expected: 2,
-		},
-		{
-			name: "Labeled break (not useless)",
-			code: `
-package main
-
-func main() {
-outer:
-	for {
-		switch x := 1; x {
-		case 1:
-			println("one")
-			break outer
-		case 2:
-			println("two")
-		}
-	}
-}`,
-			expected: 0,
-		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			fset := token.NewFileSet()
-			node, err := parser.ParseFile(fset, "test.go", tt.code, parser.ParseComments)
-			require.NoError(t, err)
-
-			issues, err := DetectUselessBreak("test.go", node, fset, types.SeverityError)
-			require.NoError(t, err)
-
-			assert.Equal(
-				t,
-				tt.expected,
-				len(issues),
-				"Number of detected useless break statements doesn't match expected",
-			)
-
-			if len(issues) > 0 {
-				for _, issue := range issues {
-					assert.Equal(t, "useless-break", issue.Rule)
-					assert.Contains(t, issue.Message, "useless break statement")
-				}
-			}
-		})
-	}
-}
-
-func TestDetectConstErrorDeclaration(t *testing.T) {
-	t.Parallel()
-	tests := []struct {
-		name     string
-		code     string
-		expected int
-	}{
-		{
-			name: "Constant error declaration",
-			code: `
-package main
-
-import "errors"
-
-const err = errors.New("error")
-`,
-			expected: 1,
-		},
-		{
-			name: "Constant error declaration with multiple errors",
-			code: `
-package main
-
-import "errors"
-
-const (
-	err1 = errors.New("error1")
-	err2 = errors.New("error2")
-)
-`,
-			expected: 1,
-		},
-		{
-			name: "Variable error declaration",
-			code: `
-package main
-
-import "errors"
-
-var err = errors.New("error")
-`,
-			expected: 0,
-		},
-	}
-
-	for _, tt := range tests {
-		tt := tt
-		t.Run(tt.name, func(t *testing.T) {
-			t.Parallel()
-
-			tmpDir, err := os.MkdirTemp("", "lint-test")
-			require.NoError(t, err)
-			defer os.RemoveAll(tmpDir)
-
-			tmpfile := filepath.Join(tmpDir, "test.go")
-			err = os.WriteFile(tmpfile, []byte(tt.code), 0o644)
-			require.NoError(t, err)
-
-			node, fset, err := ParseFile(tmpfile, nil)
-			require.NoError(t, err)
-
-			issues, err := DetectConstErrorDeclaration(tmpfile, node, fset, types.SeverityError)
-			require.NoError(t, err)
-
-			assert.Equal(
-				t,
-				tt.expected,
-				len(issues),
-				"Number of detected constant error declarations doesn't match expected",
-			)
-
-			for _, issue := range issues {
-				assert.Equal(t, "const-error-declaration", issue.Rule)
-				assert.Contains(t, issue.Message, "avoid declaring constant errors")
-				assert.Contains(t, issue.Suggestion, "var")
-			}
-		})
-	}
-}
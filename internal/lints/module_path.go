@@ -0,0 +1,198 @@
+package lints
+
+import (
+	"fmt"
+	"go/token"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	tt "github.com/gnolang/tlin/internal/types"
+)
+
+// DefaultModuleRoots map the directories holding gno packages to the import
+// path they stand for, as `dir=path`: with `gno.land=gno.land`, the package
+// in `examples/gno.land/r/demo/foo` is expected to be `gno.land/r/demo/foo`.
+var DefaultModuleRoots = []string{"gno.land=gno.land"}
+
+const gnoModFile = "gno.mod"
+
+// DetectModulePathMismatch compares the module path declared by the gno.mod
+// file governing the package with the path its directory maps to through
+// roots, and reports:
+//
+//   - in the directory of gno.mod, a module path the directory does not map
+//     to, on the package clause;
+//   - imports of a package of the module under a stale path, such as the path
+//     of its directory when the module declares another one, or the module
+//     path of another namespace.
+//
+// The issues relate to the module line of gno.mod. Nothing is reported when
+// there is no gno.mod or when the directory does not match any root.
+func DetectModulePathMismatch(pkg *Package, severity tt.Severity, roots []string) ([]tt.Issue, error) {
+	if len(pkg.Files) == 0 {
+		return nil, nil
+	}
+	dir, err := filepath.Abs(pkg.Dir)
+	if err != nil {
+		return nil, nil
+	}
+	modDir, mod, ok := findModule(dir)
+	if !ok {
+		return nil, nil
+	}
+	expected, ok := mapModuleDir(modDir, roots)
+	if !ok {
+		return nil, nil
+	}
+	declared := []tt.Location{{Position: mod.pos, Label: "module declared here"}}
+
+	var issues []tt.Issue
+	if dir == modDir && mod.path != expected {
+		for _, file := range pkg.Files {
+			if file.IsTest() {
+				continue
+			}
+			issues = append(issues, tt.Issue{
+				Rule:     "module-path-mismatch",
+				Filename: file.Filename,
+				Start:    pkg.Fset.Position(file.File.Name.Pos()),
+				End:      pkg.Fset.Position(file.File.Name.End()),
+				Message:  fmt.Sprintf("gno.mod declares module %s, but its directory maps to %s", mod.path, expected),
+				Note:     "update the module path or move the package, along with the imports of its packages",
+				Severity: severity,
+				Related:  declared,
+			})
+			break
+		}
+	}
+
+	sub, _ := filepath.Rel(modDir, dir)
+	for _, file := range pkg.Files {
+		for _, imp := range file.File.Imports {
+			importPath, err := strconv.Unquote(imp.Path.Value)
+			if err != nil {
+				continue
+			}
+			want, stale := staleImport(importPath, mod.path, expected, modDir, sub != ".")
+			if !stale {
+				continue
+			}
+			issues = append(issues, tt.Issue{
+				Rule:     "module-path-mismatch",
+				Filename: file.Filename,
+				Start:    pkg.Fset.Position(imp.Path.Pos()),
+				End:      pkg.Fset.Position(imp.Path.End()),
+				Message:  fmt.Sprintf("import %q refers to module %s under a stale path, expected %q", importPath, mod.path, want),
+				Severity: severity,
+				Related:  declared,
+			})
+		}
+	}
+	return issues, nil
+}
+
+type moduleDecl struct {
+	path string
+	pos  token.Position
+}
+
+// findModule looks for the gno.mod file governing dir, in dir or one of its
+// parents, and returns its directory and module declaration.
+func findModule(dir string) (string, moduleDecl, bool) {
+	for {
+		filename := filepath.Join(dir, gnoModFile)
+		if content, err := os.ReadFile(filename); err == nil {
+			mod, ok := parseModuleDecl(filename, string(content))
+			return dir, mod, ok
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", moduleDecl{}, false
+		}
+		dir = parent
+	}
+}
+
+func parseModuleDecl(filename, content string) (moduleDecl, bool) {
+	offset := 0
+	for i, line := range strings.SplitAfter(content, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) >= 2 && fields[0] == "module" {
+			value := strings.Trim(fields[1], `"`)
+			column := strings.Index(line, fields[1])
+			return moduleDecl{
+				path: value,
+				pos: token.Position{
+					Filename: filename,
+					Offset:   offset + column,
+					Line:     i + 1,
+					Column:   column + 1,
+				},
+			}, true
+		}
+		offset += len(line)
+	}
+	return moduleDecl{}, false
+}
+
+// mapModuleDir returns the path dir stands for according to the deepest
+// root it lies in.
+func mapModuleDir(dir string, roots []string) (string, bool) {
+	slashed := "/" + strings.Trim(filepath.ToSlash(dir), "/") + "/"
+	best, mapped := -1, ""
+	for _, root := range roots {
+		rootDir, prefix, ok := strings.Cut(root, "=")
+		rootDir = strings.Trim(rootDir, "/")
+		if !ok || rootDir == "" {
+			continue
+		}
+		at := strings.LastIndex(slashed, "/"+rootDir+"/")
+		if at <= best {
+			continue
+		}
+		rest := strings.Trim(slashed[at+len(rootDir)+1:], "/")
+		best, mapped = at, strings.Trim(path.Join(prefix, rest), "/")
+	}
+	return mapped, best >= 0
+}
+
+// staleImport reports whether importPath refers to a package of the module
+// declared as modPath, whose directory maps to dirPath, under another path,
+// and returns the path expected instead. The module's root package is only
+// considered from its sub packages, the only ones that can import it.
+func staleImport(importPath, modPath, dirPath, modDir string, inSubPackage bool) (string, bool) {
+	if importPath == modPath || strings.HasPrefix(importPath, modPath+"/") {
+		return "", false
+	}
+	rewrite := func(prefix string) (string, bool) {
+		sub := strings.TrimPrefix(strings.TrimPrefix(importPath, prefix), "/")
+		if sub == "" {
+			return modPath, inSubPackage
+		}
+		info, err := os.Stat(filepath.Join(modDir, filepath.FromSlash(sub)))
+		if err != nil || !info.IsDir() {
+			return "", false
+		}
+		return modPath + "/" + sub, true
+	}
+
+	// the path of the directory, when the module declares another one
+	if importPath == dirPath || strings.HasPrefix(importPath, dirPath+"/") {
+		return rewrite(dirPath)
+	}
+
+	// the module under another namespace, keeping its name and kind
+	modSegments := strings.Split(modPath, "/")
+	segments := strings.Split(importPath, "/")
+	n := len(modSegments)
+	if n < 3 || len(segments) < n {
+		return "", false
+	}
+	if segments[0] != modSegments[0] || segments[1] != modSegments[1] || segments[n-1] != modSegments[n-1] {
+		return "", false
+	}
+	return rewrite(strings.Join(segments[:n], "/"))
+}
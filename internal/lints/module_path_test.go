@@ -0,0 +1,123 @@
+package lints
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	tt "github.com/gnolang/tlin/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetectModulePathMismatch(t *testing.T) {
+	t.Parallel()
+	root := t.TempDir()
+	moduleDir := filepath.Join(root, "examples", "gno.land", "r", "demo", "board")
+	require.NoError(t, os.MkdirAll(filepath.Join(moduleDir, "store"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(moduleDir, "gno.mod"), []byte("// Draft\n\nmodule gno.land/r/old/board\n"), 0o644))
+
+	consistent := filepath.Join(root, "examples", "gno.land", "r", "demo", "wiki")
+	require.NoError(t, os.MkdirAll(consistent, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(consistent, "gno.mod"), []byte("// Draft\n\nmodule gno.land/r/demo/wiki\n"), 0o644))
+
+	unmapped := filepath.Join(root, "contracts", "board")
+	require.NoError(t, os.MkdirAll(unmapped, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(unmapped, "gno.mod"), []byte("// Draft\n\nmodule gno.land/r/old/board\n"), 0o644))
+
+	tests := []struct {
+		name     string
+		files    map[string]string
+		roots    []string
+		messages []string
+	}{
+		{
+			name: "module path not matching its directory",
+			files: map[string]string{
+				filepath.Join(moduleDir, "board.gno"): `package board
+
+import "gno.land/r/demo/board/store"
+
+func Render(path string) string { return store.Get(path) }
+`,
+				filepath.Join(moduleDir, "board_test.gno"): `package board
+
+import "gno.land/r/old/board/store"
+`,
+			},
+			roots: DefaultModuleRoots,
+			messages: []string{
+				"gno.mod declares module gno.land/r/old/board, but its directory maps to gno.land/r/demo/board",
+				`import "gno.land/r/demo/board/store" refers to module gno.land/r/old/board under a stale path, expected "gno.land/r/old/board/store"`,
+			},
+		},
+		{
+			name: "sub package importing the module under other namespaces",
+			files: map[string]string{
+				filepath.Join(moduleDir, "store", "store.gno"): `package store
+
+import (
+	"gno.land/p/demo/avl"
+	"gno.land/r/demo/board"
+	"gno.land/r/other/board"
+	"gno.land/r/other/board/missing"
+)
+`,
+			},
+			roots: DefaultModuleRoots,
+			messages: []string{
+				`import "gno.land/r/demo/board" refers to module gno.land/r/old/board under a stale path, expected "gno.land/r/old/board"`,
+				`import "gno.land/r/other/board" refers to module gno.land/r/old/board under a stale path, expected "gno.land/r/old/board"`,
+			},
+		},
+		{
+			name: "consistent module",
+			files: map[string]string{
+				filepath.Join(consistent, "wiki.gno"): `package wiki
+
+import "gno.land/r/demo/board"
+`,
+			},
+			roots: DefaultModuleRoots,
+		},
+		{
+			name: "no root mapping",
+			files: map[string]string{
+				filepath.Join(unmapped, "board.gno"): `package board
+
+import "gno.land/r/demo/board/store"
+`,
+			},
+			roots: DefaultModuleRoots,
+		},
+		{
+			name: "custom root mapping",
+			files: map[string]string{
+				filepath.Join(unmapped, "board.gno"): `package board
+`,
+			},
+			roots: []string{"contracts=gno.land/r/team", "invalid"},
+			messages: []string{
+				"gno.mod declares module gno.land/r/old/board, but its directory maps to gno.land/r/team/board",
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			issues, err := DetectModulePathMismatch(parsePackage(t, tc.files), tt.SeverityWarning, tc.roots)
+			require.NoError(t, err)
+
+			var messages []string
+			for _, issue := range issues {
+				assert.Equal(t, "module-path-mismatch", issue.Rule)
+				require.Len(t, issue.Related, 1)
+				assert.Equal(t, 3, issue.Related[0].Position.Line)
+				messages = append(messages, issue.Message)
+			}
+			assert.Equal(t, tc.messages, messages)
+		})
+	}
+}
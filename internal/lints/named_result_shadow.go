@@ -0,0 +1,222 @@
+package lints
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+
+	tt "github.com/gnolang/tlin/internal/types"
+)
+
+// namedResultShadowConfidence stays below the fix threshold: the issue has no
+// fix, and the fixer would delete the line of the shadowing variable.
+const namedResultShadowConfidence = 0.7
+
+// DetectNamedResultShadowing reports variables declared in an inner scope
+// with the name of a named result, whose last assignment is never read.
+//
+// Assigning a named result from a deferred closure is the usual way to
+// change what a function returns after the fact, as in
+//
+//	defer func() {
+//		if cerr := f.Close(); cerr != nil && err == nil {
+//			err = cerr
+//		}
+//	}()
+//
+// Writing `err := ...` there instead declares a new variable, and the
+// assignments meant for the result are silently dropped. The same happens
+// in an if or for block of the function itself. Shadowing variables that
+// are read after their last assignment, returned for instance, are fine.
+func DetectNamedResultShadowing(filename string, node *ast.File, fset *token.FileSet, severity tt.Severity) ([]tt.Issue, error) {
+	var issues []tt.Issue
+	ast.Inspect(node, func(n ast.Node) bool {
+		var fnType *ast.FuncType
+		var body *ast.BlockStmt
+		switch fn := n.(type) {
+		case *ast.FuncDecl:
+			fnType, body = fn.Type, fn.Body
+		case *ast.FuncLit:
+			fnType, body = fn.Type, fn.Body
+		}
+		if body == nil || fnType.Results == nil {
+			return true
+		}
+
+		for _, field := range fnType.Results.List {
+			for _, result := range field.Names {
+				if result.Name == "_" || result.Obj == nil {
+					continue
+				}
+				for _, shadow := range shadowsOf(body, result) {
+					write := lostWrite(body, shadow)
+					if write == nil {
+						continue
+					}
+					issues = append(issues, tt.Issue{
+						Rule:       "named-result-shadow",
+						Filename:   filename,
+						Start:      fset.Position(shadow.Pos()),
+						End:        fset.Position(shadow.End()),
+						Message:    fmt.Sprintf("%s shadows the named result, so the value assigned to it is never returned", result.Name),
+						Note:       "assign the named result with = instead of declaring a new variable",
+						Confidence: namedResultShadowConfidence,
+						Severity:   severity,
+						Related: []tt.Location{
+							{Position: fset.Position(result.Pos()), Label: "named result " + result.Name},
+							{Position: fset.Position(write.Pos()), Label: "assignment lost"},
+						},
+					})
+				}
+			}
+		}
+		return true
+	})
+	return issues, nil
+}
+
+// shadowsOf returns the identifiers of body declaring a variable named like
+// result in an inner scope. Function literals redeclaring the name in their
+// signature are skipped, the variables there shadow their own parameter.
+func shadowsOf(body *ast.BlockStmt, result *ast.Ident) []*ast.Ident {
+	var shadows []*ast.Ident
+	ast.Inspect(body, func(n ast.Node) bool {
+		switch node := n.(type) {
+		case *ast.FuncLit:
+			return !declaresName(node.Type, result.Name)
+		case *ast.AssignStmt:
+			if node.Tok != token.DEFINE {
+				return true
+			}
+			for _, lhs := range node.Lhs {
+				if ident := declaredIdent(lhs, result); ident != nil {
+					shadows = append(shadows, ident)
+				}
+			}
+		case *ast.ValueSpec:
+			for _, name := range node.Names {
+				if ident := declaredIdent(name, result); ident != nil {
+					shadows = append(shadows, ident)
+				}
+			}
+		}
+		return true
+	})
+	return shadows
+}
+
+// declaresName reports whether a function signature declares name as a
+// parameter or a result.
+func declaresName(fnType *ast.FuncType, name string) bool {
+	for _, list := range []*ast.FieldList{fnType.Params, fnType.Results} {
+		if list == nil {
+			continue
+		}
+		for _, field := range list.List {
+			for _, ident := range field.Names {
+				if ident.Name == name {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// declaredIdent returns expr when it declares a new variable named like
+// result. Identifiers reusing a variable of the same scope in a `:=` resolve
+// to that variable and are not declarations.
+func declaredIdent(expr ast.Expr, result *ast.Ident) *ast.Ident {
+	ident, ok := expr.(*ast.Ident)
+	if !ok || ident.Name != result.Name || ident.Obj == nil || ident.Obj == result.Obj {
+		return nil
+	}
+	if ident.Obj.Kind != ast.Var || ident.Obj.Pos() != ident.Pos() {
+		return nil
+	}
+	return ident
+}
+
+// lostWrite returns the last assignment of the variable declared by shadow
+// when nothing reads it afterwards, or nil. Variables whose address is taken,
+// or that are captured by another function literal, may be read anywhere
+// and are never reported.
+func lostWrite(body *ast.BlockStmt, shadow *ast.Ident) *ast.Ident {
+	obj := shadow.Obj
+	written := map[*ast.Ident]ast.Stmt{}
+	var writes, reads []*ast.Ident
+	var loops []ast.Node
+	escapes := false
+
+	var funcs []*ast.FuncLit
+	ast.Inspect(body, func(n ast.Node) bool {
+		if escapes {
+			return false
+		}
+		switch node := n.(type) {
+		case *ast.FuncLit:
+			funcs = append(funcs, node)
+		case *ast.ForStmt, *ast.RangeStmt:
+			if node.Pos() > shadow.Pos() {
+				loops = append(loops, node)
+			}
+		case *ast.AssignStmt:
+			for _, lhs := range node.Lhs {
+				if ident, ok := lhs.(*ast.Ident); ok && ident.Obj == obj && ident != shadow {
+					written[ident] = node
+					writes = append(writes, ident)
+				}
+			}
+		case *ast.IncDecStmt:
+			if ident, ok := node.X.(*ast.Ident); ok && ident.Obj == obj {
+				written[ident] = node
+				writes = append(writes, ident)
+			}
+		case *ast.UnaryExpr:
+			if ident, ok := node.X.(*ast.Ident); ok && node.Op == token.AND && ident.Obj == obj {
+				escapes = true
+			}
+		case *ast.Ident:
+			if node.Obj != obj || node == shadow || written[node] != nil {
+				return true
+			}
+			reads = append(reads, node)
+			if captured(funcs, node, shadow) {
+				escapes = true
+			}
+		}
+		return true
+	})
+	if escapes || len(writes) == 0 {
+		return nil
+	}
+
+	last := writes[len(writes)-1]
+	for _, read := range reads {
+		if read.Pos() >= written[last].End() {
+			return nil
+		}
+		// a loop reads the value again on its next iteration
+		for _, loop := range loops {
+			if within(loop, last) && within(loop, read) {
+				return nil
+			}
+		}
+	}
+	return last
+}
+
+// captured reports whether ident sits in one of funcs that does not also
+// contain the declaration of its variable.
+func captured(funcs []*ast.FuncLit, ident, shadow *ast.Ident) bool {
+	for _, fn := range funcs {
+		if within(fn, ident) && !within(fn, shadow) {
+			return true
+		}
+	}
+	return false
+}
+
+func within(outer, inner ast.Node) bool {
+	return inner.Pos() >= outer.Pos() && inner.End() <= outer.End()
+}
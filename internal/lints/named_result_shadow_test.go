@@ -0,0 +1,173 @@
+package lints
+
+import (
+	"go/parser"
+	"go/token"
+	"testing"
+
+	tt "github.com/gnolang/tlin/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetectNamedResultShadowing(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name    string
+		code    string
+		shadows []int // lines of the reported declarations
+		results []int // lines of the related named results
+		writes  []int // lines of the related lost assignments
+	}{
+		{
+			name: "deferred closure declaring err",
+			code: `package foo
+
+type closer interface{ Close() error }
+
+func wrap(err error) error { return err }
+
+func save(f closer) (err error) {
+	defer func() {
+		err := f.Close()
+		if err != nil {
+			err = wrap(err)
+		}
+	}()
+	return nil
+}
+`,
+			shadows: []int{9},
+			results: []int{7},
+			writes:  []int{11},
+		},
+		{
+			name: "assigning the named result",
+			code: `package foo
+
+type closer interface{ Close() error }
+
+func save(f closer) (err error) {
+	defer func() {
+		if cerr := f.Close(); cerr != nil && err == nil {
+			err = cerr
+		}
+	}()
+	return nil
+}
+`,
+		},
+		{
+			name: "plain block in the function",
+			code: `package foo
+
+func get() (int, error) { return 0, nil }
+
+func load(retry bool) (n int, err error) {
+	if retry {
+		var n int
+		n, err = get()
+		if n < 0 {
+			n = 0
+		}
+	}
+	return
+}
+`,
+			shadows: []int{7},
+			results: []int{5},
+			writes:  []int{10},
+		},
+		{
+			name: "shadow returned or read",
+			code: `package foo
+
+func get() (int, error) { return 0, nil }
+
+func load(retry bool) (n int, err error) {
+	if retry {
+		n, err := get()
+		if err != nil {
+			err = nil
+			return n, err
+		}
+	}
+	for i := 0; i < 3; i++ {
+		err := check(i)
+		for err != nil {
+			err = check(i + 1)
+		}
+	}
+	return
+}
+
+func check(i int) error { return nil }
+`,
+		},
+		{
+			name: "redeclared in the same scope",
+			code: `package foo
+
+func get() (int, error) { return 0, nil }
+
+func load() (n int, err error) {
+	x, err := get()
+	_ = x
+	err = nil
+	return n, nil
+}
+`,
+		},
+		{
+			name: "captured or parameter of a closure",
+			code: `package foo
+
+func run(f func()) { f() }
+
+func load() (err error) {
+	if true {
+		err := check()
+		run(func() { _ = err })
+		err = nil
+	}
+	run(func() {
+		handle := func(err error) {
+			err = nil
+		}
+		handle(nil)
+	})
+	return
+}
+
+func check() error { return nil }
+`,
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			fset := token.NewFileSet()
+			node, err := parser.ParseFile(fset, "test.gno", tc.code, 0)
+			require.NoError(t, err)
+
+			issues, err := DetectNamedResultShadowing("test.gno", node, fset, tt.SeverityWarning)
+			require.NoError(t, err)
+
+			var shadows, results, writes []int
+			for _, issue := range issues {
+				assert.Equal(t, "named-result-shadow", issue.Rule)
+				// no fix, so -fix must leave the line alone
+				assert.Less(t, issue.Confidence, 0.75)
+				require.Len(t, issue.Related, 2)
+				shadows = append(shadows, issue.Start.Line)
+				results = append(results, issue.Related[0].Position.Line)
+				writes = append(writes, issue.Related[1].Position.Line)
+			}
+			assert.Equal(t, tc.shadows, shadows)
+			assert.Equal(t, tc.results, results)
+			assert.Equal(t, tc.writes, writes)
+		})
+	}
+}
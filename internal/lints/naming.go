@@ -0,0 +1,199 @@
+package lints
+
+import (
+	"fmt"
+	"go/ast"
+	"strings"
+	"unicode"
+
+	tt "github.com/gnolang/tlin/internal/types"
+)
+
+// DefaultInitialisms are the initialisms that must keep a consistent case.
+var DefaultInitialisms = []string{
+	"ACL", "API", "ASCII", "CPU", "CSS", "DNS", "EOF", "GUID", "HTML", "HTTP",
+	"HTTPS", "ID", "IP", "JSON", "RPC", "SQL", "SSH", "TCP", "TLS", "TTL",
+	"UDP", "UI", "UID", "URI", "URL", "UTF8", "UUID", "XML",
+}
+
+type namedIdent struct {
+	filename string
+	ident    *ast.Ident
+	// method identifiers are not prefixed by the package name when used
+	method bool
+}
+
+// DetectNamingIssues reports exported identifiers that repeat the package
+// name (`avl.AVLTree`), contain underscores, or spell an initialism with the
+// wrong case (`UserId`). Methods whose name is required by an interface
+// declared in the package are skipped, as renaming them would break the
+// interface. Renames are not auto-fixable, so issues only carry the
+// suggested name in their message.
+func DetectNamingIssues(pkg *Package, severity tt.Severity, initialisms []string) ([]tt.Issue, error) {
+	known := make(map[string]bool, len(initialisms))
+	for _, initialism := range initialisms {
+		known[strings.ToUpper(initialism)] = true
+	}
+	required := interfaceMethodNames(pkg)
+
+	var issues []tt.Issue
+	for _, file := range pkg.Files {
+		for _, id := range exportedIdents(file) {
+			name := id.ident.Name
+			if id.method && required[name] {
+				continue
+			}
+
+			report := func(format string, args ...interface{}) {
+				issues = append(issues, tt.Issue{
+					Rule:     "exported-naming",
+					Filename: id.filename,
+					Start:    pkg.Fset.Position(id.ident.Pos()),
+					End:      pkg.Fset.Position(id.ident.End()),
+					Message:  fmt.Sprintf(format, args...),
+					Severity: severity,
+				})
+			}
+
+			if !id.method {
+				if fixed, ok := unstutter(pkg.Name, name); ok {
+					report("%s.%s repeats the package name, consider %s", pkg.Name, name, fixed)
+				}
+			}
+			if !file.IsTest() && strings.Contains(name, "_") {
+				report("%s contains underscores, consider %s", name, fixInitialisms(camelCase(name), known))
+			} else if fixed := fixInitialisms(name, known); fixed != name {
+				report("%s has initialisms with the wrong case, consider %s", name, fixed)
+			}
+		}
+	}
+	return issues, nil
+}
+
+// exportedIdents lists the exported top level identifiers and methods of a file.
+func exportedIdents(file *PackageFile) []namedIdent {
+	var idents []namedIdent
+	add := func(ident *ast.Ident, method bool) {
+		if ident.IsExported() {
+			idents = append(idents, namedIdent{filename: file.Filename, ident: ident, method: method})
+		}
+	}
+
+	for _, decl := range file.File.Decls {
+		switch d := decl.(type) {
+		case *ast.FuncDecl:
+			add(d.Name, d.Recv != nil)
+		case *ast.GenDecl:
+			for _, spec := range d.Specs {
+				switch s := spec.(type) {
+				case *ast.TypeSpec:
+					add(s.Name, false)
+				case *ast.ValueSpec:
+					for _, name := range s.Names {
+						add(name, false)
+					}
+				}
+			}
+		}
+	}
+	return idents
+}
+
+// interfaceMethodNames collects the method names of the interfaces declared
+// in the package.
+func interfaceMethodNames(pkg *Package) map[string]bool {
+	names := make(map[string]bool)
+	for _, file := range pkg.Files {
+		ast.Inspect(file.File, func(n ast.Node) bool {
+			iface, ok := n.(*ast.InterfaceType)
+			if !ok {
+				return true
+			}
+			for _, method := range iface.Methods.List {
+				for _, name := range method.Names {
+					names[name.Name] = true
+				}
+			}
+			return true
+		})
+	}
+	return names
+}
+
+// unstutter strips the package name from the start of name when it is
+// followed by another word, as in `token.TokenBalance`.
+func unstutter(pkgName, name string) (string, bool) {
+	if len(name) <= len(pkgName) || !strings.EqualFold(name[:len(pkgName)], pkgName) {
+		return "", false
+	}
+	rest := name[len(pkgName):]
+	if !unicode.IsUpper(rune(rest[0])) {
+		return "", false
+	}
+	return rest, true
+}
+
+// camelCase joins the parts of an underscored name, so `MAX_SIZE` becomes
+// `MaxSize` and `Get_value` becomes `GetValue`.
+func camelCase(name string) string {
+	var sb strings.Builder
+	for _, part := range strings.Split(name, "_") {
+		if part == "" {
+			continue
+		}
+		if isUpperWord(part) {
+			part = part[:1] + strings.ToLower(part[1:])
+		}
+		sb.WriteString(strings.ToUpper(part[:1]) + part[1:])
+	}
+	return sb.String()
+}
+
+func isUpperWord(s string) bool {
+	return len(s) > 1 && strings.ToUpper(s) == s
+}
+
+// fixInitialisms upper cases the words of name that are initialisms, as well
+// as their plural form (`Ids` becomes `IDs`).
+func fixInitialisms(name string, initialisms map[string]bool) string {
+	var sb strings.Builder
+	for _, word := range splitWords(name) {
+		upper := strings.ToUpper(word)
+		switch {
+		case initialisms[upper]:
+			word = upper
+		case len(word) > 2 && strings.HasSuffix(word, "s") && initialisms[strings.ToUpper(word[:len(word)-1])]:
+			word = upper[:len(upper)-1] + "s"
+		}
+		sb.WriteString(word)
+	}
+	return sb.String()
+}
+
+// splitWords splits a mixed caps name into its words: `HTTPServerId` gives
+// `HTTP`, `Server` and `Id`. Digits stay with the word before them.
+func splitWords(name string) []string {
+	runes := []rune(name)
+	var words []string
+	start := 0
+	for i := 1; i < len(runes); i++ {
+		prev, cur := runes[i-1], runes[i]
+		boundary := false
+		switch {
+		case cur == '_' || prev == '_':
+			boundary = true
+		case unicode.IsLower(prev) && unicode.IsUpper(cur):
+			boundary = true
+		case unicode.IsDigit(prev) && unicode.IsUpper(cur):
+			boundary = true
+		case unicode.IsUpper(prev) && unicode.IsUpper(cur) && i+1 < len(runes) && unicode.IsLower(runes[i+1]):
+			// last capital of an upper case run starts the next word
+			boundary = true
+		}
+		if boundary {
+			words = append(words, string(runes[start:i]))
+			start = i
+		}
+	}
+	return append(words, string(runes[start:]))
+}
@@ -0,0 +1,135 @@
+package lints
+
+import (
+	"testing"
+
+	tt "github.com/gnolang/tlin/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetectNamingIssues(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name     string
+		files    map[string]string
+		messages []string
+	}{
+		{
+			name: "stuttering names",
+			files: map[string]string{
+				"avl.gno": `package avl
+
+type AVLTree struct{}
+
+func AvlNew() *AVLTree { return nil }
+
+type Avl struct{}
+
+func (t *AVLTree) AVLHeight() int { return 0 }
+
+var Available = true
+`,
+			},
+			messages: []string{
+				"avl.AVLTree repeats the package name, consider Tree",
+				"avl.AvlNew repeats the package name, consider New",
+			},
+		},
+		{
+			name: "underscores",
+			files: map[string]string{
+				"a.gno": `package token
+
+const MAX_SUPPLY = 100
+
+func Get_user_id() {}
+
+func private_helper() {}
+`,
+				"a_test.gno": `package token
+
+func Test_transfer() {}
+`,
+			},
+			messages: []string{
+				"MAX_SUPPLY contains underscores, consider MaxSupply",
+				"Get_user_id contains underscores, consider GetUserID",
+			},
+		},
+		{
+			name: "initialisms",
+			files: map[string]string{
+				"a.gno": `package users
+
+type User struct{}
+
+func (u *User) Id() string { return "" }
+
+func HttpGetUrl() {}
+
+var UserIds []string
+
+var UserIDs, HTTPServer, Identity, URLs = 1, 2, 3, 4
+`,
+			},
+			messages: []string{
+				"Id has initialisms with the wrong case, consider ID",
+				"HttpGetUrl has initialisms with the wrong case, consider HTTPGetURL",
+				"UserIds has initialisms with the wrong case, consider UserIDs",
+			},
+		},
+		{
+			name: "methods required by an interface",
+			files: map[string]string{
+				"a.gno": `package users
+
+type Identifier interface {
+	Id() string
+}
+`,
+				"b.gno": `package users
+
+type User struct{}
+
+func (u User) Id() string { return "" }
+
+func (u User) Url() string { return "" }
+`,
+			},
+			messages: []string{"Url has initialisms with the wrong case, consider URL"},
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			pkg := parsePackage(t, tc.files)
+
+			issues, err := DetectNamingIssues(pkg, tt.SeverityInfo, DefaultInitialisms)
+			require.NoError(t, err)
+
+			var messages []string
+			for _, issue := range issues {
+				assert.Equal(t, "exported-naming", issue.Rule)
+				assert.Empty(t, issue.Suggestion)
+				messages = append(messages, issue.Message)
+			}
+			assert.Equal(t, tc.messages, messages)
+		})
+	}
+}
+
+func TestDetectNamingIssuesCustomInitialisms(t *testing.T) {
+	t.Parallel()
+	pkg := parsePackage(t, map[string]string{
+		"a.gno": "package grc\n\nfunc GetGrc20Id() {}\n\nfunc NftURL() {}\n",
+	})
+
+	issues, err := DetectNamingIssues(pkg, tt.SeverityInfo, []string{"id", "NFT"})
+	require.NoError(t, err)
+	require.Len(t, issues, 2)
+	assert.Equal(t, "GetGrc20Id has initialisms with the wrong case, consider GetGrc20ID", issues[0].Message)
+	assert.Equal(t, "NftURL has initialisms with the wrong case, consider NFTURL", issues[1].Message)
+}
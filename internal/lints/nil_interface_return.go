@@ -0,0 +1,240 @@
+package lints
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+
+	tt "github.com/gnolang/tlin/internal/types"
+)
+
+// DetectNilInterfaceReturns reports concrete nil values returned for an
+// interface result, such as a nil *MyErr returned as an error: the caller
+// receives a non-nil interface holding a nil pointer, and `err != nil` holds.
+//
+// Two forms are reported: conversions of nil to a concrete type, and local
+// pointer variables that are never assigned anything but nil and whose
+// address is not taken. Returns guarded by a `p != nil` condition, or
+// following an early `if p == nil` exit, are fine.
+func DetectNilInterfaceReturns(filename string, node *ast.File, fset *token.FileSet, info *types.Info, severity tt.Severity) ([]tt.Issue, error) {
+	qualifier := fileQualifier(node, info)
+
+	var issues []tt.Issue
+	var stack []ast.Node
+	ast.Inspect(node, func(n ast.Node) bool {
+		if n == nil {
+			stack = stack[:len(stack)-1]
+			return true
+		}
+		stack = append(stack, n)
+
+		ret, ok := n.(*ast.ReturnStmt)
+		if !ok || len(ret.Results) == 0 {
+			return true
+		}
+		sig, body := enclosingSignature(stack, info)
+		if sig == nil || sig.Results().Len() != len(ret.Results) {
+			return true
+		}
+
+		for i, expr := range ret.Results {
+			result := sig.Results().At(i).Type()
+			if !types.IsInterface(result) {
+				continue
+			}
+			issue := tt.Issue{
+				Rule:     "nil-interface-return",
+				Filename: filename,
+				Start:    fset.Position(expr.Pos()),
+				End:      fset.Position(expr.End()),
+				Note:     "return a literal nil instead, so that the caller gets a nil interface",
+				Severity: severity,
+			}
+
+			switch e := ast.Unparen(expr).(type) {
+			case *ast.CallExpr:
+				typ, ok := nilConversion(e, info)
+				if !ok {
+					continue
+				}
+				issue.Message = fmt.Sprintf("nil converted to %s is returned as %s, which is then not nil",
+					types.TypeString(typ, qualifier), types.TypeString(result, qualifier))
+			case *ast.Ident:
+				v, ok := info.Uses[e].(*types.Var)
+				if !ok || !isNilPointerVar(v, body, info) || nilGuarded(stack, e.Name) {
+					continue
+				}
+				issue.Message = fmt.Sprintf("%s is a nil %s returned as %s, which is then not nil",
+					e.Name, types.TypeString(v.Type(), qualifier), types.TypeString(result, qualifier))
+				issue.Related = []tt.Location{{Position: fset.Position(v.Pos()), Label: e.Name + " declared here"}}
+			default:
+				continue
+			}
+			issues = append(issues, issue)
+		}
+		return true
+	})
+	return issues, nil
+}
+
+// enclosingSignature returns the signature and body of the innermost function
+// of the stack.
+func enclosingSignature(stack []ast.Node, info *types.Info) (*types.Signature, *ast.BlockStmt) {
+	for i := len(stack) - 1; i >= 0; i-- {
+		switch fn := stack[i].(type) {
+		case *ast.FuncLit:
+			sig, _ := info.Types[fn].Type.(*types.Signature)
+			return sig, fn.Body
+		case *ast.FuncDecl:
+			obj, ok := info.Defs[fn.Name]
+			if !ok || obj == nil {
+				return nil, nil
+			}
+			sig, _ := obj.Type().(*types.Signature)
+			return sig, fn.Body
+		}
+	}
+	return nil, nil
+}
+
+// nilConversion matches `(*T)(nil)` and similar conversions of nil to a
+// concrete type.
+func nilConversion(call *ast.CallExpr, info *types.Info) (types.Type, bool) {
+	if len(call.Args) != 1 {
+		return nil, false
+	}
+	fun, ok := info.Types[call.Fun]
+	if !ok || !fun.IsType() || types.IsInterface(fun.Type) {
+		return nil, false
+	}
+	arg, ok := info.Types[call.Args[0]]
+	return fun.Type, ok && arg.IsNil()
+}
+
+// isNilPointerVar reports whether v is a pointer variable local to body that
+// is only ever assigned nil and whose address is never taken.
+func isNilPointerVar(v *types.Var, body *ast.BlockStmt, info *types.Info) bool {
+	if _, ok := v.Type().Underlying().(*types.Pointer); !ok {
+		return false
+	}
+	if body == nil || v.Pos() < body.Pos() || v.Pos() >= body.End() {
+		// parameters and results may hold anything
+		return false
+	}
+
+	isVar := func(expr ast.Expr) bool {
+		ident, ok := ast.Unparen(expr).(*ast.Ident)
+		return ok && (info.Uses[ident] == v || info.Defs[ident] == v)
+	}
+	isNil := func(expr ast.Expr) bool {
+		tv, ok := info.Types[expr]
+		if ok && tv.IsNil() {
+			return true
+		}
+		if call, ok := ast.Unparen(expr).(*ast.CallExpr); ok {
+			_, isConv := nilConversion(call, info)
+			return isConv
+		}
+		return false
+	}
+
+	onlyNil := true
+	ast.Inspect(body, func(n ast.Node) bool {
+		switch node := n.(type) {
+		case *ast.AssignStmt:
+			for i, lhs := range node.Lhs {
+				if !isVar(lhs) {
+					continue
+				}
+				if len(node.Lhs) != len(node.Rhs) || !isNil(node.Rhs[i]) {
+					onlyNil = false
+				}
+			}
+		case *ast.ValueSpec:
+			for i, name := range node.Names {
+				if isVar(name) && i < len(node.Values) && !isNil(node.Values[i]) {
+					onlyNil = false
+				}
+			}
+		case *ast.RangeStmt:
+			if (node.Key != nil && isVar(node.Key)) || (node.Value != nil && isVar(node.Value)) {
+				onlyNil = false
+			}
+		case *ast.UnaryExpr:
+			if node.Op == token.AND && isVar(node.X) {
+				onlyNil = false
+			}
+		}
+		return onlyNil
+	})
+	return onlyNil
+}
+
+// nilGuarded reports whether the top of the stack only runs when name is not
+// nil: within `if name != nil`, the else branch of `if name == nil`, or after
+// an `if name == nil` block that always exits.
+func nilGuarded(stack []ast.Node, name string) bool {
+	for i := len(stack) - 1; i > 0; i-- {
+		child := stack[i]
+		switch parent := stack[i-1].(type) {
+		case *ast.IfStmt:
+			if child == parent.Body && isNilCheck(parent.Cond, name, token.NEQ) {
+				return true
+			}
+			if child == parent.Else && isNilCheck(parent.Cond, name, token.EQL) {
+				return true
+			}
+		case *ast.BlockStmt:
+			for _, stmt := range parent.List {
+				if stmt == child {
+					break
+				}
+				if ifStmt, ok := stmt.(*ast.IfStmt); ok && isNilCheck(ifStmt.Cond, name, token.EQL) && exits(ifStmt.Body) {
+					return true
+				}
+			}
+		case *ast.FuncLit, *ast.FuncDecl:
+			return false
+		}
+	}
+	return false
+}
+
+func isNilCheck(cond ast.Expr, name string, op token.Token) bool {
+	bin, ok := ast.Unparen(cond).(*ast.BinaryExpr)
+	if !ok || bin.Op != op {
+		return false
+	}
+	return (isIdent(bin.X, name) && isIdent(bin.Y, "nil")) || (isIdent(bin.X, "nil") && isIdent(bin.Y, name))
+}
+
+// exits reports whether a block ends with a return or a panic.
+func exits(block *ast.BlockStmt) bool {
+	if len(block.List) == 0 {
+		return false
+	}
+	switch last := block.List[len(block.List)-1].(type) {
+	case *ast.ReturnStmt:
+		return true
+	case *ast.ExprStmt:
+		call, ok := last.X.(*ast.CallExpr)
+		return ok && isIdent(call.Fun, "panic")
+	}
+	return false
+}
+
+// fileQualifier qualifies the types of the other packages than the one of
+// the file by their path, like types.RelativeTo.
+func fileQualifier(node *ast.File, info *types.Info) types.Qualifier {
+	var pkgScope *types.Scope
+	if scope := info.Scopes[node]; scope != nil {
+		pkgScope = scope.Parent()
+	}
+	return func(pkg *types.Package) string {
+		if pkg.Scope() == pkgScope {
+			return ""
+		}
+		return pkg.Path()
+	}
+}
@@ -0,0 +1,180 @@
+package lints
+
+import (
+	"go/parser"
+	"go/token"
+	"testing"
+
+	tt "github.com/gnolang/tlin/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetectNilInterfaceReturns(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name     string
+		code     string
+		messages []string
+		related  []int
+	}{
+		{
+			name: "nil pointer variables",
+			code: `package foo
+
+type MyErr struct{}
+
+func (e *MyErr) Error() string { return "my error" }
+
+func validate() error {
+	var p *MyErr
+	return p
+}
+
+func lookup(ok bool) (int, error) {
+	var p *MyErr = nil
+	if !ok {
+		p = nil
+	}
+	return 0, p
+}
+`,
+			messages: []string{
+				"p is a nil *MyErr returned as error, which is then not nil",
+				"p is a nil *MyErr returned as error, which is then not nil",
+			},
+			related: []int{8, 13},
+		},
+		{
+			name: "nil conversions",
+			code: `package foo
+
+type MyErr struct{}
+
+func (e *MyErr) Error() string { return "my error" }
+
+func check() error {
+	return (*MyErr)(nil)
+}
+
+func value() interface{} {
+	return []int(nil)
+}
+`,
+			messages: []string{
+				"nil converted to *MyErr is returned as error, which is then not nil",
+				"nil converted to []int is returned as interface{}, which is then not nil",
+			},
+		},
+		{
+			name: "fine returns",
+			code: `package foo
+
+type MyErr struct{}
+
+func (e *MyErr) Error() string { return "my error" }
+
+func literal() error {
+	return nil
+}
+
+func guarded() error {
+	var p *MyErr
+	if p != nil {
+		return p
+	}
+	return nil
+}
+
+func earlyExit() error {
+	var p *MyErr
+	if p == nil {
+		return nil
+	}
+	return p
+}
+
+func assigned(fail bool) error {
+	var p *MyErr
+	if fail {
+		p = &MyErr{}
+	}
+	return p
+}
+
+func filled() error {
+	var p *MyErr
+	fill(&p)
+	return p
+}
+
+func fill(p **MyErr) {}
+
+func parameter(p *MyErr) error {
+	return p
+}
+
+func concrete() *MyErr {
+	var p *MyErr
+	return p
+}
+`,
+		},
+		{
+			name: "function literals",
+			code: `package foo
+
+type MyErr struct{}
+
+func (e *MyErr) Error() string { return "my error" }
+
+var handler = func() error {
+	var p *MyErr
+	return p
+}
+`,
+			messages: []string{"p is a nil *MyErr returned as error, which is then not nil"},
+			related:  []int{8},
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			fset := token.NewFileSet()
+			node, err := parser.ParseFile(fset, "test.gno", tc.code, 0)
+			require.NoError(t, err)
+
+			pkg := NewPackage(fset, &PackageFile{Filename: "test.gno", File: node})
+			issues, err := CheckTyped(pkg, DetectNilInterfaceReturns, tt.SeverityWarning)
+			require.NoError(t, err)
+
+			var messages []string
+			var related []int
+			for _, issue := range issues {
+				assert.Equal(t, "nil-interface-return", issue.Rule)
+				messages = append(messages, issue.Message)
+				for _, loc := range issue.Related {
+					related = append(related, loc.Position.Line)
+				}
+			}
+			assert.Equal(t, tc.messages, messages)
+			assert.Equal(t, tc.related, related)
+		})
+	}
+}
+
+func TestDetectNilInterfaceReturnsSiblingFile(t *testing.T) {
+	t.Parallel()
+	pkg := parsePackage(t, map[string]string{
+		"a.gno": "package foo\n\nfunc validate() error {\n\tvar p *MyErr\n\treturn p\n}\n",
+		"b.gno": "package foo\n\ntype MyErr struct{}\n\nfunc (e *MyErr) Error() string { return \"my error\" }\n",
+	})
+
+	issues, err := CheckTyped(pkg, DetectNilInterfaceReturns, tt.SeverityWarning)
+	require.NoError(t, err)
+	require.Len(t, issues, 1, "MyErr is declared in another file of the package")
+	assert.Equal(t, "a.gno", issues[0].Filename)
+	assert.Equal(t, "p is a nil *MyErr returned as error, which is then not nil", issues[0].Message)
+}
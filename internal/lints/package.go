@@ -0,0 +1,273 @@
+package lints
+
+import (
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	tt "github.com/gnolang/tlin/internal/types"
+)
+
+// Package holds the files of a package, parsed with a shared file set,
+// for the rules that need to look at more than one file at a time.
+type Package struct {
+	Name  string
+	Dir   string
+	Fset  *token.FileSet
+	Files []*PackageFile
+
+	// types holds the type information of the files, computed on first use
+	// by TypesInfo and shared by the rules checking the package.
+	types *packageTypes
+}
+
+type packageTypes struct {
+	once    sync.Once
+	info    *types.Info
+	checked []*checkedPackage
+}
+
+// checkedPackage is one of the packages TypesInfo checks: the package with
+// its in-package tests, or its external test package.
+type checkedPackage struct {
+	types  *types.Package
+	files  []*PackageFile
+	errors []types.Error
+}
+
+// PackageFile is a parsed file of a package.
+type PackageFile struct {
+	Filename string
+	File     *ast.File
+}
+
+// IsTest reports whether the file is a test file.
+func (f *PackageFile) IsTest() bool {
+	name := strings.TrimSuffix(strings.TrimSuffix(f.Filename, ".go"), ".gno")
+	return strings.HasSuffix(name, "_test")
+}
+
+// IsGenerated reports whether the file carries the standard
+// `// Code generated ... DO NOT EDIT.` header before its package clause.
+func (f *PackageFile) IsGenerated() bool {
+	for _, group := range f.File.Comments {
+		if group.Pos() > f.File.Package {
+			break
+		}
+		for _, comment := range group.List {
+			text := comment.Text
+			if strings.HasPrefix(text, "// Code generated ") && strings.HasSuffix(text, " DO NOT EDIT.") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Filter returns a copy of the package holding only the files for which keep
+// returns true.
+func (p *Package) Filter(keep func(*PackageFile) bool) *Package {
+	filtered := *p
+	filtered.Files = nil
+	filtered.types = &packageTypes{}
+	for _, f := range p.Files {
+		if keep(f) {
+			filtered.Files = append(filtered.Files, f)
+		}
+	}
+	return &filtered
+}
+
+// NewPackage builds a package out of already parsed files.
+func NewPackage(fset *token.FileSet, files ...*PackageFile) *Package {
+	pkg := &Package{Fset: fset, Files: files, types: &packageTypes{}}
+	if len(files) > 0 {
+		pkg.Name = files[0].File.Name.Name
+		pkg.Dir = filepath.Dir(files[0].Filename)
+	}
+	return pkg
+}
+
+// LoadPackage parses the .go and .gno files of dir that belong to the named
+// package, including its external test package. Files that do not parse are
+// skipped, as they are reported when linted on their own.
+func LoadPackage(dir, name string) (*Package, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	pkg := &Package{Name: name, Dir: dir, Fset: token.NewFileSet(), types: &packageTypes{}}
+	for _, entry := range entries {
+		filename := entry.Name()
+		if entry.IsDir() || strings.HasPrefix(filename, "temp_") {
+			continue
+		}
+		if ext := filepath.Ext(filename); ext != ".go" && ext != ".gno" {
+			continue
+		}
+
+		path := filepath.Join(dir, filename)
+		file, err := parser.ParseFile(pkg.Fset, path, nil, parser.ParseComments)
+		if err != nil {
+			continue
+		}
+		if file.Name.Name != name && file.Name.Name != name+"_test" {
+			continue
+		}
+		pkg.Files = append(pkg.Files, &PackageFile{Filename: path, File: file})
+	}
+
+	sort.Slice(pkg.Files, func(i, j int) bool {
+		return pkg.Files[i].Filename < pkg.Files[j].Filename
+	})
+	return pkg, nil
+}
+
+// typeCheck type checks the non-test files of pkg, which it returns along
+// with the collected information and the checked package. Errors are
+// ignored, gno imports are not resolved and the information is partial.
+func typeCheck(pkg *Package) ([]*PackageFile, *types.Info, *types.Package) {
+	var files []*PackageFile
+	var syntax []*ast.File
+	for _, file := range pkg.Files {
+		if !file.IsTest() {
+			files = append(files, file)
+			syntax = append(syntax, file.File)
+		}
+	}
+	info := &types.Info{
+		Types:      make(map[ast.Expr]types.TypeAndValue),
+		Defs:       make(map[*ast.Ident]types.Object),
+		Uses:       make(map[*ast.Ident]types.Object),
+		Selections: make(map[*ast.SelectorExpr]*types.Selection),
+	}
+	conf := types.Config{
+		Importer: packageImporter,
+		// keep checking past errors such as unresolved gno imports
+		Error: func(error) {},
+	}
+	checked, _ := conf.Check(pkg.Name, pkg.Fset, syntax, info)
+	return files, info, checked
+}
+
+// TypesInfo returns the type information of all the files of the package,
+// test files included. The package is checked along with its in-package
+// tests, and the external test package on its own, into the same
+// information. It is computed once, on first use, and must not be modified.
+// Like typeCheck, it ignores errors and is partial for gno imports.
+func (p *Package) TypesInfo() *types.Info {
+	info, _ := p.checkedTypes()
+	return info
+}
+
+func (p *Package) checkedTypes() (*types.Info, []*checkedPackage) {
+	if p.types == nil {
+		return p.checkTypes()
+	}
+	p.types.once.Do(func() {
+		p.types.info, p.types.checked = p.checkTypes()
+	})
+	return p.types.info, p.types.checked
+}
+
+func (p *Package) checkTypes() (*types.Info, []*checkedPackage) {
+	base := &checkedPackage{}
+	external := &checkedPackage{}
+	for _, file := range p.Files {
+		if file.File.Name.Name == p.Name+"_test" {
+			external.files = append(external.files, file)
+		} else {
+			base.files = append(base.files, file)
+		}
+	}
+	info := &types.Info{
+		Types:      make(map[ast.Expr]types.TypeAndValue),
+		Defs:       make(map[*ast.Ident]types.Object),
+		Uses:       make(map[*ast.Ident]types.Object),
+		Implicits:  make(map[ast.Node]types.Object),
+		Selections: make(map[*ast.SelectorExpr]*types.Selection),
+		Scopes:     make(map[ast.Node]*types.Scope),
+	}
+
+	var checked []*checkedPackage
+	for _, c := range []*checkedPackage{base, external} {
+		if len(c.files) == 0 {
+			continue
+		}
+		name := c.files[0].File.Name.Name
+		syntax := make([]*ast.File, len(c.files))
+		for i, file := range c.files {
+			syntax[i] = file.File
+		}
+		conf := types.Config{
+			Importer: packageImporter,
+			Error: func(err error) {
+				if err, ok := err.(types.Error); ok {
+					c.errors = append(c.errors, err)
+				}
+			},
+		}
+		c.types, _ = conf.Check(name, p.Fset, syntax, info)
+		checked = append(checked, c)
+	}
+	return info, checked
+}
+
+// packageImporter is the importer of every type check. Reading the export
+// data of a package such as fmt costs far more than checking a file, so each
+// package is imported once for the whole run.
+var packageImporter = &cachedImporter{
+	importer: importer.Default(),
+	imported: make(map[string]importResult),
+}
+
+type importResult struct {
+	pkg *types.Package
+	err error
+}
+
+// cachedImporter remembers the packages it imported, and the imports that
+// failed, such as the gno packages. It is safe for concurrent use, which the
+// importers of go/importer are not.
+type cachedImporter struct {
+	mu       sync.Mutex
+	importer types.Importer
+	imported map[string]importResult
+}
+
+func (c *cachedImporter) Import(path string) (*types.Package, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if res, ok := c.imported[path]; ok {
+		return res.pkg, res.err
+	}
+	pkg, err := c.importer.Import(path)
+	c.imported[path] = importResult{pkg: pkg, err: err}
+	return pkg, err
+}
+
+// TypedCheck checks a file knowing the types of its package.
+type TypedCheck func(filename string, node *ast.File, fset *token.FileSet, info *types.Info, severity tt.Severity) ([]tt.Issue, error)
+
+// CheckTyped runs a typed check on every file of the package, sharing the
+// type information of the package between them.
+func CheckTyped(pkg *Package, check TypedCheck, severity tt.Severity) ([]tt.Issue, error) {
+	info := pkg.TypesInfo()
+	var issues []tt.Issue
+	for _, file := range pkg.Files {
+		found, err := check(file.Filename, file.File, pkg.Fset, info, severity)
+		if err != nil {
+			return nil, err
+		}
+		issues = append(issues, found...)
+	}
+	return issues, nil
+}
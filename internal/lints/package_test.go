@@ -0,0 +1,123 @@
+package lints
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	tt "github.com/gnolang/tlin/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// parsePackage builds a package out of in-memory sources keyed by filename.
+func parsePackage(t testing.TB, files map[string]string) *Package {
+	t.Helper()
+
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fset := token.NewFileSet()
+	var parsed []*PackageFile
+	for _, name := range names {
+		file, err := parser.ParseFile(fset, name, files[name], parser.ParseComments)
+		require.NoError(t, err)
+		parsed = append(parsed, &PackageFile{Filename: name, File: file})
+	}
+	return NewPackage(fset, parsed...)
+}
+
+func TestLoadPackage(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+
+	files := map[string]string{
+		"a.gno":       "package foo\n",
+		"b.go":        "package foo\n",
+		"a_test.gno":  "package foo_test\n",
+		"other.gno":   "package bar\n",
+		"broken.gno":  "package foo\nfunc {\n",
+		"temp_1.go":   "package foo\n",
+		"README.md":   "# foo\n",
+		"sub/c.gno":   "package foo\n",
+		"notes.gno.b": "package foo\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	}
+
+	pkg, err := LoadPackage(dir, "foo")
+	require.NoError(t, err)
+
+	var names []string
+	for _, f := range pkg.Files {
+		names = append(names, filepath.Base(f.Filename))
+	}
+	assert.Equal(t, []string{"a.gno", "a_test.gno", "b.go"}, names)
+	assert.True(t, pkg.Files[1].IsTest())
+	assert.False(t, pkg.Files[0].IsTest())
+}
+
+func TestPackageFilter(t *testing.T) {
+	t.Parallel()
+	pkg := parsePackage(t, map[string]string{
+		"a.gno":      "package foo\n",
+		"a_test.gno": "package foo\n",
+		"gen.gno":    "// Code generated by gen. DO NOT EDIT.\n\npackage foo\n",
+		"doc.gno":    "// Package foo.\npackage foo\n\n// Code generated by gen. DO NOT EDIT.\n",
+	})
+
+	generated := pkg.Filter((*PackageFile).IsGenerated)
+	require.Len(t, generated.Files, 1)
+	assert.Equal(t, "gen.gno", generated.Files[0].Filename)
+
+	noTests := pkg.Filter(func(f *PackageFile) bool { return !f.IsTest() })
+	assert.Len(t, noTests.Files, 3)
+	assert.Len(t, pkg.Files, 4)
+}
+
+func TestPackageTypesInfo(t *testing.T) {
+	t.Parallel()
+	pkg := parsePackage(t, map[string]string{
+		"a.gno":        "package foo\n\ntype ID int\n",
+		"b.gno":        "package foo\n\nvar id ID\n",
+		"a_test.gno":   "package foo\n\nvar other = id\n",
+		"ext_test.gno": "package foo_test\n\nvar x int\n",
+	})
+
+	info := pkg.TypesInfo()
+	assert.Same(t, info, pkg.TypesInfo(), "computed once")
+
+	// every file is covered, and resolved against the other files
+	for _, f := range pkg.Files {
+		assert.NotNil(t, info.Scopes[f.File], f.Filename)
+	}
+	spec := pkg.Files[2].File.Decls[0].(*ast.GenDecl).Specs[0].(*ast.ValueSpec)
+	assert.Equal(t, "foo.ID", info.TypeOf(spec.Type).String())
+
+	filtered := pkg.Filter(func(f *PackageFile) bool { return !f.IsTest() })
+	assert.NotSame(t, info, filtered.TypesInfo())
+}
+
+func TestCheckTyped(t *testing.T) {
+	t.Parallel()
+	pkg := parsePackage(t, map[string]string{
+		"a.gno": "package foo\n\ntype ID int\n",
+		"b.gno": "package foo\n\nfunc f(id ID) ID {\n\treturn ID(id)\n}\n",
+	})
+
+	issues, err := CheckTyped(pkg, DetectUnnecessaryConversions, tt.SeverityWarning)
+	require.NoError(t, err)
+	require.Len(t, issues, 1)
+	assert.Equal(t, "b.gno", issues[0].Filename)
+	assert.Equal(t, 4, issues[0].Start.Line)
+}
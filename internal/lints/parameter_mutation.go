@@ -0,0 +1,159 @@
+package lints
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"strings"
+
+	tt "github.com/gnolang/tlin/internal/types"
+)
+
+// DefaultMutationKeywords are the doc comment words documenting that a
+// function mutates its arguments.
+var DefaultMutationKeywords = []string{"modifies", "mutates", "in place"}
+
+// DetectParameterMutation reports exported functions writing through a pointer
+// parameter, assigning to the elements of a slice or map parameter, or
+// appending to a slice parameter and assigning the result back.
+//
+// Functions whose doc comment contains one of keywords, and functions named
+// Set*, Update* or *InPlace, announce the mutation and are not reported.
+// Parameter types are read from the syntax, so named slice, map or pointer
+// types are not recognized.
+func DetectParameterMutation(filename string, node *ast.File, fset *token.FileSet, severity tt.Severity, keywords []string) ([]tt.Issue, error) {
+	var issues []tt.Issue
+	for _, decl := range node.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok || fn.Body == nil || !fn.Name.IsExported() || announcesMutation(fn, keywords) {
+			continue
+		}
+
+		for _, field := range fn.Type.Params.List {
+			kind := referenceKind(field.Type)
+			if kind == "" {
+				continue
+			}
+			for _, param := range field.Names {
+				mutation := findMutation(fn.Body, param.Name, kind)
+				if mutation == nil {
+					continue
+				}
+				issues = append(issues, tt.Issue{
+					Rule:     "parameter-mutation",
+					Filename: filename,
+					Start:    fset.Position(mutation.Pos()),
+					End:      fset.Position(mutation.End()),
+					Message:  fmt.Sprintf("exported function %s mutates its %s parameter %s without documenting it", fn.Name.Name, kind, param.Name),
+					Note:     "mention the mutation in the doc comment, or work on a copy",
+					Severity: severity,
+					Related:  []tt.Location{{Position: fset.Position(param.Pos()), Label: "parameter declared here"}},
+				})
+			}
+		}
+	}
+	return issues, nil
+}
+
+func announcesMutation(fn *ast.FuncDecl, keywords []string) bool {
+	name := fn.Name.Name
+	if strings.HasPrefix(name, "Set") || strings.HasPrefix(name, "Update") || strings.HasSuffix(name, "InPlace") {
+		return true
+	}
+	if fn.Doc == nil {
+		return false
+	}
+	doc := strings.ToLower(fn.Doc.Text())
+	for _, keyword := range keywords {
+		if strings.Contains(doc, strings.ToLower(keyword)) {
+			return true
+		}
+	}
+	return false
+}
+
+// referenceKind tells whether a parameter type is a pointer, slice or map.
+func referenceKind(expr ast.Expr) string {
+	switch t := expr.(type) {
+	case *ast.StarExpr:
+		return "pointer"
+	case *ast.ArrayType:
+		if t.Len == nil {
+			return "slice"
+		}
+	case *ast.MapType:
+		return "map"
+	}
+	return ""
+}
+
+// findMutation returns the first statement of body mutating what the
+// parameter refers to.
+func findMutation(body *ast.BlockStmt, name, kind string) ast.Node {
+	var mutation ast.Node
+	ast.Inspect(body, func(n ast.Node) bool {
+		if mutation != nil {
+			return false
+		}
+		switch stmt := n.(type) {
+		case *ast.AssignStmt:
+			if stmt.Tok == token.DEFINE {
+				return true
+			}
+			for i, lhs := range stmt.Lhs {
+				if writesThrough(lhs, name) || (kind == "slice" && i < len(stmt.Rhs) && isAppendBack(lhs, stmt.Rhs[i], name)) {
+					mutation = stmt
+				}
+			}
+		case *ast.IncDecStmt:
+			if writesThrough(stmt.X, name) {
+				mutation = stmt
+			}
+		case *ast.CallExpr:
+			fun, ok := stmt.Fun.(*ast.Ident)
+			if ok && (fun.Name == "delete" || fun.Name == "copy" || fun.Name == "clear") && len(stmt.Args) > 0 && isIdent(stmt.Args[0], name) {
+				mutation = stmt
+			}
+		}
+		return mutation == nil
+	})
+	return mutation
+}
+
+// writesThrough reports whether an assignment target reaches the memory the
+// parameter refers to: `*p`, `p.f`, `s[i]` or `m[k].f`, but not `p` itself.
+func writesThrough(lhs ast.Expr, name string) bool {
+	through := false
+	for {
+		switch e := lhs.(type) {
+		case *ast.Ident:
+			return through && e.Name == name
+		case *ast.StarExpr:
+			lhs = e.X
+		case *ast.SelectorExpr:
+			lhs = e.X
+		case *ast.IndexExpr:
+			lhs = e.X
+		case *ast.ParenExpr:
+			lhs = e.X
+			continue
+		default:
+			return false
+		}
+		through = true
+	}
+}
+
+// isAppendBack matches `s = append(s, ...)`.
+func isAppendBack(lhs, rhs ast.Expr, name string) bool {
+	call, ok := rhs.(*ast.CallExpr)
+	if !ok || !isIdent(lhs, name) || len(call.Args) == 0 || !isIdent(call.Args[0], name) {
+		return false
+	}
+	return isIdent(call.Fun, "append")
+}
+
+func isIdent(expr ast.Expr, name string) bool {
+	ident, ok := expr.(*ast.Ident)
+	return ok && ident.Name == name
+}
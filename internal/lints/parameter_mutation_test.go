@@ -0,0 +1,173 @@
+package lints
+
+import (
+	"go/parser"
+	"go/token"
+	"testing"
+
+	tt "github.com/gnolang/tlin/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetectParameterMutation(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name     string
+		code     string
+		messages []string
+		lines    []int
+	}{
+		{
+			name: "writes through a pointer",
+			code: `package foo
+
+type Account struct{ Balance int }
+
+func Withdraw(a *Account, amount int) {
+	a.Balance -= amount
+}
+
+func Reset(n *int) {
+	*n = 0
+}
+`,
+			messages: []string{
+				"exported function Withdraw mutates its pointer parameter a without documenting it",
+				"exported function Reset mutates its pointer parameter n without documenting it",
+			},
+			lines: []int{6, 10},
+		},
+		{
+			name: "slice and map elements",
+			code: `package foo
+
+func Normalize(values []int) {
+	for i := range values {
+		values[i] /= 2
+	}
+}
+
+func Forget(cache map[string]int, key string) {
+	delete(cache, key)
+}
+
+func Extend(items []string) []string {
+	items = append(items, "tail")
+	return items
+}
+`,
+			messages: []string{
+				"exported function Normalize mutates its slice parameter values without documenting it",
+				"exported function Forget mutates its map parameter cache without documenting it",
+				"exported function Extend mutates its slice parameter items without documenting it",
+			},
+			lines: []int{5, 10, 14},
+		},
+		{
+			name: "documented or announced mutations",
+			code: `package foo
+
+// Sort sorts values in place.
+func Sort(values []int) {
+	values[0] = 1
+}
+
+// Scale modifies every element of values.
+func Scale(values []int) {
+	values[0] *= 2
+}
+
+func SetName(p *Person) {
+	p.Name = "x"
+}
+
+func UpdateAll(m map[string]int) {
+	m["a"] = 1
+}
+
+func ReverseInPlace(s []int) {
+	s[0] = s[1]
+}
+
+type Person struct{ Name string }
+`,
+		},
+		{
+			name: "reads, reassignments and unexported functions",
+			code: `package foo
+
+func Sum(values []int) int {
+	total := 0
+	for _, v := range values {
+		total += v
+	}
+	return total
+}
+
+func Replace(p *int) {
+	p = new(int)
+	_ = p
+}
+
+func Copy(values []int) []int {
+	out := make([]int, len(values))
+	copy(out, values)
+	return out
+}
+
+func clear2(values []int) {
+	values[0] = 0
+}
+`,
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			fset := token.NewFileSet()
+			node, err := parser.ParseFile(fset, "test.gno", tc.code, parser.ParseComments)
+			require.NoError(t, err)
+
+			issues, err := DetectParameterMutation("test.gno", node, fset, tt.SeverityInfo, DefaultMutationKeywords)
+			require.NoError(t, err)
+
+			var messages []string
+			var lines []int
+			for _, issue := range issues {
+				assert.Equal(t, "parameter-mutation", issue.Rule)
+				require.Len(t, issue.Related, 1)
+				messages = append(messages, issue.Message)
+				lines = append(lines, issue.Start.Line)
+			}
+			assert.Equal(t, tc.messages, messages)
+			assert.Equal(t, tc.lines, lines)
+		})
+	}
+}
+
+func TestDetectParameterMutationKeywords(t *testing.T) {
+	t.Parallel()
+	code := `package foo
+
+// Fill overwrites dst with v.
+func Fill(dst []int, v int) {
+	for i := range dst {
+		dst[i] = v
+	}
+}
+`
+	fset := token.NewFileSet()
+	node, err := parser.ParseFile(fset, "test.gno", code, parser.ParseComments)
+	require.NoError(t, err)
+
+	issues, err := DetectParameterMutation("test.gno", node, fset, tt.SeverityInfo, DefaultMutationKeywords)
+	require.NoError(t, err)
+	assert.Len(t, issues, 1)
+
+	issues, err = DetectParameterMutation("test.gno", node, fset, tt.SeverityInfo, []string{"Overwrites"})
+	require.NoError(t, err)
+	assert.Empty(t, issues)
+}
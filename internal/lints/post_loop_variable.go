@@ -0,0 +1,255 @@
+package lints
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+
+	tt "github.com/gnolang/tlin/internal/types"
+)
+
+const postLoopVariableConfidence = 0.5
+
+// DetectPostLoopVariableUse reports variables assigned by a loop header, such
+// as `i` in `for i = 0; i < n; i++` or `v` in `for _, v = range xs`, that are
+// read after the loop, relying on the value left by the last iteration.
+//
+// Loops breaking out from an if whose condition mentions the variable are
+// searching for an element, and the variable then tells which one was found,
+// so they are not reported. Variables assigned again after the loop before
+// being read are fine too.
+func DetectPostLoopVariableUse(filename string, node *ast.File, fset *token.FileSet, severity tt.Severity) ([]tt.Issue, error) {
+	var issues []tt.Issue
+	ast.Inspect(node, func(n ast.Node) bool {
+		var list []ast.Stmt
+		switch stmt := n.(type) {
+		case *ast.BlockStmt:
+			list = stmt.List
+		case *ast.CaseClause:
+			list = stmt.Body
+		case *ast.CommClause:
+			list = stmt.Body
+		}
+
+		for i, stmt := range list {
+			loop, label := stmt, ""
+			if labeled, ok := stmt.(*ast.LabeledStmt); ok {
+				loop, label = labeled.Stmt, labeled.Label.Name
+			}
+
+			var body *ast.BlockStmt
+			var names []string
+			switch l := loop.(type) {
+			case *ast.ForStmt:
+				body, names = l.Body, forLoopVariables(l)
+			case *ast.RangeStmt:
+				body, names = l.Body, rangeLoopVariables(l)
+			}
+			if len(names) == 0 {
+				continue
+			}
+
+			breaks := loopBreaks(body, label, false)
+			for _, name := range names {
+				if searchesWith(body, breaks, name) {
+					continue
+				}
+				read := readAfter(list[i+1:], name)
+				if read == nil {
+					continue
+				}
+				issues = append(issues, tt.Issue{
+					Rule:       "post-loop-variable",
+					Filename:   filename,
+					Start:      fset.Position(read.Pos()),
+					End:        fset.Position(read.End()),
+					Message:    fmt.Sprintf("%s is read after the loop, where it holds the value left by the last iteration", name),
+					Note:       "this is a frequent off-by-one source, compute the value explicitly or keep it in a dedicated variable",
+					Confidence: postLoopVariableConfidence,
+					Severity:   severity,
+					Related:    []tt.Location{{Position: fset.Position(loop.Pos()), Label: "loop assigning " + name}},
+				})
+			}
+		}
+		return true
+	})
+	return issues, nil
+}
+
+// forLoopVariables returns the variables declared outside of the loop that
+// its init or post statement assigns.
+func forLoopVariables(loop *ast.ForStmt) []string {
+	var names []string
+	add := func(expr ast.Expr) {
+		ident, ok := expr.(*ast.Ident)
+		if !ok || ident.Name == "_" {
+			return
+		}
+		for _, name := range names {
+			if name == ident.Name {
+				return
+			}
+		}
+		names = append(names, ident.Name)
+	}
+
+	if init, ok := loop.Init.(*ast.AssignStmt); ok {
+		if init.Tok == token.DEFINE {
+			// scoped to the loop
+			return nil
+		}
+		for _, lhs := range init.Lhs {
+			add(lhs)
+		}
+	}
+	switch post := loop.Post.(type) {
+	case *ast.IncDecStmt:
+		add(post.X)
+	case *ast.AssignStmt:
+		for _, lhs := range post.Lhs {
+			add(lhs)
+		}
+	}
+	return names
+}
+
+// rangeLoopVariables returns the key and value of a range loop assigning
+// existing variables.
+func rangeLoopVariables(loop *ast.RangeStmt) []string {
+	if loop.Tok != token.ASSIGN {
+		return nil
+	}
+	var names []string
+	for _, expr := range []ast.Expr{loop.Key, loop.Value} {
+		if ident, ok := expr.(*ast.Ident); ok && ident.Name != "_" {
+			names = append(names, ident.Name)
+		}
+	}
+	return names
+}
+
+// loopBreaks returns the break statements of node leaving the loop labeled
+// label. nested tells whether node is inside a statement that unlabeled
+// breaks would leave instead.
+func loopBreaks(node ast.Node, label string, nested bool) []*ast.BranchStmt {
+	var breaks []*ast.BranchStmt
+	ast.Inspect(node, func(n ast.Node) bool {
+		if n == node {
+			return true
+		}
+		switch stmt := n.(type) {
+		case *ast.FuncLit:
+			return false
+		case *ast.ForStmt, *ast.RangeStmt, *ast.SwitchStmt, *ast.TypeSwitchStmt, *ast.SelectStmt:
+			breaks = append(breaks, loopBreaks(stmt, label, true)...)
+			return false
+		case *ast.BranchStmt:
+			if stmt.Tok != token.BREAK {
+				return true
+			}
+			if (stmt.Label == nil && !nested) || (stmt.Label != nil && stmt.Label.Name == label) {
+				breaks = append(breaks, stmt)
+			}
+		}
+		return true
+	})
+	return breaks
+}
+
+// searchesWith reports whether one of breaks sits in an if statement of body
+// whose condition mentions name, as in a search for a found index.
+func searchesWith(body *ast.BlockStmt, breaks []*ast.BranchStmt, name string) bool {
+	found := false
+	ast.Inspect(body, func(n ast.Node) bool {
+		if _, ok := n.(*ast.FuncLit); ok || found {
+			return false
+		}
+		ifStmt, ok := n.(*ast.IfStmt)
+		if !ok || !mentions(ifStmt.Cond, name) {
+			return true
+		}
+		for _, b := range breaks {
+			if b.Pos() >= ifStmt.Pos() && b.End() <= ifStmt.End() {
+				found = true
+			}
+		}
+		return !found
+	})
+	return found
+}
+
+// readAfter returns the first read of name in stmts, or nil when name is
+// assigned again before being read.
+func readAfter(stmts []ast.Stmt, name string) *ast.Ident {
+	for _, stmt := range stmts {
+		var read *ast.Ident
+		written := false
+		ast.Inspect(stmt, func(n ast.Node) bool {
+			if read != nil || written {
+				return false
+			}
+			switch node := n.(type) {
+			case *ast.AssignStmt:
+				if node.Tok != token.ASSIGN && node.Tok != token.DEFINE {
+					return true
+				}
+				for _, rhs := range node.Rhs {
+					if read = firstMention(rhs, name); read != nil {
+						return false
+					}
+				}
+				for _, lhs := range node.Lhs {
+					if isIdent(lhs, name) {
+						written = true
+						return false
+					}
+				}
+			case *ast.RangeStmt:
+				if read = firstMention(node.X, name); read != nil {
+					return false
+				}
+				if isIdent(node.Key, name) || isIdent(node.Value, name) {
+					written = true
+					return false
+				}
+			case *ast.SelectorExpr:
+				read = firstMention(node.X, name)
+				return false
+			case *ast.Ident:
+				if node.Name == name {
+					read = node
+				}
+			}
+			return true
+		})
+		if read != nil {
+			return read
+		}
+		if written {
+			return nil
+		}
+	}
+	return nil
+}
+
+// firstMention returns the first identifier named name in node, field
+// selectors aside.
+func firstMention(node ast.Node, name string) *ast.Ident {
+	var found *ast.Ident
+	ast.Inspect(node, func(n ast.Node) bool {
+		if found != nil {
+			return false
+		}
+		switch node := n.(type) {
+		case *ast.SelectorExpr:
+			found = firstMention(node.X, name)
+			return false
+		case *ast.Ident:
+			if node.Name == name {
+				found = node
+			}
+		}
+		return true
+	})
+	return found
+}
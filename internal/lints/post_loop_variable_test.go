@@ -0,0 +1,173 @@
+package lints
+
+import (
+	"go/parser"
+	"go/token"
+	"testing"
+
+	tt "github.com/gnolang/tlin/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetectPostLoopVariableUse(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name  string
+		code  string
+		reads []int // lines of the reported reads
+		loops []int // lines of the related loop headers
+	}{
+		{
+			name: "counter read after the loop",
+			code: `package foo
+
+func last(arr []int, n int) int {
+	var i int
+	for i = 0; i < n; i++ {
+		arr[i] = 0
+	}
+	return arr[i]
+}
+`,
+			reads: []int{8},
+			loops: []int{5},
+		},
+		{
+			name: "range assigning existing variables",
+			code: `package foo
+
+func tail(items []string) string {
+	var k int
+	var v string
+	for k, v = range items {
+		_ = k
+	}
+	if v == "" {
+		return "empty"
+	}
+	return v + string(rune(k))
+}
+`,
+			reads: []int{12, 9},
+			loops: []int{6, 6},
+		},
+		{
+			name: "post statement only",
+			code: `package foo
+
+func count(s string) int {
+	n := 0
+	for ; n < len(s) && s[n] == ' '; n++ {
+	}
+	switch {
+	case true:
+		return n
+	}
+	return 0
+}
+`,
+			reads: []int{9},
+			loops: []int{5},
+		},
+		{
+			name: "searching for an index",
+			code: `package foo
+
+func index(arr []int, x int) int {
+	i := 0
+	for i = 0; i < len(arr); i++ {
+		if arr[i] == x {
+			break
+		}
+	}
+	return i
+}
+
+func labeled(grid [][]int, x int) int {
+	var i int
+outer:
+	for i = 0; i < len(grid); i++ {
+		for _, v := range grid[i] {
+			if v == x && i >= 0 {
+				break outer
+			}
+		}
+	}
+	return i
+}
+`,
+		},
+		{
+			name: "unrelated breaks still report",
+			code: `package foo
+
+func scan(arr []int, stop bool) int {
+	var i int
+	for i = 0; i < len(arr); i++ {
+		switch {
+		case arr[i] == 0:
+			break
+		}
+		if stop {
+			break
+		}
+	}
+	return i
+}
+`,
+			reads: []int{14},
+			loops: []int{5},
+		},
+		{
+			name: "scoped or reassigned variables",
+			code: `package foo
+
+type T struct{ i int }
+
+func f(arr []int, t T) int {
+	for i := 0; i < len(arr); i++ {
+		arr[i] = 0
+	}
+
+	var j int
+	for j = 0; j < 3; j++ {
+	}
+	j = len(arr)
+	_ = t.i
+
+	var k int
+	for k = 0; k < 3; k++ {
+	}
+	for k = 0; k < 5; k++ {
+	}
+	return j
+}
+`,
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			fset := token.NewFileSet()
+			node, err := parser.ParseFile(fset, "test.gno", tc.code, 0)
+			require.NoError(t, err)
+
+			issues, err := DetectPostLoopVariableUse("test.gno", node, fset, tt.SeverityWarning)
+			require.NoError(t, err)
+
+			var reads, loops []int
+			for _, issue := range issues {
+				assert.Equal(t, "post-loop-variable", issue.Rule)
+				assert.Empty(t, issue.Suggestion)
+				require.Len(t, issue.Related, 1)
+				reads = append(reads, issue.Start.Line)
+				loops = append(loops, issue.Related[0].Position.Line)
+			}
+			assert.Equal(t, tc.reads, reads)
+			assert.Equal(t, tc.loops, loops)
+		})
+	}
+}
@@ -0,0 +1,364 @@
+package lints
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+
+	tt "github.com/gnolang/tlin/internal/types"
+)
+
+const (
+	preallocConfidence            = 0.8
+	preallocConditionalConfidence = 0.6
+	// preallocNoFixConfidence is the confidence of the issues without a fix,
+	// below the fix threshold.
+	preallocNoFixConfidence = 0.5
+)
+
+// sliceDecl is a function local slice declared without capacity.
+type sliceDecl struct {
+	stmt     ast.Stmt
+	name     string
+	elemType ast.Expr // the []T type expression
+}
+
+// DetectSlicePrealloc detects slices declared empty (`var out []T` or
+// `out := []T{}`) and then filled by appending one element per iteration
+// of a loop whose length is known, either ranging over a collection or
+// counting up to len(x) or a constant. The suggested fix replaces the
+// declaration with one using make and the loop length as capacity. It is
+// only offered when the loop length is declared before the slice, and the
+// range operand has a length or is an integer.
+func DetectSlicePrealloc(filename string, node *ast.File, fset *token.FileSet, info *types.Info, severity tt.Severity) ([]tt.Issue, error) {
+	consts := make(map[string]bool)
+	collectConsts(node, consts)
+
+	var issues []tt.Issue
+	ast.Inspect(node, func(n ast.Node) bool {
+		block, ok := n.(*ast.BlockStmt)
+		if !ok {
+			return true
+		}
+
+		// pending declarations, in source order
+		var decls []*sliceDecl
+		for _, stmt := range block.List {
+			if decl := emptySliceDecl(stmt); decl != nil {
+				decls = append(decls, decl)
+				continue
+			}
+
+			bound, body, known := loopBound(stmt, consts, info)
+			if bound == nil {
+				continue
+			}
+			remaining := decls[:0]
+			for _, decl := range decls {
+				appendCall, conditional, ok := singleAppend(body, decl.name)
+				if !ok {
+					remaining = append(remaining, decl)
+					continue
+				}
+				fixable := known && declaredBefore(bound, decl.stmt.Pos(), info)
+				issues = append(issues, preallocIssue(filename, fset, severity, decl, bound, appendCall, conditional, fixable))
+			}
+			decls = remaining
+		}
+		return true
+	})
+
+	return issues, nil
+}
+
+func preallocIssue(
+	filename string,
+	fset *token.FileSet,
+	severity tt.Severity,
+	decl *sliceDecl,
+	bound ast.Expr,
+	appendCall *ast.CallExpr,
+	conditional bool,
+	fixable bool,
+) tt.Issue {
+	capacity := types.ExprString(bound)
+	msg := fmt.Sprintf("slice %s is appended to in a loop of known length, preallocate it with a capacity of %s", decl.name, capacity)
+	confidence := preallocConfidence
+	if conditional {
+		msg += " (an upper bound, as the append is conditional)"
+		confidence = preallocConditionalConfidence
+	}
+
+	issue := tt.Issue{
+		Rule:       "slice-prealloc",
+		Filename:   filename,
+		Start:      fset.Position(decl.stmt.Pos()),
+		End:        fset.Position(decl.stmt.End()),
+		Message:    msg,
+		Confidence: preallocNoFixConfidence,
+		Severity:   severity,
+		Related: []tt.Location{
+			{Position: fset.Position(appendCall.Pos()), Label: "appended here"},
+		},
+	}
+	if !fixable {
+		return issue
+	}
+
+	suggestion := fmt.Sprintf("%s := make(%s, 0, %s)", decl.name, types.ExprString(decl.elemType), capacity)
+	issue.Suggestion = suggestion
+	// only the declaration is replaced, not the rest of its line
+	issue.SuggestedFix = []tt.TextEdit{{
+		Start:   fset.Position(decl.stmt.Pos()),
+		End:     fset.Position(decl.stmt.End()),
+		NewText: suggestion,
+	}}
+	issue.Confidence = confidence
+	return issue
+}
+
+// emptySliceDecl matches `var out []T`, `var out = []T{}` and `out := []T{}`.
+func emptySliceDecl(stmt ast.Stmt) *sliceDecl {
+	switch s := stmt.(type) {
+	case *ast.DeclStmt:
+		gen, ok := s.Decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.VAR || len(gen.Specs) != 1 {
+			return nil
+		}
+		spec := gen.Specs[0].(*ast.ValueSpec)
+		if len(spec.Names) != 1 {
+			return nil
+		}
+		switch {
+		case len(spec.Values) == 0 && isSliceType(spec.Type):
+			return &sliceDecl{stmt: stmt, name: spec.Names[0].Name, elemType: spec.Type}
+		case len(spec.Values) == 1 && spec.Type == nil:
+			if typ := emptySliceLiteral(spec.Values[0]); typ != nil {
+				return &sliceDecl{stmt: stmt, name: spec.Names[0].Name, elemType: typ}
+			}
+		}
+	case *ast.AssignStmt:
+		if s.Tok != token.DEFINE || len(s.Lhs) != 1 || len(s.Rhs) != 1 {
+			return nil
+		}
+		ident, ok := s.Lhs[0].(*ast.Ident)
+		if !ok {
+			return nil
+		}
+		if typ := emptySliceLiteral(s.Rhs[0]); typ != nil {
+			return &sliceDecl{stmt: stmt, name: ident.Name, elemType: typ}
+		}
+	}
+	return nil
+}
+
+func emptySliceLiteral(expr ast.Expr) ast.Expr {
+	lit, ok := expr.(*ast.CompositeLit)
+	if !ok || len(lit.Elts) != 0 || !isSliceType(lit.Type) {
+		return nil
+	}
+	return lit.Type
+}
+
+func isSliceType(expr ast.Expr) bool {
+	arr, ok := expr.(*ast.ArrayType)
+	return ok && arr.Len == nil
+}
+
+// loopBound returns the number of iterations of a loop when it can be derived
+// from the loop header, along with the loop body. known is false when the
+// types don't tell whether the range operand has a length.
+func loopBound(stmt ast.Stmt, consts map[string]bool, info *types.Info) (bound ast.Expr, body *ast.BlockStmt, known bool) {
+	switch loop := stmt.(type) {
+	case *ast.RangeStmt:
+		if lit, ok := loop.X.(*ast.BasicLit); ok && lit.Kind == token.INT {
+			return lit, loop.Body, true
+		}
+		switch loop.X.(type) {
+		case *ast.Ident, *ast.SelectorExpr, *ast.IndexExpr:
+			bound, known := rangeLength(loop.X, info)
+			return bound, loop.Body, known
+		}
+	case *ast.ForStmt:
+		counter := countingFromZero(loop.Init)
+		cond, ok := loop.Cond.(*ast.BinaryExpr)
+		if counter == "" || !ok || cond.Op != token.LSS || !isIncrement(loop.Post, counter) {
+			return nil, nil, false
+		}
+		if x, ok := cond.X.(*ast.Ident); !ok || x.Name != counter {
+			return nil, nil, false
+		}
+		if isLenCall(cond.Y) || isConstantBound(cond.Y, consts) {
+			return cond.Y, loop.Body, true
+		}
+	}
+	return nil, nil, false
+}
+
+// rangeLength returns the number of iterations of a range over x: len(x)
+// for a slice, an array, a map or a string, x itself for an integer.
+func rangeLength(x ast.Expr, info *types.Info) (ast.Expr, bool) {
+	length := &ast.CallExpr{Fun: ast.NewIdent("len"), Args: []ast.Expr{x}}
+	if info == nil {
+		return length, false
+	}
+	typ := info.TypeOf(x)
+	if typ == nil {
+		return length, false
+	}
+	switch t := typ.Underlying().(type) {
+	case *types.Slice, *types.Array, *types.Map:
+		return length, true
+	case *types.Basic:
+		switch {
+		case t.Info()&types.IsString != 0:
+			return length, true
+		case t.Info()&types.IsInteger != 0:
+			return x, true
+		}
+	}
+	return length, false
+}
+
+// declaredBefore reports whether the local variables the bound refers to are
+// declared before pos, so that the bound can move up to the declaration of
+// the slice.
+func declaredBefore(bound ast.Expr, pos token.Pos, info *types.Info) bool {
+	if info == nil {
+		return false
+	}
+	ok := true
+	ast.Inspect(bound, func(n ast.Node) bool {
+		ident, isIdent := n.(*ast.Ident)
+		if !ok || !isIdent {
+			return ok
+		}
+		obj := info.Uses[ident]
+		if obj == nil {
+			// the len of rangeLength is not in the source
+			ok = !ident.Pos().IsValid()
+			return ok
+		}
+		// fields, package level and universe objects are in scope anywhere
+		scope := obj.Parent()
+		if scope == nil || scope == types.Universe || (obj.Pkg() != nil && scope == obj.Pkg().Scope()) {
+			return true
+		}
+		ok = obj.Pos() < pos
+		return ok
+	})
+	return ok
+}
+
+// countingFromZero returns the loop counter of `i := 0`.
+func countingFromZero(init ast.Stmt) string {
+	assign, ok := init.(*ast.AssignStmt)
+	if !ok || assign.Tok != token.DEFINE || len(assign.Lhs) != 1 || len(assign.Rhs) != 1 {
+		return ""
+	}
+	ident, ok := assign.Lhs[0].(*ast.Ident)
+	if !ok || !isZeroLiteral(assign.Rhs[0]) {
+		return ""
+	}
+	return ident.Name
+}
+
+func isIncrement(post ast.Stmt, counter string) bool {
+	inc, ok := post.(*ast.IncDecStmt)
+	if !ok || inc.Tok != token.INC {
+		return false
+	}
+	ident, ok := inc.X.(*ast.Ident)
+	return ok && ident.Name == counter
+}
+
+func isLenCall(expr ast.Expr) bool {
+	call, ok := expr.(*ast.CallExpr)
+	if !ok || len(call.Args) != 1 {
+		return false
+	}
+	ident, ok := call.Fun.(*ast.Ident)
+	return ok && ident.Name == "len"
+}
+
+func isConstantBound(expr ast.Expr, consts map[string]bool) bool {
+	switch e := expr.(type) {
+	case *ast.BasicLit:
+		return e.Kind == token.INT
+	case *ast.Ident:
+		return consts[e.Name]
+	}
+	return false
+}
+
+// singleAppend finds the only `name = append(name, x)` of the loop body.
+// Appends inside nested loops or function literals, appends of several
+// elements, or any other assignment to the slice disqualify the loop.
+func singleAppend(body *ast.BlockStmt, name string) (call *ast.CallExpr, conditional, ok bool) {
+	valid := true
+	var walk func(n ast.Node, depth int)
+	walk = func(n ast.Node, depth int) {
+		ast.Inspect(n, func(node ast.Node) bool {
+			if !valid || node == nil || node == n {
+				return valid
+			}
+			switch stmt := node.(type) {
+			case *ast.ForStmt, *ast.RangeStmt, *ast.FuncLit:
+				if assignsTo(stmt, name) {
+					valid = false
+				}
+				return false
+			case *ast.IfStmt, *ast.SwitchStmt, *ast.TypeSwitchStmt, *ast.SelectStmt:
+				walk(stmt, depth+1)
+				return false
+			case *ast.AssignStmt:
+				if !assignsTo(stmt, name) {
+					return true
+				}
+				c, isAppend := appendOf(stmt, name)
+				if !isAppend || call != nil {
+					valid = false
+					return false
+				}
+				call, conditional = c, depth > 0
+			}
+			return true
+		})
+	}
+	walk(body, 0)
+
+	return call, conditional, valid && call != nil
+}
+
+func assignsTo(node ast.Node, name string) bool {
+	found := false
+	ast.Inspect(node, func(n ast.Node) bool {
+		if assign, ok := n.(*ast.AssignStmt); ok {
+			for _, lhs := range assign.Lhs {
+				if ident, ok := lhs.(*ast.Ident); ok && ident.Name == name {
+					found = true
+				}
+			}
+		}
+		return !found
+	})
+	return found
+}
+
+// appendOf matches `name = append(name, x)` with a single element.
+func appendOf(assign *ast.AssignStmt, name string) (*ast.CallExpr, bool) {
+	if assign.Tok != token.ASSIGN || len(assign.Lhs) != 1 || len(assign.Rhs) != 1 {
+		return nil, false
+	}
+	call, ok := assign.Rhs[0].(*ast.CallExpr)
+	if !ok || len(call.Args) != 2 || call.Ellipsis.IsValid() {
+		return nil, false
+	}
+	fun, ok := call.Fun.(*ast.Ident)
+	if !ok || fun.Name != "append" {
+		return nil, false
+	}
+	dst, ok := call.Args[0].(*ast.Ident)
+	return call, ok && dst.Name == name
+}
@@ -0,0 +1,266 @@
+package lints
+
+import (
+	"go/parser"
+	"go/token"
+	"strings"
+	"testing"
+
+	tt "github.com/gnolang/tlin/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetectSlicePrealloc(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name string
+		code string
+		// an empty suggestion expects the issue without a fix
+		suggestions []string
+		conditional []bool
+	}{
+		{
+			name: "range over slice",
+			code: `
+package foo
+
+func names(users []User) []string {
+	var out []string
+	for _, u := range users {
+		out = append(out, u.Name)
+	}
+	return out
+}`,
+			suggestions: []string{"out := make([]string, 0, len(users))"},
+			conditional: []bool{false},
+		},
+		{
+			name: "empty literal and len bound",
+			code: `
+package foo
+
+func double(in []int) []int {
+	out := []int{}
+	for i := 0; i < len(in); i++ {
+		out = append(out, in[i]*2)
+	}
+	return out
+}`,
+			suggestions: []string{"out := make([]int, 0, len(in))"},
+			conditional: []bool{false},
+		},
+		{
+			name: "constant bound",
+			code: `
+package foo
+
+const size = 16
+
+func squares() []int {
+	var out = []int{}
+	for i := 0; i < size; i++ {
+		out = append(out, i*i)
+	}
+	return out
+}`,
+			suggestions: []string{"out := make([]int, 0, size)"},
+			conditional: []bool{false},
+		},
+		{
+			name: "conditional append is an upper bound",
+			code: `
+package foo
+
+func positives(in []int) []int {
+	var out []int
+	for _, v := range in {
+		if v > 0 {
+			out = append(out, v)
+		}
+	}
+	return out
+}`,
+			suggestions: []string{"out := make([]int, 0, len(in))"},
+			conditional: []bool{true},
+		},
+		{
+			name: "range over an integer",
+			code: `
+package foo
+
+func squares(n int) []int {
+	var out []int
+	for i := range n {
+		out = append(out, i*i)
+	}
+	return out
+}`,
+			suggestions: []string{"out := make([]int, 0, n)"},
+			conditional: []bool{false},
+		},
+		{
+			name: "bound declared after the slice",
+			code: `
+package foo
+
+func load() []string { return nil }
+
+func names() []string {
+	var out []string
+	var sizes []int
+	users := load()
+	for _, u := range users {
+		out = append(out, u)
+	}
+	for i := 0; i < len(users); i++ {
+		sizes = append(sizes, len(users[i]))
+	}
+	return append(out, string(rune(len(sizes))))
+}`,
+			suggestions: []string{"", ""},
+			conditional: []bool{false, false},
+		},
+		{
+			name: "range over a channel",
+			code: `
+package foo
+
+func drain(ch chan int) []int {
+	var out []int
+	for v := range ch {
+		out = append(out, v)
+	}
+	return out
+}`,
+			suggestions: []string{""},
+			conditional: []bool{false},
+		},
+		{
+			name: "unknown bound",
+			code: `
+package foo
+
+func read(next func() (int, bool)) []int {
+	var out []int
+	for {
+		v, ok := next()
+		if !ok {
+			break
+		}
+		out = append(out, v)
+	}
+	for i := 0; i < limit(); i++ {
+		out = append(out, i)
+	}
+	return out
+}`,
+		},
+		{
+			name: "several appends or spread append",
+			code: `
+package foo
+
+func flatten(in [][]int) []int {
+	var out []int
+	for _, v := range in {
+		out = append(out, v...)
+	}
+	var pairs []int
+	for _, v := range in {
+		pairs = append(pairs, v[0])
+		pairs = append(pairs, v[1])
+	}
+	return append(out, pairs...)
+}`,
+		},
+		{
+			name: "append in nested loop",
+			code: `
+package foo
+
+func flatten(in [][]int) []int {
+	var out []int
+	for _, row := range in {
+		for _, v := range row {
+			out = append(out, v)
+		}
+	}
+	return out
+}`,
+		},
+		{
+			name: "already allocated",
+			code: `
+package foo
+
+func copyAll(in []int) []int {
+	out := make([]int, 0, len(in))
+	for _, v := range in {
+		out = append(out, v)
+	}
+	return out
+}`,
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			fset := token.NewFileSet()
+			node, err := parser.ParseFile(fset, "test.gno", tc.code, 0)
+			require.NoError(t, err)
+
+			pkg := NewPackage(fset, &PackageFile{Filename: "test.gno", File: node})
+			issues, err := CheckTyped(pkg, DetectSlicePrealloc, tt.SeverityWarning)
+			require.NoError(t, err)
+			require.Len(t, issues, len(tc.suggestions))
+
+			for i, issue := range issues {
+				assert.Equal(t, "slice-prealloc", issue.Rule)
+				assert.Equal(t, tc.suggestions[i], issue.Suggestion)
+				assert.Equal(t, tc.conditional[i], strings.Contains(issue.Message, "upper bound"))
+				require.Len(t, issue.Related, 1)
+				assert.Equal(t, "appended here", issue.Related[0].Label)
+				if tc.suggestions[i] == "" {
+					// -fix must leave the declaration alone
+					assert.Empty(t, issue.SuggestedFix)
+					assert.Less(t, issue.Confidence, 0.75)
+					continue
+				}
+				require.Len(t, issue.SuggestedFix, 1)
+				edit := issue.SuggestedFix[0]
+				assert.Equal(t, tc.suggestions[i], edit.NewText)
+				assert.Equal(t, issue.Start.Offset, edit.Start.Offset)
+				assert.Equal(t, issue.End.Offset, edit.End.Offset)
+				assert.Equal(t, tc.conditional[i], issue.Confidence < preallocConfidence)
+			}
+		})
+	}
+}
+
+func TestDetectSlicePreallocFixKeepsTheLine(t *testing.T) {
+	t.Parallel()
+	code := `package foo
+
+func names(users []string) []string {
+	var out []string; var y = 1 // counts the users
+	for _, u := range users {
+		out = append(out, u)
+	}
+	return append(out, string(rune(y)))
+}
+`
+	fset := token.NewFileSet()
+	node, err := parser.ParseFile(fset, "test.gno", code, parser.ParseComments)
+	require.NoError(t, err)
+
+	pkg := NewPackage(fset, &PackageFile{Filename: "test.gno", File: node})
+	issues, err := CheckTyped(pkg, DetectSlicePrealloc, tt.SeverityWarning)
+	require.NoError(t, err)
+	require.Len(t, issues, 1)
+	edit := issues[0].SuggestedFix[0]
+	fixed := code[:edit.Start.Offset] + edit.NewText + code[edit.End.Offset:]
+	assert.Contains(t, fixed, "\tout := make([]string, 0, len(users)); var y = 1 // counts the users\n")
+}
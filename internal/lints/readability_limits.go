@@ -0,0 +1,192 @@
+package lints
+
+import (
+	"bytes"
+	"go/ast"
+	"go/token"
+	"os"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/gnolang/tlin/internal/messages"
+	tt "github.com/gnolang/tlin/internal/types"
+)
+
+var (
+	lineLengthMessage = messages.Define("readability-limits.line-length",
+		"line is {width} columns long, exceeding the maximum of {max}")
+	chainDepthMessage = messages.Define("readability-limits.chain-depth",
+		"call chain has a depth of {depth}, exceeding the maximum of {max}")
+)
+
+// ReadabilityLimits configures the sub-checks of DetectReadabilityLimits.
+type ReadabilityLimits struct {
+	CheckLineLength bool
+	MaxLineLength   int
+	// TabWidth is the number of columns a tab counts for.
+	TabWidth int
+
+	CheckChainDepth bool
+	MaxChainDepth   int
+}
+
+// DetectReadabilityLimits reports lines longer than limits.MaxLineLength
+// columns and call chains deeper than limits.MaxChainDepth links.
+//
+// Line lengths are measured on the raw source in runes, a tab counting for
+// limits.TabWidth columns. Lines made only of a string literal and lines
+// holding a URL in a comment cannot be shortened and are skipped.
+// The depth of a chain is its number of selector-call links, so
+// `q.Where(a).OrderBy(b)` has a depth of 2.
+func DetectReadabilityLimits(filename string, node *ast.File, fset *token.FileSet, severity tt.Severity, limits ReadabilityLimits) ([]tt.Issue, error) {
+	var issues []tt.Issue
+	if limits.CheckLineLength {
+		content, err := os.ReadFile(filename)
+		if err != nil {
+			return nil, err
+		}
+		issues = append(issues, detectLongLines(filename, node, fset, content, severity, limits)...)
+	}
+	if limits.CheckChainDepth {
+		issues = append(issues, detectDeepChains(filename, node, fset, severity, limits.MaxChainDepth)...)
+	}
+	return issues, nil
+}
+
+func detectLongLines(filename string, node *ast.File, fset *token.FileSet, content []byte, severity tt.Severity, limits ReadabilityLimits) []tt.Issue {
+	tokFile := fset.File(node.Pos())
+	if tokFile == nil || tokFile.Size() != len(content) {
+		// the file changed since it was parsed
+		return nil
+	}
+	strs := stringLiterals(node, fset)
+	urls := urlCommentLines(node, fset)
+
+	var issues []tt.Issue
+	offset := 0
+	for i, line := range bytes.Split(content, []byte("\n")) {
+		lineNo, start := i+1, offset
+		offset += len(line) + 1
+
+		line = bytes.TrimSuffix(line, []byte("\r"))
+		width := lineWidth(line, limits.TabWidth)
+		if width <= limits.MaxLineLength || urls[lineNo] || isStringLiteralLine(line, start, strs) {
+			continue
+		}
+
+		issue := tt.Issue{
+			Rule:     "readability-limits",
+			Filename: filename,
+			Start:    fset.Position(tokFile.Pos(start)),
+			End:      fset.Position(tokFile.Pos(start + len(line))),
+			Severity: severity,
+		}
+		lineLengthMessage.Set(&issue, messages.Args{"width": width, "max": limits.MaxLineLength})
+		issues = append(issues, issue)
+	}
+	return issues
+}
+
+// lineWidth counts the runes of a line, a tab counting for tabWidth columns.
+func lineWidth(line []byte, tabWidth int) int {
+	tabs := bytes.Count(line, []byte("\t"))
+	return utf8.RuneCount(line) - tabs + tabs*tabWidth
+}
+
+// stringLiterals returns the offset ranges of the string literals of the file.
+func stringLiterals(node *ast.File, fset *token.FileSet) [][2]int {
+	var ranges [][2]int
+	ast.Inspect(node, func(n ast.Node) bool {
+		if lit, ok := n.(*ast.BasicLit); ok && lit.Kind == token.STRING {
+			ranges = append(ranges, [2]int{fset.Position(lit.Pos()).Offset, fset.Position(lit.End()).Offset})
+		}
+		return true
+	})
+	return ranges
+}
+
+// isStringLiteralLine reports whether the line, once trimmed of blanks and of a
+// trailing comma, lies within a single string literal.
+func isStringLiteralLine(line []byte, start int, strs [][2]int) bool {
+	trimmed := bytes.TrimLeft(line, " \t")
+	first := start + len(line) - len(trimmed)
+	last := start + len(bytes.TrimRight(bytes.TrimRight(line, " \t"), ","))
+	for _, r := range strs {
+		if r[0] <= first && last <= r[1] {
+			return true
+		}
+	}
+	return false
+}
+
+// urlCommentLines returns the lines holding a comment with a URL.
+func urlCommentLines(node *ast.File, fset *token.FileSet) map[int]bool {
+	lines := make(map[int]bool)
+	for _, group := range node.Comments {
+		for _, comment := range group.List {
+			if !strings.Contains(comment.Text, "://") {
+				continue
+			}
+			// block comments may span several lines
+			for line := fset.Position(comment.Pos()).Line; line <= fset.Position(comment.End()).Line; line++ {
+				if strings.Contains(commentLine(comment, fset, line), "://") {
+					lines[line] = true
+				}
+			}
+		}
+	}
+	return lines
+}
+
+func commentLine(comment *ast.Comment, fset *token.FileSet, line int) string {
+	lines := strings.Split(comment.Text, "\n")
+	index := line - fset.Position(comment.Pos()).Line
+	if index < 0 || index >= len(lines) {
+		return ""
+	}
+	return lines[index]
+}
+
+func detectDeepChains(filename string, node *ast.File, fset *token.FileSet, severity tt.Severity, maxDepth int) []tt.Issue {
+	var issues []tt.Issue
+	inChain := make(map[*ast.CallExpr]bool)
+	ast.Inspect(node, func(n ast.Node) bool {
+		call, ok := n.(*ast.CallExpr)
+		if !ok || inChain[call] {
+			return true
+		}
+
+		depth := 0
+		for link := call; link != nil; link = chainedCall(link) {
+			inChain[link] = true
+			if _, ok := link.Fun.(*ast.SelectorExpr); ok {
+				depth++
+			}
+		}
+		if depth > maxDepth {
+			issue := tt.Issue{
+				Rule:     "readability-limits",
+				Filename: filename,
+				Start:    fset.Position(call.Pos()),
+				End:      fset.Position(call.End()),
+				Note:     "split the chain using intermediate variables",
+				Severity: severity,
+			}
+			chainDepthMessage.Set(&issue, messages.Args{"depth": depth, "max": maxDepth})
+			issues = append(issues, issue)
+		}
+		return true
+	})
+	return issues
+}
+
+// chainedCall returns the call a method is selected on, as in `x.A().B()`
+// where the B call is chained on the A call.
+func chainedCall(call *ast.CallExpr) *ast.CallExpr {
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok {
+		return nil
+	}
+	inner, _ := sel.X.(*ast.CallExpr)
+	return inner
+}
@@ -0,0 +1,120 @@
+package lints
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tt "github.com/gnolang/tlin/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetectReadabilityLimits(t *testing.T) {
+	t.Parallel()
+	long := strings.Repeat("x", 30)
+	tests := []struct {
+		name     string
+		code     string
+		limits   ReadabilityLimits
+		messages []string
+		lines    []int
+	}{
+		{
+			name: "long line with tabs",
+			code: `package foo
+
+func f() {
+	v := "` + long + `" + "` + long + `"
+	_ = v
+}
+`,
+			limits:   ReadabilityLimits{CheckLineLength: true, MaxLineLength: 70, TabWidth: 8},
+			messages: []string{"line is 80 columns long, exceeding the maximum of 70"},
+			lines:    []int{4},
+		},
+		{
+			name: "tab width is configurable",
+			code: `package foo
+
+func f() {
+		v := "` + long + `" + "` + long + `"
+	_ = v
+}
+`,
+			limits: ReadabilityLimits{CheckLineLength: true, MaxLineLength: 77, TabWidth: 1},
+		},
+		{
+			name: "runes are counted instead of bytes",
+			code: `package foo
+
+var s = "` + strings.Repeat("é", 40) + `" + "a"
+`,
+			limits: ReadabilityLimits{CheckLineLength: true, MaxLineLength: 60, TabWidth: 4},
+		},
+		{
+			name: "string literal lines and URLs in comments are skipped",
+			code: `package foo
+
+// see https://gno.land/r/demo/` + long + long + `
+var doc = []string{
+	"` + long + long + `",
+}
+
+var raw = ` + "`" + `
+` + long + long + `
+` + "`" + `
+`,
+			limits: ReadabilityLimits{CheckLineLength: true, MaxLineLength: 40, TabWidth: 4},
+		},
+		{
+			name: "deep call chain",
+			code: `package foo
+
+func f(q Query) {
+	q.Select("a").From("b").Where("c").GroupBy("d").OrderBy("e").Limit(1).Run()
+	q.Select("a").From("b").Run()
+}
+`,
+			limits:   ReadabilityLimits{CheckChainDepth: true, MaxChainDepth: 5},
+			messages: []string{"call chain has a depth of 7, exceeding the maximum of 5"},
+			lines:    []int{4},
+		},
+		{
+			name: "sub-checks are independent",
+			code: `package foo
+
+func f(q Query) {
+	q.Select("a").From("b").Where("c").GroupBy("d").OrderBy("e").Limit(1).Run()
+}
+`,
+			limits:   ReadabilityLimits{CheckLineLength: true, MaxLineLength: 40, TabWidth: 4},
+			messages: []string{"line is 79 columns long, exceeding the maximum of 40"},
+			lines:    []int{4},
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			path := filepath.Join(t.TempDir(), "test.gno")
+			require.NoError(t, os.WriteFile(path, []byte(tc.code), 0o644))
+
+			node, fset, err := ParseFile(path, nil)
+			require.NoError(t, err)
+
+			issues, err := DetectReadabilityLimits(path, node, fset, tt.SeverityInfo, tc.limits)
+			require.NoError(t, err)
+			require.Len(t, issues, len(tc.messages))
+
+			for i, issue := range issues {
+				assert.Equal(t, "readability-limits", issue.Rule)
+				assert.Equal(t, tc.messages[i], issue.Message)
+				assert.Equal(t, tc.lines[i], issue.Start.Line)
+				assert.Equal(t, tc.lines[i], issue.End.Line)
+			}
+		})
+	}
+}
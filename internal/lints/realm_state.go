@@ -0,0 +1,223 @@
+package lints
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"path/filepath"
+	"strings"
+
+	tt "github.com/gnolang/tlin/internal/types"
+)
+
+// DefaultRealmStateAllowed are the types accepted in realm state even when
+// they would otherwise be reported.
+var DefaultRealmStateAllowed = []string{"std.*"}
+
+// stateHazard is a type found in persisted state that cannot be stored
+// deterministically.
+type stateHazard struct {
+	path   string
+	node   ast.Node
+	what   string
+	reason string
+}
+
+// stateWalker walks the type of a package-level variable through the struct
+// types of the package.
+type stateWalker struct {
+	pkg      *Package
+	types    map[string]*ast.TypeSpec
+	files    map[string]*ast.File // file declaring each type
+	allowed  []string
+	maxDepth int
+	hazards  []stateHazard
+}
+
+// DetectRealmStateHazards reports package-level variables of realm packages
+// whose type holds a time.Time, a channel or a function value, either
+// directly or through the fields of the struct types of the package, up to
+// maxDepth nested types. Channels and functions cannot be persisted, and
+// wall-clock times break determinism. Qualified type names matching one of
+// allowed (path.Match patterns such as "std.*") are not inspected.
+func DetectRealmStateHazards(pkg *Package, severity tt.Severity, allowed []string, maxDepth int) ([]tt.Issue, error) {
+	if !isRealmPackage(pkg) {
+		return nil, nil
+	}
+
+	w := &stateWalker{
+		pkg:      pkg,
+		types:    make(map[string]*ast.TypeSpec),
+		files:    make(map[string]*ast.File),
+		allowed:  allowed,
+		maxDepth: maxDepth,
+	}
+	for _, file := range pkg.Files {
+		if file.IsTest() {
+			continue
+		}
+		for _, decl := range file.File.Decls {
+			gen, ok := decl.(*ast.GenDecl)
+			if !ok || gen.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range gen.Specs {
+				ts := spec.(*ast.TypeSpec)
+				w.types[ts.Name.Name] = ts
+				w.files[ts.Name.Name] = file.File
+			}
+		}
+	}
+
+	var issues []tt.Issue
+	for _, file := range pkg.Files {
+		if file.IsTest() {
+			continue
+		}
+		for _, decl := range file.File.Decls {
+			gen, ok := decl.(*ast.GenDecl)
+			if !ok || gen.Tok != token.VAR {
+				continue
+			}
+			for _, spec := range gen.Specs {
+				vs := spec.(*ast.ValueSpec)
+				for i, name := range vs.Names {
+					if name.Name == "_" {
+						continue
+					}
+					typ := vs.Type
+					if typ == nil && i < len(vs.Values) {
+						typ = valueType(vs.Values[i], file.File)
+					}
+					if typ == nil {
+						continue
+					}
+
+					w.hazards = nil
+					w.walk(typ, file.File, name.Name, 0, make(map[string]bool))
+					for _, h := range w.hazards {
+						issue := tt.Issue{
+							Rule:     "realm-state-types",
+							Filename: file.Filename,
+							Start:    pkg.Fset.Position(name.Pos()),
+							End:      pkg.Fset.Position(name.End()),
+							Message:  fmt.Sprintf("persisted variable %s holds %s at %s", name.Name, h.what, h.path),
+							Note:     h.reason,
+							Severity: severity,
+						}
+						if h.path != name.Name {
+							issue.Related = []tt.Location{{Position: pkg.Fset.Position(h.node.Pos()), Label: h.what + " declared here"}}
+						}
+						issues = append(issues, issue)
+					}
+				}
+			}
+		}
+	}
+	return issues, nil
+}
+
+func (w *stateWalker) walk(expr ast.Expr, file *ast.File, path string, depth int, visiting map[string]bool) {
+	switch t := expr.(type) {
+	case *ast.ParenExpr:
+		w.walk(t.X, file, path, depth, visiting)
+	case *ast.StarExpr:
+		w.walk(t.X, file, path, depth, visiting)
+	case *ast.ArrayType:
+		w.walk(t.Elt, file, path+"[]", depth, visiting)
+	case *ast.MapType:
+		w.walk(t.Key, file, path+"[key]", depth, visiting)
+		w.walk(t.Value, file, path+"[]", depth, visiting)
+	case *ast.IndexExpr:
+		w.walk(t.X, file, path, depth, visiting)
+		w.walk(t.Index, file, path, depth, visiting)
+	case *ast.IndexListExpr:
+		w.walk(t.X, file, path, depth, visiting)
+		for _, index := range t.Indices {
+			w.walk(index, file, path, depth, visiting)
+		}
+	case *ast.ChanType:
+		w.report(path, t, "a channel", "channels cannot be persisted in realm state")
+	case *ast.FuncType:
+		w.report(path, t, "a function value", "function values cannot be persisted meaningfully in realm state")
+	case *ast.StructType:
+		if depth > w.maxDepth {
+			return
+		}
+		for _, field := range t.Fields.List {
+			if len(field.Names) == 0 {
+				w.walk(field.Type, file, path+"."+embeddedName(field.Type), depth, visiting)
+				continue
+			}
+			for _, name := range field.Names {
+				w.walk(field.Type, file, path+"."+name.Name, depth, visiting)
+			}
+		}
+	case *ast.Ident:
+		spec := w.types[t.Name]
+		if spec == nil || visiting[t.Name] || depth >= w.maxDepth || matchesAny(w.pkg.Name+"."+t.Name, w.allowed) {
+			return
+		}
+		visiting[t.Name] = true
+		w.walk(spec.Type, w.files[t.Name], path, depth+1, visiting)
+		delete(visiting, t.Name)
+	case *ast.SelectorExpr:
+		pkgIdent, ok := t.X.(*ast.Ident)
+		if !ok {
+			return
+		}
+		qualified := pkgIdent.Name + "." + t.Sel.Name
+		if matchesAny(qualified, w.allowed) {
+			return
+		}
+		if importPath(file, pkgIdent.Name) == "time" && t.Sel.Name == "Time" {
+			w.report(path, t, "a time.Time", "wall-clock times are not deterministic, store the block height or a timestamp read from std instead")
+		}
+	}
+}
+
+func (w *stateWalker) report(path string, node ast.Node, what, reason string) {
+	w.hazards = append(w.hazards, stateHazard{path: path, node: node, what: what, reason: reason})
+}
+
+// valueType infers the type of a package-level variable without an explicit
+// type from its initializer, when the syntax tells it.
+func valueType(value ast.Expr, file *ast.File) ast.Expr {
+	switch v := value.(type) {
+	case *ast.CompositeLit:
+		return v.Type
+	case *ast.UnaryExpr:
+		if v.Op == token.AND {
+			return valueType(v.X, file)
+		}
+	case *ast.FuncLit:
+		return v.Type
+	case *ast.CallExpr:
+		if fun, ok := v.Fun.(*ast.Ident); ok && (fun.Name == "make" || fun.Name == "new") && len(v.Args) > 0 {
+			return v.Args[0]
+		}
+		sel, ok := v.Fun.(*ast.SelectorExpr)
+		if !ok {
+			return nil
+		}
+		pkgIdent, ok := sel.X.(*ast.Ident)
+		if ok && importPath(file, pkgIdent.Name) == "time" && (sel.Sel.Name == "Now" || sel.Sel.Name == "Unix" || sel.Sel.Name == "Date") {
+			return &ast.SelectorExpr{X: pkgIdent, Sel: &ast.Ident{Name: "Time", NamePos: sel.Sel.NamePos}}
+		}
+	}
+	return nil
+}
+
+// importPath returns the path of the package imported under name by file.
+func importPath(file *ast.File, name string) string {
+	for _, imp := range file.Imports {
+		if importName(imp) == name {
+			return strings.Trim(imp.Path.Value, `"`)
+		}
+	}
+	return ""
+}
+
+func isRealmPackage(pkg *Package) bool {
+	return strings.Contains("/"+filepath.ToSlash(pkg.Dir)+"/", "/r/")
+}
@@ -0,0 +1,164 @@
+package lints
+
+import (
+	"testing"
+
+	tt "github.com/gnolang/tlin/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetectRealmStateHazards(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name     string
+		files    map[string]string
+		maxDepth int
+		messages []string
+	}{
+		{
+			name: "direct hazards",
+			files: map[string]string{
+				"gno.land/r/demo/board/state.gno": `package board
+
+import "time"
+
+var (
+	createdAt = time.Now()
+	updates   chan string
+	hook      func(string)
+	onPost    = func(id int) {}
+	count     int
+)
+`,
+			},
+			maxDepth: 3,
+			messages: []string{
+				"persisted variable createdAt holds a time.Time at createdAt",
+				"persisted variable updates holds a channel at updates",
+				"persisted variable hook holds a function value at hook",
+				"persisted variable onPost holds a function value at onPost",
+			},
+		},
+		{
+			name: "fields of the package types",
+			files: map[string]string{
+				"gno.land/r/demo/board/board.gno": `package board
+
+import (
+	"std"
+	gotime "time"
+)
+
+type Post struct {
+	Author  std.Address
+	Created gotime.Time
+	Replies []*Post
+}
+
+type Board struct {
+	Posts map[string]*Post
+	Meta
+}
+
+type Meta struct {
+	Callbacks []func()
+}
+`,
+				"gno.land/r/demo/board/state.gno": `package board
+
+var boards = map[string]*Board{}
+
+var pinned *Post
+`,
+			},
+			maxDepth: 3,
+			messages: []string{
+				"persisted variable boards holds a time.Time at boards[].Posts[].Created",
+				"persisted variable boards holds a function value at boards[].Meta.Callbacks[]",
+				"persisted variable pinned holds a time.Time at pinned.Created",
+			},
+		},
+		{
+			name: "depth limit",
+			files: map[string]string{
+				"gno.land/r/demo/deep/deep.gno": `package deep
+
+import "time"
+
+type A struct{ B B }
+type B struct{ C C }
+type C struct{ At time.Time }
+
+var a A
+var b B
+`,
+			},
+			maxDepth: 2,
+			messages: []string{
+				"persisted variable b holds a time.Time at b.C.At",
+			},
+		},
+		{
+			name: "allowed types and libraries",
+			files: map[string]string{
+				"gno.land/r/demo/clock/clock.gno": `package clock
+
+import "std"
+
+var realm std.Realm
+`,
+			},
+			maxDepth: 3,
+		},
+		{
+			name: "library package",
+			files: map[string]string{
+				"gno.land/p/demo/clock/clock.gno": `package clock
+
+import "time"
+
+var epoch = time.Unix(0, 0)
+`,
+			},
+			maxDepth: 3,
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			issues, err := DetectRealmStateHazards(parsePackage(t, tc.files), tt.SeverityWarning, DefaultRealmStateAllowed, tc.maxDepth)
+			require.NoError(t, err)
+
+			var messages []string
+			for _, issue := range issues {
+				assert.Equal(t, "realm-state-types", issue.Rule)
+				messages = append(messages, issue.Message)
+			}
+			assert.Equal(t, tc.messages, messages)
+		})
+	}
+}
+
+func TestDetectRealmStateHazardsRelated(t *testing.T) {
+	t.Parallel()
+	pkg := parsePackage(t, map[string]string{
+		"gno.land/r/demo/events/events.gno": `package events
+
+type Listener struct {
+	Notify chan int
+}
+
+var listeners []Listener
+`,
+	})
+
+	issues, err := DetectRealmStateHazards(pkg, tt.SeverityWarning, DefaultRealmStateAllowed, 3)
+	require.NoError(t, err)
+	require.Len(t, issues, 1)
+	assert.Equal(t, "persisted variable listeners holds a channel at listeners[].Notify", issues[0].Message)
+	require.Len(t, issues[0].Related, 1)
+	assert.Equal(t, 4, issues[0].Related[0].Position.Line)
+}
@@ -0,0 +1,205 @@
+package lints
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+
+	tt "github.com/gnolang/tlin/internal/types"
+)
+
+type receiverMethod struct {
+	filename string
+	decl     *ast.FuncDecl
+	name     *ast.Ident // nil for unnamed receivers
+	pointer  bool
+	mutates  bool
+}
+
+// DetectReceiverInconsistency groups the methods of the package by receiver
+// type and reports the methods that deviate from the first one declared,
+// either by naming the receiver differently, or by using a value receiver
+// where the others use a pointer (or the opposite) when at least one method
+// of the type mutates its receiver.
+func DetectReceiverInconsistency(pkg *Package, severity tt.Severity) ([]tt.Issue, error) {
+	var order []string
+	methods := make(map[string][]receiverMethod)
+	for _, file := range pkg.Files {
+		for _, decl := range file.File.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok || fn.Recv == nil || len(fn.Recv.List) != 1 {
+				continue
+			}
+			typeName, pointer := receiverType(fn.Recv.List[0].Type)
+			if typeName == "" {
+				continue
+			}
+
+			m := receiverMethod{filename: file.Filename, decl: fn, pointer: pointer}
+			if names := fn.Recv.List[0].Names; len(names) == 1 && names[0].Name != "_" {
+				m.name = names[0]
+				m.mutates = mutatesReceiver(fn.Body, m.name.Name)
+			}
+			if _, seen := methods[typeName]; !seen {
+				order = append(order, typeName)
+			}
+			methods[typeName] = append(methods[typeName], m)
+		}
+	}
+
+	var issues []tt.Issue
+	for _, typeName := range order {
+		issues = append(issues, receiverNameIssues(pkg.Fset, typeName, methods[typeName], severity)...)
+		issues = append(issues, receiverKindIssues(pkg.Fset, typeName, methods[typeName], severity)...)
+	}
+	return issues, nil
+}
+
+func receiverNameIssues(fset *token.FileSet, typeName string, methods []receiverMethod, severity tt.Severity) []tt.Issue {
+	var canonical *receiverMethod
+	var issues []tt.Issue
+	for i := range methods {
+		m := &methods[i]
+		if m.name == nil {
+			continue
+		}
+		if canonical == nil {
+			canonical = m
+			continue
+		}
+		if m.name.Name == canonical.name.Name {
+			continue
+		}
+		issues = append(issues, tt.Issue{
+			Rule:     "receiver-consistency",
+			Filename: m.filename,
+			Start:    fset.Position(m.name.Pos()),
+			End:      fset.Position(m.name.End()),
+			Message: fmt.Sprintf("receiver of %s.%s is named %s, but %s is used for %s.%s",
+				typeName, m.decl.Name.Name, m.name.Name, canonical.name.Name, typeName, canonical.decl.Name.Name),
+			Severity: severity,
+			Related: []tt.Location{
+				{Position: fset.Position(canonical.name.Pos()), Label: "first receiver named here"},
+			},
+		})
+	}
+	return issues
+}
+
+func receiverKindIssues(fset *token.FileSet, typeName string, methods []receiverMethod, severity tt.Severity) []tt.Issue {
+	var mutating *receiverMethod
+	mixed := false
+	for i := range methods {
+		if methods[i].pointer != methods[0].pointer {
+			mixed = true
+		}
+		if methods[i].mutates && mutating == nil {
+			mutating = &methods[i]
+		}
+	}
+	if !mixed || mutating == nil {
+		return nil
+	}
+
+	canonical := &methods[0]
+	kind, other := receiverKind(canonical.pointer), receiverKind(!canonical.pointer)
+
+	var issues []tt.Issue
+	for i := range methods {
+		m := &methods[i]
+		if m.pointer == canonical.pointer {
+			continue
+		}
+		recv := m.decl.Recv.List[0]
+		issues = append(issues, tt.Issue{
+			Rule:     "receiver-consistency",
+			Filename: m.filename,
+			Start:    fset.Position(recv.Pos()),
+			End:      fset.Position(recv.End()),
+			Message: fmt.Sprintf("%s.%s has a %s receiver while %s.%s has a %s receiver",
+				typeName, m.decl.Name.Name, other, typeName, canonical.decl.Name.Name, kind),
+			Note:     fmt.Sprintf("%s.%s mutates its receiver, mixing receiver kinds may operate on copies", typeName, mutating.decl.Name.Name),
+			Severity: severity,
+			Related: []tt.Location{
+				{Position: fset.Position(canonical.decl.Recv.List[0].Pos()), Label: "first receiver declared here"},
+			},
+		})
+	}
+	return issues
+}
+
+func receiverKind(pointer bool) string {
+	if pointer {
+		return "pointer"
+	}
+	return "value"
+}
+
+// receiverType returns the base type name of a receiver and whether it is a pointer.
+func receiverType(expr ast.Expr) (string, bool) {
+	pointer := false
+	if star, ok := expr.(*ast.StarExpr); ok {
+		pointer = true
+		expr = star.X
+	}
+	switch t := expr.(type) {
+	case *ast.IndexExpr:
+		expr = t.X
+	case *ast.IndexListExpr:
+		expr = t.X
+	}
+	if ident, ok := expr.(*ast.Ident); ok {
+		return ident.Name, pointer
+	}
+	return "", pointer
+}
+
+// mutatesReceiver reports whether the body assigns to the value of the
+// receiver or to one of its fields.
+func mutatesReceiver(body *ast.BlockStmt, name string) bool {
+	if body == nil {
+		return false
+	}
+	mutates := false
+	ast.Inspect(body, func(n ast.Node) bool {
+		switch stmt := n.(type) {
+		case *ast.AssignStmt:
+			if stmt.Tok == token.DEFINE {
+				return true
+			}
+			for _, lhs := range stmt.Lhs {
+				mutates = mutates || isReceiverStorage(lhs, name)
+			}
+		case *ast.IncDecStmt:
+			mutates = mutates || isReceiverStorage(stmt.X, name)
+		case *ast.FuncLit:
+			// closures may shadow the receiver, they are not followed
+			return false
+		}
+		return !mutates
+	})
+	return mutates
+}
+
+// isReceiverStorage reports whether expr is `*recv` or a field selected from
+// the receiver. Reassigning the receiver variable itself has no effect outside
+// the method and is not a mutation.
+func isReceiverStorage(expr ast.Expr, name string) bool {
+	if _, ok := expr.(*ast.Ident); ok {
+		return false
+	}
+	for {
+		switch e := expr.(type) {
+		case *ast.Ident:
+			return e.Name == name
+		case *ast.StarExpr:
+			expr = e.X
+		case *ast.SelectorExpr:
+			expr = e.X
+		case *ast.ParenExpr:
+			expr = e.X
+		default:
+			return false
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package lints
+
+import (
+	"testing"
+
+	tt "github.com/gnolang/tlin/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetectReceiverInconsistency(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name     string
+		files    map[string]string
+		messages []string
+	}{
+		{
+			name: "consistent receivers",
+			files: map[string]string{
+				"a.gno": `package store
+
+type Store struct{ n int }
+
+func (s *Store) Inc()      { s.n++ }
+func (s *Store) Get() int { return s.n }
+`,
+			},
+		},
+		{
+			name: "different names across files",
+			files: map[string]string{
+				"a.gno": `package store
+
+type Store struct{ n int }
+
+func (s *Store) Get() int { return s.n }
+`,
+				"b.gno": `package store
+
+func (self *Store) Set(n int) { self.n = n }
+func (this *Store) Reset()    { this.n = 0 }
+func (*Store) Name() string   { return "store" }
+`,
+			},
+			messages: []string{
+				"receiver of Store.Set is named self, but s is used for Store.Get",
+				"receiver of Store.Reset is named this, but s is used for Store.Get",
+			},
+		},
+		{
+			name: "mixed kinds with a mutating method",
+			files: map[string]string{
+				"a.gno": `package store
+
+type Counter struct{ n int }
+
+func (c *Counter) Inc()      { c.n++ }
+func (c Counter) Value() int { return c.n }
+`,
+			},
+			messages: []string{"Counter.Value has a value receiver while Counter.Inc has a pointer receiver"},
+		},
+		{
+			name: "first method is canonical",
+			files: map[string]string{
+				"a.gno": `package store
+
+type Counter struct{ n int }
+
+func (c Counter) Value() int { return c.n }
+func (c *Counter) Inc()      { c.n++ }
+`,
+			},
+			messages: []string{"Counter.Inc has a pointer receiver while Counter.Value has a value receiver"},
+		},
+		{
+			name: "mixed kinds without mutation",
+			files: map[string]string{
+				"a.gno": `package store
+
+type Point struct{ x, y int }
+
+func (p *Point) X() int { return p.x }
+func (p Point) Y() int  { return p.y }
+func (p Point) Moved() Point {
+	p = Point{p.x + 1, p.y}
+	return p
+}
+`,
+			},
+		},
+		{
+			name: "generic receivers",
+			files: map[string]string{
+				"a.gno": `package store
+
+type List[T any] struct{ items []T }
+
+func (l *List[T]) Push(v T) { l.items = append(l.items, v) }
+func (list List[T]) Len() int { return len(list.items) }
+`,
+			},
+			messages: []string{
+				"receiver of List.Len is named list, but l is used for List.Push",
+				"List.Len has a value receiver while List.Push has a pointer receiver",
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			pkg := parsePackage(t, tc.files)
+
+			issues, err := DetectReceiverInconsistency(pkg, tt.SeverityInfo)
+			require.NoError(t, err)
+			require.Len(t, issues, len(tc.messages))
+
+			for i, issue := range issues {
+				assert.Equal(t, "receiver-consistency", issue.Rule)
+				assert.Equal(t, tc.messages[i], issue.Message)
+				require.Len(t, issue.Related, 1)
+			}
+		})
+	}
+}
@@ -0,0 +1,192 @@
+package lints
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"strings"
+
+	tt "github.com/gnolang/tlin/internal/types"
+)
+
+var (
+	// DefaultCallerParamNames are the parameter names treated as caller identities.
+	DefaultCallerParamNames = []string{"caller", "sender", "owner"}
+	// DefaultCallerFuncs are the calls deriving the caller from the realm context.
+	DefaultCallerFuncs = []string{
+		"std.GetOrigCaller",
+		"std.OrigCaller",
+		"std.PrevRealm",
+		"std.PreviousRealm",
+		"std.GetCallerAt",
+	}
+)
+
+// DetectRedundantCallerParam detects functions that receive the caller as an
+// Address parameter and also derive it from the realm context, which leaves two
+// sources of truth for the identity. A parameter matches when its name starts
+// with one of names (case insensitive), so `callerAddr` matches but `newOwner`
+// does not. funcs lists the deriving calls as `pkg.Func`.
+//
+// Assert helpers comparing the parameter with the derived caller, or passing both
+// to the same call, are not reported.
+func DetectRedundantCallerParam(filename string, node *ast.File, fset *token.FileSet, severity tt.Severity, names, funcs []string) ([]tt.Issue, error) {
+	var issues []tt.Issue
+	for _, decl := range node.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok || fn.Body == nil {
+			continue
+		}
+
+		params := callerParams(fn, names)
+		if len(params) == 0 {
+			continue
+		}
+		calls := derivationCalls(fn.Body, funcs)
+		if len(calls) == 0 {
+			continue
+		}
+
+		for _, param := range params {
+			if isCallerAssertion(fn.Body, param, funcs) {
+				continue
+			}
+
+			related := []tt.Location{{Position: fset.Position(param.Pos()), Label: "caller parameter declared here"}}
+			for _, call := range calls[1:] {
+				related = append(related, tt.Location{Position: fset.Position(call.Pos()), Label: "caller also derived here"})
+			}
+
+			issues = append(issues, tt.Issue{
+				Rule:     "redundant-caller-parameter",
+				Filename: filename,
+				Start:    fset.Position(calls[0].Pos()),
+				End:      fset.Position(calls[0].End()),
+				Message: fmt.Sprintf("function %s takes the caller as parameter %q but also derives it with %s",
+					fn.Name.Name, param.Name, callName(calls[0])),
+				Note:     "pick a single source for the caller identity",
+				Severity: severity,
+				Related:  related,
+			})
+		}
+	}
+	return issues, nil
+}
+
+// callerParams returns the Address typed parameters named like a caller.
+func callerParams(fn *ast.FuncDecl, names []string) []*ast.Ident {
+	var params []*ast.Ident
+	for _, field := range fn.Type.Params.List {
+		if !isAddressType(field.Type) {
+			continue
+		}
+		for _, ident := range field.Names {
+			lower := strings.ToLower(ident.Name)
+			for _, name := range names {
+				if strings.HasPrefix(lower, strings.ToLower(name)) {
+					params = append(params, ident)
+					break
+				}
+			}
+		}
+	}
+	return params
+}
+
+func isAddressType(expr ast.Expr) bool {
+	switch t := expr.(type) {
+	case *ast.Ident:
+		return t.Name == "Address"
+	case *ast.SelectorExpr:
+		return t.Sel.Name == "Address"
+	}
+	return false
+}
+
+// derivationCalls lists the calls to funcs within body, in source order.
+func derivationCalls(body ast.Node, funcs []string) []*ast.CallExpr {
+	var calls []*ast.CallExpr
+	ast.Inspect(body, func(n ast.Node) bool {
+		if call, ok := n.(*ast.CallExpr); ok && isDerivationCall(call, funcs) {
+			calls = append(calls, call)
+		}
+		return true
+	})
+	return calls
+}
+
+func isDerivationCall(call *ast.CallExpr, funcs []string) bool {
+	name := callName(call)
+	for _, f := range funcs {
+		if name == f {
+			return true
+		}
+	}
+	return false
+}
+
+func callName(call *ast.CallExpr) string {
+	switch fun := call.Fun.(type) {
+	case *ast.Ident:
+		return fun.Name
+	case *ast.SelectorExpr:
+		if x, ok := fun.X.(*ast.Ident); ok {
+			return x.Name + "." + fun.Sel.Name
+		}
+	}
+	return ""
+}
+
+// isCallerAssertion reports whether the body checks the parameter against the
+// derived caller, either by comparing them or by passing both to a call.
+func isCallerAssertion(body *ast.BlockStmt, param *ast.Ident, funcs []string) bool {
+	// variables holding a derived caller
+	derived := make(map[string]bool)
+	ast.Inspect(body, func(n ast.Node) bool {
+		assign, ok := n.(*ast.AssignStmt)
+		if !ok || len(assign.Lhs) != len(assign.Rhs) {
+			return true
+		}
+		for i, rhs := range assign.Rhs {
+			if ident, ok := assign.Lhs[i].(*ast.Ident); ok && len(derivationCalls(rhs, funcs)) > 0 {
+				derived[ident.Name] = true
+			}
+		}
+		return true
+	})
+
+	isDerived := func(expr ast.Expr) bool {
+		if ident, ok := expr.(*ast.Ident); ok && derived[ident.Name] {
+			return true
+		}
+		return len(derivationCalls(expr, funcs)) > 0
+	}
+	isParam := func(expr ast.Expr) bool {
+		ident, ok := expr.(*ast.Ident)
+		return ok && ident.Name == param.Name
+	}
+
+	found := false
+	ast.Inspect(body, func(n ast.Node) bool {
+		switch node := n.(type) {
+		case *ast.BinaryExpr:
+			if node.Op != token.EQL && node.Op != token.NEQ {
+				return true
+			}
+			if (isParam(node.X) && isDerived(node.Y)) || (isParam(node.Y) && isDerived(node.X)) {
+				found = true
+			}
+		case *ast.CallExpr:
+			var hasParam, hasDerived bool
+			for _, arg := range node.Args {
+				hasParam = hasParam || isParam(arg)
+				hasDerived = hasDerived || isDerived(arg)
+			}
+			if hasParam && hasDerived {
+				found = true
+			}
+		}
+		return !found
+	})
+	return found
+}
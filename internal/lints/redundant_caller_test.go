@@ -0,0 +1,162 @@
+package lints
+
+import (
+	"go/parser"
+	"go/token"
+	"testing"
+
+	tt "github.com/gnolang/tlin/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetectRedundantCallerParam(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name     string
+		code     string
+		names    []string
+		funcs    []string
+		expected []string
+		related  int
+	}{
+		{
+			name: "parameter and derived caller",
+			code: `
+package foo
+
+import "std"
+
+func Transfer(caller std.Address, amount int) {
+	origin := std.GetOrigCaller()
+	balances[origin] -= amount
+	_ = caller
+}`,
+			expected: []string{`function Transfer takes the caller as parameter "caller" but also derives it with std.GetOrigCaller`},
+			related:  1,
+		},
+		{
+			name: "several derivations are related locations",
+			code: `
+package foo
+
+import "std"
+
+func Mint(senderAddr std.Address) {
+	a := std.PrevRealm().Addr()
+	b := std.GetOrigCaller()
+	_, _, _ = a, b, senderAddr
+}`,
+			expected: []string{`function Mint takes the caller as parameter "senderAddr" but also derives it with std.PrevRealm`},
+			related:  2,
+		},
+		{
+			name: "assert helper comparing the parameter",
+			code: `
+package foo
+
+import "std"
+
+func assertIsCaller(caller std.Address) {
+	if caller != std.GetOrigCaller() {
+		panic("unauthorized")
+	}
+}`,
+		},
+		{
+			name: "assert helper through a variable",
+			code: `
+package foo
+
+import "std"
+
+func assertOwner(owner std.Address) {
+	origin := std.PrevRealm().Addr()
+	if origin != owner {
+		panic("unauthorized")
+	}
+}`,
+		},
+		{
+			name: "parameter passed with the derived caller",
+			code: `
+package foo
+
+import "std"
+
+func checkCaller(caller std.Address) {
+	assertEqual(caller, std.GetOrigCaller())
+}`,
+		},
+		{
+			name: "name not matching",
+			code: `
+package foo
+
+import "std"
+
+func TransferOwnership(newOwner std.Address) {
+	if std.GetOrigCaller() != owner {
+		panic("unauthorized")
+	}
+	owner = newOwner
+}`,
+		},
+		{
+			name: "parameter is not an address",
+			code: `
+package foo
+
+import "std"
+
+func Register(caller string) {
+	_ = std.GetOrigCaller()
+}`,
+		},
+		{
+			name: "configured names and functions",
+			code: `
+package foo
+
+import "std"
+
+func Pay(payer std.Address) {
+	_ = std.GetOrigCaller()
+	_ = runtime.OriginCaller()
+}`,
+			names:    []string{"payer"},
+			funcs:    []string{"runtime.OriginCaller"},
+			expected: []string{`function Pay takes the caller as parameter "payer" but also derives it with runtime.OriginCaller`},
+			related:  1,
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			fset := token.NewFileSet()
+			node, err := parser.ParseFile(fset, "test.gno", tc.code, 0)
+			require.NoError(t, err)
+
+			names, funcs := tc.names, tc.funcs
+			if names == nil {
+				names = DefaultCallerParamNames
+			}
+			if funcs == nil {
+				funcs = DefaultCallerFuncs
+			}
+
+			issues, err := DetectRedundantCallerParam("test.gno", node, fset, tt.SeverityWarning, names, funcs)
+			require.NoError(t, err)
+			require.Len(t, issues, len(tc.expected))
+
+			for i, issue := range issues {
+				assert.Equal(t, "redundant-caller-parameter", issue.Rule)
+				assert.Equal(t, tc.expected[i], issue.Message)
+				assert.Len(t, issue.Related, tc.related)
+				assert.Equal(t, "caller parameter declared here", issue.Related[0].Label)
+			}
+		})
+	}
+}
@@ -0,0 +1,160 @@
+package lints
+
+import (
+	"fmt"
+	"go/ast"
+	"go/constant"
+	"go/token"
+	"go/types"
+	"strconv"
+	"strings"
+
+	tt "github.com/gnolang/tlin/internal/types"
+)
+
+// DetectRedundantFormatting reports Sprintf and Errorf calls whose constant
+// format does not need formatting: formats without verbs, a single %s or %v
+// applied to a string or a Stringer, and a single %d applied to an int.
+// The suggestion is the simplified expression, such as the string literal,
+// the argument itself, a String call, strconv.Itoa or errors.New. %q, which
+// adds quotes, is never simplified.
+func DetectRedundantFormatting(filename string, node *ast.File, fset *token.FileSet, info *types.Info, severity tt.Severity) ([]tt.Issue, error) {
+	var issues []tt.Issue
+	ast.Inspect(node, func(n ast.Node) bool {
+		call, ok := n.(*ast.CallExpr)
+		if !ok || len(call.Args) == 0 || call.Ellipsis.IsValid() {
+			return true
+		}
+		sel, ok := call.Fun.(*ast.SelectorExpr)
+		if !ok || (sel.Sel.Name != "Sprintf" && sel.Sel.Name != "Errorf") {
+			return true
+		}
+		pkg, ok := sel.X.(*ast.Ident)
+		if !ok || !isFormatPackage(importPath(node, pkg.Name)) {
+			return true
+		}
+		tv, ok := info.Types[call.Args[0]]
+		if !ok || tv.Value == nil || tv.Value.Kind() != constant.String {
+			return true
+		}
+
+		simplified, ok := simplifyFormatting(call, constant.StringVal(tv.Value), info)
+		if !ok {
+			return true
+		}
+		if sel.Sel.Name == "Errorf" {
+			simplified = "errors.New(" + simplified + ")"
+		}
+		issues = append(issues, tt.Issue{
+			Rule:       "redundant-format",
+			Filename:   filename,
+			Start:      fset.Position(call.Pos()),
+			End:        fset.Position(call.End()),
+			Message:    fmt.Sprintf("%s.%s call does not need formatting, use %s", pkg.Name, sel.Sel.Name, simplified),
+			Suggestion: simplified,
+			Severity:   severity,
+		})
+		return true
+	})
+	return issues, nil
+}
+
+// simplifyFormatting returns the expression equivalent to formatting the
+// arguments of call with format, when there is a simpler one.
+func simplifyFormatting(call *ast.CallExpr, format string, info *types.Info) (string, bool) {
+	args := call.Args[1:]
+	verbs := formatVerbs(format)
+
+	if len(verbs) == 0 {
+		if len(args) > 0 {
+			// extra arguments are a mistake reported by vet, not a simplification
+			return "", false
+		}
+		if lit, ok := call.Args[0].(*ast.BasicLit); ok && !strings.Contains(format, "%") {
+			return lit.Value, true
+		}
+		return strconv.Quote(strings.ReplaceAll(format, "%%", "%")), true
+	}
+
+	if len(args) != 1 || len(verbs) != 1 || format != "%"+verbs {
+		return "", false
+	}
+	arg := args[0]
+	tv, ok := info.Types[arg]
+	if !ok || tv.Type == nil {
+		return "", false
+	}
+	expr := types.ExprString(arg)
+
+	switch verbs {
+	case "s", "v":
+		if types.Implements(tv.Type, errorInterface()) {
+			return "", false
+		}
+		if isStringer(tv.Type) {
+			return expr + ".String()", true
+		}
+		basic, ok := tv.Type.Underlying().(*types.Basic)
+		if !ok || basic.Info()&types.IsString == 0 {
+			return "", false
+		}
+		if tv.Type != tv.Type.Underlying() {
+			// a named string type
+			return "string(" + expr + ")", true
+		}
+		return expr, true
+	case "d":
+		if types.Identical(tv.Type, types.Typ[types.Int]) {
+			return "strconv.Itoa(" + expr + ")", true
+		}
+	}
+	return "", false
+}
+
+// formatVerbs returns the directives of a format, flags and widths included,
+// concatenated without their %. %% is not a directive.
+func formatVerbs(format string) string {
+	var verbs strings.Builder
+	for i := 0; i < len(format); i++ {
+		if format[i] != '%' {
+			continue
+		}
+		i++
+		if i < len(format) && format[i] == '%' {
+			continue
+		}
+		for i < len(format) && strings.IndexByte("+-# 0123456789.*[]", format[i]) >= 0 {
+			verbs.WriteByte(format[i])
+			i++
+		}
+		if i < len(format) {
+			verbs.WriteByte(format[i])
+		}
+	}
+	return verbs.String()
+}
+
+// isFormatPackage reports whether path is fmt or a ufmt package.
+func isFormatPackage(path string) bool {
+	return path == "fmt" || path == "ufmt" || strings.HasSuffix(path, "/ufmt")
+}
+
+// isStringer reports whether the method set of typ, as passed by value, has
+// a `String() string` method.
+func isStringer(typ types.Type) bool {
+	obj, _, _ := types.LookupFieldOrMethod(typ, false, nil, "String")
+	fn, ok := obj.(*types.Func)
+	if !ok {
+		return false
+	}
+	sig := fn.Type().(*types.Signature)
+	if sig.Params().Len() != 0 || sig.Results().Len() != 1 {
+		return false
+	}
+	basic, ok := sig.Results().At(0).Type().(*types.Basic)
+	return ok && basic.Kind() == types.String
+}
+
+func errorInterface() *types.Interface {
+	return types.Universe.Lookup("error").Type().Underlying().(*types.Interface)
+}
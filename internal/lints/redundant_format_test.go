@@ -0,0 +1,136 @@
+package lints
+
+import (
+	"go/parser"
+	"go/token"
+	"testing"
+
+	tt "github.com/gnolang/tlin/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetectRedundantFormatting(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name        string
+		code        string
+		suggestions []string
+	}{
+		{
+			name: "formats without verbs",
+			code: `package foo
+
+import (
+	"fmt"
+
+	"gno.land/p/demo/ufmt"
+)
+
+const greeting = "hello"
+
+func f() {
+	_ = ufmt.Sprintf("hello")
+	_ = fmt.Sprintf("100%% done")
+	_ = fmt.Sprintf(greeting)
+	_ = ufmt.Errorf("not found")
+	_ = fmt.Sprintf("%d items", 3)
+}
+`,
+			suggestions: []string{
+				`"hello"`,
+				`"100% done"`,
+				`"hello"`,
+				`errors.New("not found")`,
+			},
+		},
+		{
+			name: "single verb on a string",
+			code: `package foo
+
+import "gno.land/p/demo/ufmt"
+
+type Name string
+
+type ID int
+
+func (id ID) String() string { return "id" }
+
+func f(s string, n Name, id ID, i int, i64 int64) {
+	_ = ufmt.Sprintf("%s", s)
+	_ = ufmt.Sprintf("%v", n)
+	_ = ufmt.Sprintf("%s", id)
+	_ = ufmt.Errorf("%s", s)
+	_ = ufmt.Sprintf("%d", i)
+	_ = ufmt.Sprintf("%d", i64)
+}
+`,
+			suggestions: []string{
+				"s",
+				"string(n)",
+				"id.String()",
+				"errors.New(s)",
+				"strconv.Itoa(i)",
+			},
+		},
+		{
+			name: "formatting that matters",
+			code: `package foo
+
+import (
+	"errors"
+	"fmt"
+)
+
+type T struct{}
+
+func (t *T) String() string { return "t" }
+
+func f(s string, b []byte, err error, v T, args []interface{}) {
+	_ = fmt.Sprintf("%q", s)
+	_ = fmt.Sprintf("%5s", s)
+	_ = fmt.Sprintf("%s!", s)
+	_ = fmt.Sprintf("%s", b)
+	_ = fmt.Sprintf("%v", err)
+	_ = fmt.Sprintf("%v", v)
+	_ = fmt.Sprintf("%s %s", s, s)
+	_ = fmt.Sprintf(s)
+	_ = fmt.Sprintf("%s", args...)
+	_ = errors.New("x")
+}
+`,
+		},
+		{
+			name: "other packages",
+			code: `package foo
+
+import fmt "example.com/format"
+
+func f(s string) {
+	_ = fmt.Sprintf("%s", s)
+}
+`,
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			fset := token.NewFileSet()
+			node, err := parser.ParseFile(fset, "test.gno", tc.code, 0)
+			require.NoError(t, err)
+
+			pkg := NewPackage(fset, &PackageFile{Filename: "test.gno", File: node})
+			issues, err := CheckTyped(pkg, DetectRedundantFormatting, tt.SeverityInfo)
+			require.NoError(t, err)
+
+			var suggestions []string
+			for _, issue := range issues {
+				assert.Equal(t, "redundant-format", issue.Rule)
+				suggestions = append(suggestions, issue.Suggestion)
+			}
+			assert.Equal(t, tc.suggestions, suggestions)
+		})
+	}
+}
@@ -0,0 +1,319 @@
+package lints
+
+import (
+	"fmt"
+	"go/ast"
+	"go/types"
+	"sort"
+	"strings"
+
+	tt "github.com/gnolang/tlin/internal/types"
+)
+
+const reentrantCallConfidence = 0.7
+
+// DetectReentrantCalls reports, in realm packages, cycles of the package
+// call graph going through Render or an exported function. Anyone can call
+// these functions, so a crafted input can re-enter them while their state
+// update is only half done, or recurse without bound:
+//
+//	func Transfer(to string, amount int) {
+//		balances[to] += amount
+//		notify(to, amount)
+//	}
+//
+//	func notify(to string, amount int) {
+//		if forward[to] != "" {
+//			Transfer(forward[to], amount)
+//		}
+//	}
+//
+// Cycles made only of unexported functions are left to cycle-detection.
+// Calls to other realms are not followed, but those made from the cycle are
+// noted in the message since they may call back into the realm.
+func DetectReentrantCalls(pkg *Package, severity tt.Severity) ([]tt.Issue, error) {
+	if !isRealmPackage(pkg) {
+		return nil, nil
+	}
+	files, info, _ := typeCheck(pkg)
+	g := newCallGraph(files, info)
+
+	var issues []tt.Issue
+	for _, component := range g.cycles() {
+		entry := entryPointOf(component)
+		if entry == nil {
+			continue
+		}
+		path := g.cyclePath(entry, component)
+
+		names := []string{entry.fn.Name()}
+		related := make([]tt.Location, 0, len(path))
+		for _, edge := range path {
+			names = append(names, edge.callee.fn.Name())
+			related = append(related, tt.Location{
+				Position: pkg.Fset.Position(edge.call.Pos()),
+				Label:    edge.caller.fn.Name() + " calls " + edge.callee.fn.Name(),
+			})
+		}
+
+		message := fmt.Sprintf("%s re-enters itself through %s, a cycle callers outside the realm can trigger to recurse without bound",
+			entry.fn.Name(), strings.Join(names, " -> "))
+		if external := g.externalCalls(component); len(external) > 0 {
+			message += fmt.Sprintf("; the cycle also calls %s in another realm, which is not analyzed and may call back",
+				strings.Join(external, ", "))
+		}
+
+		issues = append(issues, tt.Issue{
+			Rule:       "reentrant-call",
+			Filename:   entry.file.Filename,
+			Start:      pkg.Fset.Position(entry.decl.Name.Pos()),
+			End:        pkg.Fset.Position(entry.decl.Name.End()),
+			Message:    message,
+			Suggestion: fmt.Sprintf("bound the recursion, or finish the state update and guard against re-entry before the calls leading back to %s", entry.fn.Name()),
+			Confidence: reentrantCallConfidence,
+			Severity:   severity,
+			Related:    related,
+		})
+	}
+	return issues, nil
+}
+
+// callNode is a function or method declared in the package.
+type callNode struct {
+	fn    *types.Func
+	decl  *ast.FuncDecl
+	file  *PackageFile
+	calls []callEdge
+	// calls to functions of other realms, which the graph does not follow
+	external []string
+}
+
+type callEdge struct {
+	caller, callee *callNode
+	call           *ast.CallExpr
+}
+
+// callGraph is the graph of the static calls between the functions of a
+// package. Calls made from function literals are attributed to the
+// enclosing declaration.
+type callGraph struct {
+	nodes []*callNode // in source order
+}
+
+func newCallGraph(files []*PackageFile, info *types.Info) *callGraph {
+	g := &callGraph{}
+	byFunc := make(map[*types.Func]*callNode)
+	for _, file := range files {
+		for _, decl := range file.File.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok || fn.Body == nil {
+				continue
+			}
+			obj, ok := info.Defs[fn.Name].(*types.Func)
+			if !ok {
+				continue
+			}
+			node := &callNode{fn: obj, decl: fn, file: file}
+			byFunc[obj] = node
+			g.nodes = append(g.nodes, node)
+		}
+	}
+
+	for _, node := range g.nodes {
+		ast.Inspect(node.decl.Body, func(n ast.Node) bool {
+			call, ok := n.(*ast.CallExpr)
+			if !ok {
+				return true
+			}
+			if name, ok := realmCall(call, info); ok {
+				if !contains(node.external, name) {
+					node.external = append(node.external, name)
+				}
+				return true
+			}
+			if callee := byFunc[calledFunc(call, info)]; callee != nil {
+				node.calls = append(node.calls, callEdge{caller: node, callee: callee, call: call})
+			}
+			return true
+		})
+	}
+	return g
+}
+
+// calledFunc returns the function or method called by call, if static.
+func calledFunc(call *ast.CallExpr, info *types.Info) *types.Func {
+	var obj types.Object
+	switch fun := ast.Unparen(call.Fun).(type) {
+	case *ast.Ident:
+		obj = info.Uses[fun]
+	case *ast.SelectorExpr:
+		obj = info.Uses[fun.Sel]
+	case *ast.IndexExpr:
+		// explicit instantiation of a generic function
+		if ident, ok := fun.X.(*ast.Ident); ok {
+			obj = info.Uses[ident]
+		}
+	}
+	fn, ok := obj.(*types.Func)
+	if !ok {
+		return nil
+	}
+	return fn.Origin()
+}
+
+// realmCall returns the qualified name of the function called by call when
+// it belongs to an imported realm package.
+func realmCall(call *ast.CallExpr, info *types.Info) (string, bool) {
+	sel, ok := ast.Unparen(call.Fun).(*ast.SelectorExpr)
+	if !ok {
+		return "", false
+	}
+	x, ok := sel.X.(*ast.Ident)
+	if !ok {
+		return "", false
+	}
+	pkgName, ok := info.Uses[x].(*types.PkgName)
+	if !ok {
+		return "", false
+	}
+	path := pkgName.Imported().Path()
+	if !strings.Contains("/"+path+"/", "/r/") {
+		return "", false
+	}
+	return path + "." + sel.Sel.Name, true
+}
+
+// cycles returns the strongly connected components of the graph which
+// contain a cycle, that is more than one function or a function calling
+// itself. Components and their functions are in source order.
+func (g *callGraph) cycles() [][]*callNode {
+	// Tarjan's algorithm
+	index := make(map[*callNode]int)
+	lowlink := make(map[*callNode]int)
+	onStack := make(map[*callNode]bool)
+	var stack []*callNode
+	var components [][]*callNode
+
+	var visit func(node *callNode)
+	visit = func(node *callNode) {
+		index[node] = len(index)
+		lowlink[node] = index[node]
+		stack = append(stack, node)
+		onStack[node] = true
+
+		for _, edge := range node.calls {
+			if _, seen := index[edge.callee]; !seen {
+				visit(edge.callee)
+				lowlink[node] = min(lowlink[node], lowlink[edge.callee])
+			} else if onStack[edge.callee] {
+				lowlink[node] = min(lowlink[node], index[edge.callee])
+			}
+		}
+
+		if lowlink[node] != index[node] {
+			return
+		}
+		var component []*callNode
+		for {
+			top := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			onStack[top] = false
+			component = append(component, top)
+			if top == node {
+				break
+			}
+		}
+		if len(component) > 1 || callsItself(node) {
+			components = append(components, component)
+		}
+	}
+
+	for _, node := range g.nodes {
+		if _, seen := index[node]; !seen {
+			visit(node)
+		}
+	}
+
+	order := make(map[*callNode]int, len(g.nodes))
+	for i, node := range g.nodes {
+		order[node] = i
+	}
+	for _, component := range components {
+		sort.Slice(component, func(i, j int) bool {
+			return order[component[i]] < order[component[j]]
+		})
+	}
+	sort.Slice(components, func(i, j int) bool {
+		return order[components[i][0]] < order[components[j][0]]
+	})
+	return components
+}
+
+func callsItself(node *callNode) bool {
+	for _, edge := range node.calls {
+		if edge.callee == node {
+			return true
+		}
+	}
+	return false
+}
+
+// entryPointOf returns the first function of component callers outside the
+// realm can call: Render or an exported function.
+func entryPointOf(component []*callNode) *callNode {
+	for _, node := range component {
+		if node.decl.Recv == nil && (node.fn.Exported() || node.fn.Name() == "Render") {
+			return node
+		}
+	}
+	return nil
+}
+
+// cyclePath returns the shortest chain of calls leading from entry back to
+// itself within component.
+func (g *callGraph) cyclePath(entry *callNode, component []*callNode) []callEdge {
+	inComponent := make(map[*callNode]bool, len(component))
+	for _, node := range component {
+		inComponent[node] = true
+	}
+
+	// breadth-first search, remembering the call reaching each function
+	reached := make(map[*callNode]callEdge)
+	queue := []*callNode{entry}
+	for len(queue) > 0 {
+		node := queue[0]
+		queue = queue[1:]
+		for _, edge := range node.calls {
+			if edge.callee == entry {
+				path := []callEdge{edge}
+				for at := node; at != entry; at = reached[at].caller {
+					path = append(path, reached[at])
+				}
+				for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+					path[i], path[j] = path[j], path[i]
+				}
+				return path
+			}
+			if _, seen := reached[edge.callee]; seen || !inComponent[edge.callee] {
+				continue
+			}
+			reached[edge.callee] = edge
+			queue = append(queue, edge.callee)
+		}
+	}
+	return nil
+}
+
+// externalCalls returns the functions of other realms called from
+// component.
+func (g *callGraph) externalCalls(component []*callNode) []string {
+	var names []string
+	for _, node := range component {
+		for _, name := range node.external {
+			if !contains(names, name) {
+				names = append(names, name)
+			}
+		}
+	}
+	return names
+}
@@ -0,0 +1,166 @@
+package lints
+
+import (
+	"testing"
+
+	tt "github.com/gnolang/tlin/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetectReentrantCalls(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name     string
+		files    map[string]string
+		messages []string
+		related  [][]string
+	}{
+		{
+			name: "cycles through entry points",
+			files: map[string]string{
+				"gno.land/r/demo/bank/bank.gno": `package bank
+
+var (
+	balances = map[string]int{}
+	forward  = map[string]string{}
+)
+
+func Transfer(to string, amount int) {
+	balances[to] += amount
+	notify(to, amount)
+}
+
+func notify(to string, amount int) {
+	if next := forward[to]; next != "" {
+		Transfer(next, amount)
+	}
+}
+
+func Render(path string) string {
+	if path == "" {
+		return ""
+	}
+	return Render(path[1:])
+}
+`,
+				"gno.land/r/demo/bank/admin.gno": `package bank
+
+type registry struct{ names []string }
+
+func (r *registry) add(name string) {
+	Register(name)
+}
+
+var reg registry
+
+func Register(name string) {
+	reg.names = append(reg.names, name)
+	if len(reg.names) < 10 {
+		reg.add(name)
+	}
+}
+`,
+			},
+			messages: []string{
+				"Register re-enters itself through Register -> add -> Register, a cycle callers outside the realm can trigger to recurse without bound",
+				"Transfer re-enters itself through Transfer -> notify -> Transfer, a cycle callers outside the realm can trigger to recurse without bound",
+				"Render re-enters itself through Render -> Render, a cycle callers outside the realm can trigger to recurse without bound",
+			},
+			related: [][]string{
+				{"Register calls add", "add calls Register"},
+				{"Transfer calls notify", "notify calls Transfer"},
+				{"Render calls Render"},
+			},
+		},
+		{
+			name: "cross-realm calls from the cycle",
+			files: map[string]string{
+				"gno.land/r/demo/market/market.gno": `package market
+
+import "gno.land/r/demo/hooks"
+
+func Buy(id string) {
+	settle(id)
+}
+
+func settle(id string) {
+	hooks.OnSettle(id)
+	if id != "" {
+		Buy(id[1:])
+	}
+}
+`,
+			},
+			messages: []string{
+				"Buy re-enters itself through Buy -> settle -> Buy, a cycle callers outside the realm can trigger to recurse without bound; the cycle also calls gno.land/r/demo/hooks.OnSettle in another realm, which is not analyzed and may call back",
+			},
+			related: [][]string{{"Buy calls settle", "settle calls Buy"}},
+		},
+		{
+			name: "no entry point in the cycle",
+			files: map[string]string{
+				"gno.land/r/demo/tree/tree.gno": `package tree
+
+type node struct{ children []*node }
+
+func (n *node) Size() int {
+	size := 1
+	for _, c := range n.children {
+		size += c.Size()
+	}
+	return size
+}
+
+func depth(n *node) int {
+	d := 0
+	for _, c := range n.children {
+		d = max(d, depth(c)+1)
+	}
+	return d
+}
+
+func Depth() int {
+	return depth(&node{})
+}
+`,
+			},
+		},
+		{
+			name: "not a realm",
+			files: map[string]string{
+				"gno.land/p/demo/tree/tree.gno": `package tree
+
+func Render(path string) string {
+	return Render(path)
+}
+`,
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			issues, err := DetectReentrantCalls(parsePackage(t, tc.files), tt.SeverityWarning)
+			require.NoError(t, err)
+
+			var messages []string
+			var related [][]string
+			for _, issue := range issues {
+				assert.Equal(t, "reentrant-call", issue.Rule)
+				messages = append(messages, issue.Message)
+				var labels []string
+				for _, loc := range issue.Related {
+					labels = append(labels, loc.Label)
+				}
+				related = append(related, labels)
+			}
+			assert.Equal(t, tc.messages, messages)
+			if tc.related != nil {
+				assert.Equal(t, tc.related, related)
+			}
+		})
+	}
+}
@@ -6,16 +6,17 @@ import (
 
 	tt "github.com/gnolang/tlin/internal/types"
 	"golang.org/x/tools/go/analysis"
-	"golang.org/x/tools/go/packages"
 )
 
 var RepeatedRegexCompilationAnalyzer = &analysis.Analyzer{
 	Name: "repeatedregexcompilation",
 	Doc:  "Checks for repeated compilation of the same regex pattern",
 	Run:  runRepeatedRegexCompilation,
+	// the analyzer only looks at the syntax
+	RunDespiteErrors: true,
 }
 
-func DetectRepeatedRegexCompilation(filename string, node *ast.File, _ *token.FileSet, severity tt.Severity) ([]tt.Issue, error) {
+func DetectRepeatedRegexCompilation(filename string, node *ast.File, fset *token.FileSet, severity tt.Severity) ([]tt.Issue, error) {
 	imports := extractImports(node, func(path string) bool {
 		return path == "regexp"
 	})
@@ -24,55 +25,8 @@ func DetectRepeatedRegexCompilation(filename string, node *ast.File, _ *token.Fi
 		return nil, nil
 	}
 
-	issues, err := runAnalyzer(filename, RepeatedRegexCompilationAnalyzer, severity)
-	if err != nil {
-		return nil, err
-	}
-	return issues, nil
-}
-
-func runAnalyzer(filename string, a *analysis.Analyzer, severity tt.Severity) ([]tt.Issue, error) {
-	cfg := &packages.Config{
-		Mode:  packages.NeedFiles | packages.NeedSyntax | packages.NeedTypesInfo | packages.NeedTypes,
-		Tests: false,
-	}
-
-	pkgs, err := packages.Load(cfg, filename)
-	if err != nil {
-		return nil, err
-	}
-
-	var diagnostics []analysis.Diagnostic
-	pass := &analysis.Pass{
-		Analyzer:  a,
-		Fset:      pkgs[0].Fset,
-		Files:     pkgs[0].Syntax,
-		Pkg:       pkgs[0].Types,
-		TypesInfo: pkgs[0].TypesInfo,
-		ResultOf:  make(map[*analysis.Analyzer]interface{}),
-		Report: func(d analysis.Diagnostic) {
-			diagnostics = append(diagnostics, d)
-		},
-	}
-
-	_, err = a.Run(pass)
-	if err != nil {
-		return nil, err
-	}
-
-	issues := make([]tt.Issue, 0, len(diagnostics))
-	for _, diag := range diagnostics {
-		issues = append(issues, tt.Issue{
-			Rule:     a.Name,
-			Filename: filename,
-			Start:    pass.Fset.Position(diag.Pos),
-			End:      pass.Fset.Position(diag.End),
-			Message:  diag.Message,
-			Severity: severity,
-		})
-	}
-
-	return issues, nil
+	pkg := NewPackage(fset, &PackageFile{Filename: filename, File: node})
+	return RunAnalyzer(pkg, RepeatedRegexCompilationAnalyzer, severity)
 }
 
 func runRepeatedRegexCompilation(pass *analysis.Pass) (interface{}, error) {
@@ -0,0 +1,202 @@
+package lints
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"strings"
+	"unicode"
+
+	tt "github.com/gnolang/tlin/internal/types"
+)
+
+// preStateWords in the name or doc comment of a function tell that it
+// returns the state from before its update on purpose.
+var preStateWords = []string{"previous", "prev", "old", "prior", "before"}
+
+// DetectStaleStateReturns reports exported functions of realm packages
+// copying a package-level variable into a local, updating the variable, and
+// then returning the local, so that callers observe the state from before
+// the update:
+//
+//	func Deposit(amount int) int {
+//		balance := balances[caller]
+//		balances[caller] += amount
+//		return balance
+//	}
+//
+// Functions whose name or doc comment mentions the previous or old value
+// return it on purpose and are skipped. Locals assigned again after the
+// update are fine too. Statements are considered in source order, the
+// branches of the function are not told apart.
+func DetectStaleStateReturns(pkg *Package, severity tt.Severity) ([]tt.Issue, error) {
+	if !isRealmPackage(pkg) {
+		return nil, nil
+	}
+	files, info, checked := typeCheck(pkg)
+
+	var issues []tt.Issue
+	for _, file := range files {
+		for _, decl := range file.File.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok || fn.Body == nil || fn.Recv != nil || !fn.Name.IsExported() || returnsPreState(fn) {
+				continue
+			}
+
+			// locals copied from the state, and the variable they copy
+			copies := make(map[types.Object]*stateCopy)
+			// last update of each package variable
+			updates := make(map[*types.Var]ast.Node)
+			ast.Inspect(fn.Body, func(n ast.Node) bool {
+				switch stmt := n.(type) {
+				case *ast.FuncLit:
+					return false
+				case *ast.AssignStmt:
+					for _, lhs := range stmt.Lhs {
+						if state := packageRoot(lhs, info, checked.Scope()); state != nil {
+							updates[state] = stmt
+						}
+					}
+					trackCopies(stmt, info, checked.Scope(), copies)
+				case *ast.IncDecStmt:
+					if state := packageRoot(stmt.X, info, checked.Scope()); state != nil {
+						updates[state] = stmt
+					}
+				case *ast.ReturnStmt:
+					for _, result := range stmt.Results {
+						ident, ok := ast.Unparen(result).(*ast.Ident)
+						if !ok {
+							continue
+						}
+						c, ok := copies[info.Uses[ident]]
+						if !ok {
+							continue
+						}
+						update, ok := updates[c.state]
+						if !ok || update.Pos() < c.read.Pos() {
+							continue
+						}
+						issues = append(issues, tt.Issue{
+							Rule:     "stale-state-return",
+							Filename: file.Filename,
+							Start:    pkg.Fset.Position(ident.Pos()),
+							End:      pkg.Fset.Position(ident.End()),
+							Message: fmt.Sprintf("%s returns %s, read from %s before it is updated",
+								fn.Name.Name, ident.Name, c.state.Name()),
+							Note:     "callers observe the state from before the call, recompute the value after the update or return the updated one",
+							Severity: severity,
+							Related: []tt.Location{
+								{Position: pkg.Fset.Position(c.read.Pos()), Label: ident.Name + " read here"},
+								{Position: pkg.Fset.Position(update.Pos()), Label: c.state.Name() + " updated here"},
+							},
+						})
+					}
+				}
+				return true
+			})
+		}
+	}
+	return issues, nil
+}
+
+type stateCopy struct {
+	state *types.Var
+	read  ast.Node
+}
+
+// trackCopies records the locals assigned a value copied from a package
+// variable, and forgets the locals assigned anything else. References such
+// as pointers, slices and maps share the updated memory and are not copies.
+func trackCopies(assign *ast.AssignStmt, info *types.Info, scope *types.Scope, copies map[types.Object]*stateCopy) {
+	if len(assign.Lhs) != len(assign.Rhs) {
+		for _, lhs := range assign.Lhs {
+			if ident, ok := lhs.(*ast.Ident); ok {
+				delete(copies, definedOrUsed(ident, info))
+			}
+		}
+		return
+	}
+	for i, lhs := range assign.Lhs {
+		ident, ok := lhs.(*ast.Ident)
+		if !ok {
+			continue
+		}
+		obj := definedOrUsed(ident, info)
+		if obj == nil || obj.Parent() == scope {
+			continue
+		}
+		delete(copies, obj)
+		if assign.Tok != token.ASSIGN && assign.Tok != token.DEFINE {
+			continue
+		}
+		rhs := assign.Rhs[i]
+		if isReference(info.TypeOf(rhs)) {
+			continue
+		}
+		if state := packageRoot(rhs, info, scope); state != nil {
+			copies[obj] = &stateCopy{state: state, read: assign}
+		}
+	}
+}
+
+func definedOrUsed(ident *ast.Ident, info *types.Info) types.Object {
+	if obj := info.Defs[ident]; obj != nil {
+		return obj
+	}
+	return info.Uses[ident]
+}
+
+// packageRoot returns the package variable at the root of a selector and
+// index chain such as `balances[addr]` or `config.Total`.
+func packageRoot(expr ast.Expr, info *types.Info, scope *types.Scope) *types.Var {
+	for {
+		switch e := ast.Unparen(expr).(type) {
+		case *ast.Ident:
+			v, ok := info.Uses[e].(*types.Var)
+			if !ok || v.Parent() != scope {
+				return nil
+			}
+			return v
+		case *ast.SelectorExpr:
+			expr = e.X
+		case *ast.IndexExpr:
+			expr = e.X
+		case *ast.StarExpr:
+			expr = e.X
+		default:
+			return nil
+		}
+	}
+}
+
+func isReference(typ types.Type) bool {
+	if typ == nil {
+		// unresolved, possibly a reference
+		return true
+	}
+	switch typ.Underlying().(type) {
+	case *types.Pointer, *types.Slice, *types.Map, *types.Chan, *types.Interface, *types.Signature:
+		return true
+	}
+	return false
+}
+
+// returnsPreState reports whether the name or the doc comment of fn tells
+// that it returns the state from before its update.
+func returnsPreState(fn *ast.FuncDecl) bool {
+	words := splitWords(fn.Name.Name)
+	if fn.Doc != nil {
+		words = append(words, strings.FieldsFunc(fn.Doc.Text(), func(r rune) bool {
+			return !unicode.IsLetter(r)
+		})...)
+	}
+	for _, word := range words {
+		for _, pre := range preStateWords {
+			if strings.EqualFold(word, pre) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,149 @@
+package lints
+
+import (
+	"testing"
+
+	tt "github.com/gnolang/tlin/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetectStaleStateReturns(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name     string
+		files    map[string]string
+		messages []string
+	}{
+		{
+			name: "returning a copy read before the update",
+			files: map[string]string{
+				"gno.land/r/demo/bank/bank.gno": `package bank
+
+type stats struct{ Total int }
+
+var (
+	balances = map[string]int{}
+	counter  int
+	global   stats
+)
+
+func Deposit(addr string, amount int) int {
+	balance := balances[addr]
+	balances[addr] += amount
+	return balance
+}
+
+func Increment() (int, error) {
+	n := counter
+	counter++
+	return n, nil
+}
+
+func Record(v int) int {
+	total := global.Total
+	if v > 0 {
+		global.Total = total + v
+	}
+	return total
+}
+
+func Threshold() int {
+	n := counter
+	counter = 0
+	return n
+}
+`,
+			},
+			messages: []string{
+				"Deposit returns balance, read from balances before it is updated",
+				"Increment returns n, read from counter before it is updated",
+				"Record returns total, read from global before it is updated",
+				"Threshold returns n, read from counter before it is updated",
+			},
+		},
+		{
+			name: "intentional or fresh values",
+			files: map[string]string{
+				"gno.land/r/demo/bank/bank.gno": `package bank
+
+var (
+	balances = map[string]int{}
+	counter  int
+	items    []int
+)
+
+// Swap sets the counter and returns the previous value.
+func Swap(v int) int {
+	n := counter
+	counter = v
+	return n
+}
+
+func ResetOld() int {
+	n := counter
+	counter = 0
+	return n
+}
+
+func Deposit(addr string, amount int) int {
+	balance := balances[addr]
+	balances[addr] = balance + amount
+	balance = balances[addr]
+	return balance
+}
+
+func Append(v int) []int {
+	list := items
+	items = append(items, v)
+	return list
+}
+
+func Read() int {
+	counter++
+	n := counter
+	return n
+}
+
+func helper() int {
+	n := counter
+	counter++
+	return n
+}
+`,
+			},
+		},
+		{
+			name: "not a realm",
+			files: map[string]string{
+				"gno.land/p/demo/bank/bank.gno": `package bank
+
+var counter int
+
+func Increment() int {
+	n := counter
+	counter++
+	return n
+}
+`,
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			issues, err := DetectStaleStateReturns(parsePackage(t, tc.files), tt.SeverityWarning)
+			require.NoError(t, err)
+
+			var messages []string
+			for _, issue := range issues {
+				assert.Equal(t, "stale-state-return", issue.Rule)
+				require.Len(t, issue.Related, 2)
+				messages = append(messages, issue.Message)
+			}
+			assert.Equal(t, tc.messages, messages)
+		})
+	}
+}
@@ -0,0 +1,192 @@
+package lints
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+
+	tt "github.com/gnolang/tlin/internal/types"
+)
+
+// DetectStateReferenceReturns reports exported functions of realm packages
+// returning a pointer to a package-level variable, or to one of its fields
+// or elements, and those returning a slice, map or pointer held by the realm
+// state. Callers then hold a mutable reference into the realm storage and
+// bypass its functions. Interface results wrapping such a reference are
+// reported as well, and so are local variables holding one, while pointers
+// to local copies are fine.
+func DetectStateReferenceReturns(pkg *Package, severity tt.Severity) ([]tt.Issue, error) {
+	if !isRealmPackage(pkg) {
+		return nil, nil
+	}
+
+	files, info, checked := typeCheck(pkg)
+
+	// declared types of the package variables, for types left unresolved
+	declared := make(map[types.Object]ast.Expr)
+	for _, file := range files {
+		for _, decl := range file.File.Decls {
+			gen, ok := decl.(*ast.GenDecl)
+			if !ok || gen.Tok != token.VAR {
+				continue
+			}
+			for _, spec := range gen.Specs {
+				vs := spec.(*ast.ValueSpec)
+				for _, name := range vs.Names {
+					if obj := info.Defs[name]; obj != nil && vs.Type != nil {
+						declared[obj] = vs.Type
+					}
+				}
+			}
+		}
+	}
+
+	sr := &stateReferences{
+		info:     info,
+		scope:    checked.Scope(),
+		declared: declared,
+	}
+
+	var issues []tt.Issue
+	for _, file := range files {
+		for _, decl := range file.File.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok || fn.Body == nil || !fn.Name.IsExported() {
+				continue
+			}
+
+			sr.locals = make(map[types.Object]*types.Var)
+			ast.Inspect(fn.Body, func(n ast.Node) bool {
+				switch stmt := n.(type) {
+				case *ast.FuncLit:
+					return false
+				case *ast.AssignStmt:
+					sr.trackLocals(stmt)
+				case *ast.ReturnStmt:
+					for _, result := range stmt.Results {
+						state, kind := sr.reference(result)
+						if state == nil {
+							continue
+						}
+						issues = append(issues, tt.Issue{
+							Rule:     "state-reference-return",
+							Filename: file.Filename,
+							Start:    pkg.Fset.Position(result.Pos()),
+							End:      pkg.Fset.Position(result.End()),
+							Message: fmt.Sprintf("exported function %s returns %s referencing the realm state %s",
+								fn.Name.Name, kind, state.Name()),
+							Note:     "return a copy or a value type, so that callers go through the realm functions to change its state",
+							Severity: severity,
+							Related:  []tt.Location{{Position: pkg.Fset.Position(state.Pos()), Label: state.Name() + " declared here"}},
+						})
+					}
+				}
+				return true
+			})
+		}
+	}
+	return issues, nil
+}
+
+// stateReferences tells which expressions of a function refer to the
+// package-level variables.
+type stateReferences struct {
+	info     *types.Info
+	scope    *types.Scope
+	declared map[types.Object]ast.Expr
+	// local variables holding a reference, with the state they refer to
+	locals map[types.Object]*types.Var
+}
+
+// trackLocals records the local variables assigned a reference to the state.
+func (sr *stateReferences) trackLocals(assign *ast.AssignStmt) {
+	if len(assign.Lhs) != len(assign.Rhs) {
+		return
+	}
+	for i, lhs := range assign.Lhs {
+		ident, ok := lhs.(*ast.Ident)
+		if !ok {
+			continue
+		}
+		obj := sr.info.Defs[ident]
+		if obj == nil {
+			obj = sr.info.Uses[ident]
+		}
+		if obj == nil {
+			continue
+		}
+		if state, _ := sr.reference(assign.Rhs[i]); state != nil {
+			sr.locals[obj] = state
+		} else {
+			delete(sr.locals, obj)
+		}
+	}
+}
+
+// reference returns the package variable expr refers to, along with the kind
+// of reference, when expr is a pointer, slice or map sharing its memory.
+func (sr *stateReferences) reference(expr ast.Expr) (*types.Var, string) {
+	expr = ast.Unparen(expr)
+	if unary, ok := expr.(*ast.UnaryExpr); ok && unary.Op == token.AND {
+		if state := sr.root(unary.X); state != nil {
+			return state, "a pointer"
+		}
+		return nil, ""
+	}
+
+	state := sr.root(expr)
+	if state == nil {
+		return nil, ""
+	}
+	var kind string
+	if tv, ok := sr.info.Types[expr]; ok && tv.Type != nil {
+		switch tv.Type.Underlying().(type) {
+		case *types.Pointer:
+			kind = "pointer"
+		case *types.Slice:
+			kind = "slice"
+		case *types.Map:
+			kind = "map"
+		}
+	}
+	if kind == "" {
+		if ident, ok := expr.(*ast.Ident); ok {
+			if typ, ok := sr.declared[sr.info.Uses[ident]]; ok {
+				kind = referenceKind(typ)
+			}
+		}
+	}
+	if kind == "" {
+		return nil, ""
+	}
+	return state, "a " + kind
+}
+
+// root returns the package variable at the root of a selector and index
+// chain such as `config.Items[0].Name`, following the local variables
+// holding references.
+func (sr *stateReferences) root(expr ast.Expr) *types.Var {
+	for {
+		switch e := ast.Unparen(expr).(type) {
+		case *ast.Ident:
+			obj := sr.info.Uses[e]
+			if state, ok := sr.locals[obj]; ok {
+				return state
+			}
+			v, ok := obj.(*types.Var)
+			if !ok || v.Parent() != sr.scope {
+				return nil
+			}
+			return v
+		case *ast.SelectorExpr:
+			expr = e.X
+		case *ast.IndexExpr:
+			expr = e.X
+		case *ast.StarExpr:
+			expr = e.X
+		default:
+			return nil
+		}
+	}
+}
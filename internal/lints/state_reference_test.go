@@ -0,0 +1,170 @@
+package lints
+
+import (
+	"testing"
+
+	tt "github.com/gnolang/tlin/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetectStateReferenceReturns(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name     string
+		files    map[string]string
+		messages []string
+	}{
+		{
+			name: "references into the state",
+			files: map[string]string{
+				"gno.land/r/demo/config/config.gno": `package config
+
+type Config struct {
+	Admin   string
+	Limits  []int
+	Members map[string]bool
+	Nested  struct{ Level int }
+}
+
+var config Config
+
+var history []string
+
+func GetConfig() *Config {
+	return &config
+}
+
+func GetLevel() *int {
+	return &config.Nested.Level
+}
+
+func GetLimits() []int {
+	return config.Limits
+}
+
+func GetMembers() map[string]bool {
+	return config.Members
+}
+
+func GetHistory() interface{} {
+	return &history[0]
+}
+
+func Alias() *Config {
+	c := &config
+	return c
+}
+`,
+			},
+			messages: []string{
+				"exported function GetConfig returns a pointer referencing the realm state config",
+				"exported function GetLevel returns a pointer referencing the realm state config",
+				"exported function GetLimits returns a slice referencing the realm state config",
+				"exported function GetMembers returns a map referencing the realm state config",
+				"exported function GetHistory returns a pointer referencing the realm state history",
+				"exported function Alias returns a pointer referencing the realm state config",
+			},
+		},
+		{
+			name: "state declared in another file",
+			files: map[string]string{
+				"gno.land/r/demo/board/state.gno": `package board
+
+import "gno.land/p/demo/avl"
+
+var posts *avl.Tree
+`,
+				"gno.land/r/demo/board/board.gno": `package board
+
+import "gno.land/p/demo/avl"
+
+func Posts() *avl.Tree {
+	return posts
+}
+`,
+			},
+			messages: []string{
+				"exported function Posts returns a pointer referencing the realm state posts",
+			},
+		},
+		{
+			name: "copies, values and fresh allocations",
+			files: map[string]string{
+				"gno.land/r/demo/config/config.gno": `package config
+
+type Config struct {
+	Admin  string
+	Limits []int
+}
+
+var config Config
+
+func GetConfig() Config {
+	return config
+}
+
+func CopyConfig() *Config {
+	localCopy := config
+	return &localCopy
+}
+
+func Limits() []int {
+	return append([]int(nil), config.Limits...)
+}
+
+func Admin() string {
+	return config.Admin
+}
+
+func New() *Config {
+	c := &Config{}
+	return c
+}
+
+func Reassigned() *Config {
+	c := &config
+	c = &Config{}
+	return c
+}
+
+func getConfig() *Config {
+	return &config
+}
+`,
+			},
+		},
+		{
+			name: "library package",
+			files: map[string]string{
+				"gno.land/p/demo/config/config.gno": `package config
+
+type Config struct{}
+
+var config Config
+
+func GetConfig() *Config {
+	return &config
+}
+`,
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			issues, err := DetectStateReferenceReturns(parsePackage(t, tc.files), tt.SeverityWarning)
+			require.NoError(t, err)
+
+			var messages []string
+			for _, issue := range issues {
+				assert.Equal(t, "state-reference-return", issue.Rule)
+				require.Len(t, issue.Related, 1)
+				messages = append(messages, issue.Message)
+			}
+			assert.Equal(t, tc.messages, messages)
+		})
+	}
+}
@@ -0,0 +1,137 @@
+package lints
+
+import (
+	"go/ast"
+	"go/token"
+	"path"
+	"strconv"
+
+	"github.com/gnolang/tlin/internal/messages"
+	"github.com/gnolang/tlin/internal/stdapi"
+	tt "github.com/gnolang/tlin/internal/types"
+)
+
+var (
+	stdAPIRemovedMessage = messages.Define("std-api.removed",
+		"{name} was removed in gno {version}, use {replacement} instead")
+	stdAPIDroppedMessage = messages.Define("std-api.dropped",
+		"{name} was removed in gno {version}")
+	stdAPIUnknownMessage = messages.Define("std-api.unknown",
+		"{name} does not exist in gno {version}")
+	stdAPIArgumentsMessage = messages.Define("std-api.arguments",
+		"wrong number of arguments in call to {name} for gno {version}: got {got}, want {want}")
+	stdAPIVariadicArgumentsMessage = messages.Define("std-api.variadic-arguments",
+		"wrong number of arguments in call to {name} for gno {version}: got {got}, want at least {want}")
+)
+
+// DetectStdAPIMisuse reports the uses of the standard packages described by
+// the manifest that do not match the gno version it is for: names removed
+// from the package, with their replacement, names the package does not
+// declare, and calls whose number of arguments does not match the signature
+// of the function.
+//
+// Calls passing the results of another call as their only argument are not
+// counted, since the number of results is not known.
+func DetectStdAPIMisuse(filename string, node *ast.File, fset *token.FileSet, severity tt.Severity, manifest *stdapi.Manifest) ([]tt.Issue, error) {
+	// local names of the imported standard packages
+	imported := make(map[string]string)
+	for _, imp := range node.Imports {
+		importPath, err := strconv.Unquote(imp.Path.Value)
+		if err != nil {
+			continue
+		}
+		if _, ok := manifest.Packages[importPath]; !ok {
+			continue
+		}
+		name := path.Base(importPath)
+		if imp.Name != nil {
+			name = imp.Name.Name
+		}
+		if name != "_" && name != "." {
+			imported[name] = importPath
+		}
+	}
+	if len(imported) == 0 {
+		return nil, nil
+	}
+
+	// packageMember returns the package, the name and the display name of
+	// the member selected by expr, when it is one of an imported package.
+	packageMember := func(expr ast.Expr) (*stdapi.Package, string, string, bool) {
+		sel, ok := expr.(*ast.SelectorExpr)
+		if !ok {
+			return nil, "", "", false
+		}
+		x, ok := sel.X.(*ast.Ident)
+		// locals shadowing the package resolve to their declaration
+		if !ok || x.Obj != nil {
+			return nil, "", "", false
+		}
+		importPath, ok := imported[x.Name]
+		if !ok {
+			return nil, "", "", false
+		}
+		return manifest.Packages[importPath], sel.Sel.Name, x.Name + "." + sel.Sel.Name, true
+	}
+
+	var issues []tt.Issue
+	report := func(n ast.Node, message messages.Message, args messages.Args, suggestion string) {
+		args["version"] = manifest.Version
+		issue := tt.Issue{
+			Rule:       "std-api",
+			Filename:   filename,
+			Start:      fset.Position(n.Pos()),
+			End:        fset.Position(n.End()),
+			Suggestion: suggestion,
+			Severity:   severity,
+		}
+		message.Set(&issue, args)
+		issues = append(issues, issue)
+	}
+
+	ast.Inspect(node, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.SelectorExpr:
+			pkg, name, display, ok := packageMember(n)
+			if !ok {
+				return true
+			}
+			if replacement, removed := pkg.Removed[name]; removed {
+				if replacement == "" {
+					report(n, stdAPIDroppedMessage, messages.Args{"name": display}, "")
+				} else {
+					qualified := n.X.(*ast.Ident).Name + "." + replacement
+					report(n, stdAPIRemovedMessage, messages.Args{"name": display, "replacement": qualified}, qualified)
+				}
+			} else if !pkg.Declares(name) {
+				report(n, stdAPIUnknownMessage, messages.Args{"name": display}, "")
+			}
+		case *ast.CallExpr:
+			pkg, name, display, ok := packageMember(n.Fun)
+			if !ok {
+				return true
+			}
+			sig, ok := pkg.Functions[name]
+			if !ok || n.Ellipsis.IsValid() || isMultiValueArgument(n, sig) || sig.Accepts(len(n.Args)) {
+				return true
+			}
+			message, want := stdAPIArgumentsMessage, sig.Params
+			if sig.Variadic {
+				message, want = stdAPIVariadicArgumentsMessage, sig.Params-1
+			}
+			report(n, message, messages.Args{"name": display, "got": len(n.Args), "want": want}, "")
+		}
+		return true
+	})
+	return issues, nil
+}
+
+// isMultiValueArgument reports whether call may pass the results of another
+// call as its arguments, as in f(g()).
+func isMultiValueArgument(call *ast.CallExpr, sig stdapi.Signature) bool {
+	if len(call.Args) != 1 || sig.Params < 2 {
+		return false
+	}
+	_, ok := ast.Unparen(call.Args[0]).(*ast.CallExpr)
+	return ok
+}
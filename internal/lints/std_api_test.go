@@ -0,0 +1,88 @@
+package lints
+
+import (
+	"go/parser"
+	"go/token"
+	"testing"
+
+	"github.com/gnolang/tlin/internal/stdapi"
+	tt "github.com/gnolang/tlin/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetectStdAPIMisuse(t *testing.T) {
+	t.Parallel()
+	code := `package foo
+
+import (
+	"std"
+
+	chain "std"
+)
+
+func Transfer(to std.Address) {
+	caller := std.GetOrigCaller()
+	if std.IsOriginCall() {
+		std.Emit()
+	}
+	std.Emit("Transfer", "from", caller.String(), "to", to.String())
+	banker := chain.GetBanker(chain.BankerTypeOrigSend, 1)
+	_ = std.OriginCaller()
+	_ = std.PrevRealm
+	_ = std.Unknown
+	std.CallerAt(split())
+	std.Emit(attrs()...)
+}
+
+func local() {
+	std := struct{ GetOrigCaller func() }{}
+	std.GetOrigCaller()
+}
+`
+	fset := token.NewFileSet()
+	node, err := parser.ParseFile(fset, "test.gno", code, parser.ParseComments)
+	require.NoError(t, err)
+
+	tests := []struct {
+		version  string
+		messages []string
+	}{
+		{
+			version: "0.1",
+			messages: []string{
+				"wrong number of arguments in call to std.Emit for gno 0.1: got 0, want at least 1",
+				"wrong number of arguments in call to chain.GetBanker for gno 0.1: got 2, want 1",
+				"std.OriginCaller does not exist in gno 0.1",
+				"std.Unknown does not exist in gno 0.1",
+				"std.CallerAt does not exist in gno 0.1",
+			},
+		},
+		{
+			version: "0.2",
+			messages: []string{
+				"std.GetOrigCaller was removed in gno 0.2, use std.OriginCaller instead",
+				"std.IsOriginCall was removed in gno 0.2",
+				"wrong number of arguments in call to std.Emit for gno 0.2: got 0, want at least 1",
+				"chain.GetBanker was removed in gno 0.2, use chain.NewBanker instead",
+				"chain.BankerTypeOrigSend was removed in gno 0.2, use chain.BankerTypeOriginSend instead",
+				"std.PrevRealm was removed in gno 0.2, use std.PreviousRealm instead",
+				"std.Unknown does not exist in gno 0.2",
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		manifest, ok := stdapi.Lookup(tc.version)
+		require.True(t, ok)
+		issues, err := DetectStdAPIMisuse("test.gno", node, fset, tt.SeverityWarning, manifest)
+		require.NoError(t, err)
+
+		var messages []string
+		for _, issue := range issues {
+			assert.Equal(t, "std-api", issue.Rule)
+			messages = append(messages, issue.Message)
+		}
+		assert.Equal(t, tc.messages, messages, tc.version)
+	}
+}
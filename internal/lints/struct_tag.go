@@ -0,0 +1,189 @@
+package lints
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"path"
+	"strconv"
+	"strings"
+
+	tt "github.com/gnolang/tlin/internal/types"
+)
+
+// jsonTagOptions are the options understood by the json encoder.
+var jsonTagOptions = map[string]bool{
+	"omitempty": true,
+	"omitzero":  true,
+	"string":    true,
+}
+
+var (
+	errTagPairSyntax  = errors.New("bad syntax for struct tag pair")
+	errTagValueSyntax = errors.New("bad syntax for struct tag value")
+)
+
+// DetectStructTagIssues validates the tags of struct fields: their key:"value"
+// syntax, the options and names of json tags, and tags set on unexported
+// fields that encoders never see. Types whose name matches one of
+// taggedTypes (path.Match patterns) must tag all their exported fields.
+func DetectStructTagIssues(filename string, node *ast.File, fset *token.FileSet, severity tt.Severity, taggedTypes []string) ([]tt.Issue, error) {
+	var issues []tt.Issue
+	report := func(n ast.Node, format string, args ...interface{}) {
+		issues = append(issues, tt.Issue{
+			Rule:     "struct-tag",
+			Filename: filename,
+			Start:    fset.Position(n.Pos()),
+			End:      fset.Position(n.End()),
+			Message:  fmt.Sprintf(format, args...),
+			Severity: severity,
+		})
+	}
+
+	ast.Inspect(node, func(n ast.Node) bool {
+		spec, ok := n.(*ast.TypeSpec)
+		if !ok {
+			return true
+		}
+		st, ok := spec.Type.(*ast.StructType)
+		if !ok {
+			return true
+		}
+
+		requireTags := matchesAny(spec.Name.Name, taggedTypes)
+		names := make(map[string]*ast.Field)
+		for _, field := range st.Fields.List {
+			if field.Tag == nil {
+				if hasExportedName(field) {
+					// encoded under its own name
+					names[field.Names[0].Name] = field
+					if requireTags {
+						report(field.Names[0], "exported field %s of %s has no json tag", field.Names[0].Name, spec.Name.Name)
+					}
+				}
+				continue
+			}
+
+			tag, err := strconv.Unquote(field.Tag.Value)
+			if err != nil {
+				report(field.Tag, "struct tag is not a valid string literal")
+				continue
+			}
+			pairs, err := parseStructTag(tag)
+			if err != nil {
+				report(field.Tag, "%s: %s", err, tag)
+				continue
+			}
+
+			jsonTag, hasJSON := pairs["json"]
+			if !hasJSON {
+				continue
+			}
+			if len(field.Names) > 0 && !hasExportedName(field) {
+				report(field.Tag, "field %s has a json tag but is unexported, so it is never serialized", field.Names[0].Name)
+				continue
+			}
+
+			name, options, _ := strings.Cut(jsonTag, ",")
+			if strings.TrimSpace(name) != name {
+				report(field.Tag, "json name %q has leading or trailing spaces", name)
+			}
+			if options != "" {
+				for _, option := range strings.Split(options, ",") {
+					switch {
+					case strings.TrimSpace(option) != option:
+						report(field.Tag, "json tag option %q has leading or trailing spaces", option)
+					case !jsonTagOptions[option]:
+						report(field.Tag, "unknown json tag option %q", option)
+					}
+				}
+			}
+
+			if name == "-" && options == "" {
+				continue
+			}
+			if name == "" && len(field.Names) > 0 {
+				name = field.Names[0].Name
+			}
+			if name == "" {
+				// embedded field without a name, flattened by the encoder
+				continue
+			}
+			if prev, dup := names[name]; dup {
+				report(field.Tag, "json name %q is already used by field %s", name, fieldName(prev))
+				continue
+			}
+			names[name] = field
+		}
+		return true
+	})
+	return issues, nil
+}
+
+// parseStructTag splits a tag into its key:"value" pairs, following the
+// conventions of reflect.StructTag.
+func parseStructTag(tag string) (map[string]string, error) {
+	pairs := make(map[string]string)
+	for tag != "" {
+		i := 0
+		for i < len(tag) && tag[i] == ' ' {
+			i++
+		}
+		tag = tag[i:]
+		if tag == "" {
+			break
+		}
+
+		i = 0
+		for i < len(tag) && tag[i] > ' ' && tag[i] != ':' && tag[i] != '"' && tag[i] != 0x7f {
+			i++
+		}
+		if i == 0 || i+1 >= len(tag) || tag[i] != ':' || tag[i+1] != '"' {
+			return nil, errTagPairSyntax
+		}
+		key := tag[:i]
+		tag = tag[i+1:]
+
+		i = 1
+		for i < len(tag) && tag[i] != '"' {
+			if tag[i] == '\\' {
+				i++
+			}
+			i++
+		}
+		if i >= len(tag) {
+			return nil, errTagValueSyntax
+		}
+		value, err := strconv.Unquote(tag[:i+1])
+		if err != nil {
+			return nil, errTagValueSyntax
+		}
+		tag = tag[i+1:]
+		if tag != "" && tag[0] != ' ' {
+			return nil, errTagPairSyntax
+		}
+		pairs[key] = value
+	}
+	return pairs, nil
+}
+
+func hasExportedName(field *ast.Field) bool {
+	return len(field.Names) > 0 && field.Names[0].IsExported()
+}
+
+func fieldName(field *ast.Field) string {
+	if len(field.Names) > 0 {
+		return field.Names[0].Name
+	}
+	return embeddedName(field.Type)
+}
+
+func matchesAny(name string, patterns []string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,115 @@
+package lints
+
+import (
+	"go/parser"
+	"go/token"
+	"testing"
+
+	tt "github.com/gnolang/tlin/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetectStructTagIssues(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name        string
+		code        string
+		taggedTypes []string
+		messages    []string
+		lines       []int
+	}{
+		{
+			name: "valid tags",
+			code: "package foo\n\n" +
+				"type User struct {\n" +
+				"\tName  string `json:\"name,omitempty\" amino:\"name\"`\n" +
+				"\tEmail string `json:\",omitempty\"`\n" +
+				"\tSkip  int    `json:\"-\"`\n" +
+				"\tDash  int    `json:\"-,\"`\n" +
+				"\tBase\n" +
+				"\tplain int\n" +
+				"}\n",
+		},
+		{
+			name: "malformed syntax",
+			code: "package foo\n\n" +
+				"type T struct {\n" +
+				"\tA int `json:name`\n" +
+				"\tB int `json:\"b\"amino:\"b\"`\n" +
+				"\tC int `json:\"c`\n" +
+				"}\n",
+			messages: []string{
+				"bad syntax for struct tag pair: json:name",
+				`bad syntax for struct tag pair: json:"b"amino:"b"`,
+				`bad syntax for struct tag value: json:"c`,
+			},
+			lines: []int{4, 5, 6},
+		},
+		{
+			name: "json names and options",
+			code: "package foo\n\n" +
+				"type T struct {\n" +
+				"\tA int `json:\"name,omitempty \"`\n" +
+				"\tB int `json:\"b,omitempy\"`\n" +
+				"\tC int `json:\" c\"`\n" +
+				"\tD int `json:\"name\"`\n" +
+				"\tE int\n" +
+				"\tF int `json:\"E\"`\n" +
+				"}\n",
+			messages: []string{
+				`json tag option "omitempty " has leading or trailing spaces`,
+				`unknown json tag option "omitempy"`,
+				`json name " c" has leading or trailing spaces`,
+				`json name "name" is already used by field A`,
+				`json name "E" is already used by field E`,
+			},
+			lines: []int{4, 5, 6, 7, 9},
+		},
+		{
+			name: "tag on unexported field",
+			code: "package foo\n\n" +
+				"type T struct {\n" +
+				"\tbalance int `json:\"balance\"`\n" +
+				"\tother   int `amino:\"other\"`\n" +
+				"}\n",
+			messages: []string{"field balance has a json tag but is unexported, so it is never serialized"},
+			lines:    []int{4},
+		},
+		{
+			name: "all fields tagged for matching types",
+			code: "package foo\n\n" +
+				"type OrderState struct {\n" +
+				"\tID     int `json:\"id\"`\n" +
+				"\tAmount int\n" +
+				"\tnote   string\n" +
+				"}\n\n" +
+				"type Config struct {\n" +
+				"\tDebug bool\n" +
+				"}\n",
+			taggedTypes: []string{"*State"},
+			messages:    []string{"exported field Amount of OrderState has no json tag"},
+			lines:       []int{5},
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			fset := token.NewFileSet()
+			node, err := parser.ParseFile(fset, "test.gno", tc.code, 0)
+			require.NoError(t, err)
+
+			issues, err := DetectStructTagIssues("test.gno", node, fset, tt.SeverityWarning, tc.taggedTypes)
+			require.NoError(t, err)
+			require.Len(t, issues, len(tc.messages))
+
+			for i, issue := range issues {
+				assert.Equal(t, "struct-tag", issue.Rule)
+				assert.Equal(t, tc.messages[i], issue.Message)
+				assert.Equal(t, tc.lines[i], issue.Start.Line)
+			}
+		})
+	}
+}
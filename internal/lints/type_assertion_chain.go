@@ -0,0 +1,254 @@
+package lints
+
+import (
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/printer"
+	"go/token"
+	"go/types"
+	"strings"
+
+	tt "github.com/gnolang/tlin/internal/types"
+)
+
+const minTypeAssertionChain = 3
+
+// typeAssertionBranch is an `if v, ok := x.(T); ok` branch of an if/else-if chain.
+type typeAssertionBranch struct {
+	stmt  *ast.IfStmt
+	value string // "_" when the asserted value is discarded
+	typ   ast.Expr
+	ok    string
+}
+
+// typeAssertionChain is a run of branches asserting the same expression.
+type typeAssertionChain struct {
+	expr     ast.Expr
+	branches []typeAssertionBranch
+	rest     ast.Stmt // final else, a block or an if statement
+	preludes bool     // statements run between two branches
+}
+
+// DetectTypeAssertionChains reports if/else-if chains of three or more
+// comma-ok type assertions on the same expression, which read better as a
+// type switch asserting it once. Else blocks starting with short statements
+// that do not use the asserted values before the next if are part of the
+// chain. A type switch is suggested when the branches bind the asserted
+// value under a single name and do not read the ok variable.
+func DetectTypeAssertionChains(filename string, node *ast.File, fset *token.FileSet, severity tt.Severity) ([]tt.Issue, error) {
+	var issues []tt.Issue
+	seen := make(map[*ast.IfStmt]bool)
+	ast.Inspect(node, func(n ast.Node) bool {
+		ifStmt, ok := n.(*ast.IfStmt)
+		if !ok || seen[ifStmt] {
+			return true
+		}
+		chain := typeAssertionChainOf(ifStmt)
+		for _, b := range chain.branches {
+			seen[b.stmt] = true
+		}
+		if len(chain.branches) < minTypeAssertionChain {
+			return true
+		}
+
+		expr := types.ExprString(chain.expr)
+		var related []tt.Location
+		for _, b := range chain.branches[1:] {
+			related = append(related, tt.Location{Position: fset.Position(b.stmt.Pos()), Label: "asserted again as " + types.ExprString(b.typ)})
+		}
+		issue := tt.Issue{
+			Rule:     "type-assertion-chain",
+			Filename: filename,
+			Start:    fset.Position(ifStmt.Pos()),
+			End:      fset.Position(ifStmt.End()),
+			Message:  fmt.Sprintf("%s is type asserted %d times in an if/else-if chain", expr, len(chain.branches)),
+			Note:     fmt.Sprintf("use a type switch: switch v := %s.(type)", expr),
+			Severity: severity,
+			Related:  related,
+		}
+		if suggestion, ok := typeSwitchSuggestion(chain, fset, node.Comments); ok {
+			issue.Suggestion = suggestion
+			issue.Confidence = 0.8
+		}
+		issues = append(issues, issue)
+		return true
+	})
+	return issues, nil
+}
+
+// typeAssertionChainOf follows the else branches of ifStmt as long as they
+// assert the same expression as the first one.
+func typeAssertionChainOf(ifStmt *ast.IfStmt) typeAssertionChain {
+	var chain typeAssertionChain
+	current := ifStmt
+	for {
+		b, expr, ok := typeAssertionBranchOf(current)
+		if !ok || (chain.expr != nil && types.ExprString(expr) != types.ExprString(chain.expr)) {
+			if len(chain.branches) > 0 {
+				chain.rest = current
+			}
+			return chain
+		}
+		if chain.expr == nil {
+			chain.expr = expr
+		}
+		chain.branches = append(chain.branches, b)
+
+		switch els := current.Else.(type) {
+		case nil:
+			return chain
+		case *ast.IfStmt:
+			current = els
+		case *ast.BlockStmt:
+			next, ok := chainContinuation(els, chain)
+			if !ok {
+				chain.rest = els
+				return chain
+			}
+			chain.preludes = true
+			current = next
+		}
+	}
+}
+
+// chainContinuation matches an else block made of short statements followed
+// by a single if statement, the statements using neither the asserted
+// expression nor the values asserted so far.
+func chainContinuation(block *ast.BlockStmt, chain typeAssertionChain) (*ast.IfStmt, bool) {
+	if len(block.List) < 2 {
+		return nil, false
+	}
+	next, ok := block.List[len(block.List)-1].(*ast.IfStmt)
+	if !ok {
+		return nil, false
+	}
+	root := rootIdent(chain.expr)
+	for _, stmt := range block.List[:len(block.List)-1] {
+		switch stmt.(type) {
+		case *ast.AssignStmt, *ast.ExprStmt, *ast.DeclStmt, *ast.IncDecStmt:
+		default:
+			return nil, false
+		}
+		if root != "" && mentions(stmt, root) {
+			return nil, false
+		}
+		for _, b := range chain.branches {
+			if b.value != "_" && mentions(stmt, b.value) {
+				return nil, false
+			}
+		}
+	}
+	return next, true
+}
+
+// typeAssertionBranchOf matches `if v, ok := x.(T); ok`.
+func typeAssertionBranchOf(ifStmt *ast.IfStmt) (typeAssertionBranch, ast.Expr, bool) {
+	assign, ok := ifStmt.Init.(*ast.AssignStmt)
+	if !ok || assign.Tok != token.DEFINE || len(assign.Lhs) != 2 || len(assign.Rhs) != 1 {
+		return typeAssertionBranch{}, nil, false
+	}
+	assertion, ok := assign.Rhs[0].(*ast.TypeAssertExpr)
+	if !ok || assertion.Type == nil {
+		return typeAssertionBranch{}, nil, false
+	}
+	value, ok1 := assign.Lhs[0].(*ast.Ident)
+	okVar, ok2 := assign.Lhs[1].(*ast.Ident)
+	cond, ok3 := ifStmt.Cond.(*ast.Ident)
+	if !ok1 || !ok2 || !ok3 || cond.Name != okVar.Name {
+		return typeAssertionBranch{}, nil, false
+	}
+	return typeAssertionBranch{stmt: ifStmt, value: value.Name, typ: assertion.Type, ok: okVar.Name}, assertion.X, true
+}
+
+// rootIdent returns the variable an expression such as `a.b.c` starts from.
+func rootIdent(expr ast.Expr) string {
+	for {
+		switch e := expr.(type) {
+		case *ast.Ident:
+			return e.Name
+		case *ast.SelectorExpr:
+			expr = e.X
+		case *ast.ParenExpr:
+			expr = e.X
+		case *ast.StarExpr:
+			expr = e.X
+		default:
+			return ""
+		}
+	}
+}
+
+func typeSwitchSuggestion(chain typeAssertionChain, fset *token.FileSet, comments []*ast.CommentGroup) (string, bool) {
+	if chain.preludes || rootIdent(chain.expr) == "" {
+		// the expression is evaluated once by a switch, calls could differ
+		return "", false
+	}
+
+	value := "_"
+	for _, b := range chain.branches {
+		if b.value == "_" {
+			continue
+		}
+		if value != "_" && value != b.value {
+			return "", false
+		}
+		value = b.value
+	}
+	for _, b := range chain.branches {
+		if b.ok != "_" && mentions(b.stmt.Body, b.ok) {
+			return "", false
+		}
+	}
+
+	var sb strings.Builder
+	if value == "_" {
+		fmt.Fprintf(&sb, "switch %s.(type) {\n", types.ExprString(chain.expr))
+	} else {
+		fmt.Fprintf(&sb, "switch %s := %s.(type) {\n", value, types.ExprString(chain.expr))
+	}
+	writeClause := func(header string, node ast.Node) bool {
+		body, err := formatClauseBody(node, fset, comments)
+		if err != nil {
+			return false
+		}
+		sb.WriteString(header + "\n")
+		if body != "" {
+			sb.WriteString(body + "\n")
+		}
+		return true
+	}
+	for _, b := range chain.branches {
+		if !writeClause("case "+types.ExprString(b.typ)+":", b.stmt.Body) {
+			return "", false
+		}
+	}
+	if chain.rest != nil && !writeClause("default:", chain.rest) {
+		return "", false
+	}
+	sb.WriteString("}")
+	return sb.String(), true
+}
+
+// formatClauseBody prints the statements of a block, or a single statement,
+// indented as the body of a case clause.
+func formatClauseBody(node ast.Node, fset *token.FileSet, comments []*ast.CommentGroup) (string, error) {
+	var buf strings.Builder
+	if err := format.Node(&buf, fset, &printer.CommentedNode{Node: node, Comments: comments}); err != nil {
+		return "", err
+	}
+	text := buf.String()
+	if _, ok := node.(*ast.BlockStmt); ok {
+		text = cleanUpResult(text)
+	}
+	if strings.TrimSpace(text) == "" {
+		return "", nil
+	}
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		if line != "" {
+			lines[i] = "\t" + line
+		}
+	}
+	return strings.Join(lines, "\n"), nil
+}
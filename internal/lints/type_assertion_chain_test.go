@@ -0,0 +1,192 @@
+package lints
+
+import (
+	"go/parser"
+	"go/token"
+	"testing"
+
+	tt "github.com/gnolang/tlin/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetectTypeAssertionChains(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name       string
+		code       string
+		messages   []string
+		suggestion string
+	}{
+		{
+			name: "chain with a single value name",
+			code: `package foo
+
+func describe(x interface{}) string {
+	if v, ok := x.(int); ok {
+		return itoa(v)
+	} else if v, ok := x.(string); ok {
+		// already a string
+		return v
+	} else if _, ok := x.(bool); ok {
+		return "bool"
+	} else {
+		return "unknown"
+	}
+}
+`,
+			messages: []string{"x is type asserted 3 times in an if/else-if chain"},
+			suggestion: `switch v := x.(type) {
+case int:
+	return itoa(v)
+case string:
+	// already a string
+	return v
+case bool:
+	return "bool"
+default:
+	return "unknown"
+}`,
+		},
+		{
+			name: "trailing condition becomes the default clause",
+			code: `package foo
+
+func kind(n Node, strict bool) string {
+	if _, ok := n.(*Leaf); ok {
+		return "leaf"
+	} else if _, ok := n.(*Branch); ok {
+		return "branch"
+	} else if _, ok := n.(*Root); ok {
+		return "root"
+	} else if strict {
+		panic("unknown node")
+	}
+	return ""
+}
+`,
+			messages: []string{"n is type asserted 3 times in an if/else-if chain"},
+			suggestion: `switch n.(type) {
+case *Leaf:
+	return "leaf"
+case *Branch:
+	return "branch"
+case *Root:
+	return "root"
+default:
+	if strict {
+		panic("unknown node")
+	}
+}`,
+		},
+		{
+			name: "different value names",
+			code: `package foo
+
+func size(x interface{}) int {
+	if s, ok := x.(string); ok {
+		return len(s)
+	} else if b, ok := x.([]byte); ok {
+		return len(b)
+	} else if r, ok := x.([]rune); ok {
+		return len(r)
+	}
+	return 0
+}
+`,
+			messages: []string{"x is type asserted 3 times in an if/else-if chain"},
+		},
+		{
+			name: "intervening statements",
+			code: `package foo
+
+func handle(msg interface{}) {
+	if m, ok := msg.(Ping); ok {
+		pong(m)
+	} else {
+		count++
+		if m, ok := msg.(Join); ok {
+			join(m)
+		} else if m, ok := msg.(Leave); ok {
+			leave(m)
+		}
+	}
+}
+`,
+			messages: []string{"msg is type asserted 3 times in an if/else-if chain"},
+		},
+		{
+			name: "ok read in a branch",
+			code: `package foo
+
+func check(x interface{}) bool {
+	if _, ok := x.(A); ok {
+		return ok
+	} else if _, ok := x.(B); ok {
+		return true
+	} else if _, ok := x.(C); ok {
+		return true
+	}
+	return false
+}
+`,
+			messages: []string{"x is type asserted 3 times in an if/else-if chain"},
+		},
+		{
+			name: "short or mixed chains",
+			code: `package foo
+
+func f(x, y interface{}) {
+	if _, ok := x.(A); ok {
+		a()
+	} else if _, ok := x.(B); ok {
+		b()
+	}
+
+	if _, ok := x.(A); ok {
+		a()
+	} else if _, ok := y.(B); ok {
+		b()
+	} else if _, ok := x.(C); ok {
+		c()
+	}
+
+	if _, ok := x.(A); ok {
+		a()
+	} else {
+		use(x)
+		if _, ok := x.(B); ok {
+			b()
+		} else if _, ok := x.(C); ok {
+			c()
+		}
+	}
+}
+`,
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			fset := token.NewFileSet()
+			node, err := parser.ParseFile(fset, "test.gno", tc.code, parser.ParseComments)
+			require.NoError(t, err)
+
+			issues, err := DetectTypeAssertionChains("test.gno", node, fset, tt.SeverityInfo)
+			require.NoError(t, err)
+
+			var messages []string
+			for _, issue := range issues {
+				assert.Equal(t, "type-assertion-chain", issue.Rule)
+				messages = append(messages, issue.Message)
+			}
+			assert.Equal(t, tc.messages, messages)
+			if len(issues) == 1 {
+				assert.Equal(t, tc.suggestion, issues[0].Suggestion)
+				assert.Len(t, issues[0].Related, 2)
+			}
+		})
+	}
+}
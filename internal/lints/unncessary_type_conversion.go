@@ -3,25 +3,15 @@ package lints
 import (
 	"fmt"
 	"go/ast"
-	"go/importer"
 	"go/token"
 	"go/types"
 
 	tt "github.com/gnolang/tlin/internal/types"
 )
 
-func DetectUnnecessaryConversions(filename string, node *ast.File, fset *token.FileSet, severity tt.Severity) ([]tt.Issue, error) {
-	info := &types.Info{
-		Types: make(map[ast.Expr]types.TypeAndValue),
-		Uses:  make(map[*ast.Ident]types.Object),
-		Defs:  make(map[*ast.Ident]types.Object),
-	}
-
-	conf := types.Config{Importer: importer.Default()}
-	//! DO NOT CHECK ERROR HERE.
-	//! error check may broke the lint formatting process.
-	conf.Check("", fset, []*ast.File{node}, info)
-
+// DetectUnnecessaryConversions is a typed check: it relies on the type
+// information of the whole package, see CheckTyped.
+func DetectUnnecessaryConversions(filename string, node *ast.File, fset *token.FileSet, info *types.Info, severity tt.Severity) ([]tt.Issue, error) {
 	var issues []tt.Issue
 	varDecls := make(map[*types.Var]ast.Node)
 
@@ -0,0 +1,165 @@
+package lints
+
+import (
+	"fmt"
+	"go/ast"
+	"strings"
+
+	tt "github.com/gnolang/tlin/internal/types"
+)
+
+// DetectUntestedExports reports library packages exporting functions without
+// any test file, and exported functions that the test files never reference.
+//
+// This is a lexical heuristic, not coverage data: a function counts as tested
+// when its name is called from a test file, appears within the name of a
+// `TestXxx` function, or is referenced by a composite literal element as done
+// by table-driven tests. Functions only reached indirectly through other calls
+// are reported even when the tests exercise them. Realm packages and main
+// packages are not libraries and are skipped.
+func DetectUntestedExports(pkg *Package, severity tt.Severity) ([]tt.Issue, error) {
+	if !isLibraryPackage(pkg) {
+		return nil, nil
+	}
+
+	var exported []*PackageFile
+	var funcs []*ast.FuncDecl
+	var tests []*PackageFile
+	for _, file := range pkg.Files {
+		if file.IsTest() {
+			tests = append(tests, file)
+			continue
+		}
+		declared := exportedFuncs(file.File)
+		if len(declared) > 0 {
+			exported = append(exported, file)
+			funcs = append(funcs, declared...)
+		}
+	}
+	if len(funcs) == 0 {
+		return nil, nil
+	}
+
+	if len(tests) == 0 {
+		file := exported[0]
+		noun := "functions"
+		if len(funcs) == 1 {
+			noun = "function"
+		}
+		return []tt.Issue{{
+			Rule:     "untested-exports",
+			Filename: file.Filename,
+			Start:    pkg.Fset.Position(file.File.Name.Pos()),
+			End:      pkg.Fset.Position(file.File.Name.End()),
+			Message:  fmt.Sprintf("package %s exports %d %s but has no test file", pkg.Name, len(funcs), noun),
+			Severity: severity,
+		}}, nil
+	}
+
+	referenced, testNames := testReferences(tests)
+
+	var issues []tt.Issue
+	for _, fn := range funcs {
+		name := fn.Name.Name
+		if referenced[name] || mentionedInTestName(name, testNames) {
+			continue
+		}
+		issues = append(issues, tt.Issue{
+			Rule:     "untested-exports",
+			Filename: pkg.Fset.Position(fn.Pos()).Filename,
+			Start:    pkg.Fset.Position(fn.Name.Pos()),
+			End:      pkg.Fset.Position(fn.Name.End()),
+			Message:  fmt.Sprintf("exported function %s is not referenced by any test", funcDisplayName(fn)),
+			Note:     "detection is based on the names used in test files, not on coverage",
+			Severity: severity,
+		})
+	}
+	return issues, nil
+}
+
+func isLibraryPackage(pkg *Package) bool {
+	if pkg.Name == "main" {
+		return false
+	}
+	return !isRealmPackage(pkg)
+}
+
+// exportedFuncs returns the exported functions of the file, along with the
+// exported methods of exported types.
+func exportedFuncs(file *ast.File) []*ast.FuncDecl {
+	var funcs []*ast.FuncDecl
+	for _, decl := range file.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok || !fn.Name.IsExported() {
+			continue
+		}
+		if fn.Recv != nil {
+			typeName, _ := receiverType(fn.Recv.List[0].Type)
+			if !ast.IsExported(typeName) {
+				continue
+			}
+		}
+		funcs = append(funcs, fn)
+	}
+	return funcs
+}
+
+// testReferences collects the names called or listed in composite literals by
+// the test files, as well as the names of their test functions.
+func testReferences(tests []*PackageFile) (map[string]bool, []string) {
+	referenced := make(map[string]bool)
+	var testNames []string
+
+	refName := func(expr ast.Expr) string {
+		switch e := expr.(type) {
+		case *ast.Ident:
+			return e.Name
+		case *ast.SelectorExpr:
+			return e.Sel.Name
+		}
+		return ""
+	}
+
+	for _, file := range tests {
+		for _, decl := range file.File.Decls {
+			if fn, ok := decl.(*ast.FuncDecl); ok && fn.Recv == nil && strings.HasPrefix(fn.Name.Name, "Test") {
+				testNames = append(testNames, strings.TrimPrefix(fn.Name.Name, "Test"))
+			}
+		}
+
+		ast.Inspect(file.File, func(n ast.Node) bool {
+			switch node := n.(type) {
+			case *ast.CallExpr:
+				referenced[refName(node.Fun)] = true
+			case *ast.CompositeLit:
+				for _, elt := range node.Elts {
+					if kv, ok := elt.(*ast.KeyValueExpr); ok {
+						elt = kv.Value
+					}
+					referenced[refName(elt)] = true
+				}
+			}
+			return true
+		})
+	}
+	return referenced, testNames
+}
+
+// mentionedInTestName reports whether name appears in one of the test names,
+// as `Transfer` does in `TestTransfer` or `TestToken_TransferFrom`.
+func mentionedInTestName(name string, testNames []string) bool {
+	for _, testName := range testNames {
+		if strings.Contains(testName, name) {
+			return true
+		}
+	}
+	return false
+}
+
+func funcDisplayName(fn *ast.FuncDecl) string {
+	if fn.Recv == nil {
+		return fn.Name.Name
+	}
+	typeName, _ := receiverType(fn.Recv.List[0].Type)
+	return typeName + "." + fn.Name.Name
+}
@@ -0,0 +1,120 @@
+package lints
+
+import (
+	"testing"
+
+	tt "github.com/gnolang/tlin/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetectUntestedExports(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name     string
+		dir      string
+		files    map[string]string
+		messages []string
+	}{
+		{
+			name: "no test file",
+			dir:  "gno.land/p/demo/math",
+			files: map[string]string{
+				"math.gno": `package math
+
+func Add(a, b int) int { return a + b }
+func Sub(a, b int) int { return a - b }
+func helper() {}
+`,
+			},
+			messages: []string{"package math exports 2 functions but has no test file"},
+		},
+		{
+			name: "no test file for a single function",
+			dir:  "gno.land/p/demo/math",
+			files: map[string]string{
+				"math.gno": `package math
+
+func Add(a, b int) int { return a + b }
+`,
+			},
+			messages: []string{"package math exports 1 function but has no test file"},
+		},
+		{
+			name: "untested functions",
+			dir:  "gno.land/p/demo/token",
+			files: map[string]string{
+				"token.gno": `package token
+
+type Token struct{}
+
+func New() *Token { return &Token{} }
+func (t *Token) Transfer() {}
+func (t *Token) Burn() {}
+func Mint() {}
+func Approve() {}
+func Allowance() {}
+
+type ledger struct{}
+
+func (l *ledger) Balance() int { return 0 }
+`,
+				"token_test.gno": `package token
+
+func TestToken_TransferFrom(t *testing.T) {
+	tok := New()
+	tok.Transfer()
+}
+
+func TestMint(t *testing.T) {}
+
+func TestApprovals(t *testing.T) {
+	cases := []struct {
+		fn func()
+	}{
+		{fn: Approve},
+	}
+	_ = cases
+}
+`,
+			},
+			messages: []string{
+				"exported function Token.Burn is not referenced by any test",
+				"exported function Allowance is not referenced by any test",
+			},
+		},
+		{
+			name: "realm packages are skipped",
+			dir:  "gno.land/r/demo/foo",
+			files: map[string]string{
+				"foo.gno": "package foo\n\nfunc Render(path string) string { return \"\" }\n",
+			},
+		},
+		{
+			name: "no exported functions",
+			dir:  "gno.land/p/demo/consts",
+			files: map[string]string{
+				"consts.gno": "package consts\n\nconst Max = 10\n",
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			pkg := parsePackage(t, tc.files)
+			pkg.Dir = tc.dir
+
+			issues, err := DetectUntestedExports(pkg, tt.SeverityInfo)
+			require.NoError(t, err)
+
+			var messages []string
+			for _, issue := range issues {
+				assert.Equal(t, "untested-exports", issue.Rule)
+				messages = append(messages, issue.Message)
+			}
+			assert.Equal(t, tc.messages, messages)
+		})
+	}
+}
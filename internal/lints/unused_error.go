@@ -0,0 +1,336 @@
+package lints
+
+import (
+	"go/ast"
+	"go/token"
+
+	"github.com/gnolang/tlin/internal/analysis/cfg"
+	"github.com/gnolang/tlin/internal/messages"
+	tt "github.com/gnolang/tlin/internal/types"
+)
+
+var (
+	unusedErrorMessage = messages.Define("unused-error",
+		"error assigned to {name} is never used")
+	overwrittenErrorMessage = messages.Define("unused-error.overwritten",
+		"error assigned to {name} is overwritten before being used")
+	shadowedErrorMessage = messages.Define("unused-error.shadowed",
+		"error assigned to {name} is shadowed before being used")
+)
+
+// errorCreation is a statement assigning a newly created error to a variable.
+type errorCreation struct {
+	stmt ast.Stmt
+	name string
+}
+
+// DetectUnusedErrors reports errors created by errors.New, Errorf or by a
+// function of the file returning only an error, that are never used
+// afterwards on any path: neither returned, passed, compared nor read.
+// Errors overwritten or shadowed before being used are reported at the
+// assignment hiding them. Named results count as used when the function
+// returns, as a bare return hands them to the caller.
+func DetectUnusedErrors(filename string, node *ast.File, fset *token.FileSet, severity tt.Severity) ([]tt.Issue, error) {
+	errorFuncs := make(map[string]bool)
+	for _, decl := range node.Decls {
+		if fn, ok := decl.(*ast.FuncDecl); ok && fn.Recv == nil && returnsOnlyError(fn.Type) {
+			errorFuncs[fn.Name.Name] = true
+		}
+	}
+
+	var issues []tt.Issue
+	ast.Inspect(node, func(n ast.Node) bool {
+		var body *ast.BlockStmt
+		var typ *ast.FuncType
+		switch fn := n.(type) {
+		case *ast.FuncDecl:
+			body, typ = fn.Body, fn.Type
+		case *ast.FuncLit:
+			body, typ = fn.Body, fn.Type
+		}
+		if body != nil {
+			issues = append(issues, unusedErrorsInFunc(filename, fset, body, typ, errorFuncs, severity)...)
+		}
+		return true
+	})
+	return issues, nil
+}
+
+func unusedErrorsInFunc(
+	filename string,
+	fset *token.FileSet,
+	body *ast.BlockStmt,
+	typ *ast.FuncType,
+	errorFuncs map[string]bool,
+	severity tt.Severity,
+) []tt.Issue {
+	graph := cfg.FromStmts(body.List)
+	blocks := graph.Blocks()
+	graph.Sort(blocks)
+
+	namedResults := make(map[string]bool)
+	if typ.Results != nil {
+		for _, field := range typ.Results.List {
+			for _, name := range field.Names {
+				namedResults[name.Name] = true
+			}
+		}
+	}
+
+	var issues []tt.Issue
+	for _, stmt := range blocks {
+		creation, ok := errorCreationOf(stmt, errorFuncs)
+		if !ok || capturedByClosure(body, graph, creation.name) {
+			continue
+		}
+
+		used, kills := followError(graph, creation, namedResults[creation.name])
+		if used {
+			continue
+		}
+
+		issue := tt.Issue{
+			Rule:     "unused-error",
+			Filename: filename,
+			Start:    fset.Position(creation.stmt.Pos()),
+			End:      fset.Position(creation.stmt.End()),
+			Note:     "return, handle or explicitly discard the error",
+			Severity: severity,
+		}
+		message := unusedErrorMessage
+		if len(kills) > 0 {
+			graph.Sort(kills)
+			kill := kills[0]
+			message = overwrittenErrorMessage
+			if isDeclaration(kill) {
+				message = shadowedErrorMessage
+			}
+			issue.Start = fset.Position(kill.Pos())
+			issue.End = fset.Position(kill.End())
+			issue.Related = []tt.Location{{Position: fset.Position(creation.stmt.Pos()), Label: "error created here"}}
+		}
+		message.Set(&issue, messages.Args{"name": creation.name})
+		issues = append(issues, issue)
+	}
+	return issues
+}
+
+// followError walks the paths leaving the creation and reports whether one of
+// them uses the error, along with the statements reassigning it first.
+func followError(graph *cfg.CFG, creation errorCreation, namedResult bool) (bool, []ast.Stmt) {
+	var kills []ast.Stmt
+	visited := make(map[ast.Stmt]bool)
+	queue := append([]ast.Stmt(nil), graph.Succs(creation.stmt)...)
+	for len(queue) > 0 {
+		stmt := queue[0]
+		queue = queue[1:]
+		if visited[stmt] {
+			continue
+		}
+		visited[stmt] = true
+
+		switch {
+		case stmt == graph.Exit:
+			if namedResult {
+				return true, nil
+			}
+			continue
+		case usesName(stmt, creation.name):
+			return true, nil
+		case reassigns(stmt, creation.name):
+			if stmt != creation.stmt {
+				kills = append(kills, stmt)
+			}
+			continue
+		}
+		if ret, ok := stmt.(*ast.ReturnStmt); ok && len(ret.Results) == 0 && namedResult {
+			return true, nil
+		}
+		queue = append(queue, graph.Succs(stmt)...)
+	}
+	return false, kills
+}
+
+// errorCreationOf matches `err := errors.New(...)`, `err = f()` where f only
+// returns an error, and `var err = ufmt.Errorf(...)`.
+func errorCreationOf(stmt ast.Stmt, errorFuncs map[string]bool) (errorCreation, bool) {
+	var lhs *ast.Ident
+	var rhs ast.Expr
+	switch s := stmt.(type) {
+	case *ast.AssignStmt:
+		if (s.Tok != token.DEFINE && s.Tok != token.ASSIGN) || len(s.Lhs) != 1 || len(s.Rhs) != 1 {
+			return errorCreation{}, false
+		}
+		lhs, _ = s.Lhs[0].(*ast.Ident)
+		rhs = s.Rhs[0]
+	case *ast.DeclStmt:
+		gen, ok := s.Decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.VAR || len(gen.Specs) != 1 {
+			return errorCreation{}, false
+		}
+		spec := gen.Specs[0].(*ast.ValueSpec)
+		if len(spec.Names) != 1 || len(spec.Values) != 1 {
+			return errorCreation{}, false
+		}
+		lhs, rhs = spec.Names[0], spec.Values[0]
+	}
+	if lhs == nil || lhs.Name == "_" {
+		return errorCreation{}, false
+	}
+
+	call, ok := rhs.(*ast.CallExpr)
+	if !ok || !createsError(call, errorFuncs) {
+		return errorCreation{}, false
+	}
+	return errorCreation{stmt: stmt, name: lhs.Name}, true
+}
+
+func createsError(call *ast.CallExpr, errorFuncs map[string]bool) bool {
+	switch fun := call.Fun.(type) {
+	case *ast.Ident:
+		return errorFuncs[fun.Name]
+	case *ast.SelectorExpr:
+		if fun.Sel.Name == "Errorf" {
+			return true
+		}
+		pkg, ok := fun.X.(*ast.Ident)
+		return ok && pkg.Name == "errors" && fun.Sel.Name == "New"
+	}
+	return false
+}
+
+func returnsOnlyError(typ *ast.FuncType) bool {
+	if typ.Results == nil || len(typ.Results.List) != 1 || len(typ.Results.List[0].Names) > 1 {
+		return false
+	}
+	ident, ok := typ.Results.List[0].Type.(*ast.Ident)
+	return ok && ident.Name == "error"
+}
+
+// capturedByClosure reports whether a closure or a deferred call of the
+// function refers to name, in which case its uses cannot be ordered.
+func capturedByClosure(body *ast.BlockStmt, graph *cfg.CFG, name string) bool {
+	for _, d := range graph.Defers {
+		if mentions(d, name) {
+			return true
+		}
+	}
+	captured := false
+	ast.Inspect(body, func(n ast.Node) bool {
+		if lit, ok := n.(*ast.FuncLit); ok {
+			captured = captured || mentions(lit.Body, name)
+			return false
+		}
+		return !captured
+	})
+	return captured
+}
+
+// usesName reports whether the statement reads name. Compound statements are
+// nodes of the control flow graph on their own, so only their header is
+// looked at, their bodies being separate nodes.
+func usesName(stmt ast.Stmt, name string) bool {
+	switch s := stmt.(type) {
+	case *ast.IfStmt:
+		return mentions(s.Cond, name)
+	case *ast.ForStmt:
+		return s.Cond != nil && mentions(s.Cond, name)
+	case *ast.RangeStmt:
+		return mentions(s.X, name)
+	case *ast.SwitchStmt:
+		return s.Tag != nil && mentions(s.Tag, name)
+	case *ast.CaseClause:
+		for _, expr := range s.List {
+			if mentions(expr, name) {
+				return true
+			}
+		}
+		return false
+	case *ast.TypeSwitchStmt, *ast.SelectStmt, *ast.CommClause, *ast.LabeledStmt, *ast.BlockStmt:
+		return false
+	case *ast.AssignStmt:
+		for _, rhs := range s.Rhs {
+			if mentions(rhs, name) {
+				return true
+			}
+		}
+		for _, lhs := range s.Lhs {
+			if ident, ok := lhs.(*ast.Ident); ok && ident.Name == name {
+				// plain writes do not read, compound assignments do
+				if s.Tok != token.ASSIGN && s.Tok != token.DEFINE {
+					return true
+				}
+				continue
+			}
+			if mentions(lhs, name) {
+				return true
+			}
+		}
+		return false
+	case *ast.DeclStmt:
+		gen, ok := s.Decl.(*ast.GenDecl)
+		if !ok {
+			return false
+		}
+		for _, spec := range gen.Specs {
+			if vs, ok := spec.(*ast.ValueSpec); ok {
+				for _, value := range vs.Values {
+					if mentions(value, name) {
+						return true
+					}
+				}
+			}
+		}
+		return false
+	}
+	return mentions(stmt, name)
+}
+
+// reassigns reports whether the statement writes to name or declares it again.
+func reassigns(stmt ast.Stmt, name string) bool {
+	switch s := stmt.(type) {
+	case *ast.AssignStmt:
+		if s.Tok != token.ASSIGN && s.Tok != token.DEFINE {
+			return false
+		}
+		for _, lhs := range s.Lhs {
+			if ident, ok := lhs.(*ast.Ident); ok && ident.Name == name {
+				return true
+			}
+		}
+	case *ast.DeclStmt:
+		gen, ok := s.Decl.(*ast.GenDecl)
+		if !ok {
+			return false
+		}
+		for _, spec := range gen.Specs {
+			if vs, ok := spec.(*ast.ValueSpec); ok {
+				for _, ident := range vs.Names {
+					if ident.Name == name {
+						return true
+					}
+				}
+			}
+		}
+	}
+	return false
+}
+
+func isDeclaration(stmt ast.Stmt) bool {
+	if assign, ok := stmt.(*ast.AssignStmt); ok {
+		return assign.Tok == token.DEFINE
+	}
+	_, ok := stmt.(*ast.DeclStmt)
+	return ok
+}
+
+func mentions(node ast.Node, name string) bool {
+	found := false
+	ast.Inspect(node, func(n ast.Node) bool {
+		if ident, ok := n.(*ast.Ident); ok && ident.Name == name {
+			found = true
+		}
+		return !found
+	})
+	return found
+}
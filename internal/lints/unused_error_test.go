@@ -0,0 +1,155 @@
+package lints
+
+import (
+	"go/parser"
+	"go/token"
+	"testing"
+
+	tt "github.com/gnolang/tlin/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetectUnusedErrors(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name     string
+		code     string
+		messages []string
+		lines    []int
+	}{
+		{
+			name: "dropped error",
+			code: `package foo
+
+func transfer(amount int) {
+	err := ufmt.Errorf("invalid amount %d", amount)
+	println(amount)
+}`,
+			messages: []string{"error assigned to err is never used"},
+			lines:    []int{4},
+		},
+		{
+			name: "used on one path only",
+			code: `package foo
+
+import "errors"
+
+func check(ok bool) error {
+	err := errors.New("failed")
+	if ok {
+		return nil
+	}
+	return err
+}`,
+		},
+		{
+			name: "checked in an if statement",
+			code: `package foo
+
+func run() {
+	if err := validate(); err != nil {
+		panic(err)
+	}
+}
+
+func validate() error { return nil }`,
+		},
+		{
+			name: "overwritten and shadowed before use",
+			code: `package foo
+
+import "errors"
+
+func first() error {
+	err := errors.New("first")
+	err = validate()
+	return err
+}
+
+func second(ok bool) error {
+	var err = errors.New("second")
+	if ok {
+		err := validate()
+		return err
+	}
+	return nil
+}
+
+func validate() error { return nil }`,
+			messages: []string{
+				"error assigned to err is overwritten before being used",
+				"error assigned to err is shadowed before being used",
+			},
+			lines: []int{7, 14},
+		},
+		{
+			name: "named result returned by a bare return",
+			code: `package foo
+
+import "errors"
+
+func named(ok bool) (err error) {
+	if !ok {
+		err = errors.New("not ok")
+		return
+	}
+	return
+}
+
+func fallsThrough() (err error) {
+	err = errors.New("end")
+	return
+}`,
+		},
+		{
+			name: "captured by a closure or a defer",
+			code: `package foo
+
+import "errors"
+
+func deferred() {
+	err := errors.New("deferred")
+	defer func() { println(err) }()
+}
+
+func closure() func() error {
+	err := errors.New("closure")
+	return func() error { return err }
+}`,
+		},
+		{
+			name: "errors created in closures",
+			code: `package foo
+
+import "errors"
+
+var handler = func() {
+	err := errors.New("dropped")
+	_ = 1
+}`,
+			messages: []string{"error assigned to err is never used"},
+			lines:    []int{6},
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			fset := token.NewFileSet()
+			node, err := parser.ParseFile(fset, "test.gno", tc.code, 0)
+			require.NoError(t, err)
+
+			issues, err := DetectUnusedErrors("test.gno", node, fset, tt.SeverityWarning)
+			require.NoError(t, err)
+			require.Len(t, issues, len(tc.messages))
+
+			for i, issue := range issues {
+				assert.Equal(t, "unused-error", issue.Rule)
+				assert.Equal(t, tc.messages[i], issue.Message)
+				assert.Equal(t, tc.lines[i], issue.Start.Line)
+			}
+		})
+	}
+}
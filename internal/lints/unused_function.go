@@ -0,0 +1,127 @@
+package lints
+
+import (
+	"fmt"
+	"go/ast"
+	"strings"
+
+	tt "github.com/gnolang/tlin/internal/types"
+)
+
+// DetectUnusedFunctions reports the unexported functions and methods
+// declared in the non-test files of the package that nothing references: no
+// call, function value, method value or method expression uses them.
+// References from test files count when includeTests is set.
+//
+// Identifiers are matched by name, as the package is not type checked. The
+// methods named like a method of an interface declared in the package are
+// assumed to implement it. Files with a //go:linkname directive are skipped,
+// and so are the functions such a directive names, since they may be used
+// without any reference in Go code.
+func DetectUnusedFunctions(pkg *Package, severity tt.Severity, includeTests bool) ([]tt.Issue, error) {
+	scanned := 0
+	linknamed := make(map[string]bool)
+	skipped := make(map[*PackageFile]bool)
+	interfaceMethods := make(map[string]bool)
+	// references by name, along with the declarations they were made from
+	refs := make(map[string][]*ast.FuncDecl)
+
+	for _, file := range pkg.Files {
+		if file.IsTest() && !includeTests {
+			continue
+		}
+		scanned++
+		for _, name := range linknames(file.File) {
+			linknamed[name] = true
+			skipped[file] = true
+		}
+
+		ast.Inspect(file.File, func(n ast.Node) bool {
+			if iface, ok := n.(*ast.InterfaceType); ok {
+				for _, method := range iface.Methods.List {
+					for _, name := range method.Names {
+						interfaceMethods[name.Name] = true
+					}
+				}
+			}
+			return true
+		})
+
+		for _, decl := range file.File.Decls {
+			fn, _ := decl.(*ast.FuncDecl)
+			ast.Inspect(decl, func(n ast.Node) bool {
+				ident, ok := n.(*ast.Ident)
+				if ok && (fn == nil || ident != fn.Name) {
+					refs[ident.Name] = append(refs[ident.Name], fn)
+				}
+				return true
+			})
+		}
+	}
+
+	var issues []tt.Issue
+	for _, file := range pkg.Files {
+		if file.IsTest() || skipped[file] {
+			continue
+		}
+		for _, decl := range file.File.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok {
+				continue
+			}
+			name := fn.Name.Name
+			if fn.Name.IsExported() || name == "_" || linknamed[name] || referencedOutside(refs[name], fn) {
+				continue
+			}
+			kind := "function " + name
+			if fn.Recv != nil {
+				if interfaceMethods[name] {
+					continue
+				}
+				typeName, _ := receiverType(fn.Recv.List[0].Type)
+				kind = "method " + typeName + "." + name
+			} else if name == "init" || name == "main" {
+				continue
+			}
+
+			files := "file"
+			if scanned > 1 {
+				files = "files"
+			}
+			issues = append(issues, tt.Issue{
+				Rule:     "unused-function",
+				Filename: file.Filename,
+				Start:    pkg.Fset.Position(fn.Name.Pos()),
+				End:      pkg.Fset.Position(fn.Name.End()),
+				Message:  fmt.Sprintf("%s is never used in the %d %s of the package", kind, scanned, files),
+				Note:     "remove it, or use it where it was meant to be used",
+				Severity: severity,
+			})
+		}
+	}
+	return issues, nil
+}
+
+// referencedOutside reports whether one of the references was made from
+// another declaration than fn, recursive calls aside.
+func referencedOutside(from []*ast.FuncDecl, fn *ast.FuncDecl) bool {
+	for _, decl := range from {
+		if decl != fn {
+			return true
+		}
+	}
+	return false
+}
+
+// linknames returns the local names of the //go:linkname directives of file.
+func linknames(file *ast.File) []string {
+	var names []string
+	for _, group := range file.Comments {
+		for _, comment := range group.List {
+			if fields := strings.Fields(comment.Text); len(fields) >= 2 && fields[0] == "//go:linkname" {
+				names = append(names, fields[1])
+			}
+		}
+	}
+	return names
+}
@@ -0,0 +1,116 @@
+package lints
+
+import (
+	"testing"
+
+	tt "github.com/gnolang/tlin/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetectUnusedFunctions(t *testing.T) {
+	t.Parallel()
+	files := map[string]string{
+		"pkg/a.gno": `package foo
+
+type store struct{}
+
+type getter interface{ get() int }
+
+func (s *store) get() int { return 0 }
+
+func (s *store) unused() {}
+
+func (s *store) viaExpr() {}
+
+func (s store) viaValue() {}
+
+func init() {}
+
+func Exported() int {
+	f := (*store).viaExpr
+	_ = f
+	g := store{}.viaValue
+	_ = g
+	return helper() + generic[int](1)
+}
+
+func helper() int { return 0 }
+
+func generic[T any](v T) int { return 0 }
+
+func recursive(n int) int {
+	if n == 0 {
+		return 0
+	}
+	return recursive(n - 1)
+}
+
+func testedOnly() {}
+`,
+		"pkg/b.gno": `package foo
+
+import _ "unsafe"
+
+//go:linkname hidden runtime.hidden
+func hidden()
+
+func alsoSkipped() {}
+`,
+		"pkg/c.gno": `package foo
+
+//go:linkname elsewhere runtime.elsewhere
+var x int
+
+func elsewhere() {}
+`,
+		"pkg/a_test.gno": `package foo
+
+func TestFoo() {
+	testedOnly()
+}
+
+func testHelper() {}
+`,
+	}
+
+	tests := []struct {
+		name         string
+		includeTests bool
+		messages     []string
+	}{
+		{
+			name:         "references from tests",
+			includeTests: true,
+			messages: []string{
+				"method store.unused is never used in the 4 files of the package",
+				"function recursive is never used in the 4 files of the package",
+			},
+		},
+		{
+			name:         "references from sources only",
+			includeTests: false,
+			messages: []string{
+				"method store.unused is never used in the 3 files of the package",
+				"function recursive is never used in the 3 files of the package",
+				"function testedOnly is never used in the 3 files of the package",
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			issues, err := DetectUnusedFunctions(parsePackage(t, files), tt.SeverityWarning, tc.includeTests)
+			require.NoError(t, err)
+
+			var messages []string
+			for _, issue := range issues {
+				assert.Equal(t, "unused-function", issue.Rule)
+				messages = append(messages, issue.Message)
+			}
+			assert.Equal(t, tc.messages, messages)
+		})
+	}
+}
@@ -0,0 +1,132 @@
+package lints
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"strings"
+
+	"github.com/gnolang/tlin/internal/messages"
+	tt "github.com/gnolang/tlin/internal/types"
+)
+
+// DefaultPureFunctions are the functions whose only effect is their
+// result, named by import path and function name.
+var DefaultPureFunctions = []string{
+	"strings.Contains", "strings.ContainsAny", "strings.Count", "strings.EqualFold",
+	"strings.Fields", "strings.HasPrefix", "strings.HasSuffix", "strings.Index",
+	"strings.Join", "strings.LastIndex", "strings.Repeat", "strings.Replace",
+	"strings.ReplaceAll", "strings.Split", "strings.SplitN", "strings.Title",
+	"strings.ToLower", "strings.ToTitle", "strings.ToUpper", "strings.Trim",
+	"strings.TrimFunc", "strings.TrimLeft", "strings.TrimPrefix", "strings.TrimRight",
+	"strings.TrimSpace", "strings.TrimSuffix",
+	"bytes.Equal", "bytes.ToLower", "bytes.ToUpper", "bytes.Trim", "bytes.TrimPrefix",
+	"bytes.TrimSpace", "bytes.TrimSuffix", "bytes.Replace", "bytes.ReplaceAll",
+	"strconv.FormatInt", "strconv.FormatUint", "strconv.Itoa", "strconv.Quote",
+	"errors.New", "fmt.Errorf", "fmt.Sprint", "fmt.Sprintf", "fmt.Sprintln",
+	"gno.land/p/demo/ufmt.Errorf", "gno.land/p/demo/ufmt.Sprint", "gno.land/p/demo/ufmt.Sprintf",
+	"std.DecodeBech32", "std.DerivePkgAddr", "std.EncodeBech32",
+}
+
+// resultReplacesArgument lists the pure functions returning a modified copy
+// of their first argument, which callers usually mean to modify in place.
+var resultReplacesArgument = map[string]bool{
+	"strings.Replace": true, "strings.ReplaceAll": true, "strings.Title": true,
+	"strings.ToLower": true, "strings.ToTitle": true, "strings.ToUpper": true,
+	"strings.Trim": true, "strings.TrimFunc": true, "strings.TrimLeft": true,
+	"strings.TrimPrefix": true, "strings.TrimRight": true, "strings.TrimSpace": true,
+	"strings.TrimSuffix": true,
+
+	"bytes.ToLower": true, "bytes.ToUpper": true, "bytes.Trim": true,
+	"bytes.TrimPrefix": true, "bytes.TrimSpace": true, "bytes.TrimSuffix": true,
+	"bytes.Replace": true, "bytes.ReplaceAll": true,
+}
+
+var unusedResultMessage = messages.Define("unused-result",
+	"result of {call} is discarded")
+
+// DetectUnusedResults reports calls to pure functions made as statements,
+// whose result is discarded. Functions are named by import path and name,
+// such as strings.TrimSpace, or by name alone for the functions of the
+// package.
+func DetectUnusedResults(filename string, node *ast.File, fset *token.FileSet, severity tt.Severity, functions []string) ([]tt.Issue, error) {
+	pure := make(map[string]bool, len(functions))
+	for _, fn := range functions {
+		pure[fn] = true
+	}
+	imports := make(map[string]string)
+	for _, imp := range node.Imports {
+		imports[importName(imp)] = strings.Trim(imp.Path.Value, `"`)
+	}
+
+	var issues []tt.Issue
+	ast.Inspect(node, func(n ast.Node) bool {
+		stmt, ok := n.(*ast.ExprStmt)
+		if !ok {
+			return true
+		}
+		call, ok := ast.Unparen(stmt.X).(*ast.CallExpr)
+		if !ok {
+			return true
+		}
+		name, display := pureCallName(call, imports)
+		if name == "" || !pure[name] {
+			return true
+		}
+
+		note := fmt.Sprintf("%s does not modify its arguments, use the result or remove the call", display)
+		if resultReplacesArgument[name] && len(call.Args) > 0 && isAssignable(call.Args[0]) {
+			arg := exprSource(fset, call.Args[0])
+			note = fmt.Sprintf("%s returns a modified copy and leaves %s unchanged, assign it back: %s = %s",
+				display, arg, arg, exprSource(fset, call))
+		}
+		issue := tt.Issue{
+			Rule:     "unused-result",
+			Filename: filename,
+			Start:    fset.Position(call.Pos()),
+			End:      fset.Position(call.End()),
+			Note:     note,
+			Severity: severity,
+		}
+		unusedResultMessage.Set(&issue, messages.Args{"call": display})
+		issues = append(issues, issue)
+		return true
+	})
+	return issues, nil
+}
+
+// pureCallName returns the name a call target has in the function list,
+// along with the way the code names it. Calls of local variables, and of
+// methods, have no name.
+func pureCallName(call *ast.CallExpr, imports map[string]string) (string, string) {
+	switch fun := call.Fun.(type) {
+	case *ast.Ident:
+		if fun.Obj != nil && fun.Obj.Kind != ast.Fun {
+			return "", ""
+		}
+		return fun.Name, fun.Name
+	case *ast.SelectorExpr:
+		x, ok := fun.X.(*ast.Ident)
+		if !ok || x.Obj != nil {
+			return "", ""
+		}
+		path, ok := imports[x.Name]
+		if !ok {
+			return "", ""
+		}
+		return path + "." + fun.Sel.Name, x.Name + "." + fun.Sel.Name
+	}
+	return "", ""
+}
+
+func isAssignable(expr ast.Expr) bool {
+	switch e := expr.(type) {
+	case *ast.Ident:
+		return e.Name != "_" && e.Obj != nil && e.Obj.Kind == ast.Var
+	case *ast.SelectorExpr, *ast.IndexExpr:
+		return true
+	case *ast.StarExpr:
+		return isAssignable(e.X)
+	}
+	return false
+}
@@ -0,0 +1,70 @@
+package lints
+
+import (
+	"go/parser"
+	"go/token"
+	"testing"
+
+	tt "github.com/gnolang/tlin/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetectUnusedResults(t *testing.T) {
+	t.Parallel()
+	code := `package foo
+
+import (
+	"strings"
+	fmtx "gno.land/p/demo/ufmt"
+)
+
+type T struct{ name string }
+
+func normalize(s string) string { return strings.ToLower(s) }
+
+func f(s string, t *T, parts []string) {
+	strings.TrimSpace(s)
+	strings.ReplaceAll(t.name, " ", "_")
+	strings.Split(s, ",")
+	fmtx.Sprintf("%s", s)
+	normalize(s)
+	strings.TrimSpace(strings.Join(parts, ","))
+
+	s = strings.TrimSpace(s)
+	_ = strings.ToUpper(s)
+	println(strings.TrimSpace(s))
+}
+
+func g(strings *T) {
+	strings.TrimSpace()
+}
+
+func h() {
+	normalize := func(string) {}
+	normalize("a")
+}
+`
+	fset := token.NewFileSet()
+	node, err := parser.ParseFile(fset, "test.gno", code, parser.ParseComments)
+	require.NoError(t, err)
+
+	functions := append([]string{"normalize"}, DefaultPureFunctions...)
+	issues, err := DetectUnusedResults("test.gno", node, fset, tt.SeverityWarning, functions)
+	require.NoError(t, err)
+
+	type result struct{ message, note string }
+	var got []result
+	for _, issue := range issues {
+		assert.Equal(t, "unused-result", issue.Rule)
+		got = append(got, result{issue.Message, issue.Note})
+	}
+	assert.Equal(t, []result{
+		{"result of strings.TrimSpace is discarded", "strings.TrimSpace returns a modified copy and leaves s unchanged, assign it back: s = strings.TrimSpace(s)"},
+		{"result of strings.ReplaceAll is discarded", `strings.ReplaceAll returns a modified copy and leaves t.name unchanged, assign it back: t.name = strings.ReplaceAll(t.name, " ", "_")`},
+		{"result of strings.Split is discarded", "strings.Split does not modify its arguments, use the result or remove the call"},
+		{"result of fmtx.Sprintf is discarded", "fmtx.Sprintf does not modify its arguments, use the result or remove the call"},
+		{"result of normalize is discarded", "normalize does not modify its arguments, use the result or remove the call"},
+		{"result of strings.TrimSpace is discarded", "strings.TrimSpace does not modify its arguments, use the result or remove the call"},
+	}, got)
+}
@@ -0,0 +1,205 @@
+package lints
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+
+	tt "github.com/gnolang/tlin/internal/types"
+)
+
+type structField struct {
+	typeName string
+	ident    *ast.Ident
+	field    *ast.Field
+	filename string
+}
+
+// DetectUnusedStructFields reports struct fields that are never read in the
+// package: either never used at all, or only ever written.
+//
+// There is no type information, so fields are matched by name: a selector
+// `x.name` on any value counts as a use of every field called `name`. Since
+// accesses from other packages or through reflection cannot be seen, only
+// unexported fields of unexported types (or of any type in an internal
+// package) are checked. Fields with a `json` tag may still be read by an
+// encoder, so they are reported at info severity.
+func DetectUnusedStructFields(pkg *Package, severity tt.Severity) ([]tt.Issue, error) {
+	fields, typeFields := collectStructFields(pkg)
+	if len(fields) == 0 {
+		return nil, nil
+	}
+
+	reads, writes := countFieldAccesses(pkg, typeFields)
+
+	var issues []tt.Issue
+	for _, f := range fields {
+		name := f.ident.Name
+		if reads[name] > 0 {
+			continue
+		}
+
+		msg := fmt.Sprintf("field %s.%s is never used", f.typeName, name)
+		if writes[name] > 0 {
+			msg = fmt.Sprintf("field %s.%s is written but never read", f.typeName, name)
+		}
+
+		fieldSeverity := severity
+		if hasSerializationTag(f.field) && severity < tt.SeverityInfo {
+			fieldSeverity = tt.SeverityInfo
+		}
+
+		issues = append(issues, tt.Issue{
+			Rule:     "unused-struct-field",
+			Filename: f.filename,
+			Start:    pkg.Fset.Position(f.ident.Pos()),
+			End:      pkg.Fset.Position(f.ident.End()),
+			Message:  msg,
+			Note:     "fields read through reflection or serialization are not detected",
+			Severity: fieldSeverity,
+		})
+	}
+
+	sort.SliceStable(issues, func(i, j int) bool {
+		if issues[i].Filename != issues[j].Filename {
+			return issues[i].Filename < issues[j].Filename
+		}
+		return issues[i].Start.Offset < issues[j].Start.Offset
+	})
+	return issues, nil
+}
+
+// collectStructFields lists the fields to check, along with the field names of
+// every struct type of the package, in declaration order.
+func collectStructFields(pkg *Package) ([]structField, map[string][]string) {
+	internalPkg := strings.Contains("/"+filepath.ToSlash(pkg.Dir)+"/", "/internal/")
+
+	var fields []structField
+	typeFields := make(map[string][]string)
+	for _, pf := range pkg.Files {
+		if pf.IsTest() {
+			continue
+		}
+		for _, decl := range pf.File.Decls {
+			gen, ok := decl.(*ast.GenDecl)
+			if !ok || gen.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range gen.Specs {
+				ts := spec.(*ast.TypeSpec)
+				st, ok := ts.Type.(*ast.StructType)
+				if !ok {
+					continue
+				}
+
+				checked := !ast.IsExported(ts.Name.Name) || internalPkg
+				for _, field := range st.Fields.List {
+					if len(field.Names) == 0 {
+						// embedded field
+						typeFields[ts.Name.Name] = append(typeFields[ts.Name.Name], embeddedName(field.Type))
+						continue
+					}
+					for _, ident := range field.Names {
+						typeFields[ts.Name.Name] = append(typeFields[ts.Name.Name], ident.Name)
+						if checked && ident.Name != "_" && !ast.IsExported(ident.Name) {
+							fields = append(fields, structField{
+								typeName: ts.Name.Name,
+								ident:    ident,
+								field:    field,
+								filename: pf.Filename,
+							})
+						}
+					}
+				}
+			}
+		}
+	}
+	return fields, typeFields
+}
+
+// countFieldAccesses counts, by field name, the selectors reading a field and
+// the assignments and composite literals only writing to it.
+func countFieldAccesses(pkg *Package, typeFields map[string][]string) (reads, writes map[string]int) {
+	reads = make(map[string]int)
+	writes = make(map[string]int)
+
+	for _, pf := range pkg.Files {
+		// selectors that are only assigned to, `x.f = v`
+		written := make(map[*ast.SelectorExpr]bool)
+		ast.Inspect(pf.File, func(n ast.Node) bool {
+			assign, ok := n.(*ast.AssignStmt)
+			if !ok || (assign.Tok != token.ASSIGN && assign.Tok != token.DEFINE) {
+				return true
+			}
+			for _, lhs := range assign.Lhs {
+				if sel, ok := lhs.(*ast.SelectorExpr); ok {
+					written[sel] = true
+				}
+			}
+			return true
+		})
+
+		ast.Inspect(pf.File, func(n ast.Node) bool {
+			switch node := n.(type) {
+			case *ast.SelectorExpr:
+				if written[node] {
+					writes[node.Sel.Name]++
+				} else {
+					reads[node.Sel.Name]++
+				}
+			case *ast.CompositeLit:
+				countLiteralWrites(node, typeFields, writes)
+			}
+			return true
+		})
+	}
+	return reads, writes
+}
+
+func countLiteralWrites(lit *ast.CompositeLit, typeFields map[string][]string, writes map[string]int) {
+	var names []string
+	if ident, ok := lit.Type.(*ast.Ident); ok {
+		names = typeFields[ident.Name]
+	} else if lit.Type != nil {
+		return
+	}
+
+	for _, elt := range lit.Elts {
+		kv, ok := elt.(*ast.KeyValueExpr)
+		if !ok {
+			// positional literal sets every field
+			for _, name := range names {
+				writes[name]++
+			}
+			return
+		}
+		if key, ok := kv.Key.(*ast.Ident); ok {
+			writes[key.Name]++
+		}
+	}
+}
+
+func embeddedName(expr ast.Expr) string {
+	switch t := expr.(type) {
+	case *ast.Ident:
+		return t.Name
+	case *ast.StarExpr:
+		return embeddedName(t.X)
+	case *ast.SelectorExpr:
+		return t.Sel.Name
+	}
+	return ""
+}
+
+func hasSerializationTag(field *ast.Field) bool {
+	if field.Tag == nil {
+		return false
+	}
+	tag := reflect.StructTag(strings.Trim(field.Tag.Value, "`"))
+	_, ok := tag.Lookup("json")
+	return ok
+}
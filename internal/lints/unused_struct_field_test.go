@@ -0,0 +1,181 @@
+package lints
+
+import (
+	"testing"
+
+	tt "github.com/gnolang/tlin/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetectUnusedStructFields(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name     string
+		files    map[string]string
+		messages []string
+		severity []tt.Severity
+	}{
+		{
+			name: "never used and write-only fields",
+			files: map[string]string{
+				"store.gno": `package store
+
+type entry struct {
+	key     string
+	value   int
+	created int64
+	unused  bool
+}
+
+func newEntry(k string, v int) *entry {
+	e := &entry{key: k}
+	e.value = v
+	e.created = 1
+	return e
+}
+
+func (e *entry) Key() string { return e.key }
+`,
+			},
+			messages: []string{
+				"field entry.value is written but never read",
+				"field entry.created is written but never read",
+				"field entry.unused is never used",
+			},
+			severity: []tt.Severity{tt.SeverityWarning, tt.SeverityWarning, tt.SeverityWarning},
+		},
+		{
+			name: "reads in another file of the package",
+			files: map[string]string{
+				"a.gno": `package store
+
+type counter struct {
+	n int
+}
+
+func (c *counter) inc() { c.n = c.n + 1 }
+`,
+				"b.gno": `package store
+
+func total(c *counter) int { return c.n }
+`,
+			},
+		},
+		{
+			name: "compound assignment reads the field",
+			files: map[string]string{
+				"a.gno": `package store
+
+type counter struct {
+	n int
+}
+
+func (c *counter) inc() { c.n++ }
+`,
+			},
+		},
+		{
+			name: "positional literal only writes",
+			files: map[string]string{
+				"a.gno": `package store
+
+type pair struct {
+	a, b int
+}
+
+func sum() int {
+	p := pair{1, 2}
+	return p.a
+}
+`,
+			},
+			messages: []string{"field pair.b is written but never read"},
+			severity: []tt.Severity{tt.SeverityWarning},
+		},
+		{
+			name: "json tag downgrades to info",
+			files: map[string]string{
+				"a.gno": `package store
+
+type payload struct {
+	id int ` + "`json:\"id\"`" + `
+}
+
+func make() payload { return payload{id: 1} }
+`,
+			},
+			messages: []string{"field payload.id is written but never read"},
+			severity: []tt.Severity{tt.SeverityInfo},
+		},
+		{
+			name: "exported types and fields are skipped",
+			files: map[string]string{
+				"a.gno": `package store
+
+type Config struct {
+	secret string
+}
+
+type settings struct {
+	Public string
+}
+`,
+			},
+		},
+		{
+			name: "reads in test files count",
+			files: map[string]string{
+				"a.gno": `package store
+
+type state struct {
+	debug bool
+}
+
+var s = state{debug: true}
+`,
+				"a_test.gno": `package store
+
+func check() bool { return s.debug }
+`,
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			pkg := parsePackage(t, tc.files)
+
+			issues, err := DetectUnusedStructFields(pkg, tt.SeverityWarning)
+			require.NoError(t, err)
+			require.Len(t, issues, len(tc.messages))
+
+			for i, issue := range issues {
+				assert.Equal(t, "unused-struct-field", issue.Rule)
+				assert.Equal(t, tc.messages[i], issue.Message)
+				assert.Equal(t, tc.severity[i], issue.Severity)
+			}
+		})
+	}
+}
+
+func TestDetectUnusedStructFieldsInternalPackage(t *testing.T) {
+	t.Parallel()
+	pkg := parsePackage(t, map[string]string{
+		"a.gno": `package store
+
+type Config struct {
+	secret string
+}
+`,
+	})
+	pkg.Dir = "gno.land/p/demo/internal/store"
+
+	issues, err := DetectUnusedStructFields(pkg, tt.SeverityWarning)
+	require.NoError(t, err)
+	require.Len(t, issues, 1)
+	assert.Equal(t, "field Config.secret is never used", issues[0].Message)
+	assert.Equal(t, 4, issues[0].Start.Line)
+}
@@ -4,9 +4,13 @@ import (
 	"go/ast"
 	"go/token"
 
+	"github.com/gnolang/tlin/internal/messages"
 	tt "github.com/gnolang/tlin/internal/types"
 )
 
+var uselessBreakMessage = messages.Define("useless-break",
+	"useless break statement at the end of case clause")
+
 // DetectUselessBreak detects useless break statements in switch or select statements.
 func DetectUselessBreak(filename string, node *ast.File, fset *token.FileSet, severity tt.Severity) ([]tt.Issue, error) {
 	var issues []tt.Issue
@@ -38,13 +42,14 @@ func checkUselessBreak(stmts []ast.Stmt, filename string, fset *token.FileSet, i
 
 	lastStmt := stmts[len(stmts)-1]
 	if breakStmt, ok := lastStmt.(*ast.BranchStmt); ok && breakStmt.Tok == token.BREAK && breakStmt.Label == nil {
-		*issues = append(*issues, tt.Issue{
+		issue := tt.Issue{
 			Rule:     "useless-break",
 			Filename: filename,
 			Start:    fset.Position(breakStmt.Pos()),
 			End:      fset.Position(breakStmt.End()),
-			Message:  "useless break statement at the end of case clause",
 			Severity: severity,
-		})
+		}
+		uselessBreakMessage.Set(&issue, nil)
+		*issues = append(*issues, issue)
 	}
 }
@@ -0,0 +1,220 @@
+package lints
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+
+	tt "github.com/gnolang/tlin/internal/types"
+)
+
+// DetectValueReceiverMutations reports methods with a value receiver that
+// assign to the fields of the receiver, or call pointer methods on it. They
+// modify a copy, and the change is lost when the method returns.
+//
+// Writes through a pointer, a slice or a map reached from the receiver do
+// change the shared memory and are fine. Methods returning a value of the
+// receiver type derived from the receiver are builders working on the copy
+// on purpose:
+//
+//	func (c Config) WithLimit(n int) Config {
+//		c.limit = n
+//		return c
+//	}
+func DetectValueReceiverMutations(pkg *Package, severity tt.Severity) ([]tt.Issue, error) {
+	files, info, _ := typeCheck(pkg)
+
+	var issues []tt.Issue
+	for _, file := range files {
+		for _, decl := range file.File.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok || fn.Body == nil || fn.Recv == nil || len(fn.Recv.List) != 1 || len(fn.Recv.List[0].Names) != 1 {
+				continue
+			}
+			recvIdent := fn.Recv.List[0].Names[0]
+			recv, ok := info.Defs[recvIdent].(*types.Var)
+			if !ok || recvIdent.Name == "_" || !isStructValue(recv.Type()) || returnsDerived(fn, recv, info) {
+				continue
+			}
+			typeName, _ := receiverType(fn.Recv.List[0].Type)
+
+			reported := make(map[string]bool)
+			report := func(node ast.Node, target, message string) {
+				if reported[target] {
+					return
+				}
+				reported[target] = true
+				issues = append(issues, tt.Issue{
+					Rule:     "value-receiver-mutation",
+					Filename: file.Filename,
+					Start:    pkg.Fset.Position(node.Pos()),
+					End:      pkg.Fset.Position(node.End()),
+					Message:  message,
+					Note: fmt.Sprintf("the method works on a copy of the %s, use a pointer receiver: func (%s *%s) %s",
+						typeName, recvIdent.Name, typeName, fn.Name.Name),
+					Severity: severity,
+					Related: []tt.Location{
+						{Position: pkg.Fset.Position(recvIdent.Pos()), Label: "value receiver declared here"},
+					},
+				})
+			}
+
+			method := typeName + "." + fn.Name.Name
+			ast.Inspect(fn.Body, func(n ast.Node) bool {
+				switch n := n.(type) {
+				case *ast.AssignStmt:
+					if n.Tok == token.DEFINE {
+						return true
+					}
+					for _, lhs := range n.Lhs {
+						if field := copiedField(lhs, recv, info); field != "" {
+							report(n, field, fmt.Sprintf("method %s assigns %s on its value receiver, the change is lost when it returns", method, field))
+						}
+					}
+				case *ast.IncDecStmt:
+					if field := copiedField(n.X, recv, info); field != "" {
+						report(n, field, fmt.Sprintf("method %s assigns %s on its value receiver, the change is lost when it returns", method, field))
+					}
+				case *ast.CallExpr:
+					sel, ok := n.Fun.(*ast.SelectorExpr)
+					if !ok {
+						return true
+					}
+					selection := info.Selections[sel]
+					if selection == nil || selection.Kind() != types.MethodVal || !hasPointerReceiver(selection.Obj()) {
+						return true
+					}
+					if _, isPointer := selection.Recv().Underlying().(*types.Pointer); isPointer {
+						return true
+					}
+					target := types.ExprString(sel.X)
+					if isIdent(sel.X, recvIdent.Name) && info.Uses[sel.X.(*ast.Ident)] == recv || copiedField(sel.X, recv, info) != "" {
+						report(n, target+"."+sel.Sel.Name, fmt.Sprintf("method %s calls the pointer method %s on its value receiver, the change is lost when it returns",
+							method, types.ExprString(sel)))
+					}
+				}
+				return true
+			})
+		}
+	}
+	return issues, nil
+}
+
+func isStructValue(typ types.Type) bool {
+	_, ok := typ.Underlying().(*types.Struct)
+	return ok
+}
+
+func hasPointerReceiver(obj types.Object) bool {
+	fn, ok := obj.(*types.Func)
+	if !ok {
+		return false
+	}
+	_, ok = fn.Type().(*types.Signature).Recv().Type().(*types.Pointer)
+	return ok
+}
+
+// copiedField returns the source of expr when it designates memory held in
+// the receiver value itself, through field selections and array indexes,
+// such as c.total or c.limits[0].
+func copiedField(expr ast.Expr, recv *types.Var, info *types.Info) string {
+	root := ast.Unparen(expr)
+	steps := 0
+	for {
+		switch e := root.(type) {
+		case *ast.SelectorExpr:
+			if _, isPointer := info.TypeOf(e.X).Underlying().(*types.Pointer); isPointer {
+				return ""
+			}
+			if selection := info.Selections[e]; selection == nil || selection.Kind() != types.FieldVal || selection.Indirect() {
+				return ""
+			}
+			root = ast.Unparen(e.X)
+		case *ast.IndexExpr:
+			if _, isArray := info.TypeOf(e.X).Underlying().(*types.Array); !isArray {
+				return ""
+			}
+			root = ast.Unparen(e.X)
+		case *ast.Ident:
+			if steps == 0 || info.Uses[e] != recv {
+				return ""
+			}
+			return types.ExprString(expr)
+		default:
+			return ""
+		}
+		steps++
+	}
+}
+
+// returnsDerived reports whether fn returns a value of the receiver type
+// computed from the receiver, or from locals derived from it.
+func returnsDerived(fn *ast.FuncDecl, recv *types.Var, info *types.Info) bool {
+	derived := map[types.Object]bool{recv: true}
+	uses := func(node ast.Node) bool {
+		found := false
+		ast.Inspect(node, func(n ast.Node) bool {
+			if ident, ok := n.(*ast.Ident); ok && derived[info.Uses[ident]] {
+				found = true
+			}
+			return !found
+		})
+		return found
+	}
+
+	// assignments are visited in source order, which is enough for locals
+	// copied before being returned
+	returned := false
+	ast.Inspect(fn.Body, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.AssignStmt:
+			for i, lhs := range n.Lhs {
+				ident, ok := lhs.(*ast.Ident)
+				if !ok {
+					continue
+				}
+				if (len(n.Rhs) == len(n.Lhs) && uses(n.Rhs[i])) || (len(n.Rhs) == 1 && uses(n.Rhs[0])) {
+					if obj := definedOrUsed(ident, info); obj != nil {
+						derived[obj] = true
+					}
+				}
+			}
+		case *ast.ValueSpec:
+			for i, name := range n.Names {
+				if (len(n.Values) == len(n.Names) && uses(n.Values[i])) || (len(n.Values) == 1 && uses(n.Values[0])) {
+					if obj := info.Defs[name]; obj != nil {
+						derived[obj] = true
+					}
+				}
+			}
+		case *ast.ReturnStmt:
+			for _, result := range n.Results {
+				if isReceiverType(info.TypeOf(result), recv) && uses(result) {
+					returned = true
+				}
+			}
+			if len(n.Results) == 0 && fn.Type.Results != nil {
+				for _, field := range fn.Type.Results.List {
+					for _, name := range field.Names {
+						if obj := info.Defs[name]; derived[obj] && isReceiverType(obj.Type(), recv) {
+							returned = true
+						}
+					}
+				}
+			}
+		}
+		return !returned
+	})
+	return returned
+}
+
+func isReceiverType(typ types.Type, recv *types.Var) bool {
+	if typ == nil {
+		return false
+	}
+	if ptr, ok := typ.(*types.Pointer); ok {
+		typ = ptr.Elem()
+	}
+	return types.Identical(typ, recv.Type())
+}
@@ -0,0 +1,99 @@
+package lints
+
+import (
+	"testing"
+
+	tt "github.com/gnolang/tlin/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetectValueReceiverMutations(t *testing.T) {
+	t.Parallel()
+	pkg := parsePackage(t, map[string]string{
+		"pkg/counter.gno": `package counter
+
+type Limits struct{ max int }
+
+func (l *Limits) Raise() { l.max++ }
+
+type Counter struct {
+	n      int
+	limits Limits
+	last   [3]int
+	shared *Limits
+	items  []int
+	byName map[string]int
+}
+
+func (c Counter) Inc() int {
+	c.n++
+	return c.n
+}
+
+func (c Counter) Reset() {
+	c.n = 0
+	c.n = 0
+	c.limits.max = 0
+	c.last[0] = 1
+}
+
+func (c Counter) Grow() {
+	c.limits.Raise()
+}
+
+func (c Counter) Shared() {
+	c.shared.max = 1
+	c.shared.Raise()
+	c.items[0] = 1
+	c.byName["a"] = 1
+	local := c.limits
+	local.max = 2
+	_ = local
+}
+
+func (c Counter) WithN(n int) Counter {
+	c.n = n
+	return c
+}
+
+func (c Counter) Clone() (out Counter) {
+	c.n = 0
+	out = c
+	return
+}
+
+func (c Counter) Copy() *Counter {
+	c.n = 0
+	cp := c
+	return &cp
+}
+
+func (c *Counter) Set(n int) { c.n = n }
+
+func (_ Counter) Ignored() {}
+
+type Ints []int
+
+func (s Ints) Zero() { s[0] = 0 }
+`,
+	})
+
+	issues, err := DetectValueReceiverMutations(pkg, tt.SeverityWarning)
+	require.NoError(t, err)
+
+	var messages []string
+	for _, issue := range issues {
+		assert.Equal(t, "value-receiver-mutation", issue.Rule)
+		require.Len(t, issue.Related, 1)
+		messages = append(messages, issue.Message)
+	}
+	assert.Equal(t, []string{
+		"method Counter.Inc assigns c.n on its value receiver, the change is lost when it returns",
+		"method Counter.Reset assigns c.n on its value receiver, the change is lost when it returns",
+		"method Counter.Reset assigns c.limits.max on its value receiver, the change is lost when it returns",
+		"method Counter.Reset assigns c.last[0] on its value receiver, the change is lost when it returns",
+		"method Counter.Grow calls the pointer method c.limits.Raise on its value receiver, the change is lost when it returns",
+	}, messages)
+	assert.Equal(t, "the method works on a copy of the Counter, use a pointer receiver: func (c *Counter) Inc", issues[0].Note)
+}
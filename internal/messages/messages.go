@@ -0,0 +1,231 @@
+// Package messages lets the messages of the issues be translated.
+//
+// Rules define their messages once, with an ID and an English template
+// whose parameters are written {name}:
+//
+//	var lineTooLong = messages.Define("line-length",
+//		"line is {width} columns long, exceeding the maximum of {max}")
+//
+// and fill the issues they report with Set, which renders the English text
+// and records the ID and the arguments. A Catalog loaded from a YAML file
+// mapping IDs to translated templates then rewrites the messages it has a
+// translation for, the others staying in English.
+package messages
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	tt "github.com/gnolang/tlin/internal/types"
+	"gopkg.in/yaml.v3"
+)
+
+// english holds the templates of the defined messages by ID. It is only
+// written while the packages defining messages are initialized.
+var english = make(map[string]string)
+
+// Message is a message defined in the English catalog.
+type Message struct {
+	id string
+}
+
+// Args are the arguments of a message, by parameter name.
+type Args map[string]interface{}
+
+// Define adds a message to the English catalog. It is meant to initialize
+// package variables, and panics if the ID is already defined.
+func Define(id, template string) Message {
+	if _, ok := english[id]; ok {
+		panic(fmt.Sprintf("messages: %q defined twice", id))
+	}
+	english[id] = template
+	return Message{id: id}
+}
+
+// ID returns the ID of the message.
+func (m Message) ID() string {
+	return m.id
+}
+
+// Set fills the message of issue with the English text of m, and records
+// the ID and the arguments needed to translate it.
+func (m Message) Set(issue *tt.Issue, args Args) {
+	values := make(map[string]string, len(args))
+	for name, value := range args {
+		values[name] = fmt.Sprint(value)
+	}
+	issue.MessageID = m.id
+	issue.MessageArgs = values
+	issue.Message = expand(english[m.id], values)
+}
+
+// English returns the templates of the defined messages by ID.
+func English() map[string]string {
+	templates := make(map[string]string, len(english))
+	for id, template := range english {
+		templates[id] = template
+	}
+	return templates
+}
+
+// Catalog holds translated templates by message ID.
+type Catalog struct {
+	templates map[string]string
+}
+
+// LoadCatalog reads the catalog file at path, see ParseCatalog.
+func LoadCatalog(path string) (*Catalog, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	catalog, err := ParseCatalog(content)
+	if err != nil {
+		return nil, fmt.Errorf("invalid message catalog %s: %w", path, err)
+	}
+	return catalog, nil
+}
+
+// ParseCatalog parses a YAML mapping of message IDs to translated
+// templates. Every ID must be defined, and every template must use the same
+// parameters as the English one. Problems are reported with their line.
+func ParseCatalog(content []byte) (*Catalog, error) {
+	catalog := &Catalog{templates: make(map[string]string)}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(content, &doc); err != nil {
+		return nil, err
+	}
+	if len(doc.Content) == 0 {
+		return catalog, nil
+	}
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("line %d: catalog must map message IDs to templates", root.Line)
+	}
+
+	var errs []error
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		key, value := root.Content[i], root.Content[i+1]
+		original, ok := english[key.Value]
+		if !ok {
+			errs = append(errs, fmt.Errorf("line %d: unknown message %q", key.Line, key.Value))
+			continue
+		}
+		if value.Kind != yaml.ScalarNode {
+			errs = append(errs, fmt.Errorf("line %d: template of %q must be a string", value.Line, key.Value))
+			continue
+		}
+		if err := sameParameters(original, value.Value); err != nil {
+			errs = append(errs, fmt.Errorf("line %d: template of %q %w", value.Line, key.Value, err))
+			continue
+		}
+		catalog.templates[key.Value] = value.Value
+	}
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+	return catalog, nil
+}
+
+// Translate rewrites the messages of the issues the catalog has a
+// translation for. A nil catalog leaves the issues as they are.
+func (c *Catalog) Translate(issues []tt.Issue) {
+	if c == nil {
+		return
+	}
+	for i := range issues {
+		if template, ok := c.templates[issues[i].MessageID]; ok {
+			issues[i].Message = expand(template, issues[i].MessageArgs)
+		}
+	}
+}
+
+// sameParameters checks that the translation uses the parameters of the
+// original template, no more and no less.
+func sameParameters(original, translation string) error {
+	want, got := parameters(original), parameters(translation)
+	var unknown, missing []string
+	for name := range got {
+		if !want[name] {
+			unknown = append(unknown, "{"+name+"}")
+		}
+	}
+	for name := range want {
+		if !got[name] {
+			missing = append(missing, "{"+name+"}")
+		}
+	}
+	sort.Strings(unknown)
+	sort.Strings(missing)
+
+	switch {
+	case len(unknown) > 0:
+		return fmt.Errorf("uses unknown parameter %s", strings.Join(unknown, ", "))
+	case len(missing) > 0:
+		return fmt.Errorf("misses parameter %s", strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// parameters returns the names of the parameters used by template.
+func parameters(template string) map[string]bool {
+	names := make(map[string]bool)
+	scan(template, func(literal, name string) {
+		if name != "" {
+			names[name] = true
+		}
+	})
+	return names
+}
+
+// expand replaces the parameters of template by their value.
+func expand(template string, values map[string]string) string {
+	var b strings.Builder
+	scan(template, func(literal, name string) {
+		b.WriteString(literal)
+		if name != "" {
+			b.WriteString(values[name])
+		}
+	})
+	return b.String()
+}
+
+// scan splits template into literal text, each part followed by the name of
+// a parameter or by nothing at the end. Braces not enclosing an identifier
+// are literal text.
+func scan(template string, yield func(literal, name string)) {
+	start := 0
+	for i := 0; i < len(template); i++ {
+		if template[i] != '{' {
+			continue
+		}
+		end := strings.IndexByte(template[i:], '}')
+		if end < 0 {
+			break
+		}
+		name := template[i+1 : i+end]
+		if !isParameterName(name) {
+			continue
+		}
+		yield(template[start:i], name)
+		start = i + end + 1
+		i = start - 1
+	}
+	yield(template[start:], "")
+}
+
+func isParameterName(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r != '_' && r != '-' && !('a' <= r && r <= 'z') && !('A' <= r && r <= 'Z') && !('0' <= r && r <= '9') {
+			return false
+		}
+	}
+	return true
+}
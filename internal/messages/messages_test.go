@@ -0,0 +1,109 @@
+package messages
+
+import (
+	"testing"
+
+	tt "github.com/gnolang/tlin/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	testLongLine = Define("test.long-line", "line is {width} columns long, exceeding the maximum of {max}")
+	testBreak    = Define("test.break", "useless break statement")
+	testLiteral  = Define("test.literal", "{name} is set to {} and {not a parameter}")
+)
+
+func TestSet(t *testing.T) {
+	t.Parallel()
+
+	var issue tt.Issue
+	testLongLine.Set(&issue, Args{"width": 120, "max": 100})
+	assert.Equal(t, "line is 120 columns long, exceeding the maximum of 100", issue.Message)
+	assert.Equal(t, "test.long-line", issue.MessageID)
+	assert.Equal(t, map[string]string{"width": "120", "max": "100"}, issue.MessageArgs)
+
+	testLiteral.Set(&issue, Args{"name": "x"})
+	assert.Equal(t, "x is set to {} and {not a parameter}", issue.Message)
+
+	assert.Panics(t, func() { Define("test.break", "again") })
+}
+
+func TestParseCatalog(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		content string
+		errors  []string
+	}{
+		{
+			name:    "empty",
+			content: "",
+		},
+		{
+			name: "valid",
+			content: `test.long-line: "{width}열로 최대 {max}열을 초과합니다"
+test.break: 불필요한 break 문
+`,
+		},
+		{
+			name: "invalid",
+			content: `test.long-line: "{width}열입니다"
+test.break: "{name} 불필요한 break 문"
+test.unknown: 알 수 없음
+test.literal:
+  - list
+`,
+			errors: []string{
+				`line 1: template of "test.long-line" misses parameter {max}`,
+				`line 2: template of "test.break" uses unknown parameter {name}`,
+				`line 3: unknown message "test.unknown"`,
+				`line 5: template of "test.literal" must be a string`,
+			},
+		},
+		{
+			name:    "not a mapping",
+			content: "- test.break\n",
+			errors:  []string{"line 1: catalog must map message IDs to templates"},
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			_, err := ParseCatalog([]byte(tc.content))
+			if len(tc.errors) == 0 {
+				require.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			for _, msg := range tc.errors {
+				assert.Contains(t, err.Error(), msg)
+			}
+		})
+	}
+}
+
+func TestTranslate(t *testing.T) {
+	t.Parallel()
+
+	catalog, err := ParseCatalog([]byte(`test.long-line: "{width}열로 최대 {max}열을 초과합니다"`))
+	require.NoError(t, err)
+
+	issues := make([]tt.Issue, 3)
+	testLongLine.Set(&issues[0], Args{"width": 120, "max": 100})
+	testBreak.Set(&issues[1], nil)
+	issues[2].Message = "written directly"
+
+	catalog.Translate(issues)
+	assert.Equal(t, "120열로 최대 100열을 초과합니다", issues[0].Message)
+	// missing translations stay in English
+	assert.Equal(t, "useless break statement", issues[1].Message)
+	assert.Equal(t, "written directly", issues[2].Message)
+
+	var none *Catalog
+	none.Translate(issues)
+	assert.Equal(t, "120열로 최대 100열을 초과합니다", issues[0].Message)
+}
@@ -4,14 +4,22 @@ import (
 	"fmt"
 	"go/ast"
 	"go/token"
+	"sort"
 	"strings"
+	"sync"
 )
 
-const nolintPrefix = "//nolint"
+const (
+	nolintPrefix = "//nolint"
+	ignorePrefix = "//tlin:ignore"
+)
 
 // Manager manages nolint scopes and checks if a position is nolinted.
 type Manager struct {
-	scopes map[string][]scope // filename to scopes
+	scopes map[string][]*scope // filename to scopes
+
+	// mu guards the used flags of the scopes, set by concurrent rules.
+	mu sync.Mutex
 }
 
 // scope represents a range in the code where nolint applies.
@@ -19,12 +27,26 @@ type scope struct {
 	rules map[string]struct{}
 	start token.Position
 	end   token.Position
+
+	// ignore is set for //tlin:ignore directives, written at pos with the
+	// given reason, and used once they suppressed an issue.
+	ignore bool
+	pos    token.Position
+	reason string
+	used   bool
+}
+
+// Directive is a //tlin:ignore comment.
+type Directive struct {
+	Pos    token.Position
+	Rule   string
+	Reason string
 }
 
 // ParseComments parses nolint comments in the given AST file and returns a nolintManager.
 func ParseComments(f *ast.File, fset *token.FileSet) *Manager {
 	manager := Manager{
-		scopes: make(map[string][]scope, len(f.Comments)),
+		scopes: make(map[string][]*scope, len(f.Comments)),
 	}
 	stmtMap := indexStatementsByLine(f, fset)
 	packageLine := fset.Position(f.Package).Line
@@ -37,13 +59,14 @@ func ParseComments(f *ast.File, fset *token.FileSet) *Manager {
 				continue
 			}
 			filename := scope.start.Filename
-			manager.scopes[filename] = append(manager.scopes[filename], scope)
+			manager.scopes[filename] = append(manager.scopes[filename], &scope)
 		}
 	}
 	return &manager
 }
 
-// parseComment parses a single nolint comment and determines its scope.
+// parseComment parses a single nolint or tlin:ignore comment and determines
+// its scope.
 func parseComment(
 	comment *ast.Comment,
 	f *ast.File,
@@ -54,6 +77,19 @@ func parseComment(
 	var scope scope
 	text := comment.Text
 
+	if strings.HasPrefix(text, ignorePrefix) {
+		rule, reason, err := parseIgnoreDirective(text[len(ignorePrefix):])
+		if err != nil {
+			return scope, err
+		}
+		scope.ignore = true
+		scope.pos = fset.Position(comment.Slash)
+		scope.reason = reason
+		scope.rules = map[string]struct{}{rule: {}}
+		placeScope(&scope, comment, f, fset, stmtMap, packageLine)
+		return scope, nil
+	}
+
 	if !strings.HasPrefix(text, nolintPrefix) {
 		return scope, fmt.Errorf("invalid nolint comment")
 	}
@@ -76,13 +112,41 @@ func parseComment(
 	}
 
 	scope.rules = parseIgnoreRuleNames(rest)
+	placeScope(&scope, comment, f, fset, stmtMap, packageLine)
+	return scope, nil
+}
+
+// parseIgnoreDirective parses what follows //tlin:ignore: the name of the
+// rule, then an optional free form reason.
+func parseIgnoreDirective(text string) (rule, reason string, err error) {
+	if text != "" && text[0] != ' ' && text[0] != '\t' {
+		return "", "", fmt.Errorf("invalid tlin:ignore comment format")
+	}
+	fields := strings.Fields(text)
+	if len(fields) == 0 {
+		return "", "", fmt.Errorf("invalid tlin:ignore comment: no rule specified")
+	}
+	rule = fields[0]
+	reason = strings.TrimSpace(strings.TrimSpace(text)[len(rule):])
+	return rule, reason, nil
+}
+
+// placeScope sets the range of the code a comment applies to.
+func placeScope(
+	scope *scope,
+	comment *ast.Comment,
+	f *ast.File,
+	fset *token.FileSet,
+	stmtMap map[int]ast.Stmt,
+	packageLine int,
+) {
 	pos := fset.Position(comment.Slash)
 
 	// check if the comment is before the package declaration
 	if isBeforePackageDecl(pos.Line, packageLine) {
 		scope.start = fset.Position(f.Pos())
 		scope.end = fset.Position(f.End())
-		return scope, nil
+		return
 	}
 
 	// check if the comment is at the end of a line (inline comment)
@@ -91,7 +155,7 @@ func parseComment(
 		if stmt, exists := stmtMap[pos.Line]; exists {
 			scope.start = fset.Position(stmt.Pos())
 			scope.end = fset.Position(stmt.End())
-			return scope, nil
+			return
 		}
 	}
 
@@ -100,7 +164,7 @@ func parseComment(
 	if stmt, exists := stmtMap[nextLine]; exists {
 		scope.start = fset.Position(stmt.Pos())
 		scope.end = fset.Position(stmt.End())
-		return scope, nil
+		return
 	}
 
 	// check if the comment is above a function declaration
@@ -109,14 +173,13 @@ func parseComment(
 		if funcPos.Line == pos.Line+1 {
 			scope.start = funcPos
 			scope.end = fset.Position(decl.End())
-			return scope, nil
+			return
 		}
 	}
 
 	// Default case: apply to the line of the comment
 	scope.start = pos
 	scope.end = pos
-	return scope, nil
 }
 
 // parseIgnoreRuleNames parses the rule list from the nolint comment more efficiently.
@@ -175,21 +238,55 @@ func findFunctionAfterLine(fset *token.FileSet, f *ast.File, line int) *ast.Func
 }
 
 // IsNolint checks if a given position and rule are nolinted.
+// It is safe for concurrent use.
 func (m *Manager) IsNolint(pos token.Position, ruleName string) bool {
 	scopes, exists := m.scopes[pos.Filename]
 	if !exists {
 		return false
 	}
+	nolinted := false
 	for _, scope := range scopes {
 		if pos.Line < scope.start.Line || pos.Line > scope.end.Line {
 			continue
 		}
-		if len(scope.rules) == 0 {
-			return true
+		_, named := scope.rules[ruleName]
+		if len(scope.rules) > 0 && !named {
+			continue
 		}
-		if _, exists := scope.rules[ruleName]; exists {
-			return true
+		nolinted = true
+		// keep going, every directive covering the issue is used
+		if scope.ignore {
+			m.mu.Lock()
+			scope.used = true
+			m.mu.Unlock()
 		}
 	}
-	return false
+	return nolinted
+}
+
+// Unused returns the //tlin:ignore directives that suppressed no issue so
+// far, in the order of the file.
+func (m *Manager) Unused() []Directive {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var unused []Directive
+	for _, scopes := range m.scopes {
+		for _, scope := range scopes {
+			if !scope.ignore || scope.used {
+				continue
+			}
+			for rule := range scope.rules {
+				unused = append(unused, Directive{Pos: scope.pos, Rule: rule, Reason: scope.reason})
+			}
+		}
+	}
+	sort.Slice(unused, func(i, j int) bool {
+		a, b := unused[i].Pos, unused[j].Pos
+		if a.Filename != b.Filename {
+			return a.Filename < b.Filename
+		}
+		return a.Offset < b.Offset
+	})
+	return unused
 }
@@ -99,6 +99,88 @@ func main() {
 	}
 }
 
+func TestIgnoreDirective(t *testing.T) {
+	t.Parallel()
+	source := `package main
+
+func main() {
+	fmt.Println("Line 4") //tlin:ignore rule1 reviewed, the output is expected
+	//tlin:ignore rule2
+	if true {
+		fmt.Println("Line 7")
+	}
+	//tlin:ignore rule3 never triggered
+	fmt.Println("Line 10")
+	//tlin:ignore
+	fmt.Println("Line 12")
+	//tlin:ignorerule4
+	fmt.Println("Line 14")
+}
+`
+
+	fset := token.NewFileSet()
+	node, err := parser.ParseFile(fset, "test.go", source, parser.ParseComments)
+	if err != nil {
+		t.Fatalf("Failed to parse source: %v", err)
+	}
+
+	manager := ParseComments(node, fset)
+
+	tests := []struct {
+		rule     string
+		line     int
+		expected bool
+	}{
+		{"rule1", 4, true},     // inline directive
+		{"rule2", 4, false},    // other rules are not covered
+		{"rule2", 7, true},     // the directive covers the whole block below it
+		{"rule1", 7, false},    // only the named rule
+		{"anyrule", 12, false}, // a directive without rule is invalid
+		{"rule4", 14, false},   // the rule must be separated from the prefix
+	}
+	for _, test := range tests {
+		result := manager.IsNolint(positionAtLine(test.line), test.rule)
+		if result != test.expected {
+			t.Errorf("IsNolint at line %d for rule '%s': expected %v, got %v", test.line, test.rule, test.expected, result)
+		}
+	}
+
+	unused := manager.Unused()
+	if len(unused) != 1 {
+		t.Fatalf("Expected 1 unused directive, got %d: %v", len(unused), unused)
+	}
+	if unused[0].Rule != "rule3" || unused[0].Pos.Line != 9 || unused[0].Reason != "never triggered" {
+		t.Errorf("Unexpected unused directive: %+v", unused[0])
+	}
+}
+
+func TestParseIgnoreDirective(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		text   string
+		rule   string
+		reason string
+		valid  bool
+	}{
+		{" rule1", "rule1", "", true},
+		{" rule1 generated code", "rule1", "generated code", true},
+		{"\trule1  spaced   reason ", "rule1", "spaced   reason", true},
+		{"", "", "", false},
+		{"   ", "", "", false},
+		{"rule1", "", "", false},
+	}
+	for _, test := range tests {
+		rule, reason, err := parseIgnoreDirective(test.text)
+		if (err == nil) != test.valid {
+			t.Errorf("parseIgnoreDirective(%q): expected valid %v, got error %v", test.text, test.valid, err)
+			continue
+		}
+		if rule != test.rule || reason != test.reason {
+			t.Errorf("parseIgnoreDirective(%q) = %q, %q, expected %q, %q", test.text, rule, reason, test.rule, test.reason)
+		}
+	}
+}
+
 func positionAtLine(line int) token.Position {
 	return token.Position{
 		Filename: "test.go",
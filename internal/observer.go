@@ -0,0 +1,91 @@
+package internal
+
+import (
+	"sync"
+	"time"
+
+	tt "github.com/gnolang/tlin/internal/types"
+)
+
+// Observer receives the events of a lint run, for example to report progress.
+//
+// The engine serializes the calls, so implementations need no locking of
+// their own even when files are processed in parallel. Hooks are called from
+// the workers and should return quickly.
+type Observer interface {
+	// OnRunStart is called once the files to lint are known.
+	OnRunStart(files int)
+	// OnFileStart is called before a file is linted.
+	OnFileStart(filename string)
+	// OnFileDone is called once a file is linted, with the issues found in it.
+	// The issues are nil when the file could not be linted.
+	OnFileDone(filename string, issues []tt.Issue, duration time.Duration)
+	// OnRuleError is called when a rule fails on a file. The rule is then
+	// left out of the results of that file. The filename is empty for sources.
+	OnRuleError(filename, rule string, err error)
+	// OnRuleSkipped is called once for a rule left out of the whole run, as
+	// the external tool it runs is unusable, with the reason.
+	OnRuleSkipped(rule, reason string)
+	// OnRunDone is called after the last file.
+	OnRunDone(summary RunSummary)
+}
+
+// RunSummary describes a finished lint run.
+type RunSummary struct {
+	Files    int
+	Failed   int // files that could not be linted
+	Issues   int
+	Duration time.Duration
+}
+
+// NopObserver ignores every event. It is the default observer of the engine.
+type NopObserver struct{}
+
+func (NopObserver) OnRunStart(int)                               {}
+func (NopObserver) OnFileStart(string)                           {}
+func (NopObserver) OnFileDone(string, []tt.Issue, time.Duration) {}
+func (NopObserver) OnRuleError(string, string, error)            {}
+func (NopObserver) OnRuleSkipped(string, string)                 {}
+func (NopObserver) OnRunDone(RunSummary)                         {}
+
+// syncObserver serializes the calls to an observer.
+type syncObserver struct {
+	mu       sync.Mutex
+	observer Observer
+}
+
+func (o *syncObserver) OnRunStart(files int) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.observer.OnRunStart(files)
+}
+
+func (o *syncObserver) OnFileStart(filename string) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.observer.OnFileStart(filename)
+}
+
+func (o *syncObserver) OnFileDone(filename string, issues []tt.Issue, duration time.Duration) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.observer.OnFileDone(filename, issues, duration)
+}
+
+func (o *syncObserver) OnRuleError(filename, rule string, err error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.observer.OnRuleError(filename, rule, err)
+}
+
+func (o *syncObserver) OnRuleSkipped(rule, reason string) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.observer.OnRuleSkipped(rule, reason)
+}
+
+func (o *syncObserver) OnRunDone(summary RunSummary) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.observer.OnRunDone(summary)
+}
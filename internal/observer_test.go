@@ -0,0 +1,131 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	tt "github.com/gnolang/tlin/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type recordingObserver struct {
+	NopObserver
+	events []string
+}
+
+func (o *recordingObserver) OnFileStart(filename string) {
+	o.events = append(o.events, "start "+filepath.Base(filename))
+}
+
+func (o *recordingObserver) OnFileDone(filename string, issues []tt.Issue, _ time.Duration) {
+	o.events = append(o.events, fmt.Sprintf("done %s %d issues", filepath.Base(filename), len(issues)))
+}
+
+func (o *recordingObserver) OnRuleError(filename, rule string, err error) {
+	o.events = append(o.events, "error "+filepath.Base(filename)+" "+rule+": "+err.Error())
+}
+
+func TestEngineObserver(t *testing.T) {
+	t.Parallel()
+
+	tempDir := createTempDir(t, "observer_test")
+	filename := filepath.Join(tempDir, "a.gno")
+	require.NoError(t, os.WriteFile(filename, []byte("package a\n"), 0o644))
+
+	engine, err := NewEngine(tempDir, nil, nil)
+	require.NoError(t, err)
+	engine.rules = map[string]LintRule{
+		"failing": {
+			name: "failing",
+			check: func(string, *ast.File, *token.FileSet, tt.Severity) ([]tt.Issue, error) {
+				return nil, errors.New("boom")
+			},
+		},
+		"passing": {
+			name: "passing",
+			check: func(filename string, _ *ast.File, _ *token.FileSet, _ tt.Severity) ([]tt.Issue, error) {
+				return []tt.Issue{{Rule: "passing", Filename: filename}}, nil
+			},
+		},
+	}
+
+	observer := &recordingObserver{}
+	engine.SetObserver(observer)
+
+	issues, err := engine.Run(filename)
+	require.NoError(t, err)
+	assert.Len(t, issues, 1)
+	assert.Equal(t, []string{
+		"start a.gno",
+		"error a.gno failing: boom",
+		"done a.gno 1 issues",
+	}, observer.events)
+
+	observer.events = nil
+	_, err = engine.Run(filepath.Join(tempDir, "missing.gno"))
+	assert.Error(t, err)
+	assert.Equal(t, []string{"start missing.gno", "done missing.gno 0 issues"}, observer.events)
+
+	// a nil observer restores the default one
+	engine.SetObserver(nil)
+	assert.Equal(t, NopObserver{}, engine.Observer())
+}
+
+func TestEngineRunContext(t *testing.T) {
+	t.Parallel()
+
+	tempDir := createTempDir(t, "context_test")
+	filename := filepath.Join(tempDir, "a.gno")
+	require.NoError(t, os.WriteFile(filename, []byte("package a\n"), 0o644))
+
+	release := make(chan struct{})
+	defer close(release)
+
+	engine, err := NewEngine(tempDir, nil, nil)
+	require.NoError(t, err)
+	engine.rules = map[string]LintRule{
+		"stuck": {
+			name: "stuck",
+			check: func(string, *ast.File, *token.FileSet, tt.Severity) ([]tt.Issue, error) {
+				<-release
+				return nil, nil
+			},
+		},
+		"passing": {
+			name: "passing",
+			check: func(filename string, _ *ast.File, _ *token.FileSet, _ tt.Severity) ([]tt.Issue, error) {
+				return []tt.Issue{{Rule: "passing", Filename: filename}}, nil
+			},
+		},
+	}
+	observer := &recordingObserver{}
+	engine.SetObserver(observer)
+
+	// the rule exceeding its timeout is given up on, the others are reported
+	engine.SetRuleTimeout(20 * time.Millisecond)
+	issues, err := engine.RunContext(context.Background(), filename)
+	require.NoError(t, err)
+	require.Len(t, issues, 1)
+	assert.Equal(t, "passing", issues[0].Rule)
+	assert.Contains(t, observer.events, "error a.gno stuck: "+context.DeadlineExceeded.Error())
+
+	// a run given up on fails, without blaming the rules
+	engine.SetRuleTimeout(0)
+	observer.events = nil
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err = engine.RunContext(ctx, filename)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Equal(t, []string{"start a.gno", "done a.gno 0 issues"}, observer.events)
+
+	_, err = engine.RunSourceContext(ctx, []byte("package a\n"))
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
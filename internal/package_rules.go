@@ -0,0 +1,167 @@
+package internal
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/gnolang/tlin/internal/lints"
+	tt "github.com/gnolang/tlin/internal/types"
+)
+
+// packageCache shares the parsed packages and the results of package rules
+// between the files of a directory, so each package is analyzed only once
+// per rule even though the engine runs file by file.
+type packageCache struct {
+	mu       sync.Mutex
+	packages map[string]*packageEntry
+	results  map[string]*resultEntry
+	digests  map[string]*digestEntry
+}
+
+type packageEntry struct {
+	once sync.Once
+	pkg  *lints.Package
+	err  error
+}
+
+type resultEntry struct {
+	once sync.Once
+	// done is closed once the issues are known.
+	done   chan struct{}
+	issues []tt.Issue
+	err    error
+}
+
+type digestEntry struct {
+	once   sync.Once
+	digest []byte
+	err    error
+}
+
+func newPackageCache() *packageCache {
+	return &packageCache{
+		packages: make(map[string]*packageEntry),
+		results:  make(map[string]*resultEntry),
+		digests:  make(map[string]*digestEntry),
+	}
+}
+
+func (c *packageCache) packageEntry(key string) *packageEntry {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.packages[key]
+	if !ok {
+		entry = &packageEntry{}
+		c.packages[key] = entry
+	}
+	return entry
+}
+
+func (c *packageCache) resultEntry(key string) *resultEntry {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.results[key]
+	if !ok {
+		entry = &resultEntry{}
+		c.results[key] = entry
+	}
+	return entry
+}
+
+// digest returns a hash of the source files of a directory, which the
+// results of the package rules depend on.
+func (c *packageCache) digest(dir string) ([]byte, error) {
+	c.mu.Lock()
+	entry, ok := c.digests[dir]
+	if !ok {
+		entry = &digestEntry{}
+		c.digests[dir] = entry
+	}
+	c.mu.Unlock()
+
+	entry.once.Do(func() {
+		entry.digest, entry.err = hashSourceFiles(dir)
+	})
+	return entry.digest, entry.err
+}
+
+// hashSourceFiles hashes the names and contents of the files LoadPackage
+// reads.
+func hashSourceFiles(dir string) ([]byte, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	h := sha256.New()
+	for _, entry := range entries {
+		filename := entry.Name()
+		if entry.IsDir() || strings.HasPrefix(filename, "temp_") {
+			continue
+		}
+		if ext := filepath.Ext(filename); ext != ".go" && ext != ".gno" {
+			continue
+		}
+		content, err := os.ReadFile(filepath.Join(dir, filename))
+		if err != nil {
+			return nil, err
+		}
+		sum := sha256.Sum256(content)
+		fmt.Fprintf(h, "%s\x00%x\x00", filename, sum)
+	}
+	return h.Sum(nil), nil
+}
+
+// runPackageRule runs a package rule on the package the file belongs to and
+// returns the issues located in that file. The package is checked once for
+// all its files, whatever the contexts of the files: once ctx is done, the
+// file stops waiting for the result, which the other files may still use.
+func (e *Engine) runPackageRule(ctx context.Context, rule LintRule, filename string, node *ast.File) ([]tt.Issue, error) {
+	dir := filepath.Dir(filename)
+	key := dir + "\x00" + node.Name.Name
+
+	pkgEntry := e.packages.packageEntry(key)
+	pkgEntry.once.Do(func() {
+		pkgEntry.pkg, pkgEntry.err = lints.LoadPackage(dir, node.Name.Name)
+	})
+	if pkgEntry.err != nil {
+		return nil, pkgEntry.err
+	}
+
+	result := e.packages.resultEntry(key + "\x00" + rule.Name())
+	result.once.Do(func() {
+		result.done = make(chan struct{})
+		go func() {
+			defer close(result.done)
+			result.issues, result.err = rule.CheckPackage(context.Background(), pkgEntry.pkg)
+		}()
+	})
+	select {
+	case <-result.done:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	if result.err != nil {
+		return nil, result.err
+	}
+
+	target := filepath.Clean(filename)
+	var issues []tt.Issue
+	for _, issue := range result.issues {
+		if filepath.Clean(issue.Filename) == target {
+			issues = append(issues, issue)
+		}
+	}
+	return issues, nil
+}
+
+// runPackageRuleOnSource runs a package rule on a single parsed source.
+func runPackageRuleOnSource(ctx context.Context, rule LintRule, filename string, node *ast.File, fset *token.FileSet) ([]tt.Issue, error) {
+	return rule.CheckPackage(ctx, lints.NewPackage(fset, &lints.PackageFile{Filename: filename, File: node}))
+}
@@ -0,0 +1,92 @@
+package internal
+
+import (
+	"context"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/gnolang/tlin/internal/lints"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPackageRulesAcrossFiles(t *testing.T) {
+	t.Parallel()
+	root := t.TempDir()
+
+	writeFile(t, filepath.Join(root, "types.gno"), `package store
+
+type entry struct {
+	key   string
+	value int
+	flags int //nolint:unused-struct-field
+}
+`)
+	writeFile(t, filepath.Join(root, "use.gno"), `package store
+
+func keyOf(e entry) string { return e.key }
+
+func set(e *entry, v int) { e.value = v }
+`)
+
+	engine, err := NewEngine(root, nil, nil)
+	require.NoError(t, err)
+
+	issues, err := engine.Run(filepath.Join(root, "types.gno"))
+	require.NoError(t, err)
+
+	var messages []string
+	for _, issue := range issues {
+		if issue.Rule == "unused-struct-field" {
+			assert.Equal(t, filepath.Join(root, "types.gno"), issue.Filename)
+			messages = append(messages, issue.Message)
+		}
+	}
+	assert.Equal(t, []string{"field entry.value is written but never read"}, messages)
+
+	issues, err = engine.Run(filepath.Join(root, "use.gno"))
+	require.NoError(t, err)
+	for _, issue := range issues {
+		assert.NotEqual(t, "unused-struct-field", issue.Rule)
+	}
+}
+
+func TestPackageRuleOptions(t *testing.T) {
+	t.Parallel()
+	pkg := lints.NewPackage(token.NewFileSet())
+	sources := [][2]string{
+		{"a.gno", "package store\n\ntype T struct{}\n\nfunc (t T) A() {}\n"},
+		{"a_test.gno", "package store\n\nfunc (x T) B() {}\n"},
+		{"gen.gno", "// Code generated by gen. DO NOT EDIT.\n\npackage store\n\nfunc (y T) C() {}\n"},
+	}
+	for _, src := range sources {
+		file, err := parser.ParseFile(pkg.Fset, src[0], src[1], parser.ParseComments)
+		require.NoError(t, err)
+		pkg.Files = append(pkg.Files, &lints.PackageFile{Filename: src[0], File: file})
+	}
+
+	tests := []struct {
+		data  map[string]interface{}
+		files []string
+	}{
+		{data: nil, files: []string{"a_test.gno"}},
+		{data: map[string]interface{}{"skip-tests": true}, files: nil},
+		{data: map[string]interface{}{"skip-generated": false}, files: []string{"a_test.gno", "gen.gno"}},
+	}
+	for _, tc := range tests {
+		rule, _ := allRules.lookup("receiver-consistency")
+		rule = rule.withOptions(tc.data)
+		issues, err := rule.CheckPackage(context.Background(), pkg)
+		require.NoError(t, err)
+
+		var files []string
+		for _, issue := range issues {
+			files = append(files, issue.Filename)
+		}
+		sort.Strings(files)
+		assert.Equal(t, tc.files, files, "options %v", tc.data)
+	}
+}
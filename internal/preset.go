@@ -0,0 +1,80 @@
+package internal
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	tt "github.com/gnolang/tlin/internal/types"
+)
+
+// preset maps each rule to the severity it gets when the preset is selected.
+type preset func(rule LintRule) tt.Severity
+
+var presets = map[string]preset{
+	// correctness and performance rules, plus the style rules that rarely
+	// report false positives.
+	"recommended": func(rule LintRule) tt.Severity {
+		switch {
+		case rule.category == categoryStyle && !rule.highSignal:
+			return tt.SeverityOff
+		case rule.category == categoryComplexity:
+			return tt.SeverityOff
+		}
+		return enabledSeverity(rule)
+	},
+	// every rule.
+	"strict": func(rule LintRule) tt.Severity {
+		return enabledSeverity(rule)
+	},
+	// gno-specific rules as errors, rules relying on the Go toolchain off.
+	"gno-contract": func(rule LintRule) tt.Severity {
+		switch rule.scope {
+		case scopeGno:
+			return tt.SeverityError
+		case scopeGo:
+			return tt.SeverityOff
+		}
+		return rule.severity
+	},
+}
+
+// enabledSeverity returns the default severity of a rule, falling back to
+// a warning for rules that are off by default.
+func enabledSeverity(rule LintRule) tt.Severity {
+	if rule.severity == tt.SeverityOff {
+		return tt.SeverityWarning
+	}
+	return rule.severity
+}
+
+// PresetNames returns the names of the available presets.
+func PresetNames() []string {
+	names := make([]string, 0, len(presets))
+	for name := range presets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func unknownPresetError(name string) error {
+	return fmt.Errorf("unknown preset %q (expected one of %s)", name, strings.Join(PresetNames(), ", "))
+}
+
+// SetPreset selects the preset the rule set is built from. Settings from the
+// configuration files are applied over the preset. An empty name restores the
+// rule defaults.
+func (e *Engine) SetPreset(name string) error {
+	if name != "" {
+		if _, ok := presets[name]; !ok {
+			return unknownPresetError(name)
+		}
+	}
+
+	e.preset = name
+	e.dirConfigs = newDirConfigCache()
+	e.packages = newPackageCache()
+	e.rules = newRuleSet(e.ruleSettings(nil))
+	return nil
+}
@@ -0,0 +1,112 @@
+package internal
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tt "github.com/gnolang/tlin/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var updateGolden = flag.Bool("update", false, "update golden files")
+
+// TestPresetsGolden renders the effective rule set of every preset, so that
+// any change to a preset shows up in the golden file during review.
+func TestPresetsGolden(t *testing.T) {
+	t.Parallel()
+
+	var sb strings.Builder
+	for _, name := range PresetNames() {
+		engine, err := NewEngine("", nil, nil)
+		require.NoError(t, err)
+		require.NoError(t, engine.SetPreset(name))
+
+		configs, err := engine.EffectiveConfig("")
+		require.NoError(t, err)
+
+		fmt.Fprintf(&sb, "%s:\n", name)
+		for _, rule := range configs {
+			fmt.Fprintf(&sb, "  %s: %s\n", rule.Name, rule.Severity)
+		}
+	}
+
+	golden := filepath.Join("testdata", "presets.golden")
+	if *updateGolden {
+		require.NoError(t, os.WriteFile(golden, []byte(sb.String()), 0o644))
+	}
+
+	expected, err := os.ReadFile(golden)
+	require.NoError(t, err)
+	assert.Equal(t, string(expected), sb.String(), "preset drift, run `go test ./internal -run TestPresetsGolden -update` if intended")
+}
+
+func TestPresetOverrides(t *testing.T) {
+	t.Parallel()
+
+	engine, err := NewEngine("", nil, map[string]tt.ConfigRule{
+		"early-return-opportunity": {Severity: tt.SeverityError},
+	})
+	require.NoError(t, err)
+	require.NoError(t, engine.SetPreset("gno-contract"))
+
+	assert.NotContains(t, engine.rules, "golangci-lint")
+	assert.Equal(t, tt.SeverityError, engine.rules["unused-package"].Severity())
+	assert.Equal(t, tt.SeverityError, engine.rules["early-return-opportunity"].Severity())
+
+	configs, err := engine.EffectiveConfig("")
+	require.NoError(t, err)
+	assert.Equal(t, "preset gno-contract", findRuleConfig(configs, "unused-package").SeveritySource)
+	assert.Equal(t, "configuration", findRuleConfig(configs, "early-return-opportunity").SeveritySource)
+}
+
+func TestUnknownPreset(t *testing.T) {
+	t.Parallel()
+
+	engine, err := NewEngine("", nil, nil)
+	require.NoError(t, err)
+
+	err = engine.SetPreset("lenient")
+	require.Error(t, err)
+	assert.Equal(t, `unknown preset "lenient" (expected one of gno-contract, recommended, strict)`, err.Error())
+
+	err = ValidateConfig([]byte("preset: lenient\n"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `line 1: unknown preset "lenient"`)
+
+	assert.NoError(t, ValidateConfig([]byte("preset: strict\n")))
+}
+
+func TestPresetInNestedConfig(t *testing.T) {
+	t.Parallel()
+	root := t.TempDir()
+
+	writeFile(t, filepath.Join(root, "p", localConfigName), "preset: strict\n")
+	writeFile(t, filepath.Join(root, "p", "a.gno"), "package p\n")
+
+	engine, err := NewEngine(root, nil, nil)
+	require.NoError(t, err)
+
+	_, err = engine.EffectiveConfig(filepath.Join(root, "p", "a.gno"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "preset can only be set in the root configuration")
+}
+
+func TestExcludeInNestedConfig(t *testing.T) {
+	t.Parallel()
+	root := t.TempDir()
+
+	writeFile(t, filepath.Join(root, "p", localConfigName), "exclude:\n  - \"*.gno\"\n")
+	writeFile(t, filepath.Join(root, "p", "a.gno"), "package p\n")
+
+	engine, err := NewEngine(root, nil, nil)
+	require.NoError(t, err)
+
+	_, err = engine.EffectiveConfig(filepath.Join(root, "p", "a.gno"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "exclude can only be set in the root configuration")
+}
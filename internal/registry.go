@@ -0,0 +1,215 @@
+package internal
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/gnolang/tlin/internal/lints"
+	tt "github.com/gnolang/tlin/internal/types"
+	"golang.org/x/tools/go/analysis"
+)
+
+// categories lists the categories a rule may belong to.
+var categories = []string{categoryComplexity, categoryCorrectness, categoryPerformance, categoryStyle}
+
+// registry holds the rules by name. It is safe for concurrent use, so rules
+// may be registered while engines are being built.
+type registry struct {
+	mu    sync.RWMutex
+	rules ruleMap
+}
+
+func newRegistry(rules ruleMap) *registry {
+	r := &registry{rules: make(ruleMap, len(rules))}
+	for name, rule := range rules {
+		rule.name = name
+		r.rules[name] = rule
+	}
+	return r
+}
+
+// lookup returns the rule with the given name.
+func (r *registry) lookup(name string) (LintRule, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	rule, ok := r.rules[name]
+	return rule, ok
+}
+
+// all returns a copy of the rules, by name.
+func (r *registry) all() ruleMap {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	rules := make(ruleMap, len(r.rules))
+	for name, rule := range r.rules {
+		rules[name] = rule
+	}
+	return rules
+}
+
+// names returns the names of the rules, sorted.
+func (r *registry) names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	names := make([]string, 0, len(r.rules))
+	for name := range r.rules {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (r *registry) register(rule LintRule) error {
+	switch {
+	case rule.name == "":
+		return errors.New("rule has no name")
+	case rule.name == unusedIgnoreRule:
+		return fmt.Errorf("rule name %q is reserved", rule.name)
+	case rule.check == nil && rule.checkPackage == nil && rule.checkTyped == nil && rule.configure == nil && rule.configurePackage == nil:
+		return fmt.Errorf("rule %q has no check function", rule.name)
+	}
+	if !isCategory(rule.category) {
+		return fmt.Errorf("rule %q has unknown category %q (expected one of %s)", rule.name, rule.category, strings.Join(categories, ", "))
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	// a rule pack loaded again replaces its own rules
+	if taken, ok := r.rules[rule.name]; ok && (taken.pack == "" || taken.pack != rule.pack) {
+		return fmt.Errorf("rule %q is already registered", rule.name)
+	}
+	r.rules[rule.name] = rule
+	return nil
+}
+
+func isCategory(category string) bool {
+	for _, c := range categories {
+		if c == category {
+			return true
+		}
+	}
+	return false
+}
+
+// NewLintRule returns a rule checking single files, for RegisterRule. The
+// category is one of correctness, style, performance and complexity; it
+// decides whether the presets enable the rule.
+func NewLintRule(
+	name, description, category string,
+	severity tt.Severity,
+	check func(filename string, node *ast.File, fset *token.FileSet, severity tt.Severity) ([]tt.Issue, error),
+) LintRule {
+	return LintRule{
+		name:        name,
+		description: description,
+		category:    category,
+		severity:    severity,
+		check:       check,
+	}
+}
+
+// NewPackageLintRule returns a rule checking all the files of a package at
+// once, for RegisterRule. The files share the file set of the package, and
+// the engine only reports the issues located in the file being linted.
+func NewPackageLintRule(
+	name, description, category string,
+	severity tt.Severity,
+	check func(pkg *lints.Package, severity tt.Severity) ([]tt.Issue, error),
+) LintRule {
+	return LintRule{
+		name:         name,
+		description:  description,
+		category:     category,
+		severity:     severity,
+		checkPackage: check,
+	}
+}
+
+// NewTypedLintRule returns a rule checking single files with the type
+// information of their package, for RegisterRule. The information covers the
+// whole package, so that types declared in other files are resolved, and is
+// shared with the other typed rules.
+func NewTypedLintRule(
+	name, description, category string,
+	severity tt.Severity,
+	check func(filename string, node *ast.File, fset *token.FileSet, info *types.Info, severity tt.Severity) ([]tt.Issue, error),
+) LintRule {
+	return LintRule{
+		name:        name,
+		description: description,
+		category:    category,
+		severity:    severity,
+		checkTyped:  check,
+	}
+}
+
+// NewAnalyzerRule wraps a go/analysis analyzer as a rule named after it, for
+// RegisterRule. Its diagnostics become issues, their first suggested fix
+// included; see lints.RunAnalyzer for what differs from go vet.
+func NewAnalyzerRule(a *analysis.Analyzer, category string, severity tt.Severity) LintRule {
+	description, _, _ := strings.Cut(a.Doc, "\n")
+	return NewPackageLintRule(a.Name, description, category, severity,
+		func(pkg *lints.Package, severity tt.Severity) ([]tt.Issue, error) {
+			return lints.RunAnalyzer(pkg, a, severity)
+		})
+}
+
+// RegisterRule adds a rule to the ones the engines run. It fails when the
+// rule is incomplete or its name is taken. Engines only run the rules
+// registered before they were created.
+func RegisterRule(rule LintRule) error {
+	return allRules.register(rule)
+}
+
+// RuleInfo describes a registered rule and the options it accepts, for
+// listings and documentation.
+type RuleInfo struct {
+	Name        string
+	Description string
+	Category    string
+	Severity    tt.Severity
+	Options     []tt.RuleOption
+}
+
+// Rules returns the registered rules, sorted by name.
+func Rules() []RuleInfo {
+	names := allRules.names()
+	rules := make([]RuleInfo, 0, len(names))
+	for _, name := range names {
+		if rule, ok := LookupRule(name); ok {
+			rules = append(rules, rule)
+		}
+	}
+	return rules
+}
+
+// RulesByCategory returns the registered rules grouped by category, each
+// group sorted by name.
+func RulesByCategory() map[string][]RuleInfo {
+	groups := make(map[string][]RuleInfo)
+	for _, rule := range Rules() {
+		groups[rule.Category] = append(groups[rule.Category], rule)
+	}
+	return groups
+}
+
+// LookupRule returns the registered rule with the given name.
+func LookupRule(name string) (RuleInfo, bool) {
+	rule, ok := allRules.lookup(name)
+	if !ok {
+		return RuleInfo{}, false
+	}
+	return RuleInfo{
+		Name:        rule.name,
+		Description: rule.description,
+		Category:    rule.category,
+		Severity:    rule.severity,
+		Options:     rule.options,
+	}, true
+}
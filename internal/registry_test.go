@@ -0,0 +1,97 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"testing"
+
+	"github.com/gnolang/tlin/internal/lints"
+	tt "github.com/gnolang/tlin/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegistry(t *testing.T) {
+	t.Parallel()
+
+	check := func(string, *ast.File, *token.FileSet, tt.Severity) ([]tt.Issue, error) {
+		return nil, nil
+	}
+	r := newRegistry(ruleMap{"useless-break": UselessBreakRule})
+
+	rule := NewLintRule("no-todo", "Reports TODO comments.", categoryStyle, tt.SeverityInfo, check)
+	require.NoError(t, r.register(rule))
+	assert.Equal(t, []string{"no-todo", "useless-break"}, r.names())
+	assert.False(t, rule.IsPackageRule())
+
+	typed := NewTypedLintRule("typed", "Reports nothing.", categoryCorrectness, tt.SeverityWarning,
+		func(string, *ast.File, *token.FileSet, *types.Info, tt.Severity) ([]tt.Issue, error) {
+			return nil, nil
+		})
+	require.NoError(t, r.register(typed))
+	assert.True(t, typed.IsPackageRule(), "typed rules run on whole packages")
+
+	pkgRule := NewPackageLintRule("files", "Counts files.", categoryStyle, tt.SeverityInfo,
+		func(pkg *lints.Package, severity tt.Severity) ([]tt.Issue, error) {
+			return []tt.Issue{{Rule: "files", Message: fmt.Sprint(len(pkg.Files)), Severity: severity}}, nil
+		})
+	require.NoError(t, r.register(pkgRule))
+	assert.True(t, pkgRule.IsPackageRule())
+	issues, err := pkgRule.CheckPackage(context.Background(), lints.NewPackage(token.NewFileSet()))
+	require.NoError(t, err)
+	assert.Equal(t, "0", issues[0].Message)
+
+	analyzerRule := NewAnalyzerRule(lints.RepeatedRegexCompilationAnalyzer, categoryPerformance, tt.SeverityWarning)
+	assert.Equal(t, "repeatedregexcompilation", analyzerRule.Name())
+	assert.Equal(t, lints.RepeatedRegexCompilationAnalyzer.Doc, analyzerRule.Description())
+	assert.True(t, analyzerRule.IsPackageRule())
+
+	got, ok := r.lookup("no-todo")
+	require.True(t, ok)
+	assert.Equal(t, "no-todo", got.Name())
+	assert.Equal(t, tt.SeverityInfo, got.Severity())
+	// builtin rules are named after their key
+	got, _ = r.lookup("useless-break")
+	assert.Equal(t, "useless-break", got.Name())
+
+	tests := []struct {
+		name string
+		rule LintRule
+		want string
+	}{
+		{"taken name", rule, `rule "no-todo" is already registered`},
+		{"no name", NewLintRule("", "", categoryStyle, tt.SeverityInfo, check), "rule has no name"},
+		{"reserved name", NewLintRule(unusedIgnoreRule, "", categoryStyle, tt.SeverityInfo, check), "is reserved"},
+		{"no check", NewLintRule("no-check", "", categoryStyle, tt.SeverityInfo, nil), "has no check function"},
+		{"unknown category", NewLintRule("no-fixme", "", "naming", tt.SeverityInfo, check), `unknown category "naming"`},
+	}
+	for _, tc := range tests {
+		assert.ErrorContains(t, r.register(tc.rule), tc.want, tc.name)
+	}
+	assert.Len(t, r.names(), 4)
+
+	// the builtin rules can't be replaced
+	assert.ErrorContains(t, RegisterRule(NewLintRule("useless-break", "", categoryStyle, tt.SeverityInfo, check)), "already registered")
+}
+
+func TestRulesByCategory(t *testing.T) {
+	t.Parallel()
+
+	groups := RulesByCategory()
+	count := 0
+	for category, rules := range groups {
+		assert.True(t, isCategory(category), category)
+		for i, rule := range rules {
+			assert.Equal(t, category, rule.Category)
+			if i > 0 {
+				assert.Less(t, rules[i-1].Name, rule.Name)
+			}
+		}
+		count += len(rules)
+	}
+	assert.Equal(t, len(Rules()), count)
+	assert.NotEmpty(t, groups[categoryCorrectness])
+}
@@ -0,0 +1,321 @@
+package internal
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/gnolang/tlin/fixer_v2/query"
+	tt "github.com/gnolang/tlin/internal/types"
+	"gopkg.in/yaml.v3"
+)
+
+// rulePack is a file of rules declared in YAML, each reporting the code
+// matching a pattern and rewriting it with a template:
+//
+//	rules:
+//	  - name: must-over-panic
+//	    match: "if :[x] != nil { panic(:[x]) }"
+//	    rewrite: "must(:[x])"
+//	    message: "use must instead of panicking on :[x]"
+//	    severity: WARNING
+type rulePack struct {
+	Rules []packRule `yaml:"rules"`
+}
+
+type packRule struct {
+	Name string `yaml:"name"`
+	// Match is the pattern of the code to report, in the query language.
+	Match string `yaml:"match"`
+	// Where restricts the matches to those whose captures satisfy the
+	// conditions of a where clause.
+	Where string `yaml:"where"`
+	// AST restricts the matches to whole statements, expressions and
+	// declarations of the file.
+	AST bool `yaml:"ast"`
+	// Rewrite is the template replacing the matched code, without fix when
+	// empty. Message may use the holes of the pattern too.
+	Rewrite     string `yaml:"rewrite"`
+	Message     string `yaml:"message"`
+	Description string `yaml:"description"`
+	Severity    string `yaml:"severity"`
+	Category    string `yaml:"category"`
+}
+
+// packRuleConfidence is the confidence of the issues of the rule packs: the
+// pattern states exactly what to rewrite, but knows nothing of the types.
+const packRuleConfidence = 0.9
+
+// LoadRulePack registers the rules of the rule pack at path, like
+// RegisterRule, or of every .yaml and .yml file under path when it is a
+// directory, hidden directories aside. Loading the same pack again replaces
+// its rules, while a name taken by another rule is an error. The rules run
+// on the engines created afterwards.
+func LoadRulePack(path string) error {
+	return allRules.loadRulePack(path)
+}
+
+// CheckRulePack reports the errors LoadRulePack would fail with, without
+// registering any rule.
+func CheckRulePack(path string) error {
+	return newRegistry(allRules.all()).loadRulePack(path)
+}
+
+func (r *registry) loadRulePack(path string) error {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return err
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("failed to read rule pack: %w", err)
+	}
+	if !info.IsDir() {
+		return r.loadRulePackFile(path)
+	}
+
+	var errs []error
+	err = filepath.WalkDir(path, func(file string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() && file != path && strings.HasPrefix(entry.Name(), ".") {
+			return filepath.SkipDir
+		}
+		if ext := filepath.Ext(file); !entry.IsDir() && (ext == ".yaml" || ext == ".yml") {
+			errs = append(errs, r.loadRulePackFile(file))
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to read rule packs: %w", err)
+	}
+	return errors.Join(errs...)
+}
+
+func (r *registry) loadRulePackFile(path string) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read rule pack: %w", err)
+	}
+
+	var pack rulePack
+	decoder := yaml.NewDecoder(bytes.NewReader(content))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&pack); err != nil {
+		return fmt.Errorf("invalid rule pack %s: %w", path, err)
+	}
+
+	var errs []error
+	seen := make(map[string]bool)
+	scan := new(packScan)
+	for _, spec := range pack.Rules {
+		if seen[spec.Name] {
+			errs = append(errs, fmt.Errorf("rule %q is declared twice", spec.Name))
+			continue
+		}
+		seen[spec.Name] = true
+		rule, err := spec.lintRule(path, scan)
+		if err == nil {
+			err = r.register(rule)
+		}
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		return fmt.Errorf("invalid rule pack %s: %w", path, err)
+	}
+	return nil
+}
+
+// lintRule compiles the rule declared in the pack at path, whose text
+// patterns are searched by scan.
+func (p packRule) lintRule(path string, scan *packScan) (LintRule, error) {
+	if p.Name == "" {
+		return LintRule{}, errors.New("rule has no name")
+	}
+	if p.Match == "" || p.Message == "" {
+		return LintRule{}, fmt.Errorf("rule %q needs a match pattern and a message", p.Name)
+	}
+
+	matcher, err := query.Compile(p.Match)
+	if err != nil {
+		return LintRule{}, fmt.Errorf("rule %q: invalid match pattern: %w", p.Name, err)
+	}
+	if p.Where != "" {
+		if err := matcher.Where(p.Where); err != nil {
+			return LintRule{}, fmt.Errorf("rule %q: %w", p.Name, err)
+		}
+	}
+	message, err := packTemplate(matcher, p.Message)
+	if err != nil {
+		return LintRule{}, fmt.Errorf("rule %q: invalid message: %w", p.Name, err)
+	}
+	var rewrite *query.Template
+	if p.Rewrite != "" {
+		if rewrite, err = packTemplate(matcher, p.Rewrite); err != nil {
+			return LintRule{}, fmt.Errorf("rule %q: invalid rewrite: %w", p.Name, err)
+		}
+	}
+
+	severity := tt.SeverityWarning
+	if p.Severity != "" {
+		if severity, err = tt.ParseSeverity(p.Severity); err != nil {
+			return LintRule{}, fmt.Errorf("rule %q: %w", p.Name, err)
+		}
+	}
+	category := p.Category
+	if category == "" {
+		category = categoryStyle
+	}
+	description := p.Description
+	if description == "" {
+		description = p.Message
+	}
+
+	find := func(filename string, src []byte, node *ast.File, fset *token.FileSet) ([]query.Match, error) {
+		return matcher.FindNodes(fset, node, src)
+	}
+	if !p.AST {
+		find = scan.add(matcher)
+	}
+	rule := NewLintRule(p.Name, description, category, severity, matchCheck(p.Name, find, message, rewrite))
+	rule.pack = path
+	// reformatting the pattern doesn't change what the rule finds
+	pattern, _ := query.Parse(p.Match)
+	rule.definition = fmt.Sprintf("%q %q %t %q %q", query.Format(pattern), p.Where, p.AST, p.Rewrite, p.Message)
+	return rule, nil
+}
+
+// packTemplate parses a template using the holes of the pattern.
+func packTemplate(matcher *query.Matcher, text string) (*query.Template, error) {
+	template, err := query.ParseTemplate(text)
+	if err != nil {
+		return nil, err
+	}
+	bound := make(map[string]bool)
+	for _, name := range matcher.Holes() {
+		bound[name] = true
+	}
+	for _, name := range template.Holes() {
+		if !bound[name] {
+			return nil, fmt.Errorf("the pattern has no hole %s", name)
+		}
+	}
+	return template, nil
+}
+
+// matchFinder returns the matches of the pattern of a rule in a file.
+type matchFinder func(filename string, src []byte, node *ast.File, fset *token.FileSet) ([]query.Match, error)
+
+// matchCheck reports the code the finder matches, with the rewrite as fix
+// if any.
+func matchCheck(name string, find matchFinder, message, rewrite *query.Template) checkFunc {
+	return func(filename string, node *ast.File, fset *token.FileSet, severity tt.Severity) ([]tt.Issue, error) {
+		src, err := os.ReadFile(filename)
+		if err != nil {
+			return nil, err
+		}
+		file := fset.File(node.Pos())
+
+		matches, err := find(filename, src, node, fset)
+		if err != nil {
+			return nil, err
+		}
+		var issues []tt.Issue
+		for _, match := range matches {
+			text, err := message.Expand(match.Bindings)
+			if err != nil {
+				return nil, err
+			}
+			loc := match.Location(file)
+			issue := tt.Issue{
+				Rule:     name,
+				Filename: filename,
+				Message:  text,
+				Start:    loc.Start,
+				End:      loc.End,
+				Severity: severity,
+			}
+			// without a rewrite, -fix leaves the match alone
+			if rewrite != nil {
+				replacement, err := rewrite.Expand(match.Bindings)
+				if err != nil {
+					return nil, err
+				}
+				issue.Suggestion = replacement
+				issue.Confidence = packRuleConfidence
+				issue.SuggestedFix = []tt.TextEdit{{Start: issue.Start, End: issue.End, NewText: replacement}}
+			}
+			issues = append(issues, issue)
+		}
+		return issues, nil
+	}
+}
+
+// packScanFiles is the number of files whose matches a packScan keeps,
+// more than the engine checks at once.
+const packScanFiles = 64
+
+// packScan searches a file for the text patterns of the rules of a pack in
+// a single pass: the first of the rules checking a file searches it for
+// all of them, and the others reuse the matches.
+type packScan struct {
+	matchers []*query.Matcher
+	once     sync.Once
+	set      *query.MatcherSet
+
+	mu sync.Mutex
+	// recent holds the results of the last files searched, the most recent
+	// last
+	recent []packScanResult
+}
+
+type packScanResult struct {
+	filename string
+	src      string
+	results  []query.SetResult
+}
+
+// add adds a pattern to the scan, while the pack is loaded, and returns
+// its finder.
+func (s *packScan) add(matcher *query.Matcher) matchFinder {
+	i := len(s.matchers)
+	s.matchers = append(s.matchers, matcher)
+	return func(filename string, src []byte, _ *ast.File, _ *token.FileSet) ([]query.Match, error) {
+		result := s.results(filename, src)[i]
+		return result.Matches, result.Err
+	}
+}
+
+func (s *packScan) results(filename string, src []byte) []query.SetResult {
+	s.once.Do(func() {
+		s.set = query.NewMatcherSet(s.matchers...)
+	})
+
+	s.mu.Lock()
+	for _, r := range s.recent {
+		if r.filename == filename && r.src == string(src) {
+			s.mu.Unlock()
+			return r.results
+		}
+	}
+	s.mu.Unlock()
+
+	results := s.set.FindAll(src)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.recent = append(s.recent, packScanResult{filename: filename, src: string(src), results: results})
+	if len(s.recent) > packScanFiles {
+		s.recent = s.recent[1:]
+	}
+	return results
+}
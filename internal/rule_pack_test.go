@@ -0,0 +1,250 @@
+package internal
+
+import (
+	"context"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gnolang/tlin/fixer_v2/query"
+	tt "github.com/gnolang/tlin/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadRulePack(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	pack := filepath.Join(dir, "pack.yaml")
+	require.NoError(t, os.WriteFile(pack, []byte(`rules:
+  - name: must-over-panic
+    match: "if :[x] != nil { panic(:[x]) }"
+    rewrite: "must(:[x])"
+    message: "use must instead of panicking on :[x]"
+    severity: ERROR
+  - name: no-println
+    match: "println(:[_])"
+    message: "println is for debugging"
+`), 0o644))
+
+	r := newRegistry(ruleMap{"useless-break": UselessBreakRule})
+	require.NoError(t, r.loadRulePack(pack))
+	require.NoError(t, r.loadRulePack(pack), "loading a pack again replaces its rules")
+	assert.Equal(t, []string{"must-over-panic", "no-println", "useless-break"}, r.names())
+
+	rule, ok := r.lookup("must-over-panic")
+	require.True(t, ok)
+	assert.Equal(t, tt.SeverityError, rule.Severity())
+	assert.Equal(t, categoryStyle, rule.category)
+	assert.Equal(t, "use must instead of panicking on :[x]", rule.Description())
+
+	filename := filepath.Join(dir, "a.gno")
+	src := "package a\n\nfunc f(err error) {\n\tif err != nil {\n\t\tpanic(err)\n\t}\n}\n"
+	require.NoError(t, os.WriteFile(filename, []byte(src), 0o644))
+	fset := token.NewFileSet()
+	node, err := parser.ParseFile(fset, filename, src, parser.ParseComments)
+	require.NoError(t, err)
+
+	issues, err := rule.Check(context.Background(), filename, node, fset)
+	require.NoError(t, err)
+	require.Len(t, issues, 1)
+	issue := issues[0]
+	assert.Equal(t, "must-over-panic", issue.Rule)
+	assert.Equal(t, "use must instead of panicking on err", issue.Message)
+	assert.Equal(t, 4, issue.Start.Line)
+	assert.Equal(t, 6, issue.End.Line)
+	assert.Equal(t, packRuleConfidence, issue.Confidence)
+	require.Len(t, issue.SuggestedFix, 1)
+	assert.Equal(t, "must(err)", issue.SuggestedFix[0].NewText)
+	assert.Equal(t, src[issue.Start.Offset:issue.End.Offset], "if err != nil {\n\t\tpanic(err)\n\t}")
+
+	rule, _ = r.lookup("no-println")
+	issues, err = rule.Check(context.Background(), filename, node, fset)
+	require.NoError(t, err)
+	assert.Empty(t, issues)
+
+	// reformatting a pattern keeps the definition of its rule
+	definition := r.rules["must-over-panic"].definition
+	require.NoError(t, os.WriteFile(pack, []byte(`rules:
+  - name: must-over-panic
+    match: "if :[x] != nil {\n\tpanic(:[x])\n}"
+    rewrite: "must(:[x])"
+    message: "use must instead of panicking on :[x]"
+    severity: ERROR
+`), 0o644))
+	require.NoError(t, r.loadRulePack(pack))
+	assert.Equal(t, definition, r.rules["must-over-panic"].definition)
+}
+
+func TestLoadRulePackWithoutRewrite(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	pack := filepath.Join(dir, "pack.yaml")
+	require.NoError(t, os.WriteFile(pack, []byte(`rules:
+  - name: no-println
+    match: "println(:[x])"
+    message: "println is for debugging"
+`), 0o644))
+	r := newRegistry(ruleMap{})
+	require.NoError(t, r.loadRulePack(pack))
+	rule, ok := r.lookup("no-println")
+	require.True(t, ok)
+
+	filename := filepath.Join(dir, "a.gno")
+	src := "package a\n\nfunc f(x int) {\n\tprintln(x)\n}\n"
+	require.NoError(t, os.WriteFile(filename, []byte(src), 0o644))
+	fset := token.NewFileSet()
+	node, err := parser.ParseFile(fset, filename, src, parser.ParseComments)
+	require.NoError(t, err)
+
+	issues, err := rule.Check(context.Background(), filename, node, fset)
+	require.NoError(t, err)
+	require.Len(t, issues, 1)
+	// with nothing to rewrite the match to, -fix must not delete it
+	assert.Zero(t, issues[0].Confidence)
+	assert.Empty(t, issues[0].Suggestion)
+	assert.Empty(t, issues[0].SuggestedFix)
+}
+
+func TestLoadRulePackWhere(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	pack := filepath.Join(dir, "pack.yaml")
+	require.NoError(t, os.WriteFile(pack, []byte(`rules:
+  - name: unchecked-origin
+    match: "func :[name](:[params]) { :[[body]] }"
+    where: 'not match(:[body], "std.AssertOriginCall()")'
+    message: ":[name] does not check that it is called by a user"
+`), 0o644))
+	r := newRegistry(ruleMap{})
+	require.NoError(t, r.loadRulePack(pack))
+	rule, ok := r.lookup("unchecked-origin")
+	require.True(t, ok)
+
+	filename := filepath.Join(dir, "a.gno")
+	src := "package a\n\nfunc Transfer() {\n\tstd.AssertOriginCall()\n}\n\nfunc Burn() {\n\tburn()\n}\n"
+	require.NoError(t, os.WriteFile(filename, []byte(src), 0o644))
+	fset := token.NewFileSet()
+	node, err := parser.ParseFile(fset, filename, src, parser.ParseComments)
+	require.NoError(t, err)
+
+	issues, err := rule.Check(context.Background(), filename, node, fset)
+	require.NoError(t, err)
+	require.Len(t, issues, 1)
+	assert.Equal(t, "Burn does not check that it is called by a user", issues[0].Message)
+}
+
+func TestLoadRulePackAST(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	pack := filepath.Join(dir, "pack.yaml")
+	require.NoError(t, os.WriteFile(pack, []byte(`rules:
+  - {name: text-sum, match: "a + b", message: m}
+  - {name: node-sum, match: "a + b", message: m, ast: true}
+`), 0o644))
+	r := newRegistry(ruleMap{})
+	require.NoError(t, r.loadRulePack(pack))
+
+	filename := filepath.Join(dir, "a.gno")
+	src := "package a\n\nvar x = a + b*c\nvar y = a + b\n"
+	require.NoError(t, os.WriteFile(filename, []byte(src), 0o644))
+	fset := token.NewFileSet()
+	node, err := parser.ParseFile(fset, filename, src, parser.ParseComments)
+	require.NoError(t, err)
+
+	for name, lines := range map[string][]int{"text-sum": {3, 4}, "node-sum": {4}} {
+		rule, ok := r.lookup(name)
+		require.True(t, ok)
+		issues, err := rule.Check(context.Background(), filename, node, fset)
+		require.NoError(t, err)
+		var got []int
+		for _, issue := range issues {
+			got = append(got, issue.Start.Line)
+		}
+		assert.Equal(t, lines, got, name)
+	}
+}
+
+func TestLoadRulePackErrors(t *testing.T) {
+	t.Parallel()
+	tests := map[string]string{
+		"taken name":       "rules:\n  - {name: useless-break, match: x, message: m}\n",
+		"unknown field":    "rules:\n  - {name: a, match: x, message: m, fix: y}\n",
+		"no message":       "rules:\n  - {name: a, match: x}\n",
+		"invalid pattern":  "rules:\n  - {name: a, match: ':[x', message: m}\n",
+		"unbound hole":     "rules:\n  - {name: a, match: 'f(:[x])', rewrite: 'g(:[y])', message: m}\n",
+		"invalid severity": "rules:\n  - {name: a, match: x, message: m, severity: FATAL}\n",
+		"unknown category": "rules:\n  - {name: a, match: x, message: m, category: misc}\n",
+		"declared twice":   "rules:\n  - {name: a, match: x, message: m}\n  - {name: a, match: y, message: m}\n",
+		"invalid where":    "rules:\n  - {name: a, match: 'f(:[x])', where: 'not match(:[y], \"g()\")', message: m}\n",
+	}
+	for name, content := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			pack := filepath.Join(t.TempDir(), "pack.yaml")
+			require.NoError(t, os.WriteFile(pack, []byte(content), 0o644))
+			r := newRegistry(ruleMap{"useless-break": UselessBreakRule})
+			assert.ErrorContains(t, r.loadRulePack(pack), "invalid rule pack")
+		})
+	}
+}
+
+func TestLoadRulePackDir(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	files := map[string]string{
+		"debug.yaml":         "rules:\n  - {name: no-println, match: 'println(:[_])', message: m}\n",
+		"nested/errors.yml":  "rules:\n  - {name: must-over-panic, match: 'panic(:[x])', message: m}\n",
+		"nested/README.md":   "not a rule pack",
+		".staging/pack.yaml": "rules:\n  - {name: useless-break, match: x, message: m}\n",
+	}
+	for name, content := range files {
+		file := filepath.Join(dir, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(file), 0o755))
+		require.NoError(t, os.WriteFile(file, []byte(content), 0o644))
+	}
+
+	r := newRegistry(ruleMap{"useless-break": UselessBreakRule})
+	require.NoError(t, r.loadRulePack(dir))
+	assert.Equal(t, []string{"must-over-panic", "no-println", "useless-break"}, r.names())
+
+	assert.ErrorContains(t, r.loadRulePack(filepath.Join(dir, ".staging")), "invalid rule pack")
+	assert.ErrorContains(t, r.loadRulePack(filepath.Join(dir, "missing")), "failed to read rule pack")
+}
+
+func TestCheckRulePack(t *testing.T) {
+	t.Parallel()
+	pack := filepath.Join(t.TempDir(), "pack.yaml")
+	require.NoError(t, os.WriteFile(pack, []byte("rules:\n  - {name: checked-only, match: x, message: m}\n"), 0o644))
+	require.NoError(t, CheckRulePack(pack))
+	_, ok := allRules.lookup("checked-only")
+	assert.False(t, ok, "checking a pack does not register its rules")
+}
+
+func TestPackScan(t *testing.T) {
+	t.Parallel()
+	scan := new(packScan)
+	var finders []matchFinder
+	for _, pattern := range []string{"panic(:[x])", "println(:[x])", ":[x] := 1"} {
+		matcher, err := query.Compile(pattern)
+		require.NoError(t, err)
+		finders = append(finders, scan.add(matcher))
+	}
+
+	src := []byte("package a\n\nfunc f() {\n\tx := 1\n\tprintln(x)\n\tpanic(x)\n}\n")
+	for i, want := range []string{"panic(x)", "println(x)", "x := 1"} {
+		matches, err := finders[i]("a.gno", src, nil, nil)
+		require.NoError(t, err)
+		require.Len(t, matches, 1)
+		assert.Equal(t, want, string(src[matches[0].Start:matches[0].End]))
+	}
+	assert.Len(t, scan.recent, 1, "the rules share the search of the file")
+
+	changed := append(src, "\nfunc g() { panic(1) }\n"...)
+	matches, err := finders[0]("a.gno", changed, nil, nil)
+	require.NoError(t, err)
+	assert.Len(t, matches, 2)
+	assert.Len(t, scan.recent, 2)
+}
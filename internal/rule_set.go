@@ -1,10 +1,17 @@
 package internal
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"go/ast"
 	"go/token"
+	"go/types"
+	"regexp"
+	"strings"
 
 	"github.com/gnolang/tlin/internal/lints"
+	"github.com/gnolang/tlin/internal/stdapi"
 	tt "github.com/gnolang/tlin/internal/types"
 )
 
@@ -12,11 +19,60 @@ import (
 * Implement each lint rule as a a new LintRule struct.
  */
 
+type checkFunc func(filename string, node *ast.File, fset *token.FileSet, severity tt.Severity) ([]tt.Issue, error)
+
+// packageCheckFunc checks all the files of a package at once.
+type packageCheckFunc func(pkg *lints.Package, severity tt.Severity) ([]tt.Issue, error)
+
+// rule categories, used to build the presets.
+const (
+	categoryCorrectness = "correctness"
+	categoryStyle       = "style"
+	categoryPerformance = "performance"
+	categoryComplexity  = "complexity"
+)
+
+// ruleScope tells which kind of source a rule is meaningful for.
+type ruleScope int
+
+const (
+	scopeAll ruleScope = iota
+	scopeGno           // gno-specific rule
+	scopeGo            // relies on the Go toolchain, not meaningful for gno contracts
+)
+
 // LintRule defines the struct for all lint rules.
 type LintRule struct {
 	severity tt.Severity
-	check    func(filename string, node *ast.File, fset *token.FileSet, severity tt.Severity) ([]tt.Issue, error)
-	name     string
+	check    checkFunc
+	// checkPackage is set instead of check for rules working on whole packages.
+	checkPackage packageCheckFunc
+	// checkTyped is set instead of check for rules needing the type
+	// information of the package; they run as package rules, see CheckTyped.
+	checkTyped  lints.TypedCheck
+	name        string
+	description string
+	category    string
+	scope       ruleScope
+	// highSignal marks style rules that rarely report false positives.
+	highSignal bool
+	// options declares the values a rule accepts under `data` in the configuration file.
+	options []tt.RuleOption
+	// configure builds the check function from resolved option values.
+	// It is only set for rules that declare options.
+	configure func(values map[string]interface{}) checkFunc
+	// configurePackage is the counterpart of configure for package rules.
+	configurePackage func(values map[string]interface{}) packageCheckFunc
+	// config describes the severity and options the rule was built with,
+	// which the issues of the result cache depend on.
+	config string
+	// uncached marks the rules depending on more than the files of the
+	// package, whose issues the result cache can't keep.
+	uncached bool
+	// pack is the path of the rule pack declaring the rule, if any, and
+	// definition what the issues depend on besides the configuration.
+	pack       string
+	definition string
 }
 
 func (r LintRule) Severity() tt.Severity {
@@ -27,29 +83,913 @@ func (r LintRule) Name() string {
 	return r.name
 }
 
-func (r LintRule) Check(filename string, node *ast.File, fset *token.FileSet) ([]tt.Issue, error) {
-	return r.check(filename, node, fset, r.severity)
+func (r LintRule) Description() string {
+	return r.description
+}
+
+func (r LintRule) Options() []tt.RuleOption {
+	return r.options
+}
+
+// Check runs the rule on a file. It gives up once ctx is done, see
+// checkContext.
+func (r LintRule) Check(ctx context.Context, filename string, node *ast.File, fset *token.FileSet) ([]tt.Issue, error) {
+	return checkContext(ctx, func() ([]tt.Issue, error) {
+		return r.check(filename, node, fset, r.severity)
+	})
+}
+
+// IsPackageRule reports whether the rule checks whole packages instead of single files.
+func (r LintRule) IsPackageRule() bool {
+	return r.checkPackage != nil || r.configurePackage != nil || r.checkTyped != nil
+}
+
+// CheckPackage runs the package rule on a package. It gives up once ctx is
+// done, see checkContext.
+func (r LintRule) CheckPackage(ctx context.Context, pkg *lints.Package) ([]tt.Issue, error) {
+	return checkContext(ctx, func() ([]tt.Issue, error) {
+		if r.checkTyped != nil {
+			return lints.CheckTyped(pkg, r.checkTyped, r.severity)
+		}
+		return r.checkPackage(pkg, r.severity)
+	})
+}
+
+// checkContext runs check unless ctx is already done, and stops waiting for
+// it once ctx is done. The check functions don't take a context: a check
+// given up on keeps running in the background until it returns, and its
+// result is discarded.
+func checkContext(ctx context.Context, check func() ([]tt.Issue, error)) ([]tt.Issue, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if ctx.Done() == nil {
+		// the context is never done
+		return check()
+	}
+
+	type result struct {
+		issues []tt.Issue
+		err    error
+	}
+	done := make(chan result, 1)
+	go func() {
+		issues, err := check()
+		done <- result{issues, err}
+	}()
+	select {
+	case res := <-done:
+		return res.issues, res.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
 }
 
 var (
-	GolangciLintRule             = LintRule{severity: tt.SeverityWarning, check: lints.RunGolangciLint}
-	SimplifySliceExprRule        = LintRule{severity: tt.SeverityError, check: lints.DetectUnnecessarySliceLength}
-	UnnecessaryConversionRule    = LintRule{severity: tt.SeverityWarning, check: lints.DetectUnnecessaryConversions}
-	DetectCycleRule              = LintRule{severity: tt.SeverityError, check: lints.DetectCycle}
-	EmitFormatRule               = LintRule{severity: tt.SeverityInfo, check: lints.DetectEmitFormat}
-	UselessBreakRule             = LintRule{severity: tt.SeverityError, check: lints.DetectUselessBreak}
-	EarlyReturnOpportunityRule   = LintRule{severity: tt.SeverityInfo, check: lints.DetectEarlyReturnOpportunities}
-	DeferRule                    = LintRule{severity: tt.SeverityWarning, check: lints.DetectDeferIssues}
-	ConstErrorDeclarationRule    = LintRule{severity: tt.SeverityError, check: lints.DetectConstErrorDeclaration}
-	RepeatedRegexCompilationRule = LintRule{severity: tt.SeverityWarning, check: lints.DetectRepeatedRegexCompilation}
-	GnoSpecificRule              = LintRule{severity: tt.SeverityWarning, check: lints.DetectGnoPackageImports}
+	GolangciLintRule = LintRule{
+		severity:    tt.SeverityWarning,
+		check:       lints.RunGolangciLint,
+		description: "Runs golangci-lint on the file and reports its findings.",
+		category:    categoryCorrectness,
+		scope:       scopeGo,
+		uncached:    true,
+	}
+	SimplifySliceExprRule = LintRule{
+		severity:    tt.SeverityError,
+		check:       lints.DetectUnnecessarySliceLength,
+		description: "Detects unnecessary len() calls in slice expressions.",
+		category:    categoryStyle,
+		highSignal:  true,
+	}
+	UnnecessaryConversionRule = LintRule{
+		severity:    tt.SeverityWarning,
+		checkTyped:  lints.DetectUnnecessaryConversions,
+		description: "Detects type conversions to the type the value already has.",
+		category:    categoryStyle,
+	}
+	DetectCycleRule = LintRule{
+		severity:    tt.SeverityError,
+		check:       lints.DetectCycle,
+		description: "Detects cycles between functions, types and variables.",
+		category:    categoryCorrectness,
+	}
+	EmitFormatRule = LintRule{
+		severity:    tt.SeverityInfo,
+		check:       lints.DetectEmitFormat,
+		description: "Suggests a readable layout for std.Emit calls.",
+		category:    categoryStyle,
+		scope:       scopeGno,
+	}
+	UselessBreakRule = LintRule{
+		severity:    tt.SeverityError,
+		check:       lints.DetectUselessBreak,
+		description: "Detects break statements at the end of switch and select cases.",
+		category:    categoryStyle,
+		highSignal:  true,
+	}
+	EarlyReturnOpportunityRule = LintRule{
+		severity:    tt.SeverityInfo,
+		check:       lints.DetectEarlyReturnOpportunities,
+		description: "Suggests early returns to remove unnecessary else branches.",
+		category:    categoryStyle,
+	}
+	DeferRule = LintRule{
+		severity:    tt.SeverityWarning,
+		check:       lints.DetectDeferIssues,
+		description: "Detects defer misuse such as defers in loops or panics inside defers.",
+		category:    categoryCorrectness,
+	}
+	ConstErrorDeclarationRule = LintRule{
+		severity:    tt.SeverityError,
+		check:       lints.DetectConstErrorDeclaration,
+		description: "Detects errors declared as constants.",
+		category:    categoryCorrectness,
+	}
+	RepeatedRegexCompilationRule = LintRule{
+		severity:    tt.SeverityWarning,
+		check:       lints.DetectRepeatedRegexCompilation,
+		description: "Detects the same regular expression being compiled more than once.",
+		category:    categoryPerformance,
+		scope:       scopeGo,
+	}
+	GnoSpecificRule = LintRule{
+		severity:    tt.SeverityWarning,
+		check:       lints.DetectGnoPackageImports,
+		description: "Detects imported packages that are never used.",
+		category:    categoryCorrectness,
+		scope:       scopeGno,
+	}
+	UnusedStructFieldRule = LintRule{
+		severity:     tt.SeverityWarning,
+		checkPackage: lints.DetectUnusedStructFields,
+		description:  "Detects unexported struct fields that are never read or never used.",
+		category:     categoryStyle,
+		highSignal:   true,
+	}
+	RedundantCallerParamRule = LintRule{
+		severity:    tt.SeverityWarning,
+		description: "Detects functions taking the caller as a parameter while also deriving it from the realm context.",
+		category:    categoryCorrectness,
+		scope:       scopeGno,
+		options: []tt.RuleOption{
+			{
+				Name:        "names",
+				Description: "Address parameters whose name starts with one of these are treated as the caller.",
+				Type:        tt.OptionStringList,
+				Default:     lints.DefaultCallerParamNames,
+			},
+			{
+				Name:        "functions",
+				Description: "Calls deriving the caller, written as pkg.Func.",
+				Type:        tt.OptionStringList,
+				Default:     lints.DefaultCallerFuncs,
+			},
+		},
+		configure: func(values map[string]interface{}) checkFunc {
+			names := values["names"].([]string)
+			funcs := values["functions"].([]string)
+			return func(filename string, node *ast.File, fset *token.FileSet, severity tt.Severity) ([]tt.Issue, error) {
+				return lints.DetectRedundantCallerParam(filename, node, fset, severity, names, funcs)
+			}
+		},
+	}
+	DivisionByZeroRule = LintRule{
+		severity:    tt.SeverityWarning,
+		checkTyped:  lints.DetectDivisionByZero,
+		description: "Detects divisions and modulos by variables that are not checked against zero.",
+		category:    categoryCorrectness,
+	}
+	SlicePreallocRule = LintRule{
+		severity:    tt.SeverityWarning,
+		checkTyped:  lints.DetectSlicePrealloc,
+		description: "Detects slices appended to in loops of known length that could be allocated with a capacity.",
+		category:    categoryPerformance,
+	}
+	ReceiverConsistencyRule = LintRule{
+		severity:    tt.SeverityInfo,
+		description: "Detects methods of a type using different receiver names, or mixing pointer and value receivers.",
+		category:    categoryStyle,
+		options: []tt.RuleOption{
+			{
+				Name:        "skip-tests",
+				Description: "Ignore the methods declared in test files.",
+				Type:        tt.OptionBool,
+				Default:     false,
+			},
+			{
+				Name:        "skip-generated",
+				Description: "Ignore the methods declared in generated files.",
+				Type:        tt.OptionBool,
+				Default:     true,
+			},
+		},
+		configurePackage: func(values map[string]interface{}) packageCheckFunc {
+			skipTests := values["skip-tests"].(bool)
+			skipGenerated := values["skip-generated"].(bool)
+			return func(pkg *lints.Package, severity tt.Severity) ([]tt.Issue, error) {
+				pkg = pkg.Filter(func(f *lints.PackageFile) bool {
+					return !(skipTests && f.IsTest()) && !(skipGenerated && f.IsGenerated())
+				})
+				return lints.DetectReceiverInconsistency(pkg, severity)
+			}
+		},
+	}
+	ExportedNamingRule = LintRule{
+		severity:    tt.SeverityInfo,
+		description: "Detects exported identifiers repeating the package name, containing underscores or miscasing initialisms.",
+		category:    categoryStyle,
+		options: []tt.RuleOption{
+			{
+				Name:        "initialisms",
+				Description: "Initialisms that must be written in a single case, such as ID or URL.",
+				Type:        tt.OptionStringList,
+				Default:     lints.DefaultInitialisms,
+			},
+		},
+		configurePackage: func(values map[string]interface{}) packageCheckFunc {
+			initialisms := values["initialisms"].([]string)
+			return func(pkg *lints.Package, severity tt.Severity) ([]tt.Issue, error) {
+				return lints.DetectNamingIssues(pkg, severity, initialisms)
+			}
+		},
+	}
+	ReadabilityLimitsRule = LintRule{
+		severity:    tt.SeverityInfo,
+		description: "Detects lines that are too long and call chains that are too deep.",
+		category:    categoryStyle,
+		options: []tt.RuleOption{
+			{
+				Name:        "line-length",
+				Description: "Report lines longer than max-line-length.",
+				Type:        tt.OptionBool,
+				Default:     true,
+			},
+			{
+				Name:        "max-line-length",
+				Description: "Maximum number of columns of a line.",
+				Type:        tt.OptionInt,
+				Default:     140,
+				Min:         tt.Limit(1),
+			},
+			{
+				Name:        "tab-width",
+				Description: "Number of columns a tab counts for.",
+				Type:        tt.OptionInt,
+				Default:     4,
+				Min:         tt.Limit(1),
+			},
+			{
+				Name:        "chain-depth",
+				Description: "Report call chains deeper than max-chain-depth.",
+				Type:        tt.OptionBool,
+				Default:     true,
+			},
+			{
+				Name:        "max-chain-depth",
+				Description: "Maximum number of chained method calls in an expression.",
+				Type:        tt.OptionInt,
+				Default:     5,
+				Min:         tt.Limit(1),
+			},
+		},
+		configure: func(values map[string]interface{}) checkFunc {
+			limits := lints.ReadabilityLimits{
+				CheckLineLength: values["line-length"].(bool),
+				MaxLineLength:   values["max-line-length"].(int),
+				TabWidth:        values["tab-width"].(int),
+				CheckChainDepth: values["chain-depth"].(bool),
+				MaxChainDepth:   values["max-chain-depth"].(int),
+			}
+			return func(filename string, node *ast.File, fset *token.FileSet, severity tt.Severity) ([]tt.Issue, error) {
+				return lints.DetectReadabilityLimits(filename, node, fset, severity, limits)
+			}
+		},
+	}
+	UntestedExportsRule = LintRule{
+		severity:     tt.SeverityInfo,
+		checkPackage: lints.DetectUntestedExports,
+		description:  "Detects library packages without tests and exported functions never referenced by tests.",
+		category:     categoryStyle,
+	}
+	DuplicateConstantRule = LintRule{
+		severity:    tt.SeverityWarning,
+		description: "Detects constants declaring the same value under different names, or different values across build variants.",
+		category:    categoryCorrectness,
+		options: []tt.RuleOption{
+			{
+				Name:        "min-int",
+				Description: "Integer constants whose absolute value is lower are ignored.",
+				Type:        tt.OptionInt,
+				Default:     2,
+				Min:         tt.Limit(0),
+			},
+			{
+				Name:        "min-string-length",
+				Description: "String constants shorter than this are ignored.",
+				Type:        tt.OptionInt,
+				Default:     1,
+				Min:         tt.Limit(0),
+			},
+		},
+		configurePackage: func(values map[string]interface{}) packageCheckFunc {
+			significance := lints.ConstantSignificance{
+				MinInt:          int64(values["min-int"].(int)),
+				MinStringLength: values["min-string-length"].(int),
+			}
+			return func(pkg *lints.Package, severity tt.Severity) ([]tt.Issue, error) {
+				return lints.DetectDuplicateConstants(pkg, severity, significance)
+			}
+		},
+	}
+	InitSideEffectsRule = LintRule{
+		severity:     tt.SeverityWarning,
+		checkPackage: lints.DetectInitSideEffects,
+		description:  "Detects unbounded loops, events and banker calls running in init at deployment.",
+		category:     categoryCorrectness,
+		scope:        scopeGno,
+	}
+	UnusedErrorRule = LintRule{
+		severity:    tt.SeverityWarning,
+		check:       lints.DetectUnusedErrors,
+		description: "Detects errors that are created but never returned or otherwise used.",
+		category:    categoryCorrectness,
+	}
+	StructTagRule = LintRule{
+		severity:    tt.SeverityWarning,
+		description: "Detects malformed struct tags, duplicate or unknown json tag settings and tags on unexported fields.",
+		category:    categoryCorrectness,
+		options: []tt.RuleOption{
+			{
+				Name:        "all-fields-tagged",
+				Description: "Type name patterns whose exported fields must all have a json tag.",
+				Type:        tt.OptionStringList,
+				Default:     []string{},
+			},
+		},
+		configure: func(values map[string]interface{}) checkFunc {
+			taggedTypes := values["all-fields-tagged"].([]string)
+			return func(filename string, node *ast.File, fset *token.FileSet, severity tt.Severity) ([]tt.Issue, error) {
+				return lints.DetectStructTagIssues(filename, node, fset, severity, taggedTypes)
+			}
+		},
+	}
+	FloatComparisonRule = LintRule{
+		severity:    tt.SeverityWarning,
+		checkTyped:  lints.DetectFloatComparison,
+		description: "Detects floating point values compared with == or !=.",
+		category:    categoryCorrectness,
+	}
+	DuplicateImportRule = LintRule{
+		severity:    tt.SeverityWarning,
+		check:       lints.DetectDuplicateImports,
+		description: "Detects packages imported several times in a file and redundant import aliases.",
+		category:    categoryStyle,
+		highSignal:  true,
+	}
+	LargeLiteralRule = LintRule{
+		severity:    tt.SeverityWarning,
+		description: "Detects large composite literals rebuilt on every call of a function.",
+		category:    categoryPerformance,
+		options: []tt.RuleOption{
+			{
+				Name:        "max-elements",
+				Description: "Maximum number of elements, nested ones included, of a literal built in a function.",
+				Type:        tt.OptionInt,
+				Default:     50,
+				Min:         tt.Limit(1),
+			},
+		},
+		configure: func(values map[string]interface{}) checkFunc {
+			maxElements := values["max-elements"].(int)
+			return func(filename string, node *ast.File, fset *token.FileSet, severity tt.Severity) ([]tt.Issue, error) {
+				return lints.DetectLargeLiterals(filename, node, fset, severity, maxElements)
+			}
+		},
+	}
+	ParameterMutationRule = LintRule{
+		severity:    tt.SeverityInfo,
+		description: "Detects exported functions mutating their pointer, slice or map parameters without documenting it.",
+		category:    categoryCorrectness,
+		options: []tt.RuleOption{
+			{
+				Name:        "keywords",
+				Description: "Doc comment words documenting that a function mutates its parameters.",
+				Type:        tt.OptionStringList,
+				Default:     lints.DefaultMutationKeywords,
+			},
+		},
+		configure: func(values map[string]interface{}) checkFunc {
+			keywords := values["keywords"].([]string)
+			return func(filename string, node *ast.File, fset *token.FileSet, severity tt.Severity) ([]tt.Issue, error) {
+				return lints.DetectParameterMutation(filename, node, fset, severity, keywords)
+			}
+		},
+	}
+	TypeAssertionChainRule = LintRule{
+		severity:    tt.SeverityInfo,
+		check:       lints.DetectTypeAssertionChains,
+		description: "Detects if/else-if chains of type assertions on the same value that could be a type switch.",
+		category:    categoryStyle,
+	}
+	RealmStateTypesRule = LintRule{
+		severity:    tt.SeverityWarning,
+		description: "Detects realm state holding wall-clock times, channels or function values.",
+		category:    categoryCorrectness,
+		scope:       scopeGno,
+		options: []tt.RuleOption{
+			{
+				Name:        "allowed-types",
+				Description: "Qualified type name patterns accepted in realm state, such as std.*.",
+				Type:        tt.OptionStringList,
+				Default:     lints.DefaultRealmStateAllowed,
+			},
+			{
+				Name:        "max-depth",
+				Description: "Maximum number of nested package types inspected from a variable.",
+				Type:        tt.OptionInt,
+				Default:     3,
+				Min:         tt.Limit(1),
+			},
+		},
+		configurePackage: func(values map[string]interface{}) packageCheckFunc {
+			allowed := values["allowed-types"].([]string)
+			maxDepth := values["max-depth"].(int)
+			return func(pkg *lints.Package, severity tt.Severity) ([]tt.Issue, error) {
+				return lints.DetectRealmStateHazards(pkg, severity, allowed, maxDepth)
+			}
+		},
+	}
+	NilInterfaceReturnRule = LintRule{
+		severity:    tt.SeverityWarning,
+		checkTyped:  lints.DetectNilInterfaceReturns,
+		description: "Detects nil concrete values returned as interfaces, which then compare non-nil.",
+		category:    categoryCorrectness,
+	}
+	RedundantFormatRule = LintRule{
+		severity:    tt.SeverityInfo,
+		checkTyped:  lints.DetectRedundantFormatting,
+		description: "Detects Sprintf and Errorf calls that need no formatting and have a simpler equivalent.",
+		category:    categoryStyle,
+	}
+	PostLoopVariableRule = LintRule{
+		severity:    tt.SeverityWarning,
+		check:       lints.DetectPostLoopVariableUse,
+		description: "Detects variables assigned by a loop header that are read after the loop ends.",
+		category:    categoryCorrectness,
+	}
+	EmitInLoopRule = LintRule{
+		severity:    tt.SeverityWarning,
+		description: "Detects std.Emit calls inside loops that emit one event per iteration.",
+		category:    categoryPerformance,
+		scope:       scopeGno,
+		options: []tt.RuleOption{
+			{
+				Name:        "max-iterations",
+				Description: "Constant number of iterations up to which emitting in a loop is accepted.",
+				Type:        tt.OptionInt,
+				Default:     10,
+				Min:         tt.Limit(0),
+			},
+		},
+		configurePackage: func(values map[string]interface{}) packageCheckFunc {
+			maxIterations := values["max-iterations"].(int)
+			check := func(filename string, node *ast.File, fset *token.FileSet, info *types.Info, severity tt.Severity) ([]tt.Issue, error) {
+				return lints.DetectEmitInLoop(filename, node, fset, info, severity, maxIterations)
+			}
+			return func(pkg *lints.Package, severity tt.Severity) ([]tt.Issue, error) {
+				return lints.CheckTyped(pkg, check, severity)
+			}
+		},
+	}
+	StateReferenceReturnRule = LintRule{
+		severity:     tt.SeverityWarning,
+		checkPackage: lints.DetectStateReferenceReturns,
+		description:  "Detects exported realm functions returning pointers, slices or maps into the realm state.",
+		category:     categoryCorrectness,
+		scope:        scopeGno,
+	}
+	NamedResultShadowRule = LintRule{
+		severity:    tt.SeverityWarning,
+		check:       lints.DetectNamedResultShadowing,
+		description: "Detects variables shadowing a named result whose assigned value never reaches the caller.",
+		category:    categoryCorrectness,
+	}
+	AsymmetricComparisonRule = LintRule{
+		severity:    tt.SeverityWarning,
+		description: "Detects comparisons normalizing the case of one side only, and addresses compared to malformed literals.",
+		category:    categoryCorrectness,
+		options: []tt.RuleOption{
+			{
+				Name:        "address-prefixes",
+				Description: "Prefixes a valid address literal starts with.",
+				Type:        tt.OptionStringList,
+				Default:     lints.DefaultAddressPrefixes,
+			},
+			{
+				Name:        "address-length",
+				Description: "Length of a valid address literal.",
+				Type:        tt.OptionInt,
+				Default:     lints.DefaultAddressLength,
+				Min:         tt.Limit(1),
+			},
+		},
+		configure: func(values map[string]interface{}) checkFunc {
+			prefixes := values["address-prefixes"].([]string)
+			length := values["address-length"].(int)
+			return func(filename string, node *ast.File, fset *token.FileSet, severity tt.Severity) ([]tt.Issue, error) {
+				return lints.DetectAsymmetricComparison(filename, node, fset, severity, prefixes, length)
+			}
+		},
+	}
+	EntryPointPanicRule = LintRule{
+		severity:     tt.SeverityWarning,
+		checkPackage: lints.DetectEntryPointPanics,
+		description:  "Detects indexes derived from inputs and map reads dereferenced without checks in exported realm functions.",
+		category:     categoryCorrectness,
+		scope:        scopeGno,
+	}
+	StaleStateReturnRule = LintRule{
+		severity:     tt.SeverityWarning,
+		checkPackage: lints.DetectStaleStateReturns,
+		description:  "Detects exported realm functions returning a value read from the state before updating it.",
+		category:     categoryCorrectness,
+		scope:        scopeGno,
+	}
+	CommentedOutCodeRule = LintRule{
+		severity:    tt.SeverityInfo,
+		description: "Detects comment groups made mostly of commented-out code.",
+		category:    categoryStyle,
+		options: []tt.RuleOption{
+			{
+				Name:        "min-lines",
+				Description: "Number of non empty comment lines from which a group is examined.",
+				Type:        tt.OptionInt,
+				Default:     lints.DefaultCommentedCodeMinLines,
+				Min:         tt.Limit(1),
+			},
+			{
+				Name:        "code-ratio",
+				Description: "Fraction of the lines that must look like code.",
+				Type:        tt.OptionFloat,
+				Default:     lints.DefaultCommentedCodeRatio,
+				Min:         tt.Limit(0),
+				Max:         tt.Limit(1),
+			},
+		},
+		configure: func(values map[string]interface{}) checkFunc {
+			minLines := values["min-lines"].(int)
+			ratio := values["code-ratio"].(float64)
+			return func(filename string, node *ast.File, fset *token.FileSet, severity tt.Severity) ([]tt.Issue, error) {
+				return lints.DetectCommentedOutCode(filename, node, fset, severity, minLines, ratio)
+			}
+		},
+	}
+	AppendAliasingRule = LintRule{
+		severity:    tt.SeverityWarning,
+		check:       lints.DetectAppendAliasing,
+		description: "Detects appends to a slice of another slice that may overwrite elements still in use.",
+		category:    categoryCorrectness,
+	}
+	UnusedFunctionRule = LintRule{
+		severity:    tt.SeverityWarning,
+		description: "Detects unexported functions and methods that nothing in the package references.",
+		category:    categoryStyle,
+		options: []tt.RuleOption{
+			{
+				Name:        "include-tests",
+				Description: "Count the references made from test files.",
+				Type:        tt.OptionBool,
+				Default:     true,
+			},
+		},
+		configurePackage: func(values map[string]interface{}) packageCheckFunc {
+			includeTests := values["include-tests"].(bool)
+			return func(pkg *lints.Package, severity tt.Severity) ([]tt.Issue, error) {
+				return lints.DetectUnusedFunctions(pkg, severity, includeTests)
+			}
+		},
+	}
+	ModulePathMismatchRule = LintRule{
+		severity:    tt.SeverityWarning,
+		description: "Detects gno.mod module paths not matching their directory, and imports of the module under a stale path.",
+		category:    categoryCorrectness,
+		scope:       scopeGno,
+		// gno.mod files live above the package
+		uncached: true,
+		options: []tt.RuleOption{
+			{
+				Name:        "roots",
+				Description: "Directories holding gno packages and the import path they stand for, written as dir=path.",
+				Type:        tt.OptionStringList,
+				Default:     lints.DefaultModuleRoots,
+			},
+		},
+		configurePackage: func(values map[string]interface{}) packageCheckFunc {
+			roots := values["roots"].([]string)
+			return func(pkg *lints.Package, severity tt.Severity) ([]tt.Issue, error) {
+				return lints.DetectModulePathMismatch(pkg, severity, roots)
+			}
+		},
+	}
+	NonExhaustiveSwitchRule = LintRule{
+		severity:    tt.SeverityWarning,
+		description: "Detects switches and if chains missing some constants of a named type, or some implementations of a sealed interface, declared in the package.",
+		category:    categoryCorrectness,
+		options: []tt.RuleOption{
+			{
+				Name:        "strict",
+				Description: "Report the missing variants even when a default case or a final else handles them.",
+				Type:        tt.OptionBool,
+				Default:     false,
+			},
+		},
+		configurePackage: func(values map[string]interface{}) packageCheckFunc {
+			strict := values["strict"].(bool)
+			return func(pkg *lints.Package, severity tt.Severity) ([]tt.Issue, error) {
+				return lints.DetectNonExhaustiveSwitches(pkg, severity, strict)
+			}
+		},
+	}
+	DuplicateFunctionRule = LintRule{
+		severity:    tt.SeverityWarning,
+		description: "Detects pairs of functions whose bodies are near duplicates, ignoring names and literals.",
+		category:    categoryCorrectness,
+		options: []tt.RuleOption{
+			{
+				Name:        "similarity",
+				Description: "Minimum share of fingerprints two bodies must have in common to be reported.",
+				Type:        tt.OptionFloat,
+				Default:     0.85,
+				Min:         tt.Limit(0),
+				Max:         tt.Limit(1),
+			},
+			{
+				Name:        "min-tokens",
+				Description: "Minimum number of syntax tokens of the bodies compared.",
+				Type:        tt.OptionInt,
+				Default:     60,
+				Min:         tt.Limit(1),
+			},
+			{
+				Name:        "skip-tests",
+				Description: "Ignore the functions declared in test files.",
+				Type:        tt.OptionBool,
+				Default:     true,
+			},
+			{
+				Name:        "skip-generated",
+				Description: "Ignore the functions declared in generated files.",
+				Type:        tt.OptionBool,
+				Default:     true,
+			},
+		},
+		configurePackage: func(values map[string]interface{}) packageCheckFunc {
+			similarity := values["similarity"].(float64)
+			minTokens := values["min-tokens"].(int)
+			skipTests := values["skip-tests"].(bool)
+			skipGenerated := values["skip-generated"].(bool)
+			return func(pkg *lints.Package, severity tt.Severity) ([]tt.Issue, error) {
+				pkg = pkg.Filter(func(f *lints.PackageFile) bool {
+					return !(skipTests && f.IsTest()) && !(skipGenerated && f.IsGenerated())
+				})
+				return lints.DetectDuplicateFunctions(pkg, severity, similarity, minTokens)
+			}
+		},
+	}
+	HardcodedSecretRule = LintRule{
+		severity:    tt.SeverityWarning,
+		description: "Detects string literals holding addresses, random-looking keys or tokens, or matches of configured patterns.",
+		category:    categoryCorrectness,
+		options: []tt.RuleOption{
+			{
+				Name:        "address-prefixes",
+				Description: "Prefixes an address starts with.",
+				Type:        tt.OptionStringList,
+				Default:     lints.DefaultAddressPrefixes,
+			},
+			{
+				Name:        "address-length",
+				Description: "Length of an address.",
+				Type:        tt.OptionInt,
+				Default:     lints.DefaultAddressLength,
+				Min:         tt.Limit(1),
+			},
+			{
+				Name:        "min-length",
+				Description: "Minimum length of the hex and base64 runs checked for randomness.",
+				Type:        tt.OptionInt,
+				Default:     32,
+				Min:         tt.Limit(1),
+			},
+			{
+				Name:        "hex-entropy",
+				Description: "Entropy in bits per character from which a hex run looks random.",
+				Type:        tt.OptionFloat,
+				Default:     3.0,
+				Min:         tt.Limit(0),
+				Max:         tt.Limit(4),
+			},
+			{
+				Name:        "base64-entropy",
+				Description: "Entropy in bits per character from which a base64 run looks random.",
+				Type:        tt.OptionFloat,
+				Default:     4.5,
+				Min:         tt.Limit(0),
+				Max:         tt.Limit(6),
+			},
+			{
+				Name:        "patterns",
+				Description: "Regular expressions reported wherever they match a string literal.",
+				Type:        tt.OptionStringList,
+				Default:     []string{},
+			},
+			{
+				Name:        "allowlist",
+				Description: "Case-insensitive glob patterns of the names whose values are examples, reported as info.",
+				Type:        tt.OptionStringList,
+				Default:     lints.DefaultSecretAllowlist,
+			},
+		},
+		configure: func(values map[string]interface{}) checkFunc {
+			opts := lints.SecretOptions{
+				AddressPrefixes: values["address-prefixes"].([]string),
+				AddressLength:   values["address-length"].(int),
+				MinLength:       values["min-length"].(int),
+				HexEntropy:      values["hex-entropy"].(float64),
+				Base64Entropy:   values["base64-entropy"].(float64),
+				Allowlist:       values["allowlist"].([]string),
+			}
+			for _, pattern := range values["patterns"].([]string) {
+				re, err := regexp.Compile(pattern)
+				if err != nil {
+					return func(string, *ast.File, *token.FileSet, tt.Severity) ([]tt.Issue, error) {
+						return nil, fmt.Errorf("invalid pattern %q: %w", pattern, err)
+					}
+				}
+				opts.Patterns = append(opts.Patterns, re)
+			}
+			return func(filename string, node *ast.File, fset *token.FileSet, severity tt.Severity) ([]tt.Issue, error) {
+				return lints.DetectHardcodedSecrets(filename, node, fset, severity, opts)
+			}
+		},
+	}
+	ValueReceiverMutationRule = LintRule{
+		severity:     tt.SeverityWarning,
+		checkPackage: lints.DetectValueReceiverMutations,
+		description:  "Detects methods with a value receiver assigning its fields or calling its pointer methods, changes lost on return.",
+		category:     categoryCorrectness,
+	}
+	UnusedResultRule = LintRule{
+		severity:    tt.SeverityWarning,
+		description: "Detects calls to pure functions such as strings.TrimSpace made as statements, discarding their result.",
+		category:    categoryCorrectness,
+		options: []tt.RuleOption{
+			{
+				Name:        "functions",
+				Description: "Pure functions checked in addition to the built-in list, as import path and name (gno.land/p/org/pkg.Func) or as name alone for the functions of the package.",
+				Type:        tt.OptionStringList,
+				Default:     []string{},
+			},
+		},
+		configure: func(values map[string]interface{}) checkFunc {
+			functions := append(append([]string{}, lints.DefaultPureFunctions...), values["functions"].([]string)...)
+			return func(filename string, node *ast.File, fset *token.FileSet, severity tt.Severity) ([]tt.Issue, error) {
+				return lints.DetectUnusedResults(filename, node, fset, severity, functions)
+			}
+		},
+	}
+	CallProtocolRule = LintRule{
+		severity:    tt.SeverityWarning,
+		description: "Detects functions writing the state before a required call, such as an ownership check, and calls made without their pair.",
+		category:    categoryCorrectness,
+		options: []tt.RuleOption{
+			{
+				Name:        "prerequisites",
+				Description: "Calls the matching functions must make before writing a package variable, as `<patterns> -> <call>`, such as `Set*, Withdraw* -> AssertCallerIsOwner`.",
+				Type:        tt.OptionStringList,
+				Default:     []string{},
+				Validate: func(value string) error {
+					_, err := lints.ParsePrerequisite(value)
+					return err
+				},
+			},
+			{
+				Name:        "pairs",
+				Description: "Calls that must both appear in a package making one of them, as `<call> <-> <call>`.",
+				Type:        tt.OptionStringList,
+				Default:     []string{"Pause <-> Unpause"},
+				Validate: func(value string) error {
+					_, err := lints.ParseCallPair(value)
+					return err
+				},
+			},
+		},
+		configurePackage: func(values map[string]interface{}) packageCheckFunc {
+			var prerequisites []lints.Prerequisite
+			var pairs []lints.CallPair
+			var err error
+			for _, value := range values["prerequisites"].([]string) {
+				p, perr := lints.ParsePrerequisite(value)
+				prerequisites = append(prerequisites, p)
+				err = errors.Join(err, perr)
+			}
+			for _, value := range values["pairs"].([]string) {
+				pair, perr := lints.ParseCallPair(value)
+				pairs = append(pairs, pair)
+				err = errors.Join(err, perr)
+			}
+			return func(pkg *lints.Package, severity tt.Severity) ([]tt.Issue, error) {
+				if err != nil {
+					return nil, err
+				}
+				return lints.DetectCallProtocolViolations(pkg, severity, prerequisites, pairs)
+			}
+		},
+	}
+	AmountOverflowRule = LintRule{
+		severity:    tt.SeverityWarning,
+		description: "Detects unchecked integer arithmetic on parameters whose result is sent, stored or returned as an amount.",
+		category:    categoryCorrectness,
+		scope:       scopeGno,
+		options: []tt.RuleOption{
+			{
+				Name:        "helpers",
+				Description: "Checked arithmetic helpers guarding the operations they are called on, as `pkg.Func` or as a name alone for the functions of the package.",
+				Type:        tt.OptionStringList,
+				Default:     lints.DefaultOverflowHelpers,
+			},
+		},
+		configurePackage: func(values map[string]interface{}) packageCheckFunc {
+			helpers := values["helpers"].([]string)
+			return func(pkg *lints.Package, severity tt.Severity) ([]tt.Issue, error) {
+				return lints.DetectAmountOverflows(pkg, severity, helpers)
+			}
+		},
+	}
+	ReentrantCallRule = LintRule{
+		severity:     tt.SeverityWarning,
+		checkPackage: lints.DetectReentrantCalls,
+		description:  "Detects call cycles through Render or exported realm functions, which callers can trigger to re-enter them or recurse without bound.",
+		category:     categoryCorrectness,
+		scope:        scopeGno,
+	}
+	StdAPIRule = LintRule{
+		severity:    tt.SeverityWarning,
+		description: "Detects uses of std functions that are removed, unknown or called with the wrong number of arguments in the configured gno version.",
+		category:    categoryCorrectness,
+		scope:       scopeGno,
+		options: []tt.RuleOption{
+			{
+				Name:        "gno-version",
+				Description: "Gno version the std API is checked against, set by the top-level `gno-version` key. Empty for the newest known version.",
+				Type:        tt.OptionString,
+				Default:     "",
+				Validate: func(value string) error {
+					if _, ok := stdapi.Lookup(value); !ok && value != "" {
+						return fmt.Errorf("unknown gno version %q (expected one of %s)", value, strings.Join(stdapi.Versions(), ", "))
+					}
+					return nil
+				},
+			},
+		},
+		configure: func(values map[string]interface{}) checkFunc {
+			manifest, ok := stdapi.Lookup(values["gno-version"].(string))
+			if !ok {
+				manifest = stdapi.Latest()
+			}
+			return func(filename string, node *ast.File, fset *token.FileSet, severity tt.Severity) ([]tt.Issue, error) {
+				return lints.DetectStdAPIMisuse(filename, node, fset, severity, manifest)
+			}
+		},
+	}
+	CyclomaticComplexityRule = LintRule{
+		severity:    tt.SeverityOff,
+		description: "Reports functions whose cyclomatic complexity exceeds a threshold.",
+		category:    categoryComplexity,
+		options: []tt.RuleOption{
+			{
+				Name:        "threshold",
+				Description: "Maximum complexity allowed before a function is reported.",
+				Type:        tt.OptionInt,
+				Default:     10,
+				Min:         tt.Limit(1),
+			},
+		},
+		configure: func(values map[string]interface{}) checkFunc {
+			threshold := values["threshold"].(int)
+			return func(filename string, _ *ast.File, _ *token.FileSet, severity tt.Severity) ([]tt.Issue, error) {
+				return lints.DetectHighCyclomaticComplexity(filename, threshold, severity)
+			}
+		},
+	}
 )
 
 // Define the ruleMap type
 type ruleMap map[string]LintRule
 
-// Create a map to hold the mappings of rule names to LintRule structs
-var allRules = ruleMap{
+// allRules is the registry of the rules, holding the builtin ones. Rules
+// added with RegisterRule join them.
+var allRules = newRegistry(ruleMap{
 	"golangci-lint":               GolangciLintRule,
 	"simplify-slice-range":        SimplifySliceExprRule,
 	"unnecessary-type-conversion": UnnecessaryConversionRule,
@@ -61,4 +1001,45 @@ var allRules = ruleMap{
 	"const-error-declaration":     ConstErrorDeclarationRule,
 	"repeated-regex-compilation":  RepeatedRegexCompilationRule,
 	"unused-package":              GnoSpecificRule,
-}
+	"high-cyclomatic-complexity":  CyclomaticComplexityRule,
+	"unused-struct-field":         UnusedStructFieldRule,
+	"redundant-caller-parameter":  RedundantCallerParamRule,
+	"division-by-zero":            DivisionByZeroRule,
+	"slice-prealloc":              SlicePreallocRule,
+	"receiver-consistency":        ReceiverConsistencyRule,
+	"exported-naming":             ExportedNamingRule,
+	"readability-limits":          ReadabilityLimitsRule,
+	"untested-exports":            UntestedExportsRule,
+	"duplicate-constant":          DuplicateConstantRule,
+	"init-side-effects":           InitSideEffectsRule,
+	"unused-error":                UnusedErrorRule,
+	"struct-tag":                  StructTagRule,
+	"float-comparison":            FloatComparisonRule,
+	"duplicate-import":            DuplicateImportRule,
+	"large-literal":               LargeLiteralRule,
+	"parameter-mutation":          ParameterMutationRule,
+	"type-assertion-chain":        TypeAssertionChainRule,
+	"realm-state-types":           RealmStateTypesRule,
+	"nil-interface-return":        NilInterfaceReturnRule,
+	"redundant-format":            RedundantFormatRule,
+	"post-loop-variable":          PostLoopVariableRule,
+	"emit-in-loop":                EmitInLoopRule,
+	"state-reference-return":      StateReferenceReturnRule,
+	"named-result-shadow":         NamedResultShadowRule,
+	"asymmetric-comparison":       AsymmetricComparisonRule,
+	"entry-point-panic":           EntryPointPanicRule,
+	"stale-state-return":          StaleStateReturnRule,
+	"commented-out-code":          CommentedOutCodeRule,
+	"append-aliasing":             AppendAliasingRule,
+	"unused-function":             UnusedFunctionRule,
+	"module-path-mismatch":        ModulePathMismatchRule,
+	"non-exhaustive-switch":       NonExhaustiveSwitchRule,
+	"duplicate-function":          DuplicateFunctionRule,
+	"hardcoded-secret":            HardcodedSecretRule,
+	"value-receiver-mutation":     ValueReceiverMutationRule,
+	"unused-result":               UnusedResultRule,
+	"call-protocol":               CallProtocolRule,
+	"amount-overflow":             AmountOverflowRule,
+	"reentrant-call":              ReentrantCallRule,
+	stdAPIRule:                    StdAPIRule,
+})
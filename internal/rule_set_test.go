@@ -0,0 +1,67 @@
+package internal
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gnolang/tlin/internal/lints"
+	tt "github.com/gnolang/tlin/internal/types"
+	"github.com/stretchr/testify/require"
+)
+
+// TestRulesReportValidRanges makes sure no rule reports an issue whose end
+// position comes before its start.
+func TestRulesReportValidRanges(t *testing.T) {
+	t.Parallel()
+
+	paths, err := generateCorpus(t.TempDir(), 30, corpusSeed)
+	require.NoError(t, err)
+
+	for name, rule := range allRules.all() {
+		if name == golangciLintRule {
+			// external tool, covered by its own tests
+			continue
+		}
+		rule := rule.withOptions(map[string]interface{}{"threshold": 1})
+
+		for _, path := range paths {
+			node, fset, err := lints.ParseFile(path, nil)
+			require.NoError(t, err)
+
+			var issues []tt.Issue
+			if rule.IsPackageRule() {
+				issues, err = runPackageRuleOnSource(context.Background(), rule, path, node, fset)
+			} else {
+				issues, err = rule.Check(context.Background(), path, node, fset)
+			}
+			require.NoError(t, err, name)
+
+			for _, issue := range issues {
+				start, end := issue.Start, issue.End
+				require.True(t, end.IsValid(), "%s: issue at %s has no end position", name, start)
+				require.False(t, end.Line < start.Line || (end.Line == start.Line && end.Column < start.Column),
+					"%s: issue ends at %s before its start %s", name, end, start)
+			}
+		}
+	}
+}
+
+func TestRulesIntrospection(t *testing.T) {
+	t.Parallel()
+
+	rules := Rules()
+	require.Len(t, rules, len(allRules.names()))
+	for i := 1; i < len(rules); i++ {
+		require.Less(t, rules[i-1].Name, rules[i].Name)
+	}
+
+	rule, ok := LookupRule("high-cyclomatic-complexity")
+	require.True(t, ok)
+	require.Equal(t, tt.SeverityOff, rule.Severity)
+	require.Len(t, rule.Options, 1)
+	require.Equal(t, "threshold", rule.Options[0].Name)
+	require.Equal(t, "int, at least 1", rule.Options[0].Constraint())
+
+	_, ok = LookupRule("no-such-rule")
+	require.False(t, ok)
+}
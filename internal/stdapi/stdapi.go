@@ -0,0 +1,264 @@
+// Package stdapi describes the API of the gno standard packages, such as
+// std, for each gno version, so that calls can be checked against the
+// version a contract is deployed on.
+//
+// The API of a version is given by a YAML manifest:
+//
+//	version: "0.2"
+//	packages:
+//	  std:
+//	    functions:
+//	      Emit: func(typ string, attrs ...string)
+//	    types: [Address, Coins]
+//	    values: [BankerTypeReadonly]
+//	    removed:
+//	      GetOrigCaller: OriginCaller
+//
+// Functions map to their signature, written as a Go function type. Removed
+// names map to their replacement in the package, or to an empty string when
+// there is none. Manifests for the known versions are bundled.
+package stdapi
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+//go:embed manifests/*.yaml
+var bundled embed.FS
+
+// manifests holds the bundled manifests by version.
+var manifests = mustLoadBundled()
+
+// Manifest is the API of the standard packages in a gno version.
+type Manifest struct {
+	Version  string
+	Packages map[string]*Package
+}
+
+// Package is the API of a standard package.
+type Package struct {
+	Functions map[string]Signature
+	Types     map[string]bool
+	Values    map[string]bool
+	// Removed maps the names removed from the package to their
+	// replacement, empty when there is none.
+	Removed map[string]string
+}
+
+// Signature is the signature of a function.
+type Signature struct {
+	Text string
+	// Params is the number of parameters, the variadic one included.
+	Params   int
+	Variadic bool
+}
+
+// Accepts reports whether a call with the given number of arguments
+// matches the signature.
+func (s Signature) Accepts(args int) bool {
+	if s.Variadic {
+		return args >= s.Params-1
+	}
+	return args == s.Params
+}
+
+// Declares reports whether name is a function, a type or a value of the
+// package.
+func (p *Package) Declares(name string) bool {
+	_, ok := p.Functions[name]
+	return ok || p.Types[name] || p.Values[name]
+}
+
+// Versions returns the versions with a bundled manifest, oldest first.
+func Versions() []string {
+	versions := make([]string, 0, len(manifests))
+	for version := range manifests {
+		versions = append(versions, version)
+	}
+	sort.Slice(versions, func(i, j int) bool {
+		return compareVersions(versions[i], versions[j]) < 0
+	})
+	return versions
+}
+
+// Lookup returns the bundled manifest of version.
+func Lookup(version string) (*Manifest, bool) {
+	m, ok := manifests[version]
+	return m, ok
+}
+
+// Latest returns the bundled manifest of the newest version.
+func Latest() *Manifest {
+	versions := Versions()
+	return manifests[versions[len(versions)-1]]
+}
+
+type rawManifest struct {
+	Version  string                 `yaml:"version"`
+	Packages map[string]*rawPackage `yaml:"packages"`
+}
+
+type rawPackage struct {
+	Functions map[string]string `yaml:"functions"`
+	Types     []string          `yaml:"types"`
+	Values    []string          `yaml:"values"`
+	Removed   map[string]string `yaml:"removed"`
+}
+
+// Parse parses and validates a manifest. The version must be set, function
+// signatures must be function types, a name cannot be declared twice nor be
+// both declared and removed, and replacements must be declared by the
+// package.
+func Parse(content []byte) (*Manifest, error) {
+	var raw rawManifest
+	if err := yaml.Unmarshal(content, &raw); err != nil {
+		return nil, err
+	}
+	if raw.Version == "" {
+		return nil, errors.New("missing version")
+	}
+
+	m := &Manifest{Version: raw.Version, Packages: make(map[string]*Package, len(raw.Packages))}
+	var errs []error
+	for _, pkgPath := range sortedKeys(raw.Packages) {
+		pkg, err := parsePackage(pkgPath, raw.Packages[pkgPath])
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		m.Packages[pkgPath] = pkg
+	}
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func parsePackage(pkgPath string, raw *rawPackage) (*Package, error) {
+	if raw == nil {
+		raw = &rawPackage{}
+	}
+	pkg := &Package{
+		Functions: make(map[string]Signature, len(raw.Functions)),
+		Types:     make(map[string]bool, len(raw.Types)),
+		Values:    make(map[string]bool, len(raw.Values)),
+		Removed:   raw.Removed,
+	}
+	if pkg.Removed == nil {
+		pkg.Removed = make(map[string]string)
+	}
+
+	var errs []error
+	for _, name := range sortedKeys(raw.Functions) {
+		sig, err := parseSignature(raw.Functions[name])
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s.%s: %w", pkgPath, name, err))
+			continue
+		}
+		pkg.Functions[name] = sig
+	}
+	for _, names := range []struct {
+		list []string
+		set  map[string]bool
+	}{{raw.Types, pkg.Types}, {raw.Values, pkg.Values}} {
+		for _, name := range names.list {
+			if _, ok := raw.Functions[name]; ok || pkg.Types[name] || pkg.Values[name] {
+				errs = append(errs, fmt.Errorf("%s.%s: declared twice", pkgPath, name))
+				continue
+			}
+			names.set[name] = true
+		}
+	}
+	for _, name := range sortedKeys(pkg.Removed) {
+		if pkg.Declares(name) {
+			errs = append(errs, fmt.Errorf("%s.%s: both declared and removed", pkgPath, name))
+		}
+		if replacement := pkg.Removed[name]; replacement != "" && !pkg.Declares(replacement) {
+			errs = append(errs, fmt.Errorf("%s.%s: replacement %s is not declared", pkgPath, name, replacement))
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+	return pkg, nil
+}
+
+func parseSignature(text string) (Signature, error) {
+	expr, err := parser.ParseExpr(text)
+	if err != nil {
+		return Signature{}, fmt.Errorf("invalid signature %q: %w", text, err)
+	}
+	fn, ok := expr.(*ast.FuncType)
+	if !ok {
+		return Signature{}, fmt.Errorf("signature %q is not a function type", text)
+	}
+
+	sig := Signature{Text: text}
+	for _, field := range fn.Params.List {
+		n := max(len(field.Names), 1)
+		sig.Params += n
+		if _, ok := field.Type.(*ast.Ellipsis); ok {
+			sig.Variadic = true
+		}
+	}
+	return sig, nil
+}
+
+func mustLoadBundled() map[string]*Manifest {
+	entries, err := bundled.ReadDir("manifests")
+	if err != nil {
+		panic(err)
+	}
+	loaded := make(map[string]*Manifest, len(entries))
+	for _, entry := range entries {
+		name := path.Join("manifests", entry.Name())
+		content, err := bundled.ReadFile(name)
+		if err != nil {
+			panic(err)
+		}
+		m, err := Parse(content)
+		if err != nil {
+			panic(fmt.Sprintf("stdapi: invalid manifest %s: %v", name, err))
+		}
+		loaded[m.Version] = m
+	}
+	return loaded
+}
+
+// compareVersions compares dotted version numbers, part by part.
+func compareVersions(a, b string) int {
+	pa := strings.Split(strings.TrimPrefix(a, "v"), ".")
+	pb := strings.Split(strings.TrimPrefix(b, "v"), ".")
+	for i := 0; i < max(len(pa), len(pb)); i++ {
+		var x, y int
+		if i < len(pa) {
+			x, _ = strconv.Atoi(pa[i])
+		}
+		if i < len(pb) {
+			y, _ = strconv.Atoi(pb[i])
+		}
+		if x != y {
+			return x - y
+		}
+	}
+	return strings.Compare(a, b)
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
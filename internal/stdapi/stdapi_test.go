@@ -0,0 +1,128 @@
+package stdapi
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBundledManifests(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, []string{"0.1", "0.2"}, Versions())
+	assert.Equal(t, "0.2", Latest().Version)
+
+	m, ok := Lookup("0.2")
+	require.True(t, ok)
+	std := m.Packages["std"]
+	require.NotNil(t, std)
+	assert.Equal(t, "OriginCaller", std.Removed["GetOrigCaller"])
+	assert.True(t, std.Declares("Address"))
+	assert.False(t, std.Declares("GetOrigCaller"))
+
+	_, ok = Lookup("0.0")
+	assert.False(t, ok)
+}
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		content string
+		errors  []string
+	}{
+		{
+			name: "valid",
+			content: `version: "1.0"
+packages:
+  std:
+    functions:
+      Emit: func(typ string, attrs ...string)
+    types: [Address]
+    removed:
+      SetOrigCaller: ""
+      GetEmit: Emit
+`,
+		},
+		{
+			name:    "missing version",
+			content: "packages: {}\n",
+			errors:  []string{"missing version"},
+		},
+		{
+			name: "invalid",
+			content: `version: "1.0"
+packages:
+  std:
+    functions:
+      Emit: func(typ string
+      Address: string
+      Realm: func()
+    types: [Address, Realm]
+    values: [Address]
+    removed:
+      Emit: ""
+      GetCaller: OriginCaller
+`,
+			errors: []string{
+				`std.Emit: invalid signature "func(typ string"`,
+				`std.Address: signature "string" is not a function type`,
+				"std.Realm: declared twice",
+				"std.Address: declared twice",
+				"std.GetCaller: replacement OriginCaller is not declared",
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			m, err := Parse([]byte(tc.content))
+			if len(tc.errors) == 0 {
+				require.NoError(t, err)
+				assert.Equal(t, "1.0", m.Version)
+				return
+			}
+			require.Error(t, err)
+			for _, msg := range tc.errors {
+				assert.Contains(t, err.Error(), msg)
+			}
+		})
+	}
+}
+
+func TestSignatureAccepts(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		signature string
+		accepts   []int
+		rejects   []int
+	}{
+		{"func()", []int{0}, []int{1}},
+		{"func(a, b int, c string) bool", []int{3}, []int{2, 4}},
+		{"func(typ string, attrs ...string)", []int{1, 2, 5}, []int{0}},
+	}
+	for _, tc := range tests {
+		sig, err := parseSignature(tc.signature)
+		require.NoError(t, err)
+		for _, n := range tc.accepts {
+			assert.True(t, sig.Accepts(n), "%s with %d arguments", tc.signature, n)
+		}
+		for _, n := range tc.rejects {
+			assert.False(t, sig.Accepts(n), "%s with %d arguments", tc.signature, n)
+		}
+	}
+}
+
+func TestCompareVersions(t *testing.T) {
+	t.Parallel()
+
+	assert.Negative(t, compareVersions("0.2", "0.10"))
+	assert.Negative(t, compareVersions("v0.1", "0.1.1"))
+	assert.Positive(t, compareVersions("1.0", "0.9.9"))
+	assert.Zero(t, compareVersions("0.2", "0.2"))
+}
@@ -0,0 +1,333 @@
+package internal
+
+import (
+	"errors"
+	"fmt"
+	"go/token"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	tt "github.com/gnolang/tlin/internal/types"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// SuppressionsFileName is the name of the suppression list looked up in
+	// the engine's root directory.
+	SuppressionsFileName = "tlin-suppressions.yaml"
+
+	expiredSuppressionRule = "expired-suppression"
+	staleSuppressionRule   = "stale-suppression"
+	unusedIgnoreRule       = "unused-ignore"
+
+	suppressionDateLayout = "2006-01-02"
+)
+
+var suppressionKeys = []string{"rule", "path", "fingerprint", "justification", "expires"}
+
+// suppression is an entry of the suppression list. It silences the issues of
+// a rule in the files matching a glob, optionally only the one with the
+// given fingerprint, until the end of its expiry date.
+type suppression struct {
+	Rule          string `yaml:"rule"`
+	Path          string `yaml:"path"`
+	Fingerprint   string `yaml:"fingerprint"`
+	Justification string `yaml:"justification"`
+	Expires       string `yaml:"expires"`
+
+	line    int
+	expires time.Time // zero when the entry never expires
+}
+
+// suppressionList holds the entries of the suppression list along with what
+// they matched during the run, to report the stale ones at the end.
+type suppressionList struct {
+	filename string
+	rootDir  string
+	entries  []suppression
+	now      func() time.Time
+
+	mu sync.Mutex
+	// covered records the entries whose glob matched a linted file,
+	// and used the ones that suppressed at least one issue.
+	covered []bool
+	used    []bool
+}
+
+// loadSuppressions reads the suppression list of the root directory.
+// A missing file yields a nil list.
+func loadSuppressions(rootDir string) (*suppressionList, error) {
+	filename := filepath.Join(rootDir, SuppressionsFileName)
+	content, err := os.ReadFile(filename)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := parseSuppressions(content)
+	if err != nil {
+		return nil, fmt.Errorf("invalid suppression file %s: %w", filename, err)
+	}
+	return &suppressionList{
+		filename: filename,
+		rootDir:  rootDir,
+		entries:  entries,
+		now:      time.Now,
+		covered:  make([]bool, len(entries)),
+		used:     make([]bool, len(entries)),
+	}, nil
+}
+
+// parseSuppressions validates and decodes the content of a suppression list.
+// Every problem is reported with the line it was found on.
+func parseSuppressions(content []byte) ([]suppression, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(content, &doc); err != nil {
+		return nil, err
+	}
+	if len(doc.Content) == 0 {
+		return nil, nil
+	}
+
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return nil, &ConfigError{Line: root.Line, Message: "suppression file must be a mapping"}
+	}
+
+	var (
+		entries []suppression
+		errs    []error
+	)
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		key, value := root.Content[i], root.Content[i+1]
+		if key.Value != "suppressions" {
+			errs = append(errs, unknownKeyError(key, "top-level key", []string{"suppressions"}))
+			continue
+		}
+		if isNull(value) {
+			continue
+		}
+		if value.Kind != yaml.SequenceNode {
+			errs = append(errs, &ConfigError{Line: value.Line, Message: "suppressions must be a list"})
+			continue
+		}
+		for _, node := range value.Content {
+			entry, entryErrs := parseSuppression(node)
+			errs = append(errs, entryErrs...)
+			if len(entryErrs) == 0 {
+				entries = append(entries, entry)
+			}
+		}
+	}
+
+	return entries, errors.Join(errs...)
+}
+
+func parseSuppression(node *yaml.Node) (suppression, []error) {
+	if node.Kind != yaml.MappingNode {
+		return suppression{}, []error{&ConfigError{Line: node.Line, Message: "suppression must be a mapping"}}
+	}
+
+	var errs []error
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+		if !contains(suppressionKeys, key.Value) {
+			errs = append(errs, unknownKeyError(key, "suppression key", suppressionKeys))
+		} else if value.Kind != yaml.ScalarNode {
+			errs = append(errs, &ConfigError{Line: value.Line, Message: fmt.Sprintf("%s must be a string", key.Value)})
+		}
+	}
+	if len(errs) > 0 {
+		return suppression{}, errs
+	}
+
+	entry := suppression{line: node.Line}
+	if err := node.Decode(&entry); err != nil {
+		return suppression{}, []error{err}
+	}
+	for _, required := range []struct{ key, value string }{
+		{"rule", entry.Rule},
+		{"path", entry.Path},
+		{"justification", entry.Justification},
+	} {
+		if strings.TrimSpace(required.value) == "" {
+			errs = append(errs, &ConfigError{Line: node.Line, Message: fmt.Sprintf("suppression is missing %s", required.key)})
+		}
+	}
+	if entry.Expires != "" {
+		expires, err := time.Parse(suppressionDateLayout, entry.Expires)
+		if err != nil {
+			errs = append(errs, &ConfigError{Line: node.Line, Message: fmt.Sprintf("invalid expiry date %q (expected YYYY-MM-DD)", entry.Expires)})
+		}
+		entry.expires = expires
+	}
+	return entry, errs
+}
+
+// expired reports whether the entry no longer applies. An entry still
+// applies during its whole expiry date.
+func (s *suppression) expired(now time.Time) bool {
+	if s.expires.IsZero() {
+		return false
+	}
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	return today.After(s.expires)
+}
+
+// filter drops the issues of a file silenced by the list. Fingerprints are
+// only computed, from the lines returned by source, when an entry needs them.
+func (l *suppressionList) filter(filename string, issues []tt.Issue, source func() ([]string, error)) []tt.Issue {
+	if l == nil {
+		return issues
+	}
+
+	relPath := l.relativePath(filename)
+	now := l.now()
+	var active []int
+	l.mu.Lock()
+	for i := range l.entries {
+		entry := &l.entries[i]
+		if entry.expired(now) || !matchGlob(normalizeSlashes(entry.Path), relPath) {
+			continue
+		}
+		l.covered[i] = true
+		active = append(active, i)
+	}
+	l.mu.Unlock()
+	if len(active) == 0 || len(issues) == 0 {
+		return issues
+	}
+
+	for _, i := range active {
+		if l.entries[i].Fingerprint != "" {
+			// without the source, only the entries without fingerprint apply
+			if lines, err := source(); err == nil {
+				tt.SetFingerprints(issues, lines)
+			}
+			break
+		}
+	}
+
+	kept := make([]tt.Issue, 0, len(issues))
+	for _, issue := range issues {
+		suppressed := false
+		for _, i := range active {
+			entry := &l.entries[i]
+			if entry.Rule != issue.Rule || (entry.Fingerprint != "" && entry.Fingerprint != issue.Fingerprint) {
+				continue
+			}
+			l.mu.Lock()
+			l.used[i] = true
+			l.mu.Unlock()
+			suppressed = true
+		}
+		if !suppressed {
+			kept = append(kept, issue)
+		}
+	}
+	return kept
+}
+
+// issues reports the expired entries, and the entries that suppressed nothing
+// although their glob matched a linted file.
+func (l *suppressionList) issues() []tt.Issue {
+	if l == nil {
+		return nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	var issues []tt.Issue
+	for i, entry := range l.entries {
+		issue := tt.Issue{
+			Filename: l.filename,
+			Start:    token.Position{Filename: l.filename, Line: entry.line, Column: 1},
+			End:      token.Position{Filename: l.filename, Line: entry.line, Column: 1},
+			Severity: tt.SeverityWarning,
+		}
+		switch {
+		case entry.expired(now):
+			issue.Rule = expiredSuppressionRule
+			issue.Message = fmt.Sprintf("suppression of %s in %s expired on %s", entry.Rule, entry.Path, entry.Expires)
+			issue.Note = "fix the issues it silenced, or extend the expiry date with a new justification"
+		case l.covered[i] && !l.used[i]:
+			issue.Rule = staleSuppressionRule
+			issue.Message = fmt.Sprintf("suppression of %s in %s matched no issue", entry.Rule, entry.Path)
+			issue.Note = "remove the entry, the issues it silenced are gone"
+		default:
+			continue
+		}
+		issues = append(issues, issue)
+	}
+	return issues
+}
+
+// relativePath returns the slash separated path of a file relative to the
+// root directory, which the globs of the entries are written against.
+func (l *suppressionList) relativePath(filename string) string {
+	return relativeTo(l.rootDir, filename)
+}
+
+// relativeTo returns the slash separated path of a file relative to dir, or
+// the path itself when the file is outside of dir.
+func relativeTo(dir, filename string) string {
+	if abs, err := filepath.Abs(filename); err == nil {
+		if root, err := filepath.Abs(dir); err == nil {
+			if rel, err := filepath.Rel(root, abs); err == nil && !strings.HasPrefix(rel, "..") {
+				filename = rel
+			}
+		}
+	}
+	return normalizeSlashes(filename)
+}
+
+// normalizeSlashes turns Windows style separators into slashes, whatever the
+// platform, so that a list written on Windows works everywhere.
+func normalizeSlashes(p string) string {
+	return strings.TrimPrefix(strings.ReplaceAll(p, `\`, "/"), "./")
+}
+
+// matchGlob matches a slash separated path against a glob where `**` stands
+// for any number of directories and the other segments follow path.Match.
+func matchGlob(pattern, name string) bool {
+	return matchSegments(strings.Split(pattern, "/"), strings.Split(name, "/"))
+}
+
+// validGlob reports whether every segment of a glob is a valid pattern.
+func validGlob(pattern string) bool {
+	for _, segment := range strings.Split(pattern, "/") {
+		if _, err := path.Match(segment, ""); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+func matchSegments(pattern, name []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for i := 0; i <= len(name); i++ {
+				if matchSegments(pattern[1:], name[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(name) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(pattern[0], name[0]); !ok {
+			return false
+		}
+		pattern, name = pattern[1:], name[1:]
+	}
+	return len(name) == 0
+}
@@ -0,0 +1,286 @@
+package internal
+
+import (
+	"go/token"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	tt "github.com/gnolang/tlin/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestSuppressions(t *testing.T, content string, now time.Time) *suppressionList {
+	t.Helper()
+	entries, err := parseSuppressions([]byte(content))
+	require.NoError(t, err)
+	return &suppressionList{
+		filename: SuppressionsFileName,
+		entries:  entries,
+		now:      func() time.Time { return now },
+		covered:  make([]bool, len(entries)),
+		used:     make([]bool, len(entries)),
+	}
+}
+
+func noSource() ([]string, error) {
+	return nil, os.ErrNotExist
+}
+
+func TestParseSuppressions(t *testing.T) {
+	t.Parallel()
+
+	entries, err := parseSuppressions([]byte(`suppressions:
+  - rule: simplify-slice-range
+    path: p/**/*.gno
+    justification: generated code
+    expires: 2025-06-30
+  - rule: useless-break
+    path: p/a.gno
+    fingerprint: abc
+    justification: kept for readability
+`))
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, 2, entries[0].line)
+	assert.Equal(t, time.Date(2025, 6, 30, 0, 0, 0, 0, time.UTC), entries[0].expires)
+	assert.True(t, entries[1].expires.IsZero())
+	assert.Equal(t, "abc", entries[1].Fingerprint)
+
+	entries, err = parseSuppressions(nil)
+	assert.NoError(t, err)
+	assert.Empty(t, entries)
+
+	tests := []struct {
+		name    string
+		content string
+		want    []string
+	}{
+		{
+			name:    "missing justification",
+			content: "suppressions:\n  - rule: a\n    path: '*.gno'\n",
+			want:    []string{"line 2: suppression is missing justification"},
+		},
+		{
+			name:    "blank justification and missing path",
+			content: "suppressions:\n  - rule: a\n    justification: ' '\n",
+			want:    []string{"line 2: suppression is missing path", "line 2: suppression is missing justification"},
+		},
+		{
+			name:    "invalid date",
+			content: "suppressions:\n  - rule: a\n    path: x\n    justification: j\n    expires: 30/06/2025\n",
+			want:    []string{`line 2: invalid expiry date "30/06/2025" (expected YYYY-MM-DD)`},
+		},
+		{
+			name:    "unknown key",
+			content: "suppressions:\n  - rule: a\n    path: x\n    justification: j\n    reason: r\n",
+			want:    []string{`line 5: unknown suppression key "reason"`},
+		},
+		{
+			name:    "not a list",
+			content: "suppressions:\n  rule: a\n",
+			want:    []string{"line 2: suppressions must be a list"},
+		},
+		{
+			name:    "unknown top-level key",
+			content: "ignores: []\n",
+			want:    []string{`line 1: unknown top-level key "ignores"`},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			_, err := parseSuppressions([]byte(tc.content))
+			require.Error(t, err)
+			for _, want := range tc.want {
+				assert.Contains(t, err.Error(), want)
+			}
+		})
+	}
+}
+
+func TestSuppressionExpiry(t *testing.T) {
+	t.Parallel()
+
+	const content = `suppressions:
+  - rule: r
+    path: a.gno
+    justification: j
+    expires: 2025-06-30
+`
+	issues := []tt.Issue{{Rule: "r", Filename: "a.gno"}}
+	local := time.FixedZone("UTC+9", 9*60*60)
+
+	tests := []struct {
+		name    string
+		now     time.Time
+		expired bool
+	}{
+		{"day before", time.Date(2025, 6, 29, 12, 0, 0, 0, time.UTC), false},
+		{"start of the expiry date", time.Date(2025, 6, 30, 0, 0, 0, 0, time.UTC), false},
+		{"end of the expiry date", time.Date(2025, 6, 30, 23, 59, 59, 0, local), false},
+		{"day after", time.Date(2025, 7, 1, 0, 0, 0, 0, local), true},
+		{"long after", time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC), true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			list := newTestSuppressions(t, content, tc.now)
+			kept := list.filter("a.gno", issues, noSource)
+			reported := list.issues()
+			if tc.expired {
+				assert.Equal(t, issues, kept)
+				require.Len(t, reported, 1)
+				assert.Equal(t, expiredSuppressionRule, reported[0].Rule)
+				assert.Equal(t, "suppression of r in a.gno expired on 2025-06-30", reported[0].Message)
+				assert.Equal(t, 2, reported[0].Start.Line)
+			} else {
+				assert.Empty(t, kept)
+				assert.Empty(t, reported)
+			}
+		})
+	}
+}
+
+func TestSuppressionGlobs(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		pattern string
+		path    string
+		want    bool
+	}{
+		{"a.gno", "a.gno", true},
+		{"*.gno", "a.gno", true},
+		{"*.gno", "p/a.gno", false},
+		{"p/*.gno", "p/a.gno", true},
+		{"p/**/*.gno", "p/a.gno", true},
+		{"p/**/*.gno", "p/x/y/a.gno", true},
+		{"p/**", "p/x/a.go", true},
+		{"**/a.gno", "a.gno", true},
+		{"**/a.gno", "p/x/a.gno", true},
+		{"p/**/*.gno", "q/a.gno", false},
+		{"p/?.gno", "p/ab.gno", false},
+		// Windows style separators, in the path or in the pattern
+		{"p/**/*.gno", `p\x\a.gno`, true},
+		{`p\**\*.gno`, "p/x/a.gno", true},
+		{`p\*.gno`, `p\a.gno`, true},
+		{"./p/a.gno", `.\p\a.gno`, true},
+	}
+	for _, tc := range tests {
+		assert.Equal(t, tc.want, matchGlob(normalizeSlashes(tc.pattern), normalizeSlashes(tc.path)), "%s ~ %s", tc.pattern, tc.path)
+	}
+
+	list := newTestSuppressions(t, `suppressions:
+  - rule: r
+    path: p\**\*.gno
+    justification: j
+`, time.Now())
+	kept := list.filter(`p\x\a.gno`, []tt.Issue{{Rule: "r"}, {Rule: "other"}}, noSource)
+	assert.Equal(t, []tt.Issue{{Rule: "other"}}, kept)
+}
+
+func TestSuppressionFingerprints(t *testing.T) {
+	t.Parallel()
+
+	lines := []string{"package a", "x := s[:len(s)]", "y := s[:len(s)]"}
+	issues := []tt.Issue{
+		{Rule: "r", Filename: "a.gno", Start: token.Position{Line: 2, Column: 6}, End: token.Position{Line: 2, Column: 15}},
+		{Rule: "r", Filename: "a.gno", Start: token.Position{Line: 3, Column: 6}, End: token.Position{Line: 3, Column: 15}},
+	}
+	fingerprinted := append([]tt.Issue(nil), issues...)
+	tt.SetFingerprints(fingerprinted, lines)
+
+	list := newTestSuppressions(t, `suppressions:
+  - rule: r
+    path: a.gno
+    fingerprint: `+fingerprinted[1].Fingerprint+`
+    justification: j
+`, time.Now())
+	kept := list.filter("a.gno", append([]tt.Issue(nil), issues...), func() ([]string, error) { return lines, nil })
+	require.Len(t, kept, 1)
+	assert.Equal(t, 2, kept[0].Start.Line)
+	assert.Empty(t, list.issues())
+
+	// without the source, the fingerprint cannot match
+	list = newTestSuppressions(t, `suppressions:
+  - rule: r
+    path: a.gno
+    fingerprint: `+fingerprinted[1].Fingerprint+`
+    justification: j
+`, time.Now())
+	kept = list.filter("a.gno", append([]tt.Issue(nil), issues...), noSource)
+	assert.Len(t, kept, 2)
+}
+
+func TestStaleSuppressions(t *testing.T) {
+	t.Parallel()
+
+	list := newTestSuppressions(t, `suppressions:
+  - rule: r
+    path: p/*.gno
+    justification: used
+  - rule: gone
+    path: p/*.gno
+    justification: the issue was fixed
+  - rule: r
+    path: q/*.gno
+    justification: not linted in this run
+`, time.Now())
+
+	kept := list.filter("p/a.gno", []tt.Issue{{Rule: "r"}}, noSource)
+	assert.Empty(t, kept)
+	list.filter("p/b.gno", nil, noSource)
+
+	reported := list.issues()
+	require.Len(t, reported, 1)
+	assert.Equal(t, staleSuppressionRule, reported[0].Rule)
+	assert.Equal(t, "suppression of gone in p/*.gno matched no issue", reported[0].Message)
+	assert.Equal(t, 5, reported[0].Start.Line)
+	assert.Equal(t, SuppressionsFileName, reported[0].Filename)
+}
+
+func TestEngineSuppressionFile(t *testing.T) {
+	t.Parallel()
+
+	tempDir := createTempDir(t, "suppressions_file_test")
+	filename := filepath.Join(tempDir, "p", "a.gno")
+	require.NoError(t, os.MkdirAll(filepath.Dir(filename), 0o755))
+	require.NoError(t, os.WriteFile(filename, []byte(`package p
+
+func f(s []int) []int {
+	return s[:len(s)]
+}
+`), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(tempDir, SuppressionsFileName), []byte(`suppressions:
+  - rule: simplify-slice-range
+    path: p/*.gno
+    justification: kept for symmetry with the other helpers
+  - rule: useless-break
+    path: p/**
+    justification: was fixed since
+`), 0o644))
+
+	engine, err := NewEngine(tempDir, nil, nil)
+	require.NoError(t, err)
+	engine.CountSuppressions()
+
+	issues, err := engine.Run(filename)
+	require.NoError(t, err)
+	for _, issue := range issues {
+		assert.NotEqual(t, "simplify-slice-range", issue.Rule)
+	}
+	assert.Equal(t, 1, engine.SuppressionCounts()["simplify-slice-range"].Suppressed)
+
+	reported := engine.SuppressionIssues()
+	require.Len(t, reported, 1)
+	assert.Equal(t, staleSuppressionRule, reported[0].Rule)
+	assert.Equal(t, 5, reported[0].Start.Line)
+
+	// an invalid list fails the engine creation
+	require.NoError(t, os.WriteFile(filepath.Join(tempDir, SuppressionsFileName), []byte("suppressions:\n  - rule: r\n"), 0o644))
+	_, err = NewEngine(tempDir, nil, nil)
+	assert.ErrorContains(t, err, "suppression is missing path")
+}
@@ -0,0 +1,60 @@
+package types
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Fingerprint identifies an issue across runs, as long as the code it points
+// at does not change. It is computed from the rule, the file path, the
+// normalized text of the offending lines and the occurrence index among the
+// issues of the same rule on identical text in the file. Line numbers are
+// left out, so inserting unrelated lines above keeps the fingerprint.
+func Fingerprint(rule, filename, text string, occurrence int) string {
+	h := sha256.New()
+	fmt.Fprintf(h, "%s\x00%s\x00%s\x00%d", rule, filepath.ToSlash(filename), text, occurrence)
+	return hex.EncodeToString(h.Sum(nil))[:32]
+}
+
+// SetFingerprints fills the fingerprints of the issues of a single file,
+// whose source lines are given.
+func SetFingerprints(issues []Issue, lines []string) {
+	order := make([]int, len(issues))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		ia, ib := issues[order[a]], issues[order[b]]
+		if ia.Start.Line != ib.Start.Line {
+			return ia.Start.Line < ib.Start.Line
+		}
+		return ia.Start.Column < ib.Start.Column
+	})
+
+	occurrences := make(map[string]int)
+	for _, i := range order {
+		issue := &issues[i]
+		text := normalizedText(*issue, lines)
+		key := issue.Rule + "\x00" + text
+		issue.Fingerprint = Fingerprint(issue.Rule, issue.Filename, text, occurrences[key])
+		occurrences[key]++
+	}
+}
+
+// normalizedText returns the lines of the issue with their spacing collapsed,
+// so that reindenting the code keeps the fingerprint.
+func normalizedText(issue Issue, lines []string) string {
+	start, end := issue.Range()
+	var text []string
+	for line := start.Line; line <= end.Line; line++ {
+		if line < 1 || line > len(lines) {
+			continue
+		}
+		text = append(text, strings.Join(strings.Fields(lines[line-1]), " "))
+	}
+	return strings.Join(text, "\n")
+}
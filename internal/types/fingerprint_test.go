@@ -0,0 +1,88 @@
+package types
+
+import (
+	"go/token"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const fingerprintSource = `package foo
+
+func f(slice []int) {
+	_ = slice[:len(slice)]
+	_ = slice[:len(slice)]
+}
+`
+
+func fingerprintIssues(source, needle string) []Issue {
+	var issues []Issue
+	for i, line := range strings.Split(source, "\n") {
+		if col := strings.Index(line, needle); col >= 0 {
+			issues = append(issues, Issue{
+				Rule:     "simplify-slice-range",
+				Filename: "foo/foo.gno",
+				Start:    token.Position{Line: i + 1, Column: col + 1},
+				End:      token.Position{Line: i + 1, Column: col + 1 + len(needle)},
+			})
+		}
+	}
+	return issues
+}
+
+func fingerprints(source, needle string) []string {
+	issues := fingerprintIssues(source, needle)
+	SetFingerprints(issues, strings.Split(source, "\n"))
+	prints := make([]string, 0, len(issues))
+	for _, issue := range issues {
+		prints = append(prints, issue.Fingerprint)
+	}
+	return prints
+}
+
+func TestFingerprints(t *testing.T) {
+	t.Parallel()
+	original := fingerprints(fingerprintSource, "slice[:len(slice)]")
+	assert.Len(t, original, 2)
+	assert.NotEqual(t, original[0], original[1], "identical lines are told apart by their occurrence")
+
+	t.Run("stable when unrelated lines are inserted above", func(t *testing.T) {
+		t.Parallel()
+		shifted := strings.Replace(fingerprintSource, "package foo\n", "package foo\n\nimport \"strings\"\n\nvar _ = strings.Fields\n", 1)
+		assert.Equal(t, original, fingerprints(shifted, "slice[:len(slice)]"))
+	})
+
+	t.Run("stable when reindented", func(t *testing.T) {
+		t.Parallel()
+		reindented := strings.ReplaceAll(fingerprintSource, "\t_ =", "    _  =")
+		assert.Equal(t, original, fingerprints(reindented, "slice[:len(slice)]"))
+	})
+
+	t.Run("changes with the offending code", func(t *testing.T) {
+		t.Parallel()
+		changed := strings.Replace(fingerprintSource, "_ = slice[:len(slice)]", "x := slice[:len(slice)]", 1)
+		prints := fingerprints(changed, "slice[:len(slice)]")
+		assert.NotEqual(t, original[0], prints[0])
+		// the second line is now the first occurrence of its text
+		assert.Equal(t, original[0], prints[1])
+	})
+
+	t.Run("issues out of order", func(t *testing.T) {
+		t.Parallel()
+		issues := fingerprintIssues(fingerprintSource, "slice[:len(slice)]")
+		issues[0], issues[1] = issues[1], issues[0]
+		SetFingerprints(issues, strings.Split(fingerprintSource, "\n"))
+		assert.Equal(t, original[1], issues[0].Fingerprint)
+		assert.Equal(t, original[0], issues[1].Fingerprint)
+	})
+}
+
+func TestFingerprintExcludesLineNumbers(t *testing.T) {
+	t.Parallel()
+	a := Fingerprint("rule", "a.gno", "x := 1", 0)
+	assert.Equal(t, a, Fingerprint("rule", "a.gno", "x := 1", 0))
+	assert.NotEqual(t, a, Fingerprint("rule", "b.gno", "x := 1", 0))
+	assert.NotEqual(t, a, Fingerprint("other", "a.gno", "x := 1", 0))
+	assert.NotEqual(t, a, Fingerprint("rule", "a.gno", "x := 1", 1))
+}
@@ -1,6 +1,8 @@
 package types
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -19,6 +21,65 @@ type Issue struct {
 	End        token.Position `json:"end"`
 	Confidence float64        `json:"confidence"` // 0.0 to 1.0
 	Severity   Severity       `json:"severity"`
+	// Related lists secondary locations the message refers to,
+	// such as the matching declaration or the other duplicate branch.
+	Related []Location `json:"related,omitempty"`
+	// Fingerprint tracks the issue across runs, see SetFingerprints.
+	Fingerprint string `json:"fingerprint,omitempty"`
+	// SuggestedFix is the fix of the issue as edits of its file, applied
+	// all together or not at all. Without it, the fix of an issue replaces
+	// its lines with the Suggestion.
+	SuggestedFix []TextEdit `json:"suggested_fix,omitempty"`
+	// MessageID and MessageArgs identify the message in the catalog of the
+	// messages package, which translates it. They are empty for the rules
+	// writing their messages directly.
+	MessageID   string            `json:"-"`
+	MessageArgs map[string]string `json:"-"`
+}
+
+// TextEdit replaces the text of a file between two positions, Start
+// included and End excluded, with NewText. Edits are applied by offset.
+type TextEdit struct {
+	Start   token.Position `json:"start"`
+	End     token.Position `json:"end"`
+	NewText string         `json:"new_text"`
+}
+
+// Location is a secondary position referenced by an issue.
+type Location struct {
+	Position token.Position
+	Label    string // short description of the location, e.g. "declared here"
+}
+
+// MarshalJSON flattens the position, keeping its filename since a related
+// location may be in another file than the issue.
+func (l Location) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Filename string `json:"filename"`
+		Offset   int    `json:"offset"`
+		Line     int    `json:"line"`
+		Column   int    `json:"column"`
+		Label    string `json:"label"`
+	}{l.Position.Filename, l.Position.Offset, l.Position.Line, l.Position.Column, l.Label})
+}
+
+// Range returns the start and end positions of the issue. Issues that only
+// carry a start position, or whose end is invalid, are treated as a point.
+func (i Issue) Range() (start, end token.Position) {
+	start, end = i.Start, i.End
+	if !end.IsValid() || end.Line < start.Line || (end.Line == start.Line && end.Column < start.Column) {
+		end = start
+	}
+	return start, end
+}
+
+// ID identifies the issue within a run, from its rule, file, range and
+// message. Exported fixes refer to the issue they come from by this ID.
+func (i Issue) ID() string {
+	h := sha256.New()
+	fmt.Fprintf(h, "%s\x00%s\x00%d:%d-%d:%d\x00%s",
+		i.Rule, i.Filename, i.Start.Line, i.Start.Column, i.End.Line, i.End.Column, i.Message)
+	return hex.EncodeToString(h.Sum(nil))[:16]
 }
 
 func (i Issue) String() string {
@@ -35,28 +96,34 @@ type PositionWithoutFilename struct {
 }
 
 type IssueWithoutFilename struct {
-	Rule       string                  `json:"rule"`
-	Category   string                  `json:"category"`
-	Message    string                  `json:"message"`
-	Suggestion string                  `json:"suggestion"`
-	Note       string                  `json:"note"`
-	Start      PositionWithoutFilename `json:"start"`
-	End        PositionWithoutFilename `json:"end"`
-	Confidence float64                 `json:"confidence"`
-	Severity   Severity                `json:"severity"`
+	ID          string                  `json:"id"`
+	Rule        string                  `json:"rule"`
+	Category    string                  `json:"category"`
+	Message     string                  `json:"message"`
+	Suggestion  string                  `json:"suggestion"`
+	Note        string                  `json:"note"`
+	Start       PositionWithoutFilename `json:"start"`
+	End         PositionWithoutFilename `json:"end"`
+	Confidence  float64                 `json:"confidence"`
+	Severity    Severity                `json:"severity"`
+	Related     []Location              `json:"related,omitempty"`
+	Fingerprint string                  `json:"fingerprint,omitempty"`
 }
 
 func (i *Issue) MarshalJSON() ([]byte, error) {
 	return json.Marshal(&IssueWithoutFilename{
-		Rule:       i.Rule,
-		Category:   i.Category,
-		Message:    i.Message,
-		Suggestion: i.Suggestion,
-		Note:       i.Note,
-		Start:      PositionWithoutFilename{Offset: i.Start.Offset, Line: i.Start.Line, Column: i.Start.Column},
-		End:        PositionWithoutFilename{Offset: i.End.Offset, Line: i.End.Line, Column: i.End.Column},
-		Confidence: i.Confidence,
-		Severity:   i.Severity,
+		ID:          i.ID(),
+		Rule:        i.Rule,
+		Category:    i.Category,
+		Message:     i.Message,
+		Suggestion:  i.Suggestion,
+		Note:        i.Note,
+		Start:       PositionWithoutFilename{Offset: i.Start.Offset, Line: i.Start.Line, Column: i.Start.Column},
+		End:         PositionWithoutFilename{Offset: i.End.Offset, Line: i.End.Line, Column: i.End.Column},
+		Confidence:  i.Confidence,
+		Severity:    i.Severity,
+		Related:     i.Related,
+		Fingerprint: i.Fingerprint,
 	})
 }
 
@@ -66,11 +133,30 @@ const (
 	SeverityError Severity = iota
 	SeverityWarning
 	SeverityInfo
+	SeverityHint
 	SeverityOff
 )
 
+var severityNames = [...]string{"ERROR", "WARNING", "INFO", "HINT", "OFF"}
+
 func (s Severity) String() string {
-	return [...]string{"ERROR", "WARNING", "INFO", "OFF"}[s]
+	return severityNames[s]
+}
+
+// ParseSeverity returns the severity with the given name, such as WARNING.
+func ParseSeverity(name string) (Severity, error) {
+	for i, severityName := range severityNames {
+		if name == severityName {
+			return Severity(i), nil
+		}
+	}
+	return SeverityOff, errors.New("invalid severity level")
+}
+
+// AtLeast reports whether the severity is as severe as the given one, or
+// more. Rules that are off are never at least any severity.
+func (s Severity) AtLeast(level Severity) bool {
+	return s != SeverityOff && s <= level
 }
 
 // MarshalJSON marshals the Severity to JSON as a string.
@@ -84,20 +170,8 @@ func (s Severity) UnmarshalJSON(data []byte) error {
 		return err
 	}
 
-	switch severityStr {
-	case "ERROR":
-		s = SeverityError
-	case "WARNING":
-		s = SeverityWarning
-	case "INFO":
-		s = SeverityInfo
-	case "OFF":
-		s = SeverityOff
-	default:
-		return errors.New("invalid severity level")
-	}
-
-	return nil
+	_, err := ParseSeverity(severityStr)
+	return err
 }
 
 // UnmarshalYAML unmarshals the Severity from YAML as a string.
@@ -107,22 +181,73 @@ func (s *Severity) UnmarshalYAML(unmarshal func(interface{}) error) error {
 		return err
 	}
 
-	switch severityStr {
-	case "ERROR":
-		*s = SeverityError
-	case "WARNING":
-		*s = SeverityWarning
-	case "INFO":
-		*s = SeverityInfo
-	case "OFF":
-		*s = SeverityOff
-	default:
-		return errors.New("invalid severity level")
+	severity, err := ParseSeverity(severityStr)
+	if err != nil {
+		return err
 	}
+	*s = severity
+	return nil
+}
+
+// OptionType is the kind of value accepted by a rule option.
+type OptionType int
+
+const (
+	OptionInt OptionType = iota
+	OptionFloat
+	OptionBool
+	OptionString
+	OptionStringList
+)
+
+func (t OptionType) String() string {
+	return [...]string{"int", "float", "bool", "string", "string list"}[t]
+}
 
+// RuleOption describes a single configurable option of a lint rule.
+type RuleOption struct {
+	Name        string
+	Description string
+	Type        OptionType
+	Default     interface{}
+	// Min and Max bound the values of int and float options, nil meaning
+	// no bound. See Limit.
+	Min, Max *float64
+	// Validate checks the values of string and string list options
+	// beyond their type, one string at a time.
+	Validate func(value string) error
+}
+
+// Limit returns a bound for RuleOption.Min and RuleOption.Max.
+func Limit(v float64) *float64 {
+	return &v
+}
+
+// CheckRange reports whether a numeric value is within the bounds of the option.
+func (o RuleOption) CheckRange(value float64) error {
+	if o.Min != nil && value < *o.Min {
+		return fmt.Errorf("must be at least %v", *o.Min)
+	}
+	if o.Max != nil && value > *o.Max {
+		return fmt.Errorf("must be at most %v", *o.Max)
+	}
 	return nil
 }
 
+// Constraint describes the values the option accepts, such as "int" or
+// "float, between 0 and 1".
+func (o RuleOption) Constraint() string {
+	switch {
+	case o.Min != nil && o.Max != nil:
+		return fmt.Sprintf("%s, between %v and %v", o.Type, *o.Min, *o.Max)
+	case o.Min != nil:
+		return fmt.Sprintf("%s, at least %v", o.Type, *o.Min)
+	case o.Max != nil:
+		return fmt.Sprintf("%s, at most %v", o.Type, *o.Max)
+	}
+	return o.Type.String()
+}
+
 // Rule represents an individual rule with an ID and severity.
 type ConfigRule struct {
 	Severity Severity    `yaml:"severity"`
@@ -0,0 +1,121 @@
+package types
+
+import (
+	"encoding/json"
+	"go/token"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIssueRange(t *testing.T) {
+	t.Parallel()
+
+	start := token.Position{Line: 3, Column: 5}
+	tests := []struct {
+		name string
+		end  token.Position
+		want token.Position
+	}{
+		{"range", token.Position{Line: 4, Column: 2}, token.Position{Line: 4, Column: 2}},
+		{"point", token.Position{}, start},
+		{"end before start", token.Position{Line: 3, Column: 1}, start},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			gotStart, gotEnd := Issue{Start: start, End: tt.end}.Range()
+			assert.Equal(t, start, gotStart)
+			assert.Equal(t, tt.want, gotEnd)
+		})
+	}
+}
+
+func TestIssueRelatedJSON(t *testing.T) {
+	t.Parallel()
+
+	issue := &Issue{
+		Rule:  "duplicate",
+		Start: token.Position{Filename: "a.gno", Line: 1, Column: 1},
+		Related: []Location{
+			{Position: token.Position{Filename: "b.gno", Line: 7, Column: 2}, Label: "first defined here"},
+		},
+	}
+
+	data, err := json.Marshal(issue)
+	require.NoError(t, err)
+
+	var decoded map[string]interface{}
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, []interface{}{map[string]interface{}{
+		"filename": "b.gno",
+		"offset":   float64(0),
+		"line":     float64(7),
+		"column":   float64(2),
+		"label":    "first defined here",
+	}}, decoded["related"])
+
+	// point issues without related locations keep the previous shape
+	data, err = json.Marshal(&Issue{Rule: "point"})
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "related")
+}
+
+func TestRuleOptionRange(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		option     RuleOption
+		constraint string
+		valid      []float64
+		invalid    map[float64]string
+	}{
+		{
+			option:     RuleOption{Type: OptionInt},
+			constraint: "int",
+			valid:      []float64{-1, 0, 100},
+		},
+		{
+			option:     RuleOption{Type: OptionInt, Min: Limit(1)},
+			constraint: "int, at least 1",
+			valid:      []float64{1, 100},
+			invalid:    map[float64]string{0: "must be at least 1"},
+		},
+		{
+			option:     RuleOption{Type: OptionFloat, Min: Limit(0), Max: Limit(1)},
+			constraint: "float, between 0 and 1",
+			valid:      []float64{0, 0.5, 1},
+			invalid:    map[float64]string{-0.1: "must be at least 0", 1.5: "must be at most 1"},
+		},
+	}
+
+	for _, tc := range tests {
+		assert.Equal(t, tc.constraint, tc.option.Constraint())
+		for _, v := range tc.valid {
+			assert.NoError(t, tc.option.CheckRange(v), "%v", v)
+		}
+		for v, msg := range tc.invalid {
+			assert.EqualError(t, tc.option.CheckRange(v), msg)
+		}
+	}
+}
+
+func TestParseSeverity(t *testing.T) {
+	t.Parallel()
+
+	for _, severity := range []Severity{SeverityError, SeverityWarning, SeverityInfo, SeverityHint, SeverityOff} {
+		got, err := ParseSeverity(severity.String())
+		require.NoError(t, err)
+		assert.Equal(t, severity, got)
+	}
+	_, err := ParseSeverity("hint")
+	assert.Error(t, err)
+
+	assert.True(t, SeverityError.AtLeast(SeverityWarning))
+	assert.True(t, SeverityHint.AtLeast(SeverityHint))
+	assert.False(t, SeverityHint.AtLeast(SeverityInfo))
+	assert.False(t, SeverityOff.AtLeast(SeverityHint))
+	assert.True(t, SeverityInfo.AtLeast(SeverityHint))
+}
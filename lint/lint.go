@@ -2,12 +2,20 @@ package lint
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
+	"runtime"
+	"runtime/debug"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/gnolang/tlin/internal"
 	"github.com/gnolang/tlin/internal/lints"
+	"github.com/gnolang/tlin/internal/messages"
 	tt "github.com/gnolang/tlin/internal/types"
 	"go.uber.org/zap"
 	"gopkg.in/yaml.v3"
@@ -22,9 +30,49 @@ type LintEngine interface {
 
 // export the function NewEngine to be used in other packages
 func New(rootDir string, source []byte, configurationPath string) (*internal.Engine, error) {
-	config, _ := parseConfigurationFile(configurationPath)
+	config, configErr := parseConfigurationFile(configurationPath)
+	if configErr != nil && !errors.Is(configErr, fs.ErrNotExist) {
+		return nil, configErr
+	}
+	if configErr != nil {
+		configurationPath = ""
+	}
+	return newEngine(rootDir, source, config, configurationPath)
+}
 
-	return internal.NewEngine(rootDir, source, config.Rules)
+// newEngine creates an engine with the given configuration. The path of the
+// configuration file, if any, is used to report where settings come from
+// and to resolve the paths the configuration refers to.
+func newEngine(rootDir string, source []byte, config Config, configurationPath string) (*internal.Engine, error) {
+	engine, err := internal.NewEngine(rootDir, source, config.Rules)
+	if err != nil {
+		return nil, err
+	}
+	if configurationPath != "" {
+		engine.SetConfigPath(configurationPath)
+	}
+	if config.Preset != "" {
+		if err := engine.SetPreset(config.Preset); err != nil {
+			return nil, err
+		}
+	}
+	if config.GnoVersion != "" {
+		engine.SetGnoVersion(config.GnoVersion)
+	}
+	if config.Messages != "" {
+		path := config.Messages
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(filepath.Dir(configurationPath), path)
+		}
+		catalog, err := messages.LoadCatalog(path)
+		if err != nil {
+			return nil, err
+		}
+		engine.SetMessages(catalog)
+	}
+	engine.Exclude(filepath.Dir(configurationPath), config.Exclude...)
+
+	return engine, nil
 }
 
 func ProcessSources(
@@ -56,19 +104,380 @@ func ProcessFiles(
 	paths []string,
 	processor func(LintEngine, string) ([]tt.Issue, error),
 ) ([]tt.Issue, error) {
+	return ProcessFilesWithOptions(ctx, logger, engine, paths, processor, ProcessOptions{})
+}
+
+// ProcessOptions tunes how files are scheduled by ProcessFilesWithOptions.
+// The zero value processes files one at a time without any limit.
+type ProcessOptions struct {
+	// Concurrency bounds the number of files processed in parallel.
+	// Values below 1 are treated as 1.
+	Concurrency int
+	// FileTimeout bounds the time spent on a single file. Zero disables the limit.
+	FileTimeout time.Duration
+	// MemoryLimit is a soft heap budget in bytes, checked between files.
+	// Once exceeded, the remaining files are processed by a single worker.
+	// Zero disables the check.
+	MemoryLimit uint64
+	// BeforeAnalysis and AfterAnalysis, when set, are called right before the
+	// first collected file is processed and right after the last one, so that
+	// only the analysis phase is measured when profiling.
+	BeforeAnalysis func() error
+	AfterAnalysis  func() error
+}
+
+// ProcessFilesWithOptions processes every file found under the given paths with a
+// bounded pool of workers. Issues are returned in the same order as a
+// sequential run, regardless of the number of workers.
+func ProcessFilesWithOptions(
+	ctx context.Context,
+	logger *zap.Logger,
+	engine LintEngine,
+	paths []string,
+	processor func(LintEngine, string) ([]tt.Issue, error),
+	opts ProcessOptions,
+) ([]tt.Issue, error) {
+	jobs, err := collectJobs(logger, paths)
+	if err != nil {
+		return nil, err
+	}
+
+	results, _, err := runJobs(ctx, logger, engine, jobs, withoutContext(processor), opts, nil)
+	if err != nil {
+		return nil, err
+	}
+
 	var allIssues []tt.Issue
+	for i, result := range results {
+		if result.err != nil {
+			// errors on explicitly requested files abort the run,
+			// errors on files found while walking a directory are only logged.
+			if jobs[i].explicit {
+				if logger != nil {
+					logger.Error("Error processing path", zap.String("path", jobs[i].path), zap.Error(result.err))
+				}
+				return nil, result.err
+			}
+			if logger != nil {
+				logger.Error("Error processing file", zap.String("file", jobs[i].path), zap.Error(result.err))
+			}
+			continue
+		}
+		allIssues = append(allIssues, result.issues...)
+	}
+
+	// the suppression list is checked once every file has been linted
+	if suppressions, ok := engine.(interface{ SuppressionIssues() []tt.Issue }); ok {
+		allIssues = append(allIssues, suppressions.SuppressionIssues()...)
+	}
+
+	return allIssues, nil
+}
+
+// StreamFilesWithOptions processes the files like ProcessFilesWithOptions,
+// but hands the issues of each file to emit as soon as the file is done
+// instead of collecting them, so that memory stays flat on large trees.
+//
+// The calls to emit are serialized. The issues of a file are passed in one
+// call, in the order the engine reported them, but files are emitted in the
+// order they finish, which varies with the number of workers. Issues of the
+// suppression list are emitted last. Once emit fails, the remaining issues
+// are dropped and its error is returned after the run.
+//
+// Errors on explicitly requested files are returned after the run too, since
+// the issues of the other files may already have been emitted.
+func StreamFilesWithOptions(
+	ctx context.Context,
+	logger *zap.Logger,
+	engine LintEngine,
+	paths []string,
+	processor func(LintEngine, string) ([]tt.Issue, error),
+	opts ProcessOptions,
+	emit func(issues []tt.Issue) error,
+) (internal.RunSummary, error) {
+	jobs, err := collectJobs(logger, paths)
+	if err != nil {
+		return internal.RunSummary{}, err
+	}
+
+	var (
+		emitErr     error
+		explicitErr error
+	)
+	// runJobs serializes the calls, so the errors need no locking
+	onResult := func(i int, result fileResult) {
+		if result.err != nil {
+			if jobs[i].explicit {
+				if logger != nil {
+					logger.Error("Error processing path", zap.String("path", jobs[i].path), zap.Error(result.err))
+				}
+				if explicitErr == nil {
+					explicitErr = result.err
+				}
+				return
+			}
+			if logger != nil {
+				logger.Error("Error processing file", zap.String("file", jobs[i].path), zap.Error(result.err))
+			}
+			return
+		}
+		if emitErr == nil && len(result.issues) > 0 {
+			emitErr = emit(result.issues)
+		}
+	}
+
+	_, summary, err := runJobs(ctx, logger, engine, jobs, withoutContext(processor), opts, onResult)
+	if err != nil {
+		return summary, err
+	}
+
+	if suppressions, ok := engine.(interface{ SuppressionIssues() []tt.Issue }); ok && emitErr == nil {
+		if issues := suppressions.SuppressionIssues(); len(issues) > 0 {
+			summary.Issues += len(issues)
+			emitErr = emit(issues)
+		}
+	}
+
+	if explicitErr != nil {
+		return summary, explicitErr
+	}
+	return summary, emitErr
+}
+
+// collectJobs lists the files of every path, in walk order.
+func collectJobs(logger *zap.Logger, paths []string) ([]fileJob, error) {
+	var jobs []fileJob
 	for _, path := range paths {
-		issues, err := ProcessPath(ctx, logger, engine, path, processor)
+		files, err := collectFiles(path)
 		if err != nil {
 			if logger != nil {
 				logger.Error("Error processing path", zap.String("path", path), zap.Error(err))
 			}
 			return nil, err
 		}
-		allIssues = append(allIssues, issues...)
+		jobs = append(jobs, files...)
 	}
+	return jobs, nil
+}
 
-	return allIssues, nil
+// runJobs processes the jobs with the worker pool, reporting the run to the
+// observer of the engine. onResult, when set, is called with the result of
+// each file as soon as it is done, and the returned results no longer hold
+// the issues.
+func runJobs(
+	ctx context.Context,
+	logger *zap.Logger,
+	engine LintEngine,
+	jobs []fileJob,
+	processor contextProcessor,
+	opts ProcessOptions,
+	onResult func(int, fileResult),
+) ([]fileResult, internal.RunSummary, error) {
+	observer := observerOf(engine)
+	observer.OnRunStart(len(jobs))
+	start := time.Now()
+
+	if opts.BeforeAnalysis != nil {
+		if err := opts.BeforeAnalysis(); err != nil {
+			return nil, internal.RunSummary{}, err
+		}
+	}
+	streamed := 0
+	if onResult != nil {
+		emit := onResult
+		onResult = func(i int, result fileResult) {
+			streamed += len(result.issues)
+			emit(i, result)
+		}
+	}
+	results := processConcurrently(ctx, logger, engine, jobs, processor, opts, onResult)
+	if opts.AfterAnalysis != nil {
+		if err := opts.AfterAnalysis(); err != nil {
+			return nil, internal.RunSummary{}, err
+		}
+	}
+
+	summary := internal.RunSummary{Files: len(jobs), Issues: streamed, Duration: time.Since(start)}
+	for _, result := range results {
+		if result.err != nil {
+			summary.Failed++
+		}
+		summary.Issues += len(result.issues)
+	}
+	observer.OnRunDone(summary)
+
+	return results, summary, nil
+}
+
+// observerOf returns the observer of engines that report their progress,
+// such as the one returned by New.
+func observerOf(engine LintEngine) internal.Observer {
+	if observed, ok := engine.(interface{ Observer() internal.Observer }); ok {
+		return observed.Observer()
+	}
+	return internal.NopObserver{}
+}
+
+type fileJob struct {
+	path     string
+	explicit bool
+}
+
+type fileResult struct {
+	issues []tt.Issue
+	err    error
+}
+
+// collectFiles lists the lintable files of a path, in walk order.
+func collectFiles(path string) ([]fileJob, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("error accessing %s: %w", path, err)
+	}
+
+	if !info.IsDir() {
+		if !hasDesiredExtension(path) {
+			return nil, nil
+		}
+		return []fileJob{{path: path, explicit: true}}, nil
+	}
+
+	var jobs []fileJob
+	err = filepath.Walk(path, func(filePath string, fileInfo os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !fileInfo.IsDir() && hasDesiredExtension(filePath) {
+			jobs = append(jobs, fileJob{path: filePath})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error walking directory %s: %w", path, err)
+	}
+	return jobs, nil
+}
+
+func processConcurrently(
+	ctx context.Context,
+	logger *zap.Logger,
+	engine LintEngine,
+	jobs []fileJob,
+	processor contextProcessor,
+	opts ProcessOptions,
+	onResult func(int, fileResult),
+) []fileResult {
+	results := make([]fileResult, len(jobs))
+	if len(jobs) == 0 {
+		return results
+	}
+
+	workers := opts.Concurrency
+	if workers < 1 {
+		workers = 1
+	}
+	if workers > len(jobs) {
+		workers = len(jobs)
+	}
+
+	var (
+		wg       sync.WaitGroup
+		degraded atomic.Bool
+		warnOnce sync.Once
+		queue    = make(chan int)
+		resultMu sync.Mutex
+	)
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(worker int) {
+			defer wg.Done()
+			for i := range queue {
+				result := processWithTimeout(ctx, engine, jobs[i].path, processor, opts.FileTimeout)
+				if onResult != nil {
+					resultMu.Lock()
+					onResult(i, result)
+					resultMu.Unlock()
+					result.issues = nil
+				}
+				results[i] = result
+
+				if opts.MemoryLimit > 0 && !degraded.Load() && exceedsMemoryLimit(opts.MemoryLimit) {
+					degraded.Store(true)
+					warnOnce.Do(func() {
+						if logger != nil {
+							logger.Warn("Memory limit exceeded, continuing with a single worker",
+								zap.Uint64("limit", opts.MemoryLimit))
+						}
+						debug.FreeOSMemory()
+					})
+				}
+				// the first worker always keeps going so the queue is drained
+				if worker > 0 && degraded.Load() {
+					return
+				}
+			}
+		}(w)
+	}
+
+	next := 0
+dispatch:
+	for ; next < len(jobs); next++ {
+		select {
+		case queue <- next:
+		case <-ctx.Done():
+			break dispatch
+		}
+	}
+	close(queue)
+	wg.Wait()
+
+	for i := next; i < len(jobs); i++ {
+		results[i] = fileResult{err: fmt.Errorf("error processing %s: %w", jobs[i].path, ctx.Err())}
+		if onResult != nil {
+			onResult(i, results[i])
+		}
+	}
+
+	return results
+}
+
+// processWithTimeout runs the processor on a file, giving up once the file
+// timeout or the run context expires. The processor is handed the context of
+// the file, but may ignore it: an abandoned file keeps running in the
+// background until the processor returns, and its result is discarded.
+func processWithTimeout(
+	ctx context.Context,
+	engine LintEngine,
+	path string,
+	processor contextProcessor,
+	timeout time.Duration,
+) fileResult {
+	if timeout <= 0 {
+		issues, err := processor(ctx, engine, path)
+		return fileResult{issues: issues, err: err}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	done := make(chan fileResult, 1)
+	go func() {
+		issues, err := processor(ctx, engine, path)
+		done <- fileResult{issues: issues, err: err}
+	}()
+
+	select {
+	case result := <-done:
+		return result
+	case <-ctx.Done():
+		return fileResult{err: fmt.Errorf("error processing %s: %w", path, ctx.Err())}
+	}
+}
+
+func exceedsMemoryLimit(limit uint64) bool {
+	var stats runtime.MemStats
+	runtime.ReadMemStats(&stats)
+	return stats.HeapAlloc > limit
 }
 
 func ProcessPath(
@@ -121,6 +530,29 @@ func ProcessFile(engine LintEngine, filePath string) ([]tt.Issue, error) {
 	return engine.Run(filePath)
 }
 
+// ProcessFileContext is ProcessFile for the engines whose rules can be
+// given up on once ctx is done, such as internal.Engine. Other engines
+// ignore ctx.
+func ProcessFileContext(ctx context.Context, engine LintEngine, filePath string) ([]tt.Issue, error) {
+	if engine, ok := engine.(interface {
+		RunContext(context.Context, string) ([]tt.Issue, error)
+	}); ok {
+		return engine.RunContext(ctx, filePath)
+	}
+	return engine.Run(filePath)
+}
+
+// contextProcessor lints a file, knowing the context of the file.
+type contextProcessor func(ctx context.Context, engine LintEngine, filePath string) ([]tt.Issue, error)
+
+// withoutContext adapts the processors of the exported functions, which
+// don't take a context.
+func withoutContext(processor func(LintEngine, string) ([]tt.Issue, error)) contextProcessor {
+	return func(_ context.Context, engine LintEngine, filePath string) ([]tt.Issue, error) {
+		return processor(engine, filePath)
+	}
+}
+
 func ProcessSource(engine LintEngine, source []byte) ([]tt.Issue, error) {
 	return engine.RunSource(source)
 }
@@ -131,23 +563,76 @@ func hasDesiredExtension(path string) bool {
 
 // Config represents the overall configuration with a name and a slice of rules.
 type Config struct {
-	Name  string                   `yaml:"name"`
-	Rules map[string]tt.ConfigRule `yaml:"rules"`
+	Name   string                   `yaml:"name"`
+	Preset string                   `yaml:"preset,omitempty"`
+	Rules  map[string]tt.ConfigRule `yaml:"rules"`
+
+	// Engine tuning, all of which can be overridden from the command line.
+	Concurrency int           `yaml:"concurrency,omitempty"`
+	Timeout     time.Duration `yaml:"timeout,omitempty"`
+	FileTimeout time.Duration `yaml:"file-timeout,omitempty"`
+	RuleTimeout time.Duration `yaml:"rule-timeout,omitempty"`
+	MemoryLimit int           `yaml:"memory-limit,omitempty"` // in MiB
+
+	// Messages is the path of the catalog translating the issue messages,
+	// relative to the configuration file.
+	Messages string `yaml:"messages,omitempty"`
+	// RulePacks are the paths of the rule packs declaring rewrite rules,
+	// relative to the configuration file. Their rules are registered while
+	// the configuration is read, so that it can set them as any other rule.
+	RulePacks []string `yaml:"rule-packs,omitempty"`
+	// GnoVersion is the gno version the std API is checked against.
+	GnoVersion string `yaml:"gno-version,omitempty"`
+	// Exclude lists the globs of the files left out of the run, relative
+	// to the configuration file.
+	Exclude []string `yaml:"exclude,omitempty"`
+	// FailOn is the least severity of the issues failing the run, such as
+	// WARNING. Every issue fails it when empty.
+	FailOn string `yaml:"fail-on,omitempty"`
+}
+
+// LoadConfig reads the configuration file at the given path.
+// A missing file is not an error and yields an empty configuration.
+func LoadConfig(configurationPath string) (Config, error) {
+	config, err := parseConfigurationFile(configurationPath)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return Config{}, err
+	}
+	return config, nil
 }
 
 func parseConfigurationFile(configurationPath string) (Config, error) {
 	var config Config
 
 	// Read the configuration file
-	f, err := os.Open(configurationPath)
+	content, err := os.ReadFile(configurationPath)
 	if err != nil {
 		return config, err
 	}
-	defer f.Close()
+
+	// Register the rules of the rule packs, which the rules may configure,
+	// leaving the errors of the document to the validation
+	var packs struct {
+		RulePacks []string `yaml:"rule-packs"`
+	}
+	if yaml.Unmarshal(content, &packs) == nil {
+		for _, path := range packs.RulePacks {
+			if !filepath.IsAbs(path) {
+				path = filepath.Join(filepath.Dir(configurationPath), path)
+			}
+			if err := internal.LoadRulePack(path); err != nil {
+				return config, err
+			}
+		}
+	}
+
+	// Reject typos and unknown rules before decoding
+	if err := internal.ValidateConfig(content); err != nil {
+		return config, fmt.Errorf("invalid configuration file %s: %w", configurationPath, err)
+	}
 
 	// Parse the configuration file
-	decoder := yaml.NewDecoder(f)
-	err = decoder.Decode(&config)
+	err = yaml.Unmarshal(content, &config)
 	if err != nil {
 		return config, err
 	}
@@ -2,14 +2,20 @@ package lint
 
 import (
 	"context"
+	"fmt"
 	"go/token"
+	"io/fs"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/gnolang/tlin/internal"
 	"github.com/gnolang/tlin/internal/types"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
 )
 
@@ -169,6 +175,399 @@ func TestProcessFiles(t *testing.T) {
 	mockEngine.AssertExpectations(t)
 }
 
+func TestProcessFilesWithOptions(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	tempDir, err := os.MkdirTemp("", "test")
+	assert.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(tempDir) })
+
+	names := make([]string, 0, 20)
+	for i := 0; i < 20; i++ {
+		names = append(names, fmt.Sprintf("test%02d.go", i))
+	}
+	createTempFiles(t, tempDir, names...)
+
+	processor := func(_ LintEngine, path string) ([]types.Issue, error) {
+		return []types.Issue{{Rule: "rule", Filename: path}}, nil
+	}
+
+	sequential, err := ProcessFilesWithOptions(ctx, nil, nil, []string{tempDir}, processor, ProcessOptions{})
+	assert.NoError(t, err)
+	assert.Len(t, sequential, 20)
+
+	t.Run("parallel keeps order", func(t *testing.T) {
+		t.Parallel()
+		parallel, err := ProcessFilesWithOptions(ctx, nil, nil, []string{tempDir}, processor, ProcessOptions{Concurrency: 8})
+		assert.NoError(t, err)
+		assert.Equal(t, sequential, parallel)
+	})
+
+	t.Run("memory limit", func(t *testing.T) {
+		t.Parallel()
+		// a budget of one byte is always exceeded
+		issues, err := ProcessFilesWithOptions(ctx, nil, nil, []string{tempDir}, processor, ProcessOptions{Concurrency: 4, MemoryLimit: 1})
+		assert.NoError(t, err)
+		assert.Equal(t, sequential, issues)
+	})
+
+	t.Run("file timeout", func(t *testing.T) {
+		t.Parallel()
+		slow := filepath.Join(tempDir, "test05.go")
+		slowProcessor := func(engine LintEngine, path string) ([]types.Issue, error) {
+			if path == slow {
+				time.Sleep(time.Second)
+			}
+			return processor(engine, path)
+		}
+
+		issues, err := ProcessFilesWithOptions(ctx, nil, nil, []string{tempDir}, slowProcessor, ProcessOptions{Concurrency: 2, FileTimeout: 50 * time.Millisecond})
+		assert.NoError(t, err)
+		assert.Len(t, issues, 19)
+		for _, issue := range issues {
+			assert.NotEqual(t, slow, issue.Filename)
+		}
+
+		// an explicitly requested file that times out fails the run
+		_, err = ProcessFilesWithOptions(ctx, nil, nil, []string{slow}, slowProcessor, ProcessOptions{FileTimeout: 50 * time.Millisecond})
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+}
+
+// contextEngine is an engine running files with a context.
+type contextEngine struct {
+	mockLintEngine
+	hasDeadline bool
+}
+
+func (e *contextEngine) RunContext(ctx context.Context, filePath string) ([]types.Issue, error) {
+	_, e.hasDeadline = ctx.Deadline()
+	return []types.Issue{{Rule: "rule", Filename: filePath}}, nil
+}
+
+func TestProcessFileContext(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	// the file timeout reaches the engine
+	engine := &contextEngine{}
+	result := processWithTimeout(ctx, engine, "a.go", ProcessFileContext, time.Minute)
+	require.NoError(t, result.err)
+	assert.Len(t, result.issues, 1)
+	assert.True(t, engine.hasDeadline)
+
+	// engines without a context are run as usual
+	mockEngine := setupMockEngine([]types.Issue{{Rule: "rule"}}, "a.go")
+	issues, err := ProcessFileContext(ctx, mockEngine, "a.go")
+	require.NoError(t, err)
+	assert.Len(t, issues, 1)
+	mockEngine.AssertExpectations(t)
+}
+
+// countingObserver counts the events of a run and records whether two hooks
+// ever ran at the same time.
+type countingObserver struct {
+	inHook     bool
+	overlapped bool
+
+	files      int
+	started    map[string]int
+	done       map[string]int
+	issues     int
+	ruleErrors int
+	summaries  []internal.RunSummary
+}
+
+func (o *countingObserver) enter() func() {
+	if o.inHook {
+		o.overlapped = true
+	}
+	o.inHook = true
+	return func() { o.inHook = false }
+}
+
+func (o *countingObserver) OnRunStart(files int) {
+	defer o.enter()()
+	o.files = files
+}
+
+func (o *countingObserver) OnFileStart(filename string) {
+	defer o.enter()()
+	o.started[filename]++
+}
+
+func (o *countingObserver) OnFileDone(filename string, issues []types.Issue, _ time.Duration) {
+	defer o.enter()()
+	o.done[filename]++
+	o.issues += len(issues)
+}
+
+func (o *countingObserver) OnRuleError(string, string, error) {
+	defer o.enter()()
+	o.ruleErrors++
+}
+
+func (o *countingObserver) OnRuleSkipped(string, string) {
+	defer o.enter()()
+}
+
+func (o *countingObserver) OnRunDone(summary internal.RunSummary) {
+	defer o.enter()()
+	o.summaries = append(o.summaries, summary)
+}
+
+func TestProcessFilesObserver(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	tempDir := t.TempDir()
+	fixtures := map[string]string{
+		"a.gno": `package a
+
+func f(s []int) []int {
+	return s[:len(s)]
+}
+`,
+		"b/b.go": `package b
+
+func G() {}
+`,
+		"b/c.gno":       "package b\n\nfunc broken( {\n",
+		"b/d/d.gno":     "package d\n",
+		"b/d/notes.txt": "not linted\n",
+	}
+	for name, content := range fixtures {
+		path := filepath.Join(tempDir, name)
+		assert.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		assert.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	}
+
+	engine, err := New(tempDir, nil, filepath.Join(tempDir, ".tlin.yaml"))
+	assert.NoError(t, err)
+	observer := &countingObserver{started: map[string]int{}, done: map[string]int{}}
+	engine.SetObserver(observer)
+
+	issues, err := ProcessFilesWithOptions(ctx, nil, engine, []string{tempDir}, ProcessFile, ProcessOptions{Concurrency: 4})
+	assert.NoError(t, err)
+	assert.NotEmpty(t, issues)
+
+	assert.False(t, observer.overlapped, "hooks must not run concurrently")
+	assert.Equal(t, 4, observer.files)
+	assert.Len(t, observer.started, 4)
+	assert.Len(t, observer.done, 4)
+	for name, count := range observer.started {
+		assert.Equal(t, 1, count, name)
+		assert.Equal(t, 1, observer.done[name], name)
+	}
+	assert.Equal(t, len(issues), observer.issues)
+	assert.Zero(t, observer.ruleErrors)
+
+	if assert.Len(t, observer.summaries, 1) {
+		summary := observer.summaries[0]
+		assert.Equal(t, 4, summary.Files)
+		assert.Equal(t, 1, summary.Failed) // b/c.gno does not parse
+		assert.Equal(t, len(issues), summary.Issues)
+	}
+}
+
+func TestNewWithMessageCatalog(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, ".tlin.yaml")
+	require.NoError(t, os.WriteFile(configPath, []byte("messages: messages.ko.yaml\n"), 0o644))
+	// partial catalog, the other messages stay in English
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "messages.ko.yaml"), []byte(`useless-break: "case 절 끝의 불필요한 break 문"
+`), 0o644))
+
+	engine, err := New(dir, nil, configPath)
+	require.NoError(t, err)
+
+	issues, err := engine.RunSource([]byte(`package a
+
+import "errors"
+
+func f(x int) {
+	switch x {
+	case 1:
+		break
+	}
+	err := errors.New("failed")
+}
+`))
+	require.NoError(t, err)
+
+	messages := make(map[string]string)
+	for _, issue := range issues {
+		messages[issue.Rule] = issue.Message
+	}
+	assert.Equal(t, "case 절 끝의 불필요한 break 문", messages["useless-break"])
+	assert.Equal(t, "error assigned to err is never used", messages["unused-error"])
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "messages.ko.yaml"), []byte(`unused-error: "{name}에 할당된 오류가 사용되지 않습니다: {reason}"
+`), 0o644))
+	_, err = New(dir, nil, configPath)
+	assert.ErrorContains(t, err, `line 1: template of "unused-error" uses unknown parameter {reason}`)
+}
+
+func TestNewWithGnoVersion(t *testing.T) {
+	t.Parallel()
+
+	source := []byte(`package a
+
+import "std"
+
+func Caller() std.Address {
+	return std.GetOrigCaller()
+}
+`)
+	tests := []struct {
+		version string
+		message string
+	}{
+		{"0.1", ""},
+		{"0.2", "std.GetOrigCaller was removed in gno 0.2, use std.OriginCaller instead"},
+		// unknown versions fall back to the newest manifest
+		{"9.9", "std.GetOrigCaller was removed in gno 0.2, use std.OriginCaller instead"},
+	}
+	for _, tc := range tests {
+		dir := t.TempDir()
+		configPath := filepath.Join(dir, ".tlin.yaml")
+		require.NoError(t, os.WriteFile(configPath, []byte("gno-version: "+tc.version+"\n"), 0o644))
+
+		engine, err := New(dir, nil, configPath)
+		require.NoError(t, err)
+		issues, err := engine.RunSource(source)
+		require.NoError(t, err)
+
+		var messages []string
+		for _, issue := range issues {
+			if issue.Rule == "std-api" {
+				messages = append(messages, issue.Message)
+			}
+		}
+		if tc.message == "" {
+			assert.Empty(t, messages, tc.version)
+		} else {
+			assert.Equal(t, []string{tc.message}, messages, tc.version)
+		}
+	}
+}
+
+func TestNewWithExclude(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, ".tlin.yaml")
+	require.NoError(t, os.WriteFile(configPath, []byte("exclude:\n  - \"project/gen/**\"\n"), 0o644))
+	source := []byte("package a\n\nfunc f(x int) {\n\tswitch x {\n\tcase 1:\n\t\tbreak\n\t}\n}\n")
+	root := filepath.Join(dir, "project")
+	paths := []string{filepath.Join(root, "gen", "a.go"), filepath.Join(root, "b.go")}
+	require.NoError(t, os.MkdirAll(filepath.Dir(paths[0]), 0o755))
+	for _, path := range paths {
+		require.NoError(t, os.WriteFile(path, source, 0o644))
+	}
+
+	engine, err := New(root, nil, configPath)
+	require.NoError(t, err)
+	issues, err := ProcessFiles(context.Background(), nil, engine, []string{root}, ProcessFile)
+	require.NoError(t, err)
+	require.NotEmpty(t, issues)
+	// the globs are relative to the configuration file, not to the root
+	for _, issue := range issues {
+		assert.Equal(t, paths[1], issue.Filename)
+	}
+}
+
+func TestStreamFilesWithOptions(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	// work on a copy, the engine writes temporary files next to the sources
+	root := t.TempDir()
+	fixtures := filepath.Join("..", "internal", "lints", "testdata")
+	err := filepath.WalkDir(fixtures, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(root, strings.TrimPrefix(path, fixtures))
+		if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+			return err
+		}
+		return os.WriteFile(target, content, 0o644)
+	})
+	assert.NoError(t, err)
+
+	batchEngine, err := New(root, nil, "")
+	assert.NoError(t, err)
+	batch, err := ProcessFilesWithOptions(ctx, nil, batchEngine, []string{root}, ProcessFile, ProcessOptions{Concurrency: 4})
+	assert.NoError(t, err)
+	assert.NotEmpty(t, batch)
+
+	streamEngine, err := New(root, nil, "")
+	assert.NoError(t, err)
+	var (
+		streamed []types.Issue
+		calls    int
+	)
+	summary, err := StreamFilesWithOptions(ctx, nil, streamEngine, []string{root}, ProcessFile, ProcessOptions{Concurrency: 4}, func(issues []types.Issue) error {
+		calls++
+		streamed = append(streamed, issues...)
+		return nil
+	})
+	assert.NoError(t, err)
+
+	// positions of .gno files name the temporary file the engine linted,
+	// which differs from one run to the other
+	for _, issues := range [][]types.Issue{batch, streamed} {
+		for i := range issues {
+			issues[i].Start.Filename, issues[i].End.Filename = "", ""
+		}
+	}
+
+	assert.ElementsMatch(t, batch, streamed)
+	assert.Equal(t, len(batch), summary.Issues)
+	assert.Greater(t, calls, 1, "issues should be emitted file by file")
+
+	// the issues of each file keep their order
+	byFile := func(issues []types.Issue) map[string][]types.Issue {
+		files := make(map[string][]types.Issue)
+		for _, issue := range issues {
+			files[issue.Filename] = append(files[issue.Filename], issue)
+		}
+		return files
+	}
+	assert.Equal(t, byFile(batch), byFile(streamed))
+}
+
+func TestStreamFilesWithOptionsEmitError(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	tempDir := t.TempDir()
+	paths := createTempFiles(t, tempDir, "test1.go", "test2.go")
+
+	mockEngine := new(mockLintEngine)
+	for i, path := range paths {
+		mockEngine.On("Run", path).Return([]types.Issue{{Rule: fmt.Sprintf("rule%d", i), Filename: path}}, nil)
+	}
+
+	emitErr := fmt.Errorf("broken pipe")
+	calls := 0
+	summary, err := StreamFilesWithOptions(ctx, nil, mockEngine, []string{tempDir}, ProcessFile, ProcessOptions{}, func([]types.Issue) error {
+		calls++
+		return emitErr
+	})
+	assert.ErrorIs(t, err, emitErr)
+	assert.Equal(t, 1, calls, "emit is not called again once it fails")
+	assert.Equal(t, 2, summary.Files)
+	assert.Equal(t, 2, summary.Issues)
+}
+
 func TestProcessSources(t *testing.T) {
 	t.Parallel()
 	logger, _ := zap.NewProduction()
@@ -0,0 +1,230 @@
+// Package linttest checks lint rules against fixture files annotated with
+// the issues they are expected to produce, so that rule tests do not need
+// to build the expected issues by hand.
+//
+// An annotation is a line comment on the line an issue starts at, made of
+// the word want followed by pairs of a quoted message fragment and a rule
+// name:
+//
+//	if a == b { // want "floating point values compared" float-comparison
+//
+// Issues match the annotations of their line by rule name and by message,
+// which must contain the fragment. Every issue needs an annotation and every
+// annotation an issue.
+//
+// Running the tests with the -update flag rewrites the annotations of the
+// fixture files from the issues found instead. The flag is defined by this
+// package, test packages using it must not define their own.
+package linttest
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+
+	tt "github.com/gnolang/tlin/internal/types"
+	"github.com/gnolang/tlin/lint"
+)
+
+var update = flag.Bool("update", false, "rewrite the want annotations of the lint fixtures from the issues found")
+
+// Update reports whether the annotations are being rewritten.
+func Update() bool {
+	return *update
+}
+
+var wantPattern = regexp.MustCompile(`\s*//\s*want\s+(.*)$`)
+
+// Want is an issue expected by an annotation.
+type Want struct {
+	Line    int
+	Message string
+	Rule    string
+}
+
+// RunRules lints the fixture files of dir with an engine limited to the
+// named rules, and checks the issues against the annotations.
+func RunRules(t testing.TB, dir string, rules ...string) {
+	t.Helper()
+	engine, err := lint.New(dir, nil, "")
+	if err != nil {
+		t.Fatalf("creating the engine: %v", err)
+	}
+	if err := engine.EnableOnly(rules...); err != nil {
+		t.Fatal(err)
+	}
+	Run(t, engine, dir)
+}
+
+// Run lints the .gno and .go fixture files of dir with engine, and checks
+// the issues against the annotations. Sub directories are not visited.
+func Run(t testing.TB, engine lint.LintEngine, dir string) {
+	t.Helper()
+	files, err := fixtureFiles(dir)
+	if err != nil {
+		t.Fatalf("listing the fixtures: %v", err)
+	}
+	if len(files) == 0 {
+		t.Fatalf("no fixture files in %s", dir)
+	}
+
+	for _, filename := range files {
+		issues, err := engine.Run(filename)
+		if err != nil {
+			t.Errorf("%s: %v", filename, err)
+			continue
+		}
+		issues = issuesOf(filename, issues)
+
+		content, err := os.ReadFile(filename)
+		if err != nil {
+			t.Fatalf("reading the fixture: %v", err)
+		}
+		if *update {
+			updated := Annotate(string(content), issues)
+			if updated != string(content) {
+				if err := os.WriteFile(filename, []byte(updated), 0o644); err != nil {
+					t.Fatalf("updating the fixture: %v", err)
+				}
+				t.Logf("updated the annotations of %s", filename)
+			}
+			continue
+		}
+
+		wants, err := ParseWants(string(content))
+		if err != nil {
+			t.Errorf("%s: %v", filename, err)
+			continue
+		}
+		for _, problem := range Compare(wants, issues) {
+			t.Errorf("%s:%s", filename, problem)
+		}
+	}
+}
+
+func fixtureFiles(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || strings.HasPrefix(name, "temp_") {
+			continue
+		}
+		if strings.HasSuffix(name, ".gno") || strings.HasSuffix(name, ".go") {
+			files = append(files, filepath.Join(dir, name))
+		}
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// issuesOf keeps the issues located in filename.
+func issuesOf(filename string, issues []tt.Issue) []tt.Issue {
+	var kept []tt.Issue
+	for _, issue := range issues {
+		if filepath.Clean(issue.Filename) == filepath.Clean(filename) {
+			kept = append(kept, issue)
+		}
+	}
+	return kept
+}
+
+// ParseWants returns the issues the annotations of a fixture expect.
+func ParseWants(content string) ([]Want, error) {
+	var wants []Want
+	for i, line := range strings.Split(content, "\n") {
+		match := wantPattern.FindStringSubmatch(line)
+		if match == nil {
+			continue
+		}
+		rest := strings.TrimSpace(match[1])
+		for rest != "" {
+			quoted, err := strconv.QuotedPrefix(rest)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: want a quoted message fragment, got %s", i+1, rest)
+			}
+			message, _ := strconv.Unquote(quoted)
+			fields := strings.Fields(rest[len(quoted):])
+			if len(fields) == 0 {
+				return nil, fmt.Errorf("line %d: want a rule name after %s", i+1, quoted)
+			}
+			wants = append(wants, Want{Line: i + 1, Message: message, Rule: fields[0]})
+			rest = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(rest[len(quoted):]), fields[0]))
+		}
+	}
+	return wants, nil
+}
+
+// Compare matches the issues with the annotations, and describes the
+// issues without annotation and the annotations without issue, each
+// prefixed with its line number.
+func Compare(wants []Want, issues []tt.Issue) []string {
+	matched := make([]bool, len(issues))
+	var problems []string
+	for _, want := range wants {
+		found := false
+		for i, issue := range issues {
+			if !matched[i] && issue.Start.Line == want.Line && issue.Rule == want.Rule && strings.Contains(issue.Message, want.Message) {
+				matched[i], found = true, true
+				break
+			}
+		}
+		if !found {
+			problems = append(problems, fmt.Sprintf("%d: no %s issue matching %q", want.Line, want.Rule, want.Message))
+		}
+	}
+	for i, issue := range issues {
+		if !matched[i] {
+			problems = append(problems, fmt.Sprintf("%d: unexpected %s issue: %s", issue.Start.Line, issue.Rule, issue.Message))
+		}
+	}
+	sort.SliceStable(problems, func(i, j int) bool {
+		return lineOf(problems[i]) < lineOf(problems[j])
+	})
+	return problems
+}
+
+func lineOf(problem string) int {
+	line, _ := strconv.Atoi(problem[:strings.Index(problem, ":")])
+	return line
+}
+
+// Annotate returns content with its annotations replaced by the ones
+// expecting issues, with their full message.
+func Annotate(content string, issues []tt.Issue) string {
+	byLine := make(map[int][]tt.Issue)
+	for _, issue := range issues {
+		byLine[issue.Start.Line] = append(byLine[issue.Start.Line], issue)
+	}
+
+	lines := strings.Split(content, "\n")
+	for i, line := range lines {
+		line = wantPattern.ReplaceAllString(line, "")
+		if found := byLine[i+1]; len(found) > 0 {
+			sort.SliceStable(found, func(a, b int) bool {
+				if found[a].Rule != found[b].Rule {
+					return found[a].Rule < found[b].Rule
+				}
+				return found[a].Start.Column < found[b].Start.Column
+			})
+			var sb strings.Builder
+			sb.WriteString(line)
+			sb.WriteString(" // want")
+			for _, issue := range found {
+				fmt.Fprintf(&sb, " %s %s", strconv.Quote(issue.Message), issue.Rule)
+			}
+			line = sb.String()
+		}
+		lines[i] = line
+	}
+	return strings.Join(lines, "\n")
+}
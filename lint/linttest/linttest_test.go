@@ -0,0 +1,71 @@
+package linttest
+
+import (
+	"go/token"
+	"testing"
+
+	tt "github.com/gnolang/tlin/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func issueAt(line int, rule, message string) tt.Issue {
+	return tt.Issue{Rule: rule, Message: message, Start: token.Position{Line: line, Column: 2}}
+}
+
+func TestParseWants(t *testing.T) {
+	t.Parallel()
+	wants, err := ParseWants("package foo\n\nvar a = b // want \"first\" rule-a \"second \\\"quoted\\\"\" rule-b\n//want `raw` rule-c\n")
+	require.NoError(t, err)
+	assert.Equal(t, []Want{
+		{Line: 3, Message: "first", Rule: "rule-a"},
+		{Line: 3, Message: `second "quoted"`, Rule: "rule-b"},
+		{Line: 4, Message: "raw", Rule: "rule-c"},
+	}, wants)
+
+	for _, content := range []string{
+		"var a = b // want first rule-a",
+		"var a = b // want \"first\"",
+		"var a = b // want \"first\" rule-a \"second\"",
+	} {
+		_, err := ParseWants(content)
+		assert.Error(t, err, content)
+	}
+}
+
+func TestCompare(t *testing.T) {
+	t.Parallel()
+	wants := []Want{
+		{Line: 3, Message: "compared", Rule: "float-comparison"},
+		{Line: 3, Message: "compared", Rule: "float-comparison"},
+		{Line: 5, Message: "useless", Rule: "useless-break"},
+		{Line: 9, Message: "missing", Rule: "useless-break"},
+	}
+	issues := []tt.Issue{
+		issueAt(3, "float-comparison", "values compared with =="),
+		issueAt(3, "float-comparison", "values compared with !="),
+		issueAt(5, "useless-break", "something else"),
+		issueAt(7, "useless-break", "useless break"),
+	}
+	assert.Equal(t, []string{
+		`5: no useless-break issue matching "useless"`,
+		"5: unexpected useless-break issue: something else",
+		"7: unexpected useless-break issue: useless break",
+		`9: no useless-break issue matching "missing"`,
+	}, Compare(wants, issues))
+}
+
+func TestAnnotate(t *testing.T) {
+	t.Parallel()
+	content := "package foo\n\nvar a = b // want \"stale\" old-rule\nvar c = d\n"
+	issues := []tt.Issue{
+		issueAt(4, "rule-b", "second"),
+		issueAt(4, "rule-a", `first "one"`),
+	}
+	annotated := Annotate(content, issues)
+	assert.Equal(t, "package foo\n\nvar a = b\nvar c = d // want \"first \\\"one\\\"\" rule-a \"second\" rule-b\n", annotated)
+
+	wants, err := ParseWants(annotated)
+	require.NoError(t, err)
+	assert.Empty(t, Compare(wants, issues))
+}
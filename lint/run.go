@@ -0,0 +1,380 @@
+package lint
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/gnolang/tlin/internal"
+	"github.com/gnolang/tlin/internal/cache"
+	"github.com/gnolang/tlin/internal/calibration"
+	"github.com/gnolang/tlin/internal/fixer"
+	tt "github.com/gnolang/tlin/internal/types"
+	"go.uber.org/zap"
+)
+
+// FixMode tells Run what to do with the fixes of the issues.
+type FixMode int
+
+const (
+	// FixNone only reports the issues.
+	FixNone FixMode = iota
+	// FixPreview computes the fixes into the report without modifying the
+	// files.
+	FixPreview
+	// FixApply computes the fixes and writes them to the files.
+	FixApply
+)
+
+// Options configure Run.
+type Options struct {
+	// Targets are the files and directories to lint.
+	Targets []string
+	// RootDir is the root of the repository, where the suppression list is
+	// read from. It defaults to the working directory.
+	RootDir string
+	// ConfigPath is the path of the configuration file. A missing file is
+	// not an error.
+	ConfigPath string
+	// Config, when set, is used instead of reading ConfigPath, which then
+	// only locates the files the configuration refers to.
+	Config *Config
+	// Preset selects a rule preset, replacing the one of the configuration.
+	Preset string
+	// Only, when set, restricts the run to the named rules, see
+	// internal.Engine.EnableOnly.
+	Only []string
+	// IgnoreRules and IgnorePaths leave out the issues of the named rules
+	// and of the files matching the patterns.
+	IgnoreRules []string
+	IgnorePaths []string
+	// Filter, when set, keeps only the issues it accepts, in the report,
+	// the stream and the fixes alike.
+	Filter func(issue tt.Issue) bool
+	// ReportUnusedIgnores reports the //tlin:ignore comments that suppressed
+	// no issue, see internal.Engine.ReportUnusedIgnores.
+	ReportUnusedIgnores bool
+	// Fix selects what is done with the fixes, only for the issues whose
+	// confidence is at least MinConfidence.
+	Fix           FixMode
+	MinConfidence float64
+	// Process tunes how the files are scheduled.
+	Process ProcessOptions
+	// RuleTimeout bounds the time each rule spends on a file, see
+	// internal.Engine.SetRuleTimeout. Zero disables the limit.
+	RuleTimeout time.Duration
+	// CacheDir is the directory of the result cache, which keeps the issues
+	// of the files between runs, see internal.Engine.SetCache. Empty
+	// disables the cache.
+	CacheDir string
+	// Observer, when set, is notified of the progress of the run.
+	Observer internal.Observer
+	// Stream, when set, receives the issues of each file as soon as it is
+	// linted, instead of collecting them in the report. See
+	// StreamFilesWithOptions. It cannot be combined with fixes.
+	Stream func(issues []tt.Issue) error
+	// Logger logs the files that could not be linted, nil to log nothing.
+	Logger *zap.Logger
+	// Engine, when set, lints the files instead of an engine built from
+	// the options above, which then only ignore rules and paths.
+	Engine LintEngine
+}
+
+// Report is the outcome of Run.
+type Report struct {
+	// Issues are the issues found, by file in walk order, the issues of
+	// the suppression list last. Their fingerprints are set.
+	Issues []tt.Issue
+	// Rules counts the issues of each rule that found or reported any.
+	Rules map[string]RuleStats
+	// Fixes are the fixes computed or applied, by file, for the fix modes.
+	Fixes []*fixer.Patch
+	// Skipped lists the files found in directories that could not be
+	// linted, along with the reason.
+	Skipped []SkippedFile
+	Summary internal.RunSummary
+}
+
+// RuleStats counts the issues of a rule in a run.
+type RuleStats struct {
+	// Found counts the issues the rule found, and Suppressed those of them
+	// silenced by nolint comments or by the suppression list, as counted by
+	// the engines Run builds.
+	Found      int `json:"found"`
+	Suppressed int `json:"suppressed"`
+	// Reported counts the issues of the rule in the report.
+	Reported int `json:"reported"`
+}
+
+// SkippedFile is a file Run could not lint.
+type SkippedFile struct {
+	Path   string `json:"path"`
+	Reason string `json:"reason"`
+}
+
+// Run lints the targets and returns the report of the run, doing everything
+// the command line does: it loads the configuration, builds the engine,
+// lints the files with a pool of workers, drops duplicated issues, applies
+// the suppression list, fingerprints the issues and fixes them.
+//
+// Like ProcessFilesWithOptions, it fails when an explicitly requested file
+// cannot be linted, while the files found in directories are skipped.
+func Run(ctx context.Context, opts Options) (*Report, error) {
+	if opts.Stream != nil && opts.Fix != FixNone {
+		return nil, errors.New("fixes cannot be computed while streaming issues")
+	}
+
+	engine := opts.Engine
+	if engine == nil {
+		var err error
+		if engine, err = runEngine(opts); err != nil {
+			return nil, err
+		}
+	}
+	for _, rule := range opts.IgnoreRules {
+		engine.IgnoreRule(rule)
+	}
+	for _, path := range opts.IgnorePaths {
+		engine.IgnorePath(path)
+	}
+
+	jobs, err := collectJobs(opts.Logger, opts.Targets)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &Report{Rules: make(map[string]RuleStats)}
+	seen := make(map[string]bool)
+	// keep drops the issues filtered out or already reported, and
+	// fingerprints the others
+	keep := func(issues []tt.Issue) []tt.Issue {
+		var kept []tt.Issue
+		for _, issue := range issues {
+			if opts.Filter != nil && !opts.Filter(issue) {
+				continue
+			}
+			if id := issue.ID(); !seen[id] {
+				seen[id] = true
+				kept = append(kept, issue)
+			}
+		}
+		// files that can't be read are reported by the engine already
+		_ = SetFingerprints(kept, "")
+		for _, issue := range kept {
+			stats := report.Rules[issue.Rule]
+			stats.Reported++
+			report.Rules[issue.Rule] = stats
+		}
+		return kept
+	}
+
+	var (
+		explicitErr error
+		streamErr   error
+	)
+	handle := func(i int, result fileResult) {
+		switch {
+		case result.err != nil && jobs[i].explicit:
+			if opts.Logger != nil {
+				opts.Logger.Error("Error processing path", zap.String("path", jobs[i].path), zap.Error(result.err))
+			}
+			if explicitErr == nil {
+				explicitErr = result.err
+			}
+		case result.err != nil:
+			if opts.Logger != nil {
+				opts.Logger.Error("Error processing file", zap.String("file", jobs[i].path), zap.Error(result.err))
+			}
+			report.Skipped = append(report.Skipped, SkippedFile{Path: jobs[i].path, Reason: result.err.Error()})
+		case opts.Stream != nil:
+			if issues := keep(result.issues); streamErr == nil && len(issues) > 0 {
+				streamErr = opts.Stream(issues)
+			}
+		default:
+			report.Issues = append(report.Issues, keep(result.issues)...)
+		}
+	}
+
+	var onResult func(int, fileResult)
+	if opts.Stream != nil {
+		// runJobs serializes the calls, so the state needs no locking
+		onResult = handle
+	}
+	results, summary, err := runJobs(ctx, opts.Logger, engine, jobs, ProcessFileContext, opts.Process, onResult)
+	if err != nil {
+		return nil, err
+	}
+	if opts.Stream == nil {
+		for i, result := range results {
+			handle(i, result)
+		}
+	}
+	sort.Slice(report.Skipped, func(i, j int) bool {
+		return report.Skipped[i].Path < report.Skipped[j].Path
+	})
+	if explicitErr != nil {
+		return nil, explicitErr
+	}
+
+	if opts.Fix != FixNone {
+		if err := fixIssues(report, opts); err != nil {
+			return nil, err
+		}
+	}
+
+	// the suppression list is checked once every file has been linted
+	var suppressions []tt.Issue
+	if list, ok := engine.(interface{ SuppressionIssues() []tt.Issue }); ok {
+		suppressions = keep(list.SuppressionIssues())
+	}
+	if opts.Stream != nil {
+		if streamErr == nil && len(suppressions) > 0 {
+			streamErr = opts.Stream(suppressions)
+		}
+	} else {
+		report.Issues = append(report.Issues, suppressions...)
+	}
+
+	if counter, ok := engine.(interface {
+		SuppressionCounts() map[string]calibration.Counts
+	}); ok {
+		for rule, counts := range counter.SuppressionCounts() {
+			stats := report.Rules[rule]
+			stats.Found, stats.Suppressed = counts.Found, counts.Suppressed
+			report.Rules[rule] = stats
+		}
+	}
+	// duplicated issues are not counted
+	summary.Issues = 0
+	for _, stats := range report.Rules {
+		summary.Issues += stats.Reported
+	}
+	report.Summary = summary
+
+	if streamErr != nil {
+		return nil, streamErr
+	}
+	return report, nil
+}
+
+// runEngine builds the engine of a run out of its options.
+func runEngine(opts Options) (*internal.Engine, error) {
+	rootDir := opts.RootDir
+	if rootDir == "" {
+		rootDir = "."
+	}
+
+	var (
+		engine *internal.Engine
+		err    error
+	)
+	if opts.Config != nil {
+		engine, err = newEngine(rootDir, nil, *opts.Config, opts.ConfigPath)
+	} else {
+		engine, err = New(rootDir, nil, opts.ConfigPath)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.Preset != "" {
+		if err := engine.SetPreset(opts.Preset); err != nil {
+			return nil, err
+		}
+	}
+	if len(opts.Only) > 0 {
+		if err := engine.EnableOnly(opts.Only...); err != nil {
+			return nil, err
+		}
+	}
+	if opts.Observer != nil {
+		engine.SetObserver(opts.Observer)
+	}
+	if opts.ReportUnusedIgnores {
+		engine.ReportUnusedIgnores()
+	}
+	engine.SetRuleTimeout(opts.RuleTimeout)
+	if opts.CacheDir != "" {
+		c, err := cache.Open(opts.CacheDir)
+		if err != nil {
+			// the cache only speeds up the run, which goes on without it
+			if opts.Logger != nil {
+				opts.Logger.Warn("Error opening the result cache", zap.Error(err))
+			}
+		} else {
+			engine.SetCache(c)
+		}
+	}
+	engine.CountSuppressions()
+	return engine, nil
+}
+
+// fixIssues computes the fixes of the issues of each file into the report,
+// and writes them for FixApply.
+func fixIssues(report *Report, opts Options) error {
+	fix := fixer.New(false, opts.MinConfidence)
+
+	var filenames []string
+	byFile := make(map[string][]tt.Issue)
+	for _, issue := range report.Issues {
+		if _, ok := byFile[issue.Filename]; !ok {
+			filenames = append(filenames, issue.Filename)
+		}
+		byFile[issue.Filename] = append(byFile[issue.Filename], issue)
+	}
+	sort.Strings(filenames)
+
+	for _, filename := range filenames {
+		patch, err := fix.Patch(filename, byFile[filename])
+		if err != nil {
+			return fmt.Errorf("error fixing %s: %w", filename, err)
+		}
+		if len(patch.Edits) == 0 {
+			continue
+		}
+		if opts.Fix == FixApply {
+			if err := patch.Apply(); err != nil {
+				return fmt.Errorf("error fixing %s: %w", filename, err)
+			}
+		}
+		report.Fixes = append(report.Fixes, patch)
+	}
+	return nil
+}
+
+// SetFingerprints fills the fingerprints of the issues, which may belong to
+// several files. Relative file names are relative to root. Issues of
+// unreadable files keep an empty fingerprint, and the error reading one of
+// them is returned.
+func SetFingerprints(issues []tt.Issue, root string) error {
+	byFile := make(map[string][]int)
+	for i := range issues {
+		byFile[issues[i].Filename] = append(byFile[issues[i].Filename], i)
+	}
+	var firstErr error
+	for filename, indexes := range byFile {
+		path := filename
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(root, path)
+		}
+		source, err := internal.ReadSourceCode(path)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		fileIssues := make([]tt.Issue, len(indexes))
+		for j, i := range indexes {
+			fileIssues[j] = issues[i]
+		}
+		tt.SetFingerprints(fileIssues, source.Lines)
+		for j, i := range indexes {
+			issues[i].Fingerprint = fileIssues[j].Fingerprint
+		}
+	}
+	return firstErr
+}
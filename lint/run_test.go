@@ -0,0 +1,266 @@
+package lint
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/gnolang/tlin/internal/fixer"
+	"github.com/gnolang/tlin/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var updateGolden = flag.Bool("update", false, "update golden files")
+
+// goldenReport is the form of a report kept in the golden file, with the
+// paths relative to the repository and without the timings. Issues are
+// grouped by file, since their JSON form leaves the file name out.
+type goldenReport struct {
+	Issues  map[string][]types.Issue `json:"issues"`
+	Rules   map[string]RuleStats     `json:"rules"`
+	Fixes   []*fixer.Patch           `json:"fixes"`
+	Skipped []SkippedFile            `json:"skipped"`
+	Summary struct {
+		Files  int `json:"files"`
+		Failed int `json:"failed"`
+		Issues int `json:"issues"`
+	} `json:"summary"`
+}
+
+// tempFile matches the temporary file the engine lints for a .gno file,
+// whose name changes from one run to the other.
+var tempFile = regexp.MustCompile(`temp_[0-9]+\.go`)
+
+func TestRunGolden(t *testing.T) {
+	t.Parallel()
+	// work on a copy, the engine writes temporary files next to the sources
+	root := t.TempDir()
+	copyTree(t, filepath.Join("testdata", "repo"), root)
+
+	report, err := Run(context.Background(), Options{
+		Targets:       []string{filepath.Join(root, "p")},
+		RootDir:       root,
+		ConfigPath:    filepath.Join(root, ".tlin.yaml"),
+		Fix:           FixPreview,
+		MinConfidence: 0.75,
+		Process:       ProcessOptions{Concurrency: 4},
+	})
+	require.NoError(t, err)
+
+	rel := func(path string) string {
+		p, err := filepath.Rel(root, path)
+		require.NoError(t, err)
+		return filepath.ToSlash(p)
+	}
+	golden := goldenReport{
+		Issues: make(map[string][]types.Issue),
+		Rules:  report.Rules,
+		Fixes:  report.Fixes,
+	}
+	for _, issue := range report.Issues {
+		assert.NotEmpty(t, issue.Fingerprint)
+		golden.Issues[rel(issue.Filename)] = append(golden.Issues[rel(issue.Filename)], issue)
+	}
+	for _, patch := range golden.Fixes {
+		patch.Filename = rel(patch.Filename)
+	}
+	for _, skipped := range report.Skipped {
+		golden.Skipped = append(golden.Skipped, SkippedFile{
+			Path:   rel(skipped.Path),
+			Reason: tempFile.ReplaceAllString(strings.ReplaceAll(skipped.Reason, root+string(filepath.Separator), ""), "temp.go"),
+		})
+	}
+	golden.Summary.Files = report.Summary.Files
+	golden.Summary.Failed = report.Summary.Failed
+	golden.Summary.Issues = report.Summary.Issues
+
+	got, err := json.MarshalIndent(golden, "", "  ")
+	require.NoError(t, err)
+	// the fingerprints and the IDs of the issues depend on the path of the
+	// copy, which is left out of the golden file
+	got = regexp.MustCompile(`"(id|issue_id|fingerprint)": "[0-9a-f]+"`).ReplaceAll(got, []byte(`"$1": "..."`))
+	got = append(got, '\n')
+
+	goldenFile := filepath.Join("testdata", "run.golden.json")
+	if *updateGolden {
+		require.NoError(t, os.WriteFile(goldenFile, got, 0o644))
+	}
+	expected, err := os.ReadFile(goldenFile)
+	require.NoError(t, err)
+	assert.Equal(t, string(expected), string(got), "report drift, run `go test ./lint -run TestRunGolden -update` if intended")
+
+	// previews leave the files untouched
+	source, err := os.ReadFile(filepath.Join(root, "p", "demo", "foo", "foo.gno"))
+	require.NoError(t, err)
+	original, err := os.ReadFile(filepath.Join("testdata", "repo", "p", "demo", "foo", "foo.gno"))
+	require.NoError(t, err)
+	assert.Equal(t, string(original), string(source))
+}
+
+func TestRunFixApply(t *testing.T) {
+	t.Parallel()
+	root := t.TempDir()
+	copyTree(t, filepath.Join("testdata", "repo"), root)
+	filename := filepath.Join(root, "p", "demo", "foo", "foo.gno")
+
+	report, err := Run(context.Background(), Options{
+		Targets:       []string{filename},
+		RootDir:       root,
+		ConfigPath:    filepath.Join(root, ".tlin.yaml"),
+		Fix:           FixApply,
+		MinConfidence: 0.75,
+	})
+	require.NoError(t, err)
+	require.Len(t, report.Fixes, 1)
+
+	fixed, err := os.ReadFile(filename)
+	require.NoError(t, err)
+	assert.Equal(t, string(report.Fixes[0].Fixed()), string(fixed))
+	assert.Contains(t, string(fixed), `var ErrNotFound = errors.New("not found")`)
+}
+
+func TestRunFixKeepsIssuesWithoutFix(t *testing.T) {
+	t.Parallel()
+	filename := filepath.Join(t.TempDir(), "regex.gno")
+	source := `package main
+
+import "regexp"
+
+func withRepeat() {
+	r1 := regexp.MustCompile("pattern")
+	r2 := regexp.MustCompile("pattern")
+	_ = r1
+	_ = r2
+}
+`
+	require.NoError(t, os.WriteFile(filename, []byte(source), 0o644))
+
+	report, err := Run(context.Background(), Options{
+		Targets:       []string{filename},
+		Fix:           FixApply,
+		MinConfidence: 0.75,
+	})
+	require.NoError(t, err)
+	rules := make([]string, 0, len(report.Issues))
+	for _, issue := range report.Issues {
+		rules = append(rules, issue.Rule)
+	}
+	require.Contains(t, rules, "repeatedregexcompilation")
+
+	fixed, err := os.ReadFile(filename)
+	require.NoError(t, err)
+	assert.Equal(t, source, string(fixed))
+}
+
+func TestRunStream(t *testing.T) {
+	t.Parallel()
+	root := t.TempDir()
+	copyTree(t, filepath.Join("testdata", "repo"), root)
+	opts := Options{
+		Targets:    []string{filepath.Join(root, "p")},
+		RootDir:    root,
+		ConfigPath: filepath.Join(root, ".tlin.yaml"),
+	}
+
+	batch, err := Run(context.Background(), opts)
+	require.NoError(t, err)
+
+	var streamed []types.Issue
+	opts.Stream = func(issues []types.Issue) error {
+		streamed = append(streamed, issues...)
+		return nil
+	}
+	report, err := Run(context.Background(), opts)
+	require.NoError(t, err)
+	assert.Empty(t, report.Issues)
+	assert.Equal(t, batch.Rules, report.Rules)
+	assert.Equal(t, len(streamed), report.Summary.Issues)
+
+	for _, issues := range [][]types.Issue{batch.Issues, streamed} {
+		for i := range issues {
+			issues[i].Start.Filename, issues[i].End.Filename = "", ""
+		}
+	}
+	assert.ElementsMatch(t, batch.Issues, streamed)
+
+	opts.Fix = FixPreview
+	_, err = Run(context.Background(), opts)
+	assert.Error(t, err, "fixes cannot be streamed")
+}
+
+func TestRunFilter(t *testing.T) {
+	t.Parallel()
+	root := t.TempDir()
+	copyTree(t, filepath.Join("testdata", "repo"), root)
+	opts := Options{
+		Targets:    []string{filepath.Join(root, "p")},
+		RootDir:    root,
+		ConfigPath: filepath.Join(root, ".tlin.yaml"),
+	}
+
+	all, err := Run(context.Background(), opts)
+	require.NoError(t, err)
+	require.NotEmpty(t, all.Issues)
+	rule := all.Issues[0].Rule
+
+	opts.Filter = func(issue types.Issue) bool { return issue.Rule != rule }
+	report, err := Run(context.Background(), opts)
+	require.NoError(t, err)
+	for _, issue := range report.Issues {
+		assert.NotEqual(t, rule, issue.Rule)
+	}
+	assert.Zero(t, report.Rules[rule].Reported)
+	assert.Less(t, report.Summary.Issues, all.Summary.Issues)
+	assert.Equal(t, len(report.Issues), report.Summary.Issues)
+}
+
+func TestRunExplicitFileError(t *testing.T) {
+	t.Parallel()
+	root := t.TempDir()
+	copyTree(t, filepath.Join("testdata", "repo"), root)
+
+	_, err := Run(context.Background(), Options{
+		Targets:    []string{filepath.Join(root, "p", "demo", "foo", "broken.gno")},
+		RootDir:    root,
+		ConfigPath: filepath.Join(root, ".tlin.yaml"),
+	})
+	assert.Error(t, err)
+
+	_, err = Run(context.Background(), Options{
+		Targets:    []string{filepath.Join(root, "missing")},
+		RootDir:    root,
+		ConfigPath: filepath.Join(root, ".tlin.yaml"),
+	})
+	assert.True(t, errors.Is(err, fs.ErrNotExist), err)
+}
+
+func copyTree(t *testing.T, src, dst string) {
+	t.Helper()
+	err := filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+		if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+			return err
+		}
+		return os.WriteFile(target, content, 0o644)
+	})
+	require.NoError(t, err)
+}